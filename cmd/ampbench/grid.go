@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	// gridMaxItems matches the cap MediaGrid applies in virtual scroll mode.
+	gridMaxItems = 1000
+	gridColumns  = 5
+)
+
+var (
+	gridCardSize   = fyne.NewSize(200, 280)
+	gridWindowSize = fyne.NewSize(1200, 800)
+)
+
+// benchGridScroll lays songs out the way the songs view grid does and times
+// each scroll step: moving the offset, refreshing the scroller and walking
+// the cards inside the viewport the way a driver does before drawing. There
+// is no GPU in the loop, so the numbers track layout and widget cost rather
+// than what a user sees on screen.
+func benchGridScroll(songs []*types.Song, frames int) *timings {
+	test.NewApp()
+
+	if len(songs) > gridMaxItems {
+		songs = songs[:gridMaxItems]
+	}
+
+	cards := make([]fyne.CanvasObject, 0, len(songs))
+	for _, song := range songs {
+		cards = append(cards, newBenchCard(song))
+	}
+	grid := container.NewGridWithColumns(gridColumns, cards...)
+	scroll := container.NewVScroll(grid)
+
+	c := test.NewCanvas()
+	c.SetContent(scroll)
+	c.Resize(gridWindowSize)
+
+	times := newTimings("Grid scroll frame")
+	if frames <= 0 {
+		return times
+	}
+
+	maxOffset := grid.MinSize().Height - gridWindowSize.Height
+	if maxOffset < 0 {
+		maxOffset = 0
+	}
+	step := maxOffset / float32(frames)
+
+	for i := 0; i < frames; i++ {
+		start := time.Now()
+		offset := step * float32(i)
+		scroll.Offset = fyne.NewPos(0, offset)
+		scroll.Refresh()
+		walkVisible(grid, fyne.NewPos(0, -offset), gridWindowSize)
+		times.add(time.Since(start))
+	}
+	return times
+}
+
+// walkVisible visits every object that intersects the viewport, asking each
+// for its minimum size and laying out its renderer.
+func walkVisible(obj fyne.CanvasObject, pos fyne.Position, viewport fyne.Size) {
+	if !obj.Visible() {
+		return
+	}
+	pos = pos.Add(obj.Position())
+	size := obj.Size()
+	if pos.X > viewport.Width || pos.Y > viewport.Height || pos.X+size.Width < 0 || pos.Y+size.Height < 0 {
+		return
+	}
+
+	obj.MinSize()
+	var children []fyne.CanvasObject
+	switch o := obj.(type) {
+	case fyne.Widget:
+		renderer := test.WidgetRenderer(o)
+		renderer.Layout(size)
+		children = renderer.Objects()
+	case *fyne.Container:
+		children = o.Objects
+	}
+	for _, child := range children {
+		walkVisible(child, pos, viewport)
+	}
+}
+
+func newBenchCard(song *types.Song) fyne.CanvasObject {
+	image := canvas.NewImageFromResource(theme.MediaMusicIcon())
+	image.FillMode = canvas.ImageFillContain
+	image.SetMinSize(fyne.NewSize(gridCardSize.Width-16, gridCardSize.Height-56))
+
+	title := widget.NewLabel(song.Name)
+	title.Alignment = fyne.TextAlignCenter
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	title.Truncation = fyne.TextTruncateEllipsis
+
+	names := make([]string, 0, len(song.Authors))
+	for _, author := range song.Authors {
+		names = append(names, author.Name)
+	}
+	subtitle := widget.NewLabel(strings.Join(names, ", "))
+	subtitle.Alignment = fyne.TextAlignCenter
+	subtitle.Truncation = fyne.TextTruncateEllipsis
+
+	return container.NewBorder(nil, container.NewVBox(title, subtitle), nil, nil, image)
+}
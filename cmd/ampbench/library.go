@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+var words = []string{
+	"midnight", "river", "echo", "neon", "summer", "glass", "velvet", "static",
+	"horizon", "ember", "silver", "hollow", "paper", "thunder", "violet", "drift",
+	"golden", "shadow", "signal", "harbor", "crystal", "wild", "electric", "quiet",
+	"ocean", "falling", "broken", "city", "lights", "dream", "fire", "winter",
+}
+
+// library is a generated set of songs, albums, authors and playlists with
+// the relationships the real API returns.
+type library struct {
+	rng       *rand.Rand
+	songs     []*types.Song
+	playlists []*types.Playlist
+}
+
+func generateLibrary(seed int64, songs, albums, authors, playlists int) *library {
+	rng := rand.New(rand.NewSource(seed))
+	lib := &library{rng: rng}
+
+	if albums < 1 {
+		albums = 1
+	}
+	if authors < 1 {
+		authors = 1
+	}
+
+	authorList := make([]*types.Author, authors)
+	for i := range authorList {
+		authorList[i] = &types.Author{
+			Slug: fmt.Sprintf("bench-author-%d", i),
+			Name: lib.title(2),
+		}
+	}
+
+	albumList := make([]*types.Album, albums)
+	for i := range albumList {
+		artist := authorList[rng.Intn(len(authorList))]
+		albumList[i] = &types.Album{
+			Slug:        fmt.Sprintf("bench-album-%d", i),
+			Name:        lib.title(3),
+			Artists:     []*types.Author{artist},
+			AlbumArtist: artist.Name,
+		}
+	}
+
+	created := time.Now().Add(-365 * 24 * time.Hour)
+	lib.songs = make([]*types.Song, songs)
+	for i := range lib.songs {
+		album := albumList[rng.Intn(len(albumList))]
+		songAuthors := []*types.Author{album.Artists[0]}
+		if rng.Intn(5) == 0 {
+			songAuthors = append(songAuthors, authorList[rng.Intn(len(authorList))])
+		}
+		image := fmt.Sprintf("https://example.com/covers/%d.jpg", i)
+		lib.songs[i] = &types.Song{
+			Slug:      fmt.Sprintf("bench-song-%d", i),
+			Name:      lib.title(1 + rng.Intn(4)),
+			File:      fmt.Sprintf("https://example.com/songs/%d.mp3", i),
+			Image:     &image,
+			Length:    120 + rng.Intn(300),
+			Played:    rng.Intn(1000),
+			Album:     album,
+			Authors:   songAuthors,
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		}
+	}
+
+	lib.playlists = make([]*types.Playlist, playlists)
+	for i := range lib.playlists {
+		playlist := &types.Playlist{
+			Slug: fmt.Sprintf("bench-playlist-%d", i),
+			Name: lib.title(2),
+		}
+		n := 10 + rng.Intn(90)
+		if n > len(lib.songs) {
+			n = len(lib.songs)
+		}
+		for _, idx := range rng.Perm(len(lib.songs))[:n] {
+			playlist.Songs = append(playlist.Songs, lib.songs[idx])
+		}
+		playlist.Length = len(playlist.Songs)
+		lib.playlists[i] = playlist
+	}
+
+	return lib
+}
+
+// queries returns search terms in the mix a user types: whole words, word
+// prefixes, song titles and terms that match nothing.
+func (lib *library) queries(n int) []string {
+	queries := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		word := words[lib.rng.Intn(len(words))]
+		switch i % 4 {
+		case 0:
+			queries = append(queries, word)
+		case 1:
+			queries = append(queries, word[:3])
+		case 2:
+			if len(lib.songs) > 0 {
+				queries = append(queries, lib.songs[lib.rng.Intn(len(lib.songs))].Name)
+				continue
+			}
+			queries = append(queries, word)
+		default:
+			queries = append(queries, fmt.Sprintf("zz%s%d", word, i))
+		}
+	}
+	return queries
+}
+
+func (lib *library) title(n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		word := words[lib.rng.Intn(len(words))]
+		parts[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,204 @@
+// Command ampbench generates a synthetic library and times the storage, search
+// and grid rendering paths the desktop app depends on. Budgets given on the
+// command line turn it into a regression check: it exits non-zero when any
+// measurement is over budget.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/search"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+)
+
+var (
+	songCount     = flag.Int("songs", 5000, "Number of synthetic songs to generate")
+	albumCount    = flag.Int("albums", 400, "Number of synthetic albums to generate")
+	authorCount   = flag.Int("authors", 300, "Number of synthetic authors to generate")
+	playlistCount = flag.Int("playlists", 50, "Number of synthetic playlists to generate")
+	batchSize     = flag.Int("batch", 50, "Songs per SaveSongsBatch call, matching an API page")
+	queryCount    = flag.Int("queries", 200, "Number of search queries to time")
+	frameCount    = flag.Int("frames", 120, "Number of grid scroll frames to render")
+	seed          = flag.Int64("seed", 1, "Random seed for the generated library")
+	dbDir         = flag.String("dir", "", "Directory for the benchmark database (default: a temporary directory)")
+	debug         = flag.Bool("debug", false, "Enable debug logging")
+
+	minSavesPerSec = flag.Float64("min-saves-per-sec", 0, "Fail if batched saves are slower than this many songs per second (0 disables)")
+	maxSearchP95   = flag.Duration("max-search-p95", 0, "Fail if the 95th percentile search latency exceeds this (0 disables)")
+	maxFrameP95    = flag.Duration("max-frame-p95", 0, "Fail if the 95th percentile grid frame time exceeds this (0 disables)")
+)
+
+func main() {
+	flag.Parse()
+
+	if *debug {
+		log.SetFlags(log.LstdFlags | log.Lshortfile)
+	}
+
+	dir := *dbDir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "ampbench-")
+		if err != nil {
+			log.Fatalf("[BENCH] Failed to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+
+	cfg := &config.Config{Debug: *debug}
+	cfg.Storage.DatabasePath = filepath.Join(dir, "bench.db")
+	cfg.Storage.CacheDir = filepath.Join(dir, "cache")
+	cfg.Storage.EnableWAL = true
+
+	db, err := storage.NewDatabase(cfg)
+	if err != nil {
+		log.Fatalf("[BENCH] Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	lib := generateLibrary(*seed, *songCount, *albumCount, *authorCount, *playlistCount)
+	fmt.Printf("Library: %d songs, %d albums, %d authors, %d playlists\n\n",
+		len(lib.songs), *albumCount, *authorCount, len(lib.playlists))
+
+	ctx := context.Background()
+	var failures []string
+
+	save, err := benchSave(ctx, db, lib, *batchSize)
+	if err != nil {
+		log.Fatalf("[BENCH] Save benchmark failed: %v", err)
+	}
+	save.print()
+	if *minSavesPerSec > 0 && save.batchRate < *minSavesPerSec {
+		failures = append(failures, fmt.Sprintf("batched saves %.0f songs/s, below %.0f", save.batchRate, *minSavesPerSec))
+	}
+
+	engine := search.NewSearchEngine(cfg, db)
+	searches, err := benchSearch(ctx, db, engine, lib.queries(*queryCount))
+	if err != nil {
+		log.Fatalf("[BENCH] Search benchmark failed: %v", err)
+	}
+	for _, s := range searches {
+		s.print()
+		if *maxSearchP95 > 0 && s.Percentile(95) > *maxSearchP95 {
+			failures = append(failures, fmt.Sprintf("%s p95 %v, over %v", s.name, s.Percentile(95), *maxSearchP95))
+		}
+	}
+
+	frames := benchGridScroll(lib.songs, *frameCount)
+	frames.print()
+	if *maxFrameP95 > 0 && frames.Percentile(95) > *maxFrameP95 {
+		failures = append(failures, fmt.Sprintf("grid frame p95 %v, over %v", frames.Percentile(95), *maxFrameP95))
+	}
+
+	if len(failures) > 0 {
+		fmt.Println("\nOver budget:")
+		for _, failure := range failures {
+			fmt.Printf("  - %s\n", failure)
+		}
+		os.Exit(1)
+	}
+}
+
+type saveResult struct {
+	singleCount int
+	singleTime  time.Duration
+	batchCount  int
+	batchTime   time.Duration
+	batchRate   float64
+	playlists   int
+	playlistDur time.Duration
+}
+
+// benchSave stores a sample of songs one transaction at a time as a
+// baseline, then the whole library through SaveSongsBatch.
+func benchSave(ctx context.Context, db *storage.Database, lib *library, batch int) (*saveResult, error) {
+	if batch < 1 {
+		batch = 1
+	}
+	res := &saveResult{}
+
+	sample := lib.songs
+	if len(sample) > 500 {
+		sample = sample[:500]
+	}
+	start := time.Now()
+	for _, song := range sample {
+		if err := db.SaveSong(ctx, song); err != nil {
+			return nil, fmt.Errorf("save song %s: %w", song.Slug, err)
+		}
+	}
+	res.singleCount = len(sample)
+	res.singleTime = time.Since(start)
+
+	start = time.Now()
+	for i := 0; i < len(lib.songs); i += batch {
+		end := i + batch
+		if end > len(lib.songs) {
+			end = len(lib.songs)
+		}
+		if err := db.SaveSongsBatch(ctx, lib.songs[i:end]); err != nil {
+			return nil, fmt.Errorf("save songs batch at %d: %w", i, err)
+		}
+	}
+	res.batchCount = len(lib.songs)
+	res.batchTime = time.Since(start)
+	if res.batchTime > 0 {
+		res.batchRate = float64(res.batchCount) / res.batchTime.Seconds()
+	}
+
+	start = time.Now()
+	for _, playlist := range lib.playlists {
+		if err := db.SavePlaylist(ctx, playlist); err != nil {
+			return nil, fmt.Errorf("save playlist %s: %w", playlist.Slug, err)
+		}
+	}
+	res.playlists = len(lib.playlists)
+	res.playlistDur = time.Since(start)
+
+	return res, nil
+}
+
+func (r *saveResult) print() {
+	fmt.Println("Storage")
+	fmt.Printf("  SaveSong        %6d songs in %-12v %8.0f songs/s\n",
+		r.singleCount, r.singleTime.Round(time.Millisecond), rate(r.singleCount, r.singleTime))
+	fmt.Printf("  SaveSongsBatch  %6d songs in %-12v %8.0f songs/s\n",
+		r.batchCount, r.batchTime.Round(time.Millisecond), r.batchRate)
+	fmt.Printf("  SavePlaylist    %6d lists in %-12v %8.0f lists/s\n\n",
+		r.playlists, r.playlistDur.Round(time.Millisecond), rate(r.playlists, r.playlistDur))
+}
+
+func benchSearch(ctx context.Context, db *storage.Database, engine *search.SearchEngine, queries []string) ([]*timings, error) {
+	storageTimes := newTimings("SearchSongs")
+	engineTimes := newTimings("SearchEngine.Search")
+
+	for _, query := range queries {
+		start := time.Now()
+		if _, err := db.SearchSongs(ctx, query, 50); err != nil {
+			return nil, fmt.Errorf("search songs %q: %w", query, err)
+		}
+		storageTimes.add(time.Since(start))
+
+		start = time.Now()
+		if _, err := engine.Search(ctx, query, 50); err != nil {
+			return nil, fmt.Errorf("search %q: %w", query, err)
+		}
+		engineTimes.add(time.Since(start))
+	}
+
+	return []*timings{storageTimes, engineTimes}, nil
+}
+
+func rate(count int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(count) / d.Seconds()
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// timings collects samples of one measurement.
+type timings struct {
+	name    string
+	samples []time.Duration
+	sorted  bool
+}
+
+func newTimings(name string) *timings {
+	return &timings{name: name}
+}
+
+func (t *timings) add(d time.Duration) {
+	t.samples = append(t.samples, d)
+	t.sorted = false
+}
+
+// Percentile returns the p-th percentile sample using nearest rank.
+func (t *timings) Percentile(p float64) time.Duration {
+	if len(t.samples) == 0 {
+		return 0
+	}
+	if !t.sorted {
+		sort.Slice(t.samples, func(i, j int) bool { return t.samples[i] < t.samples[j] })
+		t.sorted = true
+	}
+	rank := int(p/100*float64(len(t.samples)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(t.samples) {
+		rank = len(t.samples)
+	}
+	return t.samples[rank-1]
+}
+
+func (t *timings) Mean() time.Duration {
+	if len(t.samples) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range t.samples {
+		total += d
+	}
+	return total / time.Duration(len(t.samples))
+}
+
+func (t *timings) print() {
+	fmt.Printf("%s (%d samples)\n", t.name, len(t.samples))
+	fmt.Printf("  mean %-10v p50 %-10v p95 %-10v p99 %-10v max %v\n\n",
+		round(t.Mean()), round(t.Percentile(50)), round(t.Percentile(95)),
+		round(t.Percentile(99)), round(t.Percentile(100)))
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
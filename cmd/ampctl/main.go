@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Alexander-D-Karpov/amp/internal/platform"
+)
+
+const usage = `Usage: ampctl <command> [arguments]
+
+Controls the running AMP from scripts and keybindings.
+
+Commands:
+  play              Resume playback
+  pause             Pause playback
+  toggle            Pause or resume playback
+  stop              Stop playback
+  next              Skip to the next song
+  prev              Go back to the previous song
+  now               Print the current song as "Artist – Title"
+  status            Print the player state as "key: value" lines
+  queue <song>      Add a song to the queue by slug or search query
+  sync              Sync the library with the server now
+  show              Bring the AMP window to the front
+`
+
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	output, err := platform.SendControl(flag.Arg(0), flag.Args()[1:])
+	if errors.Is(err, platform.ErrNotRunning) {
+		fmt.Fprintln(os.Stderr, "ampctl: AMP is not running")
+		os.Exit(3)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ampctl: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(output)
+}
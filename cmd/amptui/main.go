@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/internal/audio"
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/demo"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+	"github.com/Alexander-D-Karpov/amp/internal/search"
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/internal/tui"
+)
+
+var (
+	configPath = flag.String("config", "", "Path to configuration file")
+	debug      = flag.Bool("debug", false, "Write detailed logging to amptui.log in the cache directory")
+	demoMode   = flag.Bool("demo", false, "Explore a bundled sample library without a server")
+)
+
+func main() {
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("[MAIN] Failed to load config: %v", err)
+	}
+	if *demoMode {
+		cfg.UseDemo()
+	}
+	cfg.Debug = cfg.Debug || *debug
+
+	// The terminal belongs to the UI, so logs go to a file or nowhere.
+	logFile, err := openLog(cfg)
+	if err != nil {
+		log.Fatalf("[MAIN] Failed to open log: %v", err)
+	}
+	defer logFile.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "amptui: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, cfg *config.Config) error {
+	if err := netutil.Apply(cfg); err != nil {
+		log.Printf("[MAIN] Ignoring invalid network settings: %v", err)
+	}
+
+	var backend api.MusicBackend
+	if cfg.Demo {
+		backend = demo.NewBackend(cfg)
+	} else {
+		backend = api.NewBackend(cfg)
+	}
+	client := api.MusicBackend(api.NewSwitch(backend, cfg.API.Offline))
+	if cfg.User.IsAnonymous && cfg.API.Token == "" {
+		if _, err := client.EnsureAnonymousToken(ctx); err != nil && !errors.Is(err, api.ErrUnsupported) {
+			log.Printf("[MAIN] Anonymous token failed: %v", err)
+		}
+	}
+
+	db, err := storage.NewDatabase(cfg)
+	if err != nil {
+		return fmt.Errorf("initialize database: %w", err)
+	}
+	defer db.Close()
+
+	player, err := audio.NewPlayer(cfg, db)
+	if err != nil {
+		return fmt.Errorf("initialize audio player: %w", err)
+	}
+	defer player.Close()
+
+	music := services.NewMusicService(client, db, search.NewSearchEngine(cfg, db))
+	music.SetDebug(cfg.Debug)
+
+	return tui.Run(ctx, music, player)
+}
+
+func openLog(cfg *config.Config) (io.Closer, error) {
+	if !cfg.Debug {
+		log.SetOutput(io.Discard)
+		return io.NopCloser(nil), nil
+	}
+	if err := os.MkdirAll(cfg.Storage.CacheDir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(cfg.Storage.CacheDir, "amptui.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	log.SetOutput(f)
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	return f, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
 	"os"
@@ -11,13 +12,18 @@ import (
 	"fyne.io/fyne/v2/app"
 
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/platform"
 	"github.com/Alexander-D-Karpov/amp/internal/ui"
+	"github.com/Alexander-D-Karpov/amp/internal/updater"
 )
 
 var (
 	configPath = flag.String("config", "", "Path to configuration file")
 	debug      = flag.Bool("debug", false, "Enable debug mode - shows detailed logging for all components")
+	demo       = flag.Bool("demo", false, "Explore a bundled sample library without a server")
+	kiosk      = flag.Bool("kiosk", false, "Start fullscreen in jukebox mode: now playing and search-to-queue only")
 	Version    = "dev"
+	Commit     = ""
 )
 
 func main() {
@@ -28,11 +34,27 @@ func main() {
 		log.Println("[MAIN] Debug mode enabled - all components will log detailed information")
 	}
 
+	args := platform.AbsFileArgs(flag.Args())
+	instance, err := platform.AcquireInstance(args)
+	if errors.Is(err, platform.ErrAlreadyRunning) {
+		log.Printf("[MAIN] AMP is already running, activated existing window")
+		return
+	}
+	if err != nil {
+		log.Printf("[MAIN] Single-instance lock unavailable: %v", err)
+	}
+
 	cfg, err := config.Load(*configPath)
 	if err != nil {
 		log.Fatalf("[MAIN] Failed to load config: %v", err)
 	}
 
+	if *demo {
+		cfg.UseDemo()
+		log.Printf("[MAIN] Demo mode: using the bundled sample library")
+	}
+	cfg.Kiosk = *kiosk
+
 	if *debug {
 		cfg.Debug = true
 		log.Printf("[MAIN] Configuration loaded successfully")
@@ -63,11 +85,30 @@ func main() {
 		log.Fatalf("[MAIN] Failed to create app: %v", err)
 	}
 
-	setupGracefulShutdown(cancel, ampApp)
+	ampApp.SetBuildInfo(Version, Commit)
+
+	appUpdater := updater.New(cfg, Version)
+	appUpdater.CleanupPrevious()
+	ampApp.SetUpdater(appUpdater)
+
+	if instance != nil {
+		defer instance.Release()
+		instance.OnActivate(ampApp.Activate)
+		instance.OnControl(ampApp.HandleControl)
+	}
+	if len(args) > 0 {
+		ampApp.Activate(args)
+	}
+
+	setupGracefulShutdown(cancel, ampApp, instance, appUpdater)
 	ampApp.ShowAndRun()
+
+	if err := appUpdater.ApplyPending(); err != nil {
+		log.Printf("[MAIN] Failed to apply update: %v", err)
+	}
 }
 
-func setupGracefulShutdown(cancel context.CancelFunc, ampApp *ui.App) {
+func setupGracefulShutdown(cancel context.CancelFunc, ampApp *ui.App, instance *platform.InstanceLock, appUpdater *updater.Updater) {
 	go func() {
 		c := make(chan os.Signal, 1)
 		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -78,6 +119,12 @@ func setupGracefulShutdown(cancel context.CancelFunc, ampApp *ui.App) {
 
 		cancel()
 		ampApp.Close()
+		if instance != nil {
+			instance.Release()
+		}
+		if err := appUpdater.ApplyPending(); err != nil {
+			log.Printf("[MAIN] Failed to apply update: %v", err)
+		}
 
 		log.Printf("[MAIN] Graceful shutdown completed")
 		os.Exit(0)
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	BackendAMP      = "amp"
+	BackendSubsonic = "subsonic"
+)
+
+// MusicBackend is a music server AMP can browse, stream from and report
+// plays to. Client talks to the akarpov music API; SubsonicClient talks to
+// Subsonic-compatible servers.
+type MusicBackend interface {
+	GetSongs(ctx context.Context, page int, search string) (*types.SongListResponse, error)
+	GetSongsWithSort(ctx context.Context, page int, search string, sortOption SortOption) (*types.SongListResponse, error)
+	GetSong(ctx context.Context, slug string) (*types.Song, error)
+	GetAlbums(ctx context.Context, page int, search string) (*types.AlbumListResponse, error)
+	GetAlbum(ctx context.Context, slug string) (*types.Album, error)
+	GetAuthors(ctx context.Context, page int, search string) (*types.AuthorListResponse, error)
+	GetAuthor(ctx context.Context, slug string) (*types.Author, error)
+	GetPlaylists(ctx context.Context) ([]*types.Playlist, error)
+	GetPlaylist(ctx context.Context, slug string) (*types.Playlist, error)
+	CreatePlaylist(ctx context.Context, playlist *types.Playlist) error
+	UpdatePlaylist(ctx context.Context, playlist *types.Playlist) error
+	DeletePlaylist(ctx context.Context, slug string) error
+	SearchAll(ctx context.Context, query string) (*types.SearchResponse, error)
+	ListenSong(ctx context.Context, slug string, userID string) error
+
+	Authenticate(ctx context.Context, token string) error
+	EnsureAnonymousToken(ctx context.Context) (string, error)
+	GetCurrentUser(ctx context.Context) (*types.User, error)
+	Logout(ctx context.Context) error
+	IsAnonymous() bool
+	GetToken() string
+	SetToken(token string)
+
+	ProbeServer(ctx context.Context) (*ServerInfo, error)
+	Supports(feature string) bool
+}
+
+// AnonymousValidator is implemented by backends that can check with the
+// server that the anonymous token they use is still accepted.
+type AnonymousValidator interface {
+	ValidateAnonymousToken(ctx context.Context) error
+}
+
+// ErrStaleAnonymousToken is returned when the server no longer accepts the
+// anonymous token, so a new one has to be created.
+var ErrStaleAnonymousToken = errors.New("anonymous token is no longer valid")
+
+// Liker is implemented by backends that keep the user's liked songs on the
+// server.
+type Liker interface {
+	LikeSong(ctx context.Context, slug string) error
+	DislikeSong(ctx context.Context, slug string) error
+}
+
+// SectionSearcher is implemented by backends that can hand out search
+// results one section at a time while the response is still arriving.
+type SectionSearcher interface {
+	SearchAllSections(ctx context.Context, query string, onSection func(*types.SearchResponse)) error
+}
+
+// NewBackend returns the client for the backend selected in the config.
+func NewBackend(cfg *config.Config) MusicBackend {
+	switch cfg.API.Backend {
+	case BackendSubsonic:
+		return NewSubsonicClient(cfg)
+	case BackendAMP, "":
+		return NewClient(cfg)
+	default:
+		log.Printf("[API] Unknown backend %q, using %s", cfg.API.Backend, BackendAMP)
+		return NewClient(cfg)
+	}
+}
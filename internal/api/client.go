@@ -10,12 +10,14 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
 	"golang.org/x/time/rate"
 
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
@@ -32,6 +34,10 @@ type Client struct {
 	errorCount    int64
 	lastRequestAt time.Time
 
+	infoMu         sync.RWMutex
+	serverInfo     *ServerInfo
+	schemaWarnings sync.Map
+
 	cfg *config.Config
 }
 
@@ -49,7 +55,7 @@ const (
 func NewClient(cfg *config.Config) *Client {
 	retryClient := retryablehttp.NewClient()
 	retryClient.RetryMax = cfg.API.Retries
-	retryClient.HTTPClient.Timeout = time.Duration(cfg.API.Timeout) * time.Second
+	retryClient.HTTPClient = netutil.NewClient(cfg, netutil.ProfileAPI)
 	retryClient.Logger = nil
 
 	if cfg.Debug {
@@ -138,8 +144,39 @@ func (c *Client) debugResponse(method, url string, statusCode int, duration time
 func (c *Client) makeRequest(ctx context.Context, method, path string, params url.Values, body interface{}) (*http.Response, []byte, error) {
 	startTime := time.Now()
 
+	resp, fullURL, err := c.openRequest(ctx, method, path, params, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responseBody, readErr := io.ReadAll(resp.Body)
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		c.debugLog("Failed to close response body: %v", closeErr)
+	}
+
+	if readErr != nil {
+		c.debugResponse(method, fullURL, resp.StatusCode, time.Since(startTime), readErr)
+		return resp, nil, fmt.Errorf("read response body: %w", readErr)
+	}
+
+	if resp.StatusCode >= 400 {
+		err := responseError(resp, responseBody)
+		c.debugResponse(method, fullURL, resp.StatusCode, time.Since(startTime), err)
+		return resp, responseBody, err
+	}
+
+	c.debugResponse(method, fullURL, resp.StatusCode, time.Since(startTime), nil)
+	return resp, responseBody, nil
+}
+
+// openRequest sends a request and returns the response with its body still
+// unread, along with the full URL for logging. The caller must close the
+// body.
+func (c *Client) openRequest(ctx context.Context, method, path string, params url.Values, body interface{}) (*http.Response, string, error) {
+	startTime := time.Now()
+
 	if err := c.limiter.Wait(ctx); err != nil {
-		return nil, nil, fmt.Errorf("rate limit wait: %w", err)
+		return nil, "", fmt.Errorf("rate limit wait: %w", err)
 	}
 
 	fullURL := c.baseURL + path
@@ -154,7 +191,7 @@ func (c *Client) makeRequest(ctx context.Context, method, path string, params ur
 		bodyBytes, err := json.Marshal(body)
 		if err != nil {
 			c.debugResponse(method, fullURL, 0, time.Since(startTime), err)
-			return nil, nil, fmt.Errorf("marshal request body: %w", err)
+			return nil, fullURL, fmt.Errorf("marshal request body: %w", err)
 		}
 		reqBody = bytes.NewReader(bodyBytes)
 	}
@@ -162,7 +199,7 @@ func (c *Client) makeRequest(ctx context.Context, method, path string, params ur
 	req, err := retryablehttp.NewRequestWithContext(ctx, method, fullURL, reqBody)
 	if err != nil {
 		c.debugResponse(method, fullURL, 0, time.Since(startTime), err)
-		return nil, nil, fmt.Errorf("create request: %w", err)
+		return nil, fullURL, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", c.userAgent)
@@ -183,51 +220,35 @@ func (c *Client) makeRequest(ctx context.Context, method, path string, params ur
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		c.debugResponse(method, fullURL, 0, time.Since(startTime), err)
-		return nil, nil, fmt.Errorf("do request: %w", err)
-	}
-
-	responseBody, readErr := io.ReadAll(resp.Body)
-	if closeErr := resp.Body.Close(); closeErr != nil {
-		c.debugLog("Failed to close response body: %v", closeErr)
+		return nil, fullURL, fmt.Errorf("do request: %w", err)
 	}
+	return resp, fullURL, nil
+}
 
-	if readErr != nil {
-		c.debugResponse(method, fullURL, resp.StatusCode, time.Since(startTime), readErr)
-		return resp, nil, fmt.Errorf("read response body: %w", readErr)
+// responseError builds the error for a failed response, preferring the
+// message the API put in the body.
+func responseError(resp *http.Response, responseBody []byte) error {
+	var apiError struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+		Detail  string `json:"detail"`
 	}
 
-	c.debugResponse(method, fullURL, resp.StatusCode, time.Since(startTime), nil)
-
-	if resp.StatusCode >= 400 {
-		var apiError struct {
-			Error   string `json:"error"`
-			Message string `json:"message"`
-			Detail  string `json:"detail"`
+	if json.Unmarshal(responseBody, &apiError) == nil {
+		errorMsg := apiError.Error
+		if errorMsg == "" {
+			errorMsg = apiError.Message
 		}
-
-		if json.Unmarshal(responseBody, &apiError) == nil {
-			errorMsg := apiError.Error
-			if errorMsg == "" {
-				errorMsg = apiError.Message
-			}
-			if errorMsg == "" {
-				errorMsg = apiError.Detail
-			}
-			if errorMsg == "" {
-				errorMsg = resp.Status
-			}
-
-			err := fmt.Errorf("API error %d: %s", resp.StatusCode, errorMsg)
-			c.debugResponse(method, fullURL, resp.StatusCode, time.Since(startTime), err)
-			return resp, responseBody, err
+		if errorMsg == "" {
+			errorMsg = apiError.Detail
 		}
-
-		err := fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-		c.debugResponse(method, fullURL, resp.StatusCode, time.Since(startTime), err)
-		return resp, responseBody, err
+		if errorMsg == "" {
+			errorMsg = resp.Status
+		}
+		return fmt.Errorf("API error %d: %s", resp.StatusCode, errorMsg)
 	}
 
-	return resp, responseBody, nil
+	return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 }
 
 func (c *Client) EnsureAnonymousToken(ctx context.Context) (string, error) {
@@ -238,7 +259,7 @@ func (c *Client) EnsureAnonymousToken(ctx context.Context) (string, error) {
 
 	if c.cfg != nil && c.cfg.API.Token != "" && c.cfg.User.IsAnonymous {
 		c.debugLog("Adopting anonymous token from config: %s...", c.cfg.API.Token[:min(len(c.cfg.API.Token), 10)])
-		c.SetToken(c.cfg.API.Token)
+		c.setAnonymousToken(c.cfg.API.Token)
 		return c.token, nil
 	}
 
@@ -262,13 +283,59 @@ func (c *Client) EnsureAnonymousToken(ctx context.Context) (string, error) {
 		return "", fmt.Errorf("parse anonymous token response: %w", err)
 	}
 
-	// Persist the new anon token
-	c.isAnonymous = true
-	c.SetToken(authResp.ID)
+	c.setAnonymousToken(authResp.ID)
 	c.debugLog("Anonymous token obtained and saved: %s...", authResp.ID[:min(len(authResp.ID), 10)])
 	return authResp.ID, nil
 }
 
+// setAnonymousToken switches to an anonymous token and saves it. Unlike
+// SetToken it keeps the client anonymous, so the token is never sent as a
+// login.
+func (c *Client) setAnonymousToken(token string) {
+	c.token = token
+	c.isAnonymous = true
+	c.persistToken()
+}
+
+// ValidateAnonymousToken checks that the server still accepts the client
+// as it is set up for anonymous use. It returns ErrStaleAnonymousToken when
+// the server refuses it.
+//
+// Anonymous requests carry no Authorization header; the id only reaches the
+// server as the user_id of a listen. So the check is a listen that names no
+// song: an unknown id is refused with 401 or 403, while a complaint about
+// the missing song means the id itself was accepted, and nothing is
+// recorded either way.
+func (c *Client) ValidateAnonymousToken(ctx context.Context) error {
+	if !c.isAnonymous || c.token == "" {
+		return nil
+	}
+
+	payload := map[string]interface{}{
+		"song":    "",
+		"user_id": c.token,
+	}
+	resp, _, err := c.makeRequest(ctx, "POST", "/music/song/listen/", nil, payload)
+	if c.rejectsAnonymous(resp) {
+		c.debugLog("Server refused anonymous token: %v", err)
+		return ErrStaleAnonymousToken
+	}
+	if resp != nil && resp.StatusCode == http.StatusBadRequest {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("validate anonymous token: %w", err)
+	}
+	return nil
+}
+
+// rejectsAnonymous reports whether a response to an anonymous request says
+// the server no longer accepts the anonymous token.
+func (c *Client) rejectsAnonymous(resp *http.Response) bool {
+	return c.isAnonymous && resp != nil &&
+		(resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden)
+}
+
 func (c *Client) Authenticate(ctx context.Context, token string) error {
 	c.debugLog("Authenticating with token: %s...", token[:min(len(token), 10)])
 
@@ -322,7 +389,11 @@ func (c *Client) GetSongsWithSort(ctx context.Context, page int, search string,
 		params.Set("search", search)
 	}
 	if sortOption != SortDefault {
-		params.Set("sort", string(sortOption))
+		if c.Supports(FeatureSongSort) {
+			params.Set("sort", string(sortOption))
+		} else {
+			c.debugLog("Server does not support sorting, ignoring sort '%s'", sortOption)
+		}
 	}
 
 	_, responseBody, err := c.makeRequest(ctx, "GET", "/music/song/", params, nil)
@@ -330,10 +401,11 @@ func (c *Client) GetSongsWithSort(ctx context.Context, page int, search string,
 		return nil, fmt.Errorf("get songs: %w", err)
 	}
 
-	var result types.SongListResponse
-	if err := json.Unmarshal(responseBody, &result); err != nil {
+	decoded, err := decodePage[types.Song](c, responseBody, "song")
+	if err != nil {
 		return nil, fmt.Errorf("decode songs response: %w", err)
 	}
+	result := types.SongListResponse{Count: decoded.Count, Next: decoded.Next, Previous: decoded.Previous, Results: decoded.Results}
 
 	c.debugLog("Retrieved %d songs (page %d)", len(result.Results), page)
 	return &result, nil
@@ -347,13 +419,13 @@ func (c *Client) GetSong(ctx context.Context, slug string) (*types.Song, error)
 		return nil, fmt.Errorf("get song: %w", err)
 	}
 
-	var song types.Song
-	if err := json.Unmarshal(responseBody, &song); err != nil {
+	song, err := decodeEntity[types.Song](c, responseBody, "song")
+	if err != nil {
 		return nil, fmt.Errorf("decode song response: %w", err)
 	}
 
 	c.debugLog("Retrieved song: %s", song.Name)
-	return &song, nil
+	return song, nil
 }
 
 func (c *Client) GetAlbums(ctx context.Context, page int, search string) (*types.AlbumListResponse, error) {
@@ -372,10 +444,11 @@ func (c *Client) GetAlbums(ctx context.Context, page int, search string) (*types
 		return nil, fmt.Errorf("get albums: %w", err)
 	}
 
-	var result types.AlbumListResponse
-	if err := json.Unmarshal(responseBody, &result); err != nil {
+	decoded, err := decodePage[types.Album](c, responseBody, "album")
+	if err != nil {
 		return nil, fmt.Errorf("decode albums response: %w", err)
 	}
+	result := types.AlbumListResponse{Count: decoded.Count, Next: decoded.Next, Previous: decoded.Previous, Results: decoded.Results}
 
 	c.debugLog("Retrieved %d albums (page %d)", len(result.Results), page)
 	return &result, nil
@@ -389,13 +462,13 @@ func (c *Client) GetAlbum(ctx context.Context, slug string) (*types.Album, error
 		return nil, fmt.Errorf("get album: %w", err)
 	}
 
-	var album types.Album
-	if err := json.Unmarshal(responseBody, &album); err != nil {
+	album, err := decodeEntity[types.Album](c, responseBody, "album")
+	if err != nil {
 		return nil, fmt.Errorf("decode album response: %w", err)
 	}
 
 	c.debugLog("Retrieved album: %s", album.Name)
-	return &album, nil
+	return album, nil
 }
 
 func (c *Client) GetAuthors(ctx context.Context, page int, search string) (*types.AuthorListResponse, error) {
@@ -414,10 +487,11 @@ func (c *Client) GetAuthors(ctx context.Context, page int, search string) (*type
 		return nil, fmt.Errorf("get authors: %w", err)
 	}
 
-	var result types.AuthorListResponse
-	if err := json.Unmarshal(responseBody, &result); err != nil {
+	decoded, err := decodePage[types.Author](c, responseBody, "author")
+	if err != nil {
 		return nil, fmt.Errorf("decode authors response: %w", err)
 	}
+	result := types.AuthorListResponse{Count: decoded.Count, Next: decoded.Next, Previous: decoded.Previous, Results: decoded.Results}
 
 	c.debugLog("Retrieved %d authors (page %d)", len(result.Results), page)
 	return &result, nil
@@ -431,13 +505,13 @@ func (c *Client) GetAuthor(ctx context.Context, slug string) (*types.Author, err
 		return nil, fmt.Errorf("get author: %w", err)
 	}
 
-	var author types.Author
-	if err := json.Unmarshal(responseBody, &author); err != nil {
+	author, err := decodeEntity[types.Author](c, responseBody, "author")
+	if err != nil {
 		return nil, fmt.Errorf("decode author response: %w", err)
 	}
 
 	c.debugLog("Retrieved author: %s", author.Name)
-	return &author, nil
+	return author, nil
 }
 
 func (c *Client) GetPlaylists(ctx context.Context) ([]*types.Playlist, error) {
@@ -448,8 +522,8 @@ func (c *Client) GetPlaylists(ctx context.Context) ([]*types.Playlist, error) {
 		return nil, fmt.Errorf("get playlists: %w", err)
 	}
 
-	var playlists []*types.Playlist
-	if err := json.Unmarshal(responseBody, &playlists); err != nil {
+	playlists, err := decodeList[types.Playlist](c, responseBody, "playlist")
+	if err != nil {
 		return nil, fmt.Errorf("decode playlists response: %w", err)
 	}
 
@@ -465,13 +539,13 @@ func (c *Client) GetPlaylist(ctx context.Context, slug string) (*types.Playlist,
 		return nil, fmt.Errorf("get playlist: %w", err)
 	}
 
-	var playlist types.Playlist
-	if err := json.Unmarshal(responseBody, &playlist); err != nil {
+	playlist, err := decodeEntity[types.Playlist](c, responseBody, "playlist")
+	if err != nil {
 		return nil, fmt.Errorf("decode playlist response: %w", err)
 	}
 
 	c.debugLog("Retrieved playlist: %s (%d songs)", playlist.Name, len(playlist.Songs))
-	return &playlist, nil
+	return playlist, nil
 }
 
 func (c *Client) CreatePlaylist(ctx context.Context, playlist *types.Playlist) error {
@@ -493,6 +567,10 @@ func (c *Client) CreatePlaylist(ctx context.Context, playlist *types.Playlist) e
 func (c *Client) UpdatePlaylist(ctx context.Context, playlist *types.Playlist) error {
 	c.debugLog("Updating playlist: %s", playlist.Name)
 
+	if !c.Supports(FeaturePlaylistEditing) {
+		return fmt.Errorf("update playlist: %w", ErrUnsupported)
+	}
+
 	_, responseBody, err := c.makeRequest(ctx, "PUT", "/music/playlists/"+playlist.Slug, nil, playlist)
 	if err != nil {
 		return fmt.Errorf("update playlist: %w", err)
@@ -560,7 +638,10 @@ func (c *Client) ListenSong(ctx context.Context, slug string, userID string) err
 		}
 	}
 
-	_, _, err := c.makeRequest(ctx, "POST", "/music/song/listen/", nil, payload)
+	resp, _, err := c.makeRequest(ctx, "POST", "/music/song/listen/", nil, payload)
+	if c.rejectsAnonymous(resp) {
+		return fmt.Errorf("listen song: %w", ErrStaleAnonymousToken)
+	}
 	if err != nil {
 		return fmt.Errorf("listen song: %w", err)
 	}
@@ -579,16 +660,91 @@ func (c *Client) SearchAll(ctx context.Context, query string) (*types.SearchResp
 		return nil, fmt.Errorf("search all: %w", err)
 	}
 
-	var result types.SearchResponse
-	if err := json.Unmarshal(responseBody, &result); err != nil {
+	var raw struct {
+		Songs   []json.RawMessage `json:"songs"`
+		Albums  []json.RawMessage `json:"albums"`
+		Authors []json.RawMessage `json:"authors"`
+	}
+	if err := json.Unmarshal(responseBody, &raw); err != nil {
 		return nil, fmt.Errorf("decode search response: %w", err)
 	}
+	result := types.SearchResponse{
+		Songs:   decodeEntities[types.Song](c, raw.Songs, "song"),
+		Albums:  decodeEntities[types.Album](c, raw.Albums, "album"),
+		Authors: decodeEntities[types.Author](c, raw.Authors, "author"),
+	}
 
 	c.debugLog("Search results - Songs: %d, Albums: %d, Authors: %d",
 		len(result.Songs), len(result.Albums), len(result.Authors))
 	return &result, nil
 }
 
+// SearchAllSections runs the same search as SearchAll but decodes the
+// response while it is read, passing each section to onSection as soon as
+// it is complete.
+func (c *Client) SearchAllSections(ctx context.Context, query string, onSection func(*types.SearchResponse)) error {
+	c.debugLog("Searching for: '%s' (by section)", query)
+
+	params := url.Values{}
+	params.Set("search", query)
+
+	startTime := time.Now()
+	resp, fullURL, err := c.openRequest(ctx, "GET", "/music/search/", params, nil)
+	if err != nil {
+		return fmt.Errorf("search all: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		responseBody, _ := io.ReadAll(resp.Body)
+		err := responseError(resp, responseBody)
+		c.debugResponse("GET", fullURL, resp.StatusCode, time.Since(startTime), err)
+		return fmt.Errorf("search all: %w", err)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("decode search response: expected object, got %v (%v)", tok, err)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("decode search response: %w", err)
+		}
+		key, _ := tok.(string)
+
+		var items []json.RawMessage
+		switch key {
+		case "songs", "albums", "authors":
+			if err := dec.Decode(&items); err != nil {
+				return fmt.Errorf("decode search %s: %w", key, err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("decode search response: %w", err)
+			}
+			continue
+		}
+
+		section := &types.SearchResponse{}
+		switch key {
+		case "songs":
+			section.Songs = decodeEntities[types.Song](c, items, "song")
+		case "albums":
+			section.Albums = decodeEntities[types.Album](c, items, "album")
+		case "authors":
+			section.Authors = decodeEntities[types.Author](c, items, "author")
+		}
+		c.debugLog("Search section %s: %d results after %v", key, len(items), time.Since(startTime))
+		onSection(section)
+	}
+
+	c.debugResponse("GET", fullURL, resp.StatusCode, time.Since(startTime), nil)
+	return nil
+}
+
 func (c *Client) IsAnonymous() bool {
 	return c.isAnonymous
 }
@@ -607,6 +763,7 @@ func (c *Client) GetStats() map[string]interface{} {
 		"is_anonymous":    c.isAnonymous,
 		"has_token":       c.token != "",
 		"base_url":        c.baseURL,
+		"server_version":  c.serverVersion(),
 	}
 }
 
@@ -627,13 +784,17 @@ func max(a, b int64) int64 {
 func (c *Client) GetLikedSongs(ctx context.Context) ([]*types.Song, error) {
 	c.debugLog("Getting liked songs...")
 
+	if !c.Supports(FeatureLikedSongs) {
+		return nil, fmt.Errorf("get liked songs: %w", ErrUnsupported)
+	}
+
 	_, responseBody, err := c.makeRequest(ctx, "GET", "/music/song/liked/", nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("get liked songs: %w", err)
 	}
 
-	var songs []*types.Song
-	if err := json.Unmarshal(responseBody, &songs); err != nil {
+	songs, err := decodeList[types.Song](c, responseBody, "song")
+	if err != nil {
 		return nil, fmt.Errorf("decode liked songs response: %w", err)
 	}
 
@@ -644,13 +805,17 @@ func (c *Client) GetLikedSongs(ctx context.Context) ([]*types.Song, error) {
 func (c *Client) GetListenHistory(ctx context.Context) ([]*types.Song, error) {
 	c.debugLog("Getting listen history...")
 
+	if !c.Supports(FeatureListenHistory) {
+		return nil, fmt.Errorf("get listen history: %w", ErrUnsupported)
+	}
+
 	_, responseBody, err := c.makeRequest(ctx, "GET", "/music/song/listened/", nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("get listen history: %w", err)
 	}
 
-	var songs []*types.Song
-	if err := json.Unmarshal(responseBody, &songs); err != nil {
+	songs, err := decodeList[types.Song](c, responseBody, "song")
+	if err != nil {
 		return nil, fmt.Errorf("decode listen history response: %w", err)
 	}
 
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+// anonServer knows the anonymous ids it created and, like the real API,
+// refuses listens from any other id.
+type anonServer struct {
+	mu    sync.Mutex
+	known map[string]bool
+	next  int
+}
+
+func (s *anonServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/music/anon/create/":
+		s.next++
+		id := fmt.Sprintf("anon-%d", s.next)
+		s.known[id] = true
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	case "/music/song/listen/":
+		var body struct {
+			Song   string `json:"song"`
+			UserID string `json:"user_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.Header.Get("Authorization") != "" {
+			http.Error(w, `{"detail":"unexpected credentials"}`, http.StatusBadRequest)
+			return
+		}
+		if !s.known[body.UserID] {
+			http.Error(w, `{"detail":"unknown user"}`, http.StatusForbidden)
+			return
+		}
+		if body.Song == "" {
+			http.Error(w, `{"song":["This field may not be blank."]}`, http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestValidateAnonymousTokenRotatesStaleToken(t *testing.T) {
+	server := &anonServer{known: map[string]bool{}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	cfg := &config.Config{Embedded: true}
+	cfg.API.BaseURL = ts.URL
+	cfg.API.RateLimit.RequestsPerSecond = 100
+	cfg.API.RateLimit.BurstSize = 10
+	cfg.API.Token = "forgotten-id"
+	cfg.User.IsAnonymous = true
+	client := NewClient(cfg)
+	ctx := context.Background()
+
+	err := client.ValidateAnonymousToken(ctx)
+	if !errors.Is(err, ErrStaleAnonymousToken) {
+		t.Fatalf("validate unknown id: %v, want ErrStaleAnonymousToken", err)
+	}
+
+	// Rotate the way the app does when the token went stale.
+	client.SetToken("")
+	id, err := client.EnsureAnonymousToken(ctx)
+	if err != nil {
+		t.Fatalf("create new anonymous token: %v", err)
+	}
+	if id == "" || id == "forgotten-id" {
+		t.Fatalf("token not rotated, got %q", id)
+	}
+	if client.GetToken() != id || cfg.API.Token != id {
+		t.Fatalf("client token %q, config token %q, want %q", client.GetToken(), cfg.API.Token, id)
+	}
+
+	if err := client.ValidateAnonymousToken(ctx); err != nil {
+		t.Fatalf("validate new id: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// knownFieldsCache maps a reflect.Type to the JSON keys it decodes.
+var knownFieldsCache sync.Map
+
+// entityPage is a paginated list whose results are decoded one by one.
+type entityPage[T any] struct {
+	Count    int
+	Next     *string
+	Previous *string
+	Results  []*T
+}
+
+// decodeEntity decodes a single API object tolerantly: unknown fields are
+// reported once, fields whose type changed on the server are dropped instead
+// of failing the whole object, and the result is validated.
+func decodeEntity[T any](c *Client, data []byte, entity string) (*T, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", entity, err)
+	}
+
+	known := knownFields(reflect.TypeFor[T]())
+	for key := range fields {
+		if !known[key] {
+			c.schemaNotice(entity, key, "ignoring unknown field")
+		}
+	}
+
+	value := new(T)
+	if err := json.Unmarshal(data, value); err != nil {
+		for key, raw := range fields {
+			if !known[key] {
+				continue
+			}
+			single, _ := json.Marshal(map[string]json.RawMessage{key: raw})
+			if fieldErr := json.Unmarshal(single, new(T)); fieldErr != nil {
+				c.schemaNotice(entity, key, "dropping field with unexpected type")
+				delete(fields, key)
+			}
+		}
+
+		cleaned, _ := json.Marshal(fields)
+		value = new(T)
+		if err := json.Unmarshal(cleaned, value); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", entity, err)
+		}
+	}
+
+	if err := validateEntity(value); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", entity, err)
+	}
+	return value, nil
+}
+
+// decodeEntities decodes each element of a JSON array on its own, so one
+// malformed object does not take the rest of the list down with it.
+func decodeEntities[T any](c *Client, items []json.RawMessage, entity string) []*T {
+	results := make([]*T, 0, len(items))
+	for _, raw := range items {
+		value, err := decodeEntity[T](c, raw, entity)
+		if err != nil {
+			c.debugLog("Skipping %s: %v", entity, err)
+			continue
+		}
+		results = append(results, value)
+	}
+	if skipped := len(items) - len(results); skipped > 0 {
+		log.Printf("[API] Skipped %d of %d %s entries that could not be decoded", skipped, len(items), entity)
+	}
+	return results
+}
+
+// decodeList decodes a bare JSON array of entities.
+func decodeList[T any](c *Client, data []byte, entity string) ([]*T, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return decodeEntities[T](c, items, entity), nil
+}
+
+// decodePage decodes a paginated list response.
+func decodePage[T any](c *Client, data []byte, entity string) (*entityPage[T], error) {
+	var raw struct {
+		Count    int               `json:"count"`
+		Next     *string           `json:"next"`
+		Previous *string           `json:"previous"`
+		Results  []json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return &entityPage[T]{
+		Count:    raw.Count,
+		Next:     raw.Next,
+		Previous: raw.Previous,
+		Results:  decodeEntities[T](c, raw.Results, entity),
+	}, nil
+}
+
+// schemaNotice logs a schema mismatch the first time it is seen.
+func (c *Client) schemaNotice(entity, field, message string) {
+	if _, seen := c.schemaWarnings.LoadOrStore(entity+"."+field, true); seen {
+		return
+	}
+	log.Printf("[API] %s: %s %q", entity, message, field)
+}
+
+func knownFields(t reflect.Type) map[string]bool {
+	if cached, ok := knownFieldsCache.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+
+	fields := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		fields[name] = true
+	}
+
+	knownFieldsCache.Store(t, fields)
+	return fields
+}
+
+// validateEntity rejects objects that cannot be used without a slug and
+// drops nested entries that fail the same checks.
+func validateEntity(value any) error {
+	switch v := value.(type) {
+	case *types.Song:
+		if v.Slug == "" {
+			return errors.New("missing slug")
+		}
+		if v.Name == "" {
+			return errors.New("missing name")
+		}
+		v.Authors = validAuthors(v.Authors)
+		if v.Album != nil && v.Album.Slug == "" {
+			v.Album = nil
+		}
+	case *types.Album:
+		if v.Slug == "" {
+			return errors.New("missing slug")
+		}
+		v.Songs = validSongs(v.Songs)
+		v.Artists = validAuthors(v.Artists)
+	case *types.Author:
+		if v.Slug == "" {
+			return errors.New("missing slug")
+		}
+		v.Songs = validSongs(v.Songs)
+	case *types.Playlist:
+		if v.Slug == "" {
+			return errors.New("missing slug")
+		}
+		v.Songs = validSongs(v.Songs)
+	}
+	return nil
+}
+
+func validSongs(songs []*types.Song) []*types.Song {
+	kept := songs[:0]
+	for _, song := range songs {
+		if song != nil && validateEntity(song) == nil {
+			kept = append(kept, song)
+		}
+	}
+	return kept
+}
+
+func validAuthors(authors []*types.Author) []*types.Author {
+	kept := authors[:0]
+	for _, author := range authors {
+		if author != nil && author.Slug != "" {
+			kept = append(kept, author)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// ChangeLister is implemented by backends that can list only the records
+// changed after a time, so a sync does not have to fetch the whole library.
+// Backends without the feature return ErrUnsupported.
+type ChangeLister interface {
+	GetSongsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.SongListResponse, error)
+	GetAlbumsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.AlbumListResponse, error)
+	GetAuthorsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.AuthorListResponse, error)
+}
+
+// updatedAfterParams asks for one page of the records changed after since.
+func updatedAfterParams(page int, since time.Time) url.Values {
+	params := url.Values{}
+	if page > 0 {
+		params.Set("page", strconv.Itoa(page))
+	}
+	params.Set("updated_after", since.UTC().Format(time.RFC3339))
+	return params
+}
+
+func (c *Client) GetSongsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.SongListResponse, error) {
+	if !c.Supports(FeatureUpdatedAfter) {
+		return nil, ErrUnsupported
+	}
+	c.debugLog("Getting songs updated after %s - page: %d", since.Format(time.RFC3339), page)
+
+	_, responseBody, err := c.makeRequest(ctx, "GET", "/music/song/", updatedAfterParams(page, since), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get changed songs: %w", err)
+	}
+
+	decoded, err := decodePage[types.Song](c, responseBody, "song")
+	if err != nil {
+		return nil, fmt.Errorf("decode songs response: %w", err)
+	}
+	return &types.SongListResponse{Count: decoded.Count, Next: decoded.Next, Previous: decoded.Previous, Results: decoded.Results}, nil
+}
+
+func (c *Client) GetAlbumsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.AlbumListResponse, error) {
+	if !c.Supports(FeatureUpdatedAfter) {
+		return nil, ErrUnsupported
+	}
+	c.debugLog("Getting albums updated after %s - page: %d", since.Format(time.RFC3339), page)
+
+	_, responseBody, err := c.makeRequest(ctx, "GET", "/music/albums/", updatedAfterParams(page, since), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get changed albums: %w", err)
+	}
+
+	decoded, err := decodePage[types.Album](c, responseBody, "album")
+	if err != nil {
+		return nil, fmt.Errorf("decode albums response: %w", err)
+	}
+	return &types.AlbumListResponse{Count: decoded.Count, Next: decoded.Next, Previous: decoded.Previous, Results: decoded.Results}, nil
+}
+
+func (c *Client) GetAuthorsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.AuthorListResponse, error) {
+	if !c.Supports(FeatureUpdatedAfter) {
+		return nil, ErrUnsupported
+	}
+	c.debugLog("Getting authors updated after %s - page: %d", since.Format(time.RFC3339), page)
+
+	_, responseBody, err := c.makeRequest(ctx, "GET", "/music/authors/", updatedAfterParams(page, since), nil)
+	if err != nil {
+		return nil, fmt.Errorf("get changed authors: %w", err)
+	}
+
+	decoded, err := decodePage[types.Author](c, responseBody, "author")
+	if err != nil {
+		return nil, fmt.Errorf("decode authors response: %w", err)
+	}
+	return &types.AuthorListResponse{Count: decoded.Count, Next: decoded.Next, Previous: decoded.Previous, Results: decoded.Results}, nil
+}
+
+func (s *Switch) GetSongsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.SongListResponse, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	if lister, ok := s.backend.(ChangeLister); ok {
+		return lister.GetSongsUpdatedAfter(ctx, page, since)
+	}
+	return nil, ErrUnsupported
+}
+
+func (s *Switch) GetAlbumsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.AlbumListResponse, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	if lister, ok := s.backend.(ChangeLister); ok {
+		return lister.GetAlbumsUpdatedAfter(ctx, page, since)
+	}
+	return nil, ErrUnsupported
+}
+
+func (s *Switch) GetAuthorsUpdatedAfter(ctx context.Context, page int, since time.Time) (*types.AuthorListResponse, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	if lister, ok := s.backend.(ChangeLister); ok {
+		return lister.GetAuthorsUpdatedAfter(ctx, page, since)
+	}
+	return nil, ErrUnsupported
+}
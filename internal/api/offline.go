@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// ErrOffline is returned instead of contacting the server while offline
+// mode is on.
+var ErrOffline = errors.New("offline mode is on")
+
+// Switch is a MusicBackend that can be taken offline. While offline, every
+// call that would reach the server fails at once with ErrOffline, so callers
+// fall back to what is stored locally and keep unsent changes for later.
+// Token and capability queries keep answering from memory.
+type Switch struct {
+	backend MusicBackend
+	offline atomic.Bool
+}
+
+// NewSwitch wraps backend, starting offline when offline is set.
+func NewSwitch(backend MusicBackend, offline bool) *Switch {
+	s := &Switch{backend: backend}
+	s.offline.Store(offline)
+	return s
+}
+
+// SetOffline turns offline mode on or off.
+func (s *Switch) SetOffline(offline bool) { s.offline.Store(offline) }
+
+// Offline reports whether offline mode is on.
+func (s *Switch) Offline() bool { return s.offline.Load() }
+
+// Backend returns the wrapped backend.
+func (s *Switch) Backend() MusicBackend { return s.backend }
+
+func (s *Switch) GetSongs(ctx context.Context, page int, search string) (*types.SongListResponse, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetSongs(ctx, page, search)
+}
+
+func (s *Switch) GetSongsWithSort(ctx context.Context, page int, search string, sortOption SortOption) (*types.SongListResponse, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetSongsWithSort(ctx, page, search, sortOption)
+}
+
+func (s *Switch) GetSong(ctx context.Context, slug string) (*types.Song, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetSong(ctx, slug)
+}
+
+func (s *Switch) GetAlbums(ctx context.Context, page int, search string) (*types.AlbumListResponse, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetAlbums(ctx, page, search)
+}
+
+func (s *Switch) GetAlbum(ctx context.Context, slug string) (*types.Album, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetAlbum(ctx, slug)
+}
+
+func (s *Switch) GetAuthors(ctx context.Context, page int, search string) (*types.AuthorListResponse, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetAuthors(ctx, page, search)
+}
+
+func (s *Switch) GetAuthor(ctx context.Context, slug string) (*types.Author, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetAuthor(ctx, slug)
+}
+
+func (s *Switch) GetPlaylists(ctx context.Context) ([]*types.Playlist, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetPlaylists(ctx)
+}
+
+func (s *Switch) GetPlaylist(ctx context.Context, slug string) (*types.Playlist, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetPlaylist(ctx, slug)
+}
+
+func (s *Switch) CreatePlaylist(ctx context.Context, playlist *types.Playlist) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	return s.backend.CreatePlaylist(ctx, playlist)
+}
+
+func (s *Switch) UpdatePlaylist(ctx context.Context, playlist *types.Playlist) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	return s.backend.UpdatePlaylist(ctx, playlist)
+}
+
+func (s *Switch) DeletePlaylist(ctx context.Context, slug string) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	return s.backend.DeletePlaylist(ctx, slug)
+}
+
+func (s *Switch) SearchAll(ctx context.Context, query string) (*types.SearchResponse, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.SearchAll(ctx, query)
+}
+
+// SearchAllSections streams sections when the wrapped backend can, and
+// hands over the whole response as one section otherwise.
+func (s *Switch) SearchAllSections(ctx context.Context, query string, onSection func(*types.SearchResponse)) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	if searcher, ok := s.backend.(SectionSearcher); ok {
+		return searcher.SearchAllSections(ctx, query, onSection)
+	}
+	result, err := s.backend.SearchAll(ctx, query)
+	if err == nil && result != nil {
+		onSection(result)
+	}
+	return err
+}
+
+func (s *Switch) ListenSong(ctx context.Context, slug string, userID string) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	return s.backend.ListenSong(ctx, slug, userID)
+}
+
+func (s *Switch) Authenticate(ctx context.Context, token string) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	return s.backend.Authenticate(ctx, token)
+}
+
+func (s *Switch) EnsureAnonymousToken(ctx context.Context) (string, error) {
+	if s.Offline() {
+		return "", ErrOffline
+	}
+	return s.backend.EnsureAnonymousToken(ctx)
+}
+
+// ValidateAnonymousToken checks the anonymous token when the wrapped
+// backend can.
+func (s *Switch) ValidateAnonymousToken(ctx context.Context) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	if validator, ok := s.backend.(AnonymousValidator); ok {
+		return validator.ValidateAnonymousToken(ctx)
+	}
+	return ErrUnsupported
+}
+
+// LikeSong likes a song on the server when the wrapped backend keeps likes.
+func (s *Switch) LikeSong(ctx context.Context, slug string) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	if liker, ok := s.backend.(Liker); ok {
+		return liker.LikeSong(ctx, slug)
+	}
+	return ErrUnsupported
+}
+
+// DislikeSong removes a like on the server when the wrapped backend keeps
+// likes.
+func (s *Switch) DislikeSong(ctx context.Context, slug string) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	if liker, ok := s.backend.(Liker); ok {
+		return liker.DislikeSong(ctx, slug)
+	}
+	return ErrUnsupported
+}
+
+func (s *Switch) GetCurrentUser(ctx context.Context) (*types.User, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.GetCurrentUser(ctx)
+}
+
+func (s *Switch) Logout(ctx context.Context) error {
+	if s.Offline() {
+		return ErrOffline
+	}
+	return s.backend.Logout(ctx)
+}
+
+func (s *Switch) ProbeServer(ctx context.Context) (*ServerInfo, error) {
+	if s.Offline() {
+		return nil, ErrOffline
+	}
+	return s.backend.ProbeServer(ctx)
+}
+
+func (s *Switch) IsAnonymous() bool            { return s.backend.IsAnonymous() }
+func (s *Switch) GetToken() string             { return s.backend.GetToken() }
+func (s *Switch) SetToken(token string)        { s.backend.SetToken(token) }
+func (s *Switch) Supports(feature string) bool { return s.backend.Supports(feature) }
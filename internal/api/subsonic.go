@@ -0,0 +1,801 @@
+package api
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"golang.org/x/time/rate"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	subsonicAPIVersion = "1.16.1"
+	subsonicClientName = "AMP"
+	subsonicPageSize   = 50
+	subsonicCoverSize  = 300
+)
+
+// errNotSubsonic means the base URL answered with something other than a
+// Subsonic response, usually because it points at the wrong server.
+var errNotSubsonic = errors.New("not a Subsonic server")
+
+// subsonicFeatures are the optional features every Subsonic server offers.
+var subsonicFeatures = []string{FeaturePlaylistEditing, FeatureLikedSongs}
+
+// SubsonicClient talks to Subsonic and OpenSubsonic servers such as Navidrome
+// and Airsonic. Subsonic ids are used as slugs.
+type SubsonicClient struct {
+	baseURL    string
+	httpClient *retryablehttp.Client
+	limiter    *rate.Limiter
+	userAgent  string
+	debug      bool
+
+	mu       sync.RWMutex
+	username string
+	password string
+
+	infoMu     sync.RWMutex
+	serverInfo *ServerInfo
+
+	cfg *config.Config
+}
+
+func NewSubsonicClient(cfg *config.Config) *SubsonicClient {
+	retryClient := retryablehttp.NewClient()
+	retryClient.RetryMax = cfg.API.Retries
+	retryClient.HTTPClient = netutil.NewClient(cfg, netutil.ProfileAPI)
+	retryClient.Logger = nil
+
+	if cfg.Debug {
+		retryClient.Logger = &debugLogger{}
+	}
+
+	c := &SubsonicClient{
+		baseURL:    strings.TrimSuffix(cfg.API.BaseURL, "/"),
+		httpClient: retryClient,
+		limiter:    rate.NewLimiter(rate.Limit(cfg.API.RateLimit.RequestsPerSecond), cfg.API.RateLimit.BurstSize),
+		userAgent:  cfg.API.UserAgent,
+		debug:      cfg.Debug,
+		username:   cfg.API.Subsonic.Username,
+		password:   cfg.API.Subsonic.Password,
+		cfg:        cfg,
+	}
+
+	c.debugLog("Subsonic client initialized - Base URL: %s, User: %s", c.baseURL, c.username)
+	return c
+}
+
+func (c *SubsonicClient) debugLog(format string, args ...interface{}) {
+	if c.debug {
+		log.Printf("[SUBSONIC] "+format, args...)
+	}
+}
+
+// authParams returns the token authentication parameters for one request.
+// API calls get a fresh salt, as the Subsonic API recommends. Stream and
+// cover URLs are stored and used as cache keys, so they get a stable salt
+// that only changes with the server or user.
+func (c *SubsonicClient) authParams(stable bool) url.Values {
+	c.mu.RLock()
+	username, password := c.username, c.password
+	c.mu.RUnlock()
+
+	var salt string
+	if stable {
+		sum := md5.Sum([]byte(c.baseURL + "|" + username))
+		salt = hex.EncodeToString(sum[:6])
+	} else {
+		saltBytes := make([]byte, 6)
+		_, _ = rand.Read(saltBytes)
+		salt = hex.EncodeToString(saltBytes)
+	}
+	sum := md5.Sum([]byte(password + salt))
+
+	params := url.Values{}
+	params.Set("u", username)
+	params.Set("t", hex.EncodeToString(sum[:]))
+	params.Set("s", salt)
+	params.Set("v", subsonicAPIVersion)
+	params.Set("c", subsonicClientName)
+	params.Set("f", "json")
+	return params
+}
+
+func (c *SubsonicClient) endpoint(method string, params url.Values) string {
+	return c.buildURL(method, params, false)
+}
+
+func (c *SubsonicClient) mediaURL(method string, params url.Values) string {
+	return c.buildURL(method, params, true)
+}
+
+func (c *SubsonicClient) buildURL(method string, params url.Values, stable bool) string {
+	query := c.authParams(stable)
+	for key, values := range params {
+		query[key] = values
+	}
+	return c.baseURL + "/rest/" + method + "?" + query.Encode()
+}
+
+// subsonicEnvelope is the part of every response that reports success.
+type subsonicEnvelope struct {
+	Status        string `json:"status"`
+	Version       string `json:"version"`
+	Type          string `json:"type"`
+	ServerVersion string `json:"serverVersion"`
+	OpenSubsonic  bool   `json:"openSubsonic"`
+	Error         *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call performs a Subsonic API method and decodes the response body into
+// result, which may be nil.
+func (c *SubsonicClient) call(ctx context.Context, method string, params url.Values, result any) (*subsonicEnvelope, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	c.debugLog("GET %s", method)
+	req, err := retryablehttp.NewRequestWithContext(ctx, "GET", c.endpoint(method, params), nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	var wrapper struct {
+		Response json.RawMessage `json:"subsonic-response"`
+	}
+	if err := json.Unmarshal(body, &wrapper); err != nil || wrapper.Response == nil {
+		return nil, fmt.Errorf("decode %s response: %w", method, errNotSubsonic)
+	}
+
+	var envelope subsonicEnvelope
+	if err := json.Unmarshal(wrapper.Response, &envelope); err != nil {
+		return nil, fmt.Errorf("decode %s response: %w", method, err)
+	}
+	if envelope.Status != "ok" {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("subsonic error %d: %s", envelope.Error.Code, envelope.Error.Message)
+		}
+		return nil, fmt.Errorf("subsonic %s failed", method)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(wrapper.Response, result); err != nil {
+			return nil, fmt.Errorf("decode %s response: %w", method, err)
+		}
+	}
+	return &envelope, nil
+}
+
+type subsonicArtistRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type subsonicSong struct {
+	ID        string              `json:"id"`
+	Title     string              `json:"title"`
+	Album     string              `json:"album"`
+	AlbumID   string              `json:"albumId"`
+	Artist    string              `json:"artist"`
+	ArtistID  string              `json:"artistId"`
+	Artists   []subsonicArtistRef `json:"artists"`
+	CoverArt  string              `json:"coverArt"`
+	Duration  int                 `json:"duration"`
+	PlayCount int                 `json:"playCount"`
+	Genre     string              `json:"genre"`
+	Starred   string              `json:"starred"`
+	Created   string              `json:"created"`
+}
+
+type subsonicAlbum struct {
+	ID       string              `json:"id"`
+	Name     string              `json:"name"`
+	Artist   string              `json:"artist"`
+	ArtistID string              `json:"artistId"`
+	Artists  []subsonicArtistRef `json:"artists"`
+	CoverArt string              `json:"coverArt"`
+	Genre    string              `json:"genre"`
+	Songs    []subsonicSong      `json:"song"`
+}
+
+type subsonicArtist struct {
+	ID       string          `json:"id"`
+	Name     string          `json:"name"`
+	CoverArt string          `json:"coverArt"`
+	Albums   []subsonicAlbum `json:"album"`
+}
+
+type subsonicPlaylist struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Owner     string         `json:"owner"`
+	Public    bool           `json:"public"`
+	SongCount int            `json:"songCount"`
+	CoverArt  string         `json:"coverArt"`
+	Created   string         `json:"created"`
+	Changed   string         `json:"changed"`
+	Entries   []subsonicSong `json:"entry"`
+}
+
+type subsonicSearchResult struct {
+	SearchResult3 struct {
+		Artists []subsonicArtist `json:"artist"`
+		Albums  []subsonicAlbum  `json:"album"`
+		Songs   []subsonicSong   `json:"song"`
+	} `json:"searchResult3"`
+}
+
+// streamURL asks for MP3, the format the player decodes, so servers
+// transcode anything else on the fly.
+func (c *SubsonicClient) streamURL(id string) string {
+	params := url.Values{}
+	params.Set("id", id)
+	params.Set("format", "mp3")
+	return c.mediaURL("stream", params)
+}
+
+func (c *SubsonicClient) coverURL(id string, size int) *string {
+	if id == "" {
+		return nil
+	}
+	params := url.Values{}
+	params.Set("id", id)
+	if size > 0 {
+		params.Set("size", strconv.Itoa(size))
+	}
+	u := c.mediaURL("getCoverArt", params)
+	return &u
+}
+
+func (c *SubsonicClient) toSong(s subsonicSong) *types.Song {
+	song := &types.Song{
+		Slug:         s.ID,
+		Name:         s.Title,
+		File:         c.streamURL(s.ID),
+		Image:        c.coverURL(s.CoverArt, 0),
+		ImageCropped: c.coverURL(s.CoverArt, subsonicCoverSize),
+		Length:       s.Duration,
+		Played:       s.PlayCount,
+		AlbumSlug:    s.AlbumID,
+		Authors:      c.toAuthorRefs(s.Artists, s.ArtistID, s.Artist),
+		CreatedAt:    parseSubsonicTime(s.Created),
+	}
+	if s.Starred != "" {
+		liked := true
+		song.Liked = &liked
+	}
+	if s.AlbumID != "" {
+		song.Album = &types.Album{
+			Slug:         s.AlbumID,
+			Name:         s.Album,
+			Image:        song.Image,
+			ImageCropped: song.ImageCropped,
+		}
+	}
+	if s.Genre != "" {
+		genre := s.Genre
+		song.Meta = &types.Meta{Genre: &genre}
+	}
+	return song
+}
+
+func (c *SubsonicClient) toSongs(items []subsonicSong) []*types.Song {
+	songs := make([]*types.Song, 0, len(items))
+	for _, item := range items {
+		if item.ID != "" {
+			songs = append(songs, c.toSong(item))
+		}
+	}
+	return songs
+}
+
+func (c *SubsonicClient) toAuthorRefs(refs []subsonicArtistRef, id, name string) []*types.Author {
+	var authors []*types.Author
+	for _, ref := range refs {
+		if ref.ID != "" {
+			authors = append(authors, &types.Author{Slug: ref.ID, Name: ref.Name})
+		}
+	}
+	if len(authors) == 0 && id != "" {
+		authors = append(authors, &types.Author{Slug: id, Name: name})
+	}
+	return authors
+}
+
+func (c *SubsonicClient) toAlbum(a subsonicAlbum) *types.Album {
+	album := &types.Album{
+		Slug:         a.ID,
+		Name:         a.Name,
+		Image:        c.coverURL(a.CoverArt, 0),
+		ImageCropped: c.coverURL(a.CoverArt, subsonicCoverSize),
+		Artists:      c.toAuthorRefs(a.Artists, a.ArtistID, a.Artist),
+		AlbumArtist:  a.Artist,
+		Songs:        c.toSongs(a.Songs),
+	}
+	if a.Genre != "" {
+		genre := a.Genre
+		album.Meta = &types.Meta{Genre: &genre}
+	}
+	return album
+}
+
+func (c *SubsonicClient) toAuthor(a subsonicArtist) *types.Author {
+	author := &types.Author{
+		Slug:         a.ID,
+		Name:         a.Name,
+		Image:        c.coverURL(a.CoverArt, 0),
+		ImageCropped: c.coverURL(a.CoverArt, subsonicCoverSize),
+	}
+	for _, album := range a.Albums {
+		author.Albums = append(author.Albums, c.toAlbum(album))
+	}
+	return author
+}
+
+func (c *SubsonicClient) toPlaylist(p subsonicPlaylist) *types.Playlist {
+	playlist := &types.Playlist{
+		Slug:      p.ID,
+		Name:      p.Name,
+		Private:   !p.Public,
+		Length:    p.SongCount,
+		Songs:     c.toSongs(p.Entries),
+		CreatedAt: parseSubsonicTime(p.Created),
+		UpdatedAt: parseSubsonicTime(p.Changed),
+	}
+	if p.Owner != "" {
+		playlist.Creator = &types.User{Username: p.Owner}
+	}
+	if cover := c.coverURL(p.CoverArt, subsonicCoverSize); cover != nil {
+		playlist.Images = []string{*cover}
+	}
+	return playlist
+}
+
+func parseSubsonicTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// pageOffset converts AMP's 1-based pages into a Subsonic offset.
+func pageOffset(page int) int {
+	if page <= 1 {
+		return 0
+	}
+	return (page - 1) * subsonicPageSize
+}
+
+// nextPage returns a non-nil marker when a full page suggests more results.
+func nextPage(page, count int) *string {
+	if count < subsonicPageSize {
+		return nil
+	}
+	if page < 1 {
+		page = 1
+	}
+	next := strconv.Itoa(page + 1)
+	return &next
+}
+
+func (c *SubsonicClient) search(ctx context.Context, query string, songs, albums, artists, offset int) (*subsonicSearchResult, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("songCount", strconv.Itoa(songs))
+	params.Set("albumCount", strconv.Itoa(albums))
+	params.Set("artistCount", strconv.Itoa(artists))
+	params.Set("songOffset", strconv.Itoa(offset))
+	params.Set("albumOffset", strconv.Itoa(offset))
+	params.Set("artistOffset", strconv.Itoa(offset))
+
+	var result subsonicSearchResult
+	if _, err := c.call(ctx, "search3", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *SubsonicClient) GetSongs(ctx context.Context, page int, search string) (*types.SongListResponse, error) {
+	return c.GetSongsWithSort(ctx, page, search, SortDefault)
+}
+
+// GetSongsWithSort lists songs through search3; an empty query lists the
+// whole library on Navidrome and other OpenSubsonic servers. Subsonic has
+// no song ordering, so the sort option is ignored.
+func (c *SubsonicClient) GetSongsWithSort(ctx context.Context, page int, search string, sortOption SortOption) (*types.SongListResponse, error) {
+	offset := pageOffset(page)
+	result, err := c.search(ctx, search, subsonicPageSize, 0, 0, offset)
+	if err != nil {
+		return nil, fmt.Errorf("get songs: %w", err)
+	}
+
+	songs := c.toSongs(result.SearchResult3.Songs)
+	return &types.SongListResponse{
+		Count:   offset + len(songs),
+		Next:    nextPage(page, len(result.SearchResult3.Songs)),
+		Results: songs,
+	}, nil
+}
+
+func (c *SubsonicClient) GetSong(ctx context.Context, slug string) (*types.Song, error) {
+	var result struct {
+		Song subsonicSong `json:"song"`
+	}
+	if _, err := c.call(ctx, "getSong", url.Values{"id": {slug}}, &result); err != nil {
+		return nil, fmt.Errorf("get song: %w", err)
+	}
+	return c.toSong(result.Song), nil
+}
+
+func (c *SubsonicClient) GetAlbums(ctx context.Context, page int, search string) (*types.AlbumListResponse, error) {
+	offset := pageOffset(page)
+
+	var items []subsonicAlbum
+	if search != "" {
+		result, err := c.search(ctx, search, 0, subsonicPageSize, 0, offset)
+		if err != nil {
+			return nil, fmt.Errorf("get albums: %w", err)
+		}
+		items = result.SearchResult3.Albums
+	} else {
+		params := url.Values{}
+		params.Set("type", "alphabeticalByName")
+		params.Set("size", strconv.Itoa(subsonicPageSize))
+		params.Set("offset", strconv.Itoa(offset))
+
+		var result struct {
+			AlbumList2 struct {
+				Albums []subsonicAlbum `json:"album"`
+			} `json:"albumList2"`
+		}
+		if _, err := c.call(ctx, "getAlbumList2", params, &result); err != nil {
+			return nil, fmt.Errorf("get albums: %w", err)
+		}
+		items = result.AlbumList2.Albums
+	}
+
+	albums := make([]*types.Album, 0, len(items))
+	for _, item := range items {
+		albums = append(albums, c.toAlbum(item))
+	}
+	return &types.AlbumListResponse{
+		Count:   offset + len(albums),
+		Next:    nextPage(page, len(items)),
+		Results: albums,
+	}, nil
+}
+
+func (c *SubsonicClient) GetAlbum(ctx context.Context, slug string) (*types.Album, error) {
+	var result struct {
+		Album subsonicAlbum `json:"album"`
+	}
+	if _, err := c.call(ctx, "getAlbum", url.Values{"id": {slug}}, &result); err != nil {
+		return nil, fmt.Errorf("get album: %w", err)
+	}
+	return c.toAlbum(result.Album), nil
+}
+
+// GetAuthors pages through the artist index locally, since getArtists
+// returns every artist at once.
+func (c *SubsonicClient) GetAuthors(ctx context.Context, page int, search string) (*types.AuthorListResponse, error) {
+	offset := pageOffset(page)
+
+	var items []subsonicArtist
+	if search != "" {
+		result, err := c.search(ctx, search, 0, 0, subsonicPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("get authors: %w", err)
+		}
+		items = result.SearchResult3.Artists
+	} else {
+		var result struct {
+			Artists struct {
+				Index []struct {
+					Artists []subsonicArtist `json:"artist"`
+				} `json:"index"`
+			} `json:"artists"`
+		}
+		if _, err := c.call(ctx, "getArtists", nil, &result); err != nil {
+			return nil, fmt.Errorf("get authors: %w", err)
+		}
+
+		var all []subsonicArtist
+		for _, index := range result.Artists.Index {
+			all = append(all, index.Artists...)
+		}
+		if offset < len(all) {
+			items = all[offset:min(offset+subsonicPageSize, len(all))]
+		}
+	}
+
+	authors := make([]*types.Author, 0, len(items))
+	for _, item := range items {
+		authors = append(authors, c.toAuthor(item))
+	}
+	return &types.AuthorListResponse{
+		Count:   offset + len(authors),
+		Next:    nextPage(page, len(items)),
+		Results: authors,
+	}, nil
+}
+
+// GetAuthor returns the artist with their albums and the songs on them.
+func (c *SubsonicClient) GetAuthor(ctx context.Context, slug string) (*types.Author, error) {
+	var result struct {
+		Artist subsonicArtist `json:"artist"`
+	}
+	if _, err := c.call(ctx, "getArtist", url.Values{"id": {slug}}, &result); err != nil {
+		return nil, fmt.Errorf("get author: %w", err)
+	}
+
+	author := c.toAuthor(result.Artist)
+	for i, album := range author.Albums {
+		full, err := c.GetAlbum(ctx, album.Slug)
+		if err != nil {
+			c.debugLog("Failed to load album %s of %s: %v", album.Slug, slug, err)
+			continue
+		}
+		author.Albums[i] = full
+		author.Songs = append(author.Songs, full.Songs...)
+	}
+	return author, nil
+}
+
+func (c *SubsonicClient) GetPlaylists(ctx context.Context) ([]*types.Playlist, error) {
+	var result struct {
+		Playlists struct {
+			Playlists []subsonicPlaylist `json:"playlist"`
+		} `json:"playlists"`
+	}
+	if _, err := c.call(ctx, "getPlaylists", nil, &result); err != nil {
+		return nil, fmt.Errorf("get playlists: %w", err)
+	}
+
+	playlists := make([]*types.Playlist, 0, len(result.Playlists.Playlists))
+	for _, item := range result.Playlists.Playlists {
+		playlists = append(playlists, c.toPlaylist(item))
+	}
+	return playlists, nil
+}
+
+func (c *SubsonicClient) GetPlaylist(ctx context.Context, slug string) (*types.Playlist, error) {
+	var result struct {
+		Playlist subsonicPlaylist `json:"playlist"`
+	}
+	if _, err := c.call(ctx, "getPlaylist", url.Values{"id": {slug}}, &result); err != nil {
+		return nil, fmt.Errorf("get playlist: %w", err)
+	}
+	return c.toPlaylist(result.Playlist), nil
+}
+
+// CreatePlaylist creates playlist with its songs on the server and fills in
+// the server's copy, which has the id the server gave it.
+func (c *SubsonicClient) CreatePlaylist(ctx context.Context, playlist *types.Playlist) error {
+	params := url.Values{}
+	params.Set("name", playlist.Name)
+	for _, song := range playlist.Songs {
+		if song != nil {
+			params.Add("songId", song.Slug)
+		}
+	}
+	var result struct {
+		Playlist subsonicPlaylist `json:"playlist"`
+	}
+	if _, err := c.call(ctx, "createPlaylist", params, &result); err != nil {
+		return fmt.Errorf("create playlist: %w", err)
+	}
+	if result.Playlist.ID == "" {
+		// API 1.14 and later, which every request asks for, return it.
+		return errors.New("create playlist: server did not return the playlist")
+	}
+
+	created := c.toPlaylist(result.Playlist)
+	if !playlist.Private {
+		params := url.Values{}
+		params.Set("playlistId", created.Slug)
+		params.Set("public", "true")
+		if _, err := c.call(ctx, "updatePlaylist", params, nil); err != nil {
+			return fmt.Errorf("update playlist: %w", err)
+		}
+		created.Private = false
+	}
+	*playlist = *created
+	return nil
+}
+
+// UpdatePlaylist saves the name and visibility, and replaces the songs when
+// they were loaded. A playlist from the list view carries a length but no
+// songs, and must not be emptied by a rename.
+func (c *SubsonicClient) UpdatePlaylist(ctx context.Context, playlist *types.Playlist) error {
+	if len(playlist.Songs) > 0 || playlist.Length == 0 {
+		params := url.Values{}
+		params.Set("playlistId", playlist.Slug)
+		for _, song := range playlist.Songs {
+			if song != nil {
+				params.Add("songId", song.Slug)
+			}
+		}
+		if _, err := c.call(ctx, "createPlaylist", params, nil); err != nil {
+			return fmt.Errorf("update playlist songs: %w", err)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("playlistId", playlist.Slug)
+	params.Set("name", playlist.Name)
+	params.Set("public", strconv.FormatBool(!playlist.Private))
+	if _, err := c.call(ctx, "updatePlaylist", params, nil); err != nil {
+		return fmt.Errorf("update playlist: %w", err)
+	}
+	return nil
+}
+
+func (c *SubsonicClient) DeletePlaylist(ctx context.Context, slug string) error {
+	if _, err := c.call(ctx, "deletePlaylist", url.Values{"id": {slug}}, nil); err != nil {
+		return fmt.Errorf("delete playlist: %w", err)
+	}
+	return nil
+}
+
+func (c *SubsonicClient) SearchAll(ctx context.Context, query string) (*types.SearchResponse, error) {
+	result, err := c.search(ctx, query, 20, 20, 20, 0)
+	if err != nil {
+		return nil, fmt.Errorf("search all: %w", err)
+	}
+
+	response := &types.SearchResponse{Songs: c.toSongs(result.SearchResult3.Songs)}
+	for _, album := range result.SearchResult3.Albums {
+		response.Albums = append(response.Albums, c.toAlbum(album))
+	}
+	for _, artist := range result.SearchResult3.Artists {
+		response.Authors = append(response.Authors, c.toAuthor(artist))
+	}
+	return response, nil
+}
+
+// ListenSong scrobbles a finished play. Subsonic tracks plays per account,
+// so userID is not used.
+func (c *SubsonicClient) ListenSong(ctx context.Context, slug string, userID string) error {
+	params := url.Values{}
+	params.Set("id", slug)
+	params.Set("submission", "true")
+	if _, err := c.call(ctx, "scrobble", params, nil); err != nil {
+		return fmt.Errorf("listen song: %w", err)
+	}
+	return nil
+}
+
+// Authenticate checks the password for the configured user and keeps it on
+// success.
+func (c *SubsonicClient) Authenticate(ctx context.Context, token string) error {
+	c.mu.Lock()
+	oldPassword := c.password
+	c.password = token
+	c.mu.Unlock()
+
+	if _, err := c.call(ctx, "ping", nil, nil); err != nil {
+		c.mu.Lock()
+		c.password = oldPassword
+		c.mu.Unlock()
+		return fmt.Errorf("authenticate: %w", err)
+	}
+
+	c.SetToken(token)
+	return nil
+}
+
+// EnsureAnonymousToken fails: Subsonic servers have no anonymous access.
+func (c *SubsonicClient) EnsureAnonymousToken(ctx context.Context) (string, error) {
+	return "", fmt.Errorf("anonymous access: %w", ErrUnsupported)
+}
+
+func (c *SubsonicClient) GetCurrentUser(ctx context.Context) (*types.User, error) {
+	c.mu.RLock()
+	username := c.username
+	c.mu.RUnlock()
+
+	var result struct {
+		User struct {
+			Username string `json:"username"`
+			Email    string `json:"email"`
+		} `json:"user"`
+	}
+	if _, err := c.call(ctx, "getUser", url.Values{"username": {username}}, &result); err != nil {
+		return nil, fmt.Errorf("get current user: %w", err)
+	}
+	return &types.User{Username: result.User.Username, Email: result.User.Email}, nil
+}
+
+func (c *SubsonicClient) Logout(ctx context.Context) error {
+	c.SetToken("")
+	return nil
+}
+
+// IsAnonymous reports whether no password is set.
+func (c *SubsonicClient) IsAnonymous() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.password == ""
+}
+
+// GetToken returns the password; Subsonic has no separate session token.
+func (c *SubsonicClient) GetToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.password
+}
+
+// SetToken sets the password and saves it to the config.
+func (c *SubsonicClient) SetToken(token string) {
+	c.mu.Lock()
+	c.password = token
+	c.mu.Unlock()
+
+	if c.cfg == nil {
+		return
+	}
+	c.cfg.API.Subsonic.Password = token
+	c.cfg.User.IsAnonymous = token == ""
+	_ = c.cfg.Save()
+}
+
+func (c *SubsonicClient) ProbeServer(ctx context.Context) (*ServerInfo, error) {
+	envelope, err := c.call(ctx, "ping", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("probe server: %w", err)
+	}
+
+	version := "Subsonic API " + envelope.Version
+	if envelope.Type != "" {
+		version = strings.TrimSpace(envelope.Type+" "+envelope.ServerVersion) + " (" + version + ")"
+	}
+	info := &ServerInfo{Version: version, Capabilities: subsonicFeatures}
+
+	c.infoMu.Lock()
+	c.serverInfo = info
+	c.infoMu.Unlock()
+
+	c.debugLog("Server: %s, OpenSubsonic: %v", version, envelope.OpenSubsonic)
+	return info, nil
+}
+
+func (c *SubsonicClient) Supports(feature string) bool {
+	return slices.Contains(subsonicFeatures, feature)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Optional server features. Servers that predate the version endpoint are
+// assumed to support all of them.
+const (
+	FeatureSongSort        = "song_sort"
+	FeaturePlaylistEditing = "playlist_editing"
+	FeatureLikedSongs      = "liked_songs"
+	FeatureListenHistory   = "listen_history"
+	FeatureUpdatedAfter    = "updated_after"
+)
+
+// ErrUnsupported is returned for features the server reports it lacks.
+var ErrUnsupported = errors.New("not supported by this server")
+
+// ServerInfo is what the server reports about itself on GET /version.
+type ServerInfo struct {
+	Version      string   `json:"version"`
+	Capabilities []string `json:"capabilities"`
+
+	// Legacy is set when the server has no version endpoint.
+	Legacy bool `json:"-"`
+}
+
+// ProbeServer asks the server for its version and capabilities. A server
+// without the endpoint is recorded as legacy rather than treated as an error.
+func (c *Client) ProbeServer(ctx context.Context) (*ServerInfo, error) {
+	resp, responseBody, err := c.makeRequest(ctx, "GET", "/version", nil, nil)
+	if err != nil {
+		if resp == nil || (resp.StatusCode != http.StatusNotFound && resp.StatusCode != http.StatusMethodNotAllowed) {
+			return nil, fmt.Errorf("probe server: %w", err)
+		}
+		info := &ServerInfo{Legacy: true}
+		c.setServerInfo(info)
+		c.debugLog("Server has no version endpoint, assuming legacy feature set")
+		return info, nil
+	}
+
+	var info ServerInfo
+	if err := json.Unmarshal(responseBody, &info); err != nil {
+		return nil, fmt.Errorf("decode version response: %w", err)
+	}
+
+	c.setServerInfo(&info)
+	c.debugLog("Server version %s, capabilities: %v", info.Version, info.Capabilities)
+	return &info, nil
+}
+
+// ServerInfo returns the result of the last probe, or nil before one ran.
+func (c *Client) ServerInfo() *ServerInfo {
+	c.infoMu.RLock()
+	defer c.infoMu.RUnlock()
+	return c.serverInfo
+}
+
+// Supports reports whether the server offers an optional feature. Until the
+// server has been probed, and for legacy servers, every feature is assumed.
+func (c *Client) Supports(feature string) bool {
+	info := c.ServerInfo()
+	if info == nil || info.Legacy || info.Capabilities == nil {
+		return true
+	}
+	for _, capability := range info.Capabilities {
+		if capability == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) serverVersion() string {
+	info := c.ServerInfo()
+	switch {
+	case info == nil:
+		return "unknown"
+	case info.Legacy:
+		return "legacy"
+	default:
+		return info.Version
+	}
+}
+
+func (c *Client) setServerInfo(info *ServerInfo) {
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+	c.serverInfo = info
+}
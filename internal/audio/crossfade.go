@@ -0,0 +1,64 @@
+package audio
+
+import "github.com/gopxl/beep"
+
+// trackOutput is what one track hands to the speaker. During a crossfade
+// the outgoing track keeps playing through its trackOutput for the length
+// of the fade while the next one starts on top of it; endAfter then cuts it
+// from the mixer. Changes must happen under speaker.Lock.
+type trackOutput struct {
+	Streamer beep.Streamer
+
+	remaining int // samples left before the track is cut, -1 for no limit
+	onEnd     func()
+	ended     bool
+}
+
+func newTrackOutput(s beep.Streamer) *trackOutput {
+	return &trackOutput{Streamer: s, remaining: -1}
+}
+
+// endAfter stops the output once the given number of samples has played and
+// runs onEnd in its own goroutine.
+func (t *trackOutput) endAfter(samples int, onEnd func()) {
+	if samples < 0 {
+		samples = 0
+	}
+	t.remaining = samples
+	t.onEnd = onEnd
+}
+
+func (t *trackOutput) Stream(samples [][2]float64) (int, bool) {
+	if t.remaining == 0 {
+		t.finish()
+		return 0, false
+	}
+	if t.remaining > 0 && len(samples) > t.remaining {
+		samples = samples[:t.remaining]
+	}
+
+	n, ok := t.Streamer.Stream(samples)
+	if t.remaining > 0 {
+		t.remaining -= n
+	}
+	if !ok {
+		t.finish()
+	}
+	return n, ok
+}
+
+func (t *trackOutput) Err() error {
+	return t.Streamer.Err()
+}
+
+// finish runs onEnd once. It is called from the speaker when the output
+// drains, or by the player after the output was cleared from the speaker.
+func (t *trackOutput) finish() {
+	if t.ended {
+		return
+	}
+	t.ended = true
+	if t.onEnd != nil {
+		go t.onEnd()
+	}
+}
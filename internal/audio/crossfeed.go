@@ -0,0 +1,68 @@
+package audio
+
+import (
+	"math"
+
+	"github.com/gopxl/beep"
+)
+
+const (
+	// crossfeedCutoff limits the bleed to low frequencies, which is what the
+	// ear would pick up from the opposite speaker in a room.
+	crossfeedCutoff = 700.0
+	// crossfeedMaxLevel is the strongest bleed at intensity 1 (about -6 dB).
+	crossfeedMaxLevel = 0.5
+)
+
+// crossfeed mixes a low-passed share of each channel into the other one so
+// hard-panned recordings are less fatiguing on headphones. Changes to
+// Enabled and Intensity must happen under speaker.Lock.
+type crossfeed struct {
+	Streamer  beep.Streamer
+	Enabled   bool
+	Intensity float64
+
+	alpha float64
+	lowL  float64
+	lowR  float64
+}
+
+func newCrossfeed(s beep.Streamer, sampleRate beep.SampleRate, enabled bool, intensity float64) *crossfeed {
+	dt := 1 / float64(sampleRate)
+	rc := 1 / (2 * math.Pi * crossfeedCutoff)
+	return &crossfeed{
+		Streamer:  s,
+		Enabled:   enabled,
+		Intensity: clampCrossfeedIntensity(intensity),
+		alpha:     dt / (rc + dt),
+	}
+}
+
+func clampCrossfeedIntensity(intensity float64) float64 {
+	return math.Max(0, math.Min(1, intensity))
+}
+
+func (c *crossfeed) Stream(samples [][2]float64) (int, bool) {
+	n, ok := c.Streamer.Stream(samples)
+	if !c.Enabled || c.Intensity <= 0 {
+		return n, ok
+	}
+
+	level := c.Intensity * crossfeedMaxLevel
+	gain := 1 / (1 + level)
+
+	for i := range samples[:n] {
+		left, right := samples[i][0], samples[i][1]
+
+		c.lowL += c.alpha * (left - c.lowL)
+		c.lowR += c.alpha * (right - c.lowR)
+
+		samples[i][0] = (left + level*c.lowR) * gain
+		samples[i][1] = (right + level*c.lowL) * gain
+	}
+	return n, ok
+}
+
+func (c *crossfeed) Err() error {
+	return c.Streamer.Err()
+}
@@ -0,0 +1,247 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/flac"
+	"github.com/gopxl/beep/mp3"
+	"github.com/gopxl/beep/vorbis"
+	"github.com/gopxl/beep/wav"
+)
+
+var (
+	errStreamSizeUnknown = errors.New("stream size is not known yet")
+	errInvalidWhence     = errors.New("invalid seek")
+	errSeekNotReady      = errors.New("seek not available until the download completes")
+)
+
+const (
+	formatMP3  = "mp3"
+	formatWAV  = "wav"
+	formatFLAC = "flac"
+	formatOgg  = "ogg"
+)
+
+// decodeAudio sniffs the container from the first bytes of the data and
+// picks a decoder, falling back to the file extension of a local file, and
+// for streams to the response's content type and the extension in the URL.
+// Anything unrecognized is decoded as MP3.
+func decodeAudio(reader io.ReadCloser, path string) (beep.StreamSeekCloser, beep.Format, error) {
+	var stream *StreamReader
+	if sr, ok := reader.(*StreamReader); ok {
+		// Lets the FLAC and Ogg decoders seek within the downloaded bytes.
+		stream = sr
+		reader = &streamSeeker{sr}
+	}
+
+	format, reader := sniffFormat(reader)
+	if format == "" {
+		format = formatFromExt(path)
+	}
+	if format == "" && stream != nil {
+		format = formatFromContentType(stream.ContentType())
+		if format == "" {
+			format = formatFromURL(stream.url)
+		}
+	}
+
+	switch format {
+	case formatWAV:
+		return wav.Decode(reader)
+	case formatFLAC:
+		streamer, format, err := flac.Decode(reader)
+		if err != nil {
+			return nil, format, err
+		}
+		return &flacStream{StreamSeekCloser: streamer, source: stream}, format, nil
+	case formatOgg:
+		return decodeVorbis(reader, stream)
+	default:
+		if seeker, ok := reader.(*streamSeeker); ok {
+			// The MP3 decoder scans a seekable source for its length up
+			// front, which would wait for the whole download.
+			reader = seeker.StreamReader
+		}
+		return mp3.Decode(reader)
+	}
+}
+
+func formatFromExt(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		return formatWAV
+	case ".flac":
+		return formatFLAC
+	case ".mp3":
+		return formatMP3
+	case ".ogg", ".oga":
+		return formatOgg
+	}
+	return ""
+}
+
+func formatFromURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return formatFromExt(u.Path)
+}
+
+func formatFromContentType(contentType string) string {
+	switch strings.ToLower(contentType) {
+	case "audio/ogg", "application/ogg", "audio/vorbis":
+		return formatOgg
+	case "audio/flac", "audio/x-flac":
+		return formatFLAC
+	case "audio/wav", "audio/x-wav", "audio/wave", "audio/vnd.wave":
+		return formatWAV
+	case "audio/mpeg", "audio/mp3":
+		return formatMP3
+	}
+	return ""
+}
+
+// sniffFormat reads the start of reader to recognize the container. It
+// returns a reader positioned back at the start: the same one rewound when it
+// can seek, or one that replays the bytes read.
+func sniffFormat(reader io.ReadCloser) (string, io.ReadCloser) {
+	header := make([]byte, 12)
+	n, _ := io.ReadFull(reader, header)
+	header = header[:n]
+
+	if seeker, ok := reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err == nil {
+			return detectFormat(header), reader
+		}
+	}
+	return detectFormat(header), &replayReader{
+		Reader: io.MultiReader(bytes.NewReader(header), reader),
+		closer: reader,
+	}
+}
+
+func detectFormat(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return formatFLAC
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return formatOgg
+	case len(header) >= 12 && bytes.HasPrefix(header, []byte("RIFF")) && string(header[8:12]) == "WAVE":
+		return formatWAV
+	case bytes.HasPrefix(header, []byte("ID3")):
+		// ID3 tags front MP3 files; FLAC behind a tag is rare and
+		// recognized by its extension.
+		return ""
+	case len(header) >= 2 && header[0] == 0xff && header[1]&0xe0 == 0xe0:
+		return formatMP3
+	}
+	return ""
+}
+
+// flacStream is a FLAC decoder. Its seeks read the source from the start
+// of the audio up to where they land, so on a stream they wait until the
+// download is complete rather than block playback while it goes on.
+type flacStream struct {
+	beep.StreamSeekCloser
+	source *StreamReader
+}
+
+func (f *flacStream) Seek(p int) error {
+	if f.source != nil && !f.source.IsComplete() {
+		return errSeekNotReady
+	}
+	return f.StreamSeekCloser.Seek(p)
+}
+
+// decodeVorbis decodes Ogg Vorbis. The decoder reads a seekable source to
+// its last page for the length up front, so a stream still downloading is
+// decoded without seeking and gets its seeks once the download completes.
+func decodeVorbis(reader io.ReadCloser, stream *StreamReader) (beep.StreamSeekCloser, beep.Format, error) {
+	seekable := true
+	if seeker, ok := reader.(*streamSeeker); ok && !stream.IsComplete() {
+		reader = seeker.StreamReader
+		seekable = false
+	}
+	streamer, format, err := vorbis.Decode(reader)
+	if err != nil {
+		return nil, format, err
+	}
+	return &vorbisStream{StreamSeekCloser: streamer, source: stream, seekable: seekable}, format, nil
+}
+
+// vorbisStream is an Ogg Vorbis decoder. One opened on a stream before the
+// download completed cannot seek; its first seek after that reopens the
+// decoder over the downloaded bytes.
+type vorbisStream struct {
+	beep.StreamSeekCloser
+	source   *StreamReader
+	seekable bool
+}
+
+func (v *vorbisStream) Seek(p int) error {
+	if v.seekable {
+		return v.StreamSeekCloser.Seek(p)
+	}
+	if !v.source.IsComplete() {
+		return errSeekNotReady
+	}
+	// The old decoder is dropped without closing it, which would close
+	// the stream the new one reads.
+	streamer, _, err := vorbis.Decode(&streamSeeker{v.source})
+	if err != nil {
+		return fmt.Errorf("reopen vorbis stream: %w", err)
+	}
+	if err := streamer.Seek(p); err != nil {
+		return err
+	}
+	v.StreamSeekCloser = streamer
+	v.seekable = true
+	return nil
+}
+
+type replayReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *replayReader) Close() error {
+	return r.closer.Close()
+}
+
+// streamSeeker gives a StreamReader a Seek method over its download buffer.
+// Reads past the downloaded part wait for the download like plain reads.
+type streamSeeker struct {
+	*StreamReader
+}
+
+func (s *streamSeeker) Seek(offset int64, whence int) (int64, error) {
+	sr := s.StreamReader
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += sr.position
+	case io.SeekEnd:
+		if sr.totalSize <= 0 {
+			return 0, errStreamSizeUnknown
+		}
+		offset += sr.totalSize
+	default:
+		return 0, errInvalidWhence
+	}
+	if offset < 0 {
+		return 0, errInvalidWhence
+	}
+	sr.position = offset
+	return offset, nil
+}
@@ -0,0 +1,196 @@
+package audio
+
+import (
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio/speaker"
+)
+
+const (
+	deviceCheckInterval = 2 * time.Second
+	// suspendThreshold is how far the wall clock may run ahead of the
+	// monotonic clock between checks before we assume the system slept.
+	suspendThreshold = 5 * time.Second
+	// outputStallTimeout is how long playback may stand still while nothing
+	// is paused or buffering before the output device is considered lost.
+	outputStallTimeout = 4 * time.Second
+)
+
+// watchOutputDevice re-initializes the speaker after a suspend/resume, a
+// change in the set of sound devices, a driver error or when the device stops
+// pulling samples. A chosen output device that went away falls back to the
+// system default this way, and is picked again once it is back. It runs until done is closed.
+func (p *Player) watchOutputDevice(done <-chan struct{}) {
+	ticker := time.NewTicker(deviceCheckInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	devices := outputDevices()
+	var lastPos time.Duration
+	var still time.Time
+	var lastErr string
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		slept := now.Round(0).Sub(last.Round(0))-now.Sub(last) > suspendThreshold ||
+			now.Sub(last) > deviceCheckInterval+suspendThreshold
+		last = now
+
+		driverErr := ""
+		if err := speaker.Err(); err != nil {
+			driverErr = err.Error()
+		}
+
+		reason := ""
+		current := outputDevices()
+		switch {
+		case slept:
+			reason = "system resumed from suspend"
+		case current != devices:
+			reason = "audio devices changed"
+		case driverErr != "" && driverErr != lastErr:
+			reason = "driver error: " + driverErr
+		}
+		devices = current
+		lastErr = driverErr
+
+		p.mu.RLock()
+		active := p.playing && !p.paused && !p.buffering && p.ctrl != nil
+		pos := p.position
+		p.mu.RUnlock()
+
+		switch {
+		case !active || pos != lastPos:
+			still = time.Time{}
+		case still.IsZero():
+			still = now
+		case reason == "" && now.Sub(still) > outputStallTimeout:
+			reason = "output stopped consuming audio"
+		}
+		lastPos = pos
+
+		if reason != "" {
+			p.reinitializeOutput(reason)
+			still = time.Time{}
+		}
+		p.switchDevice(p.outputDeviceKey())
+	}
+}
+
+// outputDeviceKey names the device audio goes to, for its remembered volume
+// and equalizer preset: the chosen output device while it is in use, or the
+// most recently attached sound card when playing on the system default.
+func (p *Player) outputDeviceKey() string {
+	if p.cfg.Audio.OutputDevice == "" {
+		return outputDeviceName()
+	}
+	if current := speaker.Device(); current != "" {
+		return current
+	}
+	return p.cfg.Audio.OutputDevice
+}
+
+// ErrDeviceSelection is returned by OutputDevices when this build can only
+// play on the system default device.
+var ErrDeviceSelection = speaker.ErrDeviceSelection
+
+// OutputDevices returns the names of the devices playback can be moved to.
+func OutputDevices() ([]string, error) {
+	return speaker.Devices()
+}
+
+// ApplyOutputDevice moves playback to the output device set in the config,
+// or the system default, without stopping the current song.
+func (p *Player) ApplyOutputDevice() {
+	want := p.cfg.Audio.OutputDevice
+	p.mu.Lock()
+	changed := want != p.outputDevice
+	p.outputDevice = want
+	p.mu.Unlock()
+	if !changed {
+		return
+	}
+
+	if p.debug {
+		log.Printf("[AUDIO] Switching output device to %q", want)
+	}
+	if err := speaker.SetDevice(want); err != nil {
+		log.Printf("[AUDIO] Failed to switch output device: %v", err)
+		return
+	}
+	p.reportFallback()
+	p.reattachOutput()
+	p.switchDevice(p.outputDeviceKey())
+}
+
+// ApplyCueDevice opens pre-listening on the cue device set in the config,
+// or closes it when none is set. A missing device is opened once it is
+// plugged in and the output is re-initialized.
+func (p *Player) ApplyCueDevice() {
+	want := p.cfg.Audio.CueDevice
+	if want == speaker.CueDevice() {
+		return
+	}
+	// Whatever played on the old device is dropped with it.
+	p.stopPreview(func(pv *preview) bool { return pv.cue })
+	if err := speaker.SetCueDevice(want); err != nil {
+		log.Printf("[AUDIO] Pre-listen device %q is not available: %v", want, err)
+		return
+	}
+	if p.debug && want != "" {
+		log.Printf("[AUDIO] Pre-listening on %q", want)
+	}
+}
+
+// reportFallback logs when the chosen output device is not available and
+// the system default plays instead.
+func (p *Player) reportFallback() {
+	want := p.cfg.Audio.OutputDevice
+	if current := speaker.Device(); want != "" && current != want {
+		if current == "" {
+			log.Printf("[AUDIO] Output device %q is not available, playing on the system default", want)
+			return
+		}
+		log.Printf("[AUDIO] Output device %q is not available, playing on %q", want, current)
+	}
+}
+
+// reinitializeOutput recreates the speaker output, on the chosen device if it
+// is available again, and re-attaches the current pipeline.
+func (p *Player) reinitializeOutput(reason string) {
+	log.Printf("[AUDIO] Re-initializing audio output: %s", reason)
+
+	// Reopening the cue output drops what played on it.
+	p.stopPreview(func(pv *preview) bool { return pv.cue })
+	if err := speaker.Reset(); err != nil {
+		log.Printf("[AUDIO] Failed to re-initialize audio output: %v", err)
+		return
+	}
+	p.reportFallback()
+	p.reattachOutput()
+}
+
+// reattachOutput re-attaches the current pipeline at the current position
+// after the output stream was recreated.
+func (p *Player) reattachOutput() {
+	p.mu.RLock()
+	active := p.playing && p.ctrl != nil
+	pos := p.position
+	p.mu.RUnlock()
+	if !active {
+		return
+	}
+
+	// Seeking drops whatever was queued for the old device and realigns the
+	// progress tracker with what is actually heard.
+	if err := p.Seek(pos); err != nil && p.debug {
+		log.Printf("[AUDIO] Could not re-attach playback at %v: %v", pos, err)
+	}
+}
@@ -0,0 +1,32 @@
+package audio
+
+import (
+	"os"
+	"strings"
+)
+
+// outputDevices returns a fingerprint of the sound cards known to ALSA, which
+// changes when a USB or Bluetooth device is plugged in or removed.
+func outputDevices() string {
+	data, err := os.ReadFile("/proc/asound/cards")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// outputDeviceName returns the id of the most recently attached sound card.
+// Hot-plugged headsets get the highest index and usually become the default
+// output, so this is a good stand-in for the device audio is going to.
+func outputDeviceName() string {
+	name := ""
+	for _, line := range strings.Split(outputDevices(), "\n") {
+		start := strings.Index(line, "[")
+		end := strings.Index(line, "]:")
+		if start < 0 || end < start {
+			continue
+		}
+		name = strings.TrimSpace(line[start+1 : end])
+	}
+	return name
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package audio
+
+// outputDevices has no portable device listing; changes on these platforms
+// are caught through suspend detection and output stalls instead.
+func outputDevices() string {
+	return ""
+}
+
+// outputDeviceName cannot tell devices apart here, so every output shares
+// the default entry.
+func outputDeviceName() string {
+	return ""
+}
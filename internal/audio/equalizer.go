@@ -0,0 +1,376 @@
+package audio
+
+import (
+	"log"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio/speaker"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+	"github.com/gopxl/beep"
+)
+
+// Equalizer presets. Custom uses the gains stored in the audio config.
+const (
+	EQPresetFlat      = "flat"
+	EQPresetRock      = "rock"
+	EQPresetJazz      = "jazz"
+	EQPresetVocal     = "vocal"
+	EQPresetBassBoost = "bass_boost"
+	EQPresetCustom    = "custom"
+)
+
+// eqQ gives each band roughly an octave of width, so neighbouring bands
+// blend into a smooth curve.
+const eqQ = 1.41
+
+// EQMaxGain bounds band gains in dB, both ways.
+const EQMaxGain = 12.0
+
+// EQBands are the centre frequencies of the equalizer bands in Hz.
+var EQBands = []float64{31, 62, 125, 250, 500, 1000, 2000, 4000, 8000, 16000}
+
+var eqPresetGains = map[string][]float64{
+	EQPresetFlat:      {0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+	EQPresetRock:      {5, 4, 3, 1, -1, -1, 1, 3, 4, 5},
+	EQPresetJazz:      {4, 3, 1, 2, -1, -1, 0, 1, 2, 3},
+	EQPresetVocal:     {-2, -2, -1, 1, 3, 4, 4, 3, 1, 0},
+	EQPresetBassBoost: {7, 6, 5, 3, 1, 0, 0, 0, 0, 0},
+}
+
+var eqPresetLabels = map[string]string{
+	EQPresetFlat:      "Flat",
+	EQPresetRock:      "Rock",
+	EQPresetJazz:      "Jazz",
+	EQPresetVocal:     "Vocal",
+	EQPresetBassBoost: "Bass Boost",
+	EQPresetCustom:    "Custom",
+}
+
+// EQPresetNames lists the presets in menu order.
+func EQPresetNames() []string {
+	return []string{EQPresetFlat, EQPresetRock, EQPresetJazz, EQPresetVocal, EQPresetBassBoost, EQPresetCustom}
+}
+
+// EQPresetLabel returns the display name of a preset.
+func EQPresetLabel(name string) string {
+	if label, ok := eqPresetLabels[name]; ok {
+		return label
+	}
+	return name
+}
+
+func validEQPreset(name string) bool {
+	_, ok := eqPresetLabels[name]
+	return ok
+}
+
+// eqGains returns the band gains of a preset; custom takes them from
+// custom, falling back to flat when that doesn't cover every band.
+func eqGains(preset string, custom []float64) []float64 {
+	if preset == EQPresetCustom {
+		if len(custom) != len(EQBands) {
+			return eqPresetGains[EQPresetFlat]
+		}
+		return custom
+	}
+	if gains, ok := eqPresetGains[preset]; ok {
+		return gains
+	}
+	return eqPresetGains[EQPresetFlat]
+}
+
+// biquad is a peaking filter from the Audio EQ Cookbook with separate state
+// for each channel.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     [2]float64
+}
+
+func (f *biquad) setPeaking(sampleRate, freq, gain float64) {
+	if gain == 0 || freq >= sampleRate*0.45 {
+		f.b0, f.b1, f.b2, f.a1, f.a2 = 1, 0, 0, 0, 0
+		return
+	}
+	a := math.Pow(10, gain/40)
+	w := 2 * math.Pi * freq / sampleRate
+	alpha := math.Sin(w) / (2 * eqQ)
+	cos := math.Cos(w)
+
+	a0 := 1 + alpha/a
+	f.b0 = (1 + alpha*a) / a0
+	f.b1 = -2 * cos / a0
+	f.b2 = (1 - alpha*a) / a0
+	f.a1 = -2 * cos / a0
+	f.a2 = (1 - alpha/a) / a0
+}
+
+func (f *biquad) process(c int, x float64) float64 {
+	y := f.b0*x + f.b1*f.x1[c] + f.b2*f.x2[c] - f.a1*f.y1[c] - f.a2*f.y2[c]
+	f.x2[c], f.x1[c] = f.x1[c], x
+	f.y2[c], f.y1[c] = f.y1[c], y
+	return y
+}
+
+// equalizer shapes the spectrum with one peaking filter per band. The
+// signal is attenuated by the largest boost so boosted bands don't clip.
+// Changes to Enabled and setGains must happen under speaker.Lock.
+type equalizer struct {
+	Streamer beep.Streamer
+	Enabled  bool
+
+	sampleRate float64
+	bands      []biquad
+	preamp     float64
+}
+
+func newEqualizer(s beep.Streamer, sampleRate beep.SampleRate, enabled bool, gains []float64) *equalizer {
+	eq := &equalizer{
+		Streamer:   s,
+		Enabled:    enabled,
+		sampleRate: float64(sampleRate),
+		bands:      make([]biquad, len(EQBands)),
+	}
+	eq.setGains(gains)
+	return eq
+}
+
+func (eq *equalizer) setGains(gains []float64) {
+	maxBoost := 0.0
+	for i := range eq.bands {
+		gain := 0.0
+		if i < len(gains) {
+			gain = math.Max(-EQMaxGain, math.Min(EQMaxGain, gains[i]))
+		}
+		maxBoost = math.Max(maxBoost, gain)
+		eq.bands[i].setPeaking(eq.sampleRate, EQBands[i], gain)
+	}
+	eq.preamp = math.Pow(10, -maxBoost/20)
+}
+
+func (eq *equalizer) Stream(samples [][2]float64) (int, bool) {
+	n, ok := eq.Streamer.Stream(samples)
+	if !eq.Enabled {
+		return n, ok
+	}
+
+	for i := range samples[:n] {
+		for c := 0; c < 2; c++ {
+			v := samples[i][c] * eq.preamp
+			for b := range eq.bands {
+				v = eq.bands[b].process(c, v)
+			}
+			samples[i][c] = v
+		}
+	}
+	return n, ok
+}
+
+func (eq *equalizer) Err() error {
+	return eq.Streamer.Err()
+}
+
+// EQState describes the equalizer as the player bar menu shows it.
+type EQState struct {
+	Enabled bool
+	Preset  string
+	// Device and Genre are the current output device and song genre;
+	// DeviceRemembered and GenreRemembered tell whether a preset is
+	// remembered for them.
+	Device           string
+	DeviceRemembered bool
+	Genre            string
+	GenreRemembered  bool
+}
+
+func songGenre(song *types.Song) string {
+	if song == nil || song.Meta == nil || song.Meta.Genre == nil {
+		return ""
+	}
+	return strings.TrimSpace(*song.Meta.Genre)
+}
+
+// eqPresetFor picks the preset for song on the current device: one
+// remembered for the song's genre wins over one remembered for the device,
+// which wins over the chosen preset. Callers must hold p.mu.
+func (p *Player) eqPresetFor(song *types.Song) string {
+	if genre := songGenre(song); genre != "" {
+		if preset := p.cfg.Audio.EQGenrePresets[configKey(genre)]; validEQPreset(preset) {
+			return preset
+		}
+	}
+	if preset := p.cfg.Audio.EQDevicePresets[configKey(p.device)]; validEQPreset(preset) {
+		return preset
+	}
+	if validEQPreset(p.cfg.Audio.EQPreset) {
+		return p.cfg.Audio.EQPreset
+	}
+	return EQPresetFlat
+}
+
+// applyEqualizer updates the live pipeline. Callers must hold p.mu.
+func (p *Player) applyEqualizer() {
+	if p.equalizer == nil {
+		return
+	}
+
+	speaker.Lock()
+	p.equalizer.Enabled = p.cfg.Audio.EQ
+	p.equalizer.setGains(eqGains(p.eqPreset, p.cfg.Audio.EQCustom))
+	speaker.Unlock()
+}
+
+// ApplyEqualizer updates the equalizer from the audio config.
+func (p *Player) ApplyEqualizer() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.eqPreset = p.eqPresetFor(p.currentSong)
+	p.applyEqualizer()
+
+	if p.debug {
+		log.Printf("[AUDIO] Equalizer: %v (preset %s)", p.cfg.Audio.EQ, p.eqPreset)
+	}
+}
+
+// EqualizerState reports the equalizer settings in effect.
+func (p *Player) EqualizerState() EQState {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	state := EQState{
+		Enabled: p.cfg.Audio.EQ,
+		Preset:  p.eqPreset,
+		Device:  p.device,
+		Genre:   songGenre(p.currentSong),
+	}
+	if state.Preset == "" {
+		state.Preset = p.eqPresetFor(p.currentSong)
+	}
+	state.DeviceRemembered = validEQPreset(p.cfg.Audio.EQDevicePresets[configKey(p.device)])
+	if state.Genre != "" {
+		state.GenreRemembered = validEQPreset(p.cfg.Audio.EQGenrePresets[configKey(state.Genre)])
+	}
+	return state
+}
+
+// SetEqualizerPreset switches to preset, or turns the equalizer off when it
+// is empty. When the current preset came from the genre or the device, the
+// choice is remembered for it instead of becoming the default.
+func (p *Player) SetEqualizerPreset(preset string) {
+	if preset != "" && !validEQPreset(preset) {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if preset == "" {
+		p.cfg.Audio.EQ = false
+	} else {
+		p.selectEqualizerPreset(preset)
+	}
+	p.applyEqualizer()
+	p.saveEqualizer()
+}
+
+// selectEqualizerPreset turns the equalizer on with preset, stored where
+// SetEqualizerPreset describes. Callers must hold p.mu.
+func (p *Player) selectEqualizerPreset(preset string) {
+	p.cfg.Audio.EQ = true
+	genre := songGenre(p.currentSong)
+	genreKey, deviceKey := configKey(genre), configKey(p.device)
+	switch {
+	case genre != "" && validEQPreset(p.cfg.Audio.EQGenrePresets[genreKey]):
+		p.cfg.Audio.EQGenrePresets[genreKey] = preset
+	case validEQPreset(p.cfg.Audio.EQDevicePresets[deviceKey]):
+		p.cfg.Audio.EQDevicePresets[deviceKey] = preset
+	default:
+		p.cfg.Audio.EQPreset = preset
+	}
+	p.eqPreset = preset
+}
+
+// EqualizerGains returns the band gains in dB of the preset in effect, one
+// per entry of EQBands.
+func (p *Player) EqualizerGains() []float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	preset := p.eqPreset
+	if preset == "" {
+		preset = p.eqPresetFor(p.currentSong)
+	}
+	return slices.Clone(eqGains(preset, p.cfg.Audio.EQCustom))
+}
+
+// SetEqualizerGains stores gains, one per entry of EQBands in dB, as the
+// custom preset and switches the equalizer to it.
+func (p *Player) SetEqualizerGains(gains []float64) {
+	if len(gains) != len(EQBands) {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	custom := make([]float64, len(gains))
+	for i, gain := range gains {
+		custom[i] = math.Max(-EQMaxGain, math.Min(EQMaxGain, gain))
+	}
+	p.cfg.Audio.EQCustom = custom
+	p.selectEqualizerPreset(EQPresetCustom)
+	p.applyEqualizer()
+	p.saveEqualizer()
+}
+
+// RememberEqualizerForDevice keeps the current preset for the current
+// output device, or forgets the one kept for it.
+func (p *Player) RememberEqualizerForDevice(remember bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cfg.Audio.EQDevicePresets == nil {
+		p.cfg.Audio.EQDevicePresets = make(map[string]string)
+	}
+	p.rememberEqualizer(p.cfg.Audio.EQDevicePresets, configKey(p.device), remember)
+}
+
+// RememberEqualizerForGenre keeps the current preset for the playing song's
+// genre, or forgets the one kept for it.
+func (p *Player) RememberEqualizerForGenre(remember bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	genre := songGenre(p.currentSong)
+	if genre == "" {
+		return
+	}
+	if p.cfg.Audio.EQGenrePresets == nil {
+		p.cfg.Audio.EQGenrePresets = make(map[string]string)
+	}
+	p.rememberEqualizer(p.cfg.Audio.EQGenrePresets, configKey(genre), remember)
+}
+
+// rememberEqualizer updates one of the preset maps. Callers must hold p.mu.
+func (p *Player) rememberEqualizer(presets map[string]string, key string, remember bool) {
+	if remember {
+		if p.eqPreset == "" {
+			p.eqPreset = p.eqPresetFor(p.currentSong)
+		}
+		presets[key] = p.eqPreset
+	} else {
+		delete(presets, key)
+		p.eqPreset = p.eqPresetFor(p.currentSong)
+		p.applyEqualizer()
+	}
+	p.saveEqualizer()
+}
+
+// saveEqualizer writes the equalizer settings. Callers must hold p.mu.
+func (p *Player) saveEqualizer() {
+	if err := p.cfg.Save(); err != nil {
+		log.Printf("[AUDIO] Failed to save equalizer settings: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package audio
+
+import "github.com/gopxl/beep"
+
+// fader applies a linear gain ramp, used for crossfade transitions between
+// tracks. Changes must happen under speaker.Lock.
+type fader struct {
+	Streamer beep.Streamer
+
+	gain   float64
+	target float64
+	step   float64
+}
+
+func newFader(s beep.Streamer, fadeInSamples int) *fader {
+	f := &fader{Streamer: s, gain: 1, target: 1}
+	if fadeInSamples > 0 {
+		f.gain = 0
+		f.fadeTo(1, fadeInSamples)
+	}
+	return f
+}
+
+// fadeTo ramps the gain to target over the given number of samples.
+func (f *fader) fadeTo(target float64, samples int) {
+	f.target = target
+	if samples <= 0 {
+		f.gain = target
+		f.step = 0
+		return
+	}
+	f.step = (target - f.gain) / float64(samples)
+}
+
+func (f *fader) Stream(samples [][2]float64) (int, bool) {
+	n, ok := f.Streamer.Stream(samples)
+	if f.step == 0 && f.gain == 1 {
+		return n, ok
+	}
+
+	for i := range samples[:n] {
+		if f.step != 0 {
+			f.gain += f.step
+			if (f.step > 0 && f.gain >= f.target) || (f.step < 0 && f.gain <= f.target) {
+				f.gain = f.target
+				f.step = 0
+			}
+		}
+		samples[i][0] *= f.gain
+		samples[i][1] *= f.gain
+	}
+	return n, ok
+}
+
+func (f *fader) Err() error {
+	return f.Streamer.Err()
+}
@@ -0,0 +1,36 @@
+package audio
+
+import "time"
+
+// silenceThreshold is the share of the loudest waveform bucket below which a
+// bucket counts as silence.
+const silenceThreshold = 0.02
+
+// TrailingSilence estimates how much silence ends a track from its waveform
+// summary, so gapless playback can move on when the music actually stops.
+func TrailingSilence(volume []int, length time.Duration) time.Duration {
+	if len(volume) == 0 || length <= 0 {
+		return 0
+	}
+
+	peak := 0
+	for _, v := range volume {
+		if v > peak {
+			peak = v
+		}
+	}
+	if peak == 0 {
+		return 0
+	}
+
+	limit := float64(peak) * silenceThreshold
+	silent := 0
+	for i := len(volume) - 1; i >= 0 && float64(volume[i]) <= limit; i-- {
+		silent++
+	}
+	if silent == len(volume) {
+		return 0
+	}
+
+	return length * time.Duration(silent) / time.Duration(len(volume))
+}
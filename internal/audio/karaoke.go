@@ -0,0 +1,58 @@
+package audio
+
+import (
+	"math"
+
+	"github.com/gopxl/beep"
+)
+
+// karaokeCutoff is the frequency below which the center channel is kept, so
+// kick drums and bass lines that are usually mixed in the middle survive.
+const karaokeCutoff = 200.0
+
+// vocalRemover attenuates sound that is identical in both channels, which is
+// where most mixes place the lead vocal. Toggling Enabled must happen under
+// speaker.Lock, like the other effects in the chain.
+type vocalRemover struct {
+	Streamer beep.Streamer
+	Enabled  bool
+	Strength float64
+
+	alpha  float64
+	lowMid float64
+}
+
+func newVocalRemover(s beep.Streamer, sampleRate beep.SampleRate, enabled bool) *vocalRemover {
+	dt := 1 / float64(sampleRate)
+	rc := 1 / (2 * math.Pi * karaokeCutoff)
+	return &vocalRemover{
+		Streamer: s,
+		Enabled:  enabled,
+		Strength: 1,
+		alpha:    dt / (rc + dt),
+	}
+}
+
+func (v *vocalRemover) Stream(samples [][2]float64) (int, bool) {
+	n, ok := v.Streamer.Stream(samples)
+	if !v.Enabled {
+		return n, ok
+	}
+
+	for i := range samples[:n] {
+		left, right := samples[i][0], samples[i][1]
+		mid := (left + right) / 2
+		side := (left - right) / 2
+
+		v.lowMid += v.alpha * (mid - v.lowMid)
+		mid = v.lowMid + (mid-v.lowMid)*(1-v.Strength)
+
+		samples[i][0] = mid + side
+		samples[i][1] = mid - side
+	}
+	return n, ok
+}
+
+func (v *vocalRemover) Err() error {
+	return v.Streamer.Err()
+}
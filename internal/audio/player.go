@@ -6,21 +6,20 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"net"
 	"net/http"
 	"os"
 	"runtime"
 	"sync"
 	"time"
 
-	"fyne.io/fyne/v2"
+	"github.com/Alexander-D-Karpov/amp/internal/audio/speaker"
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
 	"github.com/Alexander-D-Karpov/amp/internal/storage"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 	"github.com/gopxl/beep"
 	"github.com/gopxl/beep/effects"
 	"github.com/gopxl/beep/mp3"
-	"github.com/gopxl/beep/speaker"
 	"path/filepath"
 	"strings"
 )
@@ -37,33 +36,56 @@ type StreamManager struct {
 	cfg           *config.Config
 	debug         bool
 	activeStreams sync.Map
+	throughput    throughputMeter
 }
 
 type Player struct {
 	mu sync.RWMutex
 
-	cfg              *config.Config
-	storage          *storage.Database
-	currentSong      *types.Song
-	streamer         beep.StreamSeekCloser
-	ctrl             *beep.Ctrl
-	volume           *effects.Volume
-	position         time.Duration
-	duration         time.Duration
-	expectedDuration time.Duration
-	positionCallback func(time.Duration)
-	finishedCallback func()
-	sampleRate       beep.SampleRate
-	srcSampleRate    beep.SampleRate
-	isSeekable       bool
-	ticker           *time.Ticker
-	done             chan struct{}
-	httpClient       *http.Client
-	debug            bool
-	playing          bool
-	paused           bool
-	bufferSize       int
-	lastPosition     time.Duration
+	cfg         *config.Config
+	storage     *storage.Database
+	currentSong *types.Song
+	streamer    beep.StreamSeekCloser
+	ctrl        *beep.Ctrl
+	fader       *fader
+	karaoke     *vocalRemover
+	crossfeed   *crossfeed
+	equalizer   *equalizer
+	eqPreset    string
+	volume      *effects.Volume
+	output      *trackOutput
+	tail        *trackOutput // outgoing track still fading out
+	tailURL     string
+	preview     *preview
+	scope       scope
+	level       float64
+	device      string
+	// outputDevice is the output device last asked for, empty for the
+	// system default.
+	outputDevice      string
+	volumeCallback    func(level float64)
+	volumeSaveTimer   *time.Timer
+	position          time.Duration
+	duration          time.Duration
+	expectedDuration  time.Duration
+	positionCallback  func(time.Duration)
+	finishedCallback  func()
+	bufferingCallback func(buffering, stalled bool)
+	sampleRate        beep.SampleRate
+	srcSampleRate     beep.SampleRate
+	isSeekable        bool
+	ticker            *time.Ticker
+	done              chan struct{}
+	httpClient        *http.Client
+	debug             bool
+	playing           bool
+	paused            bool
+	bufferSize        int
+	lastPosition      time.Duration
+	karaokeEnabled    bool
+	buffering         bool
+	stalled           bool
+	nextFadeIn        time.Duration
 
 	// Streaming components
 	streamManager   *StreamManager
@@ -87,23 +109,10 @@ type Player struct {
 
 func NewPlayer(cfg *config.Config, storage *storage.Database) (*Player, error) {
 	p := &Player{
-		cfg:     cfg,
-		storage: storage,
-		done:    make(chan struct{}),
-		httpClient: &http.Client{
-			Timeout: 10 * time.Minute, // Increased from 30 seconds to 10 minutes for streaming
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout:   10 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-				TLSHandshakeTimeout:   15 * time.Second,
-				ResponseHeaderTimeout: 30 * time.Second, // Added this
-				IdleConnTimeout:       90 * time.Second, // Added this
-				MaxIdleConns:          10,
-				DisableCompression:    true, // Disable compression for audio streaming
-			},
-		},
+		cfg:                 cfg,
+		storage:             storage,
+		done:                make(chan struct{}),
+		httpClient:          netutil.NewClient(cfg, netutil.ProfileStream),
 		sampleRate:          beep.SampleRate(cfg.Audio.SampleRate),
 		srcSampleRate:       beep.SampleRate(cfg.Audio.SampleRate),
 		debug:               cfg.Debug,
@@ -111,19 +120,25 @@ func NewPlayer(cfg *config.Config, storage *storage.Database) (*Player, error) {
 		paused:              false,
 		minPlayTime:         5 * time.Second,
 		completionThreshold: 0.95,
+		karaokeEnabled:      cfg.Audio.Karaoke,
 	}
 
 	p.bufferSize = p.calculateOptimalBufferSize()
-
 	if err := p.initializeSpeaker(); err != nil {
 		return nil, fmt.Errorf("failed to initialize speaker: %w", err)
 	}
+	p.reportFallback()
+
+	p.device = p.outputDeviceKey()
+	p.level = StartupVolume(cfg, p.device)
 
 	// Initialize sub-components
 	p.streamManager = NewStreamManager(p.httpClient, cfg, p.debug)
 	p.progressTracker = NewProgressTracker(50 * time.Millisecond)
 	p.bufferManager = NewBufferManager(cfg, p.debug)
 
+	go p.watchOutputDevice(p.done)
+
 	if p.debug {
 		log.Printf("[AUDIO] Player initialized - OS: %s, Sample Rate: %d, Buffer: %d",
 			runtime.GOOS, p.sampleRate, p.bufferSize)
@@ -158,18 +173,25 @@ func (p *Player) calculateOptimalBufferSize() int {
 func (p *Player) initializeSpeaker() error {
 	var err error
 	speakerOnce.Do(func() {
+		p.outputDevice = p.cfg.Audio.OutputDevice
+		if err = speaker.SetDevice(p.outputDevice); err != nil {
+			return
+		}
 		buf := p.sampleRate.N(200 * time.Millisecond)
 		err = speaker.Init(p.sampleRate, buf)
 		if p.debug {
 			log.Printf("[AUDIO] speaker.Init(%d, %d)", p.sampleRate, buf)
 		}
+		if err == nil {
+			p.ApplyCueDevice()
+		}
 	})
 	return err
 }
 
-func (p *Player) mkVolume(vol float64) *effects.Volume {
+func (p *Player) mkVolume(s beep.Streamer, vol float64) *effects.Volume {
 	v := &effects.Volume{
-		Streamer: p.ctrl,
+		Streamer: s,
 		Base:     2,
 	}
 	if vol <= 0 {
@@ -184,10 +206,15 @@ func (p *Player) Play(ctx context.Context, song *types.Song) error {
 	if song == nil {
 		return fmt.Errorf("song cannot be nil")
 	}
+	return p.start(ctx, song, 0)
+}
 
+// start stops the current track and loads song, fading it in over fadeIn.
+func (p *Player) start(ctx context.Context, song *types.Song, fadeIn time.Duration) error {
 	if p.debug {
 		log.Printf("[AUDIO] Starting playback for: %s (Length: %d seconds)", song.Name, song.Length)
 	}
+	p.yieldPreview()
 
 	p.mu.Lock()
 	// Cancel any ongoing loading
@@ -208,6 +235,7 @@ func (p *Player) Play(ctx context.Context, song *types.Song) error {
 	p.position = 0
 	p.playbackStartTime = time.Now()
 	p.loadingCanceled = false
+	p.nextFadeIn = fadeIn
 
 	if song.Length > 0 {
 		p.expectedDuration = time.Duration(song.Length) * time.Second
@@ -238,18 +266,20 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 	}
 
 	var (
-		reader  io.ReadCloser
-		err     error
-		isLocal bool
+		reader    io.ReadCloser
+		err       error
+		isLocal   bool
+		localPath string
 	)
 
 	// 1) Explicit local path
-	if song.LocalPath != nil && *song.LocalPath != "" {
-		if _, statErr := os.Stat(*song.LocalPath); statErr == nil {
-			if reader, err = os.Open(*song.LocalPath); err == nil {
+	if path := localSongPath(song); path != "" {
+		if _, statErr := os.Stat(path); statErr == nil {
+			if reader, err = os.Open(path); err == nil {
 				isLocal = true
+				localPath = path
 				if p.debug {
-					log.Printf("[AUDIO] Using local file %s", *song.LocalPath)
+					log.Printf("[AUDIO] Using local file %s", path)
 				}
 			}
 		}
@@ -257,8 +287,7 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 
 	// 2) Cached file
 	if reader == nil {
-		filename := safeFilename(song.Name, song.Slug) + ".mp3"
-		candidate := filepath.Join(p.cfg.Storage.CacheDir, "songs", filename)
+		candidate := p.cachedSongPath(song)
 		if _, statErr := os.Stat(candidate); statErr == nil {
 			if p.debug {
 				log.Printf("[AUDIO] Found cached file %s", candidate)
@@ -268,6 +297,7 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 
 			if reader, err = os.Open(candidate); err == nil {
 				isLocal = true
+				localPath = candidate
 				if p.debug {
 					log.Printf("[AUDIO] Using local cached file %s", candidate)
 				}
@@ -275,12 +305,19 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 		}
 	}
 
+	if localPath != "" && p.storage != nil {
+		go p.touchCached(localPath)
+	}
+
 	// 3) Stream from URL
 	if reader == nil {
 		if p.debug {
 			log.Printf("[AUDIO] Streaming %s", song.File)
 		}
-		reader, err = p.streamManager.CreateStream(ctx, song.File)
+		p.mu.RLock()
+		expected := p.expectedDuration
+		p.mu.RUnlock()
+		reader, err = p.streamManager.CreateStream(ctx, song.File, expected)
 		if err != nil {
 			if p.debug {
 				log.Printf("[AUDIO] Failed to create stream: %v", err)
@@ -323,11 +360,10 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 		return
 	}
 
-	// Decode MP3
-	streamer, format, err := mp3.Decode(reader)
+	streamer, format, err := decodeAudio(reader, localPath)
 	if err != nil {
 		if p.debug {
-			log.Printf("[AUDIO] Failed to decode MP3 for '%s': %v", song.Name, err)
+			log.Printf("[AUDIO] Failed to decode audio for '%s': %v", song.Name, err)
 		}
 		reader.Close()
 		return
@@ -355,9 +391,10 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 	} else if song.Length > 0 {
 		dur = time.Duration(song.Length) * time.Second
 	} else {
-		// If local and we know length in samples
-		if isLocal {
-			dur = format.SampleRate.D(streamer.Len())
+		// The decoder knows the length for local files and for streamed
+		// formats that carry it in their header
+		if n := streamer.Len(); n > 0 {
+			dur = format.SampleRate.D(n)
 		}
 	}
 	p.duration = dur
@@ -377,13 +414,21 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 	}
 
 	p.ctrl = &beep.Ctrl{Streamer: source, Paused: false}
-	p.volume = p.mkVolume(p.cfg.Audio.DefaultVolume)
-
-	// Start/replace speaker pipeline
-	speaker.Clear()
+	p.fader = newFader(p.ctrl, p.sampleRate.N(p.nextFadeIn))
+	p.nextFadeIn = 0
+	p.karaoke = newVocalRemover(p.fader, p.sampleRate, p.karaokeEnabled)
+	p.crossfeed = newCrossfeed(p.karaoke, p.sampleRate, p.cfg.Audio.Crossfeed, p.cfg.Audio.CrossfeedLevel)
+	p.eqPreset = p.eqPresetFor(song)
+	p.equalizer = newEqualizer(p.crossfeed, p.sampleRate, p.cfg.Audio.EQ, eqGains(p.eqPreset, p.cfg.Audio.EQCustom))
+	p.volume = p.mkVolume(&tap{Streamer: p.equalizer, scope: &p.scope}, p.level)
+
+	// Start/replace speaker pipeline, leaving a crossfading track in place
+	if p.tail == nil {
+		speaker.Clear()
+	}
 	done := make(chan struct{})
-	seq := beep.Seq(p.volume, beep.Callback(func() { close(done) }))
-	speaker.Play(seq)
+	p.output = newTrackOutput(beep.Seq(p.volume, beep.Callback(func() { close(done) })))
+	speaker.Play(p.output)
 
 	p.playing = true
 	p.paused = false
@@ -400,9 +445,23 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 		log.Printf("[AUDIO] Started playback for '%s' with position tracking", song.Name)
 	}
 
-	// Wait for finish or cancellation
+	if sr, ok := reader.(*StreamReader); ok && !isLocal {
+		go p.monitorStalls(ctx, sr)
+	}
+
+	p.awaitEnd(ctx, song, done)
+}
+
+// awaitEnd waits for the track's output to play out, then calls the
+// finished callback if it played far enough to count, or for ctx to be
+// canceled when another track replaces it.
+func (p *Player) awaitEnd(ctx context.Context, song *types.Song, done <-chan struct{}) {
 	select {
 	case <-done:
+		if ctx.Err() != nil {
+			// Replaced by another track while draining
+			return
+		}
 		if p.shouldTriggerFinished() {
 			if p.debug {
 				log.Printf("[AUDIO] Playback finished for '%s'", song.Name)
@@ -419,7 +478,7 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 			p.mu.Unlock()
 
 			if cb != nil {
-				fyne.Do(cb)
+				cb()
 			}
 		} else {
 			if p.debug {
@@ -443,6 +502,14 @@ func (p *Player) loadAndPlay(ctx context.Context, song *types.Song) {
 	}
 }
 
+// touchCached tells the cache janitor a cached song was just played, so it
+// is evicted after the ones played longer ago.
+func (p *Player) touchCached(path string) {
+	if err := p.storage.TouchCacheFile(context.Background(), path); err != nil && p.debug {
+		log.Printf("[AUDIO] Failed to touch cached file %s: %v", path, err)
+	}
+}
+
 func (p *Player) updatePositionCallback(pos time.Duration) {
 	p.mu.Lock()
 	p.position = pos
@@ -451,10 +518,7 @@ func (p *Player) updatePositionCallback(pos time.Duration) {
 	p.mu.Unlock()
 
 	if callback != nil {
-		// Ensure UI updates happen on the main thread
-		fyne.Do(func() {
-			callback(pos)
-		})
+		callback(pos)
 	}
 }
 
@@ -506,6 +570,7 @@ func (p *Player) Pause() error {
 	if p.ctrl != nil && p.playing && !p.paused {
 		speaker.Lock()
 		p.ctrl.Paused = true
+		p.cutTail()
 		speaker.Unlock()
 		p.paused = true
 
@@ -522,6 +587,7 @@ func (p *Player) Pause() error {
 }
 
 func (p *Player) Resume() error {
+	p.yieldPreview()
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -551,6 +617,13 @@ func (p *Player) stopInternal() {
 
 	if p.playing || p.paused {
 		speaker.Clear()
+		if p.tail != nil {
+			speaker.Lock()
+			p.tail.finish()
+			speaker.Unlock()
+			p.tail = nil
+			p.tailURL = ""
+		}
 	}
 
 	if p.streamer != nil {
@@ -558,15 +631,21 @@ func (p *Player) stopInternal() {
 		p.streamer = nil
 	}
 
-	// Close and forget any active network streams
+	// Close and forget any active network streams, except the one a
+	// crossfading track still reads from
 	if p.streamManager != nil {
-		p.streamManager.CleanupStreams()
+		if p.tail != nil {
+			p.streamManager.ReleaseExcept(p.tailURL)
+		} else {
+			p.streamManager.CleanupStreams()
+		}
 	}
 	p.activeStream = nil
 	p.baseOffset = 0
 
 	p.ctrl = nil
 	p.volume = nil
+	p.output = nil
 	p.position = 0
 	p.duration = 0
 	p.expectedDuration = 0
@@ -584,6 +663,11 @@ func (p *Player) Stop() error {
 
 	p.stopInternal()
 	p.currentSong = nil
+	if p.tail != nil {
+		speaker.Lock()
+		p.cutTail()
+		speaker.Unlock()
+	}
 
 	if p.debug {
 		log.Printf("[AUDIO] Stopped playback")
@@ -649,6 +733,13 @@ func (p *Player) Seek(position time.Duration) error {
 	if p.currentSong.File == "" {
 		return fmt.Errorf("seek not supported")
 	}
+	switch p.streamer.(type) {
+	case *flacStream, *vorbisStream:
+		// FLAC and Ogg seek natively; a failure there means the
+		// download is not far enough yet and re-decoding from a byte
+		// offset would not find a frame or page boundary either.
+		return fmt.Errorf("seek not available yet")
+	}
 	sr, ok := p.streamManager.GetStream(p.currentSong.File)
 	if !ok {
 		if p.debug {
@@ -843,30 +934,142 @@ func (p *Player) HasSufficientBuffer(position time.Duration) bool {
 	return downloadProgress >= requiredProgress+bufferMargin
 }
 
+// SetVolume sets the volume level between 0 and 1 and remembers it for the
+// current output device.
 func (p *Player) SetVolume(level float64) error {
-	if level < 0 {
-		level = 0
+	level = clampVolume(level)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if level == p.level {
+		return nil
+	}
+	p.level = level
+	p.applyVolume()
+	p.rememberVolume()
+	return nil
+}
+
+// CrossfadeTo starts song while the current track fades out over d, so the
+// end of one overlaps the start of the other. It falls back to Play when
+// nothing is playing.
+func (p *Player) CrossfadeTo(ctx context.Context, song *types.Song, d time.Duration) error {
+	if song == nil {
+		return fmt.Errorf("song cannot be nil")
+	}
+
+	p.mu.Lock()
+	if d <= 0 || !p.playing || p.paused || p.output == nil || p.fader == nil ||
+		p.currentSong == nil || p.currentSong.File == song.File {
+		p.mu.Unlock()
+		return p.Play(ctx, song)
+	}
+
+	// Keep the outgoing finish handler from firing once the track is detached
+	if p.loadingCancel != nil {
+		p.loadingCancel()
 	}
-	if level > 1 {
-		level = 1
+
+	tail, streamer, url := p.output, p.streamer, p.currentSong.File
+	samples := p.sampleRate.N(d)
+
+	speaker.Lock()
+	p.cutTail()
+	p.fader.fadeTo(0, samples)
+	tail.endAfter(samples, func() { p.finishTail(tail, streamer, url) })
+	speaker.Unlock()
+
+	// Detach the outgoing chain so stopping it for the next track leaves
+	// it playing
+	p.tail = tail
+	p.tailURL = url
+	p.output = nil
+	p.streamer = nil
+	p.fader = nil
+	p.activeStream = nil
+	p.playing = false
+	p.mu.Unlock()
+
+	if p.debug {
+		log.Printf("[AUDIO] Crossfading into '%s' over %v", song.Name, d)
+	}
+
+	return p.start(ctx, song, d)
+}
+
+// cutTail ends a crossfading track on the next speaker callback. Must be
+// called with p.mu and speaker.Lock held.
+func (p *Player) cutTail() {
+	if p.tail != nil {
+		p.tail.endAfter(0, p.tail.onEnd)
 	}
+}
 
+// finishTail releases an outgoing track once its fade has played out.
+func (p *Player) finishTail(tail *trackOutput, streamer beep.StreamSeekCloser, url string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if p.volume == nil {
-		return nil
+	if streamer != nil {
+		_ = streamer.Close()
+	}
+	if p.tail == tail {
+		p.tail = nil
+		p.tailURL = ""
+	}
+	if url != "" && url != p.tailURL && (p.currentSong == nil || p.currentSong.File != url) {
+		p.streamManager.CloseStream(url)
+	}
+
+	if p.debug {
+		log.Printf("[AUDIO] Crossfade finished, released %s", url)
+	}
+}
+
+// SetKaraoke toggles vocal attenuation. The setting carries over to the
+// following tracks.
+func (p *Player) SetKaraoke(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.karaokeEnabled = enabled
+	if p.karaoke == nil {
+		return
 	}
 
 	speaker.Lock()
-	if level == 0 {
-		p.volume.Silent = true
-	} else {
-		p.volume.Silent = false
-		p.volume.Volume = (level - 1) * 5
+	p.karaoke.Enabled = enabled
+	speaker.Unlock()
+
+	if p.debug {
+		log.Printf("[AUDIO] Karaoke mode: %v", enabled)
+	}
+}
+
+func (p *Player) IsKaraokeEnabled() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.karaokeEnabled
+}
+
+// ApplyCrossfeed updates the headphone crossfeed stage from the audio config.
+func (p *Player) ApplyCrossfeed() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.crossfeed == nil {
+		return
 	}
+
+	speaker.Lock()
+	p.crossfeed.Enabled = p.cfg.Audio.Crossfeed
+	p.crossfeed.Intensity = clampCrossfeedIntensity(p.cfg.Audio.CrossfeedLevel)
 	speaker.Unlock()
-	return nil
+
+	if p.debug {
+		log.Printf("[AUDIO] Crossfeed: %v (intensity %.2f)", p.cfg.Audio.Crossfeed, p.cfg.Audio.CrossfeedLevel)
+	}
 }
 
 func (p *Player) GetPosition() time.Duration {
@@ -890,18 +1093,73 @@ func (p *Player) IsPlaying() bool {
 	return p.playing && !p.paused && p.ctrl != nil
 }
 
+// OnPositionChanged is called as playback moves, from the player's own
+// goroutine: UIs must hand the update to their event loop themselves.
 func (p *Player) OnPositionChanged(callback func(time.Duration)) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.positionCallback = callback
 }
 
+// OnFinished is called from the player's goroutine when a track plays to
+// its end.
 func (p *Player) OnFinished(callback func()) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.finishedCallback = callback
 }
 
+// BufferHealth reports how far the download is ahead of the playback
+// position. complete is true for local files and finished downloads.
+func (p *Player) BufferHealth() (ahead time.Duration, complete bool) {
+	p.mu.RLock()
+	song := p.currentSong
+	position := p.position
+	p.mu.RUnlock()
+
+	if song == nil {
+		return 0, false
+	}
+
+	sr, ok := p.streamManager.GetStream(song.File)
+	if !ok || sr.IsComplete() {
+		return 0, true
+	}
+
+	ahead = sr.BufferedDuration() - position
+	if ahead < 0 {
+		ahead = 0
+	}
+	return ahead, false
+}
+
+// PrefetchDepth returns how many upcoming tracks should be downloaded ahead
+// given the measured network speed.
+func (p *Player) PrefetchDepth() int {
+	return p.streamManager.PrefetchDepth()
+}
+
+// StreamBufferUsage returns the number of open streams and the bytes of
+// audio they hold in memory.
+func (p *Player) StreamBufferUsage() (streams int, bytes int64) {
+	return p.streamManager.BufferUsage()
+}
+
+// ReleaseIdleStreams frees the buffers of every stream except the one
+// playing and the one a crossfading track still reads from, such as
+// prefetched upcoming tracks. It returns how many were released.
+func (p *Player) ReleaseIdleStreams() int {
+	p.mu.RLock()
+	keep := ""
+	if p.currentSong != nil {
+		keep = p.currentSong.File
+	}
+	tailURL := p.tailURL
+	p.mu.RUnlock()
+
+	return p.streamManager.ReleaseExcept(keep, tailURL)
+}
+
 func (p *Player) GetDownloadProgress() float64 {
 	return p.streamManager.GetDownloadProgress()
 }
@@ -925,11 +1183,40 @@ func (p *Player) Close() error {
 	p.mu.Unlock()
 
 	p.progressTracker.Stop()
+	p.stopPreview(func(*preview) bool { return true })
 	p.streamManager.Close()
+	p.flushVolume()
 
 	return p.Stop()
 }
 
+// localSongPath is where song was said to be on disk, or empty.
+func localSongPath(song *types.Song) string {
+	if song.LocalPath == nil {
+		return ""
+	}
+	return *song.LocalPath
+}
+
+// SongFile returns the file song plays from on this machine, its local or
+// cached file, or empty when it would be streamed.
+func (p *Player) SongFile(song *types.Song) string {
+	for _, path := range []string{localSongPath(song), p.cachedSongPath(song)} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// cachedSongPath is where song is kept once downloaded into the cache.
+func (p *Player) cachedSongPath(song *types.Song) string {
+	return filepath.Join(p.cfg.Storage.CacheDir, "songs", safeFilename(song.Name, song.Slug)+".mp3")
+}
+
 func safeFilename(name, slug string) string {
 	if slug != "" {
 		return slug
@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// TestPlayToEndWithoutFyneApp plays a short track through to its end the
+// way the speaker does, with no Fyne app running, as in amptui. The player
+// must call its callbacks directly rather than through Fyne.
+func TestPlayToEndWithoutFyneApp(t *testing.T) {
+	const rate = beep.SampleRate(44100)
+	length := time.Second
+	p := &Player{
+		sampleRate:          rate,
+		expectedDuration:    length,
+		playbackStartTime:   time.Now().Add(-length),
+		completionThreshold: 0.95,
+	}
+
+	var positions []time.Duration
+	finished := make(chan struct{}, 1)
+	var buffering []bool
+	p.OnPositionChanged(func(pos time.Duration) { positions = append(positions, pos) })
+	p.OnFinished(func() { finished <- struct{}{} })
+	p.OnBufferingChanged(func(b, _ bool) { buffering = append(buffering, b) })
+
+	done := make(chan struct{})
+	output := beep.Seq(beep.Silence(rate.N(length)), beep.Callback(func() { close(done) }))
+
+	p.setBuffering(true, false)
+	p.setBuffering(false, false)
+
+	// Pull the output the way the speaker's mixer does, reporting the
+	// position as the progress tracker would.
+	buf := make([][2]float64, 512)
+	played := 0
+	for {
+		n, ok := output.Stream(buf)
+		played += n
+		p.updatePositionCallback(rate.D(played))
+		if !ok {
+			break
+		}
+	}
+
+	p.awaitEnd(context.Background(), &types.Song{Name: "test"}, done)
+
+	select {
+	case <-finished:
+	default:
+		t.Fatal("finished callback was not called at the end of the track")
+	}
+	if len(positions) == 0 || positions[len(positions)-1] < length {
+		t.Fatalf("last position %v, want at least %v", positions, length)
+	}
+	if len(buffering) != 2 || !buffering[0] || buffering[1] {
+		t.Fatalf("buffering callbacks %v, want [true false]", buffering)
+	}
+}
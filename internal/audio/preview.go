@@ -0,0 +1,260 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio/speaker"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	// previewLength is how much of a song a preview plays.
+	previewLength = 10 * time.Second
+	// previewLevel scales the playback volume down for previews.
+	previewLevel = 0.6
+	// previewBytes is how much of a song that is neither local nor cached
+	// is fetched for a preview, enough for previewLength at 320 kbps.
+	previewBytes = 512 << 10
+)
+
+// preview is a song playing on top of whatever else the speaker plays,
+// either a short snippet or a whole song on the cue output.
+type preview struct {
+	cancel    context.CancelFunc
+	song      *types.Song
+	cue       bool
+	whole     bool
+	output    *trackOutput
+	streamer  beep.StreamSeekCloser
+	closeOnce sync.Once
+}
+
+func (pv *preview) close() {
+	pv.closeOnce.Do(func() {
+		if pv.streamer != nil {
+			_ = pv.streamer.Close()
+		}
+	})
+}
+
+// Preview plays a quiet snippet of song from a third of the way in,
+// replacing any snippet already playing but not a song pre-listened to with
+// Cue. Songs that are not on disk are
+// previewed from their first bytes. With a cue device open the snippet
+// plays there, next to the main mix; otherwise nothing is previewed while a
+// song plays.
+func (p *Player) Preview(song *types.Song) {
+	if song == nil || p.CueSong() != nil {
+		return
+	}
+	p.startPreview(song, previewLength, speaker.CueDevice() != "")
+}
+
+// Cue plays song from its start on the cue device, for pre-listening on
+// headphones while the main mix goes on, replacing any preview.
+func (p *Player) Cue(song *types.Song) error {
+	if song == nil {
+		return fmt.Errorf("song cannot be nil")
+	}
+	if speaker.CueDevice() == "" {
+		return fmt.Errorf("no pre-listen device is available")
+	}
+	p.startPreview(song, 0, true)
+	return nil
+}
+
+// CueSong returns the song being pre-listened to with Cue, or nil.
+func (p *Player) CueSong() *types.Song {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.preview == nil || !p.preview.whole {
+		return nil
+	}
+	return p.preview.song
+}
+
+// startPreview plays length of song, or all of it for 0, on the cue output
+// or the main one.
+func (p *Player) startPreview(song *types.Song, length time.Duration, cue bool) {
+	p.stopPreview(func(*preview) bool { return true })
+
+	p.mu.Lock()
+	if !cue && p.playing && !p.paused {
+		p.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	pv := &preview{cancel: cancel, song: song, cue: cue, whole: length == 0}
+	p.preview = pv
+	p.mu.Unlock()
+
+	go p.loadPreview(ctx, pv, song, length)
+}
+
+// StopPreview cuts a snippet started with Preview short.
+func (p *Player) StopPreview() {
+	p.stopPreview(func(pv *preview) bool { return !pv.whole })
+}
+
+// StopCue stops pre-listening to the song started with Cue.
+func (p *Player) StopCue() {
+	p.stopPreview(func(pv *preview) bool { return pv.whole })
+}
+
+// yieldPreview stops a preview on the main output, which would play over
+// the song about to be heard there. One on the cue output carries on.
+func (p *Player) yieldPreview() {
+	p.stopPreview(func(pv *preview) bool { return !pv.cue })
+}
+
+// stopPreview stops the playing preview if stop picks it.
+func (p *Player) stopPreview(stop func(*preview) bool) {
+	p.mu.Lock()
+	pv := p.preview
+	if pv == nil || !stop(pv) {
+		p.mu.Unlock()
+		return
+	}
+	p.preview = nil
+	p.mu.Unlock()
+
+	pv.cancel()
+	speaker.Lock()
+	if pv.output != nil {
+		pv.output.endAfter(0, nil)
+	}
+	pv.close()
+	speaker.Unlock()
+}
+
+func (p *Player) loadPreview(ctx context.Context, pv *preview, song *types.Song, length time.Duration) {
+	streamer, format, err := p.openPreview(ctx, song, length > 0)
+	if err != nil {
+		if p.debug && ctx.Err() == nil {
+			log.Printf("[AUDIO] Failed to load preview of %s: %v", song.Name, err)
+		}
+		p.mu.Lock()
+		if p.preview == pv {
+			p.preview = nil
+		}
+		p.mu.Unlock()
+		return
+	}
+
+	level := 1.0
+	var source beep.Streamer = streamer
+	if length > 0 {
+		if n := streamer.Len(); n > 0 {
+			if err := streamer.Seek(n / 3); err != nil && p.debug {
+				log.Printf("[AUDIO] Previewing %s from the start: %v", song.Name, err)
+			}
+		}
+		level = previewLevel
+	}
+	if format.SampleRate != p.sampleRate {
+		source = beep.Resample(4, format.SampleRate, p.sampleRate, source)
+	}
+	if length > 0 {
+		source = beep.Take(p.sampleRate.N(length), source)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Stopped, replaced or overtaken by playback while loading.
+	if p.preview != pv || (!pv.cue && p.playing && !p.paused) {
+		_ = streamer.Close()
+		return
+	}
+
+	pv.streamer = streamer
+	pv.output = newTrackOutput(p.mkVolume(source, p.level*level))
+	pv.output.onEnd = func() { p.finishPreview(pv) }
+	if pv.cue {
+		speaker.PlayCue(pv.output)
+	} else {
+		speaker.Play(pv.output)
+	}
+
+	if p.debug {
+		log.Printf("[AUDIO] Previewing %s (cue: %v)", song.Name, pv.cue)
+	}
+}
+
+// openPreview decodes song from its local or cached file, or else from its
+// stream: only the first previewBytes of it for a snippet, all of it
+// otherwise.
+func (p *Player) openPreview(ctx context.Context, song *types.Song, snippet bool) (beep.StreamSeekCloser, beep.Format, error) {
+	for _, path := range []string{localSongPath(song), p.cachedSongPath(song)} {
+		if path == "" {
+			continue
+		}
+		if f, err := os.Open(path); err == nil {
+			return decodePreview(f, path)
+		}
+	}
+
+	if song.File == "" {
+		return nil, beep.Format{}, fmt.Errorf("song has no file")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", song.File, nil)
+	if err != nil {
+		return nil, beep.Format{}, fmt.Errorf("create preview request: %w", err)
+	}
+	req.Header.Set("User-Agent", "AMP/1.0.0")
+	req.Header.Set("Accept", "audio/mpeg, audio/mp4, audio/*")
+	req.Header.Set("Accept-Encoding", "identity")
+	if snippet {
+		req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", previewBytes-1))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, beep.Format{}, fmt.Errorf("fetch preview: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, beep.Format{}, fmt.Errorf("fetch preview: HTTP %d", resp.StatusCode)
+	}
+	if !snippet {
+		// Decoded as it downloads; closing the streamer ends the download.
+		return decodePreview(resp.Body, "")
+	}
+	defer resp.Body.Close()
+
+	// A server ignoring the range sends the whole song; the start will do.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, previewBytes))
+	if err != nil && len(data) == 0 {
+		return nil, beep.Format{}, fmt.Errorf("read preview: %w", err)
+	}
+	return decodeAudio(io.NopCloser(bytes.NewReader(data)), "")
+}
+
+// decodePreview decodes reader, closing it when it can't be.
+func decodePreview(reader io.ReadCloser, path string) (beep.StreamSeekCloser, beep.Format, error) {
+	streamer, format, err := decodeAudio(reader, path)
+	if err != nil {
+		reader.Close()
+		return nil, beep.Format{}, fmt.Errorf("decode preview: %w", err)
+	}
+	return streamer, format, nil
+}
+
+// finishPreview releases a preview that played to its end.
+func (p *Player) finishPreview(pv *preview) {
+	p.mu.Lock()
+	if p.preview == pv {
+		p.preview = nil
+	}
+	p.mu.Unlock()
+	pv.close()
+}
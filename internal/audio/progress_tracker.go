@@ -184,46 +184,30 @@ func (pt *ProgressTracker) updatePosition() {
 
 // -------- Buffering support --------
 
+// bufferStallTimeout is how long the initial buffer wait goes on without any
+// new data before playback starts with whatever has arrived.
+const bufferStallTimeout = 10 * time.Second
+
 type BufferManager struct {
-	cfg           *config.Config
-	minBufferTime time.Duration
-	bufferPercent float64
-	debug         bool
+	cfg   *config.Config
+	debug bool
+	// stallTimeout ends the wait once the download stops making progress.
+	stallTimeout time.Duration
 }
 
 func NewBufferManager(cfg *config.Config, debug bool) *BufferManager {
 	return &BufferManager{
-		cfg:           cfg,
-		minBufferTime: 10 * time.Second,
-		bufferPercent: 0.05,
-		debug:         debug,
-	}
-}
-
-func (bm *BufferManager) GetMinBufferPercent() float64 {
-	return bm.bufferPercent
-}
-
-func (bm *BufferManager) SetMinBufferPercent(percent float64) {
-	if percent < 0.01 {
-		percent = 0.01
-	}
-	if percent > 0.5 {
-		percent = 0.5
+		cfg:          cfg,
+		debug:        debug,
+		stallTimeout: bufferStallTimeout,
 	}
-	bm.bufferPercent = percent
-}
-
-func (bm *BufferManager) SetMinBufferTime(duration time.Duration) {
-	if duration < time.Second {
-		duration = time.Second
-	}
-	bm.minBufferTime = duration
 }
 
 // WaitForSufficientBuffer blocks until enough data is available to start smooth playback
 // (initial buffer), or until the context is canceled. It works only for our StreamReader;
-// other readers are treated as already buffered.
+// other readers are treated as already buffered. There is no fixed deadline: a slow
+// download that keeps delivering data is waited for until the requested pre-buffer is
+// in, and only a download that stops receiving data ends the wait early.
 func (bm *BufferManager) WaitForSufficientBuffer(ctx context.Context, reader io.Reader) bool {
 	sr, ok := reader.(*StreamReader)
 	if !ok {
@@ -237,10 +221,6 @@ func (bm *BufferManager) WaitForSufficientBuffer(ctx context.Context, reader io.
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
-	timeout := 30 * time.Second
-	timeoutTimer := time.NewTimer(timeout)
-	defer timeoutTimer.Stop()
-
 	for {
 		select {
 		case <-ctx.Done():
@@ -249,12 +229,6 @@ func (bm *BufferManager) WaitForSufficientBuffer(ctx context.Context, reader io.
 			}
 			return false
 
-		case <-timeoutTimer.C:
-			if bm.debug {
-				log.Printf("[BUFFER_MANAGER] Buffer wait timeout - proceeding anyway")
-			}
-			return true
-
 		case <-ticker.C:
 			if sr.IsComplete() {
 				if bm.debug {
@@ -273,12 +247,9 @@ func (bm *BufferManager) WaitForSufficientBuffer(ctx context.Context, reader io.
 				return true
 			}
 
-			// Secondary criteria: percentage-based threshold if total size known
-			dl, total, pct := sr.GetProgress()
-			if total > 0 && pct >= bm.bufferPercent {
+			if sr.Stalled(bm.stallTimeout) {
 				if bm.debug {
-					log.Printf("[BUFFER_MANAGER] Percent threshold reached: %.1f%% (%d/%d), starting playback",
-						pct*100, dl, total)
+					log.Printf("[BUFFER_MANAGER] Download stalled - proceeding anyway")
 				}
 				return true
 			}
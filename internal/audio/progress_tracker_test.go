@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newSlowStream returns a StreamReader that needs want bytes before it is
+// ready, with nothing downloaded yet.
+func newSlowStream(want int64) *StreamReader {
+	sr := &StreamReader{
+		url:           "test://slow",
+		minBufferSize: want,
+		lastReadTime:  time.Now(),
+		meter:         &throughputMeter{},
+	}
+	sr.cond = sync.NewCond(&sr.mutex)
+	return sr
+}
+
+// feed appends n bytes the way the download loop does.
+func feed(sr *StreamReader, n int) {
+	sr.mutex.Lock()
+	sr.buffer = append(sr.buffer, make([]byte, n)...)
+	sr.downloaded += int64(n)
+	sr.lastDataTime = time.Now()
+	if sr.downloaded >= sr.minBufferSize {
+		sr.bufferReady = true
+	}
+	sr.mutex.Unlock()
+	sr.cond.Broadcast()
+}
+
+// TestWaitForSufficientBufferOutlastsSlowDownload checks that a download
+// taking several stall timeouts, but still delivering data, is waited for
+// until the whole requested pre-buffer is in.
+func TestWaitForSufficientBufferOutlastsSlowDownload(t *testing.T) {
+	const chunk, chunks = 100, 12
+	sr := newSlowStream(chunk * chunks)
+	bm := &BufferManager{stallTimeout: 300 * time.Millisecond}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for i := 0; i < chunks; i++ {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			feed(sr, chunk)
+		}
+	}()
+
+	start := time.Now()
+	if !bm.WaitForSufficientBuffer(context.Background(), sr) {
+		t.Fatal("wait failed")
+	}
+	if !sr.IsBufferReady() {
+		downloaded, _, _ := sr.GetProgress()
+		t.Fatalf("wait ended after %v with %d of %d bytes", time.Since(start), downloaded, chunk*chunks)
+	}
+	if elapsed := time.Since(start); elapsed <= bm.stallTimeout {
+		t.Fatalf("wait took %v, the download needs well over %v", elapsed, bm.stallTimeout)
+	}
+}
+
+func TestWaitForSufficientBufferGivesUpOnStall(t *testing.T) {
+	sr := newSlowStream(1000)
+	bm := &BufferManager{stallTimeout: 200 * time.Millisecond}
+	feed(sr, 100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !bm.WaitForSufficientBuffer(ctx, sr) {
+		t.Fatal("stalled download should start playback with what arrived")
+	}
+	if ctx.Err() != nil {
+		t.Fatal("wait did not end on the stalled download")
+	}
+	if sr.IsBufferReady() {
+		t.Fatal("buffer unexpectedly ready")
+	}
+}
@@ -0,0 +1,231 @@
+package audio
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio/speaker"
+)
+
+// sleepFade is how long playback fades out before a fading sleep timer
+// pauses it.
+const sleepFade = 30 * time.Second
+
+// SleepTimer pauses playback after a set time, or once the current track
+// ends.
+type SleepTimer struct {
+	player *Player
+
+	mu         sync.Mutex
+	deadline   time.Time
+	endOfTrack bool
+	fade       bool
+	fading     bool
+	timer      *time.Timer
+	fadeTimer  *time.Timer
+	onExpired  func()
+	onChanged  func()
+}
+
+// SleepState describes what a sleep timer is waiting for.
+type SleepState struct {
+	Active bool
+	// EndOfTrack is set when playback stops at the end of the current
+	// track rather than at a set time.
+	EndOfTrack bool
+	Remaining  time.Duration
+	Fade       bool
+}
+
+func NewSleepTimer(player *Player) *SleepTimer {
+	return &SleepTimer{player: player}
+}
+
+// OnExpired registers callback to run once the timer paused playback. It
+// runs on the timer's goroutine.
+func (t *SleepTimer) OnExpired(callback func()) {
+	t.mu.Lock()
+	t.onExpired = callback
+	t.mu.Unlock()
+}
+
+// OnChanged registers callback to run when the timer is set, cancelled or
+// done.
+func (t *SleepTimer) OnChanged(callback func()) {
+	t.mu.Lock()
+	t.onChanged = callback
+	t.mu.Unlock()
+}
+
+// Start pauses playback after d, fading it out over the last sleepFade (or
+// all of d, when shorter) if fade is set. It replaces any timer already set.
+func (t *SleepTimer) Start(d time.Duration, fade bool) {
+	t.mu.Lock()
+	t.resetLocked()
+	t.deadline = time.Now().Add(d)
+	t.fade = fade
+	// The callbacks take t.mu before anything else, so they see the timers
+	// assigned, and do nothing once the timer was replaced or cancelled.
+	var timer, fadeTimer *time.Timer
+	timer = time.AfterFunc(d, func() { t.expire(timer) })
+	t.timer = timer
+	if fade {
+		fadeTimer = time.AfterFunc(max(d-sleepFade, 0), func() {
+			t.mu.Lock()
+			if t.fadeTimer != fadeTimer {
+				t.mu.Unlock()
+				return
+			}
+			t.fading = true
+			t.mu.Unlock()
+			t.player.fadeOut(min(d, sleepFade))
+		})
+		t.fadeTimer = fadeTimer
+	}
+	t.mu.Unlock()
+
+	if t.player.debug {
+		log.Printf("[AUDIO] Sleep timer set for %v (fade: %v)", d, fade)
+	}
+	t.changed()
+}
+
+// StopAfterTrack stops playback once the current track ends. It replaces
+// any timer already set.
+func (t *SleepTimer) StopAfterTrack() {
+	t.mu.Lock()
+	t.resetLocked()
+	t.endOfTrack = true
+	t.mu.Unlock()
+
+	if t.player.debug {
+		log.Printf("[AUDIO] Sleep timer set for the end of the track")
+	}
+	t.changed()
+}
+
+// Cancel clears the timer, bringing the volume back if it was fading out.
+func (t *SleepTimer) Cancel() {
+	t.mu.Lock()
+	t.resetLocked()
+	t.mu.Unlock()
+	t.changed()
+}
+
+// State returns what the timer is waiting for.
+func (t *SleepTimer) State() SleepState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case t.endOfTrack:
+		return SleepState{Active: true, EndOfTrack: true}
+	case t.timer != nil:
+		return SleepState{Active: true, Remaining: max(time.Until(t.deadline), 0), Fade: t.fade}
+	}
+	return SleepState{}
+}
+
+// AtEndOfTrack reports whether playback stops once the current track ends.
+func (t *SleepTimer) AtEndOfTrack() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.endOfTrack
+}
+
+// TrackEnded clears a timer waiting for the end of the track and reports
+// whether there was one, in which case the caller stops instead of moving
+// on to the next track.
+func (t *SleepTimer) TrackEnded() bool {
+	t.mu.Lock()
+	waiting := t.endOfTrack
+	t.endOfTrack = false
+	t.mu.Unlock()
+
+	if waiting {
+		t.changed()
+	}
+	return waiting
+}
+
+// resetLocked stops the pending timers and undoes a fade in progress. It is
+// called with t.mu held.
+func (t *SleepTimer) resetLocked() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	if t.fadeTimer != nil {
+		t.fadeTimer.Stop()
+		t.fadeTimer = nil
+	}
+	if t.fading {
+		t.player.restoreGain()
+		t.fading = false
+	}
+	t.endOfTrack = false
+	t.fade = false
+}
+
+func (t *SleepTimer) expire(timer *time.Timer) {
+	t.mu.Lock()
+	if t.timer != timer {
+		t.mu.Unlock()
+		return
+	}
+	t.timer = nil
+	t.fadeTimer = nil
+	t.fading = false
+	t.fade = false
+	onExpired := t.onExpired
+	t.mu.Unlock()
+
+	if err := t.player.Pause(); err != nil {
+		log.Printf("[AUDIO] Sleep timer failed to pause: %v", err)
+	}
+	t.player.restoreGain()
+
+	if t.player.debug {
+		log.Printf("[AUDIO] Sleep timer paused playback")
+	}
+	if onExpired != nil {
+		onExpired()
+	}
+	t.changed()
+}
+
+func (t *SleepTimer) changed() {
+	t.mu.Lock()
+	onChanged := t.onChanged
+	t.mu.Unlock()
+	if onChanged != nil {
+		onChanged()
+	}
+}
+
+// fadeOut ramps the playing track down to silence over d.
+func (p *Player) fadeOut(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.fader == nil {
+		return
+	}
+	speaker.Lock()
+	p.fader.fadeTo(0, p.sampleRate.N(d))
+	speaker.Unlock()
+}
+
+// restoreGain undoes fadeOut, so playback is heard again once resumed.
+func (p *Player) restoreGain() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.fader == nil {
+		return
+	}
+	speaker.Lock()
+	p.fader.fadeTo(1, 0)
+	speaker.Unlock()
+}
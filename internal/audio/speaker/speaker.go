@@ -0,0 +1,86 @@
+// Package speaker plays beep streamers through the system audio device.
+//
+// It mirrors the API of github.com/gopxl/beep/speaker but keeps hold of the
+// output, so it can be torn down and recreated when the audio device goes
+// away (suspend/resume, headphones unplugged). beep's own speaker can only
+// be initialized once per process.
+//
+// By default output goes through oto, which needs no C libraries and builds
+// for every platform, always on the system default device. Built with the
+// portaudio tag it goes through PortAudio instead, which can list the
+// output devices, move output to another one and open a cue output on a
+// second device, such as headphones, with a mixer of its own for
+// pre-listening.
+package speaker
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/gopxl/beep"
+)
+
+const channelCount = 2
+
+// ErrDeviceSelection is returned for device choices the audio backend this
+// build plays through cannot make.
+var ErrDeviceSelection = errors.New("choosing an output device needs a build with the portaudio tag")
+
+var (
+	mu       sync.Mutex
+	mixer    beep.Mixer
+	cueMixer beep.Mixer
+)
+
+// mix fills buf from m, padding with silence what it cannot fill.
+func mix(m *beep.Mixer, buf [][2]float64) {
+	mu.Lock()
+	filled, _ := m.Stream(buf)
+	mu.Unlock()
+	for i := filled; i < len(buf); i++ {
+		buf[i] = [2]float64{}
+	}
+}
+
+func clip(v float64) float64 {
+	if v < -1 {
+		return -1
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// Lock locks the speaker. While locked, the speaker won't pull new data from
+// the playing streamers of either output, so they can be modified safely.
+func Lock() {
+	mu.Lock()
+}
+
+// Unlock unlocks the speaker.
+func Unlock() {
+	mu.Unlock()
+}
+
+// Play starts playing all provided streamers through the speaker.
+func Play(s ...beep.Streamer) {
+	mu.Lock()
+	mixer.Add(s...)
+	mu.Unlock()
+}
+
+// Clear removes all currently playing streamers from the speaker.
+func Clear() {
+	mu.Lock()
+	mixer.Clear()
+	mu.Unlock()
+}
+
+// PlayCue starts playing all provided streamers through the cue output, once
+// it is open.
+func PlayCue(s ...beep.Streamer) {
+	mu.Lock()
+	cueMixer.Add(s...)
+	mu.Unlock()
+}
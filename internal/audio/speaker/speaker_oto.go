@@ -0,0 +1,153 @@
+//go:build !portaudio
+
+package speaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ebitengine/oto/v3"
+	"github.com/gopxl/beep"
+)
+
+const (
+	bytesPerSample  = 2 * channelCount
+	maxSampleBuffer = 512
+)
+
+var (
+	// deviceMu guards the context and player, which Reset replaces.
+	deviceMu         sync.Mutex
+	ctx              *oto.Context
+	player           *oto.Player
+	playerBufferSize int
+)
+
+// Init initializes audio playback. bufferSize is the number of samples
+// buffered between the mixer and the device, split between driver and player.
+func Init(sampleRate beep.SampleRate, bufferSize int) error {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+
+	if ctx != nil {
+		return errors.New("speaker cannot be initialized more than once")
+	}
+
+	c, ready, err := oto.NewContext(&oto.NewContextOptions{
+		SampleRate:   int(sampleRate),
+		ChannelCount: channelCount,
+		Format:       oto.FormatSignedInt16LE,
+		BufferSize:   sampleRate.D(bufferSize / 2),
+	})
+	if err != nil {
+		return fmt.Errorf("create audio context: %w", err)
+	}
+	<-ready
+
+	ctx = c
+	playerBufferSize = bufferSize / 2
+	startPlayer()
+	return nil
+}
+
+// Reset recreates the output player on top of the existing context and
+// restarts the driver. Streamers queued in the mixer keep their position and
+// continue on the new player.
+func Reset() error {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+
+	if ctx == nil {
+		return errors.New("speaker is not initialized")
+	}
+
+	if err := ctx.Suspend(); err != nil {
+		return fmt.Errorf("suspend audio context: %w", err)
+	}
+	if player != nil {
+		_ = player.Close()
+	}
+	startPlayer()
+
+	if err := ctx.Resume(); err != nil {
+		return fmt.Errorf("resume audio context: %w", err)
+	}
+	return nil
+}
+
+// SetDevice does nothing: oto always plays on the system default device.
+func SetDevice(name string) error {
+	return nil
+}
+
+// Device returns an empty name, as the system default device is all oto
+// plays on.
+func Device() string {
+	return ""
+}
+
+// Devices returns ErrDeviceSelection: oto cannot list output devices.
+func Devices() ([]string, error) {
+	return nil, ErrDeviceSelection
+}
+
+// SetCueDevice returns ErrDeviceSelection for any device, as oto has no
+// second output; an empty name is accepted and leaves the cue closed.
+func SetCueDevice(name string) error {
+	if name == "" {
+		return nil
+	}
+	return ErrDeviceSelection
+}
+
+// CueDevice returns an empty name, as the cue output is never open.
+func CueDevice() string {
+	return ""
+}
+
+// Err returns the last error reported by the audio driver, if any.
+func Err() error {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+
+	if ctx == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if player != nil {
+		return player.Err()
+	}
+	return nil
+}
+
+func startPlayer() {
+	player = ctx.NewPlayer(&sampleReader{})
+	player.SetBufferSize(playerBufferSize * bytesPerSample)
+	player.Play()
+}
+
+// sampleReader converts mixer output to signed 16-bit little-endian PCM.
+type sampleReader struct {
+	buf [][2]float64
+}
+
+func (r *sampleReader) Read(p []byte) (int, error) {
+	n := min(len(p)/bytesPerSample, maxSampleBuffer)
+	if cap(r.buf) < n {
+		r.buf = make([][2]float64, n)
+	}
+	samples := r.buf[:n]
+	mix(&mixer, samples)
+
+	for i, sample := range samples {
+		for c := range sample {
+			s := int16(clip(sample[c]) * (1<<15 - 1))
+			p[i*bytesPerSample+c*2] = byte(s)
+			p[i*bytesPerSample+c*2+1] = byte(s >> 8)
+		}
+	}
+	return n * bytesPerSample, nil
+}
@@ -0,0 +1,297 @@
+//go:build portaudio
+
+package speaker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gopxl/beep"
+	"github.com/gordonklaus/portaudio"
+)
+
+var (
+	samples    [][2]float64
+	cueSamples [][2]float64
+
+	// deviceMu guards the stream and the device it plays on, which Reset
+	// and SetDevice replace.
+	deviceMu    sync.Mutex
+	initialized bool
+	stream      *portaudio.Stream
+	sampleRate  beep.SampleRate
+	bufferSize  int
+	preferred   string
+	current     string
+	lastErr     error
+
+	cueStream    *portaudio.Stream
+	cuePreferred string
+	cueCurrent   string
+)
+
+// Init initializes audio playback. bufferSize is the number of samples
+// buffered between the mixer and the device.
+func Init(rate beep.SampleRate, size int) error {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+
+	if initialized {
+		return errors.New("speaker cannot be initialized more than once")
+	}
+	if err := portaudio.Initialize(); err != nil {
+		return fmt.Errorf("initialize audio backend: %w", err)
+	}
+
+	initialized = true
+	sampleRate = rate
+	bufferSize = size
+	return openStream()
+}
+
+// Reset closes the output stream and opens it again, on the preferred device
+// if it is back, or on the system default if it is gone. Streamers queued in
+// the mixer keep their position and continue on the new stream.
+func Reset() error {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+
+	if !initialized {
+		return errors.New("speaker is not initialized")
+	}
+	return reopen()
+}
+
+// SetDevice moves output to the device called name, or to the system default
+// for an empty name. Until the device is available the default is used.
+// Before Init it only picks the device Init opens.
+func SetDevice(name string) error {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+
+	preferred = name
+	if !initialized || name == current {
+		return nil
+	}
+	return reopen()
+}
+
+// Device returns the name of the device output is playing on.
+func Device() string {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+	return current
+}
+
+// Devices returns the names of the output devices of the system's default
+// audio host, as of the last Init or Reset.
+func Devices() ([]string, error) {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+
+	if !initialized {
+		return nil, errors.New("speaker is not initialized")
+	}
+	host, err := portaudio.DefaultHostApi()
+	if err != nil {
+		return nil, fmt.Errorf("get audio host: %w", err)
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, device := range host.Devices {
+		if device.MaxOutputChannels > 0 && !seen[device.Name] {
+			seen[device.Name] = true
+			names = append(names, device.Name)
+		}
+	}
+	return names, nil
+}
+
+// Err returns the error that kept the output stream from opening, if any.
+func Err() error {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+	return lastErr
+}
+
+// SetCueDevice opens the cue output on the device called name, or closes it
+// for an empty name. Unlike the main output it never falls back to the
+// default device, which would mix the cue into what everyone hears; while
+// the device is missing the cue output stays closed.
+func SetCueDevice(name string) error {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+
+	cuePreferred = name
+	if !initialized || name == cueCurrent {
+		return nil
+	}
+	closeCue()
+	return openCue()
+}
+
+// CueDevice returns the name of the device the cue output plays on, or
+// empty while it is closed.
+func CueDevice() string {
+	deviceMu.Lock()
+	defer deviceMu.Unlock()
+	return cueCurrent
+}
+
+// reopen closes the streams and restarts the backend, which lists the
+// devices attached since, before opening the streams again. It is called
+// with deviceMu held.
+func reopen() error {
+	if stream != nil {
+		_ = stream.Abort()
+		_ = stream.Close()
+		stream = nil
+	}
+	current = ""
+	closeCue()
+
+	if err := portaudio.Terminate(); err != nil {
+		return fmt.Errorf("stop audio backend: %w", err)
+	}
+	if err := portaudio.Initialize(); err != nil {
+		initialized = false
+		return fmt.Errorf("restart audio backend: %w", err)
+	}
+	if err := openStream(); err != nil {
+		return err
+	}
+	// A missing cue device leaves the main output working.
+	_ = openCue()
+	return nil
+}
+
+// openStream opens and starts the stream on the preferred device, falling
+// back to the default one. It is called with deviceMu held.
+func openStream() error {
+	var err error
+	if preferred != "" {
+		if device := findDevice(preferred); device != nil {
+			if stream, err = startStream(device, fill); err == nil {
+				current = device.Name
+				lastErr = nil
+				return nil
+			}
+		}
+	}
+
+	device, defaultErr := portaudio.DefaultOutputDevice()
+	if defaultErr != nil {
+		lastErr = fmt.Errorf("find default output device: %w", defaultErr)
+		return lastErr
+	}
+	if stream, defaultErr = startStream(device, fill); defaultErr != nil {
+		lastErr = errors.Join(err, defaultErr)
+		return lastErr
+	}
+	current = device.Name
+	lastErr = nil
+	return nil
+}
+
+// openCue opens the cue output on the preferred cue device, if one is set.
+// It is called with deviceMu held.
+func openCue() error {
+	if cuePreferred == "" {
+		return nil
+	}
+	device := findDevice(cuePreferred)
+	if device == nil {
+		return fmt.Errorf("cue device %q not found", cuePreferred)
+	}
+	s, err := startStream(device, fillCue)
+	if err != nil {
+		return err
+	}
+	cueStream = s
+	cueCurrent = device.Name
+	return nil
+}
+
+// closeCue closes the cue output and drops what was playing on it. It is
+// called with deviceMu held.
+func closeCue() {
+	if cueStream != nil {
+		_ = cueStream.Abort()
+		_ = cueStream.Close()
+		cueStream = nil
+	}
+	cueCurrent = ""
+
+	mu.Lock()
+	cueMixer.Clear()
+	mu.Unlock()
+}
+
+// findDevice returns the output device called name, or nil if there is none.
+func findDevice(name string) *portaudio.DeviceInfo {
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil
+	}
+	for _, device := range devices {
+		if device.Name == name && device.MaxOutputChannels > 0 {
+			return device
+		}
+	}
+	return nil
+}
+
+func startStream(device *portaudio.DeviceInfo, callback func([][]float32)) (*portaudio.Stream, error) {
+	params := portaudio.HighLatencyParameters(nil, device)
+	params.SampleRate = float64(sampleRate)
+	if latency := sampleRate.D(bufferSize); latency > params.Output.Latency {
+		params.Output.Latency = latency
+	}
+
+	s, err := portaudio.OpenStream(params, callback)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", device.Name, err)
+	}
+	if err := s.Start(); err != nil {
+		_ = s.Close()
+		return nil, fmt.Errorf("start %s: %w", device.Name, err)
+	}
+	return s, nil
+}
+
+// fill is the main stream callback.
+func fill(out [][]float32) {
+	fillFrom(&mixer, &samples, out)
+}
+
+// fillCue is the cue stream callback.
+func fillCue(out [][]float32) {
+	fillFrom(&cueMixer, &cueSamples, out)
+}
+
+// fillFrom writes the output of m to one buffer per channel, using samples
+// as scratch space; a mono device gets both channels mixed down.
+func fillFrom(m *beep.Mixer, samples *[][2]float64, out [][]float32) {
+	if len(out) == 0 {
+		return
+	}
+	n := len(out[0])
+	if cap(*samples) < n {
+		*samples = make([][2]float64, n)
+	}
+	buf := (*samples)[:n]
+
+	mix(m, buf)
+
+	for i, sample := range buf {
+		if len(out) == 1 {
+			out[0][i] = float32(clip((sample[0] + sample[1]) / 2))
+			continue
+		}
+		for c := range out {
+			out[c][i] = float32(clip(sample[min(c, channelCount-1)]))
+		}
+	}
+}
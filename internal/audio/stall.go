@@ -0,0 +1,144 @@
+package audio
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio/speaker"
+)
+
+const (
+	// rebufferLowWater pauses playback before the decoder runs out of data.
+	rebufferLowWater = time.Second
+	// stallTimeout is how long a download may go without data before it is
+	// reported as stalled.
+	stallTimeout       = 3 * time.Second
+	stallCheckInterval = 250 * time.Millisecond
+)
+
+// OnBufferingChanged is called when playback pauses to rebuffer or resumes.
+// stalled is set when the download has stopped receiving data altogether.
+// It is called from the player's goroutine.
+func (p *Player) OnBufferingChanged(callback func(buffering, stalled bool)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bufferingCallback = callback
+}
+
+// IsBuffering reports whether playback is paused waiting for the stream.
+func (p *Player) IsBuffering() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.buffering
+}
+
+// monitorStalls pauses playback when the stream falls behind and resumes it
+// once enough audio has arrived again. It exits when ctx is canceled, the
+// download completes or playback stops.
+func (p *Player) monitorStalls(ctx context.Context, sr *StreamReader) {
+	ticker := time.NewTicker(stallCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.setBuffering(false, false)
+			return
+		case <-ticker.C:
+		}
+
+		if !p.checkStall(sr) {
+			return
+		}
+	}
+}
+
+// checkStall runs one monitor step and reports whether monitoring should go on.
+func (p *Player) checkStall(sr *StreamReader) bool {
+	complete := sr.IsComplete()
+	stalled := sr.Stalled(stallTimeout)
+	buffered := sr.BufferedDuration()
+
+	p.mu.Lock()
+	if p.ctrl == nil || !p.playing {
+		p.mu.Unlock()
+		p.setBuffering(false, false)
+		return false
+	}
+
+	if p.paused {
+		// The user paused; a pending rebuffer no longer applies.
+		wasBuffering := p.buffering
+		p.mu.Unlock()
+		if wasBuffering {
+			p.setBuffering(false, false)
+		}
+		return !complete
+	}
+
+	ahead := buffered - p.position
+	resumeAt := time.Duration(p.cfg.Audio.PrebufferSeconds) * time.Second
+	duration := p.expectedDuration
+	if duration <= 0 {
+		duration = p.duration
+	}
+	if remaining := duration - p.position; remaining > 0 && remaining < resumeAt {
+		resumeAt = remaining
+	}
+
+	switch {
+	case !p.buffering && !complete && ahead < rebufferLowWater:
+		speaker.Lock()
+		p.ctrl.Paused = true
+		speaker.Unlock()
+		if p.progressTracker.IsRunning() {
+			p.progressTracker.Stop()
+		}
+		p.mu.Unlock()
+
+		if p.debug {
+			log.Printf("[AUDIO] Rebuffering: %v ahead (stalled: %v)", ahead, stalled)
+		}
+		p.setBuffering(true, stalled)
+
+	case p.buffering && (complete || ahead >= resumeAt):
+		speaker.Lock()
+		p.ctrl.Paused = false
+		speaker.Unlock()
+		if !p.progressTracker.IsRunning() {
+			p.progressTracker.Start(p.updatePositionCallback)
+		}
+		p.mu.Unlock()
+
+		if p.debug {
+			log.Printf("[AUDIO] Buffer recovered: %v ahead, resuming", ahead)
+		}
+		p.setBuffering(false, false)
+
+	case p.buffering && stalled != p.stalled:
+		p.mu.Unlock()
+		p.setBuffering(true, stalled)
+
+	default:
+		p.mu.Unlock()
+	}
+
+	return !complete
+}
+
+func (p *Player) setBuffering(buffering, stalled bool) {
+	p.mu.Lock()
+	if p.buffering == buffering && p.stalled == stalled {
+		p.mu.Unlock()
+		return
+	}
+	p.buffering = buffering
+	p.stalled = stalled
+	cb := p.bufferingCallback
+	p.mu.Unlock()
+
+	if cb != nil {
+		cb(buffering, stalled)
+	}
+}
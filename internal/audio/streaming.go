@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"strconv"
 	"sync"
 	"time"
 )
 
+// assumedBytesPerSecond sizes the pre-buffer before the real bitrate is known
+// (320 kbps, the highest common MP3 bitrate, so the estimate errs on the safe side).
+const assumedBytesPerSecond = 320 * 1000 / 8
+
 type StreamReader struct {
 	url        string
 	buffer     []byte
@@ -29,14 +34,31 @@ type StreamReader struct {
 	minBufferSize int64
 	bufferReady   bool
 	lastReadTime  time.Time
+	lastDataTime  time.Time
+
+	// prebuffer is how much audio must be downloaded before playback starts;
+	// expected is the track length used to turn it into bytes.
+	prebuffer time.Duration
+	expected  time.Duration
+	meter     *throughputMeter
+
+	// contentType is the media type the server sent, without parameters.
+	contentType string
 }
 
-func (sm *StreamManager) CreateStream(ctx context.Context, url string) (io.ReadCloser, error) {
+// CreateStream starts (or reuses) a download of url. expected is the track
+// length if known and is used to size the pre-buffer in bytes.
+func (sm *StreamManager) CreateStream(ctx context.Context, url string, expected time.Duration) (io.ReadCloser, error) {
+	prebuffer := time.Duration(sm.cfg.Audio.PrebufferSeconds) * time.Second
+
 	if existing, ok := sm.activeStreams.Load(url); ok {
 		reader := existing.(*StreamReader)
 		reader.mutex.Lock()
 		reader.position = 0
-		reader.bufferReady = false
+		reader.prebuffer = prebuffer
+		reader.expected = expected
+		reader.updateMinBufferSize()
+		reader.bufferReady = reader.downloaded >= reader.minBufferSize
 		reader.lastReadTime = time.Now()
 		reader.mutex.Unlock()
 
@@ -48,15 +70,18 @@ func (sm *StreamManager) CreateStream(ctx context.Context, url string) (io.ReadC
 
 	streamCtx, cancel := context.WithCancel(ctx)
 	reader := &StreamReader{
-		url:           url,
-		ctx:           streamCtx,
-		cancel:        cancel,
-		httpClient:    sm.httpClient,
-		debug:         sm.debug,
-		minBufferSize: 256 * 1024, // ~256KB before decode starts
-		lastReadTime:  time.Now(),
+		url:          url,
+		ctx:          streamCtx,
+		cancel:       cancel,
+		httpClient:   sm.httpClient,
+		debug:        sm.debug,
+		lastReadTime: time.Now(),
+		prebuffer:    prebuffer,
+		expected:     expected,
+		meter:        &sm.throughput,
 	}
 	reader.cond = sync.NewCond(&reader.mutex)
+	reader.updateMinBufferSize()
 
 	sm.activeStreams.Store(url, reader)
 
@@ -69,6 +94,26 @@ func (sm *StreamManager) CreateStream(ctx context.Context, url string) (io.ReadC
 	return reader, nil
 }
 
+// Throughput returns the recent download speed in bytes per second, or 0
+// before anything was measured.
+func (sm *StreamManager) Throughput() float64 {
+	return sm.throughput.Rate()
+}
+
+// PrefetchDepth returns how many upcoming tracks are worth downloading ahead
+// of time: one on fast connections, more when the network barely keeps up.
+func (sm *StreamManager) PrefetchDepth() int {
+	rate := sm.throughput.Rate()
+	switch {
+	case rate <= 0 || rate >= 4*assumedBytesPerSecond:
+		return 1
+	case rate >= assumedBytesPerSecond:
+		return 2
+	default:
+		return 3
+	}
+}
+
 func (sm *StreamManager) GetDownloadProgress() float64 {
 	progress := 0.0
 	count := 0
@@ -98,6 +143,52 @@ func (sm *StreamManager) CleanupStreams() {
 	})
 }
 
+// CloseStream closes the stream for url, if any, and forgets it.
+func (sm *StreamManager) CloseStream(url string) {
+	if value, ok := sm.activeStreams.LoadAndDelete(url); ok {
+		if reader, ok := value.(*StreamReader); ok {
+			reader.Close()
+		}
+	}
+}
+
+// BufferUsage returns the number of open streams and the bytes they hold.
+func (sm *StreamManager) BufferUsage() (streams int, bytes int64) {
+	sm.activeStreams.Range(func(key, value interface{}) bool {
+		if reader, ok := value.(*StreamReader); ok {
+			reader.mutex.RLock()
+			bytes += int64(len(reader.buffer))
+			reader.mutex.RUnlock()
+			streams++
+		}
+		return true
+	})
+	return streams, bytes
+}
+
+// ReleaseExcept closes every stream other than the ones in keep and frees
+// its buffer. It returns the number of streams released.
+func (sm *StreamManager) ReleaseExcept(keep ...string) int {
+	released := 0
+	sm.activeStreams.Range(func(key, value interface{}) bool {
+		for _, url := range keep {
+			if key == url {
+				return true
+			}
+		}
+		if reader, ok := value.(*StreamReader); ok {
+			reader.Close()
+			reader.mutex.Lock()
+			reader.buffer = nil
+			reader.mutex.Unlock()
+		}
+		sm.activeStreams.Delete(key)
+		released++
+		return true
+	})
+	return released
+}
+
 func (sm *StreamManager) Close() {
 	sm.CleanupStreams()
 }
@@ -162,6 +253,7 @@ func (sr *StreamReader) startDownload() {
 		if v, perr := strconv.ParseInt(cl, 10, 64); perr == nil {
 			sr.mutex.Lock()
 			sr.totalSize = v
+			sr.updateMinBufferSize()
 			sr.mutex.Unlock()
 			if sr.debug {
 				log.Printf("[STREAM_READER] Content-Length: %d bytes (%.2f MB)", v, float64(v)/(1024*1024))
@@ -169,6 +261,14 @@ func (sr *StreamReader) startDownload() {
 		}
 	}
 
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		if mediaType, _, perr := mime.ParseMediaType(ct); perr == nil {
+			sr.mutex.Lock()
+			sr.contentType = mediaType
+			sr.mutex.Unlock()
+		}
+	}
+
 	if sr.debug {
 		log.Printf("[STREAM_READER] Response headers - Content-Type: %s, Accept-Ranges: %s",
 			resp.Header.Get("Content-Type"), resp.Header.Get("Accept-Ranges"))
@@ -177,6 +277,8 @@ func (sr *StreamReader) startDownload() {
 	buf := make([]byte, 64*1024)
 	lastLogTime := time.Now()
 	lastLoggedDownloaded := int64(0)
+	windowStart := time.Now()
+	windowBytes := int64(0)
 
 	for {
 		select {
@@ -193,6 +295,7 @@ func (sr *StreamReader) startDownload() {
 			sr.mutex.Lock()
 			sr.buffer = append(sr.buffer, buf[:n]...)
 			sr.downloaded += int64(n)
+			sr.lastDataTime = time.Now()
 
 			if !sr.bufferReady && sr.downloaded >= sr.minBufferSize {
 				sr.bufferReady = true
@@ -205,6 +308,18 @@ func (sr *StreamReader) startDownload() {
 			sr.cond.Broadcast()
 
 			now := time.Now()
+			windowBytes += int64(n)
+			if elapsed := now.Sub(windowStart); elapsed >= throughputWindow {
+				sr.meter.Record(windowBytes, elapsed)
+				windowStart = now
+				windowBytes = 0
+
+				sr.mutex.Lock()
+				if !sr.bufferReady {
+					sr.updateMinBufferSize()
+				}
+				sr.mutex.Unlock()
+			}
 			if sr.totalSize > 0 {
 				if now.Sub(lastLogTime) > 5*time.Second || sr.downloaded-lastLoggedDownloaded >= 1<<20 {
 					pct := float64(sr.downloaded) / float64(sr.totalSize) * 100
@@ -322,6 +437,51 @@ func (sr *StreamReader) IsBufferReady() bool {
 	return sr.bufferReady || sr.done
 }
 
+// bytesPerSecond estimates the stream bitrate from its size and the track
+// length, falling back to assumedBytesPerSecond. Callers hold sr.mutex.
+func (sr *StreamReader) bytesPerSecond() float64 {
+	if sr.totalSize > 0 && sr.expected > 0 {
+		return float64(sr.totalSize) / sr.expected.Seconds()
+	}
+	return assumedBytesPerSecond
+}
+
+// updateMinBufferSize converts the pre-buffer duration into bytes, buffering
+// more when recent throughput is below the stream bitrate. Callers hold
+// sr.mutex.
+func (sr *StreamReader) updateMinBufferSize() {
+	bitrate := sr.bytesPerSecond()
+	prebuffer := adaptivePrebuffer(sr.prebuffer, sr.expected, bitrate, sr.meter.Rate())
+	sr.minBufferSize = int64(prebuffer.Seconds() * bitrate)
+	if sr.totalSize > 0 && sr.minBufferSize > sr.totalSize {
+		sr.minBufferSize = sr.totalSize
+	}
+}
+
+// Stalled reports whether an unfinished download has received no data for
+// longer than timeout.
+func (sr *StreamReader) Stalled(timeout time.Duration) bool {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+
+	if sr.done {
+		return false
+	}
+	last := sr.lastDataTime
+	if last.IsZero() {
+		last = sr.lastReadTime
+	}
+	return time.Since(last) > timeout
+}
+
+// BufferedDuration estimates how much audio from the start of the track has
+// been downloaded.
+func (sr *StreamReader) BufferedDuration() time.Duration {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+	return time.Duration(float64(sr.downloaded) / sr.bytesPerSecond() * float64(time.Second))
+}
+
 func (sr *StreamReader) NewSegmentFrom(offset int64) io.ReadCloser {
 	if offset < 0 {
 		offset = 0
@@ -340,6 +500,14 @@ func (sr *StreamReader) TotalSize() int64 {
 	return sr.totalSize
 }
 
+// ContentType returns the media type from the response headers, or "" when
+// the server sent none or the response has not arrived yet.
+func (sr *StreamReader) ContentType() string {
+	sr.mutex.RLock()
+	defer sr.mutex.RUnlock()
+	return sr.contentType
+}
+
 // DownloadedSize returns the number of bytes downloaded so far.
 func (sr *StreamReader) DownloadedSize() int64 {
 	sr.mutex.RLock()
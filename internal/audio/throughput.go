@@ -0,0 +1,78 @@
+package audio
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// throughputWindow is the minimum span a single measurement covers, so
+	// bursts from the socket buffer do not look like a fast network.
+	throughputWindow = 500 * time.Millisecond
+	// throughputSmoothing weights new measurements in the moving average.
+	throughputSmoothing = 0.3
+	// throughputMaxAge discards measurements after the network was idle for long.
+	throughputMaxAge = 10 * time.Minute
+	// maxAdaptivePrebuffer caps how long a slow network may delay playback.
+	maxAdaptivePrebuffer = 60 * time.Second
+	// prebufferSafety adds headroom on top of the computed deficit.
+	prebufferSafety = 1.5
+)
+
+// throughputMeter keeps an exponential moving average of download speed
+// across all streams.
+type throughputMeter struct {
+	mu       sync.Mutex
+	rate     float64 // bytes per second
+	measured time.Time
+}
+
+func (m *throughputMeter) Record(bytes int64, elapsed time.Duration) {
+	if bytes <= 0 || elapsed < throughputWindow {
+		return
+	}
+
+	sample := float64(bytes) / elapsed.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.rate == 0 || time.Since(m.measured) > throughputMaxAge {
+		m.rate = sample
+	} else {
+		m.rate = throughputSmoothing*sample + (1-throughputSmoothing)*m.rate
+	}
+	m.measured = time.Now()
+}
+
+// Rate returns the recent throughput in bytes per second, or 0 if unknown.
+func (m *throughputMeter) Rate() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.measured.IsZero() || time.Since(m.measured) > throughputMaxAge {
+		return 0
+	}
+	return m.rate
+}
+
+// adaptivePrebuffer returns how much audio to buffer before starting so that
+// a download running at rate keeps ahead of playback for the whole track.
+// The configured prebuffer is used as a floor.
+func adaptivePrebuffer(configured, length time.Duration, bitrate, rate float64) time.Duration {
+	if rate <= 0 || bitrate <= 0 || rate >= bitrate {
+		return configured
+	}
+	if length <= 0 {
+		length = 4 * time.Minute
+	}
+
+	deficit := time.Duration(float64(length) * (bitrate - rate) / bitrate * prebufferSafety)
+	if deficit > maxAdaptivePrebuffer {
+		deficit = maxAdaptivePrebuffer
+	}
+	if deficit < configured {
+		return configured
+	}
+	return deficit
+}
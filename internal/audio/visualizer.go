@@ -0,0 +1,176 @@
+package audio
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep"
+)
+
+const (
+	// scopeSize is how many of the latest samples the tap keeps, a power of
+	// two for the FFT: about 46 ms at 44.1 kHz.
+	scopeSize = 2048
+	// scopeStale is how long after the last samples the visualizer goes
+	// quiet, as when playback paused.
+	scopeStale = 150 * time.Millisecond
+
+	// The spectrum spans spectrumMinFreq to spectrumMaxFreq on a log scale
+	// and spectrumRange decibels below full scale.
+	spectrumMinFreq = 40.0
+	spectrumMaxFreq = 16000.0
+	spectrumRange   = 60.0
+)
+
+// scope keeps the latest samples played, mixed down to mono, for the
+// visualizer. The tap writes it from the speaker's goroutine; the UI reads
+// it from its own.
+type scope struct {
+	mu      sync.Mutex
+	enabled bool
+	ring    [scopeSize]float64
+	pos     int
+	updated time.Time
+}
+
+func (s *scope) setEnabled(enabled bool) {
+	s.mu.Lock()
+	s.enabled = enabled
+	s.updated = time.Time{}
+	s.mu.Unlock()
+}
+
+func (s *scope) write(samples [][2]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.enabled || len(samples) == 0 {
+		return
+	}
+	for _, sample := range samples {
+		s.ring[s.pos] = (sample[0] + sample[1]) / 2
+		s.pos = (s.pos + 1) % scopeSize
+	}
+	s.updated = time.Now()
+}
+
+// latest returns the samples kept, oldest first, or nil when nothing played
+// lately.
+func (s *scope) latest() []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updated.IsZero() || time.Since(s.updated) > scopeStale {
+		return nil
+	}
+	out := make([]float64, scopeSize)
+	n := copy(out, s.ring[s.pos:])
+	copy(out[n:], s.ring[:s.pos])
+	return out
+}
+
+// tap passes the samples of a track through unchanged, copying them to the
+// scope on the way.
+type tap struct {
+	Streamer beep.Streamer
+	scope    *scope
+}
+
+func (t *tap) Stream(samples [][2]float64) (int, bool) {
+	n, ok := t.Streamer.Stream(samples)
+	t.scope.write(samples[:n])
+	return n, ok
+}
+
+func (t *tap) Err() error {
+	return t.Streamer.Err()
+}
+
+// SetVisualizer starts or stops keeping the samples played for Spectrum and
+// Oscilloscope, which is only worth it while a visualizer shows them.
+func (p *Player) SetVisualizer(enabled bool) {
+	p.scope.setEnabled(enabled)
+}
+
+// Oscilloscope returns the latest samples played, scaled down to points
+// values from -1 to 1. It returns nil while nothing plays.
+func (p *Player) Oscilloscope(points int) []float64 {
+	samples := p.scope.latest()
+	if samples == nil || points <= 0 {
+		return nil
+	}
+
+	out := make([]float64, points)
+	step := float64(len(samples)) / float64(points)
+	for i := range out {
+		out[i] = math.Max(-1, math.Min(1, samples[int(float64(i)*step)]))
+	}
+	return out
+}
+
+// Spectrum returns the level of the latest samples played in bands bands
+// spread evenly over the octaves, each from 0 for silence to 1 for full
+// scale. It returns nil while nothing plays.
+func (p *Player) Spectrum(bands int) []float64 {
+	samples := p.scope.latest()
+	if samples == nil || bands <= 0 {
+		return nil
+	}
+
+	// A Hann window keeps the edges of the slice from smearing the peaks.
+	buf := make([]complex128, scopeSize)
+	for i, sample := range samples {
+		window := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(scopeSize-1))
+		buf[i] = complex(sample*window, 0)
+	}
+	fft(buf)
+
+	binWidth := float64(p.sampleRate) / scopeSize
+	maxFreq := math.Min(spectrumMaxFreq, float64(p.sampleRate)/2)
+	ratio := maxFreq / spectrumMinFreq
+	// A full scale sine peaks at a quarter of the size with the window.
+	fullScale := float64(scopeSize) / 4
+
+	levels := make([]float64, bands)
+	for b := range levels {
+		lo := int(spectrumMinFreq * math.Pow(ratio, float64(b)/float64(bands)) / binWidth)
+		hi := int(spectrumMinFreq * math.Pow(ratio, float64(b+1)/float64(bands)) / binWidth)
+		hi = max(hi, lo+1)
+
+		peak := 0.0
+		for bin := lo; bin < hi && bin < scopeSize/2; bin++ {
+			peak = math.Max(peak, cmplx.Abs(buf[bin]))
+		}
+		if peak <= 0 {
+			continue
+		}
+		db := 20 * math.Log10(peak/fullScale)
+		levels[b] = math.Max(0, math.Min(1, (db+spectrumRange)/spectrumRange))
+	}
+	return levels
+}
+
+// fft transforms buf in place; its length must be a power of two.
+func fft(buf []complex128) {
+	n := len(buf)
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range buf {
+		if j := int(bits.Reverse64(uint64(i)) >> shift); j > i {
+			buf[i], buf[j] = buf[j], buf[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even, odd := buf[start+k], w*buf[start+k+size/2]
+				buf[start+k] = even + odd
+				buf[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package audio
+
+import (
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio/speaker"
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+const (
+	VolumeRestore = "restore"
+	VolumeFixed   = "fixed"
+	VolumeCapped  = "capped"
+
+	defaultDeviceKey = "default"
+	// volumeSaveDelay batches slider drags into a single config write.
+	volumeSaveDelay = time.Second
+)
+
+// configKey turns a device or genre name into a stable config key. Viper
+// lowercases keys and treats dots as nesting, so only lowercase letters,
+// digits and underscores are kept.
+func configKey(name string) string {
+	key := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		default:
+			return '_'
+		}
+	}, strings.TrimSpace(name))
+	if key == "" {
+		return defaultDeviceKey
+	}
+	return key
+}
+
+// StartupVolume resolves the volume to start with on the given device
+// according to the configured volume policy.
+func StartupVolume(cfg *config.Config, device string) float64 {
+	remembered, ok := cfg.Audio.DeviceVolumes[configKey(device)]
+	if !ok {
+		remembered = cfg.Audio.DefaultVolume
+	}
+
+	level := remembered
+	switch cfg.Audio.VolumePolicy {
+	case VolumeFixed:
+		level = cfg.Audio.DefaultVolume
+	case VolumeCapped:
+		if level > cfg.Audio.MaxStartupVolume {
+			level = cfg.Audio.MaxStartupVolume
+		}
+	}
+	return clampVolume(level)
+}
+
+func clampVolume(level float64) float64 {
+	if level < 0 {
+		return 0
+	}
+	if level > 1 {
+		return 1
+	}
+	return level
+}
+
+// Volume returns the current volume level between 0 and 1.
+func (p *Player) Volume() float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.level
+}
+
+// OutputDevice returns the name of the device audio is being played on, or
+// an empty string when it cannot be determined.
+func (p *Player) OutputDevice() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.device
+}
+
+// OnVolumeChanged is called when the player changes the volume on its own,
+// for example after switching to a device with a remembered volume. It is
+// called from the player's goroutine.
+func (p *Player) OnVolumeChanged(callback func(level float64)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.volumeCallback = callback
+}
+
+// applyVolume updates the live pipeline. Callers must hold p.mu.
+func (p *Player) applyVolume() {
+	if p.volume == nil {
+		return
+	}
+
+	speaker.Lock()
+	if p.level == 0 {
+		p.volume.Silent = true
+	} else {
+		p.volume.Silent = false
+		p.volume.Volume = (p.level - 1) * 5
+	}
+	speaker.Unlock()
+}
+
+// rememberVolume stores the level for the current device and schedules a
+// config write. Callers must hold p.mu.
+func (p *Player) rememberVolume() {
+	if p.cfg.Audio.DeviceVolumes == nil {
+		p.cfg.Audio.DeviceVolumes = make(map[string]float64)
+	}
+	p.cfg.Audio.DeviceVolumes[configKey(p.device)] = p.level
+
+	if p.volumeSaveTimer != nil {
+		p.volumeSaveTimer.Stop()
+	}
+	p.volumeSaveTimer = time.AfterFunc(volumeSaveDelay, func() {
+		p.mu.RLock()
+		defer p.mu.RUnlock()
+		if err := p.cfg.Save(); err != nil {
+			log.Printf("[AUDIO] Failed to save device volume: %v", err)
+		}
+	})
+}
+
+// flushVolume writes a pending volume change right away.
+func (p *Player) flushVolume() {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.volumeSaveTimer == nil || !p.volumeSaveTimer.Stop() {
+		return
+	}
+	if err := p.cfg.Save(); err != nil {
+		log.Printf("[AUDIO] Failed to save device volume: %v", err)
+	}
+}
+
+// switchDevice restores the remembered volume and equalizer preset when
+// output moves to another device.
+func (p *Player) switchDevice(device string) {
+	p.mu.Lock()
+	if device == p.device {
+		p.mu.Unlock()
+		return
+	}
+	p.device = device
+	p.eqPreset = p.eqPresetFor(p.currentSong)
+	p.applyEqualizer()
+
+	level, ok := p.cfg.Audio.DeviceVolumes[configKey(device)]
+	if !ok || level == p.level {
+		p.mu.Unlock()
+		return
+	}
+	p.level = clampVolume(level)
+	p.applyVolume()
+	cb := p.volumeCallback
+	p.mu.Unlock()
+
+	if p.debug {
+		log.Printf("[AUDIO] Output moved to %q, restoring volume %.2f", device, level)
+	}
+	if cb != nil {
+		cb(level)
+	}
+}
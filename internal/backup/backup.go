@@ -0,0 +1,243 @@
+// Package backup snapshots the database and config into a folder on a
+// schedule, keeps a number of them, and restores one.
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+)
+
+const (
+	filePrefix = "amp-backup-"
+	fileExt    = ".zip"
+	timeLayout = "20060102-150405"
+
+	databaseEntry = "music.db"
+	configEntry   = "config.yaml"
+)
+
+// ErrNoFolder is returned when no backup folder is configured.
+var ErrNoFolder = errors.New("no backup folder configured")
+
+// Snapshot is one backup file.
+type Snapshot struct {
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// Name returns how the snapshot is shown to the user.
+func (s *Snapshot) Name() string {
+	return fmt.Sprintf("%s (%.1f MB)", s.CreatedAt.Format("2006-01-02 15:04"), float64(s.Size)/(1<<20))
+}
+
+// Create writes a snapshot of the database and config file into folder.
+func Create(ctx context.Context, db *storage.Database, folder string) (*Snapshot, error) {
+	if folder == "" {
+		return nil, ErrNoFolder
+	}
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return nil, fmt.Errorf("create backup folder: %w", err)
+	}
+
+	now := time.Now()
+	path := filepath.Join(folder, filePrefix+now.Format(timeLayout)+fileExt)
+	dbCopy := path + ".db.tmp"
+	if err := os.Remove(dbCopy); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := db.Snapshot(ctx, dbCopy); err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(dbCopy) }()
+
+	tmp := path + ".tmp"
+	if err := writeArchive(tmp, dbCopy); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("save backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Path: path, CreatedAt: now, Size: info.Size()}, nil
+}
+
+func writeArchive(path, dbCopy string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+	zw := zip.NewWriter(f)
+
+	err = addFile(zw, databaseEntry, dbCopy)
+	if err == nil {
+		if configPath, pathErr := config.Path(); pathErr == nil {
+			if _, statErr := os.Stat(configPath); statErr == nil {
+				err = addFile(zw, configEntry, configPath)
+			}
+		}
+	}
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	return nil
+}
+
+func addFile(zw *zip.Writer, name, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// List returns the snapshots in folder, newest first.
+func List(folder string) ([]*Snapshot, error) {
+	if folder == "" {
+		return nil, ErrNoFolder
+	}
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read backup folder: %w", err)
+	}
+
+	var snapshots []*Snapshot
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		created, err := time.ParseInLocation(timeLayout, strings.TrimSuffix(strings.TrimPrefix(name, filePrefix), fileExt), time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, &Snapshot{Path: filepath.Join(folder, name), CreatedAt: created, Size: info.Size()})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+	return snapshots, nil
+}
+
+// Prune deletes all but the keep newest snapshots in folder and returns
+// how many it deleted.
+func Prune(folder string, keep int) (int, error) {
+	snapshots, err := List(folder)
+	if err != nil || keep < 1 || len(snapshots) <= keep {
+		return 0, err
+	}
+
+	removed := 0
+	for _, snapshot := range snapshots[keep:] {
+		if err := os.Remove(snapshot.Path); err != nil {
+			log.Printf("[BACKUP] Failed to delete %s: %v", snapshot.Path, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Restore stages the database of snapshot and the config file saved in it,
+// if any, to replace the live ones at the next start. The application must
+// be restarted to use them.
+func Restore(snapshot *Snapshot, cfg *config.Config) error {
+	zr, err := zip.OpenReader(snapshot.Path)
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+
+	var dbFile, configFile *zip.File
+	for _, f := range zr.File {
+		switch f.Name {
+		case databaseEntry:
+			dbFile = f
+		case configEntry:
+			configFile = f
+		}
+	}
+	if dbFile == nil {
+		return fmt.Errorf("backup has no database")
+	}
+
+	if err := extract(dbFile, cfg.Storage.DatabasePath+storage.RestoreSuffix); err != nil {
+		return fmt.Errorf("restore database: %w", err)
+	}
+	if configFile != nil {
+		configPath, err := config.Path()
+		if err != nil {
+			return err
+		}
+		if err := extract(configFile, configPath+config.RestoreSuffix); err != nil {
+			return fmt.Errorf("restore config: %w", err)
+		}
+	}
+	return nil
+}
+
+// extract writes f to path through a temporary file, so a failed restore
+// leaves nothing half written.
+func extract(f *zip.File, path string) error {
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	tmp := path + ".tmp"
+	dst, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+	}
+	return err
+}
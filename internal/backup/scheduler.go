@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+)
+
+// checkInterval is how often the scheduler looks whether a backup is due.
+// Backups are due by the age of the newest snapshot, so time spent asleep or
+// closed counts too.
+const checkInterval = 15 * time.Minute
+
+// Scheduler backs up on the interval set in the config.
+type Scheduler struct {
+	db  *storage.Database
+	cfg *config.Config
+
+	onBackup func(*Snapshot, error)
+}
+
+func NewScheduler(db *storage.Database, cfg *config.Config) *Scheduler {
+	return &Scheduler{db: db, cfg: cfg}
+}
+
+// OnBackup sets a callback for every scheduled backup, made or failed. It
+// is called from the scheduler's goroutine.
+func (s *Scheduler) OnBackup(callback func(*Snapshot, error)) {
+	s.onBackup = callback
+}
+
+// Run backs up whenever one is due, until ctx is done. Set OnBackup before.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.due() {
+			snapshot, err := s.BackupNow(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("[BACKUP] Scheduled backup failed: %v", err)
+			}
+			if s.onBackup != nil && ctx.Err() == nil {
+				s.onBackup(snapshot, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) due() bool {
+	folder := s.cfg.Backup.Folder
+	if !s.cfg.Backup.Enabled || folder == "" {
+		return false
+	}
+	snapshots, err := List(folder)
+	if err != nil {
+		log.Printf("[BACKUP] Cannot list backups: %v", err)
+		return false
+	}
+	if len(snapshots) == 0 {
+		return true
+	}
+	interval := time.Duration(s.cfg.Backup.IntervalHours) * time.Hour
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return time.Since(snapshots[0].CreatedAt) >= interval
+}
+
+// BackupNow makes a snapshot and prunes old ones.
+func (s *Scheduler) BackupNow(ctx context.Context) (*Snapshot, error) {
+	snapshot, err := Create(ctx, s.db, s.cfg.Backup.Folder)
+	if err != nil {
+		return nil, err
+	}
+	removed, err := Prune(s.cfg.Backup.Folder, s.cfg.Backup.Keep)
+	if err != nil {
+		log.Printf("[BACKUP] Failed to prune backups: %v", err)
+	}
+	log.Printf("[BACKUP] Saved %s, deleted %d old backups", snapshot.Path, removed)
+	return snapshot, nil
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 
 	"github.com/spf13/viper"
@@ -10,10 +13,30 @@ import (
 	"github.com/Alexander-D-Karpov/amp/internal/platform"
 )
 
+// RestoreSuffix marks a config file waiting next to the live one to replace
+// it the next time the config is loaded. Restoring a backup stages the file
+// this way so that saves made before the restart do not overwrite it.
+const RestoreSuffix = ".restore"
+
 type Config struct {
 	Debug bool `mapstructure:"debug"`
 
+	// Demo is set for sessions on the bundled sample library. It is never
+	// read from or written to the config file.
+	Demo bool `mapstructure:"-"`
+
+	// Kiosk starts the window in kiosk mode, set by the -kiosk flag for
+	// machines that run AMP as a shared jukebox. Like Demo it is not saved.
+	Kiosk bool `mapstructure:"-"`
+
+	// Embedded is set for configs built by pkg/ampclient for other
+	// programs, which must not overwrite AMP's own config file. Like Demo
+	// it is not saved.
+	Embedded bool `mapstructure:"-"`
+
 	API struct {
+		// Backend selects the server protocol: "amp" or "subsonic".
+		Backend   string `mapstructure:"backend"`
 		BaseURL   string `mapstructure:"base_url"`
 		Token     string `mapstructure:"token"`
 		RateLimit struct {
@@ -23,8 +46,31 @@ type Config struct {
 		Timeout   int    `mapstructure:"timeout"`
 		Retries   int    `mapstructure:"retries"`
 		UserAgent string `mapstructure:"user_agent"`
+		// Offline keeps AMP away from the server: only downloaded and
+		// local songs are shown, and changes are sent once back online.
+		Offline  bool `mapstructure:"offline"`
+		Subsonic struct {
+			Username string `mapstructure:"username"`
+			Password string `mapstructure:"password"`
+		} `mapstructure:"subsonic"`
 	} `mapstructure:"api"`
 
+	// Network settings apply to every connection AMP makes: the API,
+	// streaming, images and downloads.
+	Network struct {
+		Proxy struct {
+			// Mode is "system" to follow the environment, "none" or "manual".
+			Mode string `mapstructure:"mode"`
+			// URL of the manual proxy: http://, https:// or socks5://.
+			URL      string `mapstructure:"url"`
+			Username string `mapstructure:"username"`
+			Password string `mapstructure:"password"`
+		} `mapstructure:"proxy"`
+		// CAFile is a PEM bundle trusted in addition to the system roots.
+		CAFile             string `mapstructure:"ca_file"`
+		InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+	} `mapstructure:"network"`
+
 	Storage struct {
 		DatabasePath string `mapstructure:"database_path"`
 		CacheDir     string `mapstructure:"cache_dir"`
@@ -34,15 +80,62 @@ type Config struct {
 		MaxSyncPages int    `mapstructure:"max_sync_pages"`
 	} `mapstructure:"storage"`
 
+	// Library folders are scanned for audio files, which are added as
+	// local-only songs. With Watch they are rescanned whenever they change.
+	Library struct {
+		Folders []string `mapstructure:"folders"`
+		Watch   bool     `mapstructure:"watch"`
+	} `mapstructure:"library"`
+
+	// Memory limits in megabytes; 0 disables a limit. When one is exceeded
+	// the caches behind it are trimmed.
+	Memory struct {
+		HeapLimitMB    int `mapstructure:"heap_limit_mb"`
+		ImageCacheMB   int `mapstructure:"image_cache_mb"`
+		StreamBufferMB int `mapstructure:"stream_buffer_mb"`
+		CheckInterval  int `mapstructure:"check_interval"`
+	} `mapstructure:"memory"`
+
 	Audio struct {
-		SampleRate      int     `mapstructure:"sample_rate"`
-		BufferSize      int     `mapstructure:"buffer_size"`
-		DefaultVolume   float64 `mapstructure:"default_volume"`
-		Crossfade       bool    `mapstructure:"crossfade"`
-		LowLatencyMode  bool    `mapstructure:"low_latency_mode"`
-		PlatformOptimal bool    `mapstructure:"platform_optimal"`
-		MaxChannels     int     `mapstructure:"max_channels"`
-		BitDepth        int     `mapstructure:"bit_depth"`
+		SampleRate       int     `mapstructure:"sample_rate"`
+		BufferSize       int     `mapstructure:"buffer_size"`
+		DefaultVolume    float64 `mapstructure:"default_volume"`
+		Crossfade        bool    `mapstructure:"crossfade"`
+		CrossfadeSeconds int     `mapstructure:"crossfade_seconds"`
+		LowLatencyMode   bool    `mapstructure:"low_latency_mode"`
+		PlatformOptimal  bool    `mapstructure:"platform_optimal"`
+		MaxChannels      int     `mapstructure:"max_channels"`
+		BitDepth         int     `mapstructure:"bit_depth"`
+		Karaoke          bool    `mapstructure:"karaoke"`
+		Crossfeed        bool    `mapstructure:"crossfeed"`
+		CrossfeedLevel   float64 `mapstructure:"crossfeed_level"`
+		PrebufferSeconds int     `mapstructure:"prebuffer_seconds"`
+		// Radio keeps playing songs by the same artists or from the same
+		// album once the queue runs out, when shuffle and repeat are off.
+		Radio bool `mapstructure:"radio"`
+		// OutputDevice is the name of the device to play on, empty for the
+		// system default. The default is used while it is unplugged.
+		OutputDevice string `mapstructure:"output_device"`
+		// CueDevice is the name of a second device, such as headphones,
+		// that songs are pre-listened and previewed on while the main mix
+		// goes on. Empty turns pre-listening off.
+		CueDevice string `mapstructure:"cue_device"`
+		// VolumePolicy decides the volume at startup: "restore" the last
+		// volume of the output device, "fixed" at DefaultVolume, or "capped"
+		// to restore but never above MaxStartupVolume.
+		VolumePolicy     string             `mapstructure:"volume_policy"`
+		MaxStartupVolume float64            `mapstructure:"max_startup_volume"`
+		DeviceVolumes    map[string]float64 `mapstructure:"device_volumes"`
+		// EQ applies the EQPreset equalizer preset: "flat", "rock", "jazz",
+		// "vocal", "bass_boost" or "custom", which uses the ten EQCustom
+		// gains in dB.
+		EQ       bool      `mapstructure:"eq"`
+		EQPreset string    `mapstructure:"eq_preset"`
+		EQCustom []float64 `mapstructure:"eq_custom"`
+		// EQDevicePresets and EQGenrePresets pick a preset automatically by
+		// output device and by genre tag; a genre preset wins.
+		EQDevicePresets map[string]string `mapstructure:"eq_device_presets"`
+		EQGenrePresets  map[string]string `mapstructure:"eq_genre_presets"`
 	} `mapstructure:"audio"`
 
 	UI struct {
@@ -54,6 +147,36 @@ type Config struct {
 		WindowHeight int    `mapstructure:"window_height"`
 		VirtualGrid  bool   `mapstructure:"virtual_grid"`
 		ImageQuality string `mapstructure:"image_quality"`
+		// DynamicColors tints the player bar with the current cover's colors.
+		DynamicColors bool `mapstructure:"dynamic_colors"`
+		// BrowseLayouts remembers, per view, whether it shows a grid or
+		// side-by-side columns.
+		BrowseLayouts map[string]string `mapstructure:"browse_layouts"`
+		// TextScale multiplies text sizes, from 0.9 to 1.5. Font and
+		// TimeFont pick "default" or "monospace" for regular text and for
+		// time displays.
+		TextScale float64 `mapstructure:"text_scale"`
+		Font      string  `mapstructure:"font"`
+		TimeFont  string  `mapstructure:"time_font"`
+		// ReduceMotion switches views at once instead of sliding or
+		// fading between them.
+		ReduceMotion bool `mapstructure:"reduce_motion"`
+		// WaveformSeek makes the waveform the seek bar, with the played part
+		// tinted, for songs that have volume data.
+		WaveformSeek bool `mapstructure:"waveform_seek"`
+		// Visualizer shows a panel under the player bar drawing what plays,
+		// as a spectrum or, with VisualizerMode "oscilloscope", a wave.
+		Visualizer     bool   `mapstructure:"visualizer"`
+		VisualizerMode string `mapstructure:"visualizer_mode"`
+		// HighContrast draws the interface at full contrast, with status
+		// colors that can be told apart without color perception.
+		HighContrast bool `mapstructure:"high_contrast"`
+		// MinimizeToTray hides the window in the system tray when it is
+		// closed, so playback goes on.
+		MinimizeToTray bool `mapstructure:"minimize_to_tray"`
+		// HoverPreview plays a quiet snippet of a song whose card the
+		// pointer rests on for a second, while nothing else plays.
+		HoverPreview bool `mapstructure:"hover_preview"`
 	} `mapstructure:"ui"`
 
 	Search struct {
@@ -70,6 +193,96 @@ type Config struct {
 		AutoDownload  bool   `mapstructure:"auto_download"`
 	} `mapstructure:"download"`
 
+	Update struct {
+		Channel   string `mapstructure:"channel"`
+		FeedURL   string `mapstructure:"feed_url"`
+		AutoCheck bool   `mapstructure:"auto_check"`
+		PublicKey string `mapstructure:"public_key"`
+	} `mapstructure:"update"`
+
+	Feedback struct {
+		Endpoint string `mapstructure:"endpoint"`
+		IssueURL string `mapstructure:"issue_url"`
+	} `mapstructure:"feedback"`
+
+	// Backup snapshots the database and config into Folder every
+	// IntervalHours, keeping the Keep newest snapshots.
+	Backup struct {
+		Enabled       bool   `mapstructure:"enabled"`
+		Folder        string `mapstructure:"folder"`
+		IntervalHours int    `mapstructure:"interval_hours"`
+		Keep          int    `mapstructure:"keep"`
+	} `mapstructure:"backup"`
+
+	// SmartCache keeps the TopSongs most played songs and those liked in the
+	// last LikedDays downloaded, and removes downloads not played in
+	// EvictDays. It runs once a night between StartHour and EndHour, while
+	// on mains power and an unmetered network.
+	SmartCache struct {
+		Enabled   bool `mapstructure:"enabled"`
+		TopSongs  int  `mapstructure:"top_songs"`
+		LikedDays int  `mapstructure:"liked_days"`
+		EvictDays int  `mapstructure:"evict_days"`
+		StartHour int  `mapstructure:"start_hour"`
+		EndHour   int  `mapstructure:"end_hour"`
+		// RunWhenUnknown lets it run where the power supply or network
+		// cannot be read, as on Windows and macOS, instead of waiting.
+		RunWhenUnknown bool `mapstructure:"run_when_unknown"`
+	} `mapstructure:"smart_cache"`
+
+	// Cast lends local files to Chromecast and DLNA renderers from a small
+	// HTTP server on ServerPort, or on any free port when it is 0.
+	Cast struct {
+		ServerPort int `mapstructure:"server_port"`
+	} `mapstructure:"cast"`
+
+	Party struct {
+		Enabled         bool `mapstructure:"enabled"`
+		Port            int  `mapstructure:"port"`
+		RequireApproval bool `mapstructure:"require_approval"`
+		// RequestsPerHour caps how many songs each guest gets queued in
+		// an hour, 0 for no cap.
+		RequestsPerHour int `mapstructure:"requests_per_hour"`
+		// JoinCode, when set, must be entered on the party page before a
+		// guest can search or request.
+		JoinCode string `mapstructure:"join_code"`
+	} `mapstructure:"party"`
+
+	// Wellbeing suggests a break once playback ran for BreakMinutes without
+	// stopping for five minutes. BreakAction is "remind" to only say so or
+	// "pause" to pause as well.
+	Wellbeing struct {
+		BreakReminder bool   `mapstructure:"break_reminder"`
+		BreakMinutes  int    `mapstructure:"break_minutes"`
+		BreakAction   string `mapstructure:"break_action"`
+	} `mapstructure:"wellbeing"`
+
+	Integrations struct {
+		MPRIS bool `mapstructure:"mpris"`
+		// LRCLIB looks lyrics up on lrclib.net for songs the server has
+		// none for.
+		LRCLIB bool `mapstructure:"lrclib"`
+		// MediaButton picks what one, two and three quick presses of a
+		// headset button or the play/pause media key do: "play_pause",
+		// "next", "previous" or "none".
+		MediaButton struct {
+			SinglePress string `mapstructure:"single_press"`
+			DoublePress string `mapstructure:"double_press"`
+			TriplePress string `mapstructure:"triple_press"`
+		} `mapstructure:"media_button"`
+		// GlobalHotkeys are system-wide shortcuts that work while another
+		// window has focus. Keys are written as in the XDG shortcuts
+		// specification, like "CTRL+ALT+space".
+		GlobalHotkeys struct {
+			Enabled    bool   `mapstructure:"enabled"`
+			PlayPause  string `mapstructure:"play_pause"`
+			Next       string `mapstructure:"next"`
+			Previous   string `mapstructure:"previous"`
+			VolumeUp   string `mapstructure:"volume_up"`
+			VolumeDown string `mapstructure:"volume_down"`
+		} `mapstructure:"global_hotkeys"`
+	} `mapstructure:"integrations"`
+
 	User struct {
 		ID          int    `mapstructure:"id"`
 		Username    string `mapstructure:"username"`
@@ -101,6 +314,10 @@ func Load(configPath string) (*Config, error) {
 
 	setDefaults()
 
+	if err := applyPendingRestore(); err != nil {
+		return nil, fmt.Errorf("restore config: %w", err)
+	}
+
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, err
@@ -138,6 +355,10 @@ func DefaultMobileConfig() *Config {
 	cfg.Audio.LowLatencyMode = false
 	cfg.UI.VirtualGrid = true
 	cfg.UI.ImageQuality = "medium"
+	cfg.Memory.HeapLimitMB = 384
+	cfg.Memory.ImageCacheMB = 64
+	cfg.Memory.StreamBufferMB = 96
+	cfg.Memory.CheckInterval = 30
 
 	return cfg
 }
@@ -145,12 +366,23 @@ func DefaultMobileConfig() *Config {
 func setDefaults() {
 	viper.SetDefault("debug", false)
 
+	viper.SetDefault("api.backend", "amp")
 	viper.SetDefault("api.base_url", "https://new.akarpov.ru/api/v1")
 	viper.SetDefault("api.rate_limit.requests_per_second", 100)
 	viper.SetDefault("api.rate_limit.burst_size", 10)
 	viper.SetDefault("api.timeout", 30)
 	viper.SetDefault("api.retries", 3)
 	viper.SetDefault("api.user_agent", "AMP/1.0.0")
+	viper.SetDefault("api.offline", false)
+	viper.SetDefault("api.subsonic.username", "")
+	viper.SetDefault("api.subsonic.password", "")
+
+	viper.SetDefault("network.proxy.mode", "system")
+	viper.SetDefault("network.proxy.url", "")
+	viper.SetDefault("network.proxy.username", "")
+	viper.SetDefault("network.proxy.password", "")
+	viper.SetDefault("network.ca_file", "")
+	viper.SetDefault("network.insecure_skip_verify", false)
 
 	dataDir, _ := platform.GetDataDir()
 	cacheDir, _ := platform.GetCacheDir()
@@ -162,14 +394,43 @@ func setDefaults() {
 	viper.SetDefault("storage.enable_wal", true)
 	viper.SetDefault("storage.max_sync_pages", 10)
 
+	viper.SetDefault("library.folders", []string{})
+	viper.SetDefault("library.watch", true)
+
+	viper.SetDefault("backup.enabled", false)
+	viper.SetDefault("backup.folder", "")
+	viper.SetDefault("backup.interval_hours", 24)
+	viper.SetDefault("backup.keep", 7)
+
+	viper.SetDefault("memory.heap_limit_mb", 1024)
+	viper.SetDefault("memory.image_cache_mb", 256)
+	viper.SetDefault("memory.stream_buffer_mb", 256)
+	viper.SetDefault("memory.check_interval", 30)
+
 	viper.SetDefault("audio.sample_rate", 44100)
 	viper.SetDefault("audio.buffer_size", getDefaultBufferSize())
 	viper.SetDefault("audio.default_volume", 0.7)
 	viper.SetDefault("audio.crossfade", false)
+	viper.SetDefault("audio.crossfade_seconds", 5)
 	viper.SetDefault("audio.low_latency_mode", false)
 	viper.SetDefault("audio.platform_optimal", true)
 	viper.SetDefault("audio.max_channels", 2)
 	viper.SetDefault("audio.bit_depth", 16)
+	viper.SetDefault("audio.karaoke", false)
+	viper.SetDefault("audio.crossfeed", false)
+	viper.SetDefault("audio.crossfeed_level", 0.5)
+	viper.SetDefault("audio.prebuffer_seconds", 6)
+	viper.SetDefault("audio.radio", false)
+	viper.SetDefault("audio.output_device", "")
+	viper.SetDefault("audio.cue_device", "")
+	viper.SetDefault("audio.volume_policy", "restore")
+	viper.SetDefault("audio.max_startup_volume", 0.8)
+	viper.SetDefault("audio.device_volumes", map[string]float64{})
+	viper.SetDefault("audio.eq", false)
+	viper.SetDefault("audio.eq_preset", "flat")
+	viper.SetDefault("audio.eq_custom", []float64{0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+	viper.SetDefault("audio.eq_device_presets", map[string]string{})
+	viper.SetDefault("audio.eq_genre_presets", map[string]string{})
 
 	viper.SetDefault("ui.theme", "dark")
 	viper.SetDefault("ui.language", "en")
@@ -179,6 +440,18 @@ func setDefaults() {
 	viper.SetDefault("ui.window_height", 800)
 	viper.SetDefault("ui.virtual_grid", false)
 	viper.SetDefault("ui.image_quality", "high")
+	viper.SetDefault("ui.dynamic_colors", false)
+	viper.SetDefault("ui.browse_layouts", map[string]string{})
+	viper.SetDefault("ui.text_scale", 1.0)
+	viper.SetDefault("ui.font", "default")
+	viper.SetDefault("ui.time_font", "monospace")
+	viper.SetDefault("ui.reduce_motion", false)
+	viper.SetDefault("ui.waveform_seek", false)
+	viper.SetDefault("ui.visualizer", false)
+	viper.SetDefault("ui.visualizer_mode", "spectrum")
+	viper.SetDefault("ui.high_contrast", false)
+	viper.SetDefault("ui.minimize_to_tray", false)
+	viper.SetDefault("ui.hover_preview", false)
 
 	viper.SetDefault("search.max_results", 100)
 	viper.SetDefault("search.fuzzy_threshold", 0.6)
@@ -190,6 +463,45 @@ func setDefaults() {
 	viper.SetDefault("download.temp_dir", filepath.Join(cacheDir, "temp"))
 	viper.SetDefault("download.auto_download", false)
 
+	viper.SetDefault("update.channel", "stable")
+	viper.SetDefault("update.feed_url", "https://new.akarpov.ru/amp/releases.json")
+	viper.SetDefault("update.auto_check", true)
+	viper.SetDefault("update.public_key", "")
+
+	viper.SetDefault("feedback.endpoint", "")
+	viper.SetDefault("feedback.issue_url", "https://github.com/Alexander-D-Karpov/amp/issues/new")
+
+	viper.SetDefault("smart_cache.enabled", false)
+	viper.SetDefault("smart_cache.top_songs", 100)
+	viper.SetDefault("smart_cache.liked_days", 30)
+	viper.SetDefault("smart_cache.evict_days", 90)
+	viper.SetDefault("smart_cache.start_hour", 1)
+	viper.SetDefault("smart_cache.end_hour", 6)
+	viper.SetDefault("smart_cache.run_when_unknown", false)
+
+	viper.SetDefault("cast.server_port", 0)
+
+	viper.SetDefault("party.enabled", false)
+	viper.SetDefault("party.port", 8765)
+	viper.SetDefault("party.require_approval", true)
+	viper.SetDefault("party.requests_per_hour", 5)
+	viper.SetDefault("party.join_code", "")
+	viper.SetDefault("wellbeing.break_reminder", false)
+	viper.SetDefault("wellbeing.break_minutes", 60)
+	viper.SetDefault("wellbeing.break_action", "remind")
+
+	viper.SetDefault("integrations.mpris", true)
+	viper.SetDefault("integrations.lrclib", true)
+	viper.SetDefault("integrations.media_button.single_press", "play_pause")
+	viper.SetDefault("integrations.media_button.double_press", "next")
+	viper.SetDefault("integrations.media_button.triple_press", "previous")
+	viper.SetDefault("integrations.global_hotkeys.enabled", false)
+	viper.SetDefault("integrations.global_hotkeys.play_pause", "CTRL+ALT+space")
+	viper.SetDefault("integrations.global_hotkeys.next", "CTRL+ALT+Right")
+	viper.SetDefault("integrations.global_hotkeys.previous", "CTRL+ALT+Left")
+	viper.SetDefault("integrations.global_hotkeys.volume_up", "CTRL+ALT+Up")
+	viper.SetDefault("integrations.global_hotkeys.volume_down", "CTRL+ALT+Down")
+
 	viper.SetDefault("user.is_anonymous", true)
 }
 
@@ -260,11 +572,76 @@ func ensureDirectories(cfg *Config) error {
 }
 
 func (c *Config) Save() error {
-	configDir, err := platform.GetConfigDir()
+	if c.Demo || c.Embedded {
+		return nil
+	}
+
+	configFile, err := Path()
 	if err != nil {
 		return err
 	}
 
-	configFile := filepath.Join(configDir, "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+		return err
+	}
+
+	// Values changed on the struct at runtime are not known to viper yet.
+	setFromStruct("", reflect.ValueOf(c).Elem())
+
 	return viper.WriteConfigAs(configFile)
 }
+
+// Path returns the file Save writes the config to.
+func Path() (string, error) {
+	configDir, err := platform.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "config.yaml"), nil
+}
+
+// applyPendingRestore moves a config file restored from a backup into place.
+func applyPendingRestore() error {
+	configFile, err := Path()
+	if err != nil {
+		return err
+	}
+	pending := configFile + RestoreSuffix
+	if _, err := os.Stat(pending); err != nil {
+		return nil
+	}
+	if err := os.Rename(pending, configFile); err != nil {
+		return fmt.Errorf("replace config: %w", err)
+	}
+	log.Printf("Restored config from backup at %s", configFile)
+	return nil
+}
+
+// UseDemo switches to the bundled sample library. Demo sessions keep their
+// own database next to the real one and never save the config, so nothing
+// changed while exploring leaks into the real setup.
+func (c *Config) UseDemo() {
+	c.Demo = true
+	c.Storage.DatabasePath = filepath.Join(filepath.Dir(c.Storage.DatabasePath), "demo.db")
+}
+
+// setFromStruct copies every field tagged with mapstructure into viper.
+func setFromStruct(prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			setFromStruct(key, field)
+			continue
+		}
+		viper.Set(key, field.Interface())
+	}
+}
@@ -0,0 +1,166 @@
+package demo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// Backend serves the sample library in place of a server. It is read-only:
+// playlist changes are refused and plays are not reported anywhere.
+type Backend struct {
+	lib   *library
+	debug bool
+}
+
+// NewBackend builds the sample library and renders any missing tracks into
+// the cache directory.
+func NewBackend(cfg *config.Config) *Backend {
+	dir := filepath.Join(cfg.Storage.CacheDir, "demo")
+	b := &Backend{lib: buildLibrary(dir), debug: cfg.Debug}
+
+	for _, s := range sampleSongs {
+		if err := writeTrack(filepath.Join(dir, s.Slug+".wav"), s.Spec); err != nil {
+			log.Printf("[DEMO] Failed to generate %s: %v", s.Slug, err)
+		}
+	}
+	if b.debug {
+		log.Printf("[DEMO] Sample library ready in %s", dir)
+	}
+	return b
+}
+
+func (b *Backend) GetSongs(ctx context.Context, page int, search string) (*types.SongListResponse, error) {
+	return b.GetSongsWithSort(ctx, page, search, api.SortDefault)
+}
+
+// GetSongsWithSort returns the whole sample library on the first page.
+func (b *Backend) GetSongsWithSort(ctx context.Context, page int, search string, sortOption api.SortOption) (*types.SongListResponse, error) {
+	songs := make([]*types.Song, 0)
+	if page <= 1 {
+		songs = b.lib.searchSongs(search)
+	}
+	return &types.SongListResponse{Count: len(songs), Results: songs}, nil
+}
+
+func (b *Backend) GetSong(ctx context.Context, slug string) (*types.Song, error) {
+	if song := b.lib.song(slug); song != nil {
+		return song, nil
+	}
+	return nil, fmt.Errorf("get song: %s not in the demo library", slug)
+}
+
+func (b *Backend) GetAlbums(ctx context.Context, page int, search string) (*types.AlbumListResponse, error) {
+	albums := make([]*types.Album, 0)
+	if page <= 1 {
+		for _, album := range b.lib.albums {
+			if matches(search, album.Name, album.AlbumArtist) {
+				albums = append(albums, album)
+			}
+		}
+	}
+	return &types.AlbumListResponse{Count: len(albums), Results: albums}, nil
+}
+
+func (b *Backend) GetAlbum(ctx context.Context, slug string) (*types.Album, error) {
+	for _, album := range b.lib.albums {
+		if album.Slug == slug {
+			return album, nil
+		}
+	}
+	return nil, fmt.Errorf("get album: %s not in the demo library", slug)
+}
+
+func (b *Backend) GetAuthors(ctx context.Context, page int, search string) (*types.AuthorListResponse, error) {
+	authors := make([]*types.Author, 0)
+	if page <= 1 {
+		for _, author := range b.lib.authors {
+			if matches(search, author.Name) {
+				authors = append(authors, author)
+			}
+		}
+	}
+	return &types.AuthorListResponse{Count: len(authors), Results: authors}, nil
+}
+
+func (b *Backend) GetAuthor(ctx context.Context, slug string) (*types.Author, error) {
+	for _, author := range b.lib.authors {
+		if author.Slug == slug {
+			return author, nil
+		}
+	}
+	return nil, fmt.Errorf("get author: %s not in the demo library", slug)
+}
+
+func (b *Backend) GetPlaylists(ctx context.Context) ([]*types.Playlist, error) {
+	return []*types.Playlist{b.lib.playlist}, nil
+}
+
+func (b *Backend) GetPlaylist(ctx context.Context, slug string) (*types.Playlist, error) {
+	if slug == b.lib.playlist.Slug {
+		return b.lib.playlist, nil
+	}
+	return nil, fmt.Errorf("get playlist: %s not in the demo library", slug)
+}
+
+func (b *Backend) CreatePlaylist(ctx context.Context, playlist *types.Playlist) error {
+	return fmt.Errorf("create playlist: %w", api.ErrUnsupported)
+}
+
+func (b *Backend) UpdatePlaylist(ctx context.Context, playlist *types.Playlist) error {
+	return fmt.Errorf("update playlist: %w", api.ErrUnsupported)
+}
+
+func (b *Backend) DeletePlaylist(ctx context.Context, slug string) error {
+	return fmt.Errorf("delete playlist: %w", api.ErrUnsupported)
+}
+
+func (b *Backend) SearchAll(ctx context.Context, query string) (*types.SearchResponse, error) {
+	albums, _ := b.GetAlbums(ctx, 1, query)
+	authors, _ := b.GetAuthors(ctx, 1, query)
+	return &types.SearchResponse{
+		Songs:   b.lib.searchSongs(query),
+		Albums:  albums.Results,
+		Authors: authors.Results,
+	}, nil
+}
+
+func (b *Backend) ListenSong(ctx context.Context, slug string, userID string) error {
+	return nil
+}
+
+func (b *Backend) Authenticate(ctx context.Context, token string) error {
+	return fmt.Errorf("authenticate: %w", api.ErrUnsupported)
+}
+
+func (b *Backend) EnsureAnonymousToken(ctx context.Context) (string, error) {
+	return "", fmt.Errorf("anonymous access: %w", api.ErrUnsupported)
+}
+
+func (b *Backend) GetCurrentUser(ctx context.Context) (*types.User, error) {
+	return &types.User{Username: "demo"}, nil
+}
+
+func (b *Backend) Logout(ctx context.Context) error { return nil }
+
+func (b *Backend) IsAnonymous() bool { return true }
+
+func (b *Backend) GetToken() string { return "" }
+
+func (b *Backend) SetToken(token string) {}
+
+func (b *Backend) ProbeServer(ctx context.Context) (*api.ServerInfo, error) {
+	return &api.ServerInfo{Version: "demo", Capabilities: []string{}}, nil
+}
+
+// Supports reports no optional features; the demo library cannot change.
+func (b *Backend) Supports(feature string) bool {
+	return false
+}
+
+var _ api.MusicBackend = (*Backend)(nil)
@@ -0,0 +1,161 @@
+// Package demo provides a read-only sample library that works without a
+// server, for exploring the UI and taking screenshots. Its tracks are
+// synthesized on first use, so they are free of any rights.
+package demo
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+type sampleSong struct {
+	Slug   string
+	Name   string
+	Album  string
+	Author string
+	Spec   trackSpec
+}
+
+var sampleAuthors = map[string]string{
+	"demo-paper-lanterns": "Paper Lanterns",
+	"demo-mira-holt":      "Mira Holt",
+	"demo-quiet-engines":  "Quiet Engines",
+}
+
+var sampleAlbums = []struct {
+	Slug   string
+	Name   string
+	Author string
+}{
+	{"demo-harbor-lights", "Harbor Lights", "demo-paper-lanterns"},
+	{"demo-small-hours", "Small Hours", "demo-mira-holt"},
+	{"demo-slow-machines", "Slow Machines", "demo-quiet-engines"},
+}
+
+var sampleSongs = []sampleSong{
+	{"demo-low-tide", "Low Tide", "demo-harbor-lights", "demo-paper-lanterns", trackSpec{Seed: 1, Root: 220.00, Tempo: 96}},
+	{"demo-signal-fires", "Signal Fires", "demo-harbor-lights", "demo-paper-lanterns", trackSpec{Seed: 2, Root: 246.94, Tempo: 112}},
+	{"demo-north-pier", "North Pier", "demo-harbor-lights", "demo-paper-lanterns", trackSpec{Seed: 3, Root: 196.00, Tempo: 88}},
+	{"demo-kettle-song", "Kettle Song", "demo-small-hours", "demo-mira-holt", trackSpec{Seed: 4, Root: 261.63, Tempo: 104}},
+	{"demo-four-am", "Four A.M.", "demo-small-hours", "demo-mira-holt", trackSpec{Seed: 5, Root: 174.61, Tempo: 72}},
+	{"demo-window-seat", "Window Seat", "demo-small-hours", "demo-mira-holt", trackSpec{Seed: 6, Root: 233.08, Tempo: 120}},
+	{"demo-idle-loop", "Idle Loop", "demo-slow-machines", "demo-quiet-engines", trackSpec{Seed: 7, Root: 164.81, Tempo: 128}},
+	{"demo-cold-start", "Cold Start", "demo-slow-machines", "demo-quiet-engines", trackSpec{Seed: 8, Root: 207.65, Tempo: 100}},
+}
+
+var samplePlaylist = struct {
+	Slug  string
+	Name  string
+	Songs []string
+}{
+	Slug:  "demo-evening-mix",
+	Name:  "Evening Mix",
+	Songs: []string{"demo-four-am", "demo-low-tide", "demo-idle-loop", "demo-window-seat"},
+}
+
+// library is the sample catalog built into linked types.
+type library struct {
+	songs    []*types.Song
+	albums   []*types.Album
+	authors  []*types.Author
+	playlist *types.Playlist
+}
+
+// buildLibrary links the sample catalog together. Audio files live in dir.
+func buildLibrary(dir string) *library {
+	created := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	lib := &library{}
+
+	authors := make(map[string]*types.Author)
+	for slug, name := range sampleAuthors {
+		authors[slug] = &types.Author{Slug: slug, Name: name, CreatedAt: created}
+	}
+
+	albums := make(map[string]*types.Album)
+	for _, a := range sampleAlbums {
+		album := &types.Album{
+			Slug:        a.Slug,
+			Name:        a.Name,
+			AlbumArtist: authors[a.Author].Name,
+			Artists:     []*types.Author{authors[a.Author]},
+			CreatedAt:   created,
+		}
+		albums[a.Slug] = album
+		lib.albums = append(lib.albums, album)
+		authors[a.Author].Albums = append(authors[a.Author].Albums, album)
+	}
+
+	bySlug := make(map[string]*types.Song)
+	for i, s := range sampleSongs {
+		path := filepath.Join(dir, s.Slug+".wav")
+		genre := "Demo"
+		song := &types.Song{
+			Slug:       s.Slug,
+			Name:       s.Name,
+			Length:     s.Spec.Duration(),
+			Album:      albums[s.Album],
+			AlbumSlug:  s.Album,
+			Authors:    []*types.Author{authors[s.Author]},
+			Meta:       &types.Meta{Genre: &genre},
+			LocalPath:  &path,
+			Downloaded: true,
+			CreatedAt:  created.Add(time.Duration(i) * time.Hour),
+		}
+		bySlug[s.Slug] = song
+		lib.songs = append(lib.songs, song)
+		albums[s.Album].Songs = append(albums[s.Album].Songs, song)
+		authors[s.Author].Songs = append(authors[s.Author].Songs, song)
+	}
+
+	for _, a := range sampleAlbums {
+		lib.authors = append(lib.authors, authors[a.Author])
+	}
+
+	lib.playlist = &types.Playlist{
+		Slug:      samplePlaylist.Slug,
+		Name:      samplePlaylist.Name,
+		Creator:   &types.User{Username: "demo"},
+		CreatedAt: created,
+	}
+	for _, slug := range samplePlaylist.Songs {
+		lib.playlist.Songs = append(lib.playlist.Songs, bySlug[slug])
+	}
+	lib.playlist.Length = len(lib.playlist.Songs)
+
+	return lib
+}
+
+func (l *library) song(slug string) *types.Song {
+	for _, song := range l.songs {
+		if song.Slug == slug {
+			return song
+		}
+	}
+	return nil
+}
+
+func (l *library) searchSongs(query string) []*types.Song {
+	var found []*types.Song
+	for _, song := range l.songs {
+		if matches(query, song.Name, song.Album.Name, song.Authors[0].Name) {
+			found = append(found, song)
+		}
+	}
+	return found
+}
+
+func matches(query string, fields ...string) bool {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return true
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
+}
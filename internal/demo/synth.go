@@ -0,0 +1,136 @@
+package demo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+const (
+	sampleRate    = 22050
+	beatsPerBar   = 4
+	barsPerTrack  = 12
+	stepsPerBeat  = 2
+	amplitudePeak = 0.6 * math.MaxInt16
+)
+
+// pentatonic is a major pentatonic scale in semitones above the root, which
+// sounds pleasant whatever notes the generator picks.
+var pentatonic = []int{0, 2, 4, 7, 9, 12, 14, 16}
+
+// trackSpec describes how a sample track is synthesized.
+type trackSpec struct {
+	Seed  int64
+	Root  float64 // root note frequency in Hz
+	Tempo int     // beats per minute
+}
+
+// Duration returns the length of the generated track in seconds.
+func (t trackSpec) Duration() int {
+	return barsPerTrack * beatsPerBar * 60 / t.Tempo
+}
+
+// writeTrack renders the track as a 16-bit mono WAV file unless it already
+// exists.
+func writeTrack(path string, spec trackSpec) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create demo directory: %w", err)
+	}
+
+	samples := synthesize(spec)
+
+	var buf bytes.Buffer
+	dataSize := uint32(len(samples) * 2)
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1)) // PCM
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1)) // mono
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, dataSize)
+	_ = binary.Write(&buf, binary.LittleEndian, samples)
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write demo track: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// synthesize plays a random pentatonic melody over a bass line that follows
+// a I-vi-IV-V progression.
+func synthesize(spec trackSpec) []int16 {
+	rng := rand.New(rand.NewSource(spec.Seed))
+	stepSamples := sampleRate * 60 / spec.Tempo / stepsPerBeat
+	steps := barsPerTrack * beatsPerBar * stepsPerBeat
+	out := make([]float64, steps*stepSamples)
+
+	progression := []int{0, 9, 5, 7}
+	melody := make([]int, beatsPerBar*stepsPerBeat*2)
+	for i := range melody {
+		melody[i] = pentatonic[rng.Intn(len(pentatonic))]
+		if rng.Intn(4) == 0 {
+			melody[i] = -1 // rest
+		}
+	}
+
+	for step := 0; step < steps; step++ {
+		start := step * stepSamples
+		bar := step / (beatsPerBar * stepsPerBeat)
+		chord := progression[bar%len(progression)]
+
+		if note := melody[step%len(melody)]; note >= 0 {
+			addNote(out[start:start+stepSamples], spec.Root*semitones(note+12), 0.35, triangle)
+		}
+		if step%stepsPerBeat == 0 {
+			length := min(stepSamples*stepsPerBeat, len(out)-start)
+			addNote(out[start:start+length], spec.Root*semitones(chord-12), 0.45, math.Sin)
+		}
+	}
+
+	samples := make([]int16, len(out))
+	fade := sampleRate / 2
+	for i, v := range out {
+		if i < fade {
+			v *= float64(i) / float64(fade)
+		}
+		if remaining := len(out) - i; remaining < fade {
+			v *= float64(remaining) / float64(fade)
+		}
+		samples[i] = int16(math.Max(-1, math.Min(1, v)) * amplitudePeak)
+	}
+	return samples
+}
+
+// addNote mixes a plucked note with a short attack and exponential decay.
+func addNote(dst []float64, freq, gain float64, wave func(float64) float64) {
+	attack := float64(sampleRate) * 0.01
+	for i := range dst {
+		t := float64(i) / sampleRate
+		env := math.Exp(-3 * t)
+		if float64(i) < attack {
+			env *= float64(i) / attack
+		}
+		dst[i] += gain * env * wave(2*math.Pi*freq*t)
+	}
+}
+
+func triangle(phase float64) float64 {
+	return 2 / math.Pi * math.Asin(math.Sin(phase))
+}
+
+func semitones(n int) float64 {
+	return math.Pow(2, float64(n)/12)
+}
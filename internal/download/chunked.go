@@ -0,0 +1,242 @@
+package download
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// chunkPlan splits a file into ChunkSize ranges fetched over several
+// connections. The ranges finished so far are listed in a file next to the
+// partial download, so a paused or interrupted download picks up the rest.
+type chunkPlan struct {
+	total     int64
+	chunkSize int64
+	done      map[int]bool
+}
+
+func (p *chunkPlan) count() int {
+	return int((p.total + p.chunkSize - 1) / p.chunkSize)
+}
+
+// bounds returns the first and last byte of chunk i.
+func (p *chunkPlan) bounds(i int) (int64, int64) {
+	start := int64(i) * p.chunkSize
+	return start, min(start+p.chunkSize, p.total) - 1
+}
+
+func (p *chunkPlan) doneBytes() int64 {
+	var n int64
+	for i := range p.done {
+		start, end := p.bounds(i)
+		n += end - start + 1
+	}
+	return n
+}
+
+// chunkStatePath is where the finished chunks of the task are listed.
+func chunkStatePath(task *Task) string {
+	return partialPath(task) + ".chunks"
+}
+
+// connections returns how many connections a file of total bytes is
+// fetched over: one per chunk, up to MaxConcurrent. Files of a single chunk
+// and servers without range support use one.
+func (m *Manager) connections(total int64) int {
+	if total <= 0 || m.config.ChunkSize <= 0 {
+		return 1
+	}
+	chunks := int((total + int64(m.config.ChunkSize) - 1) / int64(m.config.ChunkSize))
+	return max(1, min(chunks, m.config.MaxConcurrent))
+}
+
+// newChunkPlan starts a chunked download of total bytes, with the partial
+// file allocated at full size.
+func (m *Manager) newChunkPlan(task *Task, total int64) (*chunkPlan, error) {
+	plan := &chunkPlan{total: total, chunkSize: int64(m.config.ChunkSize), done: make(map[int]bool)}
+
+	file, err := os.Create(partialPath(task))
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+	err = file.Truncate(total)
+	if closeErr := file.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("allocate temp file: %w", err)
+	}
+
+	header := fmt.Sprintf("%d %d\n", plan.total, plan.chunkSize)
+	if err := os.WriteFile(chunkStatePath(task), []byte(header), 0644); err != nil {
+		return nil, fmt.Errorf("write chunk state: %w", err)
+	}
+	return plan, nil
+}
+
+// loadChunkPlan reads the plan of a chunked download that was interrupted.
+// It reports false when the task has none, or it no longer matches the
+// partial file.
+func (m *Manager) loadChunkPlan(task *Task) (*chunkPlan, bool) {
+	file, err := os.Open(chunkStatePath(task))
+	if err != nil {
+		return nil, false
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			m.debugLog("Failed to close chunk state: %v", closeErr)
+		}
+	}()
+
+	plan := &chunkPlan{done: make(map[int]bool)}
+	scanner := bufio.NewScanner(file)
+	if scanner.Scan() {
+		_, err = fmt.Sscanf(scanner.Text(), "%d %d", &plan.total, &plan.chunkSize)
+	}
+	info, statErr := os.Stat(partialPath(task))
+	if err != nil || statErr != nil || plan.chunkSize <= 0 || info.Size() != plan.total {
+		m.discardChunkPlan(task)
+		return nil, false
+	}
+
+	for scanner.Scan() {
+		index, err := strconv.Atoi(strings.TrimSpace(scanner.Text()))
+		if err == nil && index >= 0 && index < plan.count() {
+			plan.done[index] = true
+		}
+	}
+	return plan, true
+}
+
+// discardChunkPlan deletes an unusable chunked download, to start over.
+func (m *Manager) discardChunkPlan(task *Task) {
+	for _, path := range []string{chunkStatePath(task), partialPath(task)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			m.debugLog("Failed to remove %s: %v", path, err)
+		}
+	}
+}
+
+// downloadChunks fetches the chunks of plan not done yet, each over its own
+// range request, with up to connections requests at a time. A failed chunk
+// stops the others; the finished ones are kept for the next attempt.
+func (m *Manager) downloadChunks(ctx context.Context, task *Task, plan *chunkPlan) error {
+	task.Progress.mutex.Lock()
+	task.Progress.Total = plan.total
+	task.Progress.Downloaded = plan.doneBytes()
+	task.Progress.mutex.Unlock()
+	meter := m.newProgressMeter(task)
+
+	file, err := os.OpenFile(partialPath(task), os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open temp file: %w", err)
+	}
+	state, err := os.OpenFile(chunkStatePath(task), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("open chunk state: %w", err)
+	}
+
+	jobs := make(chan int, plan.count())
+	for i := 0; i < plan.count(); i++ {
+		if !plan.done[i] {
+			jobs <- i
+		}
+	}
+	close(jobs)
+
+	workers := m.connections(plan.total)
+	m.debugLog("Downloading %d of %d chunks over %d connections: %s",
+		len(jobs), plan.count(), workers, task.URL)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				err := m.fetchChunk(ctx, task, file, plan, index, meter)
+				mu.Lock()
+				if err == nil {
+					_, err = fmt.Fprintf(state, "%d\n", index)
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	meter.report()
+
+	if closeErr := state.Close(); closeErr != nil && firstErr == nil {
+		firstErr = fmt.Errorf("close chunk state: %w", closeErr)
+	}
+	if closeErr := file.Close(); closeErr != nil && firstErr == nil {
+		firstErr = fmt.Errorf("close temp file: %w", closeErr)
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	if err := os.Remove(chunkStatePath(task)); err != nil {
+		m.debugLog("Failed to remove chunk state: %v", err)
+	}
+	return m.finishPartial(task, partialPath(task))
+}
+
+// fetchChunk downloads chunk index of plan into its place in file.
+func (m *Manager) fetchChunk(ctx context.Context, task *Task, file *os.File, plan *chunkPlan, index int, meter *progressMeter) error {
+	start, end := plan.bounds(index)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", task.URL, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", m.config.UserAgent)
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("execute request: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			m.debugLog("Failed to close response body: %v", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("HTTP %d: %s for chunk %d", resp.StatusCode, resp.Status, index)
+	}
+
+	length := end - start + 1
+	dst := io.NewOffsetWriter(file, start)
+	written, err := m.copyWithProgress(ctx, dst, io.LimitReader(resp.Body, length), meter)
+	if err != nil {
+		return err
+	}
+	if written != length {
+		return fmt.Errorf("chunk %d: got %d of %d bytes", index, written, length)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 )
 
 func (m *Manager) performDownload(ctx context.Context, task *Task) error {
@@ -17,7 +18,20 @@ func (m *Manager) performDownload(ctx context.Context, task *Task) error {
 	req.Header.Set("User-Agent", m.config.UserAgent)
 	req.Header.Set("Accept", "*/*")
 
-	m.debugLog("Sending HTTP request: %s", task.URL)
+	if plan, ok := m.loadChunkPlan(task); ok {
+		return m.downloadChunks(ctx, task, plan)
+	}
+
+	// Continue a partial file left by a pause, a failure or a restart. A
+	// range from the start tells whether the server can split the file.
+	tempFile := partialPath(task)
+	var offset int64
+	if info, statErr := os.Stat(tempFile); statErr == nil {
+		offset = info.Size()
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+
+	m.debugLog("Sending HTTP request: %s (from byte %d)", task.URL, offset)
 
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
@@ -29,7 +43,31 @@ func (m *Manager) performDownload(ctx context.Context, task *Task) error {
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		if total, ok := rangeSize(resp.Header.Get("Content-Range")); ok && offset == 0 && m.connections(total) > 1 {
+			if closeErr := resp.Body.Close(); closeErr != nil {
+				m.debugLog("Failed to close response body: %v", closeErr)
+			}
+			plan, err := m.newChunkPlan(task, total)
+			if err != nil {
+				return err
+			}
+			return m.downloadChunks(ctx, task, plan)
+		}
+	case resp.StatusCode == http.StatusOK:
+		// The server sent the whole file, so start over
+		offset = 0
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		if size, ok := rangeSize(resp.Header.Get("Content-Range")); ok && size == offset {
+			return m.finishPartial(task, tempFile)
+		}
+		// The partial file does not match the file on the server anymore
+		if removeErr := os.Remove(tempFile); removeErr != nil {
+			m.debugLog("Failed to remove stale partial download: %v", removeErr)
+		}
+		return fmt.Errorf("HTTP %d: partial download no longer matches", resp.StatusCode)
+	default:
 		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
@@ -41,30 +79,40 @@ func (m *Manager) performDownload(ctx context.Context, task *Task) error {
 	}
 
 	task.Progress.mutex.Lock()
-	task.Progress.Total = contentLength
+	task.Progress.Downloaded = offset
+	task.Progress.Total = 0
+	if contentLength > 0 {
+		task.Progress.Total = offset + contentLength
+	}
 	task.Progress.mutex.Unlock()
 
-	m.debugLog("Starting download - Content-Length: %d", contentLength)
+	m.debugLog("Starting download - Content-Length: %d, resuming at: %d", contentLength, offset)
+	meter := m.newProgressMeter(task)
 
-	tempFile := task.Destination + ".tmp"
-	file, err := os.Create(tempFile)
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(tempFile, flags, 0644)
 	if err != nil {
-		return fmt.Errorf("create temp file: %w", err)
+		return fmt.Errorf("open temp file: %w", err)
 	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			m.debugLog("Failed to close temp file: %v", closeErr)
-		}
-	}()
 
-	err = m.copyWithProgress(ctx, file, resp.Body, task)
+	// The partial file is kept on errors for the next attempt to resume
+	_, err = m.copyWithProgress(ctx, file, resp.Body, meter)
+	meter.report()
+	if closeErr := file.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("close temp file: %w", closeErr)
+	}
 	if err != nil {
-		if removeErr := os.Remove(tempFile); removeErr != nil {
-			m.debugLog("Failed to remove temp file: %v", removeErr)
-		}
 		return err
 	}
 
+	return m.finishPartial(task, tempFile)
+}
+
+// finishPartial moves a complete partial file to the task's destination.
+func (m *Manager) finishPartial(task *Task, tempFile string) error {
 	if err := os.Rename(tempFile, task.Destination); err != nil {
 		if removeErr := os.Remove(tempFile); removeErr != nil {
 			m.debugLog("Failed to remove temp file after rename error: %v", removeErr)
@@ -75,3 +123,14 @@ func (m *Manager) performDownload(ctx context.Context, task *Task) error {
 	m.debugLog("Download completed: %s", task.Destination)
 	return nil
 }
+
+// rangeSize returns the full size from a Content-Range header,
+// "bytes start-end/size" or "bytes */size".
+func rangeSize(header string) (int64, bool) {
+	_, size, found := strings.Cut(header, "/")
+	if !found {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(size, 10, 64)
+	return n, err == nil
+}
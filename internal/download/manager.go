@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
@@ -25,6 +27,7 @@ type Manager struct {
 	progressCbs   []ProgressCallback
 	completionCbs []CompletionCallback
 	callbackMutex sync.RWMutex
+	store         *storage.Database
 	debug         bool
 }
 
@@ -34,19 +37,17 @@ func NewManager(cfg *config.Config) *Manager {
 		ChunkSize:     cfg.Download.ChunkSize,
 		RetryAttempts: 3,
 		RetryDelay:    time.Second,
-		Timeout:       time.Minute * 10,
+		Timeout:       netutil.ProfileDownload.Timeout(cfg),
 		UserAgent:     cfg.API.UserAgent,
 		TempDir:       cfg.Download.TempDir,
 		CacheDir:      cfg.Storage.CacheDir,
 	}
 
 	manager := &Manager{
-		config:    downloadConfig,
-		semaphore: make(chan struct{}, downloadConfig.MaxConcurrent),
-		httpClient: &http.Client{
-			Timeout: downloadConfig.Timeout,
-		},
-		debug: cfg.Debug,
+		config:     downloadConfig,
+		semaphore:  make(chan struct{}, downloadConfig.MaxConcurrent),
+		httpClient: netutil.NewClient(cfg, netutil.ProfileDownload),
+		debug:      cfg.Debug,
 	}
 
 	if err := os.MkdirAll(downloadConfig.TempDir, 0755); err != nil {
@@ -108,7 +109,6 @@ func (m *Manager) downloadWithOptions(ctx context.Context, url, destination, tit
 		}
 	}
 
-	taskCtx, cancel := context.WithCancel(ctx)
 	task := &Task{
 		ID:          taskID,
 		URL:         url,
@@ -117,25 +117,52 @@ func (m *Manager) downloadWithOptions(ctx context.Context, url, destination, tit
 		State:       StatePending,
 		Progress:    &Progress{},
 		StartTime:   time.Now(),
-		CancelFunc:  cancel,
 		MaxRetries:  m.config.RetryAttempts,
 		Song:        song,
 	}
 
 	m.tasks.Store(taskID, task)
+	m.persist(task)
 	m.debugLog("Created download task: %s -> %s", url, destination)
 
-	go m.executeDownload(taskCtx, task)
+	m.start(ctx, task)
 
 	return nil
 }
 
+// start runs task in the background with a context derived from parent.
+// A previous run of the task is waited for first, so only one run writes
+// the partial file at a time.
+func (m *Manager) start(parent context.Context, task *Task) {
+	ctx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+
+	task.mutex.Lock()
+	previous := task.done
+	task.CancelFunc = cancel
+	task.done = done
+	task.Retries = 0
+	task.mutex.Unlock()
+
+	go func() {
+		defer close(done)
+		if previous != nil {
+			<-previous
+		}
+		m.executeDownload(ctx, task)
+	}()
+}
+
 func (m *Manager) executeDownload(ctx context.Context, task *Task) {
 	select {
 	case m.semaphore <- struct{}{}:
 		defer func() { <-m.semaphore }()
 	case <-ctx.Done():
-		m.updateTaskState(task, StateCancelled, ctx.Err())
+		m.interrupted(task, ctx.Err())
+		return
+	}
+	if ctx.Err() != nil {
+		m.interrupted(task, ctx.Err())
 		return
 	}
 
@@ -152,7 +179,7 @@ func (m *Manager) executeDownload(ctx context.Context, task *Task) {
 			select {
 			case <-time.After(delay):
 			case <-ctx.Done():
-				m.updateTaskState(task, StateCancelled, ctx.Err())
+				m.interrupted(task, ctx.Err())
 				return
 			}
 		}
@@ -168,6 +195,10 @@ func (m *Manager) executeDownload(ctx context.Context, task *Task) {
 		task.Retries = attempt
 		task.mutex.Unlock()
 
+		if ctx.Err() != nil {
+			m.interrupted(task, ctx.Err())
+			return
+		}
 		if !m.shouldRetry(err) {
 			break
 		}
@@ -195,7 +226,148 @@ func (m *Manager) GetProgress(url string) (*types.DownloadProgress, bool) {
 	return m.taskToProgress(foundTask), true
 }
 
+// interrupted records a run stopped by its context. Pause and Cancel set
+// the state before stopping the run, so it is only changed when neither did.
+func (m *Manager) interrupted(task *Task, err error) {
+	task.mutex.RLock()
+	state := task.State
+	task.mutex.RUnlock()
+
+	if state == StatePaused || state == StateCancelled {
+		return
+	}
+	m.updateTaskState(task, StateCancelled, err)
+}
+
 func (m *Manager) Cancel(url string) error {
+	foundTask := m.findTask(url)
+	if foundTask == nil {
+		return fmt.Errorf("download not found: %s", url)
+	}
+
+	m.updateTaskState(foundTask, StateCancelled, fmt.Errorf("cancelled by user"))
+	m.stop(foundTask)
+	m.removePartial(foundTask)
+	m.debugLog("Cancelled download: %s", url)
+	return nil
+}
+
+// Pause stops the download of url and keeps what was received, to be
+// resumed later.
+func (m *Manager) Pause(url string) error {
+	task := m.findTask(url)
+	if task == nil {
+		return fmt.Errorf("download not found: %s", url)
+	}
+	if state, _ := task.Status(); state != StatePending && state != StateDownloading {
+		return fmt.Errorf("download is %s", strings.ToLower(state.String()))
+	}
+
+	m.updateTaskState(task, StatePaused, nil)
+	m.stop(task)
+	m.debugLog("Paused download: %s", url)
+	return nil
+}
+
+// Resume continues a paused download of url where it stopped.
+func (m *Manager) Resume(url string) error {
+	task := m.findTask(url)
+	if task == nil {
+		return fmt.Errorf("download not found: %s", url)
+	}
+	if state, _ := task.Status(); state != StatePaused {
+		return fmt.Errorf("download is %s", strings.ToLower(state.String()))
+	}
+
+	m.restart(task)
+	m.debugLog("Resumed download: %s", url)
+	return nil
+}
+
+// Retry starts a failed download of url again, from what was received
+// before it failed.
+func (m *Manager) Retry(url string) error {
+	task := m.findTask(url)
+	if task == nil {
+		return fmt.Errorf("download not found: %s", url)
+	}
+	if state, _ := task.Status(); state != StateFailed {
+		return fmt.Errorf("download is %s", strings.ToLower(state.String()))
+	}
+
+	m.restart(task)
+	m.debugLog("Retrying download: %s", url)
+	return nil
+}
+
+// PauseAll pauses every pending or running download and returns how many
+// were paused.
+func (m *Manager) PauseAll() int {
+	paused := 0
+	for _, task := range m.tasksIn(StatePending, StateDownloading) {
+		if err := m.Pause(task.URL); err == nil {
+			paused++
+		}
+	}
+	return paused
+}
+
+// ResumeAll resumes every paused download and returns how many were
+// resumed.
+func (m *Manager) ResumeAll() int {
+	resumed := 0
+	for _, task := range m.tasksIn(StatePaused) {
+		if err := m.Resume(task.URL); err == nil {
+			resumed++
+		}
+	}
+	return resumed
+}
+
+// Remove drops a download that is not running from the list, with its
+// partial file.
+func (m *Manager) Remove(url string) error {
+	task := m.findTask(url)
+	if task == nil {
+		return fmt.Errorf("download not found: %s", url)
+	}
+	state, _ := task.Status()
+	if state == StatePending || state == StateDownloading {
+		return fmt.Errorf("download is %s", strings.ToLower(state.String()))
+	}
+
+	if state != StateCompleted {
+		m.removePartial(task)
+	}
+	m.forget(task)
+	m.tasks.Delete(task.ID)
+	return nil
+}
+
+func (m *Manager) restart(task *Task) {
+	m.updateTaskState(task, StatePending, nil)
+	m.start(context.Background(), task)
+}
+
+// tasksIn returns the tasks in any of states.
+func (m *Manager) tasksIn(states ...State) []*Task {
+	var tasks []*Task
+	m.tasks.Range(func(key, value interface{}) bool {
+		task := value.(*Task)
+		state, _ := task.Status()
+		for _, s := range states {
+			if state == s {
+				tasks = append(tasks, task)
+				break
+			}
+		}
+		return true
+	})
+	return tasks
+}
+
+// findTask returns the task downloading url, or nil.
+func (m *Manager) findTask(url string) *Task {
 	var foundTask *Task
 	m.tasks.Range(func(key, value interface{}) bool {
 		task := value.(*Task)
@@ -205,20 +377,16 @@ func (m *Manager) Cancel(url string) error {
 		}
 		return true
 	})
+	return foundTask
+}
 
-	if foundTask == nil {
-		return fmt.Errorf("download not found: %s", url)
-	}
-
-	foundTask.mutex.Lock()
-	if foundTask.CancelFunc != nil {
-		foundTask.CancelFunc()
+// stop cancels the task's current run, if any.
+func (m *Manager) stop(task *Task) {
+	task.mutex.Lock()
+	if task.CancelFunc != nil {
+		task.CancelFunc()
 	}
-	foundTask.mutex.Unlock()
-
-	m.updateTaskState(foundTask, StateCancelled, fmt.Errorf("cancelled by user"))
-	m.debugLog("Cancelled download: %s", url)
-	return nil
+	task.mutex.Unlock()
 }
 
 func (m *Manager) GetAllDownloads() []*types.DownloadProgress {
@@ -262,6 +430,10 @@ func (m *Manager) ClearCompleted() {
 
 		if state == StateCompleted || state == StateFailed {
 			toDelete = append(toDelete, key.(string))
+			if state == StateFailed {
+				m.removePartial(task)
+				m.forget(task)
+			}
 		}
 		return true
 	})
@@ -0,0 +1,184 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+)
+
+// EnablePersistence keeps unfinished downloads in db, so they survive a
+// restart. Call ResumeSaved once the completion callbacks are set.
+func (m *Manager) EnablePersistence(db *storage.Database) {
+	m.store = db
+}
+
+// ResumeSaved restores the downloads saved by an earlier run. Pending ones
+// continue from their partial files; paused and failed ones are listed
+// until resumed or retried. It returns how many were restored.
+func (m *Manager) ResumeSaved(ctx context.Context) (int, error) {
+	if m.store == nil {
+		return 0, nil
+	}
+
+	items, err := m.store.GetDownloadItems(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("load saved downloads: %w", err)
+	}
+
+	restored := 0
+	for _, item := range items {
+		task, err := m.restoreTask(ctx, item)
+		if err != nil {
+			log.Printf("[DOWNLOAD] Dropping saved download of %s: %v", item.URL, err)
+			if err := m.store.DeleteDownloadItem(ctx, item.URL); err != nil {
+				log.Printf("[DOWNLOAD] Failed to delete saved download: %v", err)
+			}
+			continue
+		}
+		if _, exists := m.tasks.LoadOrStore(task.ID, task); exists {
+			continue
+		}
+		restored++
+
+		if task.State == StatePending {
+			m.start(context.Background(), task)
+		}
+	}
+
+	m.debugLog("Restored %d saved downloads", restored)
+	return restored, nil
+}
+
+func (m *Manager) restoreTask(ctx context.Context, item *storage.DownloadItem) (*Task, error) {
+	task := &Task{
+		ID:          m.generateTaskID(item.URL, item.LocalPath),
+		URL:         item.URL,
+		Destination: item.LocalPath,
+		Title:       item.Title,
+		State:       StatePending,
+		Progress:    &Progress{Total: item.Total},
+		StartTime:   item.CreatedAt,
+		MaxRetries:  m.config.RetryAttempts,
+	}
+	if task.StartTime.IsZero() {
+		task.StartTime = time.Now()
+	}
+
+	if item.SongSlug != "" {
+		song, err := m.store.GetSong(ctx, item.SongSlug)
+		if err != nil {
+			return nil, fmt.Errorf("load song %s: %w", item.SongSlug, err)
+		}
+		if song == nil {
+			return nil, fmt.Errorf("song %s is no longer in the library", item.SongSlug)
+		}
+		task.Song = song
+	}
+
+	switch item.Status {
+	case StatePaused.key():
+		task.State = StatePaused
+	case StateFailed.key():
+		task.State = StateFailed
+		task.Error = errors.New(item.Error)
+		now := time.Now()
+		task.CompletedAt = &now
+	}
+
+	if plan, ok := m.loadChunkPlan(task); ok {
+		task.Progress.Downloaded = plan.doneBytes()
+	} else if info, err := os.Stat(partialPath(task)); err == nil {
+		task.Progress.Downloaded = info.Size()
+	}
+	if task.Progress.Total > 0 {
+		task.Progress.Percentage = float64(task.Progress.Downloaded) / float64(task.Progress.Total) * 100
+	}
+	return task, nil
+}
+
+// persist saves the task's state. Finished and cancelled downloads are
+// forgotten.
+func (m *Manager) persist(task *Task) {
+	if m.store == nil {
+		return
+	}
+
+	task.mutex.RLock()
+	state := task.State
+	item := &storage.DownloadItem{
+		URL:       task.URL,
+		LocalPath: task.Destination,
+		Title:     task.Title,
+		Status:    state.key(),
+		CreatedAt: task.StartTime,
+	}
+	if task.Song != nil {
+		item.SongSlug = task.Song.Slug
+	}
+	if task.Error != nil {
+		item.Error = task.Error.Error()
+	}
+	task.mutex.RUnlock()
+
+	if state == StateCompleted || state == StateCancelled {
+		m.forget(task)
+		return
+	}
+
+	task.Progress.mutex.RLock()
+	item.Progress = task.Progress.Percentage
+	item.Total = task.Progress.Total
+	task.Progress.mutex.RUnlock()
+
+	if err := m.store.SaveDownloadItem(context.Background(), item); err != nil {
+		log.Printf("[DOWNLOAD] Failed to save download state: %v", err)
+	}
+}
+
+// forget deletes the saved state of the task.
+func (m *Manager) forget(task *Task) {
+	if m.store == nil {
+		return
+	}
+	if err := m.store.DeleteDownloadItem(context.Background(), task.URL); err != nil {
+		log.Printf("[DOWNLOAD] Failed to delete download state: %v", err)
+	}
+}
+
+// removePartial deletes the task's partial file, with the chunk list of a
+// chunked download, once its run has stopped, unless the download was
+// started again in the meantime.
+func (m *Manager) removePartial(task *Task) {
+	task.mutex.RLock()
+	done := task.done
+	task.mutex.RUnlock()
+
+	go func() {
+		if done != nil {
+			<-done
+		}
+		if current, ok := m.tasks.Load(task.ID); ok && current != task {
+			return
+		}
+		if state, _ := task.Status(); state == StatePending || state == StateDownloading {
+			return
+		}
+		m.discardChunkPlan(task)
+	}()
+}
+
+// partialPath is where the task's download is written until it completes.
+func partialPath(task *Task) string {
+	return task.Destination + ".tmp"
+}
+
+// key is the state's name in the download_items table.
+func (s State) key() string {
+	return strings.ToLower(s.String())
+}
@@ -7,81 +7,121 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
-func (m *Manager) copyWithProgress(ctx context.Context, dst io.Writer, src io.Reader, task *Task) error {
-	buffer := make([]byte, m.config.ChunkSize)
-	startTime := time.Now()
-	lastProgressUpdate := startTime
+const (
+	// copyBufferSize is how much is read per call, small enough for
+	// progress to move smoothly.
+	copyBufferSize = 32 * 1024
+	// progressInterval is how often progress is reported while a download
+	// runs.
+	progressInterval = 100 * time.Millisecond
+	// speedSmoothing weighs the latest interval in the reported speed.
+	speedSmoothing = 0.3
+)
+
+// progressMeter counts the bytes a task receives, from any number of
+// connections, and reports progress at most every progressInterval.
+type progressMeter struct {
+	m    *Manager
+	task *Task
+
+	mu        sync.Mutex
+	lastAt    time.Time
+	lastBytes int64
+	speed     float64
+}
+
+// newProgressMeter starts measuring from the bytes the task already has.
+func (m *Manager) newProgressMeter(task *Task) *progressMeter {
+	task.Progress.mutex.RLock()
+	downloaded := task.Progress.Downloaded
+	task.Progress.mutex.RUnlock()
+
+	return &progressMeter{m: m, task: task, lastAt: time.Now(), lastBytes: downloaded}
+}
+
+// add counts n more bytes and reports when the interval has passed.
+func (pm *progressMeter) add(n int) {
+	pm.task.Progress.mutex.Lock()
+	pm.task.Progress.Downloaded += int64(n)
+	pm.task.Progress.mutex.Unlock()
+
+	pm.mu.Lock()
+	due := time.Since(pm.lastAt) >= progressInterval
+	pm.mu.Unlock()
+	if due {
+		pm.report()
+	}
+}
+
+// report updates the task's percentage, speed and ETA and notifies the
+// progress callbacks. The speed is smoothed over the recent intervals.
+func (pm *progressMeter) report() {
+	pm.mu.Lock()
+	now := time.Now()
+	elapsed := now.Sub(pm.lastAt).Seconds()
+
+	progress := pm.task.Progress
+	progress.mutex.Lock()
+	downloaded, total := progress.Downloaded, progress.Total
+	if elapsed > 0 {
+		current := float64(downloaded-pm.lastBytes) / elapsed
+		if pm.speed == 0 {
+			pm.speed = current
+		} else {
+			pm.speed = speedSmoothing*current + (1-speedSmoothing)*pm.speed
+		}
+	}
+	if total > 0 {
+		progress.Percentage = float64(downloaded) / float64(total) * 100
+	}
+	progress.Speed = pm.speed
+	if pm.speed > 0 && total > downloaded {
+		progress.ETA = time.Duration(float64(total-downloaded) / pm.speed * float64(time.Second))
+	}
+	progress.LastUpdate = now
+	progress.mutex.Unlock()
+
+	pm.lastAt, pm.lastBytes = now, downloaded
+	pm.mu.Unlock()
+
+	pm.m.notifyProgress(pm.task)
+}
+
+// copyWithProgress copies src to dst, counting on meter, and returns the
+// bytes copied.
+func (m *Manager) copyWithProgress(ctx context.Context, dst io.Writer, src io.Reader, meter *progressMeter) (int64, error) {
+	buffer := make([]byte, copyBufferSize)
+	var written int64
 
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return written, ctx.Err()
 		default:
 		}
 
 		n, err := src.Read(buffer)
 		if n > 0 {
 			if _, writeErr := dst.Write(buffer[:n]); writeErr != nil {
-				return fmt.Errorf("write chunk: %w", writeErr)
-			}
-
-			task.Progress.mutex.Lock()
-			task.Progress.Downloaded += int64(n)
-			downloaded := task.Progress.Downloaded
-			total := task.Progress.Total
-			task.Progress.mutex.Unlock()
-
-			now := time.Now()
-			if now.Sub(lastProgressUpdate) >= 100*time.Millisecond {
-				m.updateProgressMetrics(task, downloaded, total, now, startTime)
-				m.notifyProgress(task)
-				lastProgressUpdate = now
+				return written, fmt.Errorf("write chunk: %w", writeErr)
 			}
+			written += int64(n)
+			meter.add(n)
 		}
 
 		if err != nil {
 			if err == io.EOF {
-				task.Progress.mutex.Lock()
-				downloaded := task.Progress.Downloaded
-				total := task.Progress.Total
-				task.Progress.mutex.Unlock()
-
-				m.updateProgressMetrics(task, downloaded, total, time.Now(), startTime)
-				m.notifyProgress(task)
-				break
+				return written, nil
 			}
-			return fmt.Errorf("read chunk: %w", err)
+			return written, fmt.Errorf("read chunk: %w", err)
 		}
 	}
-
-	return nil
-}
-
-func (m *Manager) updateProgressMetrics(task *Task, downloaded, total int64, now, startTime time.Time) {
-	task.Progress.mutex.Lock()
-	defer task.Progress.mutex.Unlock()
-
-	if total > 0 {
-		task.Progress.Percentage = float64(downloaded) / float64(total) * 100
-	}
-
-	elapsed := now.Sub(startTime).Seconds()
-	if elapsed > 0 {
-		task.Progress.Speed = float64(downloaded) / elapsed
-	}
-
-	if task.Progress.Speed > 0 && total > 0 {
-		remaining := total - downloaded
-		etaSeconds := float64(remaining) / task.Progress.Speed
-		task.Progress.ETA = time.Duration(etaSeconds) * time.Second
-	}
-
-	task.Progress.LastUpdate = now
 }
 
 func (m *Manager) handleDownloadSuccess(task *Task) {
@@ -211,6 +251,8 @@ func (m *Manager) CleanupFailedDownloads() {
 		task.mutex.RUnlock()
 
 		if state == StateFailed {
+			m.removePartial(task)
+			m.forget(task)
 			if _, err := os.Stat(destination); err == nil {
 				if removeErr := os.Remove(destination); removeErr != nil {
 					m.debugLog("Failed to remove failed download: %v", removeErr)
@@ -244,6 +286,7 @@ func (m *Manager) updateTaskState(task *Task, state State, err error) {
 	task.mutex.Unlock()
 
 	m.debugLog("Task state changed: %s -> %s", task.URL, state.String())
+	m.persist(task)
 	m.notifyCompletion(task)
 }
 
@@ -259,6 +302,8 @@ func (m *Manager) stateToDownloadStatus(state State) types.DownloadStatus {
 		return types.DownloadStatusFailed
 	case StateCancelled:
 		return types.DownloadStatusCancelled
+	case StatePaused:
+		return types.DownloadStatusPaused
 	default:
 		return types.DownloadStatusFailed
 	}
@@ -291,8 +336,14 @@ func (m *Manager) taskToProgress(task *Task) *types.DownloadProgress {
 		}
 	}
 
+	songSlug := ""
+	if task.Song != nil {
+		songSlug = task.Song.Slug
+	}
+
 	return &types.DownloadProgress{
 		URL:        task.URL,
+		SongSlug:   songSlug,
 		Filename:   filename,
 		Total:      task.Progress.Total,
 		Downloaded: task.Progress.Downloaded,
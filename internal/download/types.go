@@ -17,6 +17,7 @@ const (
 	StateCompleted
 	StateFailed
 	StateCancelled
+	StatePaused
 )
 
 func (s State) String() string {
@@ -31,6 +32,8 @@ func (s State) String() string {
 		return "Failed"
 	case StateCancelled:
 		return "Cancelled"
+	case StatePaused:
+		return "Paused"
 	default:
 		return "Unknown"
 	}
@@ -52,9 +55,19 @@ type Task struct {
 	MaxRetries  int
 	Song        *types.Song
 
+	// done is closed when the task's current run returns and has released
+	// the partial file.
+	done  chan struct{}
 	mutex sync.RWMutex
 }
 
+// Status returns the task's state and the error that ended it, if any.
+func (t *Task) Status() (State, error) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	return t.State, t.Error
+}
+
 // Progress tracks download progress and statistics
 type Progress struct {
 	Total      int64
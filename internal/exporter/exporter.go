@@ -0,0 +1,180 @@
+// Package exporter renders a list of songs, such as the play queue or a
+// playlist, as a snippet to paste into a blog post or a chat: numbered
+// titles with their artists, durations and links to the songs' pages.
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// Snippet formats.
+const (
+	FormatMarkdown = "Markdown"
+	FormatHTML     = "HTML"
+)
+
+// Formats lists the snippet formats in the order to offer them.
+var Formats = []string{FormatMarkdown, FormatHTML}
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// entry is a song as the snippet shows it.
+type entry struct {
+	Title    string
+	Artists  string
+	Album    string
+	Duration string
+	Link     string
+}
+
+// Extension returns the file name extension for a snippet format, with its
+// dot, or an empty string for an unknown one.
+func Extension(format string) string {
+	switch format {
+	case FormatMarkdown:
+		return ".md"
+	case FormatHTML:
+		return ".html"
+	}
+	return ""
+}
+
+// Render writes the songs as a snippet in the given format under a heading
+// naming the title. Songs without a web page are listed without a link.
+func Render(format, title string, songs []*types.Song) (string, error) {
+	entries := make([]entry, 0, len(songs))
+	total := 0
+	for _, song := range songs {
+		if song == nil {
+			continue
+		}
+		total += song.Length
+		entries = append(entries, newEntry(song))
+	}
+	summary := summarize(len(entries), total)
+
+	switch format {
+	case FormatMarkdown:
+		return renderMarkdown(title, entries, summary), nil
+	case FormatHTML:
+		return renderHTML(title, entries, summary)
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+func newEntry(song *types.Song) entry {
+	e := entry{Title: song.Name}
+	if e.Title == "" {
+		e.Title = song.Slug
+	}
+
+	var artists []string
+	for _, author := range song.Authors {
+		if author != nil && author.Name != "" {
+			artists = append(artists, author.Name)
+		}
+	}
+	e.Artists = strings.Join(artists, ", ")
+
+	if song.Album != nil {
+		e.Album = song.Album.Name
+	}
+	if song.Length > 0 {
+		e.Duration = fmt.Sprintf("%d:%02d", song.Length/60, song.Length%60)
+	}
+	if isWebLink(song.Link) {
+		e.Link = song.Link
+	}
+	return e
+}
+
+func renderMarkdown(title string, entries []entry, summary string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s\n\n", escapeMarkdown(title))
+	for i, e := range entries {
+		name := escapeMarkdown(e.Title)
+		if e.Link != "" {
+			name = fmt.Sprintf("[%s](<%s>)", name, e.Link)
+		}
+		fmt.Fprintf(&b, "%d. **%s**", i+1, name)
+		if e.Artists != "" {
+			fmt.Fprintf(&b, " — %s", escapeMarkdown(e.Artists))
+		}
+		if e.Album != "" {
+			fmt.Fprintf(&b, " · *%s*", escapeMarkdown(e.Album))
+		}
+		if e.Duration != "" {
+			fmt.Fprintf(&b, " (%s)", e.Duration)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\n%s\n", summary)
+	return b.String()
+}
+
+var htmlSnippet = template.Must(template.New("snippet").Parse(`<section class="amp-listening">
+  <h3>{{.Title}}</h3>
+  <ol>
+{{- range .Entries}}
+    <li>
+      {{- if .Link}}<a href="{{.Link}}"><strong>{{.Title}}</strong></a>{{else}}<strong>{{.Title}}</strong>{{end}}
+      {{- if .Artists}} — {{.Artists}}{{end}}
+      {{- if .Album}} · <em>{{.Album}}</em>{{end}}
+      {{- if .Duration}} <span class="duration">({{.Duration}})</span>{{end -}}
+    </li>
+{{- end}}
+  </ol>
+  <p>{{.Summary}}</p>
+</section>
+`))
+
+func renderHTML(title string, entries []entry, summary string) (string, error) {
+	var buf bytes.Buffer
+	err := htmlSnippet.Execute(&buf, struct {
+		Title   string
+		Entries []entry
+		Summary string
+	}{title, entries, summary})
+	if err != nil {
+		return "", fmt.Errorf("render html: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// summarize describes how many songs there are and how long they play.
+func summarize(count, seconds int) string {
+	noun := "songs"
+	if count == 1 {
+		noun = "song"
+	}
+	minutes := (seconds + 30) / 60
+	switch {
+	case seconds == 0:
+		return fmt.Sprintf("%d %s", count, noun)
+	case minutes < 60:
+		return fmt.Sprintf("%d %s · %d min", count, noun, max(minutes, 1))
+	default:
+		return fmt.Sprintf("%d %s · %d h %d min", count, noun, minutes/60, minutes%60)
+	}
+}
+
+// markdownEscaper backslash-escapes the characters that would otherwise
+// start emphasis, links, code or HTML in a song or artist name.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+func isWebLink(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
@@ -0,0 +1,209 @@
+package feedback
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/integrations/bluetooth"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+)
+
+// maxIssueURLLength keeps prefilled issue links under the limit GitHub accepts.
+const maxIssueURLLength = 7000
+
+type Report struct {
+	Description string
+	Diagnostics string
+	Screenshot  []byte
+}
+
+type Reporter struct {
+	cfg        *config.Config
+	httpClient *http.Client
+	version    string
+	commit     string
+	resources  func() string
+}
+
+func NewReporter(cfg *config.Config, version, commit string) *Reporter {
+	return &Reporter{
+		cfg:        cfg,
+		httpClient: netutil.NewClient(cfg, netutil.ProfileFeedback),
+		version:    version,
+		commit:     commit,
+	}
+}
+
+// HasEndpoint reports whether reports can be posted directly instead of
+// opening a prefilled issue in the browser.
+func (r *Reporter) HasEndpoint() bool {
+	return r.cfg.Feedback.Endpoint != ""
+}
+
+// Diagnostics returns a summary of the environment with credentials and
+// personal details removed.
+func (r *Reporter) Diagnostics() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "AMP version: %s (%s)\n", r.version, r.commit)
+	fmt.Fprintf(&b, "Go: %s\n", runtime.Version())
+	fmt.Fprintf(&b, "Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "API base URL: %s\n", r.cfg.API.BaseURL)
+	fmt.Fprintf(&b, "Authenticated: %v\n", r.cfg.API.Token != "" && !r.cfg.User.IsAnonymous)
+	fmt.Fprintf(&b, "Database: %s\n", r.cfg.Storage.DatabasePath)
+	fmt.Fprintf(&b, "Cache dir: %s\n", r.cfg.Storage.CacheDir)
+	fmt.Fprintf(&b, "Audio: %d Hz, buffer %d, low latency %v\n",
+		r.cfg.Audio.SampleRate, r.cfg.Audio.BufferSize, r.cfg.Audio.LowLatencyMode)
+	if device, err := bluetooth.Output(); err == nil && device != nil {
+		fmt.Fprintf(&b, "Bluetooth output: %s\n", device)
+	}
+	fmt.Fprintf(&b, "UI: theme %s, language %s\n", r.cfg.UI.Theme, r.cfg.UI.Language)
+	fmt.Fprintf(&b, "Update channel: %s\n", r.cfg.Update.Channel)
+	if r.resources != nil {
+		b.WriteString(r.resources())
+	}
+
+	return r.Redact(b.String())
+}
+
+// SetResourceReport adds current memory figures to the diagnostics.
+func (r *Reporter) SetResourceReport(report func() string) {
+	r.resources = report
+}
+
+// Redact strips tokens, account details and the home directory from text.
+func (r *Reporter) Redact(text string) string {
+	secrets := map[string]string{
+		r.cfg.API.Token:         "[token]",
+		r.cfg.User.AnonymousID:  "[anonymous-id]",
+		r.cfg.User.Email:        "[email]",
+		r.cfg.User.Username:     "[username]",
+		r.cfg.Feedback.Endpoint: "[feedback-endpoint]",
+	}
+
+	for secret, placeholder := range secrets {
+		if len(secret) < 3 {
+			continue
+		}
+		text = strings.ReplaceAll(text, secret, placeholder)
+	}
+
+	if home, err := os.UserHomeDir(); err == nil && len(home) > 1 {
+		text = strings.ReplaceAll(text, home, "~")
+	}
+
+	return text
+}
+
+// Submit posts the report to the configured feedback endpoint.
+func (r *Reporter) Submit(ctx context.Context, report *Report) error {
+	if !r.HasEndpoint() {
+		return fmt.Errorf("no feedback endpoint configured")
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	fields := map[string]string{
+		"description": report.Description,
+		"diagnostics": report.Diagnostics,
+		"version":     r.version,
+		"commit":      r.commit,
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return fmt.Errorf("write field %s: %w", name, err)
+		}
+	}
+
+	if len(report.Screenshot) > 0 {
+		part, err := writer.CreateFormFile("screenshot", "screenshot.png")
+		if err != nil {
+			return fmt.Errorf("create screenshot part: %w", err)
+		}
+		if _, err := part.Write(report.Screenshot); err != nil {
+			return fmt.Errorf("write screenshot: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("finalize feedback body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.Feedback.Endpoint, body)
+	if err != nil {
+		return fmt.Errorf("create feedback request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("User-Agent", r.cfg.API.UserAgent)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send feedback: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Printf("[FEEDBACK] Failed to close response body: %v", closeErr)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("feedback endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// IssueURL builds a prefilled "new issue" link. Screenshots cannot be passed
+// through a URL, so the body asks the reporter to attach one manually.
+func (r *Reporter) IssueURL(report *Report) (*url.URL, error) {
+	u, err := url.Parse(r.cfg.Feedback.IssueURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse issue url: %w", err)
+	}
+
+	title := strings.TrimSpace(strings.SplitN(report.Description, "\n", 2)[0])
+	if runes := []rune(title); len(runes) > 80 {
+		title = string(runes[:80]) + "..."
+	}
+	if title == "" {
+		title = "Feedback"
+	}
+
+	var body strings.Builder
+	body.WriteString(report.Description)
+	if report.Diagnostics != "" {
+		body.WriteString("\n\n<details><summary>Diagnostics</summary>\n\n```\n")
+		body.WriteString(report.Diagnostics)
+		body.WriteString("```\n</details>\n")
+	}
+	if len(report.Screenshot) > 0 {
+		body.WriteString("\n_A screenshot was saved locally, please attach it to this issue._\n")
+	}
+
+	text := body.String()
+	for {
+		q := u.Query()
+		q.Set("title", title)
+		q.Set("body", text)
+		u.RawQuery = q.Encode()
+		if len(u.String()) <= maxIssueURLLength || len(text) < 100 {
+			break
+		}
+		runes := []rune(text)
+		text = string(runes[:len(runes)*3/4]) + "\n\n[truncated]"
+	}
+
+	return u, nil
+}
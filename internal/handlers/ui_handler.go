@@ -24,16 +24,35 @@ type UIHandlers struct {
 	onAlbumSelected    func(*types.Album)
 	onArtistSelected   func(*types.Author)
 	onPlaylistSelected func(*types.Playlist)
+	onQueueSongs       func(songs []*types.Song, next bool)
+	onDownloadRecorded func(*types.Song)
 }
 
 func NewUIHandlers(musicService *services.MusicService, imageService *services.ImageService, downloadManager *download.Manager, playSyncService *services.PlaySyncService, debug bool) *UIHandlers {
-	return &UIHandlers{
+	h := &UIHandlers{
 		musicService:    musicService,
 		imageService:    imageService,
 		DownloadManager: downloadManager,
 		playSyncService: playSyncService,
 		debug:           debug,
 	}
+	downloadManager.OnCompletion(h.recordDownload)
+	return h
+}
+
+// recordDownload stores a finished song download, so it stays known as
+// downloaded across restarts and can be verified later.
+func (h *UIHandlers) recordDownload(task *download.Task) {
+	if state, _ := task.Status(); state != download.StateCompleted || task.Song == nil {
+		return
+	}
+	if err := h.musicService.RecordDownload(context.Background(), task.Song, task.Destination); err != nil {
+		log.Printf("[UI_HANDLERS] Failed to record download of %s: %v", task.Song.Name, err)
+		return
+	}
+	if h.onDownloadRecorded != nil {
+		h.onDownloadRecorded(task.Song)
+	}
 }
 
 // Add getter methods for access from views
@@ -88,13 +107,55 @@ func (h *UIHandlers) SetOnArtistSelected(callback func(*types.Author)) {
 	h.onArtistSelected = callback
 }
 
+// SetOnDownloadRecorded is called from a background goroutine after a
+// finished song download was stored.
+func (h *UIHandlers) SetOnDownloadRecorded(callback func(*types.Song)) {
+	h.onDownloadRecorded = callback
+}
+
 func (h *UIHandlers) SetOnPlaylistSelected(callback func(*types.Playlist)) {
 	h.onPlaylistSelected = callback
 }
 
+// SetOnQueueSongs is called to queue songs without interrupting playback:
+// right after the current song when next is set, at the end otherwise.
+func (h *UIHandlers) SetOnQueueSongs(callback func(songs []*types.Song, next bool)) {
+	h.onQueueSongs = callback
+}
+
+// HandlePlayNext queues songs to play after the current one.
+func (h *UIHandlers) HandlePlayNext(songs ...*types.Song) {
+	h.queueSongs(songs, true)
+}
+
+// HandleAddToQueue queues songs after everything already queued.
+func (h *UIHandlers) HandleAddToQueue(songs ...*types.Song) {
+	h.queueSongs(songs, false)
+}
+
+func (h *UIHandlers) queueSongs(songs []*types.Song, next bool) {
+	if len(songs) == 0 || h.onQueueSongs == nil {
+		return
+	}
+	if h.debug {
+		log.Printf("[UI_HANDLERS] Queueing %d songs (next: %v)", len(songs), next)
+	}
+	h.onQueueSongs(songs, next)
+}
+
 func (h *UIHandlers) HandleSongSelection(song *types.Song, playlist []*types.Song) {
+	h.HandleSongSelectionFrom(song, playlist, types.PlaySource{Type: types.PlaySourceLibrary, Name: "Songs"})
+}
+
+// HandleSongSelectionFrom starts playback and tags the resulting listening
+// history with the view or collection it was started from.
+func (h *UIHandlers) HandleSongSelectionFrom(song *types.Song, playlist []*types.Song, source types.PlaySource) {
 	if h.debug {
-		log.Printf("[UI_HANDLERS] Song selected for playback: %s", song.Name)
+		log.Printf("[UI_HANDLERS] Song selected for playback: %s (from %s)", song.Name, source.Type)
+	}
+
+	if h.playSyncService != nil {
+		h.playSyncService.SetPlaySource(source)
 	}
 
 	if h.onSongSelected != nil {
@@ -104,14 +165,6 @@ func (h *UIHandlers) HandleSongSelection(song *types.Song, playlist []*types.Son
 	go func() {
 		ctx := context.Background()
 
-		if h.playSyncService != nil {
-			if err := h.playSyncService.RecordAndSendListen(ctx, song); err != nil {
-				if h.debug {
-					log.Printf("[UI_HANDLERS] Failed to record/send listen for %s: %v", song.Name, err)
-				}
-			}
-		}
-
 		if h.isLocallyAvailable(song) {
 			if h.debug {
 				log.Printf("[UI_HANDLERS] Song '%s' is available locally", song.Name)
@@ -239,6 +292,9 @@ func (h *UIHandlers) HandleDownloadCompletion(song *types.Song, localPath string
 }
 
 func (h *UIHandlers) HandleAlbumSelection(album *types.Album) {
+	if h.playSyncService != nil {
+		h.playSyncService.SetPlaySource(types.PlaySource{Type: types.PlaySourceAlbum, ID: album.Slug, Name: album.Name})
+	}
 	if h.onAlbumSelected != nil {
 		h.onAlbumSelected(album)
 	}
@@ -248,6 +304,9 @@ func (h *UIHandlers) HandleAlbumSelection(album *types.Album) {
 }
 
 func (h *UIHandlers) HandleArtistSelection(artist *types.Author) {
+	if h.playSyncService != nil {
+		h.playSyncService.SetPlaySource(types.PlaySource{Type: types.PlaySourceAuthor, ID: artist.Slug, Name: artist.Name})
+	}
 	if h.onArtistSelected != nil {
 		h.onArtistSelected(artist)
 	}
@@ -257,6 +316,9 @@ func (h *UIHandlers) HandleArtistSelection(artist *types.Author) {
 }
 
 func (h *UIHandlers) HandlePlaylistSelection(playlist *types.Playlist) {
+	if h.playSyncService != nil {
+		h.playSyncService.SetPlaySource(types.PlaySource{Type: types.PlaySourcePlaylist, ID: playlist.Slug, Name: playlist.Name})
+	}
 	if h.onPlaylistSelected != nil {
 		h.onPlaylistSelected(playlist)
 	}
@@ -0,0 +1,148 @@
+// Package importer reads libraries, playlists and listening logs written by
+// other music players, so ratings, play counts, playlists and history can be
+// carried over into AMP. It only parses; matching entries to library songs
+// is up to the caller.
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source formats.
+const (
+	FormatITunes             = "iTunes/Music library"
+	FormatRhythmbox          = "Rhythmbox library"
+	FormatRhythmboxPlaylists = "Rhythmbox playlists"
+	FormatM3U                = "M3U playlist"
+	FormatPLS                = "PLS playlist"
+	FormatScrobblerLog       = "scrobbler log"
+)
+
+var ErrUnknownFormat = errors.New("unrecognized file format")
+
+// Track is a song as another player describes it.
+type Track struct {
+	Title  string
+	Artist string
+	Album  string
+	// Path is the file the player played, when it is known.
+	Path string
+	// Plays is the player's play count.
+	Plays int
+	// Rating is from 0 to 100; 0 means unrated.
+	Rating int
+	Loved  bool
+}
+
+// Playlist is a named list of tracks in order.
+type Playlist struct {
+	Name   string
+	Tracks []*Track
+}
+
+// Listen is one play of a track from a listening log.
+type Listen struct {
+	Track   *Track
+	At      time.Time
+	Seconds int
+}
+
+// Library is everything read from one file.
+type Library struct {
+	Format    string
+	Tracks    []*Track
+	Playlists []*Playlist
+	Listens   []*Listen
+}
+
+// Parse reads the file named name, telling the format from its extension and
+// contents.
+func Parse(name string, data []byte) (*Library, error) {
+	var (
+		lib *Library
+		err error
+	)
+	switch ext := strings.ToLower(filepath.Ext(name)); {
+	case ext == ".m3u" || ext == ".m3u8":
+		lib = parseM3U(playlistName(name), data)
+	case ext == ".pls":
+		lib = parsePLS(playlistName(name), data)
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("#AUDIOSCROBBLER")):
+		lib, err = parseScrobblerLog(data)
+	case ext == ".xml":
+		lib, err = parseXML(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(name), err)
+	}
+	return lib, nil
+}
+
+// parseXML tells the XML formats apart by their root element.
+func parseXML(data []byte) (*Library, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, ErrUnknownFormat
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "plist":
+			return parseITunes(dec)
+		case "rhythmdb":
+			return parseRhythmDB(dec, start)
+		case "rhythmdb-playlists":
+			return parseRhythmboxPlaylists(dec, start)
+		default:
+			return nil, ErrUnknownFormat
+		}
+	}
+}
+
+func playlistName(name string) string {
+	base := filepath.Base(name)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// filePath turns a file:// URL into a path; anything else is returned as is.
+func filePath(location string) string {
+	location = strings.TrimSpace(location)
+	if !strings.HasPrefix(location, "file:") {
+		return location
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// trackFromPath guesses the title and artist from a file name of the common
+// "Artist - Title" form, for playlists that list nothing but files.
+func trackFromPath(path string) *Track {
+	track := &Track{Path: path}
+	base := filepath.Base(filepath.ToSlash(path))
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	track.Artist, track.Title = splitArtistTitle(base)
+	return track
+}
+
+func splitArtistTitle(s string) (artist, title string) {
+	if i := strings.Index(s, " - "); i > 0 {
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+3:])
+	}
+	return "", strings.TrimSpace(s)
+}
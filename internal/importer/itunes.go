@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseITunes reads the "Library.xml" property list that iTunes and the
+// Music app export. dec is positioned just inside the <plist> element.
+func parseITunes(dec *xml.Decoder) (*Library, error) {
+	root, err := readPlistRoot(dec)
+	if err != nil {
+		return nil, err
+	}
+	dict, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("library is not a dictionary")
+	}
+
+	lib := &Library{Format: FormatITunes}
+	byID := make(map[string]*Track)
+
+	tracks, _ := dict["Tracks"].(map[string]interface{})
+	for id, value := range tracks {
+		entry, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		track := &Track{
+			Title:  plistString(entry, "Name"),
+			Artist: plistString(entry, "Artist"),
+			Album:  plistString(entry, "Album"),
+			Path:   filePath(plistString(entry, "Location")),
+			Plays:  int(plistInt(entry, "Play Count")),
+			Loved:  plistBool(entry, "Loved") || plistBool(entry, "Favorited"),
+		}
+		// Computed ratings are inherited from the album, not given by the
+		// listener.
+		if !plistBool(entry, "Rating Computed") {
+			track.Rating = int(plistInt(entry, "Rating"))
+		}
+		if track.Title == "" {
+			continue
+		}
+		byID[id] = track
+		lib.Tracks = append(lib.Tracks, track)
+	}
+
+	playlists, _ := dict["Playlists"].([]interface{})
+	for _, value := range playlists {
+		entry, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		// Skip the library itself, built-in lists such as Music or Podcasts,
+		// folders and smart playlists, which the source recomputes.
+		if plistBool(entry, "Master") || plistBool(entry, "Folder") ||
+			entry["Distinguished Kind"] != nil || entry["Smart Info"] != nil {
+			continue
+		}
+
+		playlist := &Playlist{Name: plistString(entry, "Name")}
+		items, _ := entry["Playlist Items"].([]interface{})
+		for _, item := range items {
+			ref, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id := strconv.FormatInt(plistInt(ref, "Track ID"), 10)
+			if track := byID[id]; track != nil {
+				playlist.Tracks = append(playlist.Tracks, track)
+			}
+		}
+		if playlist.Name != "" && len(playlist.Tracks) > 0 {
+			lib.Playlists = append(lib.Playlists, playlist)
+		}
+	}
+	return lib, nil
+}
+
+func plistString(dict map[string]interface{}, key string) string {
+	s, _ := dict[key].(string)
+	return strings.TrimSpace(s)
+}
+
+func plistInt(dict map[string]interface{}, key string) int64 {
+	n, _ := dict[key].(int64)
+	return n
+}
+
+func plistBool(dict map[string]interface{}, key string) bool {
+	b, _ := dict[key].(bool)
+	return b
+}
+
+// readPlistRoot reads the single value inside <plist>.
+func readPlistRoot(dec *xml.Decoder) (interface{}, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("read property list: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return readPlistValue(dec, start)
+		}
+	}
+}
+
+// readPlistValue reads the value that start opens into maps, slices,
+// strings, int64s and bools. Other kinds are kept as their text.
+func readPlistValue(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		dict := make(map[string]interface{})
+		key := ""
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("read dict: %w", err)
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "key" {
+					if err := dec.DecodeElement(&key, &t); err != nil {
+						return nil, fmt.Errorf("read key: %w", err)
+					}
+					continue
+				}
+				value, err := readPlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				dict[key] = value
+			case xml.EndElement:
+				return dict, nil
+			}
+		}
+	case "array":
+		var array []interface{}
+		for {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, fmt.Errorf("read array: %w", err)
+			}
+			switch t := tok.(type) {
+			case xml.StartElement:
+				value, err := readPlistValue(dec, t)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			case xml.EndElement:
+				return array, nil
+			}
+		}
+	case "true", "false":
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	var text string
+	if err := dec.DecodeElement(&text, &start); err != nil {
+		return nil, fmt.Errorf("read %s: %w", start.Name.Local, err)
+	}
+	if start.Name.Local == "integer" {
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("read integer: %w", err)
+		}
+		return n, nil
+	}
+	return text, nil
+}
@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parseM3U reads an M3U or M3U8 playlist, as MusicBee, foobar2000 and most
+// other players export them. #EXTINF lines give the artist and title;
+// without them they are guessed from the file name.
+func parseM3U(name string, data []byte) *Library {
+	lib := &Library{Format: FormatM3U}
+	playlist := &Playlist{Name: name}
+
+	var info string
+	scanner := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#EXTINF:"):
+			if i := strings.Index(line, ","); i >= 0 {
+				info = strings.TrimSpace(line[i+1:])
+			}
+		case strings.HasPrefix(line, "#"):
+		default:
+			track := trackFromPath(filePath(line))
+			if info != "" {
+				track.Artist, track.Title = splitArtistTitle(info)
+			}
+			info = ""
+			playlist.Tracks = append(playlist.Tracks, track)
+			lib.Tracks = append(lib.Tracks, track)
+		}
+	}
+
+	if len(playlist.Tracks) > 0 {
+		lib.Playlists = append(lib.Playlists, playlist)
+	}
+	return lib
+}
+
+// parsePLS reads a PLS playlist of numbered FileN and TitleN keys.
+func parsePLS(name string, data []byte) *Library {
+	files := make(map[int]string)
+	titles := make(map[int]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		lower := strings.ToLower(key)
+		switch {
+		case strings.HasPrefix(lower, "file"):
+			if n, err := strconv.Atoi(key[4:]); err == nil {
+				files[n] = value
+			}
+		case strings.HasPrefix(lower, "title"):
+			if n, err := strconv.Atoi(key[5:]); err == nil {
+				titles[n] = value
+			}
+		}
+	}
+
+	numbers := make([]int, 0, len(files))
+	for n := range files {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	lib := &Library{Format: FormatPLS}
+	playlist := &Playlist{Name: name}
+	for _, n := range numbers {
+		track := trackFromPath(filePath(files[n]))
+		if title := strings.TrimSpace(titles[n]); title != "" {
+			track.Artist, track.Title = splitArtistTitle(title)
+		}
+		playlist.Tracks = append(playlist.Tracks, track)
+		lib.Tracks = append(lib.Tracks, track)
+	}
+	if len(playlist.Tracks) > 0 {
+		lib.Playlists = append(lib.Playlists, playlist)
+	}
+	return lib
+}
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rhythmdbEntry is one <entry> of Rhythmbox's rhythmdb.xml.
+type rhythmdbEntry struct {
+	Type      string `xml:"type,attr"`
+	Title     string `xml:"title"`
+	Artist    string `xml:"artist"`
+	Album     string `xml:"album"`
+	Location  string `xml:"location"`
+	PlayCount string `xml:"play-count"`
+	Rating    string `xml:"rating"`
+}
+
+// parseRhythmDB reads Rhythmbox's song database, where ratings are stars
+// from 0 to 5.
+func parseRhythmDB(dec *xml.Decoder, root xml.StartElement) (*Library, error) {
+	lib := &Library{Format: FormatRhythmbox}
+	err := forEachChild(dec, root, "entry", func(start xml.StartElement) error {
+		var entry rhythmdbEntry
+		if err := dec.DecodeElement(&entry, &start); err != nil {
+			return err
+		}
+		if entry.Type != "song" || strings.TrimSpace(entry.Title) == "" {
+			return nil
+		}
+		plays, _ := strconv.Atoi(strings.TrimSpace(entry.PlayCount))
+		stars, _ := strconv.ParseFloat(strings.TrimSpace(entry.Rating), 64)
+		lib.Tracks = append(lib.Tracks, &Track{
+			Title:  strings.TrimSpace(entry.Title),
+			Artist: strings.TrimSpace(entry.Artist),
+			Album:  strings.TrimSpace(entry.Album),
+			Path:   filePath(entry.Location),
+			Plays:  plays,
+			Rating: int(stars * 20),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read rhythmdb: %w", err)
+	}
+	return lib, nil
+}
+
+// rhythmboxPlaylist is one <playlist> of Rhythmbox's playlists.xml.
+type rhythmboxPlaylist struct {
+	Name      string   `xml:"name,attr"`
+	Type      string   `xml:"type,attr"`
+	Locations []string `xml:"location"`
+}
+
+// parseRhythmboxPlaylists reads the static playlists of playlists.xml.
+// Entries are bare file locations, so titles come from the file names.
+func parseRhythmboxPlaylists(dec *xml.Decoder, root xml.StartElement) (*Library, error) {
+	lib := &Library{Format: FormatRhythmboxPlaylists}
+	err := forEachChild(dec, root, "playlist", func(start xml.StartElement) error {
+		var entry rhythmboxPlaylist
+		if err := dec.DecodeElement(&entry, &start); err != nil {
+			return err
+		}
+		if entry.Type != "static" || entry.Name == "" || len(entry.Locations) == 0 {
+			return nil
+		}
+		playlist := &Playlist{Name: entry.Name}
+		for _, location := range entry.Locations {
+			track := trackFromPath(filePath(location))
+			playlist.Tracks = append(playlist.Tracks, track)
+			lib.Tracks = append(lib.Tracks, track)
+		}
+		lib.Playlists = append(lib.Playlists, playlist)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read playlists: %w", err)
+	}
+	return lib, nil
+}
+
+// forEachChild calls fn for every child of root named name and skips the
+// rest, until root ends.
+func forEachChild(dec *xml.Decoder, root xml.StartElement, name string, fn func(xml.StartElement) error) error {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != name {
+				if err := dec.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := fn(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			if t.Name.Local == root.Name.Local {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseScrobblerLog reads a .scrobbler.log in the Audioscrobbler portable
+// player format that Rockbox and other devices write. Every listened ("L")
+// line becomes a listen; skipped ("S") ones are ignored. Each track's play
+// count is the number of its listens.
+func parseScrobblerLog(data []byte) (*Library, error) {
+	lib := &Library{Format: FormatScrobblerLog}
+	tracks := make(map[string]*Track)
+	localTime := true
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(text, "#") {
+			if strings.HasPrefix(text, "#TZ/") {
+				localTime = text != "#TZ/UTC"
+			}
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) < 7 {
+			return nil, fmt.Errorf("line %d: expected 7 fields, got %d", line, len(fields))
+		}
+		if fields[5] != "L" {
+			continue
+		}
+		stamp, err := strconv.ParseInt(fields[6], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad timestamp %q", line, fields[6])
+		}
+		seconds, _ := strconv.Atoi(fields[4])
+
+		artist, album, title := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), strings.TrimSpace(fields[2])
+		key := strings.ToLower(artist + "\x00" + album + "\x00" + title)
+		track := tracks[key]
+		if track == nil {
+			track = &Track{Title: title, Artist: artist, Album: album}
+			tracks[key] = track
+			lib.Tracks = append(lib.Tracks, track)
+		}
+		track.Plays++
+
+		lib.Listens = append(lib.Listens, &Listen{
+			Track:   track,
+			At:      scrobbleTime(stamp, localTime),
+			Seconds: seconds,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lib, nil
+}
+
+// scrobbleTime converts a log timestamp. Devices without a time zone
+// ("#TZ/UNKNOWN") write their local clock as if it were UTC.
+func scrobbleTime(stamp int64, localTime bool) time.Time {
+	t := time.Unix(stamp, 0).UTC()
+	if !localTime {
+		return t.Local()
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local)
+}
@@ -0,0 +1,81 @@
+// Package bluetooth reports the Bluetooth headphones or speaker audio is
+// playing on, with its battery level and the codec in use, where the system
+// exposes them. On Linux they come from BlueZ over D-Bus.
+package bluetooth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Device is a Bluetooth audio output.
+type Device struct {
+	Name string
+	// Battery is the charge in percent, or -1 when the device doesn't
+	// report it.
+	Battery int
+	// Codec is the audio codec of the connection, like "SBC", "AAC" or
+	// "LDAC", empty when unknown.
+	Codec string
+}
+
+// String renders the device as "WH-1000XM4 · 80% · LDAC", leaving out what
+// is unknown.
+func (d Device) String() string {
+	parts := []string{d.Name}
+	if d.Battery >= 0 {
+		parts = append(parts, fmt.Sprintf("%d%%", d.Battery))
+	}
+	if d.Codec != "" {
+		parts = append(parts, d.Codec)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// A2DP codec ids, from the Bluetooth assigned numbers.
+const (
+	codecSBC    = 0x00
+	codecMPEG12 = 0x01
+	codecAAC    = 0x02
+	codecATRAC  = 0x04
+	codecLC3    = 0x06
+	codecVendor = 0xff
+)
+
+// vendorCodecs names vendor specific codecs by vendor and codec id.
+var vendorCodecs = map[[2]uint32]string{
+	{0x004f, 0x0001}: "aptX",
+	{0x00d7, 0x0024}: "aptX HD",
+	{0x000a, 0x0002}: "aptX Low Latency",
+	{0x00d7, 0x00ad}: "aptX Adaptive",
+	{0x012d, 0x00aa}: "LDAC",
+	{0x08a9, 0x0001}: "LC3plus",
+}
+
+// codecName names a transport's codec. Vendor codecs are told apart by the
+// vendor and codec ids that start their configuration.
+func codecName(codec byte, configuration []byte) string {
+	switch codec {
+	case codecSBC:
+		return "SBC"
+	case codecMPEG12:
+		return "MP3"
+	case codecAAC:
+		return "AAC"
+	case codecATRAC:
+		return "ATRAC"
+	case codecLC3:
+		return "LC3"
+	case codecVendor:
+		if len(configuration) < 6 {
+			return "Vendor codec"
+		}
+		vendor := uint32(configuration[0]) | uint32(configuration[1])<<8 | uint32(configuration[2])<<16 | uint32(configuration[3])<<24
+		id := uint32(configuration[4]) | uint32(configuration[5])<<8
+		if name, ok := vendorCodecs[[2]uint32{vendor, id}]; ok {
+			return name
+		}
+		return fmt.Sprintf("Vendor codec %04x:%04x", vendor, id)
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+//go:build linux
+
+package bluetooth
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	bluezBus       = "org.bluez"
+	transportIface = "org.bluez.MediaTransport1"
+	deviceIface    = "org.bluez.Device1"
+	batteryIface   = "org.bluez.Battery1"
+)
+
+// Output returns the Bluetooth device audio is streaming to, or nil when
+// no Bluetooth audio is connected. A transport that is streaming wins over
+// one that is only connected.
+func Output() (*Device, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, fmt.Errorf("connect to system bus: %w", err)
+	}
+	defer conn.Close()
+
+	var objects map[dbus.ObjectPath]map[string]map[string]dbus.Variant
+	call := conn.Object(bluezBus, "/").Call("org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0)
+	if err := call.Store(&objects); err != nil {
+		return nil, fmt.Errorf("list bluetooth objects: %w", err)
+	}
+
+	var transport map[string]dbus.Variant
+	for _, interfaces := range objects {
+		props, ok := interfaces[transportIface]
+		if !ok {
+			continue
+		}
+		if transport == nil || stringProp(props, "State") == "active" {
+			transport = props
+		}
+	}
+	if transport == nil {
+		return nil, nil
+	}
+
+	devicePath, _ := transport["Device"].Value().(dbus.ObjectPath)
+	device := objects[devicePath]
+	name := stringProp(device[deviceIface], "Alias")
+	if name == "" {
+		name = stringProp(device[deviceIface], "Name")
+	}
+
+	battery := -1
+	if percentage, ok := device[batteryIface]["Percentage"].Value().(byte); ok {
+		battery = int(percentage)
+	}
+
+	codec, _ := transport["Codec"].Value().(byte)
+	configuration, _ := transport["Configuration"].Value().([]byte)
+
+	return &Device{Name: name, Battery: battery, Codec: codecName(codec, configuration)}, nil
+}
+
+func stringProp(props map[string]dbus.Variant, name string) string {
+	value, _ := props[name].Value().(string)
+	return value
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package bluetooth
+
+import "errors"
+
+var errUnsupported = errors.New("bluetooth device details are only available on Linux")
+
+// Output has no way to learn about Bluetooth devices on these platforms.
+func Output() (*Device, error) { return nil, errUnsupported }
@@ -0,0 +1,144 @@
+// Package cast plays songs on Chromecast and DLNA renderers on the local
+// network. Chromecasts are found over mDNS and driven over the Cast V2
+// protocol; DLNA renderers are found over SSDP and driven through their UPnP
+// AVTransport service. Files that only exist on this machine are lent to the
+// renderer by a small HTTP server.
+package cast
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the protocol a renderer speaks.
+type Kind string
+
+const (
+	KindChromecast Kind = "chromecast"
+	KindDLNA       Kind = "dlna"
+)
+
+// Device is a renderer found on the network.
+type Device struct {
+	ID   string
+	Name string
+	Kind Kind
+	// Addr is host:port for a Chromecast and the description URL for a
+	// DLNA renderer.
+	Addr string
+}
+
+// Host returns the host the device is reached at.
+func (d Device) Host() string {
+	addr := d.Addr
+	if i := strings.Index(addr, "://"); i >= 0 {
+		addr = addr[i+3:]
+	}
+	if i := strings.IndexByte(addr, '/'); i >= 0 {
+		addr = addr[:i]
+	}
+	if i := strings.LastIndexByte(addr, ':'); i >= 0 && !strings.HasSuffix(addr, "]") {
+		addr = addr[:i]
+	}
+	return strings.Trim(addr, "[]")
+}
+
+// Media is a song to play, reachable by the renderer at URL.
+type Media struct {
+	URL         string
+	ContentType string
+	Title       string
+	Artist      string
+	Album       string
+	Cover       string
+	Duration    time.Duration
+}
+
+// State is what a renderer is doing.
+type State string
+
+const (
+	StateIdle      State = "idle"
+	StateBuffering State = "buffering"
+	StatePlaying   State = "playing"
+	StatePaused    State = "paused"
+	// StateFinished is reported once the media played to its end.
+	StateFinished State = "finished"
+)
+
+// Status is where a renderer is in its media.
+type Status struct {
+	State    State
+	Position time.Duration
+	Duration time.Duration
+}
+
+// Renderer is a connected device. Its methods may be called from any
+// goroutine.
+type Renderer interface {
+	// Load replaces what plays with media, starting at start.
+	Load(ctx context.Context, media Media, start time.Duration) error
+	Play(ctx context.Context) error
+	Pause(ctx context.Context) error
+	Stop(ctx context.Context) error
+	Seek(ctx context.Context, pos time.Duration) error
+	Status(ctx context.Context) (Status, error)
+	Close() error
+}
+
+// Discover looks for renderers until ctx is done and returns those found,
+// sorted by name. It fails only when neither protocol could search.
+func Discover(ctx context.Context) ([]Device, error) {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		devices = make(map[string]Device)
+		errs    []error
+	)
+	search := func(discover func(context.Context, func(Device)) error) {
+		defer wg.Done()
+		err := discover(ctx, func(device Device) {
+			mu.Lock()
+			devices[string(device.Kind)+"/"+device.ID] = device
+			mu.Unlock()
+		})
+		if err != nil {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}
+	}
+
+	wg.Add(2)
+	go search(discoverChromecasts)
+	go search(discoverDLNA)
+	wg.Wait()
+
+	if len(errs) == 2 {
+		return nil, fmt.Errorf("discover renderers: %v; %v", errs[0], errs[1])
+	}
+	found := make([]Device, 0, len(devices))
+	for _, device := range devices {
+		found = append(found, device)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return strings.ToLower(found[i].Name) < strings.ToLower(found[j].Name)
+	})
+	return found, nil
+}
+
+// Connect opens a session with device.
+func Connect(ctx context.Context, device Device) (Renderer, error) {
+	switch device.Kind {
+	case KindChromecast:
+		return dialChromecast(ctx, device)
+	case KindDLNA:
+		return dialDLNA(ctx, device)
+	default:
+		return nil, fmt.Errorf("unknown renderer kind %q", device.Kind)
+	}
+}
@@ -0,0 +1,590 @@
+package cast
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	mdnsAddr        = "224.0.0.251:5353"
+	googlecastQuery = "_googlecast._tcp.local."
+
+	// defaultMediaReceiver is the receiver app every Chromecast has for
+	// playing a URL.
+	defaultMediaReceiver = "CC1AD845"
+
+	namespaceConnection = "urn:x-cast:com.google.cast.tp.connection"
+	namespaceHeartbeat  = "urn:x-cast:com.google.cast.tp.heartbeat"
+	namespaceReceiver   = "urn:x-cast:com.google.cast.receiver"
+	namespaceMedia      = "urn:x-cast:com.google.cast.media"
+
+	castSender   = "sender-0"
+	castReceiver = "receiver-0"
+
+	// castHeartbeat is how often the connection is pinged; the device drops
+	// senders that go quiet.
+	castHeartbeat = 5 * time.Second
+	// castMaxMessage bounds the size of a message read from the device.
+	castMaxMessage = 64 << 10
+)
+
+// discoverChromecasts asks over mDNS for Cast devices and reports each one
+// that answers, until ctx is done.
+func discoverChromecasts(ctx context.Context, found func(Device)) error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return fmt.Errorf("open mDNS socket: %w", err)
+	}
+	defer conn.Close()
+
+	dest, err := net.ResolveUDPAddr("udp4", mdnsAddr)
+	if err != nil {
+		return err
+	}
+	name, err := dnsmessage.NewName(googlecastQuery)
+	if err != nil {
+		return err
+	}
+	// The top bit of the class asks for unicast answers, which reach this
+	// socket without joining the multicast group.
+	query := dnsmessage.Message{Questions: []dnsmessage.Question{{
+		Name:  name,
+		Type:  dnsmessage.TypePTR,
+		Class: dnsmessage.ClassINET | 1<<15,
+	}}}
+	packet, err := query.Pack()
+	if err != nil {
+		return err
+	}
+	for range 2 {
+		if _, err := conn.WriteToUDP(packet, dest); err != nil {
+			return fmt.Errorf("send mDNS query: %w", err)
+		}
+	}
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read mDNS response: %w", err)
+		}
+		var msg dnsmessage.Message
+		if err := msg.Unpack(buf[:n]); err != nil {
+			continue
+		}
+		if device, ok := chromecastFrom(&msg, from.IP); ok {
+			found(device)
+		}
+	}
+}
+
+// chromecastFrom reads a Cast device out of an mDNS answer sent from ip.
+func chromecastFrom(msg *dnsmessage.Message, ip net.IP) (Device, bool) {
+	var (
+		instance string
+		port     = 8009
+		txt      = make(map[string]string)
+	)
+	for _, record := range append(msg.Answers, msg.Additionals...) {
+		switch body := record.Body.(type) {
+		case *dnsmessage.PTRResource:
+			if strings.EqualFold(record.Header.Name.String(), googlecastQuery) {
+				instance = body.PTR.String()
+			}
+		case *dnsmessage.SRVResource:
+			port = int(body.Port)
+		case *dnsmessage.TXTResource:
+			for _, entry := range body.TXT {
+				if key, value, ok := strings.Cut(entry, "="); ok {
+					txt[key] = value
+				}
+			}
+		case *dnsmessage.AResource:
+			ip = net.IP(body.A[:])
+		}
+	}
+	if instance == "" {
+		return Device{}, false
+	}
+
+	device := Device{
+		ID:   txt["id"],
+		Name: txt["fn"],
+		Kind: KindChromecast,
+		Addr: net.JoinHostPort(ip.String(), strconv.Itoa(port)),
+	}
+	if device.ID == "" {
+		device.ID = instance
+	}
+	if device.Name == "" {
+		device.Name = strings.TrimSuffix(instance, "."+googlecastQuery)
+	}
+	return device, true
+}
+
+// chromecast is a session with the default media receiver on a Cast device.
+type chromecast struct {
+	conn net.Conn
+
+	writeMu sync.Mutex
+
+	mu        sync.Mutex
+	requestID int
+	waiting   map[int]chan map[string]any
+	transport string
+	session   string
+	mediaID   int
+	status    Status
+	updated   time.Time
+	err       error
+
+	done chan struct{}
+}
+
+func dialChromecast(ctx context.Context, device Device) (Renderer, error) {
+	// Cast devices present certificates signed by Google's own CA, which no
+	// system trusts; the session is encrypted but not authenticated.
+	dialer := &tls.Dialer{Config: &tls.Config{InsecureSkipVerify: true}}
+	conn, err := dialer.DialContext(ctx, "tcp", device.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", device.Name, err)
+	}
+
+	c := &chromecast{conn: conn, waiting: make(map[int]chan map[string]any), done: make(chan struct{})}
+	go c.readLoop()
+	go c.heartbeat()
+
+	if err := c.send(castReceiver, namespaceConnection, map[string]any{"type": "CONNECT"}); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	if err := c.launch(ctx); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// launch starts the default media receiver and connects to it.
+func (c *chromecast) launch(ctx context.Context) error {
+	reply, err := c.request(ctx, castReceiver, namespaceReceiver, map[string]any{
+		"type":  "LAUNCH",
+		"appId": defaultMediaReceiver,
+	})
+	if err != nil {
+		return fmt.Errorf("launch media receiver: %w", err)
+	}
+
+	var status struct {
+		Status struct {
+			Applications []struct {
+				AppID       string `json:"appId"`
+				SessionID   string `json:"sessionId"`
+				TransportID string `json:"transportId"`
+			} `json:"applications"`
+		} `json:"status"`
+	}
+	if err := remarshal(reply, &status); err != nil {
+		return err
+	}
+	for _, app := range status.Status.Applications {
+		if app.AppID != defaultMediaReceiver {
+			continue
+		}
+		c.mu.Lock()
+		c.transport, c.session = app.TransportID, app.SessionID
+		c.mu.Unlock()
+		return c.send(app.TransportID, namespaceConnection, map[string]any{"type": "CONNECT"})
+	}
+	return fmt.Errorf("launch media receiver: %v", reply["type"])
+}
+
+func (c *chromecast) Load(ctx context.Context, media Media, start time.Duration) error {
+	metadata := map[string]any{
+		"metadataType": 3,
+		"title":        media.Title,
+		"artist":       media.Artist,
+		"albumName":    media.Album,
+	}
+	if media.Cover != "" {
+		metadata["images"] = []map[string]string{{"url": media.Cover}}
+	}
+	payload := map[string]any{
+		"type": "LOAD",
+		"media": map[string]any{
+			"contentId":   media.URL,
+			"contentType": media.ContentType,
+			"streamType":  "BUFFERED",
+			"duration":    media.Duration.Seconds(),
+			"metadata":    metadata,
+		},
+		"autoplay":    true,
+		"currentTime": start.Seconds(),
+	}
+	reply, err := c.request(ctx, c.transportID(), namespaceMedia, payload)
+	if err != nil {
+		return fmt.Errorf("load media: %w", err)
+	}
+	if kind, _ := reply["type"].(string); kind != "MEDIA_STATUS" {
+		return fmt.Errorf("load media: %s", kind)
+	}
+	return nil
+}
+
+func (c *chromecast) Play(ctx context.Context) error {
+	return c.control(ctx, "PLAY", nil)
+}
+
+func (c *chromecast) Pause(ctx context.Context) error {
+	return c.control(ctx, "PAUSE", nil)
+}
+
+func (c *chromecast) Stop(ctx context.Context) error {
+	return c.control(ctx, "STOP", nil)
+}
+
+func (c *chromecast) Seek(ctx context.Context, pos time.Duration) error {
+	return c.control(ctx, "SEEK", map[string]any{"currentTime": pos.Seconds()})
+}
+
+// Status returns the last status the device sent, with the position moved
+// on by the time since while playing.
+func (c *chromecast) Status(ctx context.Context) (Status, error) {
+	c.mu.Lock()
+	mediaID := c.mediaID
+	c.mu.Unlock()
+	if mediaID != 0 {
+		// Answers come back through readLoop like the device's own updates.
+		_ = c.send(c.transportID(), namespaceMedia, map[string]any{"type": "GET_STATUS", "requestId": c.nextRequestID()})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return Status{}, c.err
+	}
+	status := c.status
+	if status.State == StatePlaying {
+		status.Position += time.Since(c.updated)
+		if status.Duration > 0 {
+			status.Position = min(status.Position, status.Duration)
+		}
+	}
+	if status.State == StateFinished {
+		c.status.State = StateIdle
+	}
+	return status, nil
+}
+
+func (c *chromecast) Close() error {
+	select {
+	case <-c.done:
+		return nil
+	default:
+	}
+
+	c.mu.Lock()
+	session := c.session
+	c.mu.Unlock()
+	if session != "" {
+		_ = c.send(castReceiver, namespaceReceiver, map[string]any{"type": "STOP", "sessionId": session, "requestId": c.nextRequestID()})
+	}
+	_ = c.send(castReceiver, namespaceConnection, map[string]any{"type": "CLOSE"})
+	return c.conn.Close()
+}
+
+// control sends a media command for the loaded media.
+func (c *chromecast) control(ctx context.Context, command string, extra map[string]any) error {
+	c.mu.Lock()
+	mediaID := c.mediaID
+	c.mu.Unlock()
+	if mediaID == 0 {
+		return errors.New("nothing is loaded")
+	}
+
+	payload := map[string]any{"type": command, "mediaSessionId": mediaID}
+	for key, value := range extra {
+		payload[key] = value
+	}
+	if _, err := c.request(ctx, c.transportID(), namespaceMedia, payload); err != nil {
+		return fmt.Errorf("%s: %w", strings.ToLower(command), err)
+	}
+	return nil
+}
+
+func (c *chromecast) transportID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.transport
+}
+
+func (c *chromecast) nextRequestID() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestID++
+	return c.requestID
+}
+
+// request sends payload with a new request ID and waits for the reply
+// carrying it.
+func (c *chromecast) request(ctx context.Context, destination, namespace string, payload map[string]any) (map[string]any, error) {
+	id := c.nextRequestID()
+	payload["requestId"] = id
+
+	reply := make(chan map[string]any, 1)
+	c.mu.Lock()
+	c.waiting[id] = reply
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.waiting, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(destination, namespace, payload); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	select {
+	case message := <-reply:
+		return message, nil
+	case <-c.done:
+		return nil, errors.New("connection closed")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *chromecast) send(destination, namespace string, payload map[string]any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	frame := encodeCastMessage(castSender, destination, namespace, string(data))
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.conn.Write(frame); err != nil {
+		return fmt.Errorf("send to device: %w", err)
+	}
+	return nil
+}
+
+func (c *chromecast) heartbeat() {
+	ticker := time.NewTicker(castHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			_ = c.send(castReceiver, namespaceHeartbeat, map[string]any{"type": "PING"})
+		}
+	}
+}
+
+func (c *chromecast) readLoop() {
+	defer close(c.done)
+
+	reader := bufio.NewReader(c.conn)
+	for {
+		var size uint32
+		if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+			c.fail(err)
+			return
+		}
+		if size > castMaxMessage {
+			c.fail(fmt.Errorf("message of %d bytes is too large", size))
+			return
+		}
+		frame := make([]byte, size)
+		if _, err := io.ReadFull(reader, frame); err != nil {
+			c.fail(err)
+			return
+		}
+
+		source, namespace, payload, err := decodeCastMessage(frame)
+		if err != nil {
+			continue
+		}
+		var message map[string]any
+		if json.Unmarshal([]byte(payload), &message) != nil {
+			continue
+		}
+		c.handle(source, namespace, message)
+	}
+}
+
+func (c *chromecast) handle(source, namespace string, message map[string]any) {
+	kind, _ := message["type"].(string)
+	switch {
+	case namespace == namespaceHeartbeat && kind == "PING":
+		_ = c.send(source, namespaceHeartbeat, map[string]any{"type": "PONG"})
+		return
+	case namespace == namespaceConnection && kind == "CLOSE":
+		c.fail(errors.New("the device closed the session"))
+		_ = c.conn.Close()
+		return
+	case namespace == namespaceMedia && kind == "MEDIA_STATUS":
+		c.updateStatus(message)
+	}
+
+	id, _ := message["requestId"].(float64)
+	if id == 0 {
+		return
+	}
+	c.mu.Lock()
+	reply := c.waiting[int(id)]
+	c.mu.Unlock()
+	if reply != nil {
+		reply <- message
+	}
+}
+
+func (c *chromecast) updateStatus(message map[string]any) {
+	var status struct {
+		Status []struct {
+			MediaSessionID int     `json:"mediaSessionId"`
+			PlayerState    string  `json:"playerState"`
+			IdleReason     string  `json:"idleReason"`
+			CurrentTime    float64 `json:"currentTime"`
+			Media          *struct {
+				Duration float64 `json:"duration"`
+			} `json:"media"`
+		} `json:"status"`
+	}
+	if remarshal(message, &status) != nil || len(status.Status) == 0 {
+		return
+	}
+	media := status.Status[0]
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mediaID = media.MediaSessionID
+	c.updated = time.Now()
+	c.status.Position = time.Duration(media.CurrentTime * float64(time.Second))
+	if media.Media != nil && media.Media.Duration > 0 {
+		c.status.Duration = time.Duration(media.Media.Duration * float64(time.Second))
+	}
+	switch media.PlayerState {
+	case "PLAYING":
+		c.status.State = StatePlaying
+	case "PAUSED":
+		c.status.State = StatePaused
+	case "BUFFERING", "LOADING":
+		c.status.State = StateBuffering
+	default:
+		if media.IdleReason == "FINISHED" {
+			c.status.State = StateFinished
+		} else if c.status.State != StateFinished {
+			c.status.State = StateIdle
+		}
+	}
+}
+
+func (c *chromecast) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = fmt.Errorf("cast session ended: %w", err)
+	}
+}
+
+// remarshal decodes a message already parsed into a map into out.
+func remarshal(message map[string]any, out any) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// encodeCastMessage frames a CastMessage protobuf carrying a string
+// payload. The message is small enough to write out by hand:
+//
+//	1: protocol_version (0)  2: source_id  3: destination_id
+//	4: namespace  5: payload_type (0, string)  6: payload_utf8
+func encodeCastMessage(source, destination, namespace, payload string) []byte {
+	var msg []byte
+	msg = appendVarintField(msg, 1, 0)
+	msg = appendStringField(msg, 2, source)
+	msg = appendStringField(msg, 3, destination)
+	msg = appendStringField(msg, 4, namespace)
+	msg = appendVarintField(msg, 5, 0)
+	msg = appendStringField(msg, 6, payload)
+
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+func appendVarintField(b []byte, field int, value uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3)
+	return binary.AppendUvarint(b, value)
+}
+
+func appendStringField(b []byte, field int, value string) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// decodeCastMessage reads the source, namespace and string payload of a
+// CastMessage, skipping the fields it does not need.
+func decodeCastMessage(msg []byte) (source, namespace, payload string, err error) {
+	for len(msg) > 0 {
+		key, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return "", "", "", errors.New("bad field key")
+		}
+		msg = msg[n:]
+
+		switch key & 7 {
+		case 0:
+			_, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return "", "", "", errors.New("bad varint")
+			}
+			msg = msg[n:]
+		case 2:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || uint64(len(msg)-n) < size {
+				return "", "", "", errors.New("bad length")
+			}
+			value := string(msg[n : n+int(size)])
+			msg = msg[n+int(size):]
+			switch key >> 3 {
+			case 2:
+				source = value
+			case 4:
+				namespace = value
+			case 6:
+				payload = value
+			}
+		default:
+			return "", "", "", fmt.Errorf("unexpected wire type %d", key&7)
+		}
+	}
+	return source, namespace, payload, nil
+}
@@ -0,0 +1,381 @@
+package cast
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ssdpAddr        = "239.255.255.250:1900"
+	mediaRendererST = "urn:schemas-upnp-org:device:MediaRenderer:1"
+	avTransport     = "urn:schemas-upnp-org:service:AVTransport:1"
+	// dlnaTimeout limits each request to a renderer.
+	dlnaTimeout = 5 * time.Second
+)
+
+var dlnaClient = &http.Client{Timeout: dlnaTimeout}
+
+// discoverDLNA sends SSDP searches for media renderers and reports each
+// one that answers, until ctx is done.
+func discoverDLNA(ctx context.Context, found func(Device)) error {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return fmt.Errorf("open SSDP socket: %w", err)
+	}
+	defer conn.Close()
+
+	dest, err := net.ResolveUDPAddr("udp4", ssdpAddr)
+	if err != nil {
+		return err
+	}
+	search := []byte("M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddr + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n" +
+		"ST: " + mediaRendererST + "\r\n\r\n")
+	// UDP may drop a search, so it goes out twice.
+	for range 2 {
+		if _, err := conn.WriteToUDP(search, dest); err != nil {
+			return fmt.Errorf("send SSDP search: %w", err)
+		}
+	}
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetReadDeadline(time.Now()) })
+	defer stop()
+
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read SSDP response: %w", err)
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if err != nil {
+			continue
+		}
+		location := resp.Header.Get("Location")
+		if location == "" || seen[location] {
+			continue
+		}
+		seen[location] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			desc, err := fetchDescription(ctx, location)
+			if err != nil || desc.controlURL == "" {
+				return
+			}
+			found(Device{ID: desc.udn, Name: desc.name, Kind: KindDLNA, Addr: location})
+		}()
+	}
+}
+
+type deviceDescription struct {
+	name       string
+	udn        string
+	controlURL string
+}
+
+// fetchDescription reads the device description at location and finds the
+// control URL of its AVTransport service.
+func fetchDescription(ctx context.Context, location string) (*deviceDescription, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := dlnaClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get device description: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get device description: HTTP %d", resp.StatusCode)
+	}
+
+	type service struct {
+		ServiceType string `xml:"serviceType"`
+		ControlURL  string `xml:"controlURL"`
+	}
+	type device struct {
+		FriendlyName string    `xml:"friendlyName"`
+		UDN          string    `xml:"UDN"`
+		Services     []service `xml:"serviceList>service"`
+		Devices      []device  `xml:"deviceList>device"`
+	}
+	var root struct {
+		URLBase string `xml:"URLBase"`
+		Device  device `xml:"device"`
+	}
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&root); err != nil {
+		return nil, fmt.Errorf("decode device description: %w", err)
+	}
+
+	base, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if root.URLBase != "" {
+		if parsed, err := url.Parse(root.URLBase); err == nil {
+			base = parsed
+		}
+	}
+
+	// The AVTransport service may sit on an embedded device.
+	var find func(d device) *deviceDescription
+	find = func(d device) *deviceDescription {
+		for _, s := range d.Services {
+			if strings.HasPrefix(s.ServiceType, "urn:schemas-upnp-org:service:AVTransport:") {
+				control, err := base.Parse(s.ControlURL)
+				if err != nil {
+					return nil
+				}
+				return &deviceDescription{name: d.FriendlyName, udn: d.UDN, controlURL: control.String()}
+			}
+		}
+		for _, child := range d.Devices {
+			if desc := find(child); desc != nil {
+				return desc
+			}
+		}
+		return nil
+	}
+	desc := find(root.Device)
+	if desc == nil {
+		return &deviceDescription{name: root.Device.FriendlyName, udn: root.Device.UDN}, nil
+	}
+	desc.name = root.Device.FriendlyName
+	desc.udn = root.Device.UDN
+	if desc.udn == "" {
+		desc.udn = location
+	}
+	return desc, nil
+}
+
+// dlnaRenderer drives a renderer through its AVTransport service.
+type dlnaRenderer struct {
+	controlURL string
+
+	mu sync.Mutex
+	// started is set once the renderer played what was loaded, so that a
+	// stop after it reads as the end of the media.
+	started bool
+}
+
+func dialDLNA(ctx context.Context, device Device) (Renderer, error) {
+	desc, err := fetchDescription(ctx, device.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if desc.controlURL == "" {
+		return nil, fmt.Errorf("%s has no AVTransport service", device.Name)
+	}
+	return &dlnaRenderer{controlURL: desc.controlURL}, nil
+}
+
+func (r *dlnaRenderer) Load(ctx context.Context, media Media, start time.Duration) error {
+	r.mu.Lock()
+	r.started = false
+	r.mu.Unlock()
+
+	if _, err := r.call(ctx, "SetAVTransportURI",
+		"CurrentURI", media.URL,
+		"CurrentURIMetaData", didl(media),
+	); err != nil {
+		return err
+	}
+	if err := r.Play(ctx); err != nil {
+		return err
+	}
+	if start > 0 {
+		// Many renderers refuse to seek before they started playing.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+		return r.Seek(ctx, start)
+	}
+	return nil
+}
+
+func (r *dlnaRenderer) Play(ctx context.Context) error {
+	_, err := r.call(ctx, "Play", "Speed", "1")
+	return err
+}
+
+func (r *dlnaRenderer) Pause(ctx context.Context) error {
+	_, err := r.call(ctx, "Pause")
+	return err
+}
+
+func (r *dlnaRenderer) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	r.started = false
+	r.mu.Unlock()
+	_, err := r.call(ctx, "Stop")
+	return err
+}
+
+func (r *dlnaRenderer) Seek(ctx context.Context, pos time.Duration) error {
+	_, err := r.call(ctx, "Seek", "Unit", "REL_TIME", "Target", formatClock(pos))
+	return err
+}
+
+func (r *dlnaRenderer) Status(ctx context.Context) (Status, error) {
+	body, err := r.call(ctx, "GetTransportInfo")
+	if err != nil {
+		return Status{}, err
+	}
+	var transport struct {
+		State string `xml:"Body>GetTransportInfoResponse>CurrentTransportState"`
+	}
+	if err := xml.Unmarshal(body, &transport); err != nil {
+		return Status{}, fmt.Errorf("decode transport info: %w", err)
+	}
+
+	body, err = r.call(ctx, "GetPositionInfo")
+	if err != nil {
+		return Status{}, err
+	}
+	var position struct {
+		RelTime  string `xml:"Body>GetPositionInfoResponse>RelTime"`
+		Duration string `xml:"Body>GetPositionInfoResponse>TrackDuration"`
+	}
+	if err := xml.Unmarshal(body, &position); err != nil {
+		return Status{}, fmt.Errorf("decode position info: %w", err)
+	}
+
+	status := Status{Position: parseClock(position.RelTime), Duration: parseClock(position.Duration)}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch transport.State {
+	case "PLAYING":
+		r.started = true
+		status.State = StatePlaying
+	case "PAUSED_PLAYBACK", "PAUSED_RECORDING":
+		status.State = StatePaused
+	case "TRANSITIONING":
+		status.State = StateBuffering
+	default:
+		status.State = StateIdle
+		if r.started {
+			r.started = false
+			status.State = StateFinished
+		}
+	}
+	return status, nil
+}
+
+func (r *dlnaRenderer) Close() error {
+	return nil
+}
+
+// call invokes an AVTransport action with args, given as name and value
+// pairs, and returns the SOAP response.
+func (r *dlnaRenderer) call(ctx context.Context, action string, args ...string) ([]byte, error) {
+	var body strings.Builder
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	body.WriteString(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	fmt.Fprintf(&body, `<u:%s xmlns:u="%s"><InstanceID>0</InstanceID>`, action, avTransport)
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&body, "<%s>", args[i])
+		_ = xml.EscapeText(&body, []byte(args[i+1]))
+		fmt.Fprintf(&body, "</%s>", args[i])
+	}
+	fmt.Fprintf(&body, `</u:%s></s:Body></s:Envelope>`, action)
+
+	ctx, cancel := context.WithTimeout(ctx, dlnaTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.controlURL, strings.NewReader(body.String()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", fmt.Sprintf(`"%s#%s"`, avTransport, action))
+
+	resp, err := dlnaClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", action, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", action, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: HTTP %d", action, resp.StatusCode)
+	}
+	return data, nil
+}
+
+// didl describes media in DIDL-Lite, which renderers show while playing.
+func didl(media Media) string {
+	var b strings.Builder
+	element := func(name, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&b, "<%s>", name)
+		_ = xml.EscapeText(&b, []byte(value))
+		fmt.Fprintf(&b, "</%s>", name)
+	}
+
+	b.WriteString(`<DIDL-Lite xmlns="urn:schemas-upnp-org:metadata-1-0/DIDL-Lite/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:upnp="urn:schemas-upnp-org:metadata-1-0/upnp/">`)
+	b.WriteString(`<item id="0" parentID="-1" restricted="1">`)
+	element("dc:title", media.Title)
+	element("upnp:artist", media.Artist)
+	element("upnp:album", media.Album)
+	element("upnp:albumArtURI", media.Cover)
+	b.WriteString("<upnp:class>object.item.audioItem.musicTrack</upnp:class>")
+	fmt.Fprintf(&b, `<res protocolInfo="http-get:*:%s:*"`, media.ContentType)
+	if media.Duration > 0 {
+		fmt.Fprintf(&b, ` duration="%s.000"`, formatClock(media.Duration))
+	}
+	b.WriteString(">")
+	_ = xml.EscapeText(&b, []byte(media.URL))
+	b.WriteString("</res></item></DIDL-Lite>")
+	return b.String()
+}
+
+// formatClock renders d as H:MM:SS.
+func formatClock(d time.Duration) string {
+	seconds := int(d.Seconds())
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// parseClock reads H:MM:SS with optional fractions, as renderers report
+// times. Unknown times, like NOT_IMPLEMENTED, read as zero.
+func parseClock(value string) time.Duration {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	var total float64
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0
+		}
+		total = total*60 + n
+	}
+	return time.Duration(total * float64(time.Second))
+}
@@ -0,0 +1,152 @@
+package cast
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serverFiles is how many files the server lends at once; the oldest is
+// forgotten first.
+const serverFiles = 8
+
+// Server lends local files to renderers over HTTP. Each file gets a URL
+// with a random name, and nothing else on the machine can be fetched.
+type Server struct {
+	port int
+
+	mu       sync.Mutex
+	listener net.Listener
+	files    map[string]string
+	order    []string
+}
+
+// NewServer returns a server that listens on port once a file is lent, or
+// on any free port when port is 0.
+func NewServer(port int) *Server {
+	return &Server{port: port, files: make(map[string]string)}
+}
+
+// URL lends the file at path and returns where the renderer at host can
+// fetch it.
+func (s *Server) URL(path, host string) (string, error) {
+	ip, err := localIPFor(host)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.listen(); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	name := hex.EncodeToString(buf) + strings.ToLower(filepath.Ext(path))
+	s.files[name] = path
+	s.order = append(s.order, name)
+	if len(s.order) > serverFiles {
+		delete(s.files, s.order[0])
+		s.order = s.order[1:]
+	}
+
+	port := s.listener.Addr().(*net.TCPAddr).Port
+	return "http://" + net.JoinHostPort(ip.String(), strconv.Itoa(port)) + "/" + name, nil
+}
+
+// Close stops the server and forgets every file lent.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files = make(map[string]string)
+	s.order = nil
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.listener = nil
+	return err
+}
+
+func (s *Server) listen() error {
+	if s.listener != nil {
+		return nil
+	}
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(s.port))
+	if err != nil {
+		return fmt.Errorf("start cast server: %w", err)
+	}
+	s.listener = listener
+
+	server := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, net.ErrClosed) {
+			log.Printf("[CAST] Server stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	s.mu.Lock()
+	path, ok := s.files[name]
+	s.mu.Unlock()
+	if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType(path))
+	// DLNA renderers want to hear that the file can be seeked.
+	w.Header().Set("transferMode.dlna.org", "Streaming")
+	w.Header().Set("contentFeatures.dlna.org", "DLNA.ORG_OP=01;DLNA.ORG_FLAGS=01700000000000000000000000000000")
+	http.ServeFile(w, r, path)
+}
+
+// ContentType returns the MIME type of the audio file at path, going by its
+// extension.
+func ContentType(path string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".mp3", "":
+		return "audio/mpeg"
+	case ".flac":
+		return "audio/flac"
+	case ".ogg", ".oga":
+		return "audio/ogg"
+	case ".m4a", ".mp4":
+		return "audio/mp4"
+	case ".wav":
+		return "audio/wav"
+	default:
+		if kind := mime.TypeByExtension(ext); kind != "" {
+			return kind
+		}
+		return "application/octet-stream"
+	}
+}
+
+// localIPFor returns the address of this machine on the network that
+// reaches host.
+func localIPFor(host string) (net.IP, error) {
+	// Dialing UDP sends nothing; it only picks the route.
+	conn, err := net.Dial("udp", net.JoinHostPort(host, "9"))
+	if err != nil {
+		return nil, fmt.Errorf("find route to %s: %w", host, err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
@@ -0,0 +1,41 @@
+// Package hotkeys registers system-wide keyboard shortcuts, so AMP can be
+// controlled while another window has focus. On Linux they are bound
+// through the desktop portal's GlobalShortcuts interface, which lets the
+// desktop confirm them or pick other keys. Elsewhere the service does
+// nothing.
+package hotkeys
+
+// Action is what a shortcut does. Its value is the shortcut's id.
+type Action string
+
+const (
+	PlayPause  Action = "play-pause"
+	Next       Action = "next"
+	Previous   Action = "previous"
+	VolumeUp   Action = "volume-up"
+	VolumeDown Action = "volume-down"
+)
+
+// Description is how the desktop lists the action's shortcut.
+func (a Action) Description() string {
+	switch a {
+	case PlayPause:
+		return "Play or pause"
+	case Next:
+		return "Next track"
+	case Previous:
+		return "Previous track"
+	case VolumeUp:
+		return "Volume up"
+	case VolumeDown:
+		return "Volume down"
+	}
+	return string(a)
+}
+
+// Binding asks for an action on a key combination, written as in the XDG
+// shortcuts specification, like "CTRL+ALT+space" or "XF86AudioPlay".
+type Binding struct {
+	Action  Action
+	Trigger string
+}
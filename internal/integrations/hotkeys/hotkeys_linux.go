@@ -0,0 +1,229 @@
+//go:build linux
+
+package hotkeys
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	portalBus      = "org.freedesktop.portal.Desktop"
+	portalPath     = dbus.ObjectPath("/org/freedesktop/portal/desktop")
+	shortcutsIface = "org.freedesktop.portal.GlobalShortcuts"
+	requestIface   = "org.freedesktop.portal.Request"
+	sessionIface   = "org.freedesktop.portal.Session"
+
+	// responseTimeout bounds the wait for the portal, which may be showing
+	// the user a dialog to confirm the shortcuts.
+	responseTimeout = 2 * time.Minute
+)
+
+// tokenCounter makes the handle tokens of the portal requests unique.
+var tokenCounter atomic.Uint64
+
+type Service struct {
+	onActivated func(Action)
+	debug       bool
+
+	mu      sync.Mutex
+	conn    *dbus.Conn
+	session dbus.ObjectPath
+}
+
+// shortcut is one entry of BindShortcuts, a(sa{sv}) on the bus.
+type shortcut struct {
+	ID      string
+	Options map[string]dbus.Variant
+}
+
+// NewService returns a service that calls onActivated, on the bus
+// goroutine, whenever one of its shortcuts is pressed.
+func NewService(onActivated func(Action), debug bool) *Service {
+	return &Service{onActivated: onActivated, debug: debug}
+}
+
+// Running reports whether the shortcuts are bound, or being bound.
+func (s *Service) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn != nil
+}
+
+// Start opens a portal session and binds the shortcuts to it. It blocks
+// until the desktop accepted them, which may take a dialog; Stop gives up
+// on a Start still waiting. Starting a running service does nothing.
+func (s *Service) Start(bindings []Binding) error {
+	s.mu.Lock()
+	if s.conn != nil {
+		s.mu.Unlock()
+		return nil
+	}
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("connect to session bus: %w", err)
+	}
+	s.conn = conn
+	s.mu.Unlock()
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	session, err := bind(conn, signals, bindings)
+	if err != nil {
+		s.mu.Lock()
+		if s.conn == conn {
+			s.conn = nil
+		}
+		s.mu.Unlock()
+		conn.Close()
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != conn {
+		// Stopped while the desktop was asking.
+		closeSession(conn, session)
+		conn.Close()
+		return nil
+	}
+	s.session = session
+	go s.listen(signals, session)
+	if s.debug {
+		log.Printf("[HOTKEYS] Bound %d shortcuts", len(bindings))
+	}
+	return nil
+}
+
+// Stop closes the session, which releases the shortcuts.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	conn, session := s.conn, s.session
+	s.conn, s.session = nil, ""
+	s.mu.Unlock()
+
+	if conn == nil {
+		return
+	}
+	if session != "" {
+		closeSession(conn, session)
+	}
+	conn.Close()
+}
+
+// bind creates a shortcut session on conn and binds the shortcuts to it.
+func bind(conn *dbus.Conn, signals <-chan *dbus.Signal, bindings []Binding) (dbus.ObjectPath, error) {
+	if err := conn.AddMatchSignal(dbus.WithMatchInterface(requestIface), dbus.WithMatchMember("Response")); err != nil {
+		return "", fmt.Errorf("watch portal responses: %w", err)
+	}
+	if err := conn.AddMatchSignal(dbus.WithMatchInterface(shortcutsIface), dbus.WithMatchMember("Activated")); err != nil {
+		return "", fmt.Errorf("watch shortcuts: %w", err)
+	}
+
+	results, err := request(conn, signals, "CreateSession", func(token string) []interface{} {
+		return []interface{}{map[string]dbus.Variant{
+			"handle_token":         dbus.MakeVariant(token),
+			"session_handle_token": dbus.MakeVariant(token),
+		}}
+	})
+	if err != nil {
+		return "", fmt.Errorf("create shortcut session: %w", err)
+	}
+	var session dbus.ObjectPath
+	switch handle := results["session_handle"].Value().(type) {
+	case string:
+		session = dbus.ObjectPath(handle)
+	case dbus.ObjectPath:
+		session = handle
+	default:
+		return "", errors.New("create shortcut session: no session handle in response")
+	}
+
+	shortcuts := make([]shortcut, 0, len(bindings))
+	for _, binding := range bindings {
+		options := map[string]dbus.Variant{"description": dbus.MakeVariant(binding.Action.Description())}
+		if binding.Trigger != "" {
+			options["preferred_trigger"] = dbus.MakeVariant(binding.Trigger)
+		}
+		shortcuts = append(shortcuts, shortcut{ID: string(binding.Action), Options: options})
+	}
+	if _, err := request(conn, signals, "BindShortcuts", func(token string) []interface{} {
+		return []interface{}{session, shortcuts, "", map[string]dbus.Variant{
+			"handle_token": dbus.MakeVariant(token),
+		}}
+	}); err != nil {
+		closeSession(conn, session)
+		return "", fmt.Errorf("bind shortcuts: %w", err)
+	}
+	return session, nil
+}
+
+// listen calls onActivated for the shortcuts of session until the
+// connection closes.
+func (s *Service) listen(signals <-chan *dbus.Signal, session dbus.ObjectPath) {
+	for signal := range signals {
+		if signal.Name != shortcutsIface+".Activated" || len(signal.Body) < 2 {
+			continue
+		}
+		if path, ok := signal.Body[0].(dbus.ObjectPath); !ok || path != session {
+			continue
+		}
+		id, _ := signal.Body[1].(string)
+		if s.debug {
+			log.Printf("[HOTKEYS] Activated: %s", id)
+		}
+		if s.onActivated != nil {
+			s.onActivated(Action(id))
+		}
+	}
+}
+
+// request calls a GlobalShortcuts method and waits for the response the
+// portal sends to the method's request object. args builds the arguments
+// around the handle token the request is named after.
+func request(conn *dbus.Conn, signals <-chan *dbus.Signal, method string, args func(token string) []interface{}) (map[string]dbus.Variant, error) {
+	token := fmt.Sprintf("amp%d", tokenCounter.Add(1))
+	names := conn.Names()
+	if len(names) == 0 {
+		return nil, errors.New("not connected")
+	}
+	sender := strings.ReplaceAll(strings.TrimPrefix(names[0], ":"), ".", "_")
+	handle := dbus.ObjectPath(fmt.Sprintf("%s/request/%s/%s", portalPath, sender, token))
+
+	portal := conn.Object(portalBus, portalPath)
+	if call := portal.Call(shortcutsIface+"."+method, 0, args(token)...); call.Err != nil {
+		return nil, call.Err
+	}
+
+	timeout := time.After(responseTimeout)
+	for {
+		select {
+		case signal, ok := <-signals:
+			if !ok {
+				return nil, errors.New("connection closed")
+			}
+			if signal.Path != handle || signal.Name != requestIface+".Response" || len(signal.Body) < 2 {
+				continue
+			}
+			if code, _ := signal.Body[0].(uint32); code != 0 {
+				return nil, fmt.Errorf("declined (response %d)", code)
+			}
+			results, _ := signal.Body[1].(map[string]dbus.Variant)
+			return results, nil
+		case <-timeout:
+			return nil, errors.New("no response from the desktop portal")
+		}
+	}
+}
+
+func closeSession(conn *dbus.Conn, session dbus.ObjectPath) {
+	conn.Object(portalBus, session).Call(sessionIface+".Close", 0)
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+
+package hotkeys
+
+import "errors"
+
+var errUnsupported = errors.New("global hotkeys are only available on Linux")
+
+// Service has nothing to register with on these platforms; Start reports
+// that and the rest do nothing.
+type Service struct{}
+
+func NewService(onActivated func(Action), debug bool) *Service {
+	return &Service{}
+}
+
+func (s *Service) Running() bool { return false }
+
+func (s *Service) Start(bindings []Binding) error { return errUnsupported }
+
+func (s *Service) Stop() {}
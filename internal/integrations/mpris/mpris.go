@@ -0,0 +1,52 @@
+// Package mpris publishes the player on the session bus under the MPRIS
+// specification (org.mpris.MediaPlayer2), so desktop media keys, lock-screen
+// widgets and tools like playerctl can see and control what AMP plays.
+// Outside Linux the service does nothing.
+package mpris
+
+import (
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	busName    = "org.mpris.MediaPlayer2.amp"
+	objectPath = "/org/mpris/MediaPlayer2"
+	identity   = "AMP"
+	// desktopEntry is the basename of the .desktop file fyne installs.
+	desktopEntry = "ru.akarpov.amp"
+)
+
+// Controls is the player as MPRIS clients drive it. Calls arrive on the bus
+// goroutine; implementations move them to the UI as needed.
+type Controls interface {
+	Play()
+	Pause()
+	PlayPause()
+	Stop()
+	Next()
+	Previous()
+	// SeekTo jumps to position in the current track and reports where
+	// playback actually ended up.
+	SeekTo(position time.Duration) (time.Duration, error)
+	Position() time.Duration
+	Raise()
+}
+
+// Status is the MPRIS playback status.
+type Status string
+
+const (
+	StatusPlaying Status = "Playing"
+	StatusPaused  Status = "Paused"
+	StatusStopped Status = "Stopped"
+)
+
+// Track is what the service announces about the current song.
+type Track struct {
+	Song *types.Song
+	// ArtURL is where other programs can load the cover from, a file:// or
+	// http(s) URL; empty when the song has none.
+	ArtURL string
+}
@@ -0,0 +1,395 @@
+//go:build linux
+
+package mpris
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	rootIface   = "org.mpris.MediaPlayer2"
+	playerIface = "org.mpris.MediaPlayer2.Player"
+	noTrack     = dbus.ObjectPath("/org/mpris/MediaPlayer2/TrackList/NoTrack")
+)
+
+type Service struct {
+	controls Controls
+	debug    bool
+
+	mu      sync.Mutex
+	conn    *dbus.Conn
+	props   *prop.Properties
+	trackID dbus.ObjectPath
+	length  time.Duration
+}
+
+func NewService(controls Controls, debug bool) *Service {
+	return &Service{controls: controls, debug: debug, trackID: noTrack}
+}
+
+// Running reports whether the player is published on the bus.
+func (s *Service) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn != nil
+}
+
+// Start connects to the session bus and publishes the player. Starting a
+// running service does nothing.
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		return nil
+	}
+
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return fmt.Errorf("connect to session bus: %w", err)
+	}
+	reply, err := conn.RequestName(busName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("request bus name: %w", err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		conn.Close()
+		return fmt.Errorf("bus name %s is already taken", busName)
+	}
+
+	root := &rootObject{s}
+	player := &playerObject{s}
+	if err := conn.Export(root, objectPath, rootIface); err != nil {
+		conn.Close()
+		return fmt.Errorf("export %s: %w", rootIface, err)
+	}
+	if err := conn.ExportWithMap(player, playerMethods, objectPath, playerIface); err != nil {
+		conn.Close()
+		return fmt.Errorf("export %s: %w", playerIface, err)
+	}
+
+	props, err := prop.Export(conn, objectPath, s.propertyMap())
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("export properties: %w", err)
+	}
+	// Position is read from the player on every request rather than kept
+	// up to date; clients poll it and only expect signals on seeks.
+	if err := conn.Export(&liveProperties{props, s}, objectPath, "org.freedesktop.DBus.Properties"); err != nil {
+		conn.Close()
+		return fmt.Errorf("export properties: %w", err)
+	}
+
+	node := &introspect.Node{
+		Name: objectPath,
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:       rootIface,
+				Methods:    introspect.Methods(root),
+				Properties: props.Introspection(rootIface),
+			},
+			{
+				Name:       playerIface,
+				Methods:    playerIntrospection(player),
+				Properties: props.Introspection(playerIface),
+				Signals: []introspect.Signal{{
+					Name: "Seeked",
+					Args: []introspect.Arg{{Name: "Position", Type: "x"}},
+				}},
+			},
+		},
+	}
+	if err := conn.Export(introspect.NewIntrospectable(node), objectPath, "org.freedesktop.DBus.Introspectable"); err != nil {
+		conn.Close()
+		return fmt.Errorf("export introspection: %w", err)
+	}
+
+	s.conn, s.props = conn, props
+	if s.debug {
+		log.Printf("[MPRIS] Published as %s", busName)
+	}
+	return nil
+}
+
+// Stop takes the player off the bus.
+func (s *Service) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return
+	}
+	if _, err := s.conn.ReleaseName(busName); err != nil && s.debug {
+		log.Printf("[MPRIS] Failed to release %s: %v", busName, err)
+	}
+	s.conn.Close()
+	s.conn, s.props = nil, nil
+}
+
+func (s *Service) propertyMap() prop.Map {
+	constant := func(v interface{}) *prop.Prop {
+		return &prop.Prop{Value: v, Emit: prop.EmitConst}
+	}
+	changing := func(v interface{}) *prop.Prop {
+		return &prop.Prop{Value: v, Emit: prop.EmitTrue}
+	}
+	return prop.Map{
+		rootIface: {
+			"CanQuit":             constant(false),
+			"CanRaise":            constant(true),
+			"HasTrackList":        constant(false),
+			"Identity":            constant(identity),
+			"DesktopEntry":        constant(desktopEntry),
+			"SupportedUriSchemes": constant([]string{}),
+			"SupportedMimeTypes":  constant([]string{}),
+		},
+		playerIface: {
+			"PlaybackStatus": changing(string(StatusStopped)),
+			"Metadata":       changing(metadata(Track{})),
+			"Rate":           constant(1.0),
+			"MinimumRate":    constant(1.0),
+			"MaximumRate":    constant(1.0),
+			"Volume":         &prop.Prop{Value: 1.0, Emit: prop.EmitFalse},
+			"Position":       &prop.Prop{Value: int64(0), Emit: prop.EmitFalse},
+			"CanGoNext":      changing(false),
+			"CanGoPrevious":  changing(false),
+			"CanPlay":        changing(false),
+			"CanPause":       changing(false),
+			"CanSeek":        changing(false),
+			"CanControl":     constant(true),
+		},
+	}
+}
+
+// SetTrack announces the current song; a nil song clears it.
+func (s *Service) SetTrack(track Track) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.trackID, s.length = noTrack, 0
+	if track.Song != nil {
+		s.trackID = trackPath(track.Song.Slug)
+		s.length = time.Duration(track.Song.Length) * time.Second
+	}
+	if s.props == nil {
+		return
+	}
+
+	hasTrack := track.Song != nil
+	s.props.SetMust(playerIface, "Metadata", metadata(track))
+	s.props.SetMust(playerIface, "CanPlay", hasTrack)
+	s.props.SetMust(playerIface, "CanPause", hasTrack)
+	s.props.SetMust(playerIface, "CanSeek", hasTrack && s.length > 0)
+	s.props.SetMust(playerIface, "CanGoNext", hasTrack)
+	s.props.SetMust(playerIface, "CanGoPrevious", hasTrack)
+}
+
+// SetStatus announces whether the player is playing, paused or stopped.
+func (s *Service) SetStatus(status Status) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.props != nil {
+		s.props.SetMust(playerIface, "PlaybackStatus", string(status))
+	}
+}
+
+// Seeked tells clients the position jumped, so they resync their progress.
+func (s *Service) Seeked(position time.Duration) {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	if err := conn.Emit(objectPath, playerIface+".Seeked", position.Microseconds()); err != nil && s.debug {
+		log.Printf("[MPRIS] Failed to emit Seeked: %v", err)
+	}
+}
+
+func metadata(track Track) map[string]dbus.Variant {
+	song := track.Song
+	if song == nil {
+		return map[string]dbus.Variant{"mpris:trackid": dbus.MakeVariant(noTrack)}
+	}
+
+	meta := map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(trackPath(song.Slug)),
+		"xesam:title":   dbus.MakeVariant(song.Name),
+	}
+	if song.Length > 0 {
+		meta["mpris:length"] = dbus.MakeVariant((time.Duration(song.Length) * time.Second).Microseconds())
+	}
+	var artists []string
+	for _, author := range song.Authors {
+		if author != nil && author.Name != "" {
+			artists = append(artists, author.Name)
+		}
+	}
+	if len(artists) > 0 {
+		meta["xesam:artist"] = dbus.MakeVariant(artists)
+	}
+	if song.Album != nil && song.Album.Name != "" {
+		meta["xesam:album"] = dbus.MakeVariant(song.Album.Name)
+	}
+	if albumArtist := song.AlbumArtist(); albumArtist != "" {
+		meta["xesam:albumArtist"] = dbus.MakeVariant([]string{albumArtist})
+	}
+	if song.Link != "" {
+		meta["xesam:url"] = dbus.MakeVariant(song.Link)
+	}
+	if track.ArtURL != "" {
+		meta["mpris:artUrl"] = dbus.MakeVariant(track.ArtURL)
+	}
+	return meta
+}
+
+// trackPath turns a slug into a track object path, which only allows
+// letters, digits and underscores.
+func trackPath(slug string) dbus.ObjectPath {
+	var b strings.Builder
+	for _, r := range slug {
+		if r < 128 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	if b.Len() == 0 {
+		return noTrack
+	}
+	return dbus.ObjectPath("/ru/akarpov/amp/track/" + b.String())
+}
+
+// liveProperties serves the player position fresh from the player.
+type liveProperties struct {
+	*prop.Properties
+	s *Service
+}
+
+func (p *liveProperties) Get(iface, property string) (dbus.Variant, *dbus.Error) {
+	if iface == playerIface && property == "Position" {
+		return dbus.MakeVariant(p.s.controls.Position().Microseconds()), nil
+	}
+	return p.Properties.Get(iface, property)
+}
+
+func (p *liveProperties) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	all, err := p.Properties.GetAll(iface)
+	if err == nil && iface == playerIface {
+		all["Position"] = dbus.MakeVariant(p.s.controls.Position().Microseconds())
+	}
+	return all, err
+}
+
+type rootObject struct {
+	s *Service
+}
+
+func (r *rootObject) Raise() *dbus.Error {
+	r.s.controls.Raise()
+	return nil
+}
+
+func (r *rootObject) Quit() *dbus.Error {
+	return dbus.MakeFailedError(fmt.Errorf("quitting is not supported"))
+}
+
+type playerObject struct {
+	s *Service
+}
+
+// playerMethods maps Go method names to their bus names where they differ.
+var playerMethods = map[string]string{"SeekBy": "Seek"}
+
+func playerIntrospection(player *playerObject) []introspect.Method {
+	methods := introspect.Methods(player)
+	for i := range methods {
+		if name, ok := playerMethods[methods[i].Name]; ok {
+			methods[i].Name = name
+		}
+	}
+	return methods
+}
+
+func (p *playerObject) Next() *dbus.Error {
+	p.s.controls.Next()
+	return nil
+}
+
+func (p *playerObject) Previous() *dbus.Error {
+	p.s.controls.Previous()
+	return nil
+}
+
+func (p *playerObject) Pause() *dbus.Error {
+	p.s.controls.Pause()
+	return nil
+}
+
+func (p *playerObject) PlayPause() *dbus.Error {
+	p.s.controls.PlayPause()
+	return nil
+}
+
+func (p *playerObject) Stop() *dbus.Error {
+	p.s.controls.Stop()
+	return nil
+}
+
+func (p *playerObject) Play() *dbus.Error {
+	p.s.controls.Play()
+	return nil
+}
+
+// SeekBy is the MPRIS Seek method, renamed in Go so it isn't mistaken for
+// io.Seeker. It moves by offset microseconds; negative values go back.
+func (p *playerObject) SeekBy(offset int64) *dbus.Error {
+	target := p.s.controls.Position() + time.Duration(offset)*time.Microsecond
+	if target < 0 {
+		target = 0
+	}
+	p.s.mu.Lock()
+	length := p.s.length
+	p.s.mu.Unlock()
+	if length > 0 && target > length {
+		// Past the end means the next track, as the spec asks.
+		p.s.controls.Next()
+		return nil
+	}
+	return p.seekTo(target)
+}
+
+// SetPosition jumps to position microseconds if trackID is still current.
+func (p *playerObject) SetPosition(trackID dbus.ObjectPath, position int64) *dbus.Error {
+	p.s.mu.Lock()
+	current, length := p.s.trackID, p.s.length
+	p.s.mu.Unlock()
+
+	target := time.Duration(position) * time.Microsecond
+	if trackID != current || target < 0 || (length > 0 && target > length) {
+		return nil
+	}
+	return p.seekTo(target)
+}
+
+func (p *playerObject) seekTo(target time.Duration) *dbus.Error {
+	position, err := p.s.controls.SeekTo(target)
+	if err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	p.s.Seeked(position)
+	return nil
+}
+
+func (p *playerObject) OpenUri(uri string) *dbus.Error {
+	return dbus.MakeFailedError(fmt.Errorf("opening URIs is not supported"))
+}
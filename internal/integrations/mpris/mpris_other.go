@@ -0,0 +1,30 @@
+//go:build !linux
+
+package mpris
+
+import (
+	"errors"
+	"time"
+)
+
+var errUnsupported = errors.New("MPRIS is only available on Linux")
+
+// Service has nothing to publish to on these platforms; Start reports that
+// and the rest do nothing.
+type Service struct{}
+
+func NewService(controls Controls, debug bool) *Service {
+	return &Service{}
+}
+
+func (s *Service) Running() bool { return false }
+
+func (s *Service) Start() error { return errUnsupported }
+
+func (s *Service) Stop() {}
+
+func (s *Service) SetTrack(track Track) {}
+
+func (s *Service) SetStatus(status Status) {}
+
+func (s *Service) Seeked(position time.Duration) {}
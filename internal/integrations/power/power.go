@@ -0,0 +1,5 @@
+// Package power tells whether the machine runs on battery and whether its
+// network connection is metered, so background work that costs charge or
+// data can wait for a better time. On Linux the battery comes from sysfs and
+// the connection from NetworkManager over D-Bus.
+package power
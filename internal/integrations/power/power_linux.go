@@ -0,0 +1,88 @@
+//go:build linux
+
+package power
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	powerSupplies = "/sys/class/power_supply"
+
+	networkManagerBus   = "org.freedesktop.NetworkManager"
+	networkManagerPath  = "/org/freedesktop/NetworkManager"
+	networkManagerIface = "org.freedesktop.NetworkManager"
+
+	// NMMetered values that mean the connection is, or is guessed to be,
+	// metered.
+	meteredYes      = 1
+	meteredGuessYes = 3
+)
+
+// meteredTypes are connection types charged by the byte even when
+// NetworkManager has no guess.
+var meteredTypes = map[string]bool{"gsm": true, "cdma": true, "bluetooth": true}
+
+// OnBattery reports whether a system battery is discharging. Machines
+// without one never run on battery; batteries of mice and headsets are
+// ignored.
+func OnBattery() (bool, error) {
+	supplies, err := os.ReadDir(powerSupplies)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("list power supplies: %w", err)
+	}
+
+	for _, supply := range supplies {
+		dir := filepath.Join(powerSupplies, supply.Name())
+		if attribute(dir, "type") != "Battery" || attribute(dir, "scope") == "Device" {
+			continue
+		}
+		if attribute(dir, "status") == "Discharging" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Metered reports whether NetworkManager takes the primary connection to be
+// metered, like a phone's hotspot or a mobile modem.
+func Metered() (bool, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return false, fmt.Errorf("connect to system bus: %w", err)
+	}
+	defer conn.Close()
+
+	manager := conn.Object(networkManagerBus, networkManagerPath)
+	metered, err := manager.GetProperty(networkManagerIface + ".Metered")
+	if err != nil {
+		return false, fmt.Errorf("get metered state: %w", err)
+	}
+	if value, _ := metered.Value().(uint32); value == meteredYes || value == meteredGuessYes {
+		return true, nil
+	}
+
+	kind, err := manager.GetProperty(networkManagerIface + ".PrimaryConnectionType")
+	if err != nil {
+		return false, fmt.Errorf("get connection type: %w", err)
+	}
+	value, _ := kind.Value().(string)
+	return meteredTypes[value], nil
+}
+
+// attribute reads a sysfs attribute of a power supply, empty when missing.
+func attribute(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package power
+
+import "errors"
+
+var errUnsupported = errors.New("power and network state are only available on Linux")
+
+// OnBattery has no way to learn about the power supply on these platforms.
+func OnBattery() (bool, error) { return false, errUnsupported }
+
+// Metered has no way to learn about the network on these platforms.
+func Metered() (bool, error) { return false, errUnsupported }
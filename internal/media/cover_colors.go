@@ -0,0 +1,137 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	_ "image/png"
+	"sync"
+)
+
+// colorSamples is roughly how many pixels per side are looked at; covers are
+// downsampled to this grid before their colors are counted.
+const colorSamples = 64
+
+// DominantColor returns the most prominent color of an encoded image. Pixels
+// are grouped into coarse color buckets weighted towards saturated colors,
+// so a vivid accent wins over a large grey or near-black background. Images
+// without any colorful pixels yield their average color.
+func DominantColor(data []byte) (color.NRGBA, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("decode cover: %w", err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return color.NRGBA{}, fmt.Errorf("empty cover image")
+	}
+	step := max(bounds.Dx(), bounds.Dy()) / colorSamples
+	if step < 1 {
+		step = 1
+	}
+
+	type bucket struct {
+		r, g, b, weight float64
+	}
+	buckets := make(map[uint16]*bucket)
+	var all bucket
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			r, g, b := float64(c.R), float64(c.G), float64(c.B)
+			all.r, all.g, all.b, all.weight = all.r+r, all.g+g, all.b+b, all.weight+1
+
+			hi := max(c.R, c.G, c.B)
+			lo := min(c.R, c.G, c.B)
+			if hi < 24 || lo > 232 {
+				continue
+			}
+			saturation := float64(hi-lo) / float64(hi)
+			weight := 1 + 4*saturation*saturation
+
+			key := uint16(c.R>>4)<<8 | uint16(c.G>>4)<<4 | uint16(c.B>>4)
+			bk := buckets[key]
+			if bk == nil {
+				bk = &bucket{}
+				buckets[key] = bk
+			}
+			bk.r += r * weight
+			bk.g += g * weight
+			bk.b += b * weight
+			bk.weight += weight
+		}
+	}
+
+	var best *bucket
+	for _, bk := range buckets {
+		if best == nil || bk.weight > best.weight {
+			best = bk
+		}
+	}
+	if best == nil {
+		best = &all
+	}
+	if best.weight == 0 {
+		return color.NRGBA{}, fmt.Errorf("cover has no opaque pixels")
+	}
+	return color.NRGBA{
+		R: uint8(best.r / best.weight),
+		G: uint8(best.g / best.weight),
+		B: uint8(best.b / best.weight),
+		A: 0xff,
+	}, nil
+}
+
+// CoverColors remembers the dominant color of covers by key, usually the
+// song, so each cover is only analysed once.
+type CoverColors struct {
+	mu       sync.Mutex
+	colors   map[string]color.NRGBA
+	capacity int
+}
+
+func NewCoverColors(capacity int) *CoverColors {
+	return &CoverColors{
+		colors:   make(map[string]color.NRGBA),
+		capacity: capacity,
+	}
+}
+
+// Get returns the color stored for key, if any.
+func (c *CoverColors) Get(key string) (color.NRGBA, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	col, ok := c.colors[key]
+	return col, ok
+}
+
+// Extract returns the dominant color of the cover for key, analysing data
+// only when the key has not been seen before.
+func (c *CoverColors) Extract(key string, data []byte) (color.NRGBA, error) {
+	if col, ok := c.Get(key); ok {
+		return col, nil
+	}
+
+	col, err := DominantColor(data)
+	if err != nil {
+		return color.NRGBA{}, err
+	}
+
+	c.mu.Lock()
+	if len(c.colors) >= c.capacity {
+		for k := range c.colors {
+			delete(c.colors, k)
+			break
+		}
+	}
+	c.colors[key] = col
+	c.mu.Unlock()
+	return col, nil
+}
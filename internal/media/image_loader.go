@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -19,9 +20,14 @@ import (
 	"fyne.io/fyne/v2/theme"
 
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
 	db "github.com/Alexander-D-Karpov/amp/internal/storage"
 )
 
+// fileScheme marks image URLs that point at a file on this machine, such as
+// artwork the user picked themselves.
+const fileScheme = "file://"
+
 type ImageLoader struct {
 	storage      *db.Database
 	httpClient   *http.Client
@@ -116,6 +122,16 @@ func (lru *LRUCache) Range(fn func(key string, value *CachedResource) bool) {
 	}
 }
 
+func (lru *LRUCache) Remove(key string) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	if elem, ok := lru.cache[key]; ok {
+		lru.list.Remove(elem)
+		delete(lru.cache, key)
+	}
+}
+
 func (lru *LRUCache) Len() int {
 	lru.mu.RLock()
 	defer lru.mu.RUnlock()
@@ -143,7 +159,7 @@ func NewImageLoader(cfg *config.Config, db *db.Database) (*ImageLoader, error) {
 
 	loader := &ImageLoader{
 		storage:      db,
-		httpClient:   &http.Client{Timeout: time.Duration(cfg.API.Timeout) * time.Second},
+		httpClient:   netutil.NewClient(cfg, netutil.ProfileImages),
 		lruCache:     NewLRUCache(500),
 		mediaBase:    mediaBase,
 		debug:        cfg.Debug,
@@ -209,9 +225,23 @@ func (l *ImageLoader) GetResource(imageURL string) (fyne.Resource, error) {
 func (l *ImageLoader) loadResourceSync(fullURL string) (fyne.Resource, error) {
 	cacheKey := l.generateCacheKey(fullURL)
 
+	if path, ok := strings.CutPrefix(fullURL, fileScheme); ok {
+		data, err := l.loadFromDisk(path)
+		if err != nil {
+			return theme.MediaMusicIcon(), fmt.Errorf("read local image: %w", err)
+		}
+		if !l.isValidImageData(data) {
+			return theme.MediaMusicIcon(), fmt.Errorf("invalid image data")
+		}
+		res := fyne.NewStaticResource(filepath.Base(path), data)
+		l.storeInMemCache(cacheKey, res, int64(len(data)), fullURL)
+		return res, nil
+	}
+
 	localPath := filepath.Join(l.cacheDir, cacheKey)
 	if data, err := l.loadFromDisk(localPath); err == nil && len(data) > 0 {
 		if l.isValidImageData(data) {
+			go l.touchOnDisk(localPath)
 			res := fyne.NewStaticResource(l.generateResourceName(fullURL), data)
 			l.storeInMemCache(cacheKey, res, int64(len(data)), fullURL)
 			return res, nil
@@ -241,13 +271,11 @@ func (l *ImageLoader) loadResourceSync(fullURL string) (fyne.Resource, error) {
 		return theme.MediaMusicIcon(), fmt.Errorf("invalid image data")
 	}
 
-	l.saveToDisk(localPath, data)
-
-	go func() {
-		_, saveErr := l.storage.SaveCachedFile(ctx, fullURL, bytes.NewReader(data))
-		if saveErr != nil && l.debug {
-		}
-	}()
+	if err := l.saveToDisk(localPath, data); err != nil {
+		log.Printf("[IMAGE_LOADER] Failed to cache image %s: %v", fullURL, err)
+	} else {
+		go l.trackOnDisk(fullURL, localPath, int64(len(data)))
+	}
 
 	res := fyne.NewStaticResource(l.generateResourceName(fullURL), data)
 	l.storeInMemCache(cacheKey, res, int64(len(data)), fullURL)
@@ -359,6 +387,20 @@ func (l *ImageLoader) saveToDisk(path string, data []byte) error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// trackOnDisk records a downloaded image with the cache janitor.
+func (l *ImageLoader) trackOnDisk(fullURL, path string, size int64) {
+	if err := l.storage.TrackCacheFile(context.Background(), fullURL, path, size); err != nil && l.debug {
+		log.Printf("[IMAGE_LOADER] Failed to track cached image %s: %v", fullURL, err)
+	}
+}
+
+// touchOnDisk tells the cache janitor an image on disk was just used.
+func (l *ImageLoader) touchOnDisk(path string) {
+	if err := l.storage.TouchCacheFile(context.Background(), path); err != nil && l.debug {
+		log.Printf("[IMAGE_LOADER] Failed to touch cached image %s: %v", path, err)
+	}
+}
+
 func (l *ImageLoader) storeInMemCache(key string, resource fyne.Resource, size int64, url string) {
 	cached := &CachedResource{
 		resource:   resource,
@@ -389,7 +431,7 @@ func (l *ImageLoader) cleanupMemoryCache() {
 }
 
 func (l *ImageLoader) buildFullURL(path string) string {
-	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") || strings.HasPrefix(path, fileScheme) {
 		return path
 	}
 	if strings.HasPrefix(path, "/") {
@@ -410,6 +452,43 @@ func (l *ImageLoader) generateResourceName(url string) string {
 	return url
 }
 
+// Forget drops every cached copy of imageURL, in memory and on disk, so the
+// next load fetches it from the server again.
+func (l *ImageLoader) Forget(imageURL string) {
+	if imageURL == "" {
+		return
+	}
+
+	fullURL := l.buildFullURL(imageURL)
+	cacheKey := l.generateCacheKey(fullURL)
+	l.lruCache.Remove(cacheKey)
+
+	if err := os.Remove(filepath.Join(l.cacheDir, cacheKey)); err != nil && !os.IsNotExist(err) {
+		log.Printf("[IMAGE_LOADER] Failed to remove cached image %s: %v", fullURL, err)
+	}
+	if err := l.storage.DeleteCachedFile(context.Background(), fullURL); err != nil {
+		log.Printf("[IMAGE_LOADER] Failed to forget cached image %s: %v", fullURL, err)
+	}
+}
+
+// ExternalURL returns a URL other programs can load imageURL from: the copy
+// cached on disk as a file:// URL when there is one, the full server URL
+// otherwise.
+func (l *ImageLoader) ExternalURL(imageURL string) string {
+	if imageURL == "" {
+		return ""
+	}
+	fullURL := l.buildFullURL(imageURL)
+	if strings.HasPrefix(fullURL, fileScheme) {
+		return fullURL
+	}
+	localPath := filepath.Join(l.cacheDir, l.generateCacheKey(fullURL))
+	if _, err := os.Stat(localPath); err == nil {
+		return fileScheme + localPath
+	}
+	return fullURL
+}
+
 func (l *ImageLoader) ClearMemoryCache() {
 	l.lruCache.Clear()
 }
@@ -0,0 +1,110 @@
+package netutil
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+// Profile is what a client is used for, which sets how long its requests
+// may take. Every profile shares one connection pool.
+type Profile int
+
+const (
+	// ProfileAPI is for server API calls, limited by api.timeout.
+	ProfileAPI Profile = iota
+	// ProfileImages is for cover art, limited by api.timeout.
+	ProfileImages
+	// ProfileStream is for playing songs while they download.
+	ProfileStream
+	// ProfileDownload is for saving songs.
+	ProfileDownload
+	// ProfileUpdate is for fetching releases, which can be large.
+	ProfileUpdate
+	// ProfileFeedback is for posting bug reports.
+	ProfileFeedback
+	// ProfileLyrics is for looking up lyrics on LRCLIB, limited by
+	// api.timeout.
+	ProfileLyrics
+)
+
+// Timeout returns how long a whole request of the profile may take, body
+// included.
+func (p Profile) Timeout(cfg *config.Config) time.Duration {
+	switch p {
+	case ProfileAPI, ProfileImages, ProfileLyrics:
+		return time.Duration(cfg.API.Timeout) * time.Second
+	case ProfileStream, ProfileDownload, ProfileUpdate:
+		return 10 * time.Minute
+	default:
+		return 30 * time.Second
+	}
+}
+
+// NewClient returns a client for profile on the shared transport. Clients
+// follow later calls to Apply.
+func NewClient(cfg *config.Config, profile Profile) *http.Client {
+	shared.init(cfg)
+	return &http.Client{
+		Timeout:   profile.Timeout(cfg),
+		Transport: shared,
+	}
+}
+
+// Apply rebuilds the shared transport with the network settings of cfg.
+// Requests in flight finish on the old one. On error the transport is left
+// as it was.
+func Apply(cfg *config.Config) error {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return err
+	}
+	shared.swap(transport)
+	return nil
+}
+
+// shared is the transport behind every client NewClient returns.
+var shared = &sharedTransport{}
+
+// sharedTransport hands each request to the current transport, so clients
+// created before a settings change pick it up.
+type sharedTransport struct {
+	mu      sync.RWMutex
+	current *http.Transport
+}
+
+func (s *sharedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.RLock()
+	transport := s.current
+	s.mu.RUnlock()
+	return transport.RoundTrip(req)
+}
+
+// init builds the transport the first time a client is made. Invalid
+// settings leave it without a proxy or TLS changes until Apply is called
+// with valid ones.
+func (s *sharedTransport) init(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		return
+	}
+	transport, err := newTransport(cfg)
+	if err != nil {
+		transport = basicTransport()
+	}
+	s.current = transport
+}
+
+func (s *sharedTransport) swap(transport *http.Transport) {
+	s.mu.Lock()
+	old := s.current
+	s.current = transport
+	s.mu.Unlock()
+
+	if old != nil {
+		old.CloseIdleConnections()
+	}
+}
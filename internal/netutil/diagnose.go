@@ -0,0 +1,155 @@
+package netutil
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+// Stage is one step of a connection check.
+type Stage struct {
+	Name   string
+	Took   time.Duration
+	Detail string
+	Err    error
+}
+
+// CheckConnection resolves, connects to and, for https, shakes hands with
+// the server at rawURL the way AMP's clients would, stopping at the first
+// stage that fails. With a proxy in use the DNS and TCP stages check the
+// proxy, and the TLS stage is left to a request through it.
+func CheckConnection(ctx context.Context, cfg *config.Config, rawURL string) []Stage {
+	target, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || target.Host == "" {
+		if err == nil {
+			err = fmt.Errorf("%q is not a URL", rawURL)
+		}
+		return []Stage{{Name: "URL", Err: err}}
+	}
+
+	proxyURL, err := proxyFor(cfg, target)
+	if err != nil {
+		return []Stage{{Name: "Proxy", Err: err}}
+	}
+
+	dialTarget := target
+	if proxyURL != nil {
+		dialTarget = proxyURL
+	}
+	host, port := dialTarget.Hostname(), dialTarget.Port()
+	if port == "" {
+		port = defaultPort(dialTarget.Scheme)
+	}
+
+	var stages []Stage
+	run := func(name string, fn func() (string, error)) bool {
+		start := time.Now()
+		detail, err := fn()
+		stages = append(stages, Stage{Name: name, Took: time.Since(start), Detail: detail, Err: err})
+		return err == nil
+	}
+
+	var addrs []net.IPAddr
+	if !run("DNS", func() (string, error) {
+		addrs, err = net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return "", err
+		}
+		return describeAddrs(addrs), nil
+	}) {
+		return stages
+	}
+
+	var conn net.Conn
+	if !run("TCP", func() (string, error) {
+		conn, err = DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+		if err != nil {
+			return "", err
+		}
+		detail := "connected to " + conn.RemoteAddr().String()
+		if proxyURL != nil {
+			detail += " (proxy)"
+		}
+		return detail, nil
+	}) {
+		return stages
+	}
+	defer func() { _ = conn.Close() }()
+
+	if target.Scheme != "https" || proxyURL != nil {
+		return stages
+	}
+
+	run("TLS", func() (string, error) {
+		tlsConfig, err := TLSConfig(cfg)
+		if err != nil {
+			return "", err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.ServerName = target.Hostname()
+
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return "", err
+		}
+		state := tlsConn.ConnectionState()
+		detail := tls.VersionName(state.Version)
+		if len(state.PeerCertificates) > 0 {
+			cert := state.PeerCertificates[0]
+			name := cert.Subject.CommonName
+			if name == "" && len(cert.DNSNames) > 0 {
+				name = cert.DNSNames[0]
+			}
+			if name != "" {
+				detail += ", certificate for " + name
+			}
+			detail += " valid until " + cert.NotAfter.Format("2006-01-02")
+		}
+		return detail, nil
+	})
+	return stages
+}
+
+// proxyFor returns the proxy a request to target would go through, if any.
+func proxyFor(cfg *config.Config, target *url.URL) (*url.URL, error) {
+	proxy, err := ProxyFunc(cfg)
+	if err != nil || proxy == nil {
+		return nil, err
+	}
+	return proxy(&http.Request{URL: target})
+}
+
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "https":
+		return "443"
+	case "socks5", "socks5h":
+		return "1080"
+	default:
+		return "80"
+	}
+}
+
+func describeAddrs(addrs []net.IPAddr) string {
+	var v4, v6 int
+	names := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			v4++
+		} else {
+			v6++
+		}
+		names = append(names, addr.String())
+	}
+	return fmt.Sprintf("%d IPv4, %d IPv6: %s", v4, v6, strings.Join(names, ", "))
+}
@@ -0,0 +1,22 @@
+package netutil
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// dialer is shared by every transport. For hosts with both IPv6 and IPv4
+// addresses it starts on the first family and races the other after
+// FallbackDelay (happy eyeballs), so a broken family on a dual-stack
+// network costs a fraction of a second instead of the full timeout.
+var dialer = &net.Dialer{
+	Timeout:       10 * time.Second,
+	KeepAlive:     30 * time.Second,
+	FallbackDelay: 250 * time.Millisecond,
+}
+
+// DialContext dials with the shared dialer.
+func DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return dialer.DialContext(ctx, network, address)
+}
@@ -0,0 +1,159 @@
+// Package netutil creates the HTTP clients AMP uses, all on one transport
+// with the network settings applied.
+package netutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+// Proxy modes
+const (
+	ProxySystem = "system"
+	ProxyNone   = "none"
+	ProxyManual = "manual"
+)
+
+// Pool limits of the shared transport. Everything AMP fetches usually
+// comes from one server, so more idle connections are kept per host than
+// the default two.
+const (
+	maxIdleConns        = 64
+	maxIdleConnsPerHost = 16
+	idleConnTimeout     = 90 * time.Second
+)
+
+// Check reports whether the network settings of cfg can be applied.
+func Check(cfg *config.Config) error {
+	if _, err := ProxyFunc(cfg); err != nil {
+		return err
+	}
+	_, err := TLSConfig(cfg)
+	return err
+}
+
+// newTransport returns a transport with the shared dialer and the proxy and
+// TLS settings of cfg.
+func newTransport(cfg *config.Config) (*http.Transport, error) {
+	proxy, err := ProxyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := TLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := basicTransport()
+	transport.Proxy = proxy
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// basicTransport returns a transport with the shared dialer and pool
+// limits and no proxy.
+func basicTransport() *http.Transport {
+	return &http.Transport{
+		DialContext:           DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		TLSHandshakeTimeout:   15 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// ProxyFunc returns the proxy selection for the configured mode.
+func ProxyFunc(cfg *config.Config) (func(*http.Request) (*url.URL, error), error) {
+	proxy := cfg.Network.Proxy
+	switch proxy.Mode {
+	case ProxySystem, "":
+		return http.ProxyFromEnvironment, nil
+	case ProxyNone:
+		return nil, nil
+	case ProxyManual:
+		proxyURL, err := ParseProxyURL(proxy.URL)
+		if err != nil {
+			return nil, err
+		}
+		if proxy.Username != "" {
+			proxyURL.User = url.UserPassword(proxy.Username, proxy.Password)
+		}
+		return http.ProxyURL(proxyURL), nil
+	default:
+		return nil, fmt.Errorf("unknown proxy mode %q", proxy.Mode)
+	}
+}
+
+// ParseProxyURL checks a manual proxy address. A bare host:port is taken as
+// an HTTP proxy.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("manual proxy needs a URL")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse proxy URL: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", raw)
+	}
+	return proxyURL, nil
+}
+
+// TLSConfig returns the TLS settings for cfg, or nil for the defaults.
+func TLSConfig(cfg *config.Config) (*tls.Config, error) {
+	if cfg.Network.CAFile == "" && !cfg.Network.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.Network.InsecureSkipVerify,
+	}
+	if cfg.Network.CAFile != "" {
+		pool, err := certPool(cfg.Network.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// certPool returns the system roots plus the certificates in path.
+func certPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
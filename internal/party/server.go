@@ -0,0 +1,481 @@
+// Package party serves a small web page on the local network that lets guests
+// search the library and request songs for the host's queue.
+package party
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusAccepted Status = "accepted"
+	StatusRejected Status = "rejected"
+)
+
+const (
+	maxSearchResults   = 25
+	maxPendingPerGuest = 3
+	maxGuestNameLength = 32
+	maxRequestBody     = 4 << 10
+	keptRequests       = 50
+	shutdownTimeout    = 3 * time.Second
+
+	// quotaWindow is the span RequestsPerHour counts accepted requests in.
+	quotaWindow = time.Hour
+	// joinCodeHeader carries the join code on every API call of the page.
+	joinCodeHeader = "X-Party-Code"
+)
+
+//go:embed index.html
+var indexPage []byte
+
+// Library is the part of the music service the party page needs.
+type Library interface {
+	SearchAll(ctx context.Context, query string) (*types.SearchResponse, error)
+	GetSong(ctx context.Context, slug string) (*types.Song, error)
+}
+
+// Request is a song a guest asked to add to the queue.
+type Request struct {
+	ID          int
+	Song        *types.Song
+	Guest       string
+	RequestedAt time.Time
+	Status      Status
+
+	addr string
+}
+
+// QueueProvider returns the song playing now and the songs queued after it.
+type QueueProvider func() (current *types.Song, upcoming []*types.Song)
+
+type Server struct {
+	cfg     *config.Config
+	library Library
+	debug   bool
+
+	mu         sync.Mutex
+	server     *http.Server
+	requests   []*Request
+	nextID     int
+	accepted   map[string][]time.Time // by guest address, oldest first
+	queue      QueueProvider
+	onRequest  func(*Request)
+	onAccepted func(*Request)
+}
+
+func NewServer(cfg *config.Config, library Library) *Server {
+	return &Server{
+		cfg:     cfg,
+		library: library,
+		debug:   cfg.Debug,
+		nextID:  1,
+	}
+}
+
+// SetQueueProvider sets where the page reads the current queue from.
+func (s *Server) SetQueueProvider(provider QueueProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = provider
+}
+
+// OnRequest is called for every new guest request. When approval is required
+// the request is still pending and must be settled with Resolve.
+func (s *Server) OnRequest(callback func(*Request)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRequest = callback
+}
+
+// OnAccepted is called when a request is accepted and its song should be queued.
+func (s *Server) OnAccepted(callback func(*Request)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onAccepted = callback
+}
+
+// Running reports whether the server is listening.
+func (s *Server) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server != nil
+}
+
+// Start begins listening on the configured port on all interfaces.
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.server != nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.cfg.Party.Port))
+	if err != nil {
+		return fmt.Errorf("listen on port %d: %w", s.cfg.Party.Port, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleIndex)
+	mux.HandleFunc("GET /api/search", s.requireJoinCode(s.handleSearch))
+	mux.HandleFunc("GET /api/queue", s.requireJoinCode(s.handleQueue))
+	mux.HandleFunc("POST /api/requests", s.requireJoinCode(s.handleRequest))
+
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		WriteTimeout:      15 * time.Second,
+	}
+
+	go func(srv *http.Server) {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("[PARTY] Server stopped: %v", err)
+		}
+	}(s.server)
+
+	log.Printf("[PARTY] Party mode listening on port %d", s.cfg.Party.Port)
+	return nil
+}
+
+// Stop shuts the server down and drops all requests.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	srv := s.server
+	s.server = nil
+	s.requests = nil
+	s.accepted = nil
+	s.mu.Unlock()
+
+	if srv == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil && s.debug {
+		log.Printf("[PARTY] Shutdown error: %v", err)
+	}
+}
+
+// Addresses returns the URLs guests on the local network can open.
+func (s *Server) Addresses() []string {
+	var urls []string
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return urls
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		urls = append(urls, fmt.Sprintf("http://%s:%d/", ipNet.IP, s.cfg.Party.Port))
+	}
+	return urls
+}
+
+// Resolve accepts or rejects a pending request.
+func (s *Server) Resolve(id int, accept bool) {
+	s.mu.Lock()
+	var req *Request
+	for _, r := range s.requests {
+		if r.ID == id && r.Status == StatusPending {
+			req = r
+			break
+		}
+	}
+	if req == nil {
+		s.mu.Unlock()
+		return
+	}
+	if accept {
+		req.Status = StatusAccepted
+		s.recordAccepted(req.addr, time.Now())
+	} else {
+		req.Status = StatusRejected
+	}
+	cb := s.onAccepted
+	s.mu.Unlock()
+
+	if accept && cb != nil {
+		cb(req)
+	}
+}
+
+// requireJoinCode turns away calls without the join code, when one is set.
+func (s *Server) requireJoinCode(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		code := s.cfg.Party.JoinCode
+		given := strings.TrimSpace(r.Header.Get(joinCodeHeader))
+		if code != "" && subtle.ConstantTimeCompare([]byte(given), []byte(code)) != 1 {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "join code required"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexPage)
+}
+
+type songJSON struct {
+	Slug   string `json:"slug"`
+	Name   string `json:"name"`
+	Artist string `json:"artist"`
+	Length int    `json:"length"`
+}
+
+type requestJSON struct {
+	ID     int      `json:"id"`
+	Song   songJSON `json:"song"`
+	Guest  string   `json:"guest"`
+	Status Status   `json:"status"`
+}
+
+func toSongJSON(song *types.Song) songJSON {
+	return songJSON{
+		Slug:   song.Slug,
+		Name:   song.Name,
+		Artist: types.CreditsLabel(song.Authors),
+		Length: song.Length,
+	}
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	songs := make([]songJSON, 0)
+	if query == "" {
+		writeJSON(w, http.StatusOK, songs)
+		return
+	}
+
+	result, err := s.library.SearchAll(r.Context(), query)
+	if err != nil {
+		if s.debug {
+			log.Printf("[PARTY] Search for %q failed: %v", query, err)
+		}
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "search failed"})
+		return
+	}
+
+	for _, song := range result.Songs {
+		if song == nil || song.IsLocalOnly() {
+			continue
+		}
+		songs = append(songs, toSongJSON(song))
+		if len(songs) == maxSearchResults {
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, songs)
+}
+
+func (s *Server) handleQueue(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	provider := s.queue
+	requests := make([]requestJSON, 0, len(s.requests))
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		req := s.requests[i]
+		requests = append(requests, requestJSON{
+			ID:     req.ID,
+			Song:   toSongJSON(req.Song),
+			Guest:  req.Guest,
+			Status: req.Status,
+		})
+	}
+	s.mu.Unlock()
+
+	response := struct {
+		Current  *songJSON     `json:"current"`
+		Upcoming []songJSON    `json:"upcoming"`
+		Requests []requestJSON `json:"requests"`
+	}{
+		Upcoming: make([]songJSON, 0),
+		Requests: requests,
+	}
+
+	if provider != nil {
+		current, upcoming := provider()
+		if current != nil {
+			c := toSongJSON(current)
+			response.Current = &c
+		}
+		for _, song := range upcoming {
+			response.Upcoming = append(response.Upcoming, toSongJSON(song))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleRequest(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Slug  string `json:"slug"`
+		Guest string `json:"guest"`
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Slug == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request"})
+		return
+	}
+
+	song, err := s.library.GetSong(r.Context(), body.Slug)
+	if err != nil || song == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "song not found"})
+		return
+	}
+
+	addr := remoteHost(r)
+	req := &Request{
+		Song:        song,
+		Guest:       sanitizeGuest(body.Guest),
+		RequestedAt: time.Now(),
+		Status:      StatusPending,
+		addr:        addr,
+	}
+
+	// The limits are checked and the request added under one lock so that
+	// parallel requests from a guest cannot all pass the checks first.
+	s.mu.Lock()
+	if s.pendingFrom(addr) >= maxPendingPerGuest {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "too many pending requests"})
+		return
+	}
+	if s.quotaUsed(addr, req.RequestedAt) {
+		s.mu.Unlock()
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "request limit reached, try again later"})
+		return
+	}
+	req.ID = s.nextID
+	s.nextID++
+	if !s.cfg.Party.RequireApproval {
+		req.Status = StatusAccepted
+		s.recordAccepted(addr, req.RequestedAt)
+	}
+	s.requests = append(s.requests, req)
+	if len(s.requests) > keptRequests {
+		s.requests = s.requests[len(s.requests)-keptRequests:]
+	}
+	status := req.Status
+	onRequest := s.onRequest
+	onAccepted := s.onAccepted
+	s.mu.Unlock()
+
+	if s.debug {
+		log.Printf("[PARTY] %s requested %s (%s)", req.Guest, song.Name, status)
+	}
+
+	if onRequest != nil {
+		onRequest(req)
+	}
+	if status == StatusAccepted && onAccepted != nil {
+		onAccepted(req)
+	}
+
+	writeJSON(w, http.StatusCreated, requestJSON{
+		ID:     req.ID,
+		Song:   toSongJSON(song),
+		Guest:  req.Guest,
+		Status: status,
+	})
+}
+
+// pendingFrom counts the requests of the guest at addr still awaiting a
+// decision. The caller holds s.mu.
+func (s *Server) pendingFrom(addr string) int {
+	count := 0
+	for _, req := range s.requests {
+		if req.addr == addr && req.Status == StatusPending {
+			count++
+		}
+	}
+	return count
+}
+
+// quotaUsed reports whether the guest at addr had as many requests accepted
+// within quotaWindow as RequestsPerHour allows. The caller holds s.mu.
+func (s *Server) quotaUsed(addr string, now time.Time) bool {
+	limit := s.cfg.Party.RequestsPerHour
+	if limit <= 0 {
+		return false
+	}
+	return len(s.recentAccepted(addr, now)) >= limit
+}
+
+// recordAccepted counts a request of the guest at addr against its quota.
+// The caller holds s.mu.
+func (s *Server) recordAccepted(addr string, at time.Time) {
+	if s.accepted == nil {
+		s.accepted = make(map[string][]time.Time)
+	}
+	s.accepted[addr] = append(s.recentAccepted(addr, at), at)
+}
+
+// recentAccepted drops the acceptances of addr older than quotaWindow and
+// returns the rest. The caller holds s.mu.
+func (s *Server) recentAccepted(addr string, now time.Time) []time.Time {
+	times := s.accepted[addr]
+	for len(times) > 0 && now.Sub(times[0]) >= quotaWindow {
+		times = times[1:]
+	}
+	if len(times) == 0 {
+		delete(s.accepted, addr)
+		return nil
+	}
+	s.accepted[addr] = times
+	return times
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// sanitizeGuest strips control characters and limits the name length.
+func sanitizeGuest(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(name))
+
+	if runes := []rune(name); len(runes) > maxGuestNameLength {
+		name = string(runes[:maxGuestNameLength])
+	}
+	if name == "" {
+		return "Guest"
+	}
+	return name
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
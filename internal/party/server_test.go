@@ -0,0 +1,78 @@
+package party
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// fakeLibrary answers every lookup after a short delay, like a database or
+// API call would, which widens any window between checking a guest's limits
+// and recording the request.
+type fakeLibrary struct{}
+
+func (fakeLibrary) SearchAll(context.Context, string) (*types.SearchResponse, error) {
+	return &types.SearchResponse{}, nil
+}
+
+func (fakeLibrary) GetSong(_ context.Context, slug string) (*types.Song, error) {
+	time.Sleep(10 * time.Millisecond)
+	return &types.Song{Slug: slug, Name: slug}, nil
+}
+
+// requestInParallel sends n song requests from one guest at once and returns
+// how many were created.
+func requestInParallel(s *Server, n int) int {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodPost, "/api/request", strings.NewReader(`{"slug":"song","guest":"guest"}`))
+			r.RemoteAddr = "192.0.2.10:5000"
+			w := httptest.NewRecorder()
+			<-start
+			s.handleRequest(w, r)
+			if w.Code == http.StatusCreated {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return created
+}
+
+func TestParallelRequestsKeepPendingLimit(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Party.RequireApproval = true
+	s := NewServer(cfg, fakeLibrary{})
+
+	if created := requestInParallel(s, 20); created != maxPendingPerGuest {
+		t.Fatalf("%d requests created, want %d", created, maxPendingPerGuest)
+	}
+}
+
+func TestParallelRequestsKeepHourlyQuota(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Party.RequestsPerHour = 2
+	s := NewServer(cfg, fakeLibrary{})
+
+	if created := requestInParallel(s, 20); created != 2 {
+		t.Fatalf("%d requests created, want 2", created)
+	}
+}
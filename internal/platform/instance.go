@@ -0,0 +1,361 @@
+package platform
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const instanceSocketName = "amp.sock"
+
+// controlHeader opens a control request on the instance socket, telling it
+// apart from the arguments of a second launch. The command and its
+// arguments follow on their own lines.
+const controlHeader = "#amp-control"
+
+// controlTimeout bounds how long a control request may take, longer than an
+// activation since commands wait for the UI to carry them out.
+const controlTimeout = 5 * time.Second
+
+// ErrAlreadyRunning is returned by AcquireInstance when another AMP process
+// holds the lock and has been asked to activate itself.
+var ErrAlreadyRunning = errors.New("another instance is already running")
+
+// ErrNotRunning is returned by SendControl when no AMP process is listening.
+var ErrNotRunning = errors.New("AMP is not running")
+
+// ControlHandler carries out a control command and returns its output.
+type ControlHandler func(command string, args []string) (string, error)
+
+// InstanceLock guards against running two AMP processes against the same
+// data directory. The primary instance listens on a local socket; later
+// launches connect to it, forward their arguments and exit.
+type InstanceLock struct {
+	listener   net.Listener
+	path       string
+	mu         sync.Mutex
+	onActivate func(args []string)
+	onControl  ControlHandler
+	pending    [][]string
+	closed     bool
+}
+
+// AcquireInstance takes the single-instance lock. If another instance is
+// already running, args are forwarded to it and ErrAlreadyRunning is returned.
+func AcquireInstance(args []string) (*InstanceLock, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return nil, fmt.Errorf("get data dir: %w", err)
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	path := filepath.Join(dataDir, instanceSocketName)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		sendErr := sendActivation(path, args)
+		if sendErr == nil {
+			return nil, ErrAlreadyRunning
+		}
+		if !staleSocket(sendErr) {
+			// Someone listens but did not answer in time; the socket
+			// is theirs.
+			return nil, fmt.Errorf("activate running instance: %w", sendErr)
+		}
+
+		// Nobody listens, so the socket is left over from a crashed run.
+		if rmErr := os.Remove(path); rmErr != nil && !os.IsNotExist(rmErr) {
+			return nil, fmt.Errorf("remove stale instance socket: %w", rmErr)
+		}
+		listener, err = net.Listen("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("listen on instance socket: %w", err)
+		}
+	}
+
+	lock := &InstanceLock{
+		listener: listener,
+		path:     path,
+	}
+	go lock.serve()
+
+	return lock, nil
+}
+
+// staleSocket reports whether dialing the instance socket failed because
+// nothing listens on it any more.
+func staleSocket(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, os.ErrNotExist) {
+		return true
+	}
+	// Windows reports WSAECONNREFUSED, which syscall has no name for.
+	var errno syscall.Errno
+	return runtime.GOOS == "windows" && errors.As(err, &errno) && errno == 10061
+}
+
+// AbsFileArgs returns args with relative paths of existing files made
+// absolute, since the instance they are sent to may run in another
+// directory. URLs and everything else are left as they are.
+func AbsFileArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		out[i] = arg
+		if filepath.IsAbs(arg) {
+			continue
+		}
+		if _, err := os.Stat(arg); err != nil {
+			continue
+		}
+		if abs, err := filepath.Abs(arg); err == nil {
+			out[i] = abs
+		}
+	}
+	return out
+}
+
+func sendActivation(path string, args []string) error {
+	args = AbsFileArgs(args)
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := conn.Close(); closeErr != nil {
+			log.Printf("[INSTANCE] Failed to close activation connection: %v", closeErr)
+		}
+	}()
+
+	if err := conn.SetDeadline(time.Now().Add(2 * time.Second)); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(conn)
+	for _, arg := range args {
+		arg = strings.ReplaceAll(arg, "\n", " ")
+		if _, err := w.WriteString(arg + "\n"); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if uc, ok := conn.(*net.UnixConn); ok {
+		if err := uc.CloseWrite(); err != nil {
+			return err
+		}
+	}
+
+	// Wait for the primary instance to acknowledge so a dead listener
+	// is not mistaken for a live one.
+	ack, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(ack) != "ok" {
+		return fmt.Errorf("unexpected activation reply: %q", ack)
+	}
+	return nil
+}
+
+func (l *InstanceLock) serve() {
+	for {
+		conn, err := l.listener.Accept()
+		if err != nil {
+			l.mu.Lock()
+			closed := l.closed
+			l.mu.Unlock()
+			if !closed {
+				log.Printf("[INSTANCE] Accept failed: %v", err)
+			}
+			return
+		}
+		go l.handleConn(conn)
+	}
+}
+
+func (l *InstanceLock) handleConn(conn net.Conn) {
+	defer func() {
+		if closeErr := conn.Close(); closeErr != nil {
+			log.Printf("[INSTANCE] Failed to close connection: %v", closeErr)
+		}
+	}()
+
+	if err := conn.SetDeadline(time.Now().Add(controlTimeout)); err != nil {
+		return
+	}
+
+	args := make([]string, 0)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			args = append(args, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("[INSTANCE] Failed to read activation: %v", err)
+		return
+	}
+
+	if len(args) > 0 && args[0] == controlHeader {
+		l.handleControl(conn, args[1:])
+		return
+	}
+
+	if _, err := conn.Write([]byte("ok\n")); err != nil {
+		log.Printf("[INSTANCE] Failed to acknowledge activation: %v", err)
+	}
+
+	l.mu.Lock()
+	cb := l.onActivate
+	if cb == nil {
+		l.pending = append(l.pending, args)
+	}
+	l.mu.Unlock()
+
+	if cb != nil {
+		cb(args)
+	}
+}
+
+// handleControl runs a control request and writes "ok" and the command's
+// output, or "error: " and what went wrong.
+func (l *InstanceLock) handleControl(conn net.Conn, lines []string) {
+	l.mu.Lock()
+	handler := l.onControl
+	l.mu.Unlock()
+
+	var reply string
+	switch {
+	case len(lines) == 0:
+		reply = "error: no command given\n"
+	case handler == nil:
+		reply = "error: AMP is still starting\n"
+	default:
+		output, err := handler(lines[0], lines[1:])
+		if err != nil {
+			reply = "error: " + strings.ReplaceAll(err.Error(), "\n", " ") + "\n"
+		} else {
+			reply = "ok\n" + output
+			if output != "" && !strings.HasSuffix(output, "\n") {
+				reply += "\n"
+			}
+		}
+	}
+
+	if _, err := conn.Write([]byte(reply)); err != nil {
+		log.Printf("[INSTANCE] Failed to answer control request: %v", err)
+	}
+}
+
+// OnControl registers the handler for control requests sent with
+// SendControl. Requests that arrive before it is set are refused.
+func (l *InstanceLock) OnControl(handler ControlHandler) {
+	l.mu.Lock()
+	l.onControl = handler
+	l.mu.Unlock()
+}
+
+// SendControl asks the running instance to carry out a command and returns
+// its output. It returns ErrNotRunning when no instance answers.
+func SendControl(command string, args []string) (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", fmt.Errorf("get data dir: %w", err)
+	}
+
+	conn, err := net.DialTimeout("unix", filepath.Join(dataDir, instanceSocketName), time.Second)
+	if err != nil {
+		return "", ErrNotRunning
+	}
+	defer func() {
+		if closeErr := conn.Close(); closeErr != nil {
+			log.Printf("[INSTANCE] Failed to close control connection: %v", closeErr)
+		}
+	}()
+
+	if err := conn.SetDeadline(time.Now().Add(controlTimeout + time.Second)); err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(conn)
+	for _, line := range append([]string{controlHeader, command}, args...) {
+		if _, err := w.WriteString(strings.ReplaceAll(line, "\n", " ") + "\n"); err != nil {
+			return "", fmt.Errorf("send command: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("send command: %w", err)
+	}
+	if uc, ok := conn.(*net.UnixConn); ok {
+		if err := uc.CloseWrite(); err != nil {
+			return "", fmt.Errorf("send command: %w", err)
+		}
+	}
+
+	r := bufio.NewReader(conn)
+	status, err := r.ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("read reply: %w", err)
+	}
+	status = strings.TrimSpace(status)
+	if msg, ok := strings.CutPrefix(status, "error: "); ok {
+		return "", errors.New(msg)
+	}
+	if status != "ok" {
+		return "", fmt.Errorf("unexpected control reply: %q", status)
+	}
+
+	output, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("read reply: %w", err)
+	}
+	return string(output), nil
+}
+
+// OnActivate registers the callback invoked when a second launch asks this
+// instance to come to the front. Activations received before the callback
+// was set are replayed immediately.
+func (l *InstanceLock) OnActivate(callback func(args []string)) {
+	l.mu.Lock()
+	l.onActivate = callback
+	pending := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	if callback == nil {
+		return
+	}
+	for _, args := range pending {
+		callback(args)
+	}
+}
+
+// Release stops listening and removes the socket file.
+func (l *InstanceLock) Release() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	err := l.listener.Close()
+	if rmErr := os.Remove(l.path); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,212 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// id3v2Frames maps the ID3v2 text frames the scanner reads, in both their
+// four letter (v2.3, v2.4) and three letter (v2.2) forms, to Vorbis comment
+// field names.
+var id3v2Frames = map[string]string{
+	"TIT2": "TITLE", "TT2": "TITLE",
+	"TPE1": "ARTIST", "TP1": "ARTIST",
+	"TALB": "ALBUM", "TAL": "ALBUM",
+	"TPE2": "ALBUMARTIST", "TP2": "ALBUMARTIST",
+	"TCON": "GENRE", "TCO": "GENRE",
+	"TRCK": "TRACKNUMBER", "TRK": "TRACKNUMBER",
+	"TDRC": "DATE", "TYER": "DATE", "TYE": "DATE",
+}
+
+// readID3v2 reads the ID3v2 tag at the reader's position, if there is one,
+// and returns how many bytes it takes up. The reader is left after the tag.
+func readID3v2(r io.ReadSeeker, t *Tags) (int64, error) {
+	var header [10]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	if string(header[:3]) != "ID3" {
+		_, err := r.Seek(-10, io.SeekCurrent)
+		return 0, err
+	}
+
+	version, flags := header[3], header[5]
+	size := int64(syncsafe(header[6:10]))
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, err
+	}
+	total := 10 + size
+	if flags&0x10 != 0 {
+		// A footer repeats the header after the tag.
+		if _, err := r.Seek(10, io.SeekCurrent); err != nil {
+			return 0, err
+		}
+		total += 10
+	}
+
+	if version < 4 && flags&0x80 != 0 {
+		body = unsynchronise(body)
+	}
+	if flags&0x40 != 0 && len(body) >= 4 {
+		// Skip the extended header, whose size counts itself in v2.4 only.
+		skip := int(binary.BigEndian.Uint32(body)) + 4
+		if version >= 4 {
+			skip = int(syncsafe(body[:4]))
+		}
+		if skip > len(body) {
+			return total, nil
+		}
+		body = body[skip:]
+	}
+
+	parseID3v2Frames(body, version, t)
+	return total, nil
+}
+
+func parseID3v2Frames(body []byte, version byte, t *Tags) {
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	for len(body) >= headerLen && body[0] != 0 {
+		id := string(body[:idLen])
+		var size int
+		var flags uint16
+		switch version {
+		case 2:
+			size = int(body[3])<<16 | int(body[4])<<8 | int(body[5])
+		case 3:
+			size = int(binary.BigEndian.Uint32(body[4:8]))
+			flags = binary.BigEndian.Uint16(body[8:10])
+		default:
+			size = int(syncsafe(body[4:8]))
+			flags = binary.BigEndian.Uint16(body[8:10])
+		}
+		if size < 0 || headerLen+size > len(body) {
+			return
+		}
+		data := body[headerLen : headerLen+size]
+		body = body[headerLen+size:]
+
+		if version == 3 && flags&0x00c0 != 0 {
+			continue // compressed or encrypted
+		}
+		if version >= 4 {
+			if flags&0x000c != 0 {
+				continue // compressed or encrypted
+			}
+			if flags&0x0001 != 0 && len(data) >= 4 {
+				data = data[4:] // data length indicator
+			}
+			if flags&0x0002 != 0 {
+				data = unsynchronise(data)
+			}
+		}
+
+		if id == "TLEN" || id == "TLE" {
+			if ms := leadingInt(id3Text(data)); ms > 0 && t.Duration == 0 {
+				t.Duration = millis(ms)
+			}
+			continue
+		}
+		if field, ok := id3v2Frames[id]; ok {
+			t.set(field, id3Text(data))
+		}
+	}
+}
+
+// id3Text decodes a text frame. Of several values, as v2.4 separates with
+// NULs, the first is kept.
+func id3Text(data []byte) string {
+	if len(data) < 1 {
+		return ""
+	}
+
+	var text string
+	switch data[0] {
+	case 1, 2:
+		text = decodeUTF16(data[1:], data[0] == 2)
+	case 3:
+		text = string(data[1:])
+	default:
+		text = decodeLatin1(data[1:])
+	}
+	if i := strings.IndexByte(text, 0); i >= 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+// decodeUTF16 decodes UTF-16 text, which starts with a byte order mark
+// unless bigEndian says it is UTF-16BE without one.
+func decodeUTF16(data []byte, bigEndian bool) string {
+	order := binary.ByteOrder(binary.LittleEndian)
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	if len(data) >= 2 {
+		switch {
+		case data[0] == 0xfe && data[1] == 0xff:
+			order, data = binary.BigEndian, data[2:]
+		case data[0] == 0xff && data[1] == 0xfe:
+			order, data = binary.LittleEndian, data[2:]
+		}
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, order.Uint16(data[i:]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func decodeLatin1(data []byte) string {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+// readID3v1 reads the 128 byte ID3v1 tag at the end of an MP3 file, which
+// only fills in what the ID3v2 tag left out. It reports whether there was
+// one.
+func readID3v1(r io.ReaderAt, size int64, t *Tags) bool {
+	if size < 128 {
+		return false
+	}
+	var tag [128]byte
+	if _, err := r.ReadAt(tag[:], size-128); err != nil || string(tag[:3]) != "TAG" {
+		return false
+	}
+
+	field := func(b []byte) string {
+		return strings.TrimSpace(decodeLatin1(bytes.TrimRight(b, "\x00 ")))
+	}
+	t.set("TITLE", field(tag[3:33]))
+	t.set("ARTIST", field(tag[33:63]))
+	t.set("ALBUM", field(tag[63:93]))
+	t.set("DATE", field(tag[93:97]))
+	if tag[125] == 0 && tag[126] != 0 && t.Track == 0 {
+		t.Track = int(tag[126])
+	}
+	if int(tag[127]) < len(id3Genres) {
+		t.set("GENRE", id3Genres[tag[127]])
+	}
+	return true
+}
+
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7f)<<21 | uint32(b[1]&0x7f)<<14 | uint32(b[2]&0x7f)<<7 | uint32(b[3]&0x7f)
+}
+
+// unsynchronise undoes ID3 unsynchronisation, which puts a zero byte after
+// every 0xFF.
+func unsynchronise(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xff, 0x00}, []byte{0xff})
+}
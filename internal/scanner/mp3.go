@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// mp3SyncSearch is how far past the tags the first MPEG frame is looked for.
+const mp3SyncSearch = 64 * 1024
+
+var (
+	mp3Bitrates1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3Bitrates2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3Rates     = [3]int{44100, 48000, 32000}
+)
+
+// readMP3 reads the ID3 tags of an MP3 file and works out its duration from
+// the first frame: exactly from a Xing or VBRI header, as VBR encoders
+// write, or else from the bitrate.
+func readMP3(r io.ReadSeeker, size int64, t *Tags) error {
+	tagSize, err := readID3v2(r, t)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	audioEnd := size
+	if ra, ok := r.(io.ReaderAt); ok && readID3v1(ra, size, t) {
+		audioEnd -= 128
+	}
+	if t.Duration > 0 {
+		return nil
+	}
+
+	buf := make([]byte, mp3SyncSearch)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		if buf[i] != 0xff || buf[i+1]&0xe0 != 0xe0 {
+			continue
+		}
+		if d, ok := mp3Duration(buf[i:], audioEnd-tagSize-int64(i)); ok {
+			t.Duration = d
+			return nil
+		}
+	}
+	return nil
+}
+
+// mp3Duration reads the Layer III frame header at the start of frame.
+func mp3Duration(frame []byte, audioBytes int64) (time.Duration, bool) {
+	version := frame[1] >> 3 & 0x03 // 0 MPEG 2.5, 2 MPEG 2, 3 MPEG 1
+	layer := frame[1] >> 1 & 0x03   // 1 Layer III
+	bitrateIndex := frame[2] >> 4
+	rateIndex := frame[2] >> 2 & 0x03
+	mono := frame[3]>>6 == 3
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return 0, false
+	}
+
+	rate := mp3Rates[rateIndex]
+	bitrate := mp3Bitrates1[bitrateIndex]
+	samplesPerFrame, sideInfo := 1152, 32
+	if mono {
+		sideInfo = 17
+	}
+	if version != 3 {
+		rate /= 2
+		if version == 0 {
+			rate /= 2
+		}
+		bitrate = mp3Bitrates2[bitrateIndex]
+		samplesPerFrame, sideInfo = 576, 17
+		if mono {
+			sideInfo = 9
+		}
+	}
+
+	// Make sure this is a frame and not stray sync bits by finding the
+	// next one where this one says it ends.
+	coefficient := 144
+	if version != 3 {
+		coefficient = 72
+	}
+	length := coefficient*bitrate*1000/rate + int(frame[2]>>1&0x01)
+	if len(frame) >= length+2 && (frame[length] != 0xff || frame[length+1]&0xe0 != 0xe0) {
+		return 0, false
+	}
+
+	frames := 0
+	if off := 4 + sideInfo; len(frame) >= off+12 {
+		if tag := string(frame[off : off+4]); tag == "Xing" || tag == "Info" {
+			if flags := binary.BigEndian.Uint32(frame[off+4:]); flags&1 != 0 {
+				frames = int(binary.BigEndian.Uint32(frame[off+8:]))
+			}
+		}
+	}
+	if off := 4 + 32; frames == 0 && len(frame) >= off+18 && string(frame[off:off+4]) == "VBRI" {
+		frames = int(binary.BigEndian.Uint32(frame[off+14:]))
+	}
+
+	if frames > 0 {
+		return time.Duration(int64(frames) * int64(samplesPerFrame) * int64(time.Second) / int64(rate)), true
+	}
+	if audioBytes <= 0 {
+		return 0, false
+	}
+	return time.Duration(audioBytes * 8 * int64(time.Second) / int64(bitrate*1000)), true
+}
+
+func millis(ms int) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
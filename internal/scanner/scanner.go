@@ -0,0 +1,286 @@
+// Package scanner adds the audio files of local folders to the library as
+// local-only songs, with albums and artists taken from their tags.
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// unknownArtist files songs whose artist can be neither read nor guessed.
+const (
+	unknownArtist = "Unknown Artist"
+	unknownAlbum  = "Unknown Album"
+)
+
+// Result tells what a scan changed.
+type Result struct {
+	Added   int
+	Updated int
+	Removed int
+	Failed  int
+}
+
+// Changed reports whether the scan changed the library.
+func (r Result) Changed() bool {
+	return r.Added+r.Updated+r.Removed > 0
+}
+
+// Scanner keeps the songs of a set of folders in storage.
+type Scanner struct {
+	storage *storage.Database
+	folders []string
+	debug   bool
+
+	scanMu sync.Mutex // one scan at a time
+
+	mu       sync.RWMutex
+	onChange func(Result)
+}
+
+// New returns a scanner for folders. Folders that do not exist are skipped
+// when scanning, without forgetting the songs found in them before, so a
+// drive that is not mounted keeps its songs.
+func New(store *storage.Database, folders []string) *Scanner {
+	cleaned := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		if folder = strings.TrimSpace(folder); folder != "" {
+			cleaned = append(cleaned, filepath.Clean(expandHome(folder)))
+		}
+	}
+	return &Scanner{storage: store, folders: cleaned}
+}
+
+func (s *Scanner) SetDebug(debug bool) {
+	s.debug = debug
+}
+
+// Folders returns the folders the scanner looks in.
+func (s *Scanner) Folders() []string {
+	return append([]string(nil), s.folders...)
+}
+
+// OnChange sets a callback for scans that changed the library. It is called
+// from the scanning goroutine.
+func (s *Scanner) OnChange(callback func(Result)) {
+	s.mu.Lock()
+	s.onChange = callback
+	s.mu.Unlock()
+}
+
+// Scan brings storage up to date with the folders: new files are added,
+// changed ones read again and deleted ones removed. Unchanged files, by size
+// and modification time, are not opened.
+func (s *Scanner) Scan(ctx context.Context) (Result, error) {
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+
+	var result Result
+	known, err := s.storage.GetLibraryFiles(ctx)
+	if err != nil {
+		return result, fmt.Errorf("load library files: %w", err)
+	}
+
+	seen := make(map[string]bool, len(known))
+	var mounted []string
+	for _, folder := range s.folders {
+		if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+			log.Printf("[SCANNER] Skipping unavailable folder %s", folder)
+			continue
+		}
+		mounted = append(mounted, folder)
+
+		err := filepath.WalkDir(folder, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if s.debug {
+					log.Printf("[SCANNER] Cannot read %s: %v", path, err)
+				}
+				return nil
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if entry.IsDir() {
+				if path != folder && strings.HasPrefix(entry.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !IsAudioFile(path) || seen[path] {
+				return nil
+			}
+			seen[path] = true
+			s.scanFile(ctx, folder, path, known[path], &result)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	for path, file := range known {
+		if seen[path] || !removable(path, s.folders, mounted) {
+			continue
+		}
+		if err := s.storage.RemoveLibraryFile(ctx, file); err != nil {
+			log.Printf("[SCANNER] Failed to remove %s: %v", path, err)
+			continue
+		}
+		result.Removed++
+	}
+
+	if s.debug || result.Changed() {
+		log.Printf("[SCANNER] Scanned %d folders: %d added, %d updated, %d removed, %d failed",
+			len(mounted), result.Added, result.Updated, result.Removed, result.Failed)
+	}
+	if result.Changed() {
+		s.mu.RLock()
+		callback := s.onChange
+		s.mu.RUnlock()
+		if callback != nil {
+			callback(result)
+		}
+	}
+	return result, nil
+}
+
+func (s *Scanner) scanFile(ctx context.Context, folder, path string, known *types.LibraryFile, result *Result) {
+	info, err := os.Stat(path)
+	if err != nil {
+		result.Failed++
+		return
+	}
+	if known != nil && known.Size == info.Size() && known.ModTime.Equal(info.ModTime()) {
+		return
+	}
+
+	tags, err := ReadTags(path)
+	if err != nil {
+		if s.debug {
+			log.Printf("[SCANNER] %v", err)
+		}
+		result.Failed++
+		return
+	}
+
+	song := songFromTags(folder, path, tags)
+	file := &types.LibraryFile{Path: path, Size: info.Size(), ModTime: info.ModTime(), SongSlug: song.Slug}
+	if err := s.storage.SaveLibraryFile(ctx, file, song); err != nil {
+		log.Printf("[SCANNER] Failed to save %s: %v", path, err)
+		result.Failed++
+		return
+	}
+	if known != nil {
+		result.Updated++
+	} else {
+		result.Added++
+	}
+}
+
+// removable reports whether a known file that was not found may be
+// forgotten: it lies in a folder that was scanned, or in none of the
+// configured folders any more.
+func removable(path string, folders, mounted []string) bool {
+	for _, folder := range mounted {
+		if within(path, folder) {
+			return true
+		}
+	}
+	for _, folder := range folders {
+		if within(path, folder) {
+			return false
+		}
+	}
+	return true
+}
+
+func within(path, folder string) bool {
+	rel, err := filepath.Rel(folder, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// songFromTags builds the song for the file at path in the library folder
+// root. What the tags lack is guessed from the usual Artist/Album/Track
+// layout below root: the title from the file name, the album from its
+// folder and the artist from the folder above.
+func songFromTags(root, path string, tags *Tags) *types.Song {
+	title := tags.Title
+	if title == "" {
+		title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	albumDir := filepath.Dir(path)
+	artistDir := filepath.Dir(albumDir)
+
+	albumName := tags.Album
+	if albumName == "" && albumDir != root {
+		albumName = filepath.Base(albumDir)
+	}
+	if albumName == "" {
+		albumName = unknownAlbum
+	}
+	artistName := tags.Artist
+	if artistName == "" {
+		artistName = tags.AlbumArtist
+	}
+	if artistName == "" && albumDir != root && artistDir != root {
+		artistName = filepath.Base(artistDir)
+	}
+	if artistName == "" {
+		artistName = unknownArtist
+	}
+	albumArtist := tags.AlbumArtist
+	if albumArtist == "" {
+		albumArtist = artistName
+	}
+
+	artist := &types.Author{Slug: localSlug("author", artistName), Name: artistName}
+	album := &types.Album{
+		Slug:        localSlug("album", albumArtist+"/"+albumName),
+		Name:        albumName,
+		AlbumArtist: tags.AlbumArtist,
+		Artists:     []*types.Author{{Slug: localSlug("author", albumArtist), Name: albumArtist}},
+	}
+
+	localPath := path
+	song := &types.Song{
+		Slug:      types.LocalSlugPrefix + path,
+		Name:      title,
+		File:      path,
+		Length:    int(tags.Duration.Seconds() + 0.5),
+		Album:     album,
+		AlbumSlug: album.Slug,
+		Authors:   []*types.Author{artist},
+		LocalPath: &localPath,
+	}
+	if tags.Genre != "" {
+		genre := tags.Genre
+		song.Meta = &types.Meta{Genre: &genre}
+	}
+	return song
+}
+
+// localSlug names a scanned album or author. Names differing only in case or
+// spacing share a slug.
+func localSlug(kind, name string) string {
+	return types.LocalSlugPrefix + kind + ":" + strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
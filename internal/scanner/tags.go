@@ -0,0 +1,159 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnsupported is returned for files in a format the scanner cannot read.
+var ErrUnsupported = errors.New("unsupported audio format")
+
+var audioExts = map[string]bool{
+	".mp3":  true,
+	".flac": true,
+	".ogg":  true,
+	".oga":  true,
+}
+
+// IsAudioFile reports whether path has the extension of a format the
+// scanner reads.
+func IsAudioFile(path string) bool {
+	return audioExts[strings.ToLower(filepath.Ext(path))]
+}
+
+// Tags is what the scanner reads from an audio file. Fields missing from
+// the file are left empty.
+type Tags struct {
+	Title       string
+	Artist      string
+	Album       string
+	AlbumArtist string
+	Genre       string
+	Track       int
+	Year        int
+	Duration    time.Duration
+}
+
+// ReadTags reads the tags and duration of an MP3, FLAC or Ogg Vorbis file.
+func ReadTags(path string) (*Tags, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := &Tags{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp3":
+		err = readMP3(f, info.Size(), tags)
+	case ".flac":
+		err = readFLAC(f, tags)
+	case ".ogg", ".oga":
+		err = readOgg(f, info.Size(), tags)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	return tags, nil
+}
+
+// set stores value under its Vorbis comment field name; ID3 frames are
+// mapped onto the same names. The first value of a field wins.
+func (t *Tags) set(field, value string) {
+	value = strings.TrimSpace(strings.Trim(value, "\x00"))
+	if value == "" {
+		return
+	}
+
+	switch strings.ToUpper(field) {
+	case "TITLE":
+		setOnce(&t.Title, value)
+	case "ARTIST":
+		setOnce(&t.Artist, value)
+	case "ALBUM":
+		setOnce(&t.Album, value)
+	case "ALBUMARTIST", "ALBUM ARTIST":
+		setOnce(&t.AlbumArtist, value)
+	case "GENRE":
+		setOnce(&t.Genre, genreName(value))
+	case "TRACKNUMBER":
+		if t.Track == 0 {
+			t.Track = leadingInt(value)
+		}
+	case "DATE", "YEAR":
+		if t.Year == 0 && len(value) >= 4 {
+			t.Year, _ = strconv.Atoi(value[:4])
+		}
+	}
+}
+
+func setOnce(field *string, value string) {
+	if *field == "" {
+		*field = value
+	}
+}
+
+// leadingInt parses the number at the start of s, such as the 3 of "3/12".
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// genreName resolves ID3 genre references such as "17" or "(17)" to the
+// standard genre names; anything else is kept as written.
+func genreName(value string) string {
+	ref := value
+	if strings.HasPrefix(ref, "(") {
+		end := strings.Index(ref, ")")
+		if end < 0 {
+			return value
+		}
+		if rest := strings.TrimSpace(ref[end+1:]); rest != "" {
+			return rest
+		}
+		ref = ref[1:end]
+	}
+	n, err := strconv.Atoi(ref)
+	if err != nil {
+		return value
+	}
+	if n >= 0 && n < len(id3Genres) {
+		return id3Genres[n]
+	}
+	return ""
+}
+
+// id3Genres are the genres of ID3v1, which ID3v2 tags may refer to by
+// number.
+var id3Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge",
+	"Hip-Hop", "Jazz", "Metal", "New Age", "Oldies", "Other", "Pop", "R&B",
+	"Rap", "Reggae", "Rock", "Techno", "Industrial", "Alternative", "Ska",
+	"Death Metal", "Pranks", "Soundtrack", "Euro-Techno", "Ambient",
+	"Trip-Hop", "Vocal", "Jazz+Funk", "Fusion", "Trance", "Classical",
+	"Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"Alternative Rock", "Bass", "Soul", "Punk", "Space", "Meditative",
+	"Instrumental Pop", "Instrumental Rock", "Ethnic", "Gothic", "Darkwave",
+	"Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream",
+	"Southern Rock", "Comedy", "Cult", "Gangsta", "Top 40", "Christian Rap",
+	"Pop/Funk", "Jungle", "Native American", "Cabaret", "New Wave",
+	"Psychedelic", "Rave", "Showtunes", "Trailer", "Lo-Fi", "Tribal",
+	"Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll",
+	"Hard Rock",
+}
@@ -0,0 +1,191 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxCommentSize bounds the comment block read into memory; embedded cover
+// art makes them large, but not this large.
+const maxCommentSize = 16 << 20
+
+// parseVorbisComment reads a Vorbis comment block, in which FLAC and Ogg
+// Vorbis files keep their tags.
+func parseVorbisComment(data []byte, t *Tags) {
+	if len(data) < 8 {
+		return
+	}
+	vendor := int(binary.LittleEndian.Uint32(data))
+	if 4+vendor+4 > len(data) {
+		return
+	}
+	data = data[4+vendor:]
+	count := int(binary.LittleEndian.Uint32(data))
+	data = data[4:]
+
+	for i := 0; i < count && len(data) >= 4; i++ {
+		n := int(binary.LittleEndian.Uint32(data))
+		if 4+n > len(data) {
+			return
+		}
+		if field, value, ok := strings.Cut(string(data[4:4+n]), "="); ok {
+			t.set(field, value)
+		}
+		data = data[4+n:]
+	}
+}
+
+// readFLAC reads the STREAMINFO and VORBIS_COMMENT blocks of a FLAC file.
+func readFLAC(r io.ReadSeeker, t *Tags) error {
+	if _, err := readID3v2(r, t); err != nil {
+		return err
+	}
+
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return err
+	}
+	if string(magic[:]) != "fLaC" {
+		return errors.New("not a FLAC stream")
+	}
+
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return err
+		}
+		last, kind := header[0]&0x80 != 0, header[0]&0x7f
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		switch {
+		case kind == 0 && length >= 18:
+			block := make([]byte, length)
+			if _, err := io.ReadFull(r, block); err != nil {
+				return err
+			}
+			rate := int64(block[10])<<12 | int64(block[11])<<4 | int64(block[12])>>4
+			samples := int64(block[13]&0x0f)<<32 | int64(binary.BigEndian.Uint32(block[14:18]))
+			if rate > 0 {
+				t.Duration = time.Duration(samples * int64(time.Second) / rate)
+			}
+		case kind == 4 && length <= maxCommentSize:
+			block := make([]byte, length)
+			if _, err := io.ReadFull(r, block); err != nil {
+				return err
+			}
+			parseVorbisComment(block, t)
+		default:
+			if _, err := r.Seek(length, io.SeekCurrent); err != nil {
+				return err
+			}
+		}
+		if last {
+			return nil
+		}
+	}
+}
+
+// oggTailSearch is how much of the end of an Ogg file is searched for the
+// last page, whose granule position gives the duration.
+const oggTailSearch = 64 * 1024
+
+// readOgg reads the identification and comment headers of an Ogg Vorbis
+// file, the first two packets of its stream. Other codecs in an Ogg
+// container, such as Opus, are not supported.
+func readOgg(r io.ReadSeeker, size int64, t *Tags) error {
+	var packets [][]byte
+	var packet []byte
+	var serial uint32
+
+	for len(packets) < 2 {
+		var header [27]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return fmt.Errorf("read page: %w", err)
+		}
+		if string(header[:4]) != "OggS" {
+			return errors.New("not an Ogg stream")
+		}
+		if len(packets) == 0 && packet == nil {
+			serial = binary.LittleEndian.Uint32(header[14:])
+		}
+
+		segments := make([]byte, header[26])
+		if _, err := io.ReadFull(r, segments); err != nil {
+			return err
+		}
+		body := 0
+		for _, s := range segments {
+			body += int(s)
+		}
+		data := make([]byte, body)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return err
+		}
+		if binary.LittleEndian.Uint32(header[14:]) != serial {
+			continue
+		}
+
+		for _, s := range segments {
+			packet = append(packet, data[:s]...)
+			data = data[s:]
+			if len(packet) > maxCommentSize {
+				return errors.New("header packet too large")
+			}
+			if s < 255 {
+				packets = append(packets, packet)
+				packet = nil
+				if len(packets) == 2 {
+					break
+				}
+			}
+		}
+	}
+
+	ident, comment := packets[0], packets[1]
+	if !bytes.HasPrefix(ident, []byte("\x01vorbis")) || len(ident) < 16 {
+		return ErrUnsupported
+	}
+	rate := int64(binary.LittleEndian.Uint32(ident[12:]))
+	if bytes.HasPrefix(comment, []byte("\x03vorbis")) {
+		parseVorbisComment(comment[7:], t)
+	}
+
+	if granule := lastGranule(r, size, serial); granule > 0 && rate > 0 {
+		t.Duration = time.Duration(granule * int64(time.Second) / rate)
+	}
+	return nil
+}
+
+// lastGranule returns the granule position of the last page of the stream,
+// the number of samples in it, or 0 if it cannot be found.
+func lastGranule(r io.ReadSeeker, size int64, serial uint32) int64 {
+	start := size - oggTailSearch
+	if start < 0 {
+		start = 0
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return 0
+	}
+	tail, err := io.ReadAll(r)
+	if err != nil {
+		return 0
+	}
+
+	for i := len(tail) - 27; i >= 0; i-- {
+		if tail[i] != 'O' || string(tail[i:i+4]) != "OggS" {
+			continue
+		}
+		if binary.LittleEndian.Uint32(tail[i+14:]) != serial {
+			continue
+		}
+		if granule := int64(binary.LittleEndian.Uint64(tail[i+6:])); granule >= 0 {
+			return granule
+		}
+	}
+	return 0
+}
@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"context"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchSettle is how long the folders must be quiet after a change before
+// they are scanned, so copying an album in causes one scan, not one per file.
+const watchSettle = 3 * time.Second
+
+// Watch scans the folders, then scans again whenever something in them
+// changes, until ctx is done.
+func (s *Scanner) Watch(ctx context.Context) error {
+	if _, err := s.Scan(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("[SCANNER] Scan failed: %v", err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = watcher.Close() }()
+
+	for _, folder := range s.folders {
+		s.watchTree(watcher, folder)
+	}
+
+	settle := time.NewTimer(watchSettle)
+	settle.Stop()
+	defer settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					s.watchTree(watcher, event.Name)
+				}
+			}
+			if event.Has(fsnotify.Chmod) {
+				continue
+			}
+			settle.Reset(watchSettle)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("[SCANNER] Watch error: %v", err)
+		case <-settle.C:
+			if _, err := s.Scan(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("[SCANNER] Scan failed: %v", err)
+			}
+		}
+	}
+}
+
+// watchTree watches root and every folder below it, since fsnotify does not
+// watch recursively.
+func (s *Scanner) watchTree(watcher *fsnotify.Watcher, root string) {
+	_ = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if err := watcher.Add(path); err != nil && s.debug {
+			log.Printf("[SCANNER] Cannot watch %s: %v", path, err)
+		}
+		return nil
+	})
+}
@@ -43,7 +43,9 @@ func NewSearchEngine(cfg *config.Config, storage types.Storage) *SearchEngine {
 	}
 }
 
-// Search performs a comprehensive search across all content types
+// Search finds songs, albums and authors through the database's full-text
+// index, best matches first. When that finds fewer than limit songs, fuzzy
+// matches are added after them so typos still find something.
 func (e *SearchEngine) Search(ctx context.Context, query string, limit int) (*types.SearchResults, error) {
 	if query == "" {
 		return &types.SearchResults{}, nil
@@ -53,20 +55,32 @@ func (e *SearchEngine) Search(ctx context.Context, query string, limit int) (*ty
 	if err != nil {
 		return nil, err
 	}
-
-	fuzzyResults, err := e.FuzzySearch(ctx, query, limit)
+	albums, err := e.storage.SearchAlbums(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	authors, err := e.storage.SearchAuthors(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
 
 	results := &types.SearchResults{
-		Songs: mergeSongs(songs, fuzzyResults.Songs),
-		Total: len(songs),
+		Songs:   songs,
+		Albums:  albums,
+		Authors: authors,
+		Total:   len(songs) + len(albums) + len(authors),
+	}
+	if len(songs) >= limit {
+		return results, nil
 	}
 
-	if len(results.Songs) > limit {
-		results.Songs = results.Songs[:limit]
+	fuzzyResults, err := e.FuzzySearch(ctx, query, limit)
+	if err != nil {
+		return nil, err
 	}
+	results.Songs = truncate(mergeSongs(songs, fuzzyResults.Songs), limit)
+	results.Albums = truncate(mergeAlbums(albums, fuzzyResults.Albums), limit)
+	results.Authors = truncate(mergeAuthors(authors, fuzzyResults.Authors), limit)
 
 	return results, nil
 }
@@ -233,3 +247,42 @@ func mergeSongs(songs1, songs2 []*types.Song) []*types.Song {
 
 	return result
 }
+
+func mergeAlbums(albums1, albums2 []*types.Album) []*types.Album {
+	seen := make(map[string]bool)
+	var result []*types.Album
+
+	for _, list := range [][]*types.Album{albums1, albums2} {
+		for _, album := range list {
+			if !seen[album.Slug] {
+				result = append(result, album)
+				seen[album.Slug] = true
+			}
+		}
+	}
+
+	return result
+}
+
+func mergeAuthors(authors1, authors2 []*types.Author) []*types.Author {
+	seen := make(map[string]bool)
+	var result []*types.Author
+
+	for _, list := range [][]*types.Author{authors1, authors2} {
+		for _, author := range list {
+			if !seen[author.Slug] {
+				result = append(result, author)
+				seen[author.Slug] = true
+			}
+		}
+	}
+
+	return result
+}
+
+func truncate[T any](items []T, limit int) []T {
+	if len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+var benchWords = []string{
+	"midnight", "river", "echo", "neon", "summer", "glass", "velvet", "static",
+	"horizon", "ember", "silver", "hollow", "paper", "thunder", "violet", "drift",
+}
+
+// BenchmarkSearch runs the full search path: the full-text lookups and, for
+// queries that come up short, the fuzzy fallback over the library.
+func BenchmarkSearch(b *testing.B) {
+	dir := b.TempDir()
+	cfg := &config.Config{}
+	cfg.Storage.DatabasePath = filepath.Join(dir, "bench.db")
+	cfg.Storage.CacheDir = filepath.Join(dir, "cache")
+	cfg.Storage.EnableWAL = true
+
+	db, err := storage.NewDatabase(cfg)
+	if err != nil {
+		b.Fatalf("open database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	songs := make([]*types.Song, 2000)
+	for i := range songs {
+		author := &types.Author{
+			Slug: fmt.Sprintf("bench-author-%d", i%50),
+			Name: benchWords[i%len(benchWords)] + " band",
+		}
+		songs[i] = &types.Song{
+			Slug: fmt.Sprintf("bench-song-%d", i),
+			Name: benchWords[i%len(benchWords)] + " " + benchWords[(i*7)%len(benchWords)],
+			File: fmt.Sprintf("https://example.com/songs/%d.mp3", i),
+			Album: &types.Album{
+				Slug:    fmt.Sprintf("bench-album-%d", i/10),
+				Name:    benchWords[(i/10)%len(benchWords)] + " sessions",
+				Artists: []*types.Author{author},
+			},
+			Authors: []*types.Author{author},
+		}
+	}
+	if err := db.SaveSongsBatch(ctx, songs); err != nil {
+		b.Fatalf("seed songs: %v", err)
+	}
+
+	engine := NewSearchEngine(cfg, db)
+	queries := []string{"river", "hol", "velvet thunder", "rivr", "zzznothing"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := engine.Search(ctx, queries[i%len(queries)], 50); err != nil {
+			b.Fatalf("search: %v", err)
+		}
+	}
+}
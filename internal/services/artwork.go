@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// maxArtworkSize bounds images picked as artwork so a stray photo straight
+// off a camera doesn't end up decoded for every card.
+const maxArtworkSize = 20 << 20
+
+// artworkOverrides keeps the user's own artwork choices. The images are
+// copied into dir so they survive the original file being moved.
+type artworkOverrides struct {
+	mu        sync.RWMutex
+	store     *storage.Database
+	dir       string
+	paths     map[string]string
+	listeners []func(kind types.ArtworkKind, slug string)
+}
+
+func artworkKey(kind types.ArtworkKind, slug string) string {
+	return string(kind) + ":" + slug
+}
+
+// EnableArtworkOverrides loads the saved artwork overrides and stores new
+// ones under dir. Overrides whose image went missing are dropped.
+func (s *ImageService) EnableArtworkOverrides(ctx context.Context, store *storage.Database, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create artwork directory: %w", err)
+	}
+
+	overrides, err := store.GetArtworkOverrides(ctx)
+	if err != nil {
+		return fmt.Errorf("load artwork overrides: %w", err)
+	}
+
+	artwork := &artworkOverrides{store: store, dir: dir, paths: make(map[string]string, len(overrides))}
+	for _, override := range overrides {
+		if _, err := os.Stat(override.Path); err != nil {
+			log.Printf("[IMAGE_SERVICE] Artwork for %s %s is missing, using the server's: %v", override.Kind, override.Slug, err)
+			if err := store.DeleteArtworkOverride(ctx, override.Kind, override.Slug); err != nil {
+				log.Printf("[IMAGE_SERVICE] Failed to drop artwork override: %v", err)
+			}
+			continue
+		}
+		artwork.paths[artworkKey(override.Kind, override.Slug)] = override.Path
+	}
+	s.artwork = artwork
+	return nil
+}
+
+// ArtworkURL returns the user's artwork for the item when there is one, and
+// url otherwise.
+func (s *ImageService) ArtworkURL(kind types.ArtworkKind, slug, url string) string {
+	if path := s.artworkPath(kind, slug); path != "" {
+		return "file://" + path
+	}
+	return url
+}
+
+// HasArtworkOverride reports whether the user replaced the item's artwork.
+func (s *ImageService) HasArtworkOverride(kind types.ArtworkKind, slug string) bool {
+	return s.artworkPath(kind, slug) != ""
+}
+
+func (s *ImageService) artworkPath(kind types.ArtworkKind, slug string) string {
+	if s == nil || s.artwork == nil || slug == "" {
+		return ""
+	}
+	s.artwork.mu.RLock()
+	defer s.artwork.mu.RUnlock()
+	return s.artwork.paths[artworkKey(kind, slug)]
+}
+
+// OnArtworkChanged registers fn to run after an item's artwork was replaced
+// or restored. It runs on the goroutine that made the change.
+func (s *ImageService) OnArtworkChanged(fn func(kind types.ArtworkKind, slug string)) {
+	if s == nil || s.artwork == nil {
+		return
+	}
+	s.artwork.mu.Lock()
+	s.artwork.listeners = append(s.artwork.listeners, fn)
+	s.artwork.mu.Unlock()
+}
+
+// SetArtwork replaces the item's artwork with the image read from r. name is
+// the original file name and only used for its extension.
+func (s *ImageService) SetArtwork(ctx context.Context, kind types.ArtworkKind, slug string, r io.Reader, name string) error {
+	if s.artwork == nil {
+		return fmt.Errorf("artwork overrides are not enabled")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxArtworkSize+1))
+	if err != nil {
+		return fmt.Errorf("read image: %w", err)
+	}
+	if len(data) > maxArtworkSize {
+		return fmt.Errorf("image is larger than %d MB", maxArtworkSize>>20)
+	}
+	if _, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("decode image: %w", err)
+	}
+
+	// A new file name per change keeps every cache from serving the old image.
+	hash := sha256.Sum256([]byte(artworkKey(kind, slug)))
+	path := filepath.Join(s.artwork.dir, fmt.Sprintf("%s-%x-%d%s",
+		kind, hash[:8], time.Now().UnixNano(), strings.ToLower(filepath.Ext(name))))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("save image: %w", err)
+	}
+
+	override := &types.ArtworkOverride{Kind: kind, Slug: slug, Path: path}
+	if err := s.artwork.store.SaveArtworkOverride(ctx, override); err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+
+	s.replaceArtworkPath(kind, slug, path)
+	return nil
+}
+
+// RefetchArtwork drops the user's artwork for the item, if any, and forgets
+// every cached copy of url so the server's image is downloaded again.
+func (s *ImageService) RefetchArtwork(ctx context.Context, kind types.ArtworkKind, slug, url string) error {
+	if s.artwork != nil && s.HasArtworkOverride(kind, slug) {
+		if err := s.artwork.store.DeleteArtworkOverride(ctx, kind, slug); err != nil {
+			return err
+		}
+	}
+
+	s.Invalidate(url)
+	if s.artwork != nil {
+		s.replaceArtworkPath(kind, slug, "")
+	}
+	return nil
+}
+
+func (s *ImageService) replaceArtworkPath(kind types.ArtworkKind, slug, path string) {
+	key := artworkKey(kind, slug)
+
+	s.artwork.mu.Lock()
+	previous := s.artwork.paths[key]
+	if path == "" {
+		delete(s.artwork.paths, key)
+	} else {
+		s.artwork.paths[key] = path
+	}
+	listeners := append([]func(types.ArtworkKind, string){}, s.artwork.listeners...)
+	s.artwork.mu.Unlock()
+
+	if previous != "" {
+		s.Invalidate("file://" + previous)
+		if err := os.Remove(previous); err != nil && !os.IsNotExist(err) {
+			log.Printf("[IMAGE_SERVICE] Failed to remove old artwork %s: %v", previous, err)
+		}
+	}
+
+	for _, fn := range listeners {
+		fn(kind, slug)
+	}
+}
+
+// Invalidate drops url from the memory caches at every size, and from the
+// loader's caches so the next request loads it afresh.
+func (s *ImageService) Invalidate(url string) {
+	if url == "" {
+		return
+	}
+
+	s.cache.Range(func(key, _ interface{}) bool {
+		if k, ok := key.(string); ok && (k == url || strings.HasPrefix(k, url+"@")) {
+			s.cache.Delete(key)
+		}
+		return true
+	})
+	if s.loader != nil {
+		s.loader.Forget(url)
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/integrations/cast"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// castDiscoverTime is how long a search for renderers listens for answers.
+const castDiscoverTime = 3 * time.Second
+
+// ErrNotCasting is returned by the playback methods while no renderer is
+// connected.
+var ErrNotCasting = errors.New("not casting")
+
+// CastService plays songs on a Chromecast or DLNA renderer instead of the
+// speakers. Songs on this machine are lent to the renderer over HTTP;
+// others are fetched by the renderer from the server.
+type CastService struct {
+	server *cast.Server
+	debug  bool
+
+	mu       sync.Mutex
+	device   *cast.Device
+	renderer cast.Renderer
+}
+
+func NewCastService(cfg *config.Config) *CastService {
+	return &CastService{server: cast.NewServer(cfg.Cast.ServerPort), debug: cfg.Debug}
+}
+
+// Discover searches the network for renderers.
+func (s *CastService) Discover(ctx context.Context) ([]cast.Device, error) {
+	ctx, cancel := context.WithTimeout(ctx, castDiscoverTime)
+	defer cancel()
+	devices, err := cast.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.debugLog("Found %d renderers", len(devices))
+	return devices, nil
+}
+
+// Connect starts casting to device, ending any session with another.
+func (s *CastService) Connect(ctx context.Context, device cast.Device) error {
+	renderer, err := cast.Connect(ctx, device)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	previous := s.renderer
+	s.device, s.renderer = &device, renderer
+	s.mu.Unlock()
+
+	if previous != nil {
+		_ = previous.Close()
+	}
+	log.Printf("[CAST] Casting to %s (%s)", device.Name, device.Kind)
+	return nil
+}
+
+// Disconnect stops the renderer and ends the session.
+func (s *CastService) Disconnect(ctx context.Context) {
+	s.mu.Lock()
+	renderer, device := s.renderer, s.device
+	s.device, s.renderer = nil, nil
+	s.mu.Unlock()
+	if renderer == nil {
+		return
+	}
+
+	if err := renderer.Stop(ctx); err != nil {
+		s.debugLog("Failed to stop %s: %v", device.Name, err)
+	}
+	if err := renderer.Close(); err != nil {
+		s.debugLog("Failed to close %s: %v", device.Name, err)
+	}
+	if err := s.server.Close(); err != nil {
+		s.debugLog("Failed to close the cast server: %v", err)
+	}
+	log.Printf("[CAST] Stopped casting to %s", device.Name)
+}
+
+// Device returns the renderer cast to, or nil.
+func (s *CastService) Device() *cast.Device {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.device
+}
+
+// Play loads song on the renderer from start. file is where the song is on
+// this machine, empty to let the renderer stream it from the server.
+func (s *CastService) Play(ctx context.Context, song *types.Song, file string, start time.Duration) error {
+	renderer, device, err := s.current()
+	if err != nil {
+		return err
+	}
+
+	media := cast.Media{
+		Title:    song.Name,
+		Artist:   song.AlbumArtist(),
+		Duration: time.Duration(song.Length) * time.Second,
+	}
+	if song.Album != nil {
+		media.Album = song.Album.Name
+	}
+	if song.Image != nil && isWebURL(*song.Image) {
+		media.Cover = *song.Image
+	}
+
+	switch {
+	case file != "":
+		media.URL, err = s.server.URL(file, device.Host())
+		if err != nil {
+			return err
+		}
+		media.ContentType = cast.ContentType(file)
+	case isWebURL(song.File) && !song.IsLocalOnly():
+		media.URL = song.File
+		parsed, _ := url.Parse(song.File)
+		media.ContentType = cast.ContentType(parsed.Path)
+	default:
+		return fmt.Errorf("%s has no file to cast", song.Name)
+	}
+
+	s.debugLog("Loading %s on %s from %s", song.Name, device.Name, media.URL)
+	if err := renderer.Load(ctx, media, start); err != nil {
+		return fmt.Errorf("cast %s: %w", song.Name, err)
+	}
+	return nil
+}
+
+func (s *CastService) Pause(ctx context.Context) error {
+	renderer, _, err := s.current()
+	if err != nil {
+		return err
+	}
+	return renderer.Pause(ctx)
+}
+
+func (s *CastService) Resume(ctx context.Context) error {
+	renderer, _, err := s.current()
+	if err != nil {
+		return err
+	}
+	return renderer.Play(ctx)
+}
+
+func (s *CastService) Stop(ctx context.Context) error {
+	renderer, _, err := s.current()
+	if err != nil {
+		return err
+	}
+	return renderer.Stop(ctx)
+}
+
+func (s *CastService) Seek(ctx context.Context, pos time.Duration) error {
+	renderer, _, err := s.current()
+	if err != nil {
+		return err
+	}
+	return renderer.Seek(ctx, pos)
+}
+
+// Status returns where the renderer is in the song.
+func (s *CastService) Status(ctx context.Context) (cast.Status, error) {
+	renderer, _, err := s.current()
+	if err != nil {
+		return cast.Status{}, err
+	}
+	return renderer.Status(ctx)
+}
+
+func (s *CastService) current() (cast.Renderer, *cast.Device, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.renderer == nil {
+		return nil, nil, ErrNotCasting
+	}
+	return s.renderer, s.device, nil
+}
+
+func isWebURL(value string) bool {
+	return strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://")
+}
+
+func (s *CastService) debugLog(format string, args ...interface{}) {
+	if s.debug {
+		log.Printf("[CAST] "+format, args...)
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// croppedCoverMax is the largest size, in either dimension, a cover is shown
+// at from the server's cropped image, a thumbnail about 300px square. Larger
+// covers use the full image. A zero size asks for the thumbnail.
+const croppedCoverMax = 300
+
+// externalCoverSize is the size desktop media controls are assumed to show
+// covers at.
+const externalCoverSize = 512
+
+// SongImageURL returns the server's image for a song shown at size, or its
+// album's when the song has none.
+func SongImageURL(song *types.Song, size fyne.Size) string {
+	if song == nil {
+		return ""
+	}
+	if url := pickImage(song.ImageCropped, song.Image, size); url != "" {
+		return url
+	}
+	return AlbumImageURL(song.Album, size)
+}
+
+// AlbumImageURL returns the server's image for an album shown at size.
+func AlbumImageURL(album *types.Album, size fyne.Size) string {
+	if album == nil {
+		return ""
+	}
+	return pickImage(album.ImageCropped, album.Image, size)
+}
+
+// AuthorImageURL returns the server's image for an author shown at size.
+func AuthorImageURL(author *types.Author, size fyne.Size) string {
+	if author == nil {
+		return ""
+	}
+	return pickImage(author.ImageCropped, author.Image, size)
+}
+
+// pickImage prefers the cropped image up to croppedCoverMax and the full
+// one above it, taking the other when the preferred one is missing.
+func pickImage(cropped, full *string, size fyne.Size) string {
+	first, second := cropped, full
+	if size.Width > croppedCoverMax || size.Height > croppedCoverMax {
+		first, second = full, cropped
+	}
+	if first != nil && *first != "" {
+		return *first
+	}
+	if second != nil {
+		return *second
+	}
+	return ""
+}
+
+// SongCoverURL returns the cover to show for a song at size: the user's own
+// artwork for the song, the server's image for it, the user's artwork for
+// its album, then the server's image for the album.
+func (is *ImageService) SongCoverURL(song *types.Song, size fyne.Size) string {
+	if song == nil {
+		return ""
+	}
+	if url := is.ArtworkURL(types.ArtworkSong, song.Slug, pickImage(song.ImageCropped, song.Image, size)); url != "" {
+		return url
+	}
+	return is.AlbumCoverURL(song.Album, size)
+}
+
+// AlbumCoverURL returns the cover to show for an album at size, the user's
+// own artwork when they picked one.
+func (is *ImageService) AlbumCoverURL(album *types.Album, size fyne.Size) string {
+	if album == nil {
+		return ""
+	}
+	return is.ArtworkURL(types.ArtworkAlbum, album.Slug, AlbumImageURL(album, size))
+}
+
+// AuthorCoverURL returns the picture to show for an author at size, the
+// user's own when they picked one.
+func (is *ImageService) AuthorCoverURL(author *types.Author, size fyne.Size) string {
+	if author == nil {
+		return ""
+	}
+	return is.ArtworkURL(types.ArtworkAuthor, author.Slug, AuthorImageURL(author, size))
+}
+
+// ExternalCoverURL returns the song's cover as a URL other programs, like the
+// desktop's media controls, can load.
+func (is *ImageService) ExternalCoverURL(song *types.Song) string {
+	url := is.SongCoverURL(song, fyne.NewSize(externalCoverSize, externalCoverSize))
+	if url == "" || is.loader == nil {
+		return url
+	}
+	return is.loader.ExternalURL(url)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+)
+
+const (
+	// enrichStartDelay lets startup requests go first.
+	enrichStartDelay = 2 * time.Minute
+	// enrichInterval is how often a batch of stubs is filled in.
+	enrichInterval = 10 * time.Minute
+	// enrichBatchSize is how many albums, and as many authors, a batch
+	// fetches at most.
+	enrichBatchSize = 10
+	// enrichPause spaces the requests of a batch, leaving the rate limiter
+	// to requests the user is waiting for.
+	enrichPause = 3 * time.Second
+	// enrichFreshness is how long fetched details are kept before they are
+	// fetched again.
+	enrichFreshness = 7 * 24 * time.Hour
+)
+
+// noteViewed counts a view for the albums and authors that were just shown,
+// so the enricher fills in the most viewed first.
+func (s *MusicService) noteViewed(ctx context.Context, albums, authors []string) {
+	if err := s.storage.RecordAlbumViews(ctx, albums); err != nil && s.debug {
+		log.Printf("[MUSIC_SERVICE] Failed to record album views: %v", err)
+	}
+	if err := s.storage.RecordAuthorViews(ctx, authors); err != nil && s.debug {
+		log.Printf("[MUSIC_SERVICE] Failed to record author views: %v", err)
+	}
+}
+
+// RunEnrichment fetches the full details of albums and authors known only
+// from song payloads, most viewed first, a batch every enrichInterval, until
+// ctx is done. Details fetched within enrichFreshness are left alone.
+func (s *MusicService) RunEnrichment(ctx context.Context) {
+	timer := time.NewTimer(enrichStartDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if n := s.enrichBatch(ctx); n > 0 && s.debug {
+			log.Printf("[MUSIC_SERVICE] Filled in %d albums and authors", n)
+		}
+		timer.Reset(enrichInterval)
+	}
+}
+
+// enrichBatch fills in one batch of stubs and returns how many it fetched.
+// It stops early when offline.
+func (s *MusicService) enrichBatch(ctx context.Context) int {
+	staleBefore := time.Now().Add(-enrichFreshness)
+
+	albums, err := s.storage.StubAlbums(ctx, staleBefore, enrichBatchSize)
+	if err != nil {
+		log.Printf("[MUSIC_SERVICE] Failed to list album stubs: %v", err)
+	}
+	authors, err := s.storage.StubAuthors(ctx, staleBefore, enrichBatchSize)
+	if err != nil {
+		log.Printf("[MUSIC_SERVICE] Failed to list author stubs: %v", err)
+	}
+
+	fetched := 0
+	for _, slug := range albums {
+		if !s.pauseEnrichment(ctx) {
+			return fetched
+		}
+		album, err := s.api.GetAlbum(ctx, slug)
+		if errors.Is(err, api.ErrOffline) || ctx.Err() != nil {
+			return fetched
+		}
+		if err != nil || album == nil {
+			// Marked anyway, so an album the server fails on does not
+			// hold up the others. Opening it still fetches it.
+			log.Printf("[MUSIC_SERVICE] Failed to fill in album %s: %v", slug, err)
+			_ = s.storage.MarkAlbumDetailed(ctx, slug)
+			continue
+		}
+		s.cacheAlbumWithRelationships(ctx, album)
+		fetched++
+	}
+
+	for _, slug := range authors {
+		if !s.pauseEnrichment(ctx) {
+			return fetched
+		}
+		author, err := s.api.GetAuthor(ctx, slug)
+		if errors.Is(err, api.ErrOffline) || ctx.Err() != nil {
+			return fetched
+		}
+		if err != nil || author == nil {
+			log.Printf("[MUSIC_SERVICE] Failed to fill in author %s: %v", slug, err)
+			_ = s.storage.MarkAuthorDetailed(ctx, slug)
+			continue
+		}
+		s.cacheAuthorWithRelationships(ctx, author)
+		fetched++
+	}
+	return fetched
+}
+
+// pauseEnrichment waits enrichPause before the next request and reports
+// whether enrichment should go on.
+func (s *MusicService) pauseEnrichment(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(enrichPause):
+		return true
+	}
+}
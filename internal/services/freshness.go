@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// How long a detailed copy in storage is served as is. An older copy is
+// still served, while a fresh one is fetched in the background for next
+// time.
+const (
+	songTTL     = 30 * time.Minute
+	albumTTL    = time.Hour
+	authorTTL   = time.Hour
+	playlistTTL = 2 * time.Minute
+
+	// refreshTimeout bounds a background refresh.
+	refreshTimeout = 30 * time.Second
+)
+
+type forceRefreshKey struct{}
+
+// ForceRefresh returns a context under which GetSong, GetAlbum, GetAuthor
+// and GetPlaylist skip storage and ask the server.
+func ForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshKey{}, true)
+}
+
+func forcedRefresh(ctx context.Context) bool {
+	forced, _ := ctx.Value(forceRefreshKey{}).(bool)
+	return forced
+}
+
+// storedAlbum returns the stored album with its songs when its details were
+// fetched before, or nil when the server has to be asked.
+func (s *MusicService) storedAlbum(ctx context.Context, slug string) *types.Album {
+	if forcedRefresh(ctx) {
+		return nil
+	}
+	detailedAt, err := s.storage.AlbumDetailedAt(ctx, slug)
+	if err != nil || detailedAt.IsZero() {
+		return nil
+	}
+	album, err := s.storage.GetAlbum(ctx, slug)
+	if err != nil || album == nil {
+		return nil
+	}
+	songs, err := s.getAlbumSongsFromStorage(ctx, slug)
+	if err != nil {
+		return nil
+	}
+	if s.IsOffline() {
+		songs = availableSongs(songs)
+	}
+	album.Songs = songs
+
+	s.refreshIfStale("album:"+slug, detailedAt, albumTTL, func(ctx context.Context) error {
+		album, err := s.api.GetAlbum(ctx, slug)
+		if err != nil {
+			return err
+		}
+		s.cacheAlbumWithRelationships(ctx, album)
+		return nil
+	})
+	return album
+}
+
+// storedAuthor returns the stored author with their songs and albums when
+// their details were fetched before, or nil when the server has to be asked.
+func (s *MusicService) storedAuthor(ctx context.Context, slug string) *types.Author {
+	if forcedRefresh(ctx) {
+		return nil
+	}
+	detailedAt, err := s.storage.AuthorDetailedAt(ctx, slug)
+	if err != nil || detailedAt.IsZero() {
+		return nil
+	}
+	author, err := s.storage.GetAuthor(ctx, slug)
+	if err != nil || author == nil {
+		return nil
+	}
+	songs, albums := s.getAuthorContentFromStorage(ctx, slug)
+	if s.IsOffline() {
+		songs, albums = availableSongs(songs), albumsOf(songs)
+	}
+	author.Songs = songs
+	author.Albums = albums
+
+	s.refreshIfStale("author:"+slug, detailedAt, authorTTL, func(ctx context.Context) error {
+		author, err := s.api.GetAuthor(ctx, slug)
+		if err != nil {
+			return err
+		}
+		s.cacheAuthorWithRelationships(ctx, author)
+		return nil
+	})
+	return author
+}
+
+// storedSong returns the stored song when it was last fetched on its own,
+// or nil when the server has to be asked.
+func (s *MusicService) storedSong(ctx context.Context, slug string) *types.Song {
+	if forcedRefresh(ctx) {
+		return nil
+	}
+	song, err := s.storage.GetSong(ctx, slug)
+	if err != nil || song == nil || song.LastSync.IsZero() {
+		return nil
+	}
+
+	s.refreshIfStale("song:"+slug, song.LastSync, songTTL, func(ctx context.Context) error {
+		song, err := s.api.GetSong(ctx, slug)
+		if err != nil || song == nil {
+			return err
+		}
+		s.ensureSongVolumeSaved(ctx, song)
+		song.LastSync = time.Now()
+		s.cacheSongWithRelationships(ctx, song)
+		return nil
+	})
+	return song
+}
+
+// storedPlaylist returns the stored playlist when it was last fetched on its
+// own, or nil when the server has to be asked.
+func (s *MusicService) storedPlaylist(ctx context.Context, slug string) *types.Playlist {
+	if forcedRefresh(ctx) {
+		return nil
+	}
+	playlist, err := s.storage.GetPlaylist(ctx, slug)
+	if err != nil || playlist == nil || playlist.LocalOnly || playlist.LastSync.IsZero() {
+		return nil
+	}
+
+	s.refreshIfStale("playlist:"+slug, playlist.LastSync, playlistTTL, func(ctx context.Context) error {
+		playlist, err := s.api.GetPlaylist(ctx, slug)
+		if err != nil || playlist == nil {
+			return err
+		}
+		playlist.LastSync = time.Now()
+		s.cachePlaylistWithRelationships(ctx, playlist)
+		return nil
+	})
+	return playlist
+}
+
+// refreshIfStale runs refresh in the background once the copy saved at
+// savedAt is older than ttl. Only one refresh per key runs at a time, and
+// none while offline.
+func (s *MusicService) refreshIfStale(key string, savedAt time.Time, ttl time.Duration, refresh func(context.Context) error) {
+	if time.Since(savedAt) < ttl || s.IsOffline() {
+		return
+	}
+	if _, running := s.refreshing.LoadOrStore(key, true); running {
+		return
+	}
+
+	go func() {
+		defer s.refreshing.Delete(key)
+
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		if err := refresh(ctx); err != nil && s.debug {
+			log.Printf("[MUSIC_SERVICE] Failed to refresh %s: %v", key, err)
+		}
+	}()
+}
@@ -1,9 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"github.com/Alexander-D-Karpov/amp/pkg/types"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -21,6 +22,9 @@ type ImageService struct {
 	fallback   fyne.Resource
 	debug      bool
 	maxRetries int
+	loadSlots  chan struct{}
+	artwork    *artworkOverrides
+	collageDir string
 }
 
 type CacheEntry struct {
@@ -31,16 +35,28 @@ type CacheEntry struct {
 }
 
 type CallbackList struct {
-	callbacks []func(fyne.Resource, error)
+	callbacks []imageCallback
 	mutex     sync.Mutex
 }
 
+// imageCallback is a waiting caller; ctx is cancelled once the caller no
+// longer needs the image, such as a card that scrolled away or was rebuilt.
+type imageCallback struct {
+	ctx context.Context
+	fn  func(fyne.Resource, error)
+}
+
+// maxSizedLoads bounds concurrent sized image loads so a fast scroll queues
+// requests instead of starting hundreds of downloads at once.
+const maxSizedLoads = 6
+
 func NewImageService(loader *media.ImageLoader) *ImageService {
 	return &ImageService{
 		loader:     loader,
 		fallback:   theme.MediaMusicIcon(),
 		debug:      false, // Reduced debug logging
 		maxRetries: 3,
+		loadSlots:  make(chan struct{}, maxSizedLoads),
 	}
 }
 
@@ -104,7 +120,10 @@ func (s *ImageService) generateCacheKey(url string, size fyne.Size) string {
 	return fmt.Sprintf("%s@%dx%d", url, int(size.Width), int(size.Height))
 }
 
-func (s *ImageService) GetImageWithSize(url string, size fyne.Size, callback func(fyne.Resource, error)) fyne.Resource {
+// GetImageWithSize returns the cached image for url at size, or the fallback
+// while it loads and calls callback once it is ready. Cancelling ctx drops
+// the callback, and the load itself if nobody else is waiting for it.
+func (s *ImageService) GetImageWithSize(ctx context.Context, url string, size fyne.Size, callback func(fyne.Resource, error)) fyne.Resource {
 	if url == "" {
 		if callback != nil {
 			fyne.Do(func() { callback(s.fallback, nil) })
@@ -126,7 +145,7 @@ func (s *ImageService) GetImageWithSize(url string, size fyne.Size, callback fun
 	}
 
 	if callback != nil {
-		s.addCallback(cacheKey, callback)
+		s.addCallbackWithContext(cacheKey, ctx, callback)
 	}
 
 	if _, loading := s.loading.LoadOrStore(cacheKey, struct{}{}); loading {
@@ -138,6 +157,15 @@ func (s *ImageService) GetImageWithSize(url string, size fyne.Size, callback fun
 }
 
 func (s *ImageService) loadImageWithSizeAsync(url string, size fyne.Size, cacheKey string) {
+	s.loadSlots <- struct{}{}
+	defer func() { <-s.loadSlots }()
+
+	if s.dropIfAbandoned(cacheKey) {
+		if s.debug {
+			log.Printf("[IMAGE_SERVICE] Dropped image request nobody is waiting for: %s", cacheKey)
+		}
+		return
+	}
 	defer s.loading.Delete(cacheKey)
 
 	startTime := time.Now()
@@ -157,7 +185,7 @@ func (s *ImageService) loadImageWithSizeAsync(url string, size fyne.Size, cacheK
 		resource:  resource,
 		timestamp: time.Now(),
 		url:       url,
-		size:      0,
+		size:      resourceSize(resource),
 	}
 	s.cache.Store(cacheKey, entry)
 
@@ -165,6 +193,10 @@ func (s *ImageService) loadImageWithSizeAsync(url string, size fyne.Size, cacheK
 }
 
 func (s *ImageService) addCallback(key string, callback func(fyne.Resource, error)) {
+	s.addCallbackWithContext(key, context.Background(), callback)
+}
+
+func (s *ImageService) addCallbackWithContext(key string, ctx context.Context, callback func(fyne.Resource, error)) {
 	if callback == nil {
 		return
 	}
@@ -172,11 +204,37 @@ func (s *ImageService) addCallback(key string, callback func(fyne.Resource, erro
 	value, _ := s.callbacks.LoadOrStore(key, &CallbackList{})
 	if callbackList, ok := value.(*CallbackList); ok {
 		callbackList.mutex.Lock()
-		callbackList.callbacks = append(callbackList.callbacks, callback)
+		callbackList.callbacks = append(callbackList.callbacks, imageCallback{ctx: ctx, fn: callback})
 		callbackList.mutex.Unlock()
 	}
 }
 
+// dropIfAbandoned gives up a queued load when every caller waiting for it
+// has cancelled. The waiting list stays registered, and loading is cleared
+// under its lock, so a request arriving meanwhile starts a fresh load.
+func (s *ImageService) dropIfAbandoned(key string) bool {
+	value, ok := s.callbacks.Load(key)
+	if !ok {
+		return false
+	}
+	callbackList, ok := value.(*CallbackList)
+	if !ok {
+		return false
+	}
+
+	callbackList.mutex.Lock()
+	defer callbackList.mutex.Unlock()
+
+	for _, callback := range callbackList.callbacks {
+		if callback.ctx.Err() == nil {
+			return false
+		}
+	}
+	callbackList.callbacks = nil
+	s.loading.Delete(key)
+	return true
+}
+
 func (s *ImageService) loadImageAsync(url string, priorityCallback func(fyne.Resource, error)) {
 	defer s.loading.Delete(url)
 
@@ -214,7 +272,7 @@ func (s *ImageService) loadImageAsync(url string, priorityCallback func(fyne.Res
 		resource:  resource,
 		timestamp: time.Now(),
 		url:       url,
-		size:      0,
+		size:      resourceSize(resource),
 	}
 	s.cache.Store(url, entry)
 
@@ -231,16 +289,17 @@ func (s *ImageService) notifyCallbacks(key string, resource fyne.Resource, err e
 	if value, ok := s.callbacks.LoadAndDelete(key); ok {
 		if callbackList, ok := value.(*CallbackList); ok {
 			callbackList.mutex.Lock()
-			callbacks := make([]func(fyne.Resource, error), len(callbackList.callbacks))
+			callbacks := make([]imageCallback, len(callbackList.callbacks))
 			copy(callbacks, callbackList.callbacks)
 			callbackList.mutex.Unlock()
 
 			for _, callback := range callbacks {
-				if callback != nil {
-					fyne.Do(func() {
-						callback(resource, err)
-					})
+				if callback.fn == nil || callback.ctx.Err() != nil {
+					continue
 				}
+				fyne.Do(func() {
+					callback.fn(resource, err)
+				})
 			}
 		}
 	}
@@ -332,6 +391,72 @@ func (s *ImageService) CleanupOldEntries(maxAge time.Duration) {
 	}
 }
 
+// MemoryUsage returns how many images are held in memory and their size.
+func (s *ImageService) MemoryUsage() (items int, bytes int64) {
+	s.cache.Range(func(key, value interface{}) bool {
+		if entry, ok := value.(*CacheEntry); ok {
+			items++
+			bytes += entry.size
+		}
+		return true
+	})
+	return items, bytes
+}
+
+// TrimCache evicts the least recently used images until the cache holds at
+// most maxBytes, and drops the loader's in-memory copies so the memory is
+// actually released. Evicted images are reloaded from the disk cache on
+// demand. It returns the number of entries evicted.
+func (s *ImageService) TrimCache(maxBytes int64) int {
+	type cached struct {
+		key   interface{}
+		entry *CacheEntry
+	}
+
+	var entries []cached
+	var total int64
+	s.cache.Range(func(key, value interface{}) bool {
+		if entry, ok := value.(*CacheEntry); ok {
+			entries = append(entries, cached{key: key, entry: entry})
+			total += entry.size
+		}
+		return true
+	})
+	if total <= maxBytes {
+		return 0
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].entry.timestamp.Before(entries[j].entry.timestamp)
+	})
+
+	evicted := 0
+	for _, c := range entries {
+		if total <= maxBytes {
+			break
+		}
+		s.cache.Delete(c.key)
+		total -= c.entry.size
+		evicted++
+	}
+
+	if s.loader != nil {
+		s.loader.ClearMemoryCache()
+	}
+
+	if s.debug {
+		log.Printf("[IMAGE_SERVICE] Evicted %d cached images, %d bytes remain", evicted, total)
+	}
+	return evicted
+}
+
+func resourceSize(resource fyne.Resource) int64 {
+	if resource == nil {
+		return 0
+	}
+	return int64(len(resource.Content()))
+}
+
 func (s *ImageService) GetCacheSize() int {
 	count := 0
 	s.cache.Range(func(key, value interface{}) bool {
@@ -380,32 +505,3 @@ func (s *ImageService) GetCacheStats() map[string]interface{} {
 func (s *ImageService) SetDebug(debug bool) {
 	s.debug = debug
 }
-
-// PreferredCoverURL returns the best available cover image URL for a song
-func (is *ImageService) PreferredCoverURL(song *types.Song) string {
-	if song == nil {
-		return ""
-	}
-
-	// Prefer cropped image if available
-	if song.ImageCropped != nil && *song.ImageCropped != "" {
-		return *song.ImageCropped
-	}
-
-	// Fallback to regular image
-	if song.Image != nil && *song.Image != "" {
-		return *song.Image
-	}
-
-	// Try album cover if song doesn't have one
-	if song.Album != nil {
-		if song.Album.ImageCropped != nil && *song.Album.ImageCropped != "" {
-			return *song.Album.ImageCropped
-		}
-		if song.Album.Image != nil && *song.Album.Image != "" {
-			return *song.Album.Image
-		}
-	}
-
-	return ""
-}
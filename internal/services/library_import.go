@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Alexander-D-Karpov/amp/internal/importer"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// importLikeRating is the rating, out of 100, from which an imported song
+// counts as liked; four stars and up.
+const importLikeRating = 80
+
+// LibraryImportResult tells what an import changed.
+type LibraryImportResult struct {
+	Tracks     int
+	Matched    int
+	Liked      int
+	PlayCounts int
+	Playlists  int
+	Listens    int
+}
+
+// ImportLibrary carries what another player knew over to library songs.
+// Loved and highly rated songs are liked, play counts are raised to the
+// imported ones, playlists are created as local playlists, replacing ones
+// imported under the same name before, and listens are added to the history
+// without being reported to the server. Entries that match no song are
+// skipped. Importing the same file twice changes nothing.
+func (s *MusicService) ImportLibrary(ctx context.Context, lib *importer.Library) (*LibraryImportResult, error) {
+	matcher, err := s.newSongMatcher(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LibraryImportResult{Tracks: len(lib.Tracks)}
+	matched := make(map[*importer.Track]*types.Song, len(lib.Tracks))
+	changed := make(map[string]*types.Song)
+	likes := make(map[string]bool)
+
+	for _, track := range lib.Tracks {
+		song := matcher.match(track)
+		if song == nil {
+			continue
+		}
+		matched[track] = song
+		result.Matched++
+
+		if (track.Loved || track.Rating >= importLikeRating) && (song.Liked == nil || !*song.Liked) {
+			liked := true
+			song.Liked = &liked
+			changed[song.Slug] = song
+			likes[song.Slug] = true
+			result.Liked++
+		}
+		if track.Plays > song.Played {
+			song.Played = track.Plays
+			changed[song.Slug] = song
+			result.PlayCounts++
+		}
+	}
+
+	// Imported likes are queued for the server like those made with the
+	// like button.
+	for _, song := range changed {
+		save := s.storage.SaveSong
+		if likes[song.Slug] {
+			save = s.storage.SaveLike
+		}
+		if err := save(ctx, song); err != nil {
+			return result, fmt.Errorf("save song %s: %w", song.Slug, err)
+		}
+	}
+
+	for _, playlist := range lib.Playlists {
+		imported, err := s.importPlaylist(ctx, playlist, matched)
+		if err != nil {
+			return result, err
+		}
+		if imported {
+			result.Playlists++
+		}
+	}
+
+	var events []*types.PlayEvent
+	for _, listen := range lib.Listens {
+		song := matched[listen.Track]
+		if song == nil {
+			continue
+		}
+		at := listen.At.Truncate(time.Second)
+		events = append(events, &types.PlayEvent{
+			SongSlug:   song.Slug,
+			Type:       types.PlayEventStart,
+			OccurredAt: at,
+			Synced:     true,
+		})
+		if listen.Seconds > 0 {
+			events = append(events, &types.PlayEvent{
+				SongSlug:      song.Slug,
+				Type:          types.PlayEventFinish,
+				PlayedSeconds: listen.Seconds,
+				OccurredAt:    at.Add(time.Duration(listen.Seconds) * time.Second),
+				Synced:        true,
+			})
+		}
+	}
+	if len(events) > 0 {
+		source := types.PlaySource{Type: types.PlaySourceImport, Name: lib.Format}
+		added, err := s.storage.ImportPlayEvents(ctx, source, events)
+		for _, event := range added {
+			if event.Type == types.PlayEventStart {
+				result.Listens++
+			}
+		}
+		if err != nil {
+			return result, fmt.Errorf("import listens: %w", err)
+		}
+	}
+
+	if s.debug {
+		log.Printf("[MUSIC_SERVICE] Imported %s: %d/%d tracks matched, %d liked, %d play counts, %d playlists, %d listens",
+			lib.Format, result.Matched, result.Tracks, result.Liked, result.PlayCounts, result.Playlists, result.Listens)
+	}
+	return result, nil
+}
+
+// importPlaylist stores the matched songs of playlist as a local playlist.
+// It reports false when none of its songs are in the library.
+func (s *MusicService) importPlaylist(ctx context.Context, playlist *importer.Playlist, matched map[*importer.Track]*types.Song) (bool, error) {
+	var songs []*types.Song
+	for _, track := range playlist.Tracks {
+		if song := matched[track]; song != nil {
+			songs = append(songs, song)
+		}
+	}
+	if len(songs) == 0 {
+		return false, nil
+	}
+
+	now := time.Now()
+	stored := &types.Playlist{
+		Slug:      fmt.Sprintf("%simport-%d", types.LocalSlugPrefix, now.UnixNano()),
+		Name:      playlist.Name,
+		Private:   true,
+		LocalOnly: true,
+		CreatedAt: now,
+	}
+	existing, err := s.storage.GetPlaylists(ctx)
+	if err != nil {
+		return false, fmt.Errorf("load playlists: %w", err)
+	}
+	for _, p := range existing {
+		if p.LocalOnly && p.Name == playlist.Name {
+			stored.Slug, stored.CreatedAt = p.Slug, p.CreatedAt
+			break
+		}
+	}
+
+	stored.Songs = songs
+	stored.Length = len(songs)
+	stored.UpdatedAt = now
+	if err := s.storage.SavePlaylist(ctx, stored); err != nil {
+		return false, fmt.Errorf("save playlist %s: %w", playlist.Name, err)
+	}
+	return true, nil
+}
+
+// songMatcher finds library songs by file path, or by title and artist.
+type songMatcher struct {
+	byPath  map[string]*types.Song
+	byTitle map[string][]*types.Song
+}
+
+func (s *MusicService) newSongMatcher(ctx context.Context) (*songMatcher, error) {
+	const page = 500
+
+	m := &songMatcher{
+		byPath:  make(map[string]*types.Song),
+		byTitle: make(map[string][]*types.Song),
+	}
+	for offset := 0; ; offset += page {
+		songs, err := s.storage.GetSongs(ctx, page, offset)
+		if err != nil {
+			return nil, fmt.Errorf("load library: %w", err)
+		}
+		for _, song := range songs {
+			if song.LocalPath != nil && *song.LocalPath != "" {
+				m.byPath[filepath.Clean(*song.LocalPath)] = song
+			}
+			key := matchKey(song.Name)
+			m.byTitle[key] = append(m.byTitle[key], song)
+		}
+		if len(songs) < page {
+			return m, nil
+		}
+	}
+}
+
+// match returns the song track refers to, or nil. A title shared by several
+// songs needs the artist or album to tell them apart.
+func (m *songMatcher) match(track *importer.Track) *types.Song {
+	if track.Path != "" {
+		if song := m.byPath[filepath.Clean(track.Path)]; song != nil {
+			return song
+		}
+	}
+
+	candidates := m.byTitle[matchKey(track.Title)]
+	if track.Artist != "" {
+		artist := matchKey(track.Artist)
+		for _, song := range candidates {
+			if matchKey(song.AlbumArtist()) == artist {
+				return song
+			}
+			for _, author := range song.Authors {
+				if author != nil && matchKey(author.Name) == artist {
+					return song
+				}
+			}
+		}
+		return nil
+	}
+	if track.Album != "" {
+		album := matchKey(track.Album)
+		for _, song := range candidates {
+			if song.Album != nil && matchKey(song.Album.Name) == album {
+				return song
+			}
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
+
+// matchKey folds case, punctuation and spacing so that names written
+// slightly differently by different players still compare equal.
+func matchKey(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
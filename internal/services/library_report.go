@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// GetAlbumGaps returns the albums only some of whose tracks play without
+// the server.
+func (s *MusicService) GetAlbumGaps(ctx context.Context) ([]*types.AlbumGap, error) {
+	return s.storage.GetAlbumGaps(ctx)
+}
+
+// CheckAlbumGaps fetches the track lists of the albums in the gap report
+// whose track count is not known yet and returns how many it fetched.
+func (s *MusicService) CheckAlbumGaps(ctx context.Context) (int, error) {
+	gaps, err := s.storage.GetAlbumGaps(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get album gaps: %w", err)
+	}
+
+	checked := 0
+	for _, gap := range gaps {
+		if gap.Tracks > 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return checked, err
+		}
+		if _, err := s.refreshAlbumTracks(ctx, gap.Album.Slug); err != nil {
+			if s.debug {
+				log.Printf("[MUSIC_SERVICE] Failed to check tracks of album %s: %v", gap.Album.Name, err)
+			}
+			continue
+		}
+		checked++
+	}
+	return checked, nil
+}
+
+// GetMissingAlbumSongs fetches the album's track list from the server and
+// returns the songs of it that do not play without the server yet, in
+// album order.
+func (s *MusicService) GetMissingAlbumSongs(ctx context.Context, slug string) ([]*types.Song, error) {
+	album, err := s.refreshAlbumTracks(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []*types.Song
+	for _, song := range album.Songs {
+		if song != nil && !song.IsAvailableOffline() {
+			missing = append(missing, song)
+		}
+	}
+	return missing, nil
+}
+
+// refreshAlbumTracks fetches the album from the server and stores it with
+// its songs. The songs keep their download paths, which the server knows
+// nothing about.
+func (s *MusicService) refreshAlbumTracks(ctx context.Context, slug string) (*types.Album, error) {
+	album, err := s.api.GetAlbum(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("fetch album: %w", err)
+	}
+	if album == nil {
+		return nil, fmt.Errorf("album %s not found", slug)
+	}
+
+	for _, song := range album.Songs {
+		if song == nil {
+			continue
+		}
+		stored, err := s.storage.GetSong(ctx, song.Slug)
+		if err != nil || stored == nil {
+			continue
+		}
+		song.LocalPath = stored.LocalPath
+		song.Downloaded = stored.Downloaded
+		song.CreatedAt = stored.CreatedAt
+	}
+
+	s.cacheAlbumWithRelationships(ctx, album)
+	return album, nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// VerifyResult counts the outcome of checking local files against the
+// checksums recorded when they were downloaded.
+type VerifyResult struct {
+	OK int
+	// Recorded counts files without a checksum yet, whose current one was
+	// recorded.
+	Recorded int
+	Missing  int
+	// Corrupt lists the songs whose file no longer matches its checksum.
+	Corrupt []*types.Song
+}
+
+// GetLocalFiles returns every song with a local file, with its size.
+func (s *MusicService) GetLocalFiles(ctx context.Context) ([]*storage.LocalFile, error) {
+	return s.storage.GetLocalFiles(ctx)
+}
+
+// RecordDownload stores a finished download of song at path, with its
+// checksum for later verification.
+func (s *MusicService) RecordDownload(ctx context.Context, song *types.Song, path string) error {
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	return s.storage.MarkSongDownloaded(ctx, song.Slug, path, checksum)
+}
+
+// DeleteLocalCopies removes the downloaded files of entries and returns how
+// many were removed. Files scanned from a library folder belong to the user
+// and are skipped.
+func (s *MusicService) DeleteLocalCopies(ctx context.Context, entries []*storage.LocalFile) (int, error) {
+	removed := 0
+	for _, entry := range entries {
+		if entry.Song.IsLocalOnly() {
+			continue
+		}
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("remove %s: %w", entry.Path, err)
+		}
+		if err := s.storage.ClearSongDownload(ctx, entry.Song.Slug); err != nil {
+			return removed, err
+		}
+		entry.Song.LocalPath = nil
+		entry.Song.Downloaded = false
+		removed++
+	}
+	return removed, nil
+}
+
+// RemoveDownloads deletes the downloaded files of entries like
+// DeleteLocalCopies, and remembers the songs so they can be downloaded
+// again from the recently removed list.
+func (s *MusicService) RemoveDownloads(ctx context.Context, entries []*storage.LocalFile) (int, error) {
+	removed, err := s.DeleteLocalCopies(ctx, entries)
+
+	var slugs []string
+	for _, entry := range entries {
+		if !entry.Song.IsLocalOnly() && entry.Song.LocalPath == nil {
+			slugs = append(slugs, entry.Song.Slug)
+		}
+	}
+	if recordErr := s.storage.RecordRemovedDownloads(ctx, slugs); recordErr != nil {
+		log.Printf("[MUSIC_SERVICE] Failed to record removed downloads: %v", recordErr)
+	}
+	return removed, err
+}
+
+// GetRemovedDownloads returns the songs whose downloads were removed lately
+// and not downloaded again, latest first.
+func (s *MusicService) GetRemovedDownloads(ctx context.Context) ([]*storage.RemovedDownload, error) {
+	return s.storage.GetRemovedDownloads(ctx)
+}
+
+// ForgetRemovedDownload drops a song from the recently removed downloads.
+func (s *MusicService) ForgetRemovedDownload(ctx context.Context, slug string) error {
+	return s.storage.ForgetRemovedDownload(ctx, slug)
+}
+
+// ClearRemovedDownloads empties the recently removed downloads.
+func (s *MusicService) ClearRemovedDownloads(ctx context.Context) error {
+	return s.storage.ClearRemovedDownloads(ctx)
+}
+
+// VerifyLocalCopies checks the files of entries against their recorded
+// checksums. Downloads whose file is gone are marked as not downloaded.
+func (s *MusicService) VerifyLocalCopies(ctx context.Context, entries []*storage.LocalFile) (*VerifyResult, error) {
+	result := &VerifyResult{}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		checksum, err := fileChecksum(entry.Path)
+		if os.IsNotExist(err) {
+			result.Missing++
+			if !entry.Song.IsLocalOnly() {
+				if err := s.storage.ClearSongDownload(ctx, entry.Song.Slug); err != nil {
+					return result, err
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		switch entry.Checksum {
+		case checksum:
+			result.OK++
+		case "":
+			if err := s.storage.SetSongChecksum(ctx, entry.Song.Slug, checksum); err != nil {
+				return result, err
+			}
+			entry.Checksum = checksum
+			result.Recorded++
+		default:
+			if s.debug {
+				log.Printf("[MUSIC_SERVICE] Checksum mismatch for %s: recorded %s, now %s", entry.Path, entry.Checksum, checksum)
+			}
+			result.Corrupt = append(result.Corrupt, entry.Song)
+		}
+	}
+	return result, nil
+}
+
+// fileChecksum returns the hex sha256 of the file at path.
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil {
+			log.Printf("Failed to close file: %v", closeErr)
+		}
+	}()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
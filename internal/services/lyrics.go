@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	lrclibURL = "https://lrclib.net/api"
+	// lyricsRetryAfter is how long a lookup that found nothing is trusted
+	// before the song is looked up again.
+	lyricsRetryAfter = 7 * 24 * time.Hour
+	// lrclibDurationSlack is how far an LRCLIB track's length may be from
+	// the song's for its lyrics to be taken.
+	lrclibDurationSlack = 3
+)
+
+// LyricLine is one line of synced lyrics and when it is sung.
+type LyricLine struct {
+	At   time.Duration
+	Text string
+}
+
+// LyricsService finds the lyrics of songs: the server's first, then
+// LRCLIB's, caching whatever it finds.
+type LyricsService struct {
+	api     api.MusicBackend
+	storage *storage.Database
+	cfg     *config.Config
+	client  *http.Client
+	debug   bool
+}
+
+func NewLyricsService(api api.MusicBackend, storage *storage.Database, cfg *config.Config) *LyricsService {
+	return &LyricsService{
+		api:     api,
+		storage: storage,
+		cfg:     cfg,
+		client:  netutil.NewClient(cfg, netutil.ProfileLyrics),
+		debug:   cfg.Debug,
+	}
+}
+
+// Lyrics returns the lyrics of song from the cache, or looks them up when
+// they were never cached or the last lookup found none a while ago. The
+// result has no text when no lyrics were found.
+func (s *LyricsService) Lyrics(ctx context.Context, song *types.Song) (*types.Lyrics, error) {
+	cached, err := s.storage.GetLyrics(ctx, song.Slug)
+	if err != nil {
+		log.Printf("[LYRICS] Failed to read cached lyrics of %s: %v", song.Slug, err)
+	}
+	if cached != nil && (cached.Found() || time.Since(cached.FetchedAt) < lyricsRetryAfter) {
+		return cached, nil
+	}
+
+	lyrics, err := s.lookup(ctx, song)
+	if err != nil {
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+	if err := s.storage.SaveLyrics(ctx, lyrics); err != nil {
+		log.Printf("[LYRICS] Failed to cache lyrics of %s: %v", song.Slug, err)
+	}
+	return lyrics, nil
+}
+
+// lookup asks the server, then LRCLIB. It fails when a source could not be
+// asked, so that a miss is not cached while offline.
+func (s *LyricsService) lookup(ctx context.Context, song *types.Song) (*types.Lyrics, error) {
+	lyrics := &types.Lyrics{SongSlug: song.Slug}
+
+	text, err := s.serverLyrics(ctx, song)
+	if err != nil && s.debug {
+		log.Printf("[LYRICS] Server lyrics of %s unavailable: %v", song.Slug, err)
+	}
+	if text != "" {
+		lyrics.Source = types.LyricsSourceServer
+		if len(ParseLRC(text)) > 0 {
+			lyrics.Synced = text
+		} else {
+			lyrics.Plain = text
+		}
+		return lyrics, nil
+	}
+	asked := err == nil
+
+	if s.cfg.Integrations.LRCLIB && !s.cfg.API.Offline {
+		found, lrcErr := s.lrclibLyrics(ctx, song)
+		if lrcErr != nil {
+			return nil, fmt.Errorf("look up lyrics on LRCLIB: %w", lrcErr)
+		}
+		asked = true
+		if found != nil {
+			lyrics.Source = types.LyricsSourceLRCLIB
+			lyrics.Synced = strings.TrimSpace(found.SyncedLyrics)
+			lyrics.Plain = strings.TrimSpace(found.PlainLyrics)
+		}
+	}
+
+	if !asked {
+		if err == nil {
+			err = api.ErrOffline
+		}
+		return nil, fmt.Errorf("look up lyrics: %w", err)
+	}
+	return lyrics, nil
+}
+
+// serverLyrics returns the lyrics the server has for song. Songs in lists
+// come without metadata, so the song is fetched unless it carries some.
+func (s *LyricsService) serverLyrics(ctx context.Context, song *types.Song) (string, error) {
+	meta := song.Meta
+	if meta == nil || meta.Lyrics == nil {
+		if song.IsLocalOnly() {
+			return "", nil
+		}
+		full, err := s.api.GetSong(ctx, song.Slug)
+		if err != nil {
+			return "", err
+		}
+		if full == nil {
+			return "", nil
+		}
+		meta = full.Meta
+	}
+	if meta == nil || meta.Lyrics == nil {
+		return "", nil
+	}
+	return strings.TrimSpace(*meta.Lyrics), nil
+}
+
+// lrclibTrack is a track as LRCLIB returns it.
+type lrclibTrack struct {
+	Duration     float64 `json:"duration"`
+	Instrumental bool    `json:"instrumental"`
+	PlainLyrics  string  `json:"plainLyrics"`
+	SyncedLyrics string  `json:"syncedLyrics"`
+}
+
+// lrclibLyrics looks song up on LRCLIB, by its exact signature when the
+// album and length are known and by searching otherwise. It returns nil if
+// LRCLIB has no lyrics for it.
+func (s *LyricsService) lrclibLyrics(ctx context.Context, song *types.Song) (*lrclibTrack, error) {
+	artist := ""
+	for _, author := range song.Authors {
+		if author != nil && author.Name != "" {
+			artist = author.Name
+			break
+		}
+	}
+	if song.Name == "" || artist == "" {
+		return nil, nil
+	}
+
+	if song.Album != nil && song.Album.Name != "" && song.Length > 0 {
+		params := url.Values{
+			"track_name":  {song.Name},
+			"artist_name": {artist},
+			"album_name":  {song.Album.Name},
+			"duration":    {strconv.Itoa(song.Length)},
+		}
+		var track lrclibTrack
+		found, err := s.lrclibGet(ctx, "/get", params, &track)
+		if err != nil {
+			return nil, err
+		}
+		if found && (track.SyncedLyrics != "" || track.PlainLyrics != "") {
+			return &track, nil
+		}
+	}
+
+	var tracks []lrclibTrack
+	params := url.Values{"track_name": {song.Name}, "artist_name": {artist}}
+	if _, err := s.lrclibGet(ctx, "/search", params, &tracks); err != nil {
+		return nil, err
+	}
+	return pickLRCLIBTrack(tracks, song.Length), nil
+}
+
+// pickLRCLIBTrack picks the search result closest to a song of length
+// seconds, synced lyrics first. Results of another length are skipped when
+// the length is known.
+func pickLRCLIBTrack(tracks []lrclibTrack, length int) *lrclibTrack {
+	var best *lrclibTrack
+	for i := range tracks {
+		track := &tracks[i]
+		if track.Instrumental || (track.SyncedLyrics == "" && track.PlainLyrics == "") {
+			continue
+		}
+		if length > 0 && track.Duration > 0 {
+			if diff := track.Duration - float64(length); diff > lrclibDurationSlack || diff < -lrclibDurationSlack {
+				continue
+			}
+		}
+		if track.SyncedLyrics != "" {
+			return track
+		}
+		if best == nil {
+			best = track
+		}
+	}
+	return best
+}
+
+// lrclibGet decodes the response of an LRCLIB endpoint into out. It reports
+// false when LRCLIB answers that it has no such track.
+func (s *LyricsService) lrclibGet(ctx context.Context, path string, params url.Values, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, lrclibURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", s.cfg.API.UserAgent)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("request %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("request %s: status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return true, nil
+}
+
+// ParseLRC returns the timed lines of LRC text in the order they are sung.
+// A line may carry several timestamps, and an [offset:] tag shifts every
+// line. Lines without a timestamp are left out, so plain text yields none.
+func ParseLRC(text string) []LyricLine {
+	var lines []LyricLine
+	var offset time.Duration
+
+	for _, raw := range strings.Split(text, "\n") {
+		rest := strings.TrimSpace(raw)
+		var stamps []time.Duration
+		for strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				break
+			}
+			tag := rest[1:end]
+			rest = rest[end+1:]
+			if at, ok := parseLRCTimestamp(tag); ok {
+				stamps = append(stamps, at)
+			} else if value, ok := strings.CutPrefix(tag, "offset:"); ok {
+				if ms, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+					offset = time.Duration(ms) * time.Millisecond
+				}
+			}
+		}
+		words := strings.TrimSpace(rest)
+		for _, at := range stamps {
+			lines = append(lines, LyricLine{At: at, Text: words})
+		}
+	}
+
+	// A positive offset shows the lines sooner.
+	for i := range lines {
+		lines[i].At = max(0, lines[i].At-offset)
+	}
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].At < lines[j].At })
+	return lines
+}
+
+// parseLRCTimestamp parses mm:ss, mm:ss.xx or mm:ss:xx.
+func parseLRCTimestamp(tag string) (time.Duration, bool) {
+	minutes, rest, ok := strings.Cut(tag, ":")
+	if !ok {
+		return 0, false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || m < 0 {
+		return 0, false
+	}
+	rest = strings.Replace(rest, ":", ".", 1)
+	sec, err := strconv.ParseFloat(rest, 64)
+	if err != nil || !(sec >= 0 && sec < 60) {
+		return 0, false
+	}
+	return time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second)), true
+}
+
+// CurrentLyricLine returns the index of the line being sung at pos, or -1
+// before the first.
+func CurrentLyricLine(lines []LyricLine, pos time.Duration) int {
+	return sort.Search(len(lines), func(i int) bool { return lines[i].At > pos }) - 1
+}
@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Alexander-D-Karpov/amp/internal/api"
 	"github.com/Alexander-D-Karpov/amp/internal/search"
@@ -12,13 +16,16 @@ import (
 )
 
 type MusicService struct {
-	api     *api.Client
+	api     api.MusicBackend
 	storage *storage.Database
 	search  *search.SearchEngine
 	debug   bool
+
+	// refreshing holds the keys of the background refreshes under way.
+	refreshing sync.Map
 }
 
-func NewMusicService(api *api.Client, storage *storage.Database, search *search.SearchEngine) *MusicService {
+func NewMusicService(api api.MusicBackend, storage *storage.Database, search *search.SearchEngine) *MusicService {
 	return &MusicService{
 		api:     api,
 		storage: storage,
@@ -47,12 +54,15 @@ func (s *MusicService) GetSongsWithSort(ctx context.Context, page int, searchQue
 			if searchErr != nil {
 				return nil, false, fmt.Errorf("search failed: %w", searchErr)
 			}
+			if errors.Is(err, api.ErrOffline) {
+				return availableSongs(results.Songs), false, nil
+			}
 			return results.Songs, false, nil
 		}
 
 		// Cache songs in background without fetching additional details
 		go s.cacheSongsBasic(ctx, resp.Results)
-		return resp.Results, resp.Next != nil, nil
+		return s.withoutTrashedSongs(ctx, s.withLocalSongs(ctx, page, searchQuery, resp.Results)), resp.Next != nil, nil
 	}
 
 	// No search query - get regular list
@@ -65,7 +75,11 @@ func (s *MusicService) GetSongsWithSort(ctx context.Context, page int, searchQue
 			offset = 0
 		}
 
-		songs, dbErr := s.storage.GetSongs(ctx, limit, offset)
+		getSongs := s.storage.GetSongs
+		if errors.Is(err, api.ErrOffline) {
+			getSongs = s.storage.GetOfflineSongs
+		}
+		songs, dbErr := getSongs(ctx, limit, offset)
 		if dbErr != nil {
 			return nil, false, fmt.Errorf("both API and storage failed: api=%w, storage=%w", err, dbErr)
 		}
@@ -75,7 +89,64 @@ func (s *MusicService) GetSongsWithSort(ctx context.Context, page int, searchQue
 
 	// Cache songs in background without fetching additional details
 	go s.cacheSongsBasic(ctx, resp.Results)
-	return resp.Results, resp.Next != nil, nil
+	return s.withoutTrashedSongs(ctx, s.withLocalSongs(ctx, page, "", resp.Results)), resp.Next != nil, nil
+}
+
+// GetSongsOrdered returns a page of the library in an order the API can't
+// sort by, sorted over every stored song. Offline only the songs that play
+// without the server are listed.
+func (s *MusicService) GetSongsOrdered(ctx context.Context, page int, order storage.SongOrder) ([]*types.Song, bool, error) {
+	limit := 50
+	offset := max(0, (page-1)*limit)
+
+	songs, err := s.storage.GetSongsOrdered(ctx, order, s.IsOffline(), limit, offset)
+	if err != nil {
+		return nil, false, fmt.Errorf("get songs by %s: %w", order, err)
+	}
+	return songs, len(songs) == limit, nil
+}
+
+// withLocalSongs puts the songs scanned from library folders, which the API
+// doesn't know about, ahead of the first page of API songs. With a query
+// only those whose title, artist or album contain it are added.
+func (s *MusicService) withLocalSongs(ctx context.Context, page int, query string, songs []*types.Song) []*types.Song {
+	if page > 1 {
+		return songs
+	}
+	local, err := s.storage.GetLocalSongs(ctx)
+	if err != nil {
+		if s.debug {
+			log.Printf("[MUSIC_SERVICE] Failed to load local songs: %v", err)
+		}
+		return songs
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	merged := make([]*types.Song, 0, len(local)+len(songs))
+	for _, song := range local {
+		if query == "" || localSongMatches(song, query) {
+			merged = append(merged, song)
+		}
+	}
+	return append(merged, songs...)
+}
+
+func localSongMatches(song *types.Song, query string) bool {
+	fields := []string{song.Name, song.AlbumArtist()}
+	if song.Album != nil {
+		fields = append(fields, song.Album.Name)
+	}
+	for _, author := range song.Authors {
+		if author != nil {
+			fields = append(fields, author.Name)
+		}
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), query) {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *MusicService) GetAlbums(ctx context.Context, page int, searchQuery string) ([]*types.Album, bool, error) {
@@ -88,7 +159,11 @@ func (s *MusicService) GetAlbums(ctx context.Context, page int, searchQuery stri
 			offset = 0
 		}
 
-		albums, dbErr := s.storage.GetAlbums(ctx, limit, offset)
+		getAlbums := s.storage.GetAlbums
+		if errors.Is(err, api.ErrOffline) {
+			getAlbums = s.storage.GetOfflineAlbums
+		}
+		albums, dbErr := getAlbums(ctx, limit, offset)
 		if dbErr != nil {
 			return nil, false, fmt.Errorf("both API and storage failed: api=%w, storage=%w", err, dbErr)
 		}
@@ -111,7 +186,11 @@ func (s *MusicService) GetAuthors(ctx context.Context, page int, searchQuery str
 			offset = 0
 		}
 
-		authors, dbErr := s.storage.GetAuthors(ctx, limit, offset)
+		getAuthors := s.storage.GetAuthors
+		if errors.Is(err, api.ErrOffline) {
+			getAuthors = s.storage.GetOfflineAuthors
+		}
+		authors, dbErr := getAuthors(ctx, limit, offset)
 		if dbErr != nil {
 			return nil, false, fmt.Errorf("both API and storage failed: api=%w, storage=%w", err, dbErr)
 		}
@@ -136,7 +215,25 @@ func (s *MusicService) GetPlaylists(ctx context.Context) ([]*types.Playlist, err
 
 	// Cache playlists in background (basic info only)
 	go s.cachePlaylistsBasic(ctx, playlists)
-	return playlists, nil
+	return s.withoutTrashedPlaylists(ctx, s.withLocalPlaylists(ctx, playlists)), nil
+}
+
+// withLocalPlaylists adds the playlists that only exist on this machine,
+// which the API doesn't know about.
+func (s *MusicService) withLocalPlaylists(ctx context.Context, playlists []*types.Playlist) []*types.Playlist {
+	stored, err := s.storage.GetPlaylists(ctx)
+	if err != nil {
+		if s.debug {
+			log.Printf("[MUSIC_SERVICE] Failed to load local playlists: %v", err)
+		}
+		return playlists
+	}
+	for _, playlist := range stored {
+		if playlist.LocalOnly {
+			playlists = append(playlists, playlist)
+		}
+	}
+	return playlists
 }
 
 // DETAILED METHODS - Fetch full information with relationships when explicitly requested
@@ -146,6 +243,10 @@ func (s *MusicService) GetAlbum(ctx context.Context, slug string) (*types.Album,
 		log.Printf("[MUSIC_SERVICE] Fetching detailed album: %s", slug)
 	}
 
+	if album := s.storedAlbum(ctx, slug); album != nil {
+		return album, nil
+	}
+
 	// Try API first for detailed album info
 	album, err := s.api.GetAlbum(ctx, slug)
 	if err != nil {
@@ -163,6 +264,9 @@ func (s *MusicService) GetAlbum(ctx context.Context, slug string) (*types.Album,
 		if dbAlbum != nil {
 			songs, songErr := s.getAlbumSongsFromStorage(ctx, slug)
 			if songErr == nil {
+				if errors.Is(err, api.ErrOffline) {
+					songs = availableSongs(songs)
+				}
 				dbAlbum.Songs = songs
 			}
 		}
@@ -187,6 +291,10 @@ func (s *MusicService) GetAuthor(ctx context.Context, slug string) (*types.Autho
 		log.Printf("[MUSIC_SERVICE] Fetching detailed author: %s", slug)
 	}
 
+	if author := s.storedAuthor(ctx, slug); author != nil {
+		return author, nil
+	}
+
 	// Try API first for detailed author info
 	author, err := s.api.GetAuthor(ctx, slug)
 	if err != nil {
@@ -203,6 +311,9 @@ func (s *MusicService) GetAuthor(ctx context.Context, slug string) (*types.Autho
 		// Load songs and albums for the author from storage
 		if dbAuthor != nil {
 			songs, albums := s.getAuthorContentFromStorage(ctx, slug)
+			if errors.Is(err, api.ErrOffline) {
+				songs, albums = availableSongs(songs), albumsOf(songs)
+			}
 			dbAuthor.Songs = songs
 			dbAuthor.Albums = albums
 		}
@@ -228,6 +339,10 @@ func (s *MusicService) GetSong(ctx context.Context, slug string) (*types.Song, e
 		log.Printf("[MUSIC_SERVICE] Fetching detailed song: %s", slug)
 	}
 
+	if song := s.storedSong(ctx, slug); song != nil {
+		return song, nil
+	}
+
 	// Try API first
 	song, err := s.api.GetSong(ctx, slug)
 	if err != nil {
@@ -240,6 +355,8 @@ func (s *MusicService) GetSong(ctx context.Context, slug string) (*types.Song, e
 	}
 
 	if song != nil {
+		song.LastSync = time.Now()
+
 		// Persist volume if DB lacked it
 		go s.ensureSongVolumeSaved(ctx, song)
 
@@ -273,6 +390,10 @@ func (s *MusicService) GetPlaylist(ctx context.Context, slug string) (*types.Pla
 		log.Printf("[MUSIC_SERVICE] Fetching detailed playlist: %s", slug)
 	}
 
+	if playlist := s.storedPlaylist(ctx, slug); playlist != nil {
+		return playlist, nil
+	}
+
 	playlist, err := s.api.GetPlaylist(ctx, slug)
 	if err != nil {
 		dbPlaylist, dbErr := s.storage.GetPlaylist(ctx, slug)
@@ -282,7 +403,16 @@ func (s *MusicService) GetPlaylist(ctx context.Context, slug string) (*types.Pla
 		return dbPlaylist, nil
 	}
 
+	// Edits not yet sent to the server win over its copy until they are.
+	if dirty, _ := s.storage.IsPlaylistDirty(ctx, slug); dirty {
+		if stored, err := s.storage.GetPlaylist(ctx, slug); err == nil && stored != nil {
+			return stored, nil
+		}
+	}
+
 	if playlist != nil {
+		playlist.LastSync = time.Now()
+
 		// Cache the playlist and its songs
 		go s.cachePlaylistWithRelationships(ctx, playlist)
 	}
@@ -290,8 +420,223 @@ func (s *MusicService) GetPlaylist(ctx context.Context, slug string) (*types.Pla
 	return playlist, nil
 }
 
+// UpdatePlaylistDetails renames a playlist or changes its privacy, on the
+// server unless the playlist only exists locally, and in storage. The passed
+// playlist is not modified.
+func (s *MusicService) UpdatePlaylistDetails(ctx context.Context, playlist *types.Playlist, name string, private bool) error {
+	updated := *playlist
+	updated.Name = name
+	updated.Private = private
+
+	deferred := false
+	if !playlist.LocalOnly {
+		var err error
+		if deferred, err = s.pushPlaylist(ctx, &updated); err != nil {
+			return err
+		}
+	}
+
+	if err := s.storage.UpdatePlaylistDetails(ctx, playlist.Slug, name, private); err != nil {
+		return fmt.Errorf("save playlist: %w", err)
+	}
+	if deferred {
+		if err := s.storage.MarkPlaylistDirty(ctx, playlist.Slug); err != nil {
+			return err
+		}
+	}
+
+	if s.debug {
+		log.Printf("[MUSIC_SERVICE] Updated playlist %s: name=%q private=%v", playlist.Slug, name, private)
+	}
+	return nil
+}
+
+// DeletePlaylist moves a playlist to the trash. Nothing is sent to the
+// server until the trash is purged, so an accidental delete can be undone.
+func (s *MusicService) DeletePlaylist(ctx context.Context, playlist *types.Playlist) error {
+	// Playlists only seen through the API have to be stored to be trashed.
+	if stored, err := s.storage.GetPlaylist(ctx, playlist.Slug); err == nil && stored == nil {
+		if err := s.storage.SavePlaylist(ctx, playlist); err != nil {
+			return fmt.Errorf("save playlist: %w", err)
+		}
+	}
+	if err := s.storage.DeletePlaylist(ctx, playlist.Slug); err != nil {
+		return fmt.Errorf("delete playlist: %w", err)
+	}
+	if s.debug {
+		log.Printf("[MUSIC_SERVICE] Moved playlist %s to trash", playlist.Slug)
+	}
+	return nil
+}
+
+// DeleteSong moves a song to the trash, hiding it from the library.
+func (s *MusicService) DeleteSong(ctx context.Context, song *types.Song) error {
+	if stored, err := s.storage.GetSong(ctx, song.Slug); err == nil && stored == nil {
+		if err := s.storage.SaveSong(ctx, song); err != nil {
+			return fmt.Errorf("save song: %w", err)
+		}
+	}
+	if err := s.storage.DeleteSong(ctx, song.Slug); err != nil {
+		return fmt.Errorf("delete song: %w", err)
+	}
+	if s.debug {
+		log.Printf("[MUSIC_SERVICE] Moved song %s to trash", song.Slug)
+	}
+	return nil
+}
+
+func (s *MusicService) GetTrash(ctx context.Context) ([]*types.TrashItem, error) {
+	return s.storage.GetTrash(ctx)
+}
+
+func (s *MusicService) RestoreFromTrash(ctx context.Context, kind types.TrashKind, slug string) error {
+	return s.storage.RestoreFromTrash(ctx, kind, slug)
+}
+
+// PurgeFromTrash deletes a trashed item for good. Playlists that exist on the
+// server are deleted there first; songs are only removed locally.
+func (s *MusicService) PurgeFromTrash(ctx context.Context, item *types.TrashItem) error {
+	if item.Kind == types.TrashPlaylist && !item.LocalOnly {
+		err := s.api.DeletePlaylist(ctx, item.Slug)
+		if errors.Is(err, api.ErrOffline) {
+			err = s.storage.QueuePlaylistDelete(ctx, item.Slug)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.storage.PurgeFromTrash(ctx, item.Kind, item.Slug)
+}
+
+// PurgeExpiredTrash deletes everything that has been in the trash longer than
+// types.TrashRetention and returns how many items were removed.
+func (s *MusicService) PurgeExpiredTrash(ctx context.Context) (int, error) {
+	items, err := s.storage.GetTrash(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	purged := 0
+	for _, item := range items {
+		if now.Before(item.ExpiresAt()) {
+			continue
+		}
+		if err := s.PurgeFromTrash(ctx, item); err != nil {
+			log.Printf("[MUSIC_SERVICE] Failed to purge %s %s: %v", item.Kind, item.Slug, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// withoutTrashedSongs drops songs the user moved to the trash from results
+// that came from the API.
+func (s *MusicService) withoutTrashedSongs(ctx context.Context, songs []*types.Song) []*types.Song {
+	trashed, err := s.storage.TrashedSlugs(ctx, types.TrashSong)
+	if err != nil || len(trashed) == 0 {
+		return songs
+	}
+
+	kept := make([]*types.Song, 0, len(songs))
+	for _, song := range songs {
+		if !trashed[song.Slug] {
+			kept = append(kept, song)
+		}
+	}
+	return kept
+}
+
+func (s *MusicService) withoutTrashedPlaylists(ctx context.Context, playlists []*types.Playlist) []*types.Playlist {
+	trashed, err := s.storage.TrashedSlugs(ctx, types.TrashPlaylist)
+	if err != nil || len(trashed) == 0 {
+		return playlists
+	}
+
+	kept := make([]*types.Playlist, 0, len(playlists))
+	for _, playlist := range playlists {
+		if !trashed[playlist.Slug] {
+			kept = append(kept, playlist)
+		}
+	}
+	return kept
+}
+
 // SEARCH METHOD
 
+// GetSessionQueue rebuilds the queue of a past listening session: every song
+// that was started, in order, without repeats. Songs that are no longer in
+// the library are left out.
+func (s *MusicService) GetSessionQueue(ctx context.Context, sessionID string) ([]*types.Song, error) {
+	events, err := s.storage.GetSessionEvents(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get session events: %w", err)
+	}
+	return s.queueFromEvents(ctx, events), nil
+}
+
+// GetDayQueue rebuilds what was listened to on the day day starts, like
+// GetSessionQueue does for a session.
+func (s *MusicService) GetDayQueue(ctx context.Context, day time.Time) ([]*types.Song, error) {
+	events, err := s.storage.GetPlayStartsBetween(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("get play starts: %w", err)
+	}
+	return s.queueFromEvents(ctx, events), nil
+}
+
+// queueFromEvents returns the songs started in events, in order, without
+// repeats and without the ones no longer in the library.
+func (s *MusicService) queueFromEvents(ctx context.Context, events []*types.PlayEvent) []*types.Song {
+	seen := make(map[string]bool)
+	songs := make([]*types.Song, 0, len(events))
+	for _, event := range events {
+		if event.Type != types.PlayEventStart || seen[event.SongSlug] {
+			continue
+		}
+		seen[event.SongSlug] = true
+
+		song, err := s.storage.GetSong(ctx, event.SongSlug)
+		if err != nil || song == nil {
+			if s.debug {
+				log.Printf("[MUSIC_SERVICE] Skipping %s from the history: %v", event.SongSlug, err)
+			}
+			continue
+		}
+		songs = append(songs, song)
+	}
+	return songs
+}
+
+// GetPlayHistory returns the songs started most recently, newest first, with
+// each song loaded once however often it was played.
+func (s *MusicService) GetPlayHistory(ctx context.Context, limit, offset int) ([]*types.HistoryEntry, error) {
+	events, err := s.storage.GetRecentPlayStarts(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("get play starts: %w", err)
+	}
+
+	songs := make(map[string]*types.Song)
+	entries := make([]*types.HistoryEntry, 0, len(events))
+	for _, event := range events {
+		song, loaded := songs[event.SongSlug]
+		if !loaded {
+			if song, err = s.storage.GetSong(ctx, event.SongSlug); err != nil {
+				return nil, fmt.Errorf("get song %s: %w", event.SongSlug, err)
+			}
+			songs[event.SongSlug] = song
+		}
+		entries = append(entries, &types.HistoryEntry{
+			SongSlug: event.SongSlug,
+			Song:     song,
+			PlayedAt: event.OccurredAt,
+			Source:   event.Source,
+		})
+	}
+	return entries, nil
+}
+
 func (s *MusicService) SearchAll(ctx context.Context, query string) (*types.SearchResponse, error) {
 	result, err := s.api.SearchAll(ctx, query)
 	if err != nil {
@@ -300,6 +645,9 @@ func (s *MusicService) SearchAll(ctx context.Context, query string) (*types.Sear
 			return nil, fmt.Errorf("both API and local search failed: api=%w, local=%w", err, localErr)
 		}
 
+		if errors.Is(err, api.ErrOffline) {
+			localResults.Songs = availableSongs(localResults.Songs)
+		}
 		return &types.SearchResponse{
 			Songs:   localResults.Songs,
 			Albums:  localResults.Albums,
@@ -314,6 +662,7 @@ func (s *MusicService) SearchAll(ctx context.Context, query string) (*types.Sear
 			s.cacheAlbumsBasic(ctx, result.Albums)
 			s.cacheAuthorsBasic(ctx, result.Authors)
 		}()
+		result.Songs = s.withoutTrashedSongs(ctx, result.Songs)
 	}
 
 	return result, nil
@@ -322,48 +671,15 @@ func (s *MusicService) SearchAll(ctx context.Context, query string) (*types.Sear
 // HELPER METHODS FOR STORAGE QUERIES
 
 func (s *MusicService) getAlbumSongsFromStorage(ctx context.Context, albumSlug string) ([]*types.Song, error) {
-	songs, err := s.storage.GetSongs(ctx, 1000, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	var albumSongs []*types.Song
-	for _, song := range songs {
-		if song.Album != nil && song.Album.Slug == albumSlug {
-			albumSongs = append(albumSongs, song)
-		}
-	}
-
-	return albumSongs, nil
+	return s.storage.GetAlbumSongs(ctx, albumSlug)
 }
 
 func (s *MusicService) getAuthorContentFromStorage(ctx context.Context, authorSlug string) ([]*types.Song, []*types.Album) {
-	songs, err := s.storage.GetSongs(ctx, 1000, 0)
+	songs, err := s.storage.GetAuthorSongs(ctx, authorSlug)
 	if err != nil {
 		return nil, nil
 	}
-
-	var authorSongs []*types.Song
-	albumMap := make(map[string]*types.Album)
-
-	for _, song := range songs {
-		for _, author := range song.Authors {
-			if author != nil && author.Slug == authorSlug {
-				authorSongs = append(authorSongs, song)
-				if song.Album != nil {
-					albumMap[song.Album.Slug] = song.Album
-				}
-				break
-			}
-		}
-	}
-
-	var authorAlbums []*types.Album
-	for _, album := range albumMap {
-		authorAlbums = append(authorAlbums, album)
-	}
-
-	return authorSongs, authorAlbums
+	return songs, albumsOf(songs)
 }
 
 // BASIC CACHING METHODS (for list views) - No additional API calls
@@ -393,6 +709,22 @@ func (s *MusicService) cacheSongsBasic(ctx context.Context, songs []*types.Song)
 			log.Printf("[MUSIC_SERVICE] Failed to cache song %s: %v", song.Name, err)
 		}
 	}
+
+	var albums, authors []string
+	for _, song := range songs {
+		if song == nil {
+			continue
+		}
+		if song.Album != nil {
+			albums = append(albums, song.Album.Slug)
+		}
+		for _, author := range song.Authors {
+			if author != nil {
+				authors = append(authors, author.Slug)
+			}
+		}
+	}
+	s.noteViewed(ctx, albums, authors)
 }
 
 func (s *MusicService) cacheAlbumsBasic(ctx context.Context, albums []*types.Album) {
@@ -414,16 +746,27 @@ func (s *MusicService) cacheAlbumsBasic(ctx context.Context, albums []*types.Alb
 			log.Printf("[MUSIC_SERVICE] Failed to cache album %s: %v", album.Name, err)
 		}
 	}
+
+	slugs := make([]string, 0, len(albums))
+	for _, album := range albums {
+		if album != nil {
+			slugs = append(slugs, album.Slug)
+		}
+	}
+	s.noteViewed(ctx, slugs, nil)
 }
 
 func (s *MusicService) cacheAuthorsBasic(ctx context.Context, authors []*types.Author) {
+	slugs := make([]string, 0, len(authors))
 	for _, author := range authors {
 		if author != nil {
 			if err := s.storage.SaveAuthor(ctx, author); err != nil && s.debug {
 				log.Printf("[MUSIC_SERVICE] Failed to cache author %s: %v", author.Name, err)
 			}
+			slugs = append(slugs, author.Slug)
 		}
 	}
+	s.noteViewed(ctx, nil, slugs)
 }
 
 func (s *MusicService) cachePlaylistsBasic(ctx context.Context, playlists []*types.Playlist) {
@@ -432,7 +775,10 @@ func (s *MusicService) cachePlaylistsBasic(ctx context.Context, playlists []*typ
 			continue
 		}
 
-		if err := s.storage.SavePlaylist(ctx, playlist); err != nil && s.debug {
+		if dirty, _ := s.storage.IsPlaylistDirty(ctx, playlist.Slug); dirty {
+			continue
+		}
+		if err := s.storage.SaveServerPlaylist(ctx, playlist); err != nil && s.debug {
 			log.Printf("[MUSIC_SERVICE] Failed to cache playlist %s: %v", playlist.Name, err)
 		}
 	}
@@ -476,6 +822,14 @@ func (s *MusicService) cacheAlbumWithRelationships(ctx context.Context, album *t
 			}
 		}
 	}
+	if err := s.storage.MarkAlbumDetailed(ctx, album.Slug); err != nil && s.debug {
+		log.Printf("[MUSIC_SERVICE] Failed to mark album %s detailed: %v", album.Name, err)
+	}
+	if len(album.Songs) > 0 {
+		if err := s.storage.SetAlbumTrackCount(ctx, album.Slug, len(album.Songs)); err != nil && s.debug {
+			log.Printf("[MUSIC_SERVICE] Failed to save track count of album %s: %v", album.Name, err)
+		}
+	}
 }
 
 func (s *MusicService) cacheAuthorWithRelationships(ctx context.Context, author *types.Author) {
@@ -505,6 +859,9 @@ func (s *MusicService) cacheAuthorWithRelationships(ctx context.Context, author
 			}
 		}
 	}
+	if err := s.storage.MarkAuthorDetailed(ctx, author.Slug); err != nil && s.debug {
+		log.Printf("[MUSIC_SERVICE] Failed to mark author %s detailed: %v", author.Name, err)
+	}
 }
 
 func (s *MusicService) cacheSongWithRelationships(ctx context.Context, song *types.Song) {
@@ -562,12 +919,20 @@ func (s *MusicService) cachePlaylistWithRelationships(ctx context.Context, playl
 		}
 	}
 
-	// Cache the playlist
-	if err := s.storage.SavePlaylist(ctx, playlist); err != nil && s.debug {
+	// Cache the playlist, unless it has edits the server has not seen
+	if dirty, _ := s.storage.IsPlaylistDirty(ctx, playlist.Slug); dirty {
+		return
+	}
+	if err := s.storage.SaveServerPlaylist(ctx, playlist); err != nil && s.debug {
 		log.Printf("[MUSIC_SERVICE] Failed to cache playlist %s: %v", playlist.Name, err)
 	}
 }
 
+// ServerSupports reports whether the server offers an optional feature.
+func (s *MusicService) ServerSupports(feature string) bool {
+	return s.api.Supports(feature)
+}
+
 func (s *MusicService) SetDebug(debug bool) {
 	s.debug = debug
 }
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// IsOffline reports whether offline mode keeps the service away from the
+// server.
+func (s *MusicService) IsOffline() bool {
+	sw, ok := s.api.(*api.Switch)
+	return ok && sw.Offline()
+}
+
+// availableSongs keeps the songs that play without the server.
+func availableSongs(songs []*types.Song) []*types.Song {
+	kept := make([]*types.Song, 0, len(songs))
+	for _, song := range songs {
+		if song != nil && song.IsAvailableOffline() {
+			kept = append(kept, song)
+		}
+	}
+	return kept
+}
+
+// pushPlaylist sends an edited playlist to the server. While offline it
+// reports the edit as deferred instead; the caller marks the stored playlist
+// dirty once it is saved, and the sync manager sends it later.
+func (s *MusicService) pushPlaylist(ctx context.Context, playlist *types.Playlist) (bool, error) {
+	err := s.api.UpdatePlaylist(ctx, playlist)
+	if err == nil {
+		// The server's change time moved past the one stored; forget it
+		// until the next pull rather than flag later offline edits as
+		// conflicting with this one.
+		return false, s.storage.SetPlaylistServerUpdatedAt(ctx, playlist.Slug, time.Time{})
+	}
+	if !errors.Is(err, api.ErrOffline) {
+		return false, err
+	}
+	if s.debug {
+		log.Printf("[MUSIC_SERVICE] Offline, keeping changes to playlist %s until back online", playlist.Slug)
+	}
+	return true, nil
+}
+
+// albumsOf returns the albums of songs, each once.
+func albumsOf(songs []*types.Song) []*types.Album {
+	seen := make(map[string]bool)
+	var albums []*types.Album
+	for _, song := range songs {
+		if song.Album != nil && !seen[song.Album.Slug] {
+			seen[song.Album.Slug] = true
+			albums = append(albums, song.Album)
+		}
+	}
+	return albums
+}
@@ -2,8 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/Alexander-D-Karpov/amp/internal/api"
@@ -13,15 +15,27 @@ import (
 )
 
 type PlaySyncService struct {
-	api     *api.Client
+	api     api.MusicBackend
 	storage *storage.Database
 	cfg     *config.Config
 	debug   bool
 	ticker  *time.Ticker
 	stopCh  chan struct{}
+
+	sessionMu   sync.Mutex
+	session     *types.ListeningSession
+	lastEventAt time.Time
+	source      types.PlaySource
+	// private suppresses listen reports and play history until it is
+	// turned off again; it is never persisted.
+	private bool
+
+	// onStaleToken is called when the server refuses a listen because it
+	// no longer accepts the anonymous token.
+	onStaleToken func()
 }
 
-func NewPlaySyncService(api *api.Client, storage *storage.Database, cfg *config.Config, debug bool) *PlaySyncService {
+func NewPlaySyncService(api api.MusicBackend, storage *storage.Database, cfg *config.Config, debug bool) *PlaySyncService {
 	return &PlaySyncService{
 		api:     api,
 		storage: storage,
@@ -74,75 +88,185 @@ func (p *PlaySyncService) Stop() {
 	}
 }
 
-func (p *PlaySyncService) RecordAndSendListen(ctx context.Context, song *types.Song) error {
-	if song == nil {
-		return fmt.Errorf("song is nil")
+// SetPlaySource records where the current queue was started from so that
+// following play events carry that context.
+func (p *PlaySyncService) SetPlaySource(source types.PlaySource) {
+	if source.Type == "" {
+		source.Type = types.PlaySourceLibrary
 	}
 
-	userID := p.getUserID()
+	p.sessionMu.Lock()
+	p.source = source
+	p.sessionMu.Unlock()
+}
+
+// PlaySource returns where the current queue was started from.
+func (p *PlaySyncService) PlaySource() types.PlaySource {
+	p.sessionMu.Lock()
+	defer p.sessionMu.Unlock()
+	return p.source
+}
+
+// OnStaleToken sets the callback for when the server refuses a listen
+// because it no longer accepts the anonymous token. The listen stays
+// unsynced and is retried later.
+func (p *PlaySyncService) OnStaleToken(cb func()) { p.onStaleToken = cb }
+
+// SetPrivate turns private listening on or off for this run of the app.
+// Listening after it ends starts a fresh session, so private plays leave no
+// gap inside a recorded one.
+func (p *PlaySyncService) SetPrivate(private bool) {
+	p.sessionMu.Lock()
+	defer p.sessionMu.Unlock()
+	if p.private == private {
+		return
+	}
+	p.private = private
+	p.session = nil
 
 	if p.debug {
-		log.Printf("[PLAY_SYNC] Recording listen for song: %s (user: %s, anonymous: %v)",
-			song.Name, userID, p.api.IsAnonymous())
+		log.Printf("[PLAY_SYNC] Private listening %v", private)
 	}
+}
 
-	if err := p.sendListenImmediately(ctx, song.Slug, userID); err != nil {
-		if p.debug {
-			log.Printf("[PLAY_SYNC] Failed to send immediate listen for %s: %v", song.Name, err)
+// Private reports whether private listening is on.
+func (p *PlaySyncService) Private() bool {
+	p.sessionMu.Lock()
+	defer p.sessionMu.Unlock()
+	return p.private
+}
+
+// currentSession returns the active listening session, starting a new one
+// when nothing has been played for longer than storage.SessionIdleGap.
+func (p *PlaySyncService) currentSession(ctx context.Context, now time.Time) (*types.ListeningSession, types.PlaySource, error) {
+	p.sessionMu.Lock()
+	defer p.sessionMu.Unlock()
+
+	source := p.source
+	if source.Type == "" {
+		source.Type = types.PlaySourceLibrary
+	}
+
+	if p.session == nil || now.Sub(p.lastEventAt) > storage.SessionIdleGap {
+		session, err := p.storage.CreateListeningSession(ctx, source, now)
+		if err != nil {
+			return nil, source, err
 		}
+		p.session = session
 
-		if err := p.recordLocalPlay(ctx, song.Slug, userID); err != nil {
-			log.Printf("[PLAY_SYNC] Failed to record local play for %s: %v", song.Name, err)
-			return err
+		if p.debug {
+			log.Printf("[PLAY_SYNC] Started listening session %s from %s %q", session.ID, source.Type, source.Name)
 		}
+	}
+	p.lastEventAt = now
+
+	return p.session, source, nil
+}
+
+// RecordAndSendListen logs a start event for the song and reports the listen
+// to the API, leaving it unsynced for a later retry if that fails.
+func (p *PlaySyncService) RecordAndSendListen(ctx context.Context, song *types.Song, queueIndex, queueLength int) error {
+	if song == nil {
+		return fmt.Errorf("song is nil")
+	}
+	if p.Private() {
 		return nil
 	}
 
+	userID := p.getUserID()
+
 	if p.debug {
-		log.Printf("[PLAY_SYNC] Successfully sent immediate listen for song: %s", song.Name)
+		log.Printf("[PLAY_SYNC] Recording listen for song: %s (user: %s, anonymous: %v)",
+			song.Name, userID, p.api.IsAnonymous())
 	}
 
-	if err := p.recordLocalPlayAsSynced(ctx, song.Slug, userID); err != nil {
+	event := &types.PlayEvent{
+		SongSlug:    song.Slug,
+		Type:        types.PlayEventStart,
+		QueueIndex:  queueIndex,
+		QueueLength: queueLength,
+		OccurredAt:  time.Now(),
+	}
+	if userID != "" {
+		event.UserID = &userID
+	}
+
+	if song.IsLocalOnly() {
+		event.Synced = true
+	} else if err := p.sendListenImmediately(ctx, song.Slug, userID); err != nil {
 		if p.debug {
-			log.Printf("[PLAY_SYNC] Failed to record synced play locally for %s: %v", song.Name, err)
+			log.Printf("[PLAY_SYNC] Failed to send immediate listen for %s: %v", song.Name, err)
 		}
+		p.checkStaleToken(err)
+	} else {
+		event.Synced = true
+		if p.debug {
+			log.Printf("[PLAY_SYNC] Successfully sent immediate listen for song: %s", song.Name)
+		}
+	}
+
+	if err := p.recordEvent(ctx, event); err != nil {
+		log.Printf("[PLAY_SYNC] Failed to record local play for %s: %v", song.Name, err)
+		return err
 	}
 
 	return nil
 }
 
-func (p *PlaySyncService) sendListenImmediately(ctx context.Context, songSlug, userID string) error {
-	return p.api.ListenSong(ctx, songSlug, userID)
-}
+// RecordPlaybackEnd logs how long a track was listened to and whether it
+// played to the end or was skipped.
+func (p *PlaySyncService) RecordPlaybackEnd(ctx context.Context, song *types.Song, playedSeconds int, finished bool) error {
+	if song == nil {
+		return fmt.Errorf("song is nil")
+	}
+	if p.Private() {
+		return nil
+	}
 
-func (p *PlaySyncService) recordLocalPlay(ctx context.Context, songSlug, userID string) error {
-	query := `
-		INSERT INTO play_history (song_slug, user_id, played_at, synced) 
-		VALUES (?, ?, ?, false)
-	`
+	event := &types.PlayEvent{
+		SongSlug:      song.Slug,
+		Type:          types.PlayEventSkip,
+		PlayedSeconds: playedSeconds,
+		OccurredAt:    time.Now(),
+		Synced:        true,
+	}
+	if finished {
+		event.Type = types.PlayEventFinish
+	}
+	if userID := p.getUserID(); userID != "" {
+		event.UserID = &userID
+	}
 
-	var userIDPtr *string
-	if userID != "" {
-		userIDPtr = &userID
+	return p.recordEvent(ctx, event)
+}
+
+func (p *PlaySyncService) recordEvent(ctx context.Context, event *types.PlayEvent) error {
+	session, source, err := p.currentSession(ctx, event.OccurredAt)
+	if err != nil {
+		return fmt.Errorf("get listening session: %w", err)
 	}
 
-	_, err := p.storage.GetDB().ExecContext(ctx, query, songSlug, userIDPtr, time.Now())
-	return err
+	event.SessionID = session.ID
+	event.Source = source
+
+	return p.storage.AddPlayEvent(ctx, event)
 }
 
-func (p *PlaySyncService) recordLocalPlayAsSynced(ctx context.Context, songSlug, userID string) error {
-	query := `
-		INSERT INTO play_history (song_slug, user_id, played_at, synced) 
-		VALUES (?, ?, ?, true)
-	`
+func (p *PlaySyncService) sendListenImmediately(ctx context.Context, songSlug, userID string) error {
+	return p.api.ListenSong(ctx, songSlug, userID)
+}
 
-	var userIDPtr *string
-	if userID != "" {
-		userIDPtr = &userID
+// checkStaleToken reports whether err says the anonymous token went stale,
+// and tells the callback if so.
+func (p *PlaySyncService) checkStaleToken(err error) bool {
+	if !errors.Is(err, api.ErrStaleAnonymousToken) {
+		return false
 	}
-
-	_, err := p.storage.GetDB().ExecContext(ctx, query, songSlug, userIDPtr, time.Now())
-	return err
+	log.Printf("[PLAY_SYNC] Server refused the anonymous token")
+	if p.onStaleToken != nil {
+		p.onStaleToken()
+	}
+	return true
 }
 
 func (p *PlaySyncService) getUserID() string {
@@ -166,44 +290,13 @@ func (p *PlaySyncService) getUserID() string {
 func (p *PlaySyncService) syncPlayHistory() {
 	ctx := context.Background()
 
-	query := `
-		SELECT song_slug, user_id, played_at 
-		FROM play_history 
-		WHERE synced = false 
-		ORDER BY played_at ASC 
-		LIMIT 50
-	`
-
-	rows, err := p.storage.GetDB().QueryContext(ctx, query)
+	toSync, err := p.storage.GetUnsyncedListens(ctx, 50)
 	if err != nil {
 		if p.debug {
 			log.Printf("[PLAY_SYNC] Failed to query unsynced play history: %v", err)
 		}
 		return
 	}
-	defer rows.Close()
-
-	var toSync []struct {
-		songSlug string
-		userID   *string
-		playedAt time.Time
-	}
-
-	for rows.Next() {
-		var entry struct {
-			songSlug string
-			userID   *string
-			playedAt time.Time
-		}
-
-		if err := rows.Scan(&entry.songSlug, &entry.userID, &entry.playedAt); err != nil {
-			if p.debug {
-				log.Printf("[PLAY_SYNC] Failed to scan play history: %v", err)
-			}
-			continue
-		}
-		toSync = append(toSync, entry)
-	}
 
 	if len(toSync) == 0 {
 		if p.debug {
@@ -217,22 +310,24 @@ func (p *PlaySyncService) syncPlayHistory() {
 	}
 
 	synced := 0
-	for _, history := range toSync {
+	for _, event := range toSync {
 		userID := ""
-		if history.userID != nil {
-			userID = *history.userID
+		if event.UserID != nil {
+			userID = *event.UserID
 		}
 
-		if err := p.api.ListenSong(ctx, history.songSlug, userID); err != nil {
+		if err := p.api.ListenSong(ctx, event.SongSlug, userID); err != nil {
 			if p.debug {
-				log.Printf("[PLAY_SYNC] Failed to sync play count for %s: %v", history.songSlug, err)
+				log.Printf("[PLAY_SYNC] Failed to sync play count for %s: %v", event.SongSlug, err)
+			}
+			// The rest would be refused too until there is a new token.
+			if p.checkStaleToken(err) {
+				break
 			}
 			continue
 		}
 
-		if _, err := p.storage.GetDB().ExecContext(ctx,
-			"UPDATE play_history SET synced = true WHERE song_slug = ? AND played_at = ?",
-			history.songSlug, history.playedAt); err != nil {
+		if err := p.storage.MarkPlayEventSynced(ctx, event.ID); err != nil {
 			if p.debug {
 				log.Printf("[PLAY_SYNC] Failed to mark play history as synced: %v", err)
 			}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"golang.org/x/image/draw"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// collageSize is the width and height of a generated playlist cover.
+const collageSize = 600
+
+// EnablePlaylistCollages stores generated playlist covers under dir.
+func (s *ImageService) EnablePlaylistCollages(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create collage directory: %w", err)
+	}
+	s.collageDir = dir
+	return nil
+}
+
+// PlaylistCoverURL returns the image to show for a playlist: a 2×2 collage
+// of the first four different song covers, or the only cover there is. The
+// collage is made once and kept on disk under a name derived from the
+// covers it shows, so a playlist whose first covers change gets a new one
+// and the old file is removed. It blocks while covers download.
+func (s *ImageService) PlaylistCoverURL(playlist *types.Playlist) string {
+	if playlist == nil {
+		return ""
+	}
+
+	var covers []string
+	seen := make(map[string]bool)
+	add := func(url string) {
+		if url != "" && !seen[url] && len(covers) < 4 {
+			seen[url] = true
+			covers = append(covers, url)
+		}
+	}
+	for _, song := range playlist.Songs {
+		add(s.SongCoverURL(song, fyne.NewSize(collageSize/2, collageSize/2)))
+	}
+	for _, url := range playlist.Images {
+		add(url)
+	}
+
+	if len(covers) == 0 {
+		return ""
+	}
+	if len(covers) < 4 || s.collageDir == "" || s.loader == nil {
+		return covers[0]
+	}
+
+	slugHash := sha256.Sum256([]byte(playlist.Slug))
+	prefix := fmt.Sprintf("%x-", slugHash[:8])
+	coversHash := sha256.Sum256([]byte(strings.Join(covers, "\n")))
+	path := filepath.Join(s.collageDir, fmt.Sprintf("%s%x.jpg", prefix, coversHash[:8]))
+	if _, err := os.Stat(path); err == nil {
+		return "file://" + path
+	}
+
+	data, err := s.renderCollage(covers)
+	if err != nil {
+		if s.debug {
+			log.Printf("[IMAGE_SERVICE] Collage for playlist %s unavailable: %v", playlist.Slug, err)
+		}
+		return covers[0]
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		log.Printf("[IMAGE_SERVICE] Failed to save collage for playlist %s: %v", playlist.Slug, err)
+		return covers[0]
+	}
+	s.removeStaleCollages(prefix, path)
+	return "file://" + path
+}
+
+// renderCollage draws the four covers, each cropped to a square, into the
+// quarters of one JPEG image.
+func (s *ImageService) renderCollage(covers []string) ([]byte, error) {
+	const tile = collageSize / 2
+
+	dst := image.NewRGBA(image.Rect(0, 0, collageSize, collageSize))
+	for i, url := range covers {
+		res, err := s.loader.GetResource(url)
+		if err != nil {
+			return nil, fmt.Errorf("load cover %s: %w", url, err)
+		}
+		src, _, err := image.Decode(bytes.NewReader(res.Content()))
+		if err != nil {
+			return nil, fmt.Errorf("decode cover %s: %w", url, err)
+		}
+		x, y := (i%2)*tile, (i/2)*tile
+		draw.CatmullRom.Scale(dst, image.Rect(x, y, x+tile, y+tile), src, squareCrop(src.Bounds()), draw.Src, nil)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 88}); err != nil {
+		return nil, fmt.Errorf("encode collage: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// squareCrop returns the largest centered square inside r.
+func squareCrop(r image.Rectangle) image.Rectangle {
+	side := min(r.Dx(), r.Dy())
+	x := r.Min.X + (r.Dx()-side)/2
+	y := r.Min.Y + (r.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// removeStaleCollages deletes the earlier collages of a playlist, all of
+// which share prefix, except keep.
+func (s *ImageService) removeStaleCollages(prefix, keep string) {
+	matches, err := filepath.Glob(filepath.Join(s.collageDir, prefix+"*"))
+	if err != nil {
+		return
+	}
+	for _, path := range matches {
+		if path == keep {
+			continue
+		}
+		s.Invalidate("file://" + path)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("[IMAGE_SERVICE] Failed to remove old collage %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// RemoveDuplicateSongs drops repeated entries from a playlist, keeping the
+// first occurrence of each song. It returns how many entries were removed.
+func (s *MusicService) RemoveDuplicateSongs(ctx context.Context, slug string) (int, error) {
+	playlist, err := s.loadPlaylistForEdit(ctx, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[string]bool, len(playlist.Songs))
+	kept := make([]*types.Song, 0, len(playlist.Songs))
+	for _, song := range playlist.Songs {
+		if song == nil || seen[song.Slug] {
+			continue
+		}
+		seen[song.Slug] = true
+		kept = append(kept, song)
+	}
+
+	removed := len(playlist.Songs) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.rewritePlaylist(ctx, playlist, kept)
+}
+
+// RemoveUnavailableSongs drops songs that can no longer be played: entries
+// without an audio file and local files that have disappeared from disk.
+func (s *MusicService) RemoveUnavailableSongs(ctx context.Context, slug string) (int, error) {
+	playlist, err := s.loadPlaylistForEdit(ctx, slug)
+	if err != nil {
+		return 0, err
+	}
+
+	kept := make([]*types.Song, 0, len(playlist.Songs))
+	for _, song := range playlist.Songs {
+		if songAvailable(song) {
+			kept = append(kept, song)
+		}
+	}
+
+	removed := len(playlist.Songs) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, s.rewritePlaylist(ctx, playlist, kept)
+}
+
+// SortPlaylist permanently reorders a playlist's songs.
+func (s *MusicService) SortPlaylist(ctx context.Context, slug string, key types.PlaylistSortKey) error {
+	playlist, err := s.loadPlaylistForEdit(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	songs := make([]*types.Song, 0, len(playlist.Songs))
+	for _, song := range playlist.Songs {
+		if song != nil {
+			songs = append(songs, song)
+		}
+	}
+
+	switch key {
+	case types.PlaylistSortArtist:
+		sort.SliceStable(songs, func(i, j int) bool {
+			a, b := strings.ToLower(types.CreditsLabel(songs[i].Authors)), strings.ToLower(types.CreditsLabel(songs[j].Authors))
+			if a != b {
+				return a < b
+			}
+			return strings.ToLower(songs[i].Name) < strings.ToLower(songs[j].Name)
+		})
+	case types.PlaylistSortTitle:
+		sort.SliceStable(songs, func(i, j int) bool {
+			return strings.ToLower(songs[i].Name) < strings.ToLower(songs[j].Name)
+		})
+	case types.PlaylistSortDateAdded:
+		added, err := s.storage.GetPlaylistAddedAt(ctx, slug)
+		if err != nil {
+			return err
+		}
+		// Newest first. Entries added at the same time, or before dates
+		// were kept, fall back to their position: later ones were
+		// appended later.
+		position := make(map[*types.Song]int, len(songs))
+		for i, song := range songs {
+			position[song] = i
+		}
+		sort.SliceStable(songs, func(i, j int) bool {
+			a, b := added[songs[i].Slug], added[songs[j].Slug]
+			if !a.Equal(b) {
+				return a.After(b)
+			}
+			return position[songs[i]] > position[songs[j]]
+		})
+	default:
+		return fmt.Errorf("unknown playlist sort: %s", key)
+	}
+
+	return s.rewritePlaylist(ctx, playlist, songs)
+}
+
+// EditPlaylistSongs stores the playlist's songs as edited by hand: in the
+// order of slugs, without the ones left out. Slugs that are not in the
+// playlist are ignored, so an edit can only move and remove entries.
+func (s *MusicService) EditPlaylistSongs(ctx context.Context, slug string, slugs []string) error {
+	playlist, err := s.loadPlaylistForEdit(ctx, slug)
+	if err != nil {
+		return err
+	}
+
+	bySlug := make(map[string]*types.Song, len(playlist.Songs))
+	for _, song := range playlist.Songs {
+		if song != nil {
+			bySlug[song.Slug] = song
+		}
+	}
+	songs := make([]*types.Song, 0, len(slugs))
+	for _, songSlug := range slugs {
+		if song := bySlug[songSlug]; song != nil {
+			songs = append(songs, song)
+		}
+	}
+	return s.rewritePlaylist(ctx, playlist, songs)
+}
+
+// loadPlaylistForEdit returns the playlist with its full song list. Songs
+// from the API are merged with what the library knows about them locally,
+// such as download paths. Unlike GetPlaylist it
+// does not cache in the background, which could race the rewrite.
+func (s *MusicService) loadPlaylistForEdit(ctx context.Context, slug string) (*types.Playlist, error) {
+	playlist, err := s.api.GetPlaylist(ctx, slug)
+	if err != nil || playlist == nil {
+		playlist, err = s.storage.GetPlaylist(ctx, slug)
+		if err != nil {
+			return nil, fmt.Errorf("load playlist: %w", err)
+		}
+		if playlist == nil {
+			return nil, fmt.Errorf("playlist %s not found", slug)
+		}
+		return playlist, nil
+	}
+
+	for _, song := range playlist.Songs {
+		if song == nil {
+			continue
+		}
+		stored, err := s.storage.GetSong(ctx, song.Slug)
+		if err != nil || stored == nil {
+			continue
+		}
+		song.LocalPath = stored.LocalPath
+		song.Downloaded = stored.Downloaded
+	}
+	return playlist, nil
+}
+
+// rewritePlaylist stores the new song order, on the server unless the
+// playlist only exists locally, and in storage.
+func (s *MusicService) rewritePlaylist(ctx context.Context, playlist *types.Playlist, songs []*types.Song) error {
+	updated := *playlist
+	updated.Songs = songs
+	updated.Length = len(songs)
+
+	deferred := false
+	if !playlist.LocalOnly {
+		remote := updated
+		var err error
+		if deferred, err = s.pushPlaylist(ctx, &remote); err != nil {
+			return err
+		}
+	}
+
+	// Stored playlist entries need their songs stored too.
+	for _, song := range songs {
+		if stored, err := s.storage.GetSong(ctx, song.Slug); err == nil && stored == nil {
+			s.cacheSongWithRelationships(ctx, song)
+		}
+	}
+	if err := s.storage.SavePlaylist(ctx, &updated); err != nil {
+		return fmt.Errorf("save playlist: %w", err)
+	}
+	if deferred {
+		if err := s.storage.MarkPlaylistDirty(ctx, playlist.Slug); err != nil {
+			return err
+		}
+	}
+
+	if s.debug {
+		log.Printf("[MUSIC_SERVICE] Rewrote playlist %s with %d songs", playlist.Slug, len(songs))
+	}
+	return nil
+}
+
+// songAvailable reports whether a playlist entry can still be played.
+func songAvailable(song *types.Song) bool {
+	if song == nil || song.Slug == "" {
+		return false
+	}
+	if song.LocalPath != nil && *song.LocalPath != "" {
+		if _, err := os.Stat(*song.LocalPath); err == nil {
+			return true
+		}
+	}
+	if song.IsLocalOnly() {
+		return false
+	}
+	return song.File != ""
+}
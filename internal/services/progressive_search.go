@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// SearchProgressive searches the local library first and reports those
+// results right away, then merges in the server's results section by
+// section as they arrive. onUpdate gets everything found so far on every
+// step; done is true on the last call. Server failures leave the local
+// results as the final answer.
+func (s *MusicService) SearchProgressive(ctx context.Context, query string, onUpdate func(results *types.SearchResponse, done bool)) error {
+	local := &types.SearchResponse{}
+	localResults, localErr := s.search.Search(ctx, query, 100)
+	if localErr == nil {
+		local.Songs = localResults.Songs
+		if s.IsOffline() {
+			local.Songs = availableSongs(local.Songs)
+		}
+		local.Albums = localResults.Albums
+		local.Authors = localResults.Authors
+		onUpdate(copySearchResponse(local), false)
+	}
+
+	merged := copySearchResponse(local)
+	onSection := func(section *types.SearchResponse) {
+		if ctx.Err() != nil {
+			return
+		}
+		if section.Songs != nil {
+			songs := s.withoutTrashedSongs(ctx, section.Songs)
+			go s.cacheSongsBasic(ctx, songs)
+			merged.Songs = mergeBySlug(songs, local.Songs, func(song *types.Song) string { return song.Slug })
+		}
+		if section.Albums != nil {
+			go s.cacheAlbumsBasic(ctx, section.Albums)
+			merged.Albums = mergeBySlug(section.Albums, local.Albums, func(album *types.Album) string { return album.Slug })
+		}
+		if section.Authors != nil {
+			go s.cacheAuthorsBasic(ctx, section.Authors)
+			merged.Authors = mergeBySlug(section.Authors, local.Authors, func(author *types.Author) string { return author.Slug })
+		}
+		onUpdate(copySearchResponse(merged), false)
+	}
+
+	var err error
+	if searcher, ok := s.api.(api.SectionSearcher); ok {
+		err = searcher.SearchAllSections(ctx, query, onSection)
+	} else {
+		var result *types.SearchResponse
+		result, err = s.api.SearchAll(ctx, query)
+		if err == nil && result != nil {
+			onSection(result)
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil && !errors.Is(err, api.ErrOffline) {
+		if localErr != nil {
+			return fmt.Errorf("both API and local search failed: api=%w, local=%w", err, localErr)
+		}
+		if s.debug {
+			log.Printf("[MUSIC_SERVICE] Server search for '%s' failed, keeping local results: %v", query, err)
+		}
+	}
+
+	onUpdate(copySearchResponse(merged), true)
+	return nil
+}
+
+// mergeBySlug returns primary followed by the entries of extra it does not
+// already contain.
+func mergeBySlug[T any](primary, extra []*T, slug func(*T) string) []*T {
+	merged := make([]*T, 0, len(primary)+len(extra))
+	seen := make(map[string]bool, len(primary))
+	for _, item := range primary {
+		if item == nil {
+			continue
+		}
+		seen[slug(item)] = true
+		merged = append(merged, item)
+	}
+	for _, item := range extra {
+		if item != nil && !seen[slug(item)] {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+func copySearchResponse(r *types.SearchResponse) *types.SearchResponse {
+	return &types.SearchResponse{
+		Songs:   append([]*types.Song(nil), r.Songs...),
+		Albums:  append([]*types.Album(nil), r.Albums...),
+		Authors: append([]*types.Author(nil), r.Authors...),
+	}
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// RadioSongs picks up to limit songs to play after seed: songs by its
+// authors and from its album, in random order. Songs whose slug is in
+// exclude, usually those already queued, are left out. While offline only
+// songs that play without the server are picked.
+func (s *MusicService) RadioSongs(ctx context.Context, seed *types.Song, exclude map[string]bool, limit int) []*types.Song {
+	if seed == nil || limit <= 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(exclude)+1)
+	for slug := range exclude {
+		seen[slug] = true
+	}
+	seen[seed.Slug] = true
+
+	var picks []*types.Song
+	add := func(songs []*types.Song) {
+		for _, song := range songs {
+			if song == nil || song.Slug == "" || seen[song.Slug] {
+				continue
+			}
+			seen[song.Slug] = true
+			picks = append(picks, song)
+		}
+	}
+
+	for _, author := range seed.Authors {
+		if author == nil || author.Slug == "" {
+			continue
+		}
+		detailed, err := s.GetAuthor(ctx, author.Slug)
+		if err != nil {
+			log.Printf("[MUSIC_SERVICE] Radio could not load author %s: %v", author.Slug, err)
+			continue
+		}
+		if detailed != nil {
+			add(detailed.Songs)
+		}
+	}
+	if seed.Album != nil && seed.Album.Slug != "" {
+		album, err := s.GetAlbum(ctx, seed.Album.Slug)
+		if err != nil {
+			log.Printf("[MUSIC_SERVICE] Radio could not load album %s: %v", seed.Album.Slug, err)
+		} else if album != nil {
+			add(album.Songs)
+		}
+	}
+
+	if s.IsOffline() {
+		picks = availableSongs(picks)
+	}
+	rand.Shuffle(len(picks), func(i, j int) {
+		picks[i], picks[j] = picks[j], picks[i]
+	})
+	if len(picks) > limit {
+		picks = picks[:limit]
+	}
+
+	if s.debug {
+		log.Printf("[MUSIC_SERVICE] Radio picked %d songs after %s", len(picks), seed.Name)
+	}
+	return picks
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+const megabyte = 1 << 20
+
+// StreamBuffers is the audio side of memory use: downloaded tracks held in
+// memory while they play or wait to be played.
+type StreamBuffers interface {
+	StreamBufferUsage() (streams int, bytes int64)
+	ReleaseIdleStreams() int
+}
+
+// ResourceUsage is one snapshot of the memory the app is holding on to.
+type ResourceUsage struct {
+	HeapAlloc         uint64
+	HeapSys           uint64
+	NumGC             uint32
+	ImageCacheItems   int
+	ImageCacheBytes   int64
+	Streams           int
+	StreamBufferBytes int64
+}
+
+// ResourceMonitor periodically measures heap, image cache and stream buffer
+// sizes and trims caches that go over the configured limits.
+type ResourceMonitor struct {
+	cfg     *config.Config
+	images  *ImageService
+	streams StreamBuffers
+	debug   bool
+}
+
+func NewResourceMonitor(cfg *config.Config, images *ImageService, streams StreamBuffers) *ResourceMonitor {
+	return &ResourceMonitor{
+		cfg:     cfg,
+		images:  images,
+		streams: streams,
+		debug:   cfg.Debug,
+	}
+}
+
+// Run checks usage on the configured interval until ctx is done.
+func (m *ResourceMonitor) Run(ctx context.Context) {
+	interval := time.Duration(m.cfg.Memory.CheckInterval) * time.Second
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Check measures current usage, evicts caches that are over their limits
+// and returns the usage measured before any eviction.
+func (m *ResourceMonitor) Check() ResourceUsage {
+	usage := m.measure()
+	limits := m.cfg.Memory
+	heapOver := limits.HeapLimitMB > 0 && usage.HeapAlloc > uint64(limits.HeapLimitMB)*megabyte
+
+	if limit := int64(limits.ImageCacheMB) * megabyte; m.images != nil && (heapOver || (limit > 0 && usage.ImageCacheBytes > limit)) {
+		target := limit * 3 / 4
+		if heapOver {
+			target = 0
+		}
+		evicted := m.images.TrimCache(target)
+		log.Printf("[MONITOR] Image cache at %s (limit %s), evicted %d images",
+			formatMegabytes(usage.ImageCacheBytes), formatLimit(limits.ImageCacheMB), evicted)
+	}
+
+	if limit := int64(limits.StreamBufferMB) * megabyte; m.streams != nil && (heapOver || (limit > 0 && usage.StreamBufferBytes > limit)) {
+		released := m.streams.ReleaseIdleStreams()
+		log.Printf("[MONITOR] Stream buffers at %s (limit %s), released %d idle streams",
+			formatMegabytes(usage.StreamBufferBytes), formatLimit(limits.StreamBufferMB), released)
+	}
+
+	if heapOver {
+		debug.FreeOSMemory()
+		log.Printf("[MONITOR] Heap at %s is over the %d MB limit, caches emptied",
+			formatMegabytes(int64(usage.HeapAlloc)), limits.HeapLimitMB)
+	} else if m.debug {
+		log.Printf("[MONITOR] Heap %s, images %s, streams %s",
+			formatMegabytes(int64(usage.HeapAlloc)), formatMegabytes(usage.ImageCacheBytes),
+			formatMegabytes(usage.StreamBufferBytes))
+	}
+
+	return usage
+}
+
+// Report renders current usage against the limits for diagnostics.
+func (m *ResourceMonitor) Report() string {
+	usage := m.measure()
+	limits := m.cfg.Memory
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Heap: %s in use, %s reserved, %d GCs (limit %s)\n",
+		formatMegabytes(int64(usage.HeapAlloc)), formatMegabytes(int64(usage.HeapSys)),
+		usage.NumGC, formatLimit(limits.HeapLimitMB))
+	fmt.Fprintf(&b, "Image cache: %d images, %s (limit %s)\n",
+		usage.ImageCacheItems, formatMegabytes(usage.ImageCacheBytes), formatLimit(limits.ImageCacheMB))
+	fmt.Fprintf(&b, "Stream buffers: %d streams, %s (limit %s)\n",
+		usage.Streams, formatMegabytes(usage.StreamBufferBytes), formatLimit(limits.StreamBufferMB))
+	return b.String()
+}
+
+func (m *ResourceMonitor) measure() ResourceUsage {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	usage := ResourceUsage{
+		HeapAlloc: stats.HeapAlloc,
+		HeapSys:   stats.HeapSys,
+		NumGC:     stats.NumGC,
+	}
+	if m.images != nil {
+		usage.ImageCacheItems, usage.ImageCacheBytes = m.images.MemoryUsage()
+	}
+	if m.streams != nil {
+		usage.Streams, usage.StreamBufferBytes = m.streams.StreamBufferUsage()
+	}
+	return usage
+}
+
+func formatMegabytes(bytes int64) string {
+	return fmt.Sprintf("%.1f MB", float64(bytes)/megabyte)
+}
+
+func formatLimit(mb int) string {
+	if mb <= 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%d MB", mb)
+}
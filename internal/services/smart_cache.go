@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/integrations/power"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	// smartCacheCheckInterval is how often the smart cache looks whether
+	// it may run.
+	smartCacheCheckInterval = 15 * time.Minute
+	// smartCacheTopDays is how far back the most played songs are counted.
+	smartCacheTopDays = 90
+	// smartCacheRest keeps the smart cache to one run a night.
+	smartCacheRest = 12 * time.Hour
+	// smartCacheTask names the smart cache's runs in the task log.
+	smartCacheTask = "smart_cache"
+)
+
+// Downloader fetches songs for offline play in the background.
+type Downloader interface {
+	DownloadSong(ctx context.Context, song *types.Song) error
+}
+
+// SmartCacheResult counts what a smart cache run did.
+type SmartCacheResult struct {
+	Queued  int
+	Removed int
+}
+
+// SmartCache keeps the downloads in line with what is listened to: the most
+// played and recently liked songs are downloaded, and downloads left
+// unplayed for long are removed. Removed downloads are listed with the
+// recently removed ones, so they can be brought back.
+type SmartCache struct {
+	music     *MusicService
+	downloads Downloader
+	cfg       *config.Config
+
+	mu      sync.Mutex
+	lastRun time.Time
+	onRun   func(*SmartCacheResult, error)
+}
+
+func NewSmartCache(music *MusicService, downloads Downloader, cfg *config.Config) *SmartCache {
+	return &SmartCache{music: music, downloads: downloads, cfg: cfg}
+}
+
+// OnRun sets a callback for every scheduled run. It is called from the
+// smart cache's goroutine.
+func (c *SmartCache) OnRun(callback func(*SmartCacheResult, error)) {
+	c.onRun = callback
+}
+
+// Run brings the downloads in line once a night while the machine is on
+// mains power and an unmetered network, until ctx is done. Set OnRun
+// before.
+func (c *SmartCache) Run(ctx context.Context) {
+	lastRun, err := c.music.GetStorage().GetTaskRun(ctx, smartCacheTask)
+	if err != nil {
+		log.Printf("[SMART_CACHE] Failed to load last run: %v", err)
+	}
+	c.mu.Lock()
+	if lastRun.After(c.lastRun) {
+		c.lastRun = lastRun
+	}
+	c.mu.Unlock()
+
+	ticker := time.NewTicker(smartCacheCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !c.due(time.Now()) {
+			continue
+		}
+		result, err := c.RunNow(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[SMART_CACHE] Run failed: %v", err)
+		}
+		if c.onRun != nil && ctx.Err() == nil {
+			c.onRun(result, err)
+		}
+	}
+}
+
+func (c *SmartCache) due(now time.Time) bool {
+	settings := c.cfg.SmartCache
+	if !settings.Enabled || !inHours(now.Hour(), settings.StartHour, settings.EndHour) {
+		return false
+	}
+
+	c.mu.Lock()
+	rested := c.lastRun.IsZero() || now.Sub(c.lastRun) >= smartCacheRest
+	c.mu.Unlock()
+	if !rested || c.music.IsOffline() {
+		return false
+	}
+
+	// Where the state is unknown a laptop on a hotspot looks the same as a
+	// plugged in desktop, so the run waits unless the user allowed it.
+	if onBattery, err := power.OnBattery(); err != nil {
+		c.debugLog("Cannot tell the power supply: %v", err)
+		if !settings.RunWhenUnknown {
+			return false
+		}
+	} else if onBattery {
+		c.debugLog("Waiting for mains power")
+		return false
+	}
+	if metered, err := power.Metered(); err != nil {
+		c.debugLog("Cannot tell whether the network is metered: %v", err)
+		if !settings.RunWhenUnknown {
+			return false
+		}
+	} else if metered {
+		c.debugLog("Waiting for an unmetered network")
+		return false
+	}
+	return true
+}
+
+// inHours reports whether hour falls from start up to end, which may wrap
+// past midnight. The same start and end take in the whole day.
+func inHours(hour, start, end int) bool {
+	switch {
+	case start == end:
+		return true
+	case start < end:
+		return hour >= start && hour < end
+	default:
+		return hour >= start || hour < end
+	}
+}
+
+// RunNow queues the downloads of the most played and recently liked songs
+// that are not on this device, and removes the stale downloads.
+func (c *SmartCache) RunNow(ctx context.Context) (*SmartCacheResult, error) {
+	db := c.music.GetStorage()
+	settings := c.cfg.SmartCache
+	now := time.Now()
+
+	c.mu.Lock()
+	c.lastRun = now
+	c.mu.Unlock()
+	if err := db.SetTaskRun(ctx, smartCacheTask, now); err != nil {
+		log.Printf("[SMART_CACHE] Failed to save last run: %v", err)
+	}
+
+	wanted := make(map[string]bool)
+	var slugs []string
+	if settings.TopSongs > 0 {
+		top, err := db.GetTopSongs(ctx, now.AddDate(0, 0, -smartCacheTopDays), storage.RankByPlays, settings.TopSongs)
+		if err != nil {
+			return nil, fmt.Errorf("get top songs: %w", err)
+		}
+		for _, total := range top {
+			slugs = append(slugs, total.Slug)
+		}
+	}
+	if settings.LikedDays > 0 {
+		liked, err := db.GetLikedSongsSince(ctx, now.AddDate(0, 0, -settings.LikedDays))
+		if err != nil {
+			return nil, fmt.Errorf("get liked songs: %w", err)
+		}
+		slugs = append(slugs, liked...)
+	}
+
+	result := &SmartCacheResult{}
+	for _, slug := range slugs {
+		if wanted[slug] {
+			continue
+		}
+		wanted[slug] = true
+
+		song, err := db.GetSong(ctx, slug)
+		if err != nil || song == nil || song.IsLocalOnly() || song.IsAvailableOffline() {
+			continue
+		}
+		if err := c.downloads.DownloadSong(ctx, song); err != nil {
+			c.debugLog("Not downloading %s: %v", song.Name, err)
+			continue
+		}
+		result.Queued++
+	}
+
+	if settings.EvictDays > 0 {
+		removed, err := c.evict(ctx, now.AddDate(0, 0, -settings.EvictDays), wanted)
+		result.Removed = removed
+		if err != nil {
+			return result, fmt.Errorf("remove stale downloads: %w", err)
+		}
+	}
+
+	log.Printf("[SMART_CACHE] Queued %d downloads, removed %d stale ones", result.Queued, result.Removed)
+	return result, nil
+}
+
+// evict removes the downloads not played, liked or downloaded since since,
+// sparing the wanted songs.
+func (c *SmartCache) evict(ctx context.Context, since time.Time, wanted map[string]bool) (int, error) {
+	db := c.music.GetStorage()
+	stale, err := db.GetStaleDownloads(ctx, since)
+	if err != nil || len(stale) == 0 {
+		return 0, err
+	}
+
+	evict := make(map[string]bool, len(stale))
+	for _, slug := range stale {
+		evict[slug] = !wanted[slug]
+	}
+
+	files, err := db.GetLocalFiles(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get local files: %w", err)
+	}
+	var entries []*storage.LocalFile
+	for _, file := range files {
+		if evict[file.Song.Slug] {
+			entries = append(entries, file)
+		}
+	}
+	return c.music.RemoveDownloads(ctx, entries)
+}
+
+func (c *SmartCache) debugLog(format string, args ...interface{}) {
+	if c.cfg.Debug {
+		log.Printf("[SMART_CACHE] "+format, args...)
+	}
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// GetArtworkOverrides returns every artwork the user replaced locally.
+func (d *Database) GetArtworkOverrides(ctx context.Context) ([]*types.ArtworkOverride, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetArtworkOverrides", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT kind, slug, path, updated_at FROM artwork_overrides")
+	if err != nil {
+		return nil, fmt.Errorf("query artwork overrides: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var overrides []*types.ArtworkOverride
+	for rows.Next() {
+		override := &types.ArtworkOverride{}
+		if err := rows.Scan(&override.Kind, &override.Slug, &override.Path, &override.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan artwork override: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+func (d *Database) SaveArtworkOverride(ctx context.Context, override *types.ArtworkOverride) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if override.UpdatedAt.IsZero() {
+		override.UpdatedAt = time.Now()
+	}
+	_, err := d.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO artwork_overrides (kind, slug, path, updated_at)
+		 VALUES (?, ?, ?, ?)`,
+		string(override.Kind), override.Slug, override.Path, override.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save artwork override: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) DeleteArtworkOverride(ctx context.Context, kind types.ArtworkKind, slug string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"DELETE FROM artwork_overrides WHERE kind = ? AND slug = ?", string(kind), slug,
+	)
+	if err != nil {
+		return fmt.Errorf("delete artwork override: %w", err)
+	}
+	return nil
+}
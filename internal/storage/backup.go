@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+// RestoreSuffix marks a database waiting next to the live one to replace it
+// the next time the database is opened, since an open database cannot be
+// swapped out from under the running application. The config restored with
+// it waits under the same suffix.
+const RestoreSuffix = config.RestoreSuffix
+
+// Snapshot writes a consistent copy of the database to dest, which must not
+// exist yet. Writes made meanwhile are not blocked.
+func (d *Database) Snapshot(ctx context.Context, dest string) error {
+	start := time.Now()
+	defer func() { d.debugLog("Snapshot", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "VACUUM INTO ?", dest); err != nil {
+		return fmt.Errorf("snapshot database: %w", err)
+	}
+	return nil
+}
+
+// applyPendingRestore moves a database restored from a backup into place,
+// dropping the write-ahead log that belonged to the old one. It reports
+// whether there was one.
+func applyPendingRestore(dbPath string) (bool, error) {
+	pending := dbPath + RestoreSuffix
+	if _, err := os.Stat(pending); err != nil {
+		return false, nil
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("remove %s: %w", suffix, err)
+		}
+	}
+	if err := os.Rename(pending, dbPath); err != nil {
+		return false, fmt.Errorf("replace database: %w", err)
+	}
+	log.Printf("Restored database from backup at %s", dbPath)
+	return true, nil
+}
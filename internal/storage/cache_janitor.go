@@ -0,0 +1,345 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+// janitorDirs are the folders under the cache directory kept within
+// Storage.MaxCacheSize. Artwork overrides were chosen by the user and are
+// never evicted.
+var janitorDirs = []string{"images", "songs", "collages"}
+
+const (
+	janitorStartDelay = 2 * time.Minute
+	janitorInterval   = 30 * time.Minute
+
+	// evictionTarget is the share of the budget a sweep shrinks the cache
+	// to, so the next few downloads do not start another sweep right away.
+	evictionTarget = 0.9
+	// evictionGrace spares files used this recently: a download still being
+	// written, or the song playing now.
+	evictionGrace = 10 * time.Minute
+)
+
+// CacheUsage is the disk space the cache takes, by kind of file.
+type CacheUsage struct {
+	Images    int64
+	Downloads int64
+	Other     int64
+	Files     int
+	Limit     int64
+}
+
+// Total is the space taken by every cached file.
+func (u CacheUsage) Total() int64 {
+	return u.Images + u.Downloads + u.Other
+}
+
+// cacheFile is a file tracked in cache_entries.
+type cacheFile struct {
+	path       string
+	size       int64
+	accessedAt time.Time
+}
+
+// CacheJanitor keeps the cache within Storage.MaxCacheSize by removing the
+// files used least recently. It tracks cached images and downloaded songs
+// in cache_entries; files found on disk without an entry are counted from
+// their modification time.
+type CacheJanitor struct {
+	db  *Database
+	cfg *config.Config
+
+	mu      sync.Mutex
+	onSweep func(freed int64, err error)
+}
+
+func NewCacheJanitor(db *Database, cfg *config.Config) *CacheJanitor {
+	return &CacheJanitor{db: db, cfg: cfg}
+}
+
+// OnSweep sets a callback for every scheduled sweep. It is called from the
+// janitor's goroutine.
+func (j *CacheJanitor) OnSweep(callback func(freed int64, err error)) {
+	j.onSweep = callback
+}
+
+// Run sweeps the cache shortly after startup and then periodically, until
+// ctx is done. Set OnSweep before.
+func (j *CacheJanitor) Run(ctx context.Context) {
+	timer := time.NewTimer(janitorStartDelay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		freed, err := j.Sweep(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[CACHE] Sweep failed: %v", err)
+		}
+		if j.onSweep != nil && ctx.Err() == nil {
+			j.onSweep(freed, err)
+		}
+		timer.Reset(janitorInterval)
+	}
+}
+
+// Sweep removes the least recently used files while the cache is over its
+// budget, down to a little below it, and returns the bytes freed. Removing
+// a downloaded song marks it as not downloaded.
+func (j *CacheJanitor) Sweep(ctx context.Context) (int64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	limit := j.cfg.Storage.MaxCacheSize
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	files, err := j.reconcile(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, file := range files {
+		total += file.size
+	}
+	if total <= limit {
+		return 0, nil
+	}
+
+	target := int64(float64(limit) * evictionTarget)
+	cutoff := time.Now().Add(-evictionGrace)
+	var freed int64
+	for _, file := range files {
+		if total-freed <= target || file.accessedAt.After(cutoff) {
+			break
+		}
+		if err := j.evict(ctx, file); err != nil {
+			log.Printf("[CACHE] Failed to evict %s: %v", file.path, err)
+			continue
+		}
+		freed += file.size
+	}
+
+	if j.cfg.Debug {
+		log.Printf("[CACHE] Freed %d of %d bytes to stay within %d", freed, total, limit)
+	}
+	return freed, nil
+}
+
+// Clear removes every cached file except downloaded songs and returns the
+// bytes freed.
+func (j *CacheJanitor) Clear(ctx context.Context) (int64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	files, err := j.reconcile(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var freed int64
+	for _, file := range files {
+		if j.isDownload(file.path) {
+			continue
+		}
+		if err := j.evict(ctx, file); err != nil {
+			log.Printf("[CACHE] Failed to remove %s: %v", file.path, err)
+			continue
+		}
+		freed += file.size
+	}
+	return freed, nil
+}
+
+// Usage returns the space the cache takes now.
+func (j *CacheJanitor) Usage(ctx context.Context) (CacheUsage, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	files, err := j.reconcile(ctx)
+	if err != nil {
+		return CacheUsage{}, err
+	}
+
+	usage := CacheUsage{Files: len(files), Limit: j.cfg.Storage.MaxCacheSize}
+	for _, file := range files {
+		switch j.area(file.path) {
+		case "songs":
+			usage.Downloads += file.size
+		case "images", "collages":
+			usage.Images += file.size
+		default:
+			usage.Other += file.size
+		}
+	}
+	return usage, nil
+}
+
+// reconcile brings cache_entries in line with the disk and returns the
+// tracked files, least recently used first. Entries whose file is gone are
+// dropped, and files in the cache folders without an entry are added.
+func (j *CacheJanitor) reconcile(ctx context.Context) ([]cacheFile, error) {
+	tracked, err := j.db.listCacheFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(tracked))
+	files := make([]cacheFile, 0, len(tracked))
+	for _, file := range tracked {
+		info, err := os.Stat(file.path)
+		if os.IsNotExist(err) {
+			if err := j.db.forgetCacheFile(ctx, file.path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		file.size = info.Size()
+		seen[filepath.Clean(file.path)] = true
+		files = append(files, file)
+	}
+
+	for _, dir := range janitorDirs {
+		root := filepath.Join(j.db.cacheDir, dir)
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if !entry.Type().IsRegular() || seen[filepath.Clean(path)] {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			file := cacheFile{path: path, size: info.Size(), accessedAt: info.ModTime()}
+			if err := j.db.addCacheFile(ctx, file); err != nil {
+				return err
+			}
+			files = append(files, file)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("scan cache folder %s: %w", dir, err)
+		}
+	}
+
+	sort.SliceStable(files, func(a, b int) bool {
+		return files[a].accessedAt.Before(files[b].accessedAt)
+	})
+	return files, nil
+}
+
+// evict removes a cached file and its entry.
+func (j *CacheJanitor) evict(ctx context.Context, file cacheFile) error {
+	if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove file: %w", err)
+	}
+	if j.isDownload(file.path) {
+		if err := j.db.forgetDownload(ctx, file.path); err != nil {
+			return err
+		}
+	}
+	return j.db.forgetCacheFile(ctx, file.path)
+}
+
+// area is the cache folder a path is in, or "" when it is outside them.
+func (j *CacheJanitor) area(path string) string {
+	rel, err := filepath.Rel(j.db.cacheDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	first, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return first
+}
+
+func (j *CacheJanitor) isDownload(path string) bool {
+	return j.area(path) == "songs"
+}
+
+func (d *Database) listCacheFiles(ctx context.Context) ([]cacheFile, error) {
+	start := time.Now()
+	defer func() { d.debugLog("listCacheFiles", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT local_path, size, accessed_at FROM cache_entries ORDER BY accessed_at")
+	if err != nil {
+		return nil, fmt.Errorf("query cache entries: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var files []cacheFile
+	for rows.Next() {
+		var file cacheFile
+		if err := rows.Scan(&file.path, &file.size, &file.accessedAt); err != nil {
+			return nil, fmt.Errorf("scan cache entry: %w", err)
+		}
+		files = append(files, file)
+	}
+	return files, rows.Err()
+}
+
+// addCacheFile tracks a file found in the cache folders. Its path stands in
+// for the URL it was fetched from, which is not known.
+func (d *Database) addCacheFile(ctx context.Context, file cacheFile) error {
+	_, err := d.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO cache_entries (
+			key, url, local_path, size, accessed_at, created_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`, file.path, file.path, file.path, file.size, file.accessedAt, file.accessedAt)
+	if err != nil {
+		return fmt.Errorf("add cache entry: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) forgetCacheFile(ctx context.Context, localPath string) error {
+	if _, err := d.db.ExecContext(ctx, "DELETE FROM cache_entries WHERE local_path = ?", localPath); err != nil {
+		return fmt.Errorf("delete cache entry: %w", err)
+	}
+	return nil
+}
+
+// forgetDownload marks the songs downloaded to localPath as no longer
+// downloaded.
+func (d *Database) forgetDownload(ctx context.Context, localPath string) error {
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE songs SET downloaded = 0, local_path = NULL, checksum = NULL WHERE local_path = ?",
+		localPath,
+	)
+	if err != nil {
+		return fmt.Errorf("forget download %s: %w", localPath, err)
+	}
+	return nil
+}
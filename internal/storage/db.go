@@ -42,6 +42,11 @@ func NewDatabase(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("create cache directory: %w", err)
 	}
 
+	restored, err := applyPendingRestore(cfg.Storage.DatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("restore database: %w", err)
+	}
+
 	db, err := openDatabase(cfg.Storage.DatabasePath, cfg.Storage.EnableWAL)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
@@ -60,6 +65,17 @@ func NewDatabase(cfg *config.Config) (*Database, error) {
 		return nil, fmt.Errorf("run migrations: %w", err)
 	}
 
+	if restored {
+		// The backup was written with VACUUM INTO, which may renumber the
+		// rowids the search index is keyed by.
+		if err := storage.RebuildSearchIndex(context.Background()); err != nil {
+			if closeErr := storage.Close(); closeErr != nil {
+				log.Printf("Failed to close database after search index error: %v", closeErr)
+			}
+			return nil, err
+		}
+	}
+
 	return storage, nil
 }
 
@@ -143,9 +159,11 @@ func (d *Database) GetSongs(ctx context.Context, limit, offset int) ([]*types.So
 		       COALESCE(a.name, '') as album_name, 
 		       COALESCE(a.image, '') as album_image, 
 		       COALESCE(a.image_cropped, '') as album_image_cropped, 
-		       COALESCE(a.link, '') as album_link
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist
 		FROM songs s
 		LEFT JOIN albums a ON s.album_slug = a.slug
+		WHERE s.deleted_at IS NULL
 		ORDER BY s.created_at DESC
 		LIMIT ? OFFSET ?
 	`
@@ -200,10 +218,11 @@ func (d *Database) GetSong(ctx context.Context, slug string) (*types.Song, error
 		       COALESCE(a.name, '') as album_name, 
 		       COALESCE(a.image, '') as album_image, 
 		       COALESCE(a.image_cropped, '') as album_image_cropped, 
-		       COALESCE(a.link, '') as album_link
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist
 		FROM songs s
 		LEFT JOIN albums a ON s.album_slug = a.slug
-		WHERE s.slug = ?
+		WHERE s.slug = ? AND s.deleted_at IS NULL
 	`
 
 	row := d.db.QueryRowContext(ctx, query, slug)
@@ -224,6 +243,73 @@ func (d *Database) GetSong(ctx context.Context, slug string) (*types.Song, error
 	return song, nil
 }
 
+// GetAlbumSongs returns the stored songs of an album, in the order they were
+// saved.
+func (d *Database) GetAlbumSongs(ctx context.Context, albumSlug string) ([]*types.Song, error) {
+	return d.querySongs(ctx, "GetAlbumSongs", `s.album_slug = ?`, albumSlug)
+}
+
+// GetAuthorSongs returns the stored songs an author is credited on, in the
+// order they were saved.
+func (d *Database) GetAuthorSongs(ctx context.Context, authorSlug string) ([]*types.Song, error) {
+	return d.querySongs(ctx, "GetAuthorSongs",
+		`s.slug IN (SELECT song_slug FROM song_authors WHERE author_slug = ?)`, authorSlug)
+}
+
+// querySongs returns the songs matching where, with their albums and authors.
+func (d *Database) querySongs(ctx context.Context, operation, where string, args ...interface{}) ([]*types.Song, error) {
+	start := time.Now()
+	defer func() { d.debugLog(operation, nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT s.slug, s.name, s.file, s.image, s.image_cropped, s.length,
+		       s.played, s.link, s.liked, s.volume, s.album_slug, s.local_path,
+		       s.downloaded, s.last_sync, s.created_at, s.updated_at,
+		       COALESCE(a.slug, '') as album_slug_ref,
+		       COALESCE(a.name, '') as album_name,
+		       COALESCE(a.image, '') as album_image,
+		       COALESCE(a.image_cropped, '') as album_image_cropped,
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist
+		FROM songs s
+		LEFT JOIN albums a ON s.album_slug = a.slug
+		WHERE s.deleted_at IS NULL AND ` + where + `
+		ORDER BY s.rowid
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		d.debugLog(operation, err, time.Since(start))
+		return nil, fmt.Errorf("query songs: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var songs []*types.Song
+	for rows.Next() {
+		song, err := d.scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan song: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadSongAuthors(ctx, songs); err != nil {
+		return nil, fmt.Errorf("load song authors: %w", err)
+	}
+	return songs, nil
+}
+
 func (d *Database) ensureAlbumInTx(ctx context.Context, tx *sql.Tx, slug, name string) error {
 	if slug == "" {
 		return nil
@@ -273,6 +359,53 @@ func (d *Database) SaveSong(ctx context.Context, song *types.Song) error {
 		}
 	}()
 
+	if err := d.saveSongInTx(ctx, tx, song); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveSongsBatch saves songs in a single transaction. Either all of them are
+// stored or, if any fails, none are.
+func (d *Database) SaveSongsBatch(ctx context.Context, songs []*types.Song) error {
+	start := time.Now()
+	var err error
+	defer func() {
+		d.debugLog(fmt.Sprintf("SaveSongsBatch(%d)", len(songs)), err, time.Since(start))
+	}()
+
+	if err = d.checkClosed(); err != nil {
+		return err
+	}
+	if len(songs) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+	})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	for _, song := range songs {
+		if err = d.saveSongInTx(ctx, tx, song); err != nil {
+			err = fmt.Errorf("song %s: %w", song.Slug, err)
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+func (d *Database) saveSongInTx(ctx context.Context, tx *sql.Tx, song *types.Song) error {
 	if song.Album != nil {
 		if err := d.saveAlbumInTx(ctx, tx, song.Album); err != nil {
 			return fmt.Errorf("save album: %w", err)
@@ -299,11 +432,24 @@ func (d *Database) SaveSong(ctx context.Context, song *types.Song) error {
 	}
 
 	query := `
-		INSERT OR REPLACE INTO songs (
+		INSERT INTO songs (
 			slug, name, file, image, image_cropped, length, played, link, 
 			liked, volume, album_slug, local_path, downloaded, last_sync, 
-			created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			created_at, updated_at, liked_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CASE WHEN ? = 1 THEN ? END)
+		ON CONFLICT(slug) DO UPDATE SET
+			name = excluded.name, file = excluded.file, image = excluded.image,
+			image_cropped = excluded.image_cropped, length = excluded.length,
+			played = MAX(songs.played, excluded.played), link = excluded.link,
+			liked = COALESCE((SELECT liked FROM pending_likes WHERE slug = excluded.slug), excluded.liked),
+			liked_at = CASE
+				WHEN COALESCE((SELECT liked FROM pending_likes WHERE slug = excluded.slug), excluded.liked) = 1
+				THEN COALESCE(songs.liked_at, excluded.liked_at)
+			END,
+			volume = excluded.volume, album_slug = excluded.album_slug,
+			local_path = excluded.local_path, downloaded = excluded.downloaded,
+			last_sync = excluded.last_sync, created_at = excluded.created_at,
+			updated_at = excluded.updated_at
 	`
 
 	now := time.Now()
@@ -312,11 +458,11 @@ func (d *Database) SaveSong(ctx context.Context, song *types.Song) error {
 	}
 	song.UpdatedAt = now
 
-	_, err = tx.ExecContext(ctx, query,
+	_, err := tx.ExecContext(ctx, query,
 		song.Slug, song.Name, song.File, song.Image, song.ImageCropped,
 		song.Length, song.Played, song.Link, song.Liked, volumeJSON,
 		song.AlbumSlug, song.LocalPath, song.Downloaded, song.LastSync,
-		song.CreatedAt, song.UpdatedAt,
+		song.CreatedAt, song.UpdatedAt, song.Liked, now,
 	)
 	if err != nil {
 		return fmt.Errorf("insert song: %w", err)
@@ -326,9 +472,11 @@ func (d *Database) SaveSong(ctx context.Context, song *types.Song) error {
 		return fmt.Errorf("save song authors: %w", err)
 	}
 
-	return tx.Commit()
+	return nil
 }
 
+// DeleteSong moves a song to the trash. It stays restorable until it is
+// purged after types.TrashRetention.
 func (d *Database) DeleteSong(ctx context.Context, slug string) error {
 	start := time.Now()
 	defer func() { d.debugLog("DeleteSong", nil, time.Since(start)) }()
@@ -337,10 +485,12 @@ func (d *Database) DeleteSong(ctx context.Context, slug string) error {
 		return err
 	}
 
-	_, err := d.db.ExecContext(ctx, "DELETE FROM songs WHERE slug = ?", slug)
-	return err
+	return d.moveToTrash(ctx, "songs", slug)
 }
 
+// SearchSongs returns the songs whose title, artists or album match query,
+// best matches first. Words match words starting with them and quoted
+// words match as a phrase; see ftsQuery.
 func (d *Database) SearchSongs(ctx context.Context, query string, limit int) ([]*types.Song, error) {
 	start := time.Now()
 	defer func() { d.debugLog("SearchSongs", nil, time.Since(start)) }()
@@ -349,6 +499,11 @@ func (d *Database) SearchSongs(ctx context.Context, query string, limit int) ([]
 		return nil, err
 	}
 
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
 	searchQuery := `
 		SELECT s.slug, s.name, s.file, s.image, s.image_cropped, s.length, 
 		       s.played, s.link, s.liked, s.volume, s.album_slug, s.local_path, 
@@ -357,20 +512,17 @@ func (d *Database) SearchSongs(ctx context.Context, query string, limit int) ([]
 		       COALESCE(a.name, '') as album_name, 
 		       COALESCE(a.image, '') as album_image, 
 		       COALESCE(a.image_cropped, '') as album_image_cropped, 
-		       COALESCE(a.link, '') as album_link
-		FROM songs s
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist
+		FROM songs_fts
+		JOIN songs s ON s.rowid = songs_fts.rowid
 		LEFT JOIN albums a ON s.album_slug = a.slug
-		WHERE s.name LIKE ? OR EXISTS (
-			SELECT 1 FROM song_authors sa 
-			JOIN authors au ON sa.author_slug = au.slug 
-			WHERE sa.song_slug = s.slug AND au.name LIKE ?
-		)
-		ORDER BY s.created_at DESC
+		WHERE songs_fts MATCH ? AND s.deleted_at IS NULL
+		ORDER BY ` + songRank + `, s.played DESC
 		LIMIT ?
 	`
 
-	searchPattern := "%" + query + "%"
-	rows, err := d.db.QueryContext(ctx, searchQuery, searchPattern, searchPattern, limit)
+	rows, err := d.db.QueryContext(ctx, searchQuery, match, limit)
 	if err != nil {
 		d.debugLog("SearchSongs", err, time.Since(start))
 		return nil, fmt.Errorf("search songs: %w", err)
@@ -390,6 +542,10 @@ func (d *Database) SearchSongs(ctx context.Context, query string, limit int) ([]
 		}
 		songs = append(songs, song)
 	}
+	if err := rows.Err(); err != nil {
+		d.debugLog("SearchSongs", err, time.Since(start))
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
 
 	if err := d.loadSongAuthors(ctx, songs); err != nil {
 		d.debugLog("SearchSongs", err, time.Since(start))
@@ -408,7 +564,7 @@ func (d *Database) GetAlbums(ctx context.Context, limit, offset int) ([]*types.A
 	}
 
 	query := `
-		SELECT slug, name, image, image_cropped, link, last_sync, created_at, updated_at
+		SELECT slug, name, image, image_cropped, link, album_artist, last_sync, created_at, updated_at
 		FROM albums
 		ORDER BY created_at DESC
 		LIMIT ? OFFSET ?
@@ -435,6 +591,11 @@ func (d *Database) GetAlbums(ctx context.Context, limit, offset int) ([]*types.A
 		albums = append(albums, album)
 	}
 
+	if err := d.loadAlbumArtists(ctx, albums); err != nil {
+		d.debugLog("GetAlbums", err, time.Since(start))
+		return nil, fmt.Errorf("load album artists: %w", err)
+	}
+
 	return albums, nil
 }
 
@@ -447,7 +608,7 @@ func (d *Database) GetAlbum(ctx context.Context, slug string) (*types.Album, err
 	}
 
 	query := `
-		SELECT slug, name, image, image_cropped, link, last_sync, created_at, updated_at
+		SELECT slug, name, image, image_cropped, link, album_artist, last_sync, created_at, updated_at
 		FROM albums
 		WHERE slug = ?
 	`
@@ -462,6 +623,11 @@ func (d *Database) GetAlbum(ctx context.Context, slug string) (*types.Album, err
 		return nil, fmt.Errorf("scan album: %w", err)
 	}
 
+	if err := d.loadAlbumArtists(ctx, []*types.Album{album}); err != nil {
+		d.debugLog("GetAlbum", err, time.Since(start))
+		return nil, fmt.Errorf("load album artists: %w", err)
+	}
+
 	return album, nil
 }
 
@@ -473,30 +639,40 @@ func (d *Database) SaveAlbum(ctx context.Context, album *types.Album) error {
 		return err
 	}
 
-	query := `
-		INSERT OR REPLACE INTO albums (
-			slug, name, image, image_cropped, link, last_sync, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
 
-	now := time.Now()
-	if album.CreatedAt.IsZero() {
-		album.CreatedAt = now
+	if err := d.saveAlbumInTx(ctx, tx, album); err != nil {
+		return err
 	}
-	album.UpdatedAt = now
 
-	_, err := d.db.ExecContext(ctx, query,
-		album.Slug, album.Name, album.Image, album.ImageCropped,
-		album.Link, album.LastSync, album.CreatedAt, album.UpdatedAt,
-	)
-	return err
+	return tx.Commit()
 }
 
+// saveAlbumInTx upserts the album. Partial album objects embedded in songs
+// carry neither artists nor an album artist, so those are only replaced when
+// the incoming album has them.
 func (d *Database) saveAlbumInTx(ctx context.Context, tx *sql.Tx, album *types.Album) error {
 	query := `
-		INSERT OR REPLACE INTO albums (
-			slug, name, image, image_cropped, link, last_sync, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO albums (
+			slug, name, image, image_cropped, link, album_artist, last_sync, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET
+			name = excluded.name,
+			image = excluded.image,
+			image_cropped = excluded.image_cropped,
+			link = excluded.link,
+			album_artist = CASE WHEN excluded.album_artist != '' THEN excluded.album_artist ELSE albums.album_artist END,
+			last_sync = excluded.last_sync,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
 	`
 
 	now := time.Now()
@@ -505,11 +681,23 @@ func (d *Database) saveAlbumInTx(ctx context.Context, tx *sql.Tx, album *types.A
 	}
 	album.UpdatedAt = now
 
+	albumArtist := album.AlbumArtist
+	if albumArtist == "" && album.IsCompilation() {
+		albumArtist = types.VariousArtists
+	}
+
 	_, err := tx.ExecContext(ctx, query,
 		album.Slug, album.Name, album.Image, album.ImageCropped,
-		album.Link, album.LastSync, album.CreatedAt, album.UpdatedAt,
+		album.Link, albumArtist, album.LastSync, album.CreatedAt, album.UpdatedAt,
 	)
-	return err
+	if err != nil {
+		return fmt.Errorf("upsert album: %w", err)
+	}
+
+	if len(album.Artists) == 0 {
+		return nil
+	}
+	return d.saveAlbumArtists(ctx, tx, album)
 }
 
 func (d *Database) GetAuthors(ctx context.Context, limit, offset int) ([]*types.Author, error) {
@@ -587,9 +775,17 @@ func (d *Database) SaveAuthor(ctx context.Context, author *types.Author) error {
 	}
 
 	query := `
-		INSERT OR REPLACE INTO authors (
+		INSERT INTO authors (
 			slug, name, image, image_cropped, link, last_sync, created_at, updated_at
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET
+			name = excluded.name,
+			image = excluded.image,
+			image_cropped = excluded.image_cropped,
+			link = excluded.link,
+			last_sync = excluded.last_sync,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
 	`
 
 	now := time.Now()
@@ -605,11 +801,21 @@ func (d *Database) SaveAuthor(ctx context.Context, author *types.Author) error {
 	return err
 }
 
+// saveAuthorInTx upserts rather than replaces, since a REPLACE deletes the row
+// and cascades to the song_authors and album_artists links.
 func (d *Database) saveAuthorInTx(ctx context.Context, tx *sql.Tx, author *types.Author) error {
 	query := `
-		INSERT OR REPLACE INTO authors (
+		INSERT INTO authors (
 			slug, name, image, image_cropped, link, last_sync, created_at, updated_at
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET
+			name = excluded.name,
+			image = excluded.image,
+			image_cropped = excluded.image_cropped,
+			link = excluded.link,
+			last_sync = excluded.last_sync,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at
 	`
 
 	now := time.Now()
@@ -636,6 +842,7 @@ func (d *Database) GetPlaylists(ctx context.Context) ([]*types.Playlist, error)
 	query := `
 		SELECT slug, name, private, length, local_only, last_sync, created_at, updated_at
 		FROM playlists
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -674,7 +881,7 @@ func (d *Database) GetPlaylist(ctx context.Context, slug string) (*types.Playlis
 	query := `
 		SELECT slug, name, private, length, local_only, last_sync, created_at, updated_at
 		FROM playlists
-		WHERE slug = ?
+		WHERE slug = ? AND deleted_at IS NULL
 	`
 
 	row := d.db.QueryRowContext(ctx, query, slug)
@@ -717,9 +924,13 @@ func (d *Database) SavePlaylist(ctx context.Context, playlist *types.Playlist) e
 	}()
 
 	query := `
-		INSERT OR REPLACE INTO playlists (
+		INSERT INTO playlists (
 			slug, name, private, length, local_only, last_sync, created_at, updated_at
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET
+			name = excluded.name, private = excluded.private, length = excluded.length,
+			local_only = excluded.local_only, last_sync = excluded.last_sync,
+			created_at = excluded.created_at, updated_at = excluded.updated_at
 	`
 
 	now := time.Now()
@@ -745,6 +956,28 @@ func (d *Database) SavePlaylist(ctx context.Context, playlist *types.Playlist) e
 	return tx.Commit()
 }
 
+// UpdatePlaylistDetails changes the name and privacy of a stored playlist
+// without touching its songs.
+func (d *Database) UpdatePlaylistDetails(ctx context.Context, slug, name string, private bool) error {
+	start := time.Now()
+	defer func() { d.debugLog("UpdatePlaylistDetails", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE playlists SET name = ?, private = ?, updated_at = ? WHERE slug = ?",
+		name, private, time.Now(), slug,
+	)
+	if err != nil {
+		return fmt.Errorf("update playlist: %w", err)
+	}
+	return nil
+}
+
+// DeletePlaylist moves a playlist to the trash. It stays restorable until it
+// is purged after types.TrashRetention.
 func (d *Database) DeletePlaylist(ctx context.Context, slug string) error {
 	start := time.Now()
 	defer func() { d.debugLog("DeletePlaylist", nil, time.Since(start)) }()
@@ -753,8 +986,46 @@ func (d *Database) DeletePlaylist(ctx context.Context, slug string) error {
 		return err
 	}
 
-	_, err := d.db.ExecContext(ctx, "DELETE FROM playlists WHERE slug = ?", slug)
-	return err
+	return d.moveToTrash(ctx, "playlists", slug)
+}
+
+// GetPlaylistTransition returns the transition preference for a playlist, or
+// the normal transition when none was chosen.
+func (d *Database) GetPlaylistTransition(ctx context.Context, slug string) (*types.PlaylistTransition, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	transition := &types.PlaylistTransition{PlaylistSlug: slug, Mode: types.TransitionNormal}
+	var mode string
+	err := d.db.QueryRowContext(ctx,
+		"SELECT mode, seconds FROM playlist_transitions WHERE playlist_slug = ?", slug,
+	).Scan(&mode, &transition.Seconds)
+	if err == sql.ErrNoRows {
+		return transition, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query playlist transition: %w", err)
+	}
+
+	transition.Mode = types.TransitionMode(mode)
+	return transition, nil
+}
+
+func (d *Database) SavePlaylistTransition(ctx context.Context, transition *types.PlaylistTransition) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO playlist_transitions (playlist_slug, mode, seconds, updated_at)
+		 VALUES (?, ?, ?, ?)`,
+		transition.PlaylistSlug, string(transition.Mode), transition.Seconds, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("save playlist transition: %w", err)
+	}
+	return nil
 }
 
 func (d *Database) GetCachedFile(ctx context.Context, url string) (string, error) {
@@ -846,6 +1117,79 @@ func (d *Database) SaveCachedFile(ctx context.Context, url string, data io.Reade
 	return localPath, nil
 }
 
+// DeleteCachedFile forgets the cached copy of url and removes it from disk.
+func (d *Database) DeleteCachedFile(ctx context.Context, url string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	var localPath string
+	err := d.db.QueryRowContext(ctx, "SELECT local_path FROM cache_entries WHERE url = ?", url).Scan(&localPath)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get cached file: %w", err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, "DELETE FROM cache_entries WHERE url = ?", url); err != nil {
+		return fmt.Errorf("delete cache entry: %w", err)
+	}
+	if err := os.Remove(localPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove cached file: %w", err)
+	}
+	return nil
+}
+
+// TrackCacheFile records a file already written to the cache as the copy of
+// url, so the cache janitor counts it and knows when it was last used. An
+// older copy of url at another path is removed.
+func (d *Database) TrackCacheFile(ctx context.Context, url, localPath string, size int64) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	var previous string
+	err := d.db.QueryRowContext(ctx, "SELECT local_path FROM cache_entries WHERE url = ?", url).Scan(&previous)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("get cached file: %w", err)
+	}
+	if previous != "" && previous != localPath {
+		if err := os.Remove(previous); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove previous cached copy %s: %v", previous, err)
+		}
+	}
+
+	now := time.Now()
+	_, err = d.db.ExecContext(ctx, `
+		INSERT OR REPLACE INTO cache_entries (
+			key, url, local_path, size, accessed_at, created_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+	`, localPath, url, localPath, size, now, now)
+	if err != nil {
+		return fmt.Errorf("save cache entry: %w", err)
+	}
+	return nil
+}
+
+// TouchCacheFile marks the cached file at localPath as just used. Files the
+// cache does not track, such as songs in a local library folder, are
+// ignored.
+func (d *Database) TouchCacheFile(ctx context.Context, localPath string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE cache_entries SET accessed_at = ? WHERE local_path = ?",
+		time.Now(), localPath,
+	)
+	if err != nil {
+		return fmt.Errorf("touch cache entry: %w", err)
+	}
+	return nil
+}
+
 func (d *Database) Close() error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -871,7 +1215,7 @@ func (d *Database) scanSong(scanner interface{ Scan(...any) error }) (*types.Son
 	var volumeJSON string
 	var albumSlug sql.NullString
 	var albumSlugRef, albumName,
-		albumImage, albumImageCropped, albumLink string
+		albumImage, albumImageCropped, albumLink, albumArtist string
 
 	err := scanner.Scan(
 		&song.Slug, &song.Name, &song.File, &song.Image, &song.ImageCropped,
@@ -879,6 +1223,7 @@ func (d *Database) scanSong(scanner interface{ Scan(...any) error }) (*types.Son
 		&albumSlug, &song.LocalPath, &song.Downloaded, &song.LastSync,
 		&song.CreatedAt, &song.UpdatedAt,
 		&albumSlugRef, &albumName, &albumImage, &albumImageCropped, &albumLink,
+		&albumArtist,
 	)
 	if err != nil {
 		return nil, err
@@ -901,6 +1246,7 @@ func (d *Database) scanSong(scanner interface{ Scan(...any) error }) (*types.Son
 			Image:        stringToPtr(albumImage),
 			ImageCropped: stringToPtr(albumImageCropped),
 			Link:         albumLink,
+			AlbumArtist:  albumArtist,
 		}
 	}
 
@@ -914,7 +1260,7 @@ func (d *Database) scanAlbum(scanner interface {
 
 	err := scanner.Scan(
 		&album.Slug, &album.Name, &album.Image, &album.ImageCropped,
-		&album.Link, &album.LastSync, &album.CreatedAt, &album.UpdatedAt,
+		&album.Link, &album.AlbumArtist, &album.LastSync, &album.CreatedAt, &album.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -969,11 +1315,11 @@ func (d *Database) loadSongAuthors(ctx context.Context, songs []*types.Song) err
 	placeholders = placeholders[:len(placeholders)-1]
 
 	query := fmt.Sprintf(`
-		SELECT sa.song_slug, a.slug, a.name, COALESCE(a.image_cropped, '') as image_cropped
+		SELECT sa.song_slug, a.slug, a.name, COALESCE(a.image_cropped, '') as image_cropped, sa.role
 		FROM song_authors sa
 		JOIN authors a ON sa.author_slug = a.slug
 		WHERE sa.song_slug IN (%s)
-		ORDER BY sa.song_slug
+		ORDER BY sa.song_slug, sa.rowid
 	`, placeholders)
 
 	args := make([]interface{}, len(slugs))
@@ -993,9 +1339,9 @@ func (d *Database) loadSongAuthors(ctx context.Context, songs []*types.Song) err
 
 	authorMap := make(map[string][]*types.Author)
 	for rows.Next() {
-		var songSlug, authorSlug, authorName, authorImage string
+		var songSlug, authorSlug, authorName, authorImage, role string
 
-		if err := rows.Scan(&songSlug, &authorSlug, &authorName, &authorImage); err != nil {
+		if err := rows.Scan(&songSlug, &authorSlug, &authorName, &authorImage, &role); err != nil {
 			return fmt.Errorf("scan song author: %w", err)
 		}
 
@@ -1003,6 +1349,7 @@ func (d *Database) loadSongAuthors(ctx context.Context, songs []*types.Song) err
 			Slug:         authorSlug,
 			Name:         authorName,
 			ImageCropped: stringToPtr(authorImage),
+			Role:         types.AuthorRole(role),
 		}
 
 		authorMap[songSlug] = append(authorMap[songSlug], author)
@@ -1026,8 +1373,8 @@ func (d *Database) saveSongAuthors(ctx context.Context, tx *sql.Tx, song *types.
 		}
 
 		_, err := tx.ExecContext(ctx,
-			"INSERT OR IGNORE INTO song_authors (song_slug, author_slug) VALUES (?, ?)",
-			song.Slug, author.Slug,
+			"INSERT OR IGNORE INTO song_authors (song_slug, author_slug, role) VALUES (?, ?, ?)",
+			song.Slug, author.Slug, string(author.CreditRole()),
 		)
 		if err != nil {
 			return fmt.Errorf("insert song author: %w", err)
@@ -1037,6 +1384,84 @@ func (d *Database) saveSongAuthors(ctx context.Context, tx *sql.Tx, song *types.
 	return nil
 }
 
+func (d *Database) loadAlbumArtists(ctx context.Context, albums []*types.Album) error {
+	if len(albums) == 0 {
+		return nil
+	}
+
+	placeholders := strings.Repeat("?,", len(albums))
+	placeholders = placeholders[:len(placeholders)-1]
+
+	query := fmt.Sprintf(`
+		SELECT aa.album_slug, a.slug, a.name, COALESCE(a.image_cropped, '') as image_cropped
+		FROM album_artists aa
+		JOIN authors a ON aa.author_slug = a.slug
+		WHERE aa.album_slug IN (%s)
+		ORDER BY aa.album_slug
+	`, placeholders)
+
+	args := make([]interface{}, len(albums))
+	for i, album := range albums {
+		args[i] = album.Slug
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("query album artists: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	artistMap := make(map[string][]*types.Author)
+	for rows.Next() {
+		var albumSlug, authorSlug, authorName, authorImage string
+
+		if err := rows.Scan(&albumSlug, &authorSlug, &authorName, &authorImage); err != nil {
+			return fmt.Errorf("scan album artist: %w", err)
+		}
+
+		artistMap[albumSlug] = append(artistMap[albumSlug], &types.Author{
+			Slug:         authorSlug,
+			Name:         authorName,
+			ImageCropped: stringToPtr(authorImage),
+		})
+	}
+
+	for _, album := range albums {
+		album.Artists = artistMap[album.Slug]
+	}
+
+	return nil
+}
+
+func (d *Database) saveAlbumArtists(ctx context.Context, tx *sql.Tx, album *types.Album) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM album_artists WHERE album_slug = ?", album.Slug); err != nil {
+		return fmt.Errorf("delete old album artists: %w", err)
+	}
+
+	for _, artist := range album.Artists {
+		if artist == nil || artist.Slug == "" {
+			continue
+		}
+		if err := d.saveAuthorInTx(ctx, tx, artist); err != nil {
+			return fmt.Errorf("save artist: %w", err)
+		}
+
+		_, err := tx.ExecContext(ctx,
+			"INSERT OR IGNORE INTO album_artists (album_slug, author_slug) VALUES (?, ?)",
+			album.Slug, artist.Slug,
+		)
+		if err != nil {
+			return fmt.Errorf("insert album artist: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (d *Database) loadPlaylistSongs(ctx context.Context, playlist *types.Playlist) error {
 	query := `
 		SELECT s.slug, s.name, s.file, s.image, s.image_cropped, s.length, 
@@ -1046,11 +1471,12 @@ func (d *Database) loadPlaylistSongs(ctx context.Context, playlist *types.Playli
 		       COALESCE(a.name, '') as album_name, 
 		       COALESCE(a.image, '') as album_image, 
 		       COALESCE(a.image_cropped, '') as album_image_cropped, 
-		       COALESCE(a.link, '') as album_link
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist
 		FROM playlist_songs ps
 		JOIN songs s ON ps.song_slug = s.slug
 		LEFT JOIN albums a ON s.album_slug = a.slug
-		WHERE ps.playlist_slug = ?
+		WHERE ps.playlist_slug = ? AND s.deleted_at IS NULL
 		ORDER BY ps.position
 	`
 
@@ -1082,15 +1508,27 @@ func (d *Database) loadPlaylistSongs(ctx context.Context, playlist *types.Playli
 	return nil
 }
 
+// savePlaylistSongs replaces the playlist's entries. Songs that were
+// already in it keep when they were added; new ones are dated now.
 func (d *Database) savePlaylistSongs(ctx context.Context, tx *sql.Tx, playlist *types.Playlist) error {
+	added, err := playlistAddedAt(ctx, tx, playlist.Slug)
+	if err != nil {
+		return err
+	}
+
 	if _, err := tx.ExecContext(ctx, "DELETE FROM playlist_songs WHERE playlist_slug = ?", playlist.Slug); err != nil {
 		return fmt.Errorf("delete old playlist songs: %w", err)
 	}
 
+	now := time.Now()
 	for i, song := range playlist.Songs {
+		var addedAt interface{} = now
+		if at, ok := added[song.Slug]; ok {
+			addedAt = at
+		}
 		_, err := tx.ExecContext(ctx,
-			"INSERT INTO playlist_songs (playlist_slug, song_slug, position) VALUES (?, ?, ?)",
-			playlist.Slug, song.Slug, i,
+			"INSERT INTO playlist_songs (playlist_slug, song_slug, position, added_at) VALUES (?, ?, ?, ?)",
+			playlist.Slug, song.Slug, i, addedAt,
 		)
 		if err != nil {
 			return fmt.Errorf("insert playlist song: %w", err)
@@ -1100,22 +1538,59 @@ func (d *Database) savePlaylistSongs(ctx context.Context, tx *sql.Tx, playlist *
 	return nil
 }
 
-func stringToPtr(s string) *string {
-	if s == "" {
-		return nil
+// GetPlaylistAddedAt returns when each song was added to a playlist, keyed
+// by song slug. Entries stored before this was recorded are left out.
+func (d *Database) GetPlaylistAddedAt(ctx context.Context, slug string) (map[string]time.Time, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
 	}
-	return &s
+
+	added, err := playlistAddedAt(ctx, d.db, slug)
+	if err != nil {
+		return nil, err
+	}
+	dated := make(map[string]time.Time, len(added))
+	for songSlug, at := range added {
+		if at.Valid {
+			dated[songSlug] = at.Time
+		}
+	}
+	return dated, nil
 }
 
-func (d *Database) AddPlayHistory(ctx context.Context, songSlug string, userID *string) error {
-	if err := d.checkClosed(); err != nil {
-		return err
+// queryer is what *sql.DB and *sql.Tx share for reads.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// playlistAddedAt reads the added_at of every entry of a playlist, undated
+// ones included, so a rewrite can keep them as they were.
+func playlistAddedAt(ctx context.Context, q queryer, slug string) (map[string]sql.NullTime, error) {
+	rows, err := q.QueryContext(ctx, "SELECT song_slug, added_at FROM playlist_songs WHERE playlist_slug = ?", slug)
+	if err != nil {
+		return nil, fmt.Errorf("query playlist added dates: %w", err)
 	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
 
-	_, err := d.db.ExecContext(ctx,
-		`INSERT INTO play_history (song_slug, user_id, played_at, synced, created_at)
-         VALUES (?, ?, CURRENT_TIMESTAMP, false, CURRENT_TIMESTAMP)`,
-		songSlug, userID,
-	)
-	return err
+	added := make(map[string]sql.NullTime)
+	for rows.Next() {
+		var songSlug string
+		var at sql.NullTime
+		if err := rows.Scan(&songSlug, &at); err != nil {
+			return nil, fmt.Errorf("scan playlist added date: %w", err)
+		}
+		added[songSlug] = at
+	}
+	return added, rows.Err()
+}
+
+func stringToPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
 }
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+var benchWords = []string{
+	"midnight", "river", "echo", "neon", "summer", "glass", "velvet", "static",
+	"horizon", "ember", "silver", "hollow", "paper", "thunder", "violet", "drift",
+}
+
+func newTestDatabase(tb testing.TB) *Database {
+	tb.Helper()
+
+	dir := tb.TempDir()
+	cfg := &config.Config{}
+	cfg.Storage.DatabasePath = filepath.Join(dir, "test.db")
+	cfg.Storage.CacheDir = filepath.Join(dir, "cache")
+	cfg.Storage.EnableWAL = true
+
+	db, err := NewDatabase(cfg)
+	if err != nil {
+		tb.Fatalf("open database: %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+	return db
+}
+
+// benchSongs builds n songs spread over albums of ten, with names drawn from
+// benchWords so searches have something to match. prefix keeps slugs apart
+// between calls.
+func benchSongs(prefix string, n int) []*types.Song {
+	created := time.Now().Add(-365 * 24 * time.Hour)
+	songs := make([]*types.Song, n)
+	for i := range songs {
+		author := &types.Author{
+			Slug: fmt.Sprintf("bench-author-%d", i%50),
+			Name: benchWords[i%len(benchWords)] + " band",
+		}
+		album := &types.Album{
+			Slug:        fmt.Sprintf("%s-album-%d", prefix, i/10),
+			Name:        benchWords[(i/10)%len(benchWords)] + " sessions",
+			Artists:     []*types.Author{author},
+			AlbumArtist: author.Name,
+		}
+		songs[i] = &types.Song{
+			Slug:      fmt.Sprintf("%s-song-%d", prefix, i),
+			Name:      benchWords[i%len(benchWords)] + " " + benchWords[(i*7)%len(benchWords)],
+			File:      fmt.Sprintf("https://example.com/songs/%d.mp3", i),
+			Length:    120 + i%300,
+			Album:     album,
+			Authors:   []*types.Author{author},
+			CreatedAt: created.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return songs
+}
+
+func BenchmarkSaveSongsBatch(b *testing.B) {
+	const batchSize = 100
+
+	db := newTestDatabase(b)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		songs := benchSongs(fmt.Sprintf("batch%d", i), batchSize)
+		b.StartTimer()
+
+		if err := db.SaveSongsBatch(ctx, songs); err != nil {
+			b.Fatalf("save batch: %v", err)
+		}
+	}
+	b.ReportMetric(float64(b.N*batchSize)/b.Elapsed().Seconds(), "songs/s")
+}
+
+func BenchmarkSearchSongs(b *testing.B) {
+	db := newTestDatabase(b)
+	ctx := context.Background()
+
+	if err := db.SaveSongsBatch(ctx, benchSongs("search", 2000)); err != nil {
+		b.Fatalf("seed songs: %v", err)
+	}
+
+	queries := []string{"river", "hol", "velvet thunder", "zzznothing"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.SearchSongs(ctx, queries[i%len(queries)], 50); err != nil {
+			b.Fatalf("search: %v", err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DownloadItem is a download that has not finished, kept so it can be
+// resumed after a restart.
+type DownloadItem struct {
+	URL       string
+	LocalPath string
+	Title     string
+	// SongSlug is the song being downloaded, empty for other files.
+	SongSlug string
+	// Status is the download's state: pending, downloading, paused or
+	// failed.
+	Status    string
+	Error     string
+	Progress  float64
+	Total     int64
+	CreatedAt time.Time
+}
+
+// SaveDownloadItem stores item, replacing the saved state of the same URL.
+func (d *Database) SaveDownloadItem(ctx context.Context, item *DownloadItem) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO download_items (
+			url, local_path, title, song_slug, status, error, progress, total, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(url) DO UPDATE SET
+			local_path = excluded.local_path,
+			title = excluded.title,
+			song_slug = excluded.song_slug,
+			status = excluded.status,
+			error = excluded.error,
+			progress = excluded.progress,
+			total = excluded.total
+	`, item.URL, item.LocalPath, item.Title, item.SongSlug, item.Status,
+		item.Error, item.Progress, item.Total, item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("save download %s: %w", item.URL, err)
+	}
+	return nil
+}
+
+// GetDownloadItems returns the saved downloads, oldest first.
+func (d *Database) GetDownloadItems(ctx context.Context) ([]*DownloadItem, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetDownloadItems", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT url, local_path, COALESCE(title, ''), COALESCE(song_slug, ''),
+		       COALESCE(status, 'pending'), COALESCE(error, ''),
+		       COALESCE(progress, 0), COALESCE(total, 0), created_at
+		FROM download_items
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query downloads: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var items []*DownloadItem
+	for rows.Next() {
+		item := &DownloadItem{}
+		var createdAt sql.NullTime
+		if err := rows.Scan(&item.URL, &item.LocalPath, &item.Title, &item.SongSlug,
+			&item.Status, &item.Error, &item.Progress, &item.Total, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan download: %w", err)
+		}
+		item.CreatedAt = createdAt.Time
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return items, nil
+}
+
+// DeleteDownloadItem forgets the saved state of the download of url.
+func (d *Database) DeleteDownloadItem(ctx context.Context, url string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "DELETE FROM download_items WHERE url = ?", url); err != nil {
+		return fmt.Errorf("delete download %s: %w", url, err)
+	}
+	return nil
+}
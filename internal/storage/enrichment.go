@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Albums and authors synced as part of a song carry no song list. The views
+// column counts how often one was shown, and detailed_at records when its
+// full details were last fetched, so the most viewed stubs can be filled in
+// first.
+
+// RecordAlbumViews counts one view for each of the albums.
+func (d *Database) RecordAlbumViews(ctx context.Context, slugs []string) error {
+	return d.recordViews(ctx, "albums", slugs)
+}
+
+// RecordAuthorViews counts one view for each of the authors.
+func (d *Database) RecordAuthorViews(ctx context.Context, slugs []string) error {
+	return d.recordViews(ctx, "authors", slugs)
+}
+
+// MarkAlbumDetailed records that the album's full details were just saved.
+func (d *Database) MarkAlbumDetailed(ctx context.Context, slug string) error {
+	return d.markDetailed(ctx, "albums", slug)
+}
+
+// MarkAuthorDetailed records that the author's full details were just saved.
+func (d *Database) MarkAuthorDetailed(ctx context.Context, slug string) error {
+	return d.markDetailed(ctx, "authors", slug)
+}
+
+// StubAlbums returns up to limit viewed albums whose details were never
+// fetched, or not since staleBefore, most viewed first.
+func (d *Database) StubAlbums(ctx context.Context, staleBefore time.Time, limit int) ([]string, error) {
+	return d.stubs(ctx, "albums", staleBefore, limit)
+}
+
+// StubAuthors returns up to limit viewed authors whose details were never
+// fetched, or not since staleBefore, most viewed first.
+func (d *Database) StubAuthors(ctx context.Context, staleBefore time.Time, limit int) ([]string, error) {
+	return d.stubs(ctx, "authors", staleBefore, limit)
+}
+
+func (d *Database) recordViews(ctx context.Context, table string, slugs []string) error {
+	if len(slugs) == 0 {
+		return nil
+	}
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	placeholders := strings.Repeat("?,", len(slugs))
+	placeholders = placeholders[:len(placeholders)-1]
+	args := make([]interface{}, len(slugs))
+	for i, slug := range slugs {
+		args[i] = slug
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET views = views + 1 WHERE slug IN (%s)", table, placeholders)
+	if _, err := d.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("record %s views: %w", table, err)
+	}
+	return nil
+}
+
+func (d *Database) markDetailed(ctx context.Context, table, slug string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET detailed_at = ? WHERE slug = ?", table)
+	if _, err := d.db.ExecContext(ctx, query, time.Now(), slug); err != nil {
+		return fmt.Errorf("mark %s %s detailed: %w", table, slug, err)
+	}
+	return nil
+}
+
+func (d *Database) stubs(ctx context.Context, table string, staleBefore time.Time, limit int) ([]string, error) {
+	start := time.Now()
+	defer func() { d.debugLog("stubs "+table, nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT slug FROM %s
+		WHERE views > 0 AND (detailed_at IS NULL OR detailed_at < ?)
+		ORDER BY views DESC, slug
+		LIMIT ?
+	`, table)
+	rows, err := d.db.QueryContext(ctx, query, staleBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query stub %s: %w", table, err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("scan stub %s: %w", table, err)
+		}
+		slugs = append(slugs, slug)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return slugs, nil
+}
+
+// AlbumDetailedAt returns when the album's full details were last saved, or
+// the zero time if they never were.
+func (d *Database) AlbumDetailedAt(ctx context.Context, slug string) (time.Time, error) {
+	return d.detailedAt(ctx, "albums", slug)
+}
+
+// AuthorDetailedAt returns when the author's full details were last saved,
+// or the zero time if they never were.
+func (d *Database) AuthorDetailedAt(ctx context.Context, slug string) (time.Time, error) {
+	return d.detailedAt(ctx, "authors", slug)
+}
+
+func (d *Database) detailedAt(ctx context.Context, table, slug string) (time.Time, error) {
+	if err := d.checkClosed(); err != nil {
+		return time.Time{}, err
+	}
+
+	var at sql.NullTime
+	query := fmt.Sprintf("SELECT detailed_at FROM %s WHERE slug = ?", table)
+	err := d.db.QueryRowContext(ctx, query, slug).Scan(&at)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("get %s %s detailed_at: %w", table, slug, err)
+	}
+	return at.Time, nil
+}
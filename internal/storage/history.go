@@ -0,0 +1,465 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// SessionIdleGap is the pause after which playback starts a new listening session.
+const SessionIdleGap = 30 * time.Minute
+
+func newSessionID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("s%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// CreateListeningSession opens a new session that subsequent events attach to.
+func (d *Database) CreateListeningSession(ctx context.Context, source types.PlaySource, startedAt time.Time) (*types.ListeningSession, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	if source.Type == "" {
+		source.Type = types.PlaySourceLibrary
+	}
+
+	session := &types.ListeningSession{
+		ID:        newSessionID(),
+		StartedAt: startedAt,
+		EndedAt:   startedAt,
+		Source:    source,
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		`INSERT INTO listening_sessions (id, started_at, ended_at, source_type, source_id, source_name)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		session.ID, session.StartedAt, session.EndedAt, source.Type, source.ID, source.Name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert listening session: %w", err)
+	}
+
+	return session, nil
+}
+
+// AddPlayEvent appends an event to the history log and extends its session.
+func (d *Database) AddPlayEvent(ctx context.Context, event *types.PlayEvent) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+	if event.Source.Type == "" {
+		event.Source.Type = types.PlaySourceLibrary
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO play_events (session_id, song_slug, user_id, event_type, source_type, source_id,
+		                          source_name, queue_index, queue_length, played_seconds, occurred_at, synced)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.SessionID, event.SongSlug, event.UserID, string(event.Type),
+		event.Source.Type, event.Source.ID, event.Source.Name,
+		event.QueueIndex, event.QueueLength, event.PlayedSeconds, event.OccurredAt, event.Synced,
+	)
+	if err != nil {
+		return fmt.Errorf("insert play event: %w", err)
+	}
+
+	if id, err := res.LastInsertId(); err == nil {
+		event.ID = id
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE listening_sessions SET ended_at = ? WHERE id = ? AND ended_at < ?",
+		event.OccurredAt, event.SessionID, event.OccurredAt,
+	); err != nil {
+		return fmt.Errorf("update listening session: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// ImportPlayEvents adds events that happened elsewhere, such as in another
+// player, grouping them into sessions of their own by idle gap. Events
+// already in the log for the same song, type and time are skipped, so
+// importing twice adds nothing. It returns the events that were added, even
+// when a later one fails.
+func (d *Database) ImportPlayEvents(ctx context.Context, source types.PlaySource, events []*types.PlayEvent) ([]*types.PlayEvent, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	sorted := make([]*types.PlayEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].OccurredAt.Before(sorted[j].OccurredAt)
+	})
+
+	var added []*types.PlayEvent
+	var session *types.ListeningSession
+	var last time.Time
+	for _, event := range sorted {
+		var exists int
+		err := d.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM play_events WHERE song_slug = ? AND event_type = ? AND occurred_at = ?",
+			event.SongSlug, string(event.Type), event.OccurredAt,
+		).Scan(&exists)
+		if err != nil {
+			return added, fmt.Errorf("check play event: %w", err)
+		}
+		if exists > 0 {
+			continue
+		}
+
+		if session == nil || event.OccurredAt.Sub(last) > SessionIdleGap {
+			session, err = d.CreateListeningSession(ctx, source, event.OccurredAt)
+			if err != nil {
+				return added, err
+			}
+		}
+		last = event.OccurredAt
+
+		event.SessionID = session.ID
+		event.Source = source
+		if err := d.AddPlayEvent(ctx, event); err != nil {
+			return added, err
+		}
+		added = append(added, event)
+	}
+	return added, nil
+}
+
+// GetListeningSessions returns the most recent sessions with their totals.
+func (d *Database) GetListeningSessions(ctx context.Context, limit, offset int) ([]*types.ListeningSession, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetListeningSessions", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ls.id, ls.started_at, ls.ended_at, ls.source_type, ls.source_id, ls.source_name,
+		       COALESCE(SUM(CASE WHEN pe.event_type = 'start' THEN 1 ELSE 0 END), 0) AS track_count,
+		       COALESCE(SUM(CASE WHEN pe.event_type != 'start' THEN pe.played_seconds ELSE 0 END), 0) AS listened_seconds
+		FROM listening_sessions ls
+		LEFT JOIN play_events pe ON pe.session_id = ls.id
+		GROUP BY ls.id
+		ORDER BY ls.started_at DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		d.debugLog("GetListeningSessions", err, time.Since(start))
+		return nil, fmt.Errorf("query listening sessions: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var sessions []*types.ListeningSession
+	for rows.Next() {
+		session := &types.ListeningSession{}
+		var sourceID, sourceName sql.NullString
+		if err := rows.Scan(&session.ID, &session.StartedAt, &session.EndedAt, &session.Source.Type,
+			&sourceID, &sourceName, &session.TrackCount, &session.ListenedSeconds); err != nil {
+			return nil, fmt.Errorf("scan listening session: %w", err)
+		}
+		session.Source.ID = sourceID.String
+		session.Source.Name = sourceName.String
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// GetSessionEvents returns the events of a session in the order they happened.
+func (d *Database) GetSessionEvents(ctx context.Context, sessionID string) ([]*types.PlayEvent, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	return d.queryPlayEvents(ctx, `
+		SELECT id, session_id, song_slug, user_id, event_type, source_type, source_id, source_name,
+		       queue_index, queue_length, played_seconds, occurred_at, synced
+		FROM play_events
+		WHERE session_id = ?
+		ORDER BY occurred_at ASC, id ASC
+	`, sessionID)
+}
+
+// GetRecentPlayStarts returns the start events of the listening history,
+// newest first.
+func (d *Database) GetRecentPlayStarts(ctx context.Context, limit, offset int) ([]*types.PlayEvent, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	return d.queryPlayEvents(ctx, `
+		SELECT id, session_id, song_slug, user_id, event_type, source_type, source_id, source_name,
+		       queue_index, queue_length, played_seconds, occurred_at, synced
+		FROM play_events
+		WHERE event_type = 'start'
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+}
+
+// GetPlayStartsBetween returns the start events from since up to until, in
+// the order they happened.
+func (d *Database) GetPlayStartsBetween(ctx context.Context, since, until time.Time) ([]*types.PlayEvent, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	return d.queryPlayEvents(ctx, `
+		SELECT id, session_id, song_slug, user_id, event_type, source_type, source_id, source_name,
+		       queue_index, queue_length, played_seconds, occurred_at, synced
+		FROM play_events
+		WHERE event_type = 'start' AND occurred_at >= ? AND occurred_at < ?
+		ORDER BY occurred_at ASC, id ASC
+	`, since, until)
+}
+
+// GetRecentlyPlayed returns the albums and main artists of the most recently
+// started songs, newest first.
+func (d *Database) GetRecentlyPlayed(ctx context.Context, limit int) ([]*types.RecentShortcut, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT kind, slug, name FROM (
+			SELECT 'album' AS kind, a.slug, a.name, MAX(e.id) AS last_event
+			FROM play_events e
+			JOIN songs s ON s.slug = e.song_slug
+			JOIN albums a ON a.slug = s.album_slug
+			WHERE e.event_type = ?
+			GROUP BY a.slug
+			UNION ALL
+			SELECT 'author' AS kind, au.slug, au.name, MAX(e.id) AS last_event
+			FROM play_events e
+			JOIN song_authors sa ON sa.song_slug = e.song_slug AND sa.role = ?
+			JOIN authors au ON au.slug = sa.author_slug
+			WHERE e.event_type = ?
+			GROUP BY au.slug
+		)
+		ORDER BY last_event DESC, kind ASC
+		LIMIT ?
+	`, string(types.PlayEventStart), string(types.AuthorRoleMain), string(types.PlayEventStart), limit)
+	if err != nil {
+		return nil, fmt.Errorf("query recently played: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var shortcuts []*types.RecentShortcut
+	for rows.Next() {
+		var shortcut types.RecentShortcut
+		if err := rows.Scan(&shortcut.Kind, &shortcut.Slug, &shortcut.Name); err != nil {
+			return nil, fmt.Errorf("scan recently played: %w", err)
+		}
+		shortcuts = append(shortcuts, &shortcut)
+	}
+
+	return shortcuts, rows.Err()
+}
+
+// GetUnsyncedListens returns start events that have not been reported to the API yet.
+func (d *Database) GetUnsyncedListens(ctx context.Context, limit int) ([]*types.PlayEvent, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	return d.queryPlayEvents(ctx, `
+		SELECT id, session_id, song_slug, user_id, event_type, source_type, source_id, source_name,
+		       queue_index, queue_length, played_seconds, occurred_at, synced
+		FROM play_events
+		WHERE event_type = 'start' AND synced = false
+		ORDER BY occurred_at ASC
+		LIMIT ?
+	`, limit)
+}
+
+func (d *Database) MarkPlayEventSynced(ctx context.Context, id int64) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "UPDATE play_events SET synced = true WHERE id = ?", id); err != nil {
+		return fmt.Errorf("mark play event synced: %w", err)
+	}
+	return nil
+}
+
+// ReassignPlayEvents moves the play events recorded for one user ID to
+// another, so history kept under a replaced anonymous token is reported
+// under the new one. It returns how many events were moved.
+func (d *Database) ReassignPlayEvents(ctx context.Context, from, to string) (int64, error) {
+	if err := d.checkClosed(); err != nil {
+		return 0, err
+	}
+
+	res, err := d.db.ExecContext(ctx, "UPDATE play_events SET user_id = ? WHERE user_id = ?", to, from)
+	if err != nil {
+		return 0, fmt.Errorf("reassign play events: %w", err)
+	}
+	moved, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("count reassigned play events: %w", err)
+	}
+	return moved, nil
+}
+
+func (d *Database) queryPlayEvents(ctx context.Context, query string, args ...interface{}) ([]*types.PlayEvent, error) {
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query play events: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var events []*types.PlayEvent
+	for rows.Next() {
+		event := &types.PlayEvent{}
+		var eventType string
+		var sourceID, sourceName sql.NullString
+		if err := rows.Scan(&event.ID, &event.SessionID, &event.SongSlug, &event.UserID, &eventType,
+			&event.Source.Type, &sourceID, &sourceName, &event.QueueIndex, &event.QueueLength,
+			&event.PlayedSeconds, &event.OccurredAt, &event.Synced); err != nil {
+			return nil, fmt.Errorf("scan play event: %w", err)
+		}
+		event.Type = types.PlayEventType(eventType)
+		event.Source.ID = sourceID.String
+		event.Source.Name = sourceName.String
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return events, nil
+}
+
+// migratePlayHistory converts rows from the legacy play_history table into
+// start events, grouping them into sessions by idle gap, and drops the table.
+func (d *Database) migratePlayHistory() error {
+	var name string
+	err := d.db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'play_history'").Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check legacy table: %w", err)
+	}
+
+	type legacyRow struct {
+		songSlug string
+		userID   *string
+		playedAt time.Time
+		synced   bool
+	}
+
+	rows, err := d.db.Query("SELECT song_slug, user_id, played_at, synced FROM play_history ORDER BY played_at ASC")
+	if err != nil {
+		return fmt.Errorf("query legacy history: %w", err)
+	}
+
+	var legacy []legacyRow
+	for rows.Next() {
+		var row legacyRow
+		if err := rows.Scan(&row.songSlug, &row.userID, &row.playedAt, &row.synced); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("scan legacy history: %w", err)
+		}
+		legacy = append(legacy, row)
+	}
+	if err := rows.Close(); err != nil {
+		log.Printf("Failed to close rows: %v", err)
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	sessionID := ""
+	var lastPlayed time.Time
+	for _, row := range legacy {
+		if sessionID == "" || row.playedAt.Sub(lastPlayed) > SessionIdleGap {
+			sessionID = newSessionID()
+			if _, err := tx.Exec(
+				`INSERT INTO listening_sessions (id, started_at, ended_at, source_type) VALUES (?, ?, ?, ?)`,
+				sessionID, row.playedAt, row.playedAt, types.PlaySourceLibrary,
+			); err != nil {
+				return fmt.Errorf("insert migrated session: %w", err)
+			}
+		} else if _, err := tx.Exec("UPDATE listening_sessions SET ended_at = ? WHERE id = ?", row.playedAt, sessionID); err != nil {
+			return fmt.Errorf("update migrated session: %w", err)
+		}
+		lastPlayed = row.playedAt
+
+		if _, err := tx.Exec(
+			`INSERT INTO play_events (session_id, song_slug, user_id, event_type, source_type, occurred_at, synced)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			sessionID, row.songSlug, row.userID, string(types.PlayEventStart), types.PlaySourceLibrary, row.playedAt, row.synced,
+		); err != nil {
+			return fmt.Errorf("insert migrated event: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec("DROP TABLE play_history"); err != nil {
+		return fmt.Errorf("drop legacy table: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit history migration: %w", err)
+	}
+
+	if d.debug {
+		log.Printf("[DB] Migrated %d legacy play history rows into play events", len(legacy))
+	}
+	return nil
+}
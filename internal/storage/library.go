@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// GetLibraryFiles returns every file the library scanner knows, by path.
+func (d *Database) GetLibraryFiles(ctx context.Context) (map[string]*types.LibraryFile, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetLibraryFiles", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT path, size, mod_time, song_slug FROM library_files")
+	if err != nil {
+		return nil, fmt.Errorf("query library files: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	files := make(map[string]*types.LibraryFile)
+	for rows.Next() {
+		file := &types.LibraryFile{}
+		if err := rows.Scan(&file.Path, &file.Size, &file.ModTime, &file.SongSlug); err != nil {
+			return nil, fmt.Errorf("scan library file: %w", err)
+		}
+		files[file.Path] = file
+	}
+	return files, rows.Err()
+}
+
+// SaveLibraryFile stores a scanned file together with the song read from it.
+func (d *Database) SaveLibraryFile(ctx context.Context, file *types.LibraryFile, song *types.Song) error {
+	start := time.Now()
+	defer func() { d.debugLog("SaveLibraryFile", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	if err := d.saveSongInTx(ctx, tx, song); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT OR REPLACE INTO library_files (path, size, mod_time, song_slug) VALUES (?, ?, ?, ?)",
+		file.Path, file.Size, file.ModTime, song.Slug,
+	); err != nil {
+		return fmt.Errorf("save library file: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// RemoveLibraryFile forgets a file that is gone from the library folders,
+// along with its song and any local album or author left without songs.
+func (d *Database) RemoveLibraryFile(ctx context.Context, file *types.LibraryFile) error {
+	start := time.Now()
+	defer func() { d.debugLog("RemoveLibraryFile", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM library_files WHERE path = ?", file.Path); err != nil {
+		return fmt.Errorf("delete library file: %w", err)
+	}
+	if strings.HasPrefix(file.SongSlug, types.LocalSlugPrefix) {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM songs WHERE slug = ?", file.SongSlug); err != nil {
+			return fmt.Errorf("delete song: %w", err)
+		}
+	}
+
+	pattern := types.LocalSlugPrefix + "%"
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM albums WHERE slug LIKE ?
+		AND NOT EXISTS (SELECT 1 FROM songs WHERE songs.album_slug = albums.slug)`, pattern,
+	); err != nil {
+		return fmt.Errorf("delete empty albums: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM authors WHERE slug LIKE ?
+		AND NOT EXISTS (SELECT 1 FROM song_authors WHERE song_authors.author_slug = authors.slug)
+		AND NOT EXISTS (SELECT 1 FROM album_artists WHERE album_artists.author_slug = authors.slug)`, pattern,
+	); err != nil {
+		return fmt.Errorf("delete empty authors: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetLocalSongs returns the songs scanned from library folders, by artist,
+// album and title.
+func (d *Database) GetLocalSongs(ctx context.Context) ([]*types.Song, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetLocalSongs", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT s.slug, s.name, s.file, s.image, s.image_cropped, s.length,
+		       s.played, s.link, s.liked, s.volume, s.album_slug, s.local_path,
+		       s.downloaded, s.last_sync, s.created_at, s.updated_at,
+		       COALESCE(a.slug, '') as album_slug_ref,
+		       COALESCE(a.name, '') as album_name,
+		       COALESCE(a.image, '') as album_image,
+		       COALESCE(a.image_cropped, '') as album_image_cropped,
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist
+		FROM library_files f
+		JOIN songs s ON s.slug = f.song_slug
+		LEFT JOIN albums a ON s.album_slug = a.slug
+		WHERE s.deleted_at IS NULL
+		ORDER BY COALESCE(a.album_artist, ''), COALESCE(a.name, ''), f.path
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query local songs: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var songs []*types.Song
+	for rows.Next() {
+		song, err := d.scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan song: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadSongAuthors(ctx, songs); err != nil {
+		return nil, fmt.Errorf("load song authors: %w", err)
+	}
+	return songs, nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// SetAlbumTrackCount records how many tracks the server lists for the album.
+func (d *Database) SetAlbumTrackCount(ctx context.Context, slug string, count int) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "UPDATE albums SET track_count = ? WHERE slug = ?", count, slug); err != nil {
+		return fmt.Errorf("set album %s track count: %w", slug, err)
+	}
+	return nil
+}
+
+// GetAlbumGaps returns the albums with some but not all of their tracks
+// playable without the server, fewest missing first. Albums whose track
+// count was never fetched come last, with a Tracks of 0.
+func (d *Database) GetAlbumGaps(ctx context.Context) ([]*types.AlbumGap, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetAlbumGaps", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT a.slug, a.name, a.image, a.image_cropped, a.link, a.album_artist,
+		       a.last_sync, a.created_at, a.updated_at, a.track_count, COUNT(*) AS owned
+		FROM albums a
+		JOIN songs s ON s.album_slug = a.slug
+		WHERE s.deleted_at IS NULL AND `+availableOffline+`
+		GROUP BY a.slug
+		HAVING a.track_count = 0 OR owned < a.track_count
+		ORDER BY a.track_count = 0, a.track_count - owned, a.name COLLATE NOCASE
+	`)
+	if err != nil {
+		d.debugLog("GetAlbumGaps", err, time.Since(start))
+		return nil, fmt.Errorf("query album gaps: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var gaps []*types.AlbumGap
+	var albums []*types.Album
+	for rows.Next() {
+		album := &types.Album{}
+		gap := &types.AlbumGap{Album: album}
+		if err := rows.Scan(
+			&album.Slug, &album.Name, &album.Image, &album.ImageCropped,
+			&album.Link, &album.AlbumArtist, &album.LastSync, &album.CreatedAt, &album.UpdatedAt,
+			&gap.Tracks, &gap.Owned,
+		); err != nil {
+			return nil, fmt.Errorf("scan album gap: %w", err)
+		}
+		gaps = append(gaps, gap)
+		albums = append(albums, album)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadAlbumArtists(ctx, albums); err != nil {
+		return nil, fmt.Errorf("load album artists: %w", err)
+	}
+	return gaps, nil
+}
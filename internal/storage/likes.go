@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// QueuedLike is a like or unlike made locally that the server has not seen.
+type QueuedLike struct {
+	Slug  string
+	Liked bool
+}
+
+// SaveLike stores a song with its changed like and queues the like to be
+// sent to the server. Until it is sent, syncs keep the local like over the
+// server's.
+func (d *Database) SaveLike(ctx context.Context, song *types.Song) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	liked := song.Liked != nil && *song.Liked
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO pending_likes (slug, liked, queued_at) VALUES (?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET liked = excluded.liked, queued_at = excluded.queued_at
+	`, song.Slug, liked, time.Now())
+	if err != nil {
+		return fmt.Errorf("queue like %s: %w", song.Slug, err)
+	}
+
+	if err := d.saveSongInTx(ctx, tx, song); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetQueuedLikes returns the likes not yet sent, oldest first.
+func (d *Database) GetQueuedLikes(ctx context.Context) ([]QueuedLike, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT slug, liked FROM pending_likes ORDER BY queued_at")
+	if err != nil {
+		return nil, fmt.Errorf("query queued likes: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var likes []QueuedLike
+	for rows.Next() {
+		var like QueuedLike
+		if err := rows.Scan(&like.Slug, &like.Liked); err != nil {
+			return nil, fmt.Errorf("scan queued like: %w", err)
+		}
+		likes = append(likes, like)
+	}
+	return likes, rows.Err()
+}
+
+// DropQueuedLike forgets a queued like once it was sent. A like changed
+// again while it was being sent stays queued.
+func (d *Database) DropQueuedLike(ctx context.Context, like QueuedLike) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx, "DELETE FROM pending_likes WHERE slug = ? AND liked = ?", like.Slug, like.Liked)
+	if err != nil {
+		return fmt.Errorf("drop queued like %s: %w", like.Slug, err)
+	}
+	return nil
+}
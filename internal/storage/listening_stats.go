@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// ListeningRank is what the top lists of the listening statistics are
+// ranked by.
+type ListeningRank string
+
+const (
+	RankByPlays ListeningRank = "plays"
+	RankByTime  ListeningRank = "time"
+)
+
+func (r ListeningRank) orderBy() string {
+	if r == RankByTime {
+		return `seconds DESC, plays DESC, name COLLATE NOCASE ASC`
+	}
+	return `plays DESC, seconds DESC, name COLLATE NOCASE ASC`
+}
+
+// songTotals totals the plays and listened seconds of each song since a
+// time. A play is a start event; the seconds come with the finish or skip
+// that ends it.
+const songTotals = `
+	WITH totals AS (
+		SELECT song_slug,
+		       SUM(CASE WHEN event_type = 'start' THEN 1 ELSE 0 END) AS plays,
+		       SUM(CASE WHEN event_type != 'start' THEN played_seconds ELSE 0 END) AS seconds
+		FROM play_events
+		WHERE occurred_at >= ?
+		GROUP BY song_slug
+	)`
+
+// GetTopSongs returns the songs listened to most since since, songs no
+// longer in the library under their slug.
+func (d *Database) GetTopSongs(ctx context.Context, since time.Time, rank ListeningRank, limit int) ([]*types.ListeningTotal, error) {
+	return d.queryListeningTotals(ctx, "GetTopSongs", songTotals+`
+		SELECT t.song_slug, COALESCE(s.name, t.song_slug) AS name, t.plays, t.seconds
+		FROM totals t
+		LEFT JOIN songs s ON s.slug = t.song_slug
+		WHERE t.plays > 0 OR t.seconds > 0
+		ORDER BY `+rank.orderBy()+`
+		LIMIT ?
+	`, since, limit)
+}
+
+// GetTopAlbums returns the albums listened to most since since.
+func (d *Database) GetTopAlbums(ctx context.Context, since time.Time, rank ListeningRank, limit int) ([]*types.ListeningTotal, error) {
+	return d.queryListeningTotals(ctx, "GetTopAlbums", songTotals+`
+		SELECT a.slug, a.name AS name, SUM(t.plays) AS plays, SUM(t.seconds) AS seconds
+		FROM totals t
+		JOIN songs s ON s.slug = t.song_slug
+		JOIN albums a ON a.slug = s.album_slug
+		GROUP BY a.slug
+		HAVING plays > 0 OR seconds > 0
+		ORDER BY `+rank.orderBy()+`
+		LIMIT ?
+	`, since, limit)
+}
+
+// GetTopAuthors returns the main artists listened to most since since.
+func (d *Database) GetTopAuthors(ctx context.Context, since time.Time, rank ListeningRank, limit int) ([]*types.ListeningTotal, error) {
+	return d.queryListeningTotals(ctx, "GetTopAuthors", songTotals+`
+		SELECT au.slug, au.name AS name, SUM(t.plays) AS plays, SUM(t.seconds) AS seconds
+		FROM totals t
+		JOIN song_authors sa ON sa.song_slug = t.song_slug AND sa.role = ?
+		JOIN authors au ON au.slug = sa.author_slug
+		GROUP BY au.slug
+		HAVING plays > 0 OR seconds > 0
+		ORDER BY `+rank.orderBy()+`
+		LIMIT ?
+	`, since, string(types.AuthorRoleMain), limit)
+}
+
+func (d *Database) queryListeningTotals(ctx context.Context, name, query string, args ...interface{}) ([]*types.ListeningTotal, error) {
+	start := time.Now()
+	defer func() { d.debugLog(name, nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		d.debugLog(name, err, time.Since(start))
+		return nil, fmt.Errorf("query listening totals: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var totals []*types.ListeningTotal
+	for rows.Next() {
+		total := &types.ListeningTotal{}
+		if err := rows.Scan(&total.Slug, &total.Name, &total.Plays, &total.Seconds); err != nil {
+			return nil, fmt.Errorf("scan listening total: %w", err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return totals, nil
+}
+
+// GetListeningDays returns the plays and listened seconds of each day with
+// any since since, oldest first. Days are dated as the events were, in the
+// time zone they were recorded in.
+func (d *Database) GetListeningDays(ctx context.Context, since time.Time) ([]*types.ListeningDay, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetListeningDays", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	// Times are stored as text starting with the date, so its first ten
+	// characters name the day.
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT substr(occurred_at, 1, 10) AS day,
+		       SUM(CASE WHEN event_type = 'start' THEN 1 ELSE 0 END) AS plays,
+		       SUM(CASE WHEN event_type != 'start' THEN played_seconds ELSE 0 END) AS seconds
+		FROM play_events
+		WHERE occurred_at >= ?
+		GROUP BY day
+		ORDER BY day ASC
+	`, since)
+	if err != nil {
+		d.debugLog("GetListeningDays", err, time.Since(start))
+		return nil, fmt.Errorf("query listening days: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var days []*types.ListeningDay
+	for rows.Next() {
+		var date string
+		day := &types.ListeningDay{}
+		if err := rows.Scan(&date, &day.Plays, &day.Seconds); err != nil {
+			return nil, fmt.Errorf("scan listening day: %w", err)
+		}
+		day.Day, err = time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			log.Printf("[STORAGE] Skipping listening day %q: %v", date, err)
+			continue
+		}
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return days, nil
+}
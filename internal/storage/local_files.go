@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// LocalFile is a song with a file on this machine: a download, or a file
+// scanned from a library folder.
+type LocalFile struct {
+	Song *types.Song
+	Path string
+	// Checksum is the sha256 recorded when the file was downloaded, empty
+	// for library files and older downloads.
+	Checksum string
+	// Size is the file size, or -1 when the file is gone.
+	Size int64
+}
+
+// Missing reports whether the song's file is no longer on disk.
+func (l *LocalFile) Missing() bool {
+	return l.Size < 0
+}
+
+// GetLocalFiles returns every song with a local file, by name, with the
+// file sizes.
+func (d *Database) GetLocalFiles(ctx context.Context) ([]*LocalFile, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetLocalFiles", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT s.slug, s.name, s.file, s.image, s.image_cropped, s.length,
+		       s.played, s.link, s.liked, s.volume, s.album_slug, s.local_path,
+		       s.downloaded, s.last_sync, s.created_at, s.updated_at,
+		       COALESCE(a.slug, '') as album_slug_ref,
+		       COALESCE(a.name, '') as album_name,
+		       COALESCE(a.image, '') as album_image,
+		       COALESCE(a.image_cropped, '') as album_image_cropped,
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist,
+		       COALESCE(s.checksum, '')
+		FROM songs s
+		LEFT JOIN albums a ON s.album_slug = a.slug
+		WHERE s.deleted_at IS NULL AND COALESCE(s.local_path, '') != ''
+		ORDER BY s.name COLLATE NOCASE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query local files: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var local []*LocalFile
+	var songs []*types.Song
+	for rows.Next() {
+		entry := &LocalFile{}
+		song, err := d.scanSong(checksumScanner{rows, &entry.Checksum})
+		if err != nil {
+			return nil, fmt.Errorf("scan local file: %w", err)
+		}
+		entry.Song = song
+		entry.Path = *song.LocalPath
+		local = append(local, entry)
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadSongAuthors(ctx, songs); err != nil {
+		return nil, fmt.Errorf("load song authors: %w", err)
+	}
+
+	for _, entry := range local {
+		entry.Size = -1
+		if info, err := os.Stat(entry.Path); err == nil {
+			entry.Size = info.Size()
+		}
+	}
+	return local, nil
+}
+
+// checksumScanner scans a song row followed by its checksum column.
+type checksumScanner struct {
+	rows     *sql.Rows
+	checksum *string
+}
+
+func (s checksumScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.checksum)...)
+}
+
+// MarkSongDownloaded records that a song was saved to path, with the sha256
+// of the file.
+func (d *Database) MarkSongDownloaded(ctx context.Context, slug, path, checksum string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE songs SET downloaded = 1, local_path = ?, checksum = ?, downloaded_at = ? WHERE slug = ?",
+		path, checksum, time.Now(), slug,
+	)
+	if err != nil {
+		return fmt.Errorf("mark song %s downloaded: %w", slug, err)
+	}
+	return nil
+}
+
+// SetSongChecksum records the sha256 of a song's local file.
+func (d *Database) SetSongChecksum(ctx context.Context, slug, checksum string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "UPDATE songs SET checksum = ? WHERE slug = ?", checksum, slug); err != nil {
+		return fmt.Errorf("set song %s checksum: %w", slug, err)
+	}
+	return nil
+}
+
+// ClearSongDownload forgets a song's downloaded file, and its cache entry.
+// The file itself is left to the caller.
+func (d *Database) ClearSongDownload(ctx context.Context, slug string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	var path sql.NullString
+	err := d.db.QueryRowContext(ctx, "SELECT local_path FROM songs WHERE slug = ?", slug).Scan(&path)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get song %s local path: %w", slug, err)
+	}
+
+	_, err = d.db.ExecContext(ctx,
+		"UPDATE songs SET downloaded = 0, local_path = NULL, checksum = NULL WHERE slug = ?",
+		slug,
+	)
+	if err != nil {
+		return fmt.Errorf("clear song %s download: %w", slug, err)
+	}
+	if path.Valid && path.String != "" {
+		return d.forgetCacheFile(ctx, path.String)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// GetLyrics returns the cached lyrics of a song, or nil if it was never
+// looked up.
+func (d *Database) GetLyrics(ctx context.Context, slug string) (*types.Lyrics, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetLyrics", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	lyrics := &types.Lyrics{SongSlug: slug}
+	err := d.db.QueryRowContext(ctx,
+		"SELECT synced, plain, source, fetched_at FROM lyrics WHERE song_slug = ?", slug,
+	).Scan(&lyrics.Synced, &lyrics.Plain, &lyrics.Source, &lyrics.FetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get lyrics %s: %w", slug, err)
+	}
+	return lyrics, nil
+}
+
+// SaveLyrics caches the lyrics of a song, replacing any cached before.
+func (d *Database) SaveLyrics(ctx context.Context, lyrics *types.Lyrics) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if lyrics.FetchedAt.IsZero() {
+		lyrics.FetchedAt = time.Now()
+	}
+	_, err := d.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO lyrics (song_slug, synced, plain, source, fetched_at)
+		 VALUES (?, ?, ?, ?, ?)`,
+		lyrics.SongSlug, lyrics.Synced, lyrics.Plain, lyrics.Source, lyrics.FetchedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save lyrics %s: %w", lyrics.SongSlug, err)
+	}
+	return nil
+}
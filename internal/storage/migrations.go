@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"database/sql"
 	"fmt"
 )
 
@@ -8,6 +9,17 @@ func (d *Database) runMigrations() error {
 	migrations := []string{
 		createTables,
 		createIndexes,
+		createPlayEvents,
+		createPlaylistTransitions,
+		createArtworkOverrides,
+		createPlaybackQueue,
+		createLibraryFiles,
+		createPendingPlaylistDeletes,
+		createSyncState,
+		createLyrics,
+		createRemovedDownloads,
+		createTaskRuns,
+		createPendingLikes,
 	}
 
 	for i, migration := range migrations {
@@ -16,9 +28,130 @@ func (d *Database) runMigrations() error {
 		}
 	}
 
+	if err := d.migratePlayHistory(); err != nil {
+		return fmt.Errorf("migrate play history: %w", err)
+	}
+
+	if err := d.ensureColumn("albums", "album_artist", "TEXT DEFAULT ''"); err != nil {
+		return fmt.Errorf("add album artist: %w", err)
+	}
+
+	if err := d.ensureColumn("song_authors", "role", "TEXT NOT NULL DEFAULT 'main'"); err != nil {
+		return fmt.Errorf("add author role: %w", err)
+	}
+
+	// dirty counts the edits to a playlist not yet sent to the server.
+	if err := d.ensureColumn("playlists", "dirty", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("add playlist dirty: %w", err)
+	}
+
+	// server_updated_at is the server's change time of the copy last
+	// stored, which offline edits are checked against before they are sent.
+	if err := d.ensureColumn("playlists", "server_updated_at", "TIMESTAMP"); err != nil {
+		return fmt.Errorf("add playlist server updated at: %w", err)
+	}
+
+	// checksum is the sha256 of a downloaded song's file when it was saved.
+	if err := d.ensureColumn("songs", "checksum", "TEXT"); err != nil {
+		return fmt.Errorf("add song checksum: %w", err)
+	}
+
+	// download_items keeps the downloads that have not finished across
+	// restarts.
+	for column, definition := range map[string]string{
+		"title":     "TEXT DEFAULT ''",
+		"song_slug": "TEXT DEFAULT ''",
+		"total":     "INTEGER DEFAULT 0",
+	} {
+		if err := d.ensureColumn("download_items", column, definition); err != nil {
+			return fmt.Errorf("add download %s: %w", column, err)
+		}
+	}
+
+	// views and detailed_at pick the album and author stubs to fill in.
+	for _, table := range []string{"albums", "authors"} {
+		if err := d.ensureColumn(table, "views", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return fmt.Errorf("add %s views: %w", table, err)
+		}
+		if err := d.ensureColumn(table, "detailed_at", "TIMESTAMP"); err != nil {
+			return fmt.Errorf("add %s detailed_at: %w", table, err)
+		}
+	}
+
+	// track_count is how many tracks the server listed for an album when
+	// its details were last fetched, 0 while unknown.
+	if err := d.ensureColumn("albums", "track_count", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("add album track count: %w", err)
+	}
+
+	// liked_at and downloaded_at date the like and the download of a song,
+	// for the smart cache. Songs liked before the column dated from when
+	// they were stored.
+	if err := d.ensureColumn("songs", "liked_at", "TIMESTAMP"); err != nil {
+		return fmt.Errorf("add song liked_at: %w", err)
+	}
+	if _, err := d.db.Exec("UPDATE songs SET liked_at = created_at WHERE liked = 1 AND liked_at IS NULL"); err != nil {
+		return fmt.Errorf("date liked songs: %w", err)
+	}
+	if err := d.ensureColumn("songs", "downloaded_at", "TIMESTAMP"); err != nil {
+		return fmt.Errorf("add song downloaded_at: %w", err)
+	}
+
+	for _, table := range []string{"songs", "playlists"} {
+		if err := d.ensureColumn(table, "deleted_at", "TIMESTAMP"); err != nil {
+			return fmt.Errorf("add %s deleted_at: %w", table, err)
+		}
+	}
+
+	// added_at dates each playlist entry. Entries stored before the column
+	// stay undated.
+	if err := d.ensureColumn("playlist_songs", "added_at", "TIMESTAMP"); err != nil {
+		return fmt.Errorf("add playlist song added_at: %w", err)
+	}
+
+	if err := d.migratePendingPlaylistChanges(); err != nil {
+		return err
+	}
+
+	if err := d.createSearchIndex(); err != nil {
+		return fmt.Errorf("create search index: %w", err)
+	}
+
 	return nil
 }
 
+// ensureColumn adds a column to an existing table unless it is already there.
+func (d *Database) ensureColumn(table, column, definition string) error {
+	rows, err := d.db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("read table info: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name, kind string
+			notNull    int
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return fmt.Errorf("scan table info: %w", err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read table info: %w", err)
+	}
+	_ = rows.Close()
+
+	_, err = d.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
 const createTables = `
 CREATE TABLE IF NOT EXISTS songs (
 	slug TEXT PRIMARY KEY,
@@ -46,6 +179,7 @@ CREATE TABLE IF NOT EXISTS albums (
 	image TEXT,
 	image_cropped TEXT,
 	link TEXT DEFAULT '',
+	album_artist TEXT DEFAULT '',
 	last_sync TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
@@ -65,6 +199,7 @@ CREATE TABLE IF NOT EXISTS authors (
 CREATE TABLE IF NOT EXISTS song_authors (
 	song_slug TEXT NOT NULL,
 	author_slug TEXT NOT NULL,
+	role TEXT NOT NULL DEFAULT 'main',
 	PRIMARY KEY (song_slug, author_slug),
 	FOREIGN KEY (song_slug) REFERENCES songs(slug) ON DELETE CASCADE,
 	FOREIGN KEY (author_slug) REFERENCES authors(slug) ON DELETE CASCADE
@@ -98,23 +233,6 @@ CREATE TABLE IF NOT EXISTS playlist_songs (
 	FOREIGN KEY (song_slug) REFERENCES songs(slug) ON DELETE CASCADE
 );
 
-
-CREATE TABLE IF NOT EXISTS play_history (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    song_slug TEXT NOT NULL,
-    user_id TEXT,
-    played_at DATETIME NOT NULL,
-    synced BOOLEAN NOT NULL DEFAULT FALSE,
-    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (song_slug) REFERENCES songs(slug)
-);
-
-CREATE INDEX IF NOT EXISTS idx_play_history_song_slug ON play_history(song_slug);
-CREATE INDEX IF NOT EXISTS idx_play_history_user_id ON play_history(user_id);
-CREATE INDEX IF NOT EXISTS idx_play_history_played_at ON play_history(played_at);
-CREATE INDEX IF NOT EXISTS idx_play_history_synced ON play_history(synced);
-CREATE INDEX IF NOT EXISTS idx_play_history_sync_query ON play_history(synced, played_at);
-
 CREATE TABLE IF NOT EXISTS download_items (
 	url TEXT PRIMARY KEY,
 	local_path TEXT NOT NULL,
@@ -157,3 +275,148 @@ CREATE INDEX IF NOT EXISTS idx_cache_entries_accessed_at ON cache_entries(access
 CREATE INDEX IF NOT EXISTS idx_authors_name ON authors(name);
 CREATE INDEX IF NOT EXISTS idx_albums_name ON albums(name);
 `
+
+const createPlayEvents = `
+CREATE TABLE IF NOT EXISTS listening_sessions (
+	id TEXT PRIMARY KEY,
+	started_at DATETIME NOT NULL,
+	ended_at DATETIME NOT NULL,
+	source_type TEXT NOT NULL DEFAULT 'library',
+	source_id TEXT DEFAULT '',
+	source_name TEXT DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS play_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id TEXT NOT NULL,
+	song_slug TEXT NOT NULL,
+	user_id TEXT,
+	event_type TEXT NOT NULL,
+	source_type TEXT NOT NULL DEFAULT 'library',
+	source_id TEXT DEFAULT '',
+	source_name TEXT DEFAULT '',
+	queue_index INTEGER DEFAULT 0,
+	queue_length INTEGER DEFAULT 0,
+	played_seconds INTEGER DEFAULT 0,
+	occurred_at DATETIME NOT NULL,
+	synced BOOLEAN NOT NULL DEFAULT FALSE,
+	FOREIGN KEY (session_id) REFERENCES listening_sessions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_listening_sessions_started_at ON listening_sessions(started_at);
+CREATE INDEX IF NOT EXISTS idx_play_events_session ON play_events(session_id);
+CREATE INDEX IF NOT EXISTS idx_play_events_song_slug ON play_events(song_slug);
+CREATE INDEX IF NOT EXISTS idx_play_events_occurred_at ON play_events(occurred_at);
+CREATE INDEX IF NOT EXISTS idx_play_events_sync_query ON play_events(event_type, synced, occurred_at);
+`
+
+// playlist_transitions has no foreign key on purpose: playlists are written
+// with INSERT OR REPLACE during sync, which would wipe cascaded rows.
+const createPlaylistTransitions = `
+CREATE TABLE IF NOT EXISTS playlist_transitions (
+	playlist_slug TEXT PRIMARY KEY,
+	mode TEXT NOT NULL DEFAULT 'normal',
+	seconds INTEGER DEFAULT 0,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// artwork_overrides has no foreign key either, so overrides outlive sync
+// rewriting the songs, albums and authors they belong to.
+const createArtworkOverrides = `
+CREATE TABLE IF NOT EXISTS artwork_overrides (
+	kind TEXT NOT NULL,
+	slug TEXT NOT NULL,
+	path TEXT NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (kind, slug)
+);
+`
+
+// playback_queue has no foreign key so that sync rewriting songs leaves the
+// queue alone; songs missing on restore are skipped.
+const createPlaybackQueue = `
+CREATE TABLE IF NOT EXISTS playback_queue (
+	position INTEGER PRIMARY KEY,
+	song_slug TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS playback_state (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	queue_index INTEGER NOT NULL DEFAULT -1,
+	position_ms INTEGER NOT NULL DEFAULT 0,
+	shuffle BOOLEAN NOT NULL DEFAULT FALSE,
+	repeat_mode INTEGER NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// library_files has no foreign key so that a scanned song the user deletes
+// stays deleted: its file is still known and unchanged, so rescans skip it.
+const createLibraryFiles = `
+CREATE TABLE IF NOT EXISTS library_files (
+	path TEXT PRIMARY KEY,
+	size INTEGER NOT NULL,
+	mod_time TIMESTAMP NOT NULL,
+	song_slug TEXT NOT NULL
+);
+`
+
+// pending_playlist_deletes holds server playlists purged from the trash
+// while offline; the rows of the playlists themselves are already gone.
+const createPendingPlaylistDeletes = `
+CREATE TABLE IF NOT EXISTS pending_playlist_deletes (
+	slug TEXT PRIMARY KEY,
+	queued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// sync_state holds the delta sync cursor of each synced entity: records
+// changed after it are all that the next sync has to fetch.
+const createSyncState = `
+CREATE TABLE IF NOT EXISTS sync_state (
+	entity TEXT PRIMARY KEY,
+	cursor TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// lyrics has no foreign key so cached lyrics survive sync rewriting songs.
+// Rows with neither synced nor plain text record a lookup that found none.
+const createLyrics = `
+CREATE TABLE IF NOT EXISTS lyrics (
+	song_slug TEXT PRIMARY KEY,
+	synced TEXT NOT NULL DEFAULT '',
+	plain TEXT NOT NULL DEFAULT '',
+	source TEXT NOT NULL DEFAULT '',
+	fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// removed_downloads remembers the songs whose downloaded file the user
+// deleted lately, so a mistaken removal can be downloaded again.
+const createRemovedDownloads = `
+CREATE TABLE IF NOT EXISTS removed_downloads (
+	song_slug TEXT PRIMARY KEY,
+	removed_at TIMESTAMP NOT NULL
+);
+`
+
+// task_runs records when each scheduled background task last ran, so a
+// restart does not run it again early.
+const createTaskRuns = `
+CREATE TABLE IF NOT EXISTS task_runs (
+	task TEXT PRIMARY KEY,
+	ran_at TIMESTAMP NOT NULL
+);
+`
+
+// pending_likes holds likes and unlikes made locally that the server has not
+// seen yet. While a song has one, syncs keep its local like.
+const createPendingLikes = `
+CREATE TABLE IF NOT EXISTS pending_likes (
+	slug TEXT PRIMARY KEY,
+	liked INTEGER NOT NULL,
+	queued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+`
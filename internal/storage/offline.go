@@ -0,0 +1,476 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// availableOffline matches songs that can be played without the server.
+const availableOffline = `(s.downloaded = 1 OR COALESCE(s.local_path, '') != '')`
+
+// GetOfflineSongs returns the downloaded and locally scanned songs, newest
+// first.
+func (d *Database) GetOfflineSongs(ctx context.Context, limit, offset int) ([]*types.Song, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetOfflineSongs", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT s.slug, s.name, s.file, s.image, s.image_cropped, s.length,
+		       s.played, s.link, s.liked, s.volume, s.album_slug, s.local_path,
+		       s.downloaded, s.last_sync, s.created_at, s.updated_at,
+		       COALESCE(a.slug, '') as album_slug_ref,
+		       COALESCE(a.name, '') as album_name,
+		       COALESCE(a.image, '') as album_image,
+		       COALESCE(a.image_cropped, '') as album_image_cropped,
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist
+		FROM songs s
+		LEFT JOIN albums a ON s.album_slug = a.slug
+		WHERE s.deleted_at IS NULL AND `+availableOffline+`
+		ORDER BY s.created_at DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query offline songs: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var songs []*types.Song
+	for rows.Next() {
+		song, err := d.scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan song: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadSongAuthors(ctx, songs); err != nil {
+		return nil, fmt.Errorf("load song authors: %w", err)
+	}
+	return songs, nil
+}
+
+// GetOfflineAlbums returns the albums with at least one song that plays
+// without the server.
+func (d *Database) GetOfflineAlbums(ctx context.Context, limit, offset int) ([]*types.Album, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetOfflineAlbums", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT slug, name, image, image_cropped, link, album_artist, last_sync, created_at, updated_at
+		FROM albums
+		WHERE slug IN (
+			SELECT s.album_slug FROM songs s
+			WHERE s.deleted_at IS NULL AND `+availableOffline+`
+		)
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query offline albums: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var albums []*types.Album
+	for rows.Next() {
+		album, err := d.scanAlbum(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan album: %w", err)
+		}
+		albums = append(albums, album)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadAlbumArtists(ctx, albums); err != nil {
+		return nil, fmt.Errorf("load album artists: %w", err)
+	}
+	return albums, nil
+}
+
+// GetOfflineAuthors returns the authors with at least one song that plays
+// without the server.
+func (d *Database) GetOfflineAuthors(ctx context.Context, limit, offset int) ([]*types.Author, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetOfflineAuthors", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT slug, name, image, image_cropped, link, last_sync, created_at, updated_at
+		FROM authors
+		WHERE slug IN (
+			SELECT sa.author_slug FROM song_authors sa
+			JOIN songs s ON s.slug = sa.song_slug
+			WHERE s.deleted_at IS NULL AND `+availableOffline+`
+		)
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query offline authors: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var authors []*types.Author
+	for rows.Next() {
+		author, err := d.scanAuthor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan author: %w", err)
+		}
+		authors = append(authors, author)
+	}
+	return authors, rows.Err()
+}
+
+// DirtyPlaylist is a stored playlist with edits the server has not seen yet.
+// Edits counts them, so a push only clears the ones it sent. ServerUpdatedAt
+// is the server's change time of the copy the edits were made to, zero when
+// it is not known.
+type DirtyPlaylist struct {
+	Playlist        *types.Playlist
+	Edits           int
+	ServerUpdatedAt time.Time
+}
+
+// MarkPlaylistDirty records an edit to a stored playlist that could not be
+// sent to the server.
+func (d *Database) MarkPlaylistDirty(ctx context.Context, slug string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE playlists SET dirty = dirty + 1, updated_at = ? WHERE slug = ?",
+		time.Now(), slug,
+	)
+	if err != nil {
+		return fmt.Errorf("mark playlist %s dirty: %w", slug, err)
+	}
+	return nil
+}
+
+// IsPlaylistDirty reports whether a stored playlist has unsent edits.
+func (d *Database) IsPlaylistDirty(ctx context.Context, slug string) (bool, error) {
+	if err := d.checkClosed(); err != nil {
+		return false, err
+	}
+
+	var dirty int
+	err := d.db.QueryRowContext(ctx, "SELECT dirty FROM playlists WHERE slug = ?", slug).Scan(&dirty)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("query playlist %s dirty: %w", slug, err)
+	}
+	return dirty > 0, nil
+}
+
+// GetDirtyPlaylists returns the server playlists with unsent edits, with
+// their songs, oldest edit first. Trashed playlists are left out; their
+// delete is sent when the trash is purged.
+func (d *Database) GetDirtyPlaylists(ctx context.Context) ([]*DirtyPlaylist, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetDirtyPlaylists", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT slug, name, private, length, local_only, last_sync, created_at, updated_at, dirty,
+		       server_updated_at
+		FROM playlists
+		WHERE dirty > 0 AND local_only = 0 AND deleted_at IS NULL
+		ORDER BY updated_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query dirty playlists: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var dirty []*DirtyPlaylist
+	for rows.Next() {
+		var playlist types.Playlist
+		var serverUpdatedAt sql.NullTime
+		entry := &DirtyPlaylist{Playlist: &playlist}
+		err := rows.Scan(
+			&playlist.Slug, &playlist.Name, &playlist.Private, &playlist.Length,
+			&playlist.LocalOnly, &playlist.LastSync, &playlist.CreatedAt, &playlist.UpdatedAt,
+			&entry.Edits, &serverUpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan dirty playlist: %w", err)
+		}
+		entry.ServerUpdatedAt = serverUpdatedAt.Time
+		dirty = append(dirty, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	for _, entry := range dirty {
+		if err := d.loadPlaylistSongs(ctx, entry.Playlist); err != nil {
+			return nil, fmt.Errorf("load playlist songs: %w", err)
+		}
+	}
+	return dirty, nil
+}
+
+// GetLocalOnlyPlaylists returns the playlists that exist only locally, with
+// their songs, oldest first. Trashed playlists are left out.
+func (d *Database) GetLocalOnlyPlaylists(ctx context.Context) ([]*types.Playlist, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetLocalOnlyPlaylists", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT slug, name, private, length, local_only, last_sync, created_at, updated_at
+		FROM playlists
+		WHERE local_only = 1 AND deleted_at IS NULL
+		ORDER BY created_at
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query local-only playlists: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var playlists []*types.Playlist
+	for rows.Next() {
+		var playlist types.Playlist
+		err := rows.Scan(
+			&playlist.Slug, &playlist.Name, &playlist.Private, &playlist.Length,
+			&playlist.LocalOnly, &playlist.LastSync, &playlist.CreatedAt, &playlist.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan local-only playlist: %w", err)
+		}
+		playlists = append(playlists, &playlist)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	for _, playlist := range playlists {
+		if err := d.loadPlaylistSongs(ctx, playlist); err != nil {
+			return nil, fmt.Errorf("load playlist songs: %w", err)
+		}
+	}
+	return playlists, nil
+}
+
+// PublishPlaylist turns the local-only playlist slug into the server
+// playlist created from it, moving its songs and transition over to the slug
+// the server gave it.
+func (d *Database) PublishPlaylist(ctx context.Context, slug string, created *types.Playlist) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	// The songs point at the old slug until they are moved below.
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{"PRAGMA defer_foreign_keys = ON", nil},
+		{"DELETE FROM playlists WHERE slug = ? AND slug != ?", []any{created.Slug, slug}},
+		{`UPDATE playlists SET slug = ?, name = ?, private = ?, local_only = 0, dirty = 0, last_sync = ?
+			WHERE slug = ?`, []any{created.Slug, created.Name, created.Private, time.Now(), slug}},
+		{"UPDATE playlist_songs SET playlist_slug = ? WHERE playlist_slug = ?", []any{created.Slug, slug}},
+		{"UPDATE playlist_transitions SET playlist_slug = ? WHERE playlist_slug = ?", []any{created.Slug, slug}},
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement.query, statement.args...); err != nil {
+			return fmt.Errorf("publish playlist %s: %w", slug, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// migratePendingPlaylistChanges moves the queue of playlist changes made
+// offline by earlier versions into the dirty counts and the delete queue,
+// then drops it.
+func (d *Database) migratePendingPlaylistChanges() error {
+	var name string
+	err := d.db.QueryRow("SELECT name FROM sqlite_master WHERE type = 'table' AND name = 'pending_playlist_changes'").Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check legacy table: %w", err)
+	}
+
+	_, err = d.db.Exec(`
+		INSERT OR IGNORE INTO pending_playlist_deletes (slug, queued_at)
+		SELECT slug, queued_at FROM pending_playlist_changes WHERE is_delete;
+
+		UPDATE playlists SET dirty = dirty + 1
+		WHERE local_only = 0 AND slug IN (SELECT slug FROM pending_playlist_changes WHERE NOT is_delete);
+
+		DROP TABLE pending_playlist_changes;
+	`)
+	if err != nil {
+		return fmt.Errorf("migrate pending playlist changes: %w", err)
+	}
+	return nil
+}
+
+// ClearPlaylistDirty marks the given number of edits as sent. Edits made
+// while they were being sent keep the playlist dirty.
+func (d *Database) ClearPlaylistDirty(ctx context.Context, slug string, edits int) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE playlists SET dirty = MAX(dirty - ?, 0), last_sync = ? WHERE slug = ?",
+		edits, time.Now(), slug,
+	)
+	if err != nil {
+		return fmt.Errorf("clear playlist %s dirty: %w", slug, err)
+	}
+	return nil
+}
+
+// SaveServerPlaylist stores a playlist as the server sent it and remembers
+// the server's change time, so later offline edits can tell whether the
+// server copy changed since.
+func (d *Database) SaveServerPlaylist(ctx context.Context, playlist *types.Playlist) error {
+	serverUpdatedAt := playlist.UpdatedAt
+	if err := d.SavePlaylist(ctx, playlist); err != nil {
+		return err
+	}
+	return d.SetPlaylistServerUpdatedAt(ctx, playlist.Slug, serverUpdatedAt)
+}
+
+// SetPlaylistServerUpdatedAt records the server's change time of a stored
+// playlist. A zero time marks it unknown, as after an edit this client sent
+// itself; the next pull records the new time.
+func (d *Database) SetPlaylistServerUpdatedAt(ctx context.Context, slug string, at time.Time) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	var value any
+	if !at.IsZero() {
+		value = at
+	}
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE playlists SET server_updated_at = ? WHERE slug = ?",
+		value, slug,
+	)
+	if err != nil {
+		return fmt.Errorf("set playlist %s server updated at: %w", slug, err)
+	}
+	return nil
+}
+
+// QueuePlaylistDelete remembers a server playlist purged from the trash
+// while offline, to be deleted on the server later.
+func (d *Database) QueuePlaylistDelete(ctx context.Context, slug string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO pending_playlist_deletes (slug, queued_at) VALUES (?, ?)
+		ON CONFLICT(slug) DO UPDATE SET queued_at = excluded.queued_at
+	`, slug, time.Now())
+	if err != nil {
+		return fmt.Errorf("queue playlist delete %s: %w", slug, err)
+	}
+	return nil
+}
+
+// GetQueuedPlaylistDeletes returns the slugs of the queued deletes, oldest
+// first.
+func (d *Database) GetQueuedPlaylistDeletes(ctx context.Context) ([]string, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT slug FROM pending_playlist_deletes ORDER BY queued_at")
+	if err != nil {
+		return nil, fmt.Errorf("query queued playlist deletes: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("scan queued playlist delete: %w", err)
+		}
+		slugs = append(slugs, slug)
+	}
+	return slugs, rows.Err()
+}
+
+// DropQueuedPlaylistDelete forgets a queued delete once it was sent.
+func (d *Database) DropQueuedPlaylistDelete(ctx context.Context, slug string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "DELETE FROM pending_playlist_deletes WHERE slug = ?", slug); err != nil {
+		return fmt.Errorf("drop queued playlist delete %s: %w", slug, err)
+	}
+	return nil
+}
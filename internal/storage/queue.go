@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// GetPlaybackQueue returns the saved queue, or nil when none was saved.
+// Songs no longer in the library are left out.
+func (d *Database) GetPlaybackQueue(ctx context.Context) (*types.PlaybackQueue, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	queue := &types.PlaybackQueue{}
+	var positionMs int64
+	err := d.db.QueryRowContext(ctx,
+		"SELECT queue_index, position_ms, shuffle, repeat_mode FROM playback_state WHERE id = 1",
+	).Scan(&queue.Index, &positionMs, &queue.Shuffle, &queue.Repeat)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query playback state: %w", err)
+	}
+	queue.Position = time.Duration(positionMs) * time.Millisecond
+
+	rows, err := d.db.QueryContext(ctx, "SELECT song_slug FROM playback_queue ORDER BY position")
+	if err != nil {
+		return nil, fmt.Errorf("query playback queue: %w", err)
+	}
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("scan playback queue: %w", err)
+		}
+		slugs = append(slugs, slug)
+	}
+	if closeErr := rows.Close(); closeErr != nil {
+		log.Printf("Failed to close rows: %v", closeErr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	index := queue.Index
+	for i, slug := range slugs {
+		song, err := d.GetSong(ctx, slug)
+		if err != nil {
+			return nil, err
+		}
+		if song == nil {
+			if i < index {
+				queue.Index--
+			} else if i == index {
+				// The current song is gone; start over at the one after it.
+				queue.Position = 0
+			}
+			continue
+		}
+		queue.Songs = append(queue.Songs, song)
+	}
+
+	if len(queue.Songs) == 0 {
+		return nil, nil
+	}
+	if queue.Index >= len(queue.Songs) {
+		queue.Index, queue.Position = len(queue.Songs)-1, 0
+	}
+	return queue, nil
+}
+
+// SavePlaybackQueue replaces the saved queue and playback state.
+func (d *Database) SavePlaybackQueue(ctx context.Context, queue *types.PlaybackQueue) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && rollbackErr != sql.ErrTxDone {
+			log.Printf("Failed to rollback transaction: %v", rollbackErr)
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM playback_queue"); err != nil {
+		return fmt.Errorf("clear playback queue: %w", err)
+	}
+	for i, song := range queue.Songs {
+		if song == nil {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO playback_queue (position, song_slug) VALUES (?, ?)", i, song.Slug,
+		); err != nil {
+			return fmt.Errorf("insert playback queue entry: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR REPLACE INTO playback_state (id, queue_index, position_ms, shuffle, repeat_mode, updated_at)
+		 VALUES (1, ?, ?, ?, ?, ?)`,
+		queue.Index, queue.Position.Milliseconds(), queue.Shuffle, queue.Repeat, time.Now(),
+	); err != nil {
+		return fmt.Errorf("save playback state: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// SavePlaybackPosition updates where playback is in the saved queue, which
+// changes far more often than the queue itself.
+func (d *Database) SavePlaybackPosition(ctx context.Context, index int, position time.Duration) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx,
+		"UPDATE playback_state SET queue_index = ?, position_ms = ?, updated_at = ? WHERE id = 1",
+		index, position.Milliseconds(), time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("save playback position: %w", err)
+	}
+	return nil
+}
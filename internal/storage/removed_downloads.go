@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	// removedDownloadsKept is how many removed downloads are remembered.
+	removedDownloadsKept = 50
+	// removedDownloadsRetention is how long a removed download is
+	// remembered.
+	removedDownloadsRetention = 30 * 24 * time.Hour
+)
+
+// RemovedDownload is a song whose downloaded file the user deleted, so it
+// can be downloaded again.
+type RemovedDownload struct {
+	Song      *types.Song
+	RemovedAt time.Time
+}
+
+// RecordRemovedDownloads remembers that the downloads of slugs were just
+// deleted, and forgets the oldest beyond the most recent
+// removedDownloadsKept.
+func (d *Database) RecordRemovedDownloads(ctx context.Context, slugs []string) error {
+	if len(slugs) == 0 {
+		return nil
+	}
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := time.Now()
+	for _, slug := range slugs {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT OR REPLACE INTO removed_downloads (song_slug, removed_at) VALUES (?, ?)", slug, now,
+		); err != nil {
+			return fmt.Errorf("record removed download %s: %w", slug, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM removed_downloads
+		WHERE removed_at < ? OR song_slug NOT IN (
+			SELECT song_slug FROM removed_downloads ORDER BY removed_at DESC LIMIT ?
+		)
+	`, now.Add(-removedDownloadsRetention), removedDownloadsKept); err != nil {
+		return fmt.Errorf("prune removed downloads: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetRemovedDownloads returns the remembered removed downloads, latest
+// first. Songs downloaded again or deleted since are left out.
+func (d *Database) GetRemovedDownloads(ctx context.Context) ([]*RemovedDownload, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetRemovedDownloads", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT s.slug, s.name, s.file, s.image, s.image_cropped, s.length,
+		       s.played, s.link, s.liked, s.volume, s.album_slug, s.local_path,
+		       s.downloaded, s.last_sync, s.created_at, s.updated_at,
+		       COALESCE(a.slug, '') as album_slug_ref,
+		       COALESCE(a.name, '') as album_name,
+		       COALESCE(a.image, '') as album_image,
+		       COALESCE(a.image_cropped, '') as album_image_cropped,
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist,
+		       r.removed_at
+		FROM removed_downloads r
+		JOIN songs s ON s.slug = r.song_slug
+		LEFT JOIN albums a ON s.album_slug = a.slug
+		WHERE s.deleted_at IS NULL AND COALESCE(s.local_path, '') = ''
+		ORDER BY r.removed_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query removed downloads: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var removed []*RemovedDownload
+	var songs []*types.Song
+	for rows.Next() {
+		entry := &RemovedDownload{}
+		song, err := d.scanSong(removedAtScanner{rows, &entry.RemovedAt})
+		if err != nil {
+			return nil, fmt.Errorf("scan removed download: %w", err)
+		}
+		entry.Song = song
+		removed = append(removed, entry)
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadSongAuthors(ctx, songs); err != nil {
+		return nil, fmt.Errorf("load song authors: %w", err)
+	}
+	return removed, nil
+}
+
+// ForgetRemovedDownload drops a song from the removed downloads.
+func (d *Database) ForgetRemovedDownload(ctx context.Context, slug string) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "DELETE FROM removed_downloads WHERE song_slug = ?", slug); err != nil {
+		return fmt.Errorf("forget removed download %s: %w", slug, err)
+	}
+	return nil
+}
+
+// ClearRemovedDownloads forgets every removed download.
+func (d *Database) ClearRemovedDownloads(ctx context.Context) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "DELETE FROM removed_downloads"); err != nil {
+		return fmt.Errorf("clear removed downloads: %w", err)
+	}
+	return nil
+}
+
+// removedAtScanner scans a song row followed by when it was removed.
+type removedAtScanner struct {
+	rows      *sql.Rows
+	removedAt *time.Time
+}
+
+func (s removedAtScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(dest, s.removedAt)...)
+}
@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// The search index is an FTS5 table per kind, keyed by the rowid of the row
+// it indexes and kept current by triggers. Songs are found by title, artist
+// and album, albums by name and artist, authors by name. Rows are replaced
+// with a delete and an insert rather than updated, since a song's row
+// depends on its authors and album too. VACUUM may renumber the rowids of
+// tables with a TEXT primary key, so a database restored from a backup is
+// indexed again.
+const createSearchTables = `
+CREATE VIRTUAL TABLE IF NOT EXISTS songs_fts USING fts5(
+	name, artists, album,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS albums_fts USING fts5(
+	name, artists,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS authors_fts USING fts5(
+	name,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+`
+
+// Column weights for bm25: a match in the title counts most.
+const (
+	songRank   = "bm25(songs_fts, 10.0, 5.0, 2.0)"
+	albumRank  = "bm25(albums_fts, 10.0, 4.0)"
+	authorRank = "bm25(authors_fts)"
+)
+
+// indexSongs inserts the index rows of the songs s for which where holds.
+func indexSongs(where string) string {
+	return `
+		INSERT INTO songs_fts (rowid, name, artists, album)
+		SELECT s.rowid, s.name,
+			COALESCE((SELECT group_concat(au.name, ' ') FROM song_authors sa
+				JOIN authors au ON au.slug = sa.author_slug
+				WHERE sa.song_slug = s.slug), ''),
+			COALESCE(al.name, '')
+		FROM songs s LEFT JOIN albums al ON al.slug = s.album_slug
+		WHERE s.deleted_at IS NULL AND ` + where + `;`
+}
+
+// reindexSongs replaces the index rows of the songs s for which where holds.
+func reindexSongs(where string) string {
+	return `DELETE FROM songs_fts WHERE rowid IN (SELECT s.rowid FROM songs s WHERE ` + where + `);` +
+		indexSongs(where)
+}
+
+// indexAlbums inserts the index rows of the albums al for which where holds.
+func indexAlbums(where string) string {
+	return `
+		INSERT INTO albums_fts (rowid, name, artists)
+		SELECT al.rowid, al.name,
+			TRIM(COALESCE(al.album_artist, '') || ' ' || COALESCE((SELECT group_concat(au.name, ' ')
+				FROM album_artists aa JOIN authors au ON au.slug = aa.author_slug
+				WHERE aa.album_slug = al.slug), ''))
+		FROM albums al WHERE ` + where + `;`
+}
+
+func reindexAlbums(where string) string {
+	return `DELETE FROM albums_fts WHERE rowid IN (SELECT al.rowid FROM albums al WHERE ` + where + `);` +
+		indexAlbums(where)
+}
+
+func searchTriggers() string {
+	trigger := func(name, event, body string) string {
+		return fmt.Sprintf("CREATE TRIGGER IF NOT EXISTS %s AFTER %s BEGIN %s END;\n", name, event, body)
+	}
+
+	return trigger("songs_fts_insert", "INSERT ON songs",
+		indexSongs("s.rowid = NEW.rowid")) +
+		trigger("songs_fts_update", "UPDATE OF name, album_slug, deleted_at ON songs",
+			"DELETE FROM songs_fts WHERE rowid = OLD.rowid;"+indexSongs("s.rowid = NEW.rowid")) +
+		trigger("songs_fts_delete", "DELETE ON songs",
+			"DELETE FROM songs_fts WHERE rowid = OLD.rowid;") +
+		trigger("song_authors_fts_insert", "INSERT ON song_authors",
+			reindexSongs("s.slug = NEW.song_slug")) +
+		trigger("song_authors_fts_delete", "DELETE ON song_authors",
+			reindexSongs("s.slug = OLD.song_slug")) +
+
+		trigger("albums_fts_insert", "INSERT ON albums",
+			indexAlbums("al.rowid = NEW.rowid")) +
+		trigger("albums_fts_update", "UPDATE OF name, album_artist ON albums",
+			"DELETE FROM albums_fts WHERE rowid = OLD.rowid;"+indexAlbums("al.rowid = NEW.rowid")+
+				reindexSongs("s.album_slug = NEW.slug")) +
+		trigger("albums_fts_delete", "DELETE ON albums",
+			"DELETE FROM albums_fts WHERE rowid = OLD.rowid;") +
+		trigger("album_artists_fts_insert", "INSERT ON album_artists",
+			reindexAlbums("al.slug = NEW.album_slug")) +
+		trigger("album_artists_fts_delete", "DELETE ON album_artists",
+			reindexAlbums("al.slug = OLD.album_slug")) +
+
+		trigger("authors_fts_insert", "INSERT ON authors",
+			"INSERT INTO authors_fts (rowid, name) VALUES (NEW.rowid, NEW.name);") +
+		trigger("authors_fts_update", "UPDATE OF name ON authors",
+			"DELETE FROM authors_fts WHERE rowid = OLD.rowid;"+
+				"INSERT INTO authors_fts (rowid, name) VALUES (NEW.rowid, NEW.name);"+
+				reindexSongs("s.slug IN (SELECT song_slug FROM song_authors WHERE author_slug = NEW.slug)")+
+				reindexAlbums("al.slug IN (SELECT album_slug FROM album_artists WHERE author_slug = NEW.slug)")) +
+		trigger("authors_fts_delete", "DELETE ON authors",
+			"DELETE FROM authors_fts WHERE rowid = OLD.rowid;")
+}
+
+// createSearchIndex sets up the search index, filling it from the library
+// the first time.
+func (d *Database) createSearchIndex() error {
+	if _, err := d.db.Exec(createSearchTables); err != nil {
+		return fmt.Errorf("create search tables: %w", err)
+	}
+	if _, err := d.db.Exec(searchTriggers()); err != nil {
+		return fmt.Errorf("create search triggers: %w", err)
+	}
+
+	var indexed, songs int
+	if err := d.db.QueryRow("SELECT (SELECT COUNT(*) FROM songs_fts), (SELECT COUNT(*) FROM songs)").Scan(&indexed, &songs); err != nil {
+		return fmt.Errorf("count search rows: %w", err)
+	}
+	if indexed > 0 || songs == 0 {
+		return nil
+	}
+	return d.RebuildSearchIndex(context.Background())
+}
+
+// RebuildSearchIndex indexes the whole library again.
+func (d *Database) RebuildSearchIndex(ctx context.Context) error {
+	start := time.Now()
+	defer func() { d.debugLog("RebuildSearchIndex", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	statements := "DELETE FROM songs_fts; DELETE FROM albums_fts; DELETE FROM authors_fts;" +
+		indexSongs("1") + indexAlbums("1") +
+		"INSERT INTO authors_fts (rowid, name) SELECT rowid, name FROM authors;"
+	if _, err := d.db.ExecContext(ctx, statements); err != nil {
+		return fmt.Errorf("rebuild search index: %w", err)
+	}
+	return nil
+}
+
+// ftsQuery turns what the user typed into an FTS5 query. Words in double
+// quotes must appear together as a phrase; every other word matches words
+// starting with it. All of them must match. It is empty when nothing
+// searchable was typed.
+func ftsQuery(input string) string {
+	var terms []string
+	for i, part := range strings.Split(input, `"`) {
+		words := strings.FieldsFunc(part, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+		if i%2 == 1 {
+			terms = append(terms, `"`+strings.Join(words, " ")+`"`)
+			continue
+		}
+		for _, word := range words {
+			terms = append(terms, `"`+word+`"*`)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// SearchAlbums returns the albums whose name or artist match query, best
+// matches first.
+func (d *Database) SearchAlbums(ctx context.Context, query string, limit int) ([]*types.Album, error) {
+	start := time.Now()
+	defer func() { d.debugLog("SearchAlbums", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT al.slug, al.name, al.image, al.image_cropped, al.link, al.album_artist,
+		       al.last_sync, al.created_at, al.updated_at
+		FROM albums_fts JOIN albums al ON al.rowid = albums_fts.rowid
+		WHERE albums_fts MATCH ?
+		ORDER BY `+albumRank+`
+		LIMIT ?`, match, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search albums: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var albums []*types.Album
+	for rows.Next() {
+		album, err := d.scanAlbum(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan album: %w", err)
+		}
+		albums = append(albums, album)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadAlbumArtists(ctx, albums); err != nil {
+		return nil, fmt.Errorf("load album artists: %w", err)
+	}
+	return albums, nil
+}
+
+// SearchAuthors returns the authors whose name matches query, best matches
+// first.
+func (d *Database) SearchAuthors(ctx context.Context, query string, limit int) ([]*types.Author, error) {
+	start := time.Now()
+	defer func() { d.debugLog("SearchAuthors", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+	match := ftsQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT au.slug, au.name, au.image, au.image_cropped, au.link,
+		       au.last_sync, au.created_at, au.updated_at
+		FROM authors_fts JOIN authors au ON au.rowid = authors_fts.rowid
+		WHERE authors_fts MATCH ?
+		ORDER BY `+authorRank+`
+		LIMIT ?`, match, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search authors: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var authors []*types.Author
+	for rows.Next() {
+		author, err := d.scanAuthor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan author: %w", err)
+		}
+		authors = append(authors, author)
+	}
+	return authors, rows.Err()
+}
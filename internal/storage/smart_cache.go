@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// GetLikedSongsSince returns the slugs of the songs liked since since,
+// latest first.
+func (d *Database) GetLikedSongsSince(ctx context.Context, since time.Time) ([]string, error) {
+	return d.querySlugs(ctx, "GetLikedSongsSince", `
+		SELECT slug FROM songs
+		WHERE liked = 1 AND liked_at >= ? AND deleted_at IS NULL
+		ORDER BY liked_at DESC
+	`, since)
+}
+
+// GetStaleDownloads returns the slugs of the downloaded songs that were
+// neither liked nor downloaded nor played since since.
+func (d *Database) GetStaleDownloads(ctx context.Context, since time.Time) ([]string, error) {
+	return d.querySlugs(ctx, "GetStaleDownloads", `
+		SELECT s.slug FROM songs s
+		WHERE s.downloaded = 1 AND COALESCE(s.liked, 0) != 1
+		  AND COALESCE(s.downloaded_at, '') < ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM play_events e
+			WHERE e.song_slug = s.slug AND e.event_type = 'start' AND e.occurred_at >= ?
+		  )
+	`, since, since)
+}
+
+func (d *Database) querySlugs(ctx context.Context, name, query string, args ...interface{}) ([]string, error) {
+	start := time.Now()
+	defer func() { d.debugLog(name, nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		d.debugLog(name, err, time.Since(start))
+		return nil, fmt.Errorf("query slugs: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("scan slug: %w", err)
+		}
+		slugs = append(slugs, slug)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return slugs, nil
+}
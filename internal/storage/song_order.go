@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// SongOrder is an order of the song library the API can't sort by, so it is
+// sorted here over every stored song instead of over a loaded page.
+type SongOrder string
+
+const (
+	SongOrderName        SongOrder = "name"
+	SongOrderNameReverse SongOrder = "-name"
+	// SongOrderArtist sorts by the first credited author; songs without one
+	// come last.
+	SongOrderArtist   SongOrder = "artist"
+	SongOrderShortest SongOrder = "shortest"
+)
+
+// firstAuthorName is the name of a song's first credited author, in the
+// order loadSongAuthors returns them.
+const firstAuthorName = `(
+	SELECT au.name FROM song_authors sa
+	JOIN authors au ON au.slug = sa.author_slug
+	WHERE sa.song_slug = s.slug
+	ORDER BY sa.rowid
+	LIMIT 1
+)`
+
+// orderBy returns the ORDER BY clause of order, ties broken newest first as
+// GetSongs sorts.
+func (o SongOrder) orderBy() (string, error) {
+	switch o {
+	case SongOrderName:
+		return `s.name COLLATE NOCASE ASC, s.created_at DESC`, nil
+	case SongOrderNameReverse:
+		return `s.name COLLATE NOCASE DESC, s.created_at DESC`, nil
+	case SongOrderArtist:
+		return firstAuthorName + ` IS NULL, ` + firstAuthorName + ` COLLATE NOCASE ASC, s.name COLLATE NOCASE ASC`, nil
+	case SongOrderShortest:
+		return `s.length ASC, s.created_at DESC`, nil
+	default:
+		return "", fmt.Errorf("unknown song order %q", o)
+	}
+}
+
+// GetSongsOrdered returns a page of the stored songs in order, only those
+// that play without the server when offlineOnly is set.
+func (d *Database) GetSongsOrdered(ctx context.Context, order SongOrder, offlineOnly bool, limit, offset int) ([]*types.Song, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetSongsOrdered", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	orderBy, err := order.orderBy()
+	if err != nil {
+		return nil, err
+	}
+	where := `s.deleted_at IS NULL`
+	if offlineOnly {
+		where += ` AND ` + availableOffline
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT s.slug, s.name, s.file, s.image, s.image_cropped, s.length,
+		       s.played, s.link, s.liked, s.volume, s.album_slug, s.local_path,
+		       s.downloaded, s.last_sync, s.created_at, s.updated_at,
+		       COALESCE(a.slug, '') as album_slug_ref,
+		       COALESCE(a.name, '') as album_name,
+		       COALESCE(a.image, '') as album_image,
+		       COALESCE(a.image_cropped, '') as album_image_cropped,
+		       COALESCE(a.link, '') as album_link,
+		       COALESCE(a.album_artist, '') as album_artist
+		FROM songs s
+		LEFT JOIN albums a ON s.album_slug = a.slug
+		WHERE `+where+`
+		ORDER BY `+orderBy+`
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		d.debugLog("GetSongsOrdered", err, time.Since(start))
+		return nil, fmt.Errorf("query songs by %s: %w", order, err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var songs []*types.Song
+	for rows.Next() {
+		song, err := d.scanSong(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan song: %w", err)
+		}
+		songs = append(songs, song)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if err := d.loadSongAuthors(ctx, songs); err != nil {
+		return nil, fmt.Errorf("load song authors: %w", err)
+	}
+	return songs, nil
+}
@@ -2,11 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/url"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Alexander-D-Karpov/amp/internal/api"
@@ -16,14 +18,17 @@ import (
 
 // SyncManager handles synchronization between local storage and remote API
 type SyncManager struct {
-	api     *api.Client
+	api     api.MusicBackend
 	storage *Database
 	cfg     *config.Config
 
-	mu      sync.RWMutex
-	running bool
-	stop    chan struct{}
-	ticker  *time.Ticker
+	mu       sync.RWMutex
+	running  bool
+	stop     chan struct{}
+	trigger  chan struct{}
+	syncing  atomic.Bool
+	failures int
+	nextSync time.Time
 
 	onProgress func(string, int, int)
 	onError    func(error)
@@ -49,12 +54,12 @@ type SyncStats struct {
 }
 
 // NewSyncManager creates a new sync manager with the given dependencies
-func NewSyncManager(api *api.Client, storage *Database, cfg *config.Config) *SyncManager {
+func NewSyncManager(api api.MusicBackend, storage *Database, cfg *config.Config) *SyncManager {
 	return &SyncManager{
 		api:     api,
 		storage: storage,
 		cfg:     cfg,
-		stop:    make(chan struct{}),
+		trigger: make(chan struct{}, 1),
 		debug:   cfg.Debug,
 	}
 }
@@ -88,54 +93,55 @@ func extractPageFromURL(urlStr string) int {
 	return page
 }
 
-// Start begins the synchronization process with periodic updates
-func (sm *SyncManager) Start(ctx context.Context) {
-	sm.mu.Lock()
-	if sm.running {
-		sm.mu.Unlock()
-		return
-	}
-	sm.running = true
-	sm.mu.Unlock()
-
-	sm.debugLog("Sync manager starting with interval: %v", time.Duration(sm.cfg.Storage.SyncInterval)*time.Second)
-
-	sm.ticker = time.NewTicker(time.Duration(sm.cfg.Storage.SyncInterval) * time.Second)
+// Entities with a delta sync cursor in sync_state.
+const (
+	syncEntitySongs   = "songs"
+	syncEntityAlbums  = "albums"
+	syncEntityAuthors = "authors"
+)
 
-	go func() {
-		defer func() {
-			sm.mu.Lock()
-			sm.running = false
-			sm.mu.Unlock()
-			sm.debugLog("Sync manager stopped")
-		}()
+// cursorOverlap is taken off each cursor so that records changed around the
+// start of a sync, or hidden by clock skew with the server, are fetched again
+// rather than missed.
+const cursorOverlap = time.Minute
 
-		for {
-			select {
-			case <-ctx.Done():
-				sm.debugLog("Sync manager stopping due to context cancellation")
-				return
-			case <-sm.stop:
-				sm.debugLog("Sync manager stopping due to stop signal")
-				return
-			}
-		}
-	}()
+// syncCursor returns the time after which changes of entity still have to be
+// fetched, or the zero time for a full sync.
+func (sm *SyncManager) syncCursor(ctx context.Context, entity string) time.Time {
+	cursor, err := sm.storage.GetSyncCursor(ctx, entity)
+	if err != nil {
+		sm.debugLog("Failed to load %s sync cursor, doing a full sync: %v", entity, err)
+		return time.Time{}
+	}
+	if !cursor.IsZero() {
+		sm.debugLog("Fetching %s changed since %s", entity, cursor.Format(time.RFC3339))
+	}
+	return cursor
 }
 
-// Stop halts the synchronization process
-func (sm *SyncManager) Stop() {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	if !sm.running {
-		return
+// saveSyncCursor moves the cursor of entity to the start of a sync that
+// fetched every page.
+func (sm *SyncManager) saveSyncCursor(ctx context.Context, entity string, started time.Time) {
+	if err := sm.storage.SetSyncCursor(ctx, entity, started.Add(-cursorOverlap)); err != nil {
+		sm.debugLog("Failed to save %s sync cursor: %v", entity, err)
 	}
+}
 
-	sm.debugLog("Stopping sync manager...")
-	close(sm.stop)
-	if sm.ticker != nil {
-		sm.ticker.Stop()
+// pageFetcher returns delta for syncs with a cursor, and full otherwise or
+// once the server turns out unable to filter by change time.
+func pageFetcher[T any](since time.Time, full, delta func(int) (T, error)) func(int) (T, error) {
+	if since.IsZero() || delta == nil {
+		return full
+	}
+	return func(page int) (T, error) {
+		if delta != nil {
+			resp, err := delta(page)
+			if !errors.Is(err, api.ErrUnsupported) {
+				return resp, err
+			}
+			delta = nil
+		}
+		return full(page)
 	}
 }
 
@@ -145,6 +151,14 @@ func (sm *SyncManager) FullSync(ctx context.Context) error {
 		sm.debugLog("Skipping sync - running in anonymous mode")
 		return nil
 	}
+	if sw, ok := sm.api.(*api.Switch); ok && sw.Offline() {
+		sm.debugLog("Skipping sync - offline mode")
+		return nil
+	}
+	if !sm.syncing.CompareAndSwap(false, true) {
+		return ErrSyncRunning
+	}
+	defer sm.syncing.Store(false)
 
 	stats := &SyncStats{
 		StartTime: time.Now(),
@@ -161,9 +175,11 @@ func (sm *SyncManager) FullSync(ctx context.Context) error {
 		name string
 		fn   func(context.Context, *SyncStats) error
 	}{
+		{"like_changes", sm.pushLikeChanges},
 		{"songs", sm.syncSongs},
 		{"albums", sm.syncAlbums},
 		{"authors", sm.syncAuthors},
+		{"playlist_changes", sm.pushPlaylistChanges},
 		{"playlists", sm.syncPlaylists},
 		{"play_history", sm.syncPlayHistory},
 		{"user_preferences", sm.syncUserPreferences},
@@ -211,6 +227,16 @@ func (sm *SyncManager) FullSync(ctx context.Context) error {
 	return nil
 }
 
+// FullResync forgets the delta sync cursors and fetches the whole library
+// again, for when the local copy is suspected to have drifted.
+func (sm *SyncManager) FullResync(ctx context.Context) error {
+	if err := sm.storage.ClearSyncCursors(ctx); err != nil {
+		return err
+	}
+	sm.debugLog("Sync cursors cleared, running a full sync")
+	return sm.FullSync(ctx)
+}
+
 func (sm *SyncManager) syncSongs(ctx context.Context, stats *SyncStats) error {
 	sm.debugLog("--- Syncing Songs ---")
 
@@ -221,6 +247,20 @@ func (sm *SyncManager) syncSongs(ctx context.Context, stats *SyncStats) error {
 
 	sm.debugLog("Starting songs sync with page limit: %d", limit)
 
+	started := time.Now()
+	since := sm.syncCursor(ctx, syncEntitySongs)
+	var delta func(int) (*types.SongListResponse, error)
+	if lister, ok := sm.api.(api.ChangeLister); ok {
+		delta = func(page int) (*types.SongListResponse, error) {
+			return lister.GetSongsUpdatedAfter(ctx, page, since)
+		}
+	}
+	fetch := pageFetcher(since, func(page int) (*types.SongListResponse, error) {
+		return sm.api.GetSongs(ctx, page, "")
+	}, delta)
+	complete := false
+	failed := 0
+
 	for {
 		if limit > 0 && pagesFetched >= limit {
 			sm.debugLog("Songs page limit reached (%d), stopping.", limit)
@@ -228,35 +268,47 @@ func (sm *SyncManager) syncSongs(ctx context.Context, stats *SyncStats) error {
 		}
 
 		sm.debugLog("Fetching songs page %d...", page)
-		resp, err := sm.api.GetSongs(ctx, page, "")
+		resp, err := fetch(page)
 		if err != nil {
 			return fmt.Errorf("get songs page %d: %w", page, err)
 		}
 		if len(resp.Results) == 0 {
 			sm.debugLog("No more songs to sync")
+			complete = true
 			break
 		}
 
-		for i, song := range resp.Results {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-			}
-			song.LastSync = time.Now()
-			if err := sm.storage.SaveSong(ctx, song); err != nil {
-				sm.debugLog("Failed to save song %s: %v", song.Slug, err)
-				stats.Errors = append(stats.Errors, fmt.Sprintf("save song %s: %v", song.Name, err))
-				continue
-			}
-			totalSynced++
-			if (i+1)%50 == 0 {
-				time.Sleep(50 * time.Millisecond)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, song := range resp.Results {
+			song.LastSync = now
+		}
+
+		// Save the page in one transaction; if that fails, retry song by song
+		// so a single bad row does not drop the rest of the page.
+		if err := sm.storage.SaveSongsBatch(ctx, resp.Results); err == nil {
+			totalSynced += len(resp.Results)
+		} else {
+			sm.debugLog("Batch save of songs page %d failed, saving individually: %v", page, err)
+			for _, song := range resp.Results {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				if err := sm.storage.SaveSong(ctx, song); err != nil {
+					sm.debugLog("Failed to save song %s: %v", song.Slug, err)
+					stats.Errors = append(stats.Errors, fmt.Sprintf("save song %s: %v", song.Name, err))
+					failed++
+					continue
+				}
+				totalSynced++
 			}
 		}
 
 		pagesFetched++
 		if resp.Next == nil {
+			complete = true
 			break
 		}
 		nextPage := extractPageFromURL(*resp.Next)
@@ -267,6 +319,14 @@ func (sm *SyncManager) syncSongs(ctx context.Context, stats *SyncStats) error {
 		time.Sleep(100 * time.Millisecond)
 	}
 
+	// A row that failed to save would be skipped by the next delta sync,
+	// so the cursor only moves when the whole run was stored.
+	if complete && failed == 0 {
+		sm.saveSyncCursor(ctx, syncEntitySongs, started)
+	} else if failed > 0 {
+		sm.debugLog("%d songs failed to save, keeping the sync cursor", failed)
+	}
+
 	stats.SongsSynced = totalSynced
 	stats.SongsTotal = totalSynced
 	sm.debugLog("Songs sync completed: %d synced (pages: %d)", totalSynced, pagesFetched)
@@ -281,6 +341,20 @@ func (sm *SyncManager) syncAlbums(ctx context.Context, stats *SyncStats) error {
 	limit := sm.cfg.Storage.MaxSyncPages
 	totalSynced := 0
 
+	started := time.Now()
+	since := sm.syncCursor(ctx, syncEntityAlbums)
+	var delta func(int) (*types.AlbumListResponse, error)
+	if lister, ok := sm.api.(api.ChangeLister); ok {
+		delta = func(page int) (*types.AlbumListResponse, error) {
+			return lister.GetAlbumsUpdatedAfter(ctx, page, since)
+		}
+	}
+	fetch := pageFetcher(since, func(page int) (*types.AlbumListResponse, error) {
+		return sm.api.GetAlbums(ctx, page, "")
+	}, delta)
+	complete := false
+	failed := 0
+
 	for {
 		if limit > 0 && pagesFetched >= limit {
 			sm.debugLog("Albums page limit reached (%d), stopping.", limit)
@@ -288,13 +362,14 @@ func (sm *SyncManager) syncAlbums(ctx context.Context, stats *SyncStats) error {
 		}
 		sm.debugLog("Fetching albums page %d...", page)
 
-		resp, err := sm.api.GetAlbums(ctx, page, "")
+		resp, err := fetch(page)
 		if err != nil {
 			return fmt.Errorf("get albums page %d: %w", page, err)
 		}
 
 		if len(resp.Results) == 0 {
 			sm.debugLog("No more albums to sync")
+			complete = true
 			break
 		}
 
@@ -311,6 +386,7 @@ func (sm *SyncManager) syncAlbums(ctx context.Context, stats *SyncStats) error {
 			if err := sm.storage.SaveAlbum(ctx, album); err != nil {
 				sm.debugLog("Failed to save album %s: %v", album.Slug, err)
 				stats.Errors = append(stats.Errors, fmt.Sprintf("Failed to save album %s: %v", album.Name, err))
+				failed++
 				continue
 			}
 
@@ -319,6 +395,7 @@ func (sm *SyncManager) syncAlbums(ctx context.Context, stats *SyncStats) error {
 		}
 
 		if resp.Next == nil {
+			complete = true
 			break
 		}
 
@@ -332,6 +409,12 @@ func (sm *SyncManager) syncAlbums(ctx context.Context, stats *SyncStats) error {
 		pagesFetched++
 	}
 
+	if complete && failed == 0 {
+		sm.saveSyncCursor(ctx, syncEntityAlbums, started)
+	} else if failed > 0 {
+		sm.debugLog("%d albums failed to save, keeping the sync cursor", failed)
+	}
+
 	stats.AlbumsSynced = totalSynced
 	stats.AlbumsTotal = totalSynced
 	sm.debugLog("Albums sync completed: %d albums synced", totalSynced)
@@ -347,6 +430,20 @@ func (sm *SyncManager) syncAuthors(ctx context.Context, stats *SyncStats) error
 	limit := sm.cfg.Storage.MaxSyncPages
 	totalSynced := 0
 
+	started := time.Now()
+	since := sm.syncCursor(ctx, syncEntityAuthors)
+	var delta func(int) (*types.AuthorListResponse, error)
+	if lister, ok := sm.api.(api.ChangeLister); ok {
+		delta = func(page int) (*types.AuthorListResponse, error) {
+			return lister.GetAuthorsUpdatedAfter(ctx, page, since)
+		}
+	}
+	fetch := pageFetcher(since, func(page int) (*types.AuthorListResponse, error) {
+		return sm.api.GetAuthors(ctx, page, "")
+	}, delta)
+	complete := false
+	failed := 0
+
 	for {
 		if limit > 0 && pagesFetched >= limit {
 			sm.debugLog("Authors page limit reached (%d), stopping.", limit)
@@ -354,13 +451,14 @@ func (sm *SyncManager) syncAuthors(ctx context.Context, stats *SyncStats) error
 		}
 		sm.debugLog("Fetching authors page %d...", page)
 
-		resp, err := sm.api.GetAuthors(ctx, page, "")
+		resp, err := fetch(page)
 		if err != nil {
 			return fmt.Errorf("get authors page %d: %w", page, err)
 		}
 
 		if len(resp.Results) == 0 {
 			sm.debugLog("No more authors to sync")
+			complete = true
 			break
 		}
 
@@ -377,6 +475,7 @@ func (sm *SyncManager) syncAuthors(ctx context.Context, stats *SyncStats) error
 			if err := sm.storage.SaveAuthor(ctx, author); err != nil {
 				sm.debugLog("Failed to save author %s: %v", author.Slug, err)
 				stats.Errors = append(stats.Errors, fmt.Sprintf("Failed to save author %s: %v", author.Name, err))
+				failed++
 				continue
 			}
 
@@ -385,6 +484,7 @@ func (sm *SyncManager) syncAuthors(ctx context.Context, stats *SyncStats) error
 		}
 
 		if resp.Next == nil {
+			complete = true
 			break
 		}
 
@@ -398,6 +498,12 @@ func (sm *SyncManager) syncAuthors(ctx context.Context, stats *SyncStats) error
 		pagesFetched++
 	}
 
+	if complete && failed == 0 {
+		sm.saveSyncCursor(ctx, syncEntityAuthors, started)
+	} else if failed > 0 {
+		sm.debugLog("%d authors failed to save, keeping the sync cursor", failed)
+	}
+
 	stats.AuthorsSynced = totalSynced
 	stats.AuthorsTotal = totalSynced
 	sm.debugLog("Authors sync completed: %d authors synced", totalSynced)
@@ -405,6 +511,202 @@ func (sm *SyncManager) syncAuthors(ctx context.Context, stats *SyncStats) error
 	return nil
 }
 
+// PushChanges sends the playlist edits and deletes made while offline,
+// creates the local-only playlists on the server, and returns how many
+// playlists were updated or created there. Changes that fail stay queued
+// for the next attempt.
+func (sm *SyncManager) PushChanges(ctx context.Context) (int, error) {
+	sm.debugLog("--- Pushing Playlist Changes ---")
+
+	var errs []error
+	deletes, err := sm.storage.GetQueuedPlaylistDeletes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, slug := range deletes {
+		if err := sm.api.DeletePlaylist(ctx, slug); err != nil {
+			errs = append(errs, fmt.Errorf("delete playlist %s: %w", slug, err))
+			continue
+		}
+		if err := sm.storage.DropQueuedPlaylistDelete(ctx, slug); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	dirty, err := sm.storage.GetDirtyPlaylists(ctx)
+	if err != nil {
+		return 0, errors.Join(append(errs, err)...)
+	}
+
+	pushed := 0
+	for _, entry := range dirty {
+		select {
+		case <-ctx.Done():
+			return pushed, ctx.Err()
+		default:
+		}
+
+		sent, err := sm.pushPlaylist(ctx, entry)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("playlist %s: %w", entry.Playlist.Slug, err))
+			continue
+		}
+		if sent {
+			pushed++
+		}
+	}
+
+	locals, err := sm.storage.GetLocalOnlyPlaylists(ctx)
+	if err != nil {
+		return pushed, errors.Join(append(errs, err)...)
+	}
+	created := 0
+	for _, local := range locals {
+		select {
+		case <-ctx.Done():
+			return pushed + created, ctx.Err()
+		default:
+		}
+
+		err := sm.createPlaylist(ctx, local)
+		if errors.Is(err, api.ErrUnsupported) {
+			sm.debugLog("Server cannot create playlists, keeping %d local-only", len(locals)-created)
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("playlist %s: %w", local.Slug, err))
+			continue
+		}
+		created++
+	}
+
+	sm.debugLog("Pushed %d/%d changed playlists, created %d/%d local-only, %d deletes queued",
+		pushed, len(dirty), created, len(locals), len(deletes))
+	return pushed + created, errors.Join(errs...)
+}
+
+// createPlaylist creates a local-only playlist on the server and stores it
+// under the slug the server gave it. Songs the server does not know, from
+// local files, stay in the stored playlist but are not sent.
+func (sm *SyncManager) createPlaylist(ctx context.Context, local *types.Playlist) error {
+	remote := *local
+	remote.Slug = ""
+	remote.LocalOnly = false
+	remote.Songs = nil
+	for _, song := range local.Songs {
+		if song != nil && !song.IsLocalOnly() {
+			remote.Songs = append(remote.Songs, song)
+		}
+	}
+	remote.Length = len(remote.Songs)
+
+	if err := sm.api.CreatePlaylist(ctx, &remote); err != nil {
+		return fmt.Errorf("create playlist: %w", err)
+	}
+	if remote.Slug == "" {
+		return errors.New("create playlist: server returned no slug")
+	}
+	sm.debugLog("Created playlist %s on the server as %s", local.Name, remote.Slug)
+	return sm.storage.PublishPlaylist(ctx, local.Slug, &remote)
+}
+
+// pushPlaylist resolves one dirty playlist against the server. A server copy
+// changed since the one the local edits were made to wins over them;
+// otherwise the local copy is sent. Both change times come from the server,
+// so clock skew between it and this machine does not matter. Servers that
+// report no change time, and playlists whose last server copy is not known,
+// always take the local copy. It reports whether the local copy was sent.
+func (sm *SyncManager) pushPlaylist(ctx context.Context, entry *DirtyPlaylist) (bool, error) {
+	local := entry.Playlist
+
+	remote, err := sm.api.GetPlaylist(ctx, local.Slug)
+	if err != nil {
+		return false, fmt.Errorf("get playlist: %w", err)
+	}
+
+	if remote != nil && !remote.UpdatedAt.IsZero() && !entry.ServerUpdatedAt.IsZero() &&
+		remote.UpdatedAt.After(entry.ServerUpdatedAt) {
+		sm.debugLog("Conflict on playlist %s: server copy changed since, dropping %d local edits", local.Name, entry.Edits)
+		remote.LastSync = time.Now()
+		if err := sm.storage.SaveServerPlaylist(ctx, remote); err != nil {
+			return false, fmt.Errorf("save playlist: %w", err)
+		}
+		return false, sm.storage.ClearPlaylistDirty(ctx, local.Slug, entry.Edits)
+	}
+
+	err = sm.api.UpdatePlaylist(ctx, local)
+	if errors.Is(err, api.ErrUnsupported) {
+		// The server will never take the edits; keep them locally only.
+		sm.debugLog("Server cannot edit playlist %s, keeping local edits", local.Name)
+		return false, sm.storage.ClearPlaylistDirty(ctx, local.Slug, entry.Edits)
+	}
+	if err != nil {
+		return false, fmt.Errorf("update playlist: %w", err)
+	}
+	// The update changed the server's copy; the next pull records its time.
+	if err := sm.storage.SetPlaylistServerUpdatedAt(ctx, local.Slug, time.Time{}); err != nil {
+		return true, err
+	}
+	return true, sm.storage.ClearPlaylistDirty(ctx, local.Slug, entry.Edits)
+}
+
+// PushLikes sends the queued likes and unlikes and returns how many were
+// sent. Likes that fail stay queued for the next attempt; backends that keep
+// no likes leave them all queued, so they stay local.
+func (sm *SyncManager) PushLikes(ctx context.Context) (int, error) {
+	likes, err := sm.storage.GetQueuedLikes(ctx)
+	if err != nil || len(likes) == 0 {
+		return 0, err
+	}
+	liker, ok := sm.api.(api.Liker)
+	if !ok {
+		sm.debugLog("Server keeps no likes, keeping %d likes local", len(likes))
+		return 0, nil
+	}
+
+	sm.debugLog("--- Pushing %d Like Changes ---", len(likes))
+
+	var errs []error
+	sent := 0
+	for _, like := range likes {
+		select {
+		case <-ctx.Done():
+			return sent, ctx.Err()
+		default:
+		}
+
+		send := liker.DislikeSong
+		if like.Liked {
+			send = liker.LikeSong
+		}
+		err := send(ctx, like.Slug)
+		if errors.Is(err, api.ErrUnsupported) {
+			sm.debugLog("Server keeps no likes, keeping %d likes local", len(likes)-sent)
+			break
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("like %s: %w", like.Slug, err))
+			continue
+		}
+		if err := sm.storage.DropQueuedLike(ctx, like); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (sm *SyncManager) pushLikeChanges(ctx context.Context, stats *SyncStats) error {
+	_, err := sm.PushLikes(ctx)
+	return err
+}
+
+func (sm *SyncManager) pushPlaylistChanges(ctx context.Context, stats *SyncStats) error {
+	_, err := sm.PushChanges(ctx)
+	return err
+}
+
 func (sm *SyncManager) syncPlaylists(ctx context.Context, stats *SyncStats) error {
 	sm.debugLog("--- Syncing Playlists ---")
 
@@ -427,6 +729,10 @@ func (sm *SyncManager) syncPlaylists(ctx context.Context, stats *SyncStats) erro
 			sm.debugLog("Skipping local-only playlist: %s", playlist.Name)
 			continue
 		}
+		if dirty, err := sm.storage.IsPlaylistDirty(ctx, playlist.Slug); err == nil && dirty {
+			sm.debugLog("Skipping playlist with unsent changes: %s", playlist.Name)
+			continue
+		}
 
 		sm.debugLog("Fetching full playlist data for: %s", playlist.Name)
 
@@ -438,7 +744,7 @@ func (sm *SyncManager) syncPlaylists(ctx context.Context, stats *SyncStats) erro
 		}
 
 		fullPlaylist.LastSync = time.Now()
-		if err := sm.storage.SavePlaylist(ctx, fullPlaylist); err != nil {
+		if err := sm.storage.SaveServerPlaylist(ctx, fullPlaylist); err != nil {
 			sm.debugLog("Failed to save playlist %s: %v", playlist.Slug, err)
 			stats.Errors = append(stats.Errors, fmt.Sprintf("Failed to save playlist %s: %v", playlist.Name, err))
 			continue
@@ -460,38 +766,15 @@ func (sm *SyncManager) syncPlaylists(ctx context.Context, stats *SyncStats) erro
 func (sm *SyncManager) syncPlayHistory(ctx context.Context, stats *SyncStats) error {
 	sm.debugLog("--- Syncing Play History ---")
 
-	query := `
-		SELECT song_slug, user_id, played_at 
-		FROM play_history 
-		WHERE synced = false 
-		ORDER BY played_at ASC 
-		LIMIT 100
-	`
-
-	rows, err := sm.storage.GetDB().QueryContext(ctx, query)
+	toSync, err := sm.storage.GetUnsyncedListens(ctx, 100)
 	if err != nil {
 		return fmt.Errorf("query unsynced play history: %w", err)
 	}
-	defer func() {
-		if closeErr := rows.Close(); closeErr != nil {
-			sm.debugLog("Failed to close rows: %v", closeErr)
-		}
-	}()
-
-	var toSync []types.PlayHistory
-	for rows.Next() {
-		var history types.PlayHistory
-		if err := rows.Scan(&history.SongSlug, &history.UserID, &history.PlayedAt); err != nil {
-			sm.debugLog("Failed to scan play history: %v", err)
-			continue
-		}
-		toSync = append(toSync, history)
-	}
 
 	sm.debugLog("Found %d play history entries to sync", len(toSync))
 
 	synced := 0
-	for _, history := range toSync {
+	for _, event := range toSync {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
@@ -499,18 +782,16 @@ func (sm *SyncManager) syncPlayHistory(ctx context.Context, stats *SyncStats) er
 		}
 
 		userID := ""
-		if history.UserID != nil {
-			userID = *history.UserID
+		if event.UserID != nil {
+			userID = *event.UserID
 		}
 
-		if err := sm.api.ListenSong(ctx, history.SongSlug, userID); err != nil {
-			sm.debugLog("Failed to sync play count for %s: %v", history.SongSlug, err)
+		if err := sm.api.ListenSong(ctx, event.SongSlug, userID); err != nil {
+			sm.debugLog("Failed to sync play count for %s: %v", event.SongSlug, err)
 			continue
 		}
 
-		if _, err := sm.storage.GetDB().ExecContext(ctx,
-			"UPDATE play_history SET synced = true WHERE song_slug = ? AND played_at = ?",
-			history.SongSlug, history.PlayedAt); err != nil {
+		if err := sm.storage.MarkPlayEventSynced(ctx, event.ID); err != nil {
 			sm.debugLog("Failed to mark play history as synced: %v", err)
 			continue
 		}
@@ -579,29 +860,10 @@ func (sm *SyncManager) ForceSyncAuthors(ctx context.Context) error {
 func (sm *SyncManager) ForceSyncPlaylists(ctx context.Context) error {
 	sm.debugLog("Force syncing playlists...")
 	stats := &SyncStats{StartTime: time.Now(), Errors: make([]string, 0)}
-	return sm.syncPlaylists(ctx, stats)
-}
-
-// IsRunning returns true if the sync manager is currently running
-func (sm *SyncManager) IsRunning() bool {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
-	return sm.running
-}
-
-// SetInterval updates the sync interval
-func (sm *SyncManager) SetInterval(interval time.Duration) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	sm.cfg.Storage.SyncInterval = int(interval.Seconds())
-
-	if sm.ticker != nil {
-		sm.ticker.Stop()
-		sm.ticker = time.NewTicker(interval)
+	if err := sm.pushPlaylistChanges(ctx, stats); err != nil {
+		sm.debugLog("Failed to push playlist changes: %v", err)
 	}
-
-	sm.debugLog("Sync interval updated to: %v", interval)
+	return sm.syncPlaylists(ctx, stats)
 }
 
 // OnProgress sets the progress callback
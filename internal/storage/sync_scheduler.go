@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// ErrSyncRunning is returned when a sync is asked for while one runs.
+var ErrSyncRunning = errors.New("a sync is already running")
+
+const (
+	// syncStartDelay lets the app finish starting before the first sync.
+	syncStartDelay = 5 * time.Second
+	// minSyncInterval guards against a zero or tiny configured interval.
+	minSyncInterval = time.Minute
+	// syncJitter spreads syncs by up to this fraction of the delay, so many
+	// clients started together do not hit the server at the same moment.
+	syncJitter = 0.1
+	// Failed syncs are retried after syncRetryBase, doubling each time up
+	// to the regular interval or syncRetryMax, whichever is longer.
+	syncRetryBase = 30 * time.Second
+	syncRetryMax  = time.Hour
+)
+
+// Start runs FullSync in the background: shortly after starting, then every
+// Storage.SyncInterval seconds, retrying sooner with exponential backoff
+// after a failure. It runs until ctx is done or Stop is called.
+func (sm *SyncManager) Start(ctx context.Context) {
+	sm.mu.Lock()
+	if sm.running {
+		sm.mu.Unlock()
+		return
+	}
+	sm.running = true
+	sm.failures = 0
+	stop := make(chan struct{})
+	sm.stop = stop
+	sm.mu.Unlock()
+
+	sm.debugLog("Sync scheduler starting with interval: %v", sm.interval())
+	timer := time.NewTimer(sm.schedule(syncStartDelay))
+
+	go func() {
+		defer func() {
+			sm.mu.Lock()
+			sm.running = false
+			sm.nextSync = time.Time{}
+			sm.mu.Unlock()
+			sm.debugLog("Sync scheduler stopped")
+		}()
+
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				sm.debugLog("Sync scheduler stopping due to context cancellation")
+				return
+			case <-stop:
+				sm.debugLog("Sync scheduler stopping due to stop signal")
+				return
+			case <-sm.trigger:
+				sm.debugLog("Sync requested")
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			case <-timer.C:
+			}
+
+			timer.Reset(sm.schedule(sm.runScheduled(ctx)))
+		}
+	}()
+}
+
+// Stop halts the scheduler. A sync in progress finishes first.
+func (sm *SyncManager) Stop() {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if !sm.running || sm.stop == nil {
+		return
+	}
+
+	sm.debugLog("Stopping sync scheduler...")
+	close(sm.stop)
+	sm.stop = nil
+}
+
+// SyncNow asks the scheduler for a sync right away. Without a running
+// scheduler the sync runs once in the background.
+func (sm *SyncManager) SyncNow(ctx context.Context) {
+	if !sm.IsRunning() {
+		go func() {
+			if err := sm.FullSync(ctx); err != nil && sm.onError != nil {
+				sm.onError(err)
+			}
+		}()
+		return
+	}
+
+	select {
+	case sm.trigger <- struct{}{}:
+	default:
+		// A sync is already requested.
+	}
+}
+
+// IsRunning returns true if the scheduler is running
+func (sm *SyncManager) IsRunning() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.running
+}
+
+// IsSyncing reports whether a sync is in progress.
+func (sm *SyncManager) IsSyncing() bool {
+	return sm.syncing.Load()
+}
+
+// NextSync returns when the scheduler syncs next, or the zero time when it
+// is not running.
+func (sm *SyncManager) NextSync() time.Time {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.nextSync
+}
+
+// runScheduled runs one sync and returns the delay until the next one.
+func (sm *SyncManager) runScheduled(ctx context.Context) time.Duration {
+	err := sm.FullSync(ctx)
+	if errors.Is(err, ErrSyncRunning) {
+		// A resync started by hand is running; check back later.
+		return syncRetryBase
+	}
+
+	sm.mu.Lock()
+	if err == nil {
+		sm.failures = 0
+	} else {
+		sm.failures++
+	}
+	failures := sm.failures
+	sm.mu.Unlock()
+
+	if err == nil {
+		return sm.interval()
+	}
+
+	if ctx.Err() == nil && sm.onError != nil {
+		sm.onError(err)
+	}
+	delay := retryDelay(failures, sm.interval())
+	sm.debugLog("Sync failed (%d in a row), retrying in %v: %v", failures, delay, err)
+	return delay
+}
+
+// schedule adds jitter to delay and records when the next sync is due.
+func (sm *SyncManager) schedule(delay time.Duration) time.Duration {
+	delay = withJitter(delay)
+	sm.mu.Lock()
+	sm.nextSync = time.Now().Add(delay)
+	sm.mu.Unlock()
+	return delay
+}
+
+func (sm *SyncManager) interval() time.Duration {
+	return max(time.Duration(sm.cfg.Storage.SyncInterval)*time.Second, minSyncInterval)
+}
+
+// retryDelay is the backoff after the given number of failures in a row.
+func retryDelay(failures int, interval time.Duration) time.Duration {
+	limit := max(interval, syncRetryMax)
+	delay := syncRetryBase
+	for i := 1; i < failures && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+func withJitter(delay time.Duration) time.Duration {
+	spread := float64(delay) * syncJitter
+	return delay + time.Duration((rand.Float64()*2-1)*spread)
+}
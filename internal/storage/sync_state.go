@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetSyncCursor returns the delta sync cursor of an entity, or the zero time
+// when it was never fully synced.
+func (d *Database) GetSyncCursor(ctx context.Context, entity string) (time.Time, error) {
+	if err := d.checkClosed(); err != nil {
+		return time.Time{}, err
+	}
+
+	var cursor time.Time
+	err := d.db.QueryRowContext(ctx, "SELECT cursor FROM sync_state WHERE entity = ?", entity).Scan(&cursor)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query sync cursor %s: %w", entity, err)
+	}
+	return cursor, nil
+}
+
+// SetSyncCursor records that everything of an entity changed before cursor
+// is stored.
+func (d *Database) SetSyncCursor(ctx context.Context, entity string, cursor time.Time) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO sync_state (entity, cursor, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(entity) DO UPDATE SET cursor = excluded.cursor, updated_at = excluded.updated_at
+	`, entity, cursor, time.Now())
+	if err != nil {
+		return fmt.Errorf("save sync cursor %s: %w", entity, err)
+	}
+	return nil
+}
+
+// ClearSyncCursors forgets every cursor, so the next sync fetches everything.
+func (d *Database) ClearSyncCursors(ctx context.Context) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, "DELETE FROM sync_state"); err != nil {
+		return fmt.Errorf("clear sync cursors: %w", err)
+	}
+	return nil
+}
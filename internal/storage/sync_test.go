@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// playlistServer serves one playlist and records the updates sent to it.
+type playlistServer struct {
+	api.MusicBackend
+	remote  types.Playlist
+	updated []types.Playlist
+}
+
+func (s *playlistServer) GetPlaylist(_ context.Context, slug string) (*types.Playlist, error) {
+	remote := s.remote
+	return &remote, nil
+}
+
+func (s *playlistServer) UpdatePlaylist(_ context.Context, playlist *types.Playlist) error {
+	s.updated = append(s.updated, *playlist)
+	return nil
+}
+
+// editOffline stores the server's copy, then renames it the way an edit made
+// while offline does.
+func editOffline(t *testing.T, db *Database, server *playlistServer, name string) *DirtyPlaylist {
+	t.Helper()
+	ctx := context.Background()
+
+	synced := server.remote
+	if err := db.SaveServerPlaylist(ctx, &synced); err != nil {
+		t.Fatalf("save server playlist: %v", err)
+	}
+	if err := db.UpdatePlaylistDetails(ctx, synced.Slug, name, synced.Private); err != nil {
+		t.Fatalf("rename playlist: %v", err)
+	}
+	if err := db.MarkPlaylistDirty(ctx, synced.Slug); err != nil {
+		t.Fatalf("mark playlist dirty: %v", err)
+	}
+
+	dirty, err := db.GetDirtyPlaylists(ctx)
+	if err != nil || len(dirty) != 1 {
+		t.Fatalf("dirty playlists %v, %v; want one", dirty, err)
+	}
+	return dirty[0]
+}
+
+// TestPushPlaylistIgnoresServerClockSkew edits a playlist offline whose
+// server clock runs an hour ahead. The server copy did not change since the
+// last sync, so the edit must be sent, not dropped as a conflict.
+func TestPushPlaylistIgnoresServerClockSkew(t *testing.T) {
+	db := newTestDatabase(t)
+	server := &playlistServer{remote: types.Playlist{
+		Slug:      "road-trip",
+		Name:      "Road Trip",
+		UpdatedAt: time.Now().Add(time.Hour).Truncate(time.Millisecond),
+	}}
+	sm := NewSyncManager(server, db, &config.Config{})
+
+	entry := editOffline(t, db, server, "Road Trip 2")
+	sent, err := sm.pushPlaylist(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("push playlist: %v", err)
+	}
+	if !sent || len(server.updated) != 1 || server.updated[0].Name != "Road Trip 2" {
+		t.Fatalf("sent %v, updates %v; want the renamed playlist sent", sent, server.updated)
+	}
+}
+
+// TestPushPlaylistKeepsNewerServerCopy changes the server copy after the
+// last sync; the server copy wins over the offline edit.
+func TestPushPlaylistKeepsNewerServerCopy(t *testing.T) {
+	db := newTestDatabase(t)
+	synced := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	server := &playlistServer{remote: types.Playlist{
+		Slug:      "road-trip",
+		Name:      "Road Trip",
+		UpdatedAt: synced,
+	}}
+	sm := NewSyncManager(server, db, &config.Config{})
+
+	entry := editOffline(t, db, server, "Road Trip 2")
+	server.remote.Name = "Road Trip (edited elsewhere)"
+	server.remote.UpdatedAt = synced.Add(time.Minute)
+
+	sent, err := sm.pushPlaylist(context.Background(), entry)
+	if err != nil {
+		t.Fatalf("push playlist: %v", err)
+	}
+	if sent || len(server.updated) != 0 {
+		t.Fatalf("sent %v, updates %v; want the local edit dropped", sent, server.updated)
+	}
+
+	stored, err := db.GetPlaylist(context.Background(), "road-trip")
+	if err != nil || stored == nil {
+		t.Fatalf("get playlist: %v", err)
+	}
+	if stored.Name != server.remote.Name {
+		t.Fatalf("stored name %q, want the server's %q", stored.Name, server.remote.Name)
+	}
+	if dirty, _ := db.IsPlaylistDirty(context.Background(), "road-trip"); dirty {
+		t.Fatal("playlist still dirty after the conflict was resolved")
+	}
+}
+
+// likeServer records the likes sent to it.
+type likeServer struct {
+	api.MusicBackend
+	liked map[string]bool
+}
+
+func (s *likeServer) LikeSong(_ context.Context, slug string) error {
+	s.liked[slug] = true
+	return nil
+}
+
+func (s *likeServer) DislikeSong(_ context.Context, slug string) error {
+	s.liked[slug] = false
+	return nil
+}
+
+// TestSyncKeepsQueuedLikesAndPlayCounts likes a song and raises its play
+// count locally, as a library import does, then pulls the server's older
+// copy. The pull keeps both until the like is sent.
+func TestSyncKeepsQueuedLikesAndPlayCounts(t *testing.T) {
+	db := newTestDatabase(t)
+	ctx := context.Background()
+
+	liked, notLiked := true, false
+	album := &types.Album{Slug: "late-hours", Name: "Late Hours"}
+	song := &types.Song{Slug: "night-drive", Name: "Night Drive", Album: album, Played: 40, Liked: &liked}
+	if err := db.SaveLike(ctx, song); err != nil {
+		t.Fatalf("save like: %v", err)
+	}
+
+	pulled := &types.Song{Slug: "night-drive", Name: "Night Drive", Album: album, Played: 3, Liked: &notLiked}
+	if err := db.SaveSongsBatch(ctx, []*types.Song{pulled}); err != nil {
+		t.Fatalf("save pulled songs: %v", err)
+	}
+	stored, err := db.GetSong(ctx, "night-drive")
+	if err != nil || stored == nil {
+		t.Fatalf("get song: %v", err)
+	}
+	if stored.Liked == nil || !*stored.Liked {
+		t.Fatal("pull dropped the queued like")
+	}
+	if stored.Played != 40 {
+		t.Fatalf("played %d after pull, want 40", stored.Played)
+	}
+
+	server := &likeServer{liked: map[string]bool{}}
+	sm := NewSyncManager(server, db, &config.Config{})
+	sent, err := sm.PushLikes(ctx)
+	if err != nil || sent != 1 || !server.liked["night-drive"] {
+		t.Fatalf("push likes: sent %d, %v, server %v", sent, err, server.liked)
+	}
+	if queued, _ := db.GetQueuedLikes(ctx); len(queued) != 0 {
+		t.Fatalf("likes still queued after push: %v", queued)
+	}
+
+	// Once sent, the server's copy is the one to follow.
+	if err := db.SaveSongsBatch(ctx, []*types.Song{pulled}); err != nil {
+		t.Fatalf("save pulled songs: %v", err)
+	}
+	if stored, _ := db.GetSong(ctx, "night-drive"); stored.Liked == nil || *stored.Liked {
+		t.Fatal("server copy ignored after the like was sent")
+	}
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// GetTaskRun returns when a scheduled task last ran, or the zero time when it
+// never did.
+func (d *Database) GetTaskRun(ctx context.Context, task string) (time.Time, error) {
+	if err := d.checkClosed(); err != nil {
+		return time.Time{}, err
+	}
+
+	var ranAt time.Time
+	err := d.db.QueryRowContext(ctx, "SELECT ran_at FROM task_runs WHERE task = ?", task).Scan(&ranAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query task run %s: %w", task, err)
+	}
+	return ranAt, nil
+}
+
+// SetTaskRun records that a scheduled task ran at ranAt.
+func (d *Database) SetTaskRun(ctx context.Context, task string, ranAt time.Time) error {
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO task_runs (task, ran_at) VALUES (?, ?)
+		ON CONFLICT(task) DO UPDATE SET ran_at = excluded.ran_at
+	`, task, ranAt)
+	if err != nil {
+		return fmt.Errorf("save task run %s: %w", task, err)
+	}
+	return nil
+}
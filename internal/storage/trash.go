@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+func (d *Database) moveToTrash(ctx context.Context, table, slug string) error {
+	_, err := d.db.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s SET deleted_at = ? WHERE slug = ? AND deleted_at IS NULL", table),
+		time.Now(), slug,
+	)
+	if err != nil {
+		return fmt.Errorf("move %s to trash: %w", slug, err)
+	}
+	return nil
+}
+
+// GetTrash returns deleted songs and playlists, most recently deleted first.
+func (d *Database) GetTrash(ctx context.Context) ([]*types.TrashItem, error) {
+	start := time.Now()
+	defer func() { d.debugLog("GetTrash", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT ? AS kind, slug, name, FALSE AS local_only, deleted_at
+		FROM songs WHERE deleted_at IS NOT NULL
+		UNION ALL
+		SELECT ? AS kind, slug, name, local_only, deleted_at
+		FROM playlists WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+	`, string(types.TrashSong), string(types.TrashPlaylist))
+	if err != nil {
+		return nil, fmt.Errorf("query trash: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	var items []*types.TrashItem
+	for rows.Next() {
+		item := &types.TrashItem{}
+		if err := rows.Scan(&item.Kind, &item.Slug, &item.Name, &item.LocalOnly, &item.DeletedAt); err != nil {
+			return nil, fmt.Errorf("scan trash item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// RestoreFromTrash brings a deleted song or playlist back.
+func (d *Database) RestoreFromTrash(ctx context.Context, kind types.TrashKind, slug string) error {
+	start := time.Now()
+	defer func() { d.debugLog("RestoreFromTrash", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	table, err := trashTable(kind)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE slug = ?", table), slug,
+	)
+	if err != nil {
+		return fmt.Errorf("restore %s: %w", slug, err)
+	}
+	return nil
+}
+
+// PurgeFromTrash deletes a trashed song or playlist for good. Items that are
+// not in the trash are left alone.
+func (d *Database) PurgeFromTrash(ctx context.Context, kind types.TrashKind, slug string) error {
+	start := time.Now()
+	defer func() { d.debugLog("PurgeFromTrash", nil, time.Since(start)) }()
+
+	if err := d.checkClosed(); err != nil {
+		return err
+	}
+
+	table, err := trashTable(kind)
+	if err != nil {
+		return err
+	}
+
+	result, err := d.db.ExecContext(ctx,
+		fmt.Sprintf("DELETE FROM %s WHERE slug = ? AND deleted_at IS NOT NULL", table), slug,
+	)
+	if err != nil {
+		return fmt.Errorf("purge %s: %w", slug, err)
+	}
+
+	if kind == types.TrashPlaylist {
+		if n, _ := result.RowsAffected(); n > 0 {
+			if _, err := d.db.ExecContext(ctx, "DELETE FROM playlist_transitions WHERE playlist_slug = ?", slug); err != nil {
+				return fmt.Errorf("delete playlist transition: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func trashTable(kind types.TrashKind) (string, error) {
+	switch kind {
+	case types.TrashSong:
+		return "songs", nil
+	case types.TrashPlaylist:
+		return "playlists", nil
+	default:
+		return "", fmt.Errorf("unknown trash kind %q", kind)
+	}
+}
+
+// TrashedSlugs returns the slugs of everything of the given kind in the trash,
+// so results fetched from the API can be filtered the same way as local ones.
+func (d *Database) TrashedSlugs(ctx context.Context, kind types.TrashKind) (map[string]bool, error) {
+	if err := d.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	table, err := trashTable(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.QueryContext(ctx,
+		fmt.Sprintf("SELECT slug FROM %s WHERE deleted_at IS NOT NULL", table),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query trashed %s: %w", table, err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("Failed to close rows: %v", closeErr)
+		}
+	}()
+
+	slugs := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, fmt.Errorf("scan trashed slug: %w", err)
+		}
+		slugs[slug] = true
+	}
+	return slugs, rows.Err()
+}
@@ -0,0 +1,429 @@
+// Package tui is a terminal front end for AMP: it browses and searches the
+// library, keeps a queue and plays it, for SSH sessions and machines where
+// the desktop app is too heavy. It runs on the same services and player as
+// the desktop app.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio"
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	// refreshInterval is how often the playback position is redrawn.
+	refreshInterval = 500 * time.Millisecond
+	seekStep        = 10 * time.Second
+	volumeStep      = 0.05
+	// loadAhead is how close to the end of the library the cursor gets
+	// before the next page is fetched.
+	loadAhead = 10
+)
+
+type view int
+
+const (
+	viewLibrary view = iota
+	viewSearch
+	viewQueue
+)
+
+var viewNames = []string{"Library", "Search", "Queue"}
+
+// Run shows the terminal UI until the user quits or ctx is done.
+func Run(ctx context.Context, music *services.MusicService, player *audio.Player) error {
+	m := newModel(ctx, music, player)
+	program := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx))
+	player.OnFinished(func() { program.Send(finishedMsg{}) })
+
+	_, err := program.Run()
+	if errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+type (
+	pageMsg struct {
+		page  int
+		songs []*types.Song
+		more  bool
+		err   error
+	}
+	searchMsg struct {
+		query string
+		songs []*types.Song
+		err   error
+	}
+	playMsg struct {
+		song *types.Song
+		err  error
+	}
+	finishedMsg struct{}
+	tickMsg     time.Time
+)
+
+type model struct {
+	ctx    context.Context
+	music  *services.MusicService
+	player *audio.Player
+
+	width, height int
+	view          view
+
+	library     songList
+	libraryPage int
+	libraryMore bool
+	loading     bool
+
+	results   songList
+	query     string
+	input     string
+	searching bool
+
+	queue   songList
+	current int
+	paused  bool
+
+	status string
+}
+
+func newModel(ctx context.Context, music *services.MusicService, player *audio.Player) *model {
+	return &model{
+		ctx:         ctx,
+		music:       music,
+		player:      player,
+		libraryMore: true,
+		current:     -1,
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return tea.Batch(m.loadPage(), tick())
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case tickMsg:
+		return m, tick()
+
+	case pageMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Could not load songs: %v", msg.err)
+			return m, nil
+		}
+		m.libraryPage = msg.page
+		m.libraryMore = msg.more
+		m.library.songs = append(m.library.songs, msg.songs...)
+		return m, nil
+
+	case searchMsg:
+		if msg.query != m.query {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Search failed: %v", msg.err)
+			return m, nil
+		}
+		m.results = songList{songs: msg.songs}
+		m.status = fmt.Sprintf("%d songs for %q", len(msg.songs), msg.query)
+		return m, nil
+
+	case playMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Could not play %s: %v", msg.song.Name, msg.err)
+			return m, nil
+		}
+		m.paused = false
+		m.status = ""
+		return m, nil
+
+	case finishedMsg:
+		if m.current+1 < len(m.queue.songs) {
+			return m, m.playAt(m.current + 1)
+		}
+		m.status = "End of the queue"
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.searching {
+			return m, m.editSearch(msg)
+		}
+		return m, m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	list := m.list()
+	switch msg.String() {
+	case "q", "ctrl+c":
+		_ = m.player.Stop()
+		return tea.Quit
+	case "tab":
+		m.view = (m.view + 1) % view(len(viewNames))
+	case "shift+tab":
+		m.view = (m.view + view(len(viewNames)) - 1) % view(len(viewNames))
+	case "1", "2", "3":
+		m.view = view(msg.String()[0] - '1')
+	case "/":
+		m.view = viewSearch
+		m.searching = true
+		m.input = m.query
+
+	case "up", "k":
+		list.move(-1)
+	case "down", "j":
+		list.move(1)
+	case "pgup":
+		list.move(-m.listHeight())
+	case "pgdown":
+		list.move(m.listHeight())
+	case "home", "g":
+		list.move(-len(list.songs))
+	case "end", "G":
+		list.move(len(list.songs))
+
+	case "enter":
+		return m.playSelected()
+	case "a":
+		if song := list.selected(); song != nil && m.view != viewQueue {
+			m.queue.songs = append(m.queue.songs, song)
+			m.status = "Queued " + song.Name
+		}
+	case "d", "x", "delete":
+		if m.view == viewQueue {
+			m.removeFromQueue(m.queue.cursor)
+		}
+	case "K":
+		if m.view == viewQueue {
+			m.moveInQueue(-1)
+		}
+	case "J":
+		if m.view == viewQueue {
+			m.moveInQueue(1)
+		}
+
+	case " ":
+		m.togglePause()
+	case "n":
+		if m.current+1 < len(m.queue.songs) {
+			return m.playAt(m.current + 1)
+		}
+	case "p":
+		if m.player.GetPosition() > 3*time.Second || m.current <= 0 {
+			m.seek(-m.player.GetPosition())
+		} else {
+			return m.playAt(m.current - 1)
+		}
+	case "left":
+		m.seek(-seekStep)
+	case "right":
+		m.seek(seekStep)
+	case "+", "=":
+		m.setVolume(m.player.Volume() + volumeStep)
+	case "-":
+		m.setVolume(m.player.Volume() - volumeStep)
+	}
+
+	if m.view == viewLibrary && m.libraryMore && !m.loading && m.library.cursor >= len(m.library.songs)-loadAhead {
+		return m.loadPage()
+	}
+	return nil
+}
+
+// editSearch edits the search query; enter runs it and esc gives up.
+func (m *model) editSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.searching = false
+	case tea.KeyEnter:
+		m.searching = false
+		m.query = m.input
+		if m.query == "" {
+			return nil
+		}
+		m.status = fmt.Sprintf("Searching for %q…", m.query)
+		return m.search(m.query)
+	case tea.KeyBackspace:
+		if runes := []rune(m.input); len(runes) > 0 {
+			m.input = string(runes[:len(runes)-1])
+		}
+	case tea.KeyCtrlU:
+		m.input = ""
+	case tea.KeyCtrlC:
+		_ = m.player.Stop()
+		return tea.Quit
+	case tea.KeyRunes, tea.KeySpace:
+		m.input += string(msg.Runes)
+	}
+	return nil
+}
+
+func (m *model) list() *songList {
+	switch m.view {
+	case viewSearch:
+		return &m.results
+	case viewQueue:
+		return &m.queue
+	default:
+		return &m.library
+	}
+}
+
+func (m *model) loadPage() tea.Cmd {
+	if m.loading || !m.libraryMore {
+		return nil
+	}
+	m.loading = true
+	page := m.libraryPage + 1
+	ctx, music := m.ctx, m.music
+	return func() tea.Msg {
+		songs, more, err := music.GetSongs(ctx, page, "")
+		return pageMsg{page: page, songs: songs, more: more, err: err}
+	}
+}
+
+func (m *model) search(query string) tea.Cmd {
+	ctx, music := m.ctx, m.music
+	return func() tea.Msg {
+		results, err := music.SearchAll(ctx, query)
+		if err != nil {
+			return searchMsg{query: query, err: err}
+		}
+		var songs []*types.Song
+		if results != nil {
+			songs = results.Songs
+		}
+		return searchMsg{query: query, songs: songs}
+	}
+}
+
+// playSelected plays the song under the cursor. From the library or search
+// results the list becomes the queue, as it does in the desktop app.
+func (m *model) playSelected() tea.Cmd {
+	list := m.list()
+	if list.selected() == nil {
+		return nil
+	}
+	if m.view != viewQueue {
+		m.queue = songList{songs: append([]*types.Song(nil), list.songs...), cursor: list.cursor}
+	}
+	return m.playAt(list.cursor)
+}
+
+func (m *model) playAt(index int) tea.Cmd {
+	if index < 0 || index >= len(m.queue.songs) {
+		return nil
+	}
+	m.current = index
+	song := m.queue.songs[index]
+	m.status = "Loading " + song.Name + "…"
+
+	ctx, player := m.ctx, m.player
+	return func() tea.Msg {
+		return playMsg{song: song, err: player.Play(ctx, song)}
+	}
+}
+
+func (m *model) removeFromQueue(index int) {
+	if index < 0 || index >= len(m.queue.songs) {
+		return
+	}
+	m.queue.songs = append(m.queue.songs[:index], m.queue.songs[index+1:]...)
+	switch {
+	case index < m.current:
+		m.current--
+	case index == m.current:
+		// The song goes on playing; the next one is the one after it.
+		m.current = index - 1
+	}
+	m.queue.move(0)
+}
+
+func (m *model) moveInQueue(delta int) {
+	from := m.queue.cursor
+	to := from + delta
+	if from < 0 || to < 0 || to >= len(m.queue.songs) {
+		return
+	}
+	songs := m.queue.songs
+	songs[from], songs[to] = songs[to], songs[from]
+	switch m.current {
+	case from:
+		m.current = to
+	case to:
+		m.current = from
+	}
+	m.queue.cursor = to
+}
+
+func (m *model) togglePause() {
+	if m.current < 0 {
+		return
+	}
+	var err error
+	if m.paused {
+		err = m.player.Resume()
+	} else {
+		err = m.player.Pause()
+	}
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	m.paused = !m.paused
+}
+
+func (m *model) seek(delta time.Duration) {
+	if m.current < 0 || !m.player.CanSeek() {
+		return
+	}
+	minSeek, maxSeek := m.player.GetSeekableRange()
+	pos := max(minSeek, min(m.player.GetPosition()+delta, maxSeek))
+	if err := m.player.Seek(pos); err != nil {
+		m.status = fmt.Sprintf("Seek failed: %v", err)
+	}
+}
+
+func (m *model) setVolume(level float64) {
+	level = max(0, min(1, level))
+	if err := m.player.SetVolume(level); err != nil {
+		log.Printf("[TUI] Failed to set volume: %v", err)
+	}
+}
+
+// songList is a list of songs with a cursor.
+type songList struct {
+	songs  []*types.Song
+	cursor int
+	offset int
+}
+
+func (l *songList) move(delta int) {
+	l.cursor = max(0, min(l.cursor+delta, len(l.songs)-1))
+}
+
+func (l *songList) selected() *types.Song {
+	if l.cursor < 0 || l.cursor >= len(l.songs) {
+		return nil
+	}
+	return l.songs[l.cursor]
+}
@@ -0,0 +1,228 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+var (
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("245"))
+	activeTabStyle = lipgloss.NewStyle().Padding(0, 1).Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("212"))
+	cursorStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	playingStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	dimStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	titleStyle     = lipgloss.NewStyle().Bold(true)
+)
+
+// chromeLines is how many lines the tabs, now playing and help take.
+const chromeLines = 6
+
+const help = "enter play · a queue · space pause · n/p next/prev · ←/→ seek · +/- volume · / search · tab views · q quit"
+
+func (m *model) View() string {
+	if m.width == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.tabs())
+	b.WriteString("\n\n")
+	b.WriteString(m.body())
+	b.WriteString(m.nowPlaying())
+	b.WriteString("\n")
+	b.WriteString(dimStyle.Render(truncate(help, m.width)))
+	return b.String()
+}
+
+func (m *model) tabs() string {
+	var tabs []string
+	for i, name := range viewNames {
+		label := fmt.Sprintf("%d %s", i+1, name)
+		if view(i) == viewQueue && len(m.queue.songs) > 0 {
+			label += fmt.Sprintf(" (%d)", len(m.queue.songs))
+		}
+		if view(i) == m.view {
+			tabs = append(tabs, activeTabStyle.Render(label))
+		} else {
+			tabs = append(tabs, tabStyle.Render(label))
+		}
+	}
+	line := strings.Join(tabs, " ")
+	if m.music.IsOffline() {
+		line += dimStyle.Render("  offline")
+	}
+	return line
+}
+
+func (m *model) listHeight() int {
+	return max(1, m.height-chromeLines)
+}
+
+// body renders the list of the current view, padded to its height so the
+// now playing lines stay at the bottom.
+func (m *model) body() string {
+	height := m.listHeight()
+	lines := make([]string, 0, height)
+
+	if m.view == viewSearch {
+		prompt := "/" + m.query
+		if m.searching {
+			prompt = "/" + m.input + "█"
+		}
+		lines = append(lines, titleStyle.Render(truncate(prompt, m.width)))
+		height--
+	}
+
+	list := m.list()
+	switch {
+	case len(list.songs) == 0:
+		lines = append(lines, dimStyle.Render(m.emptyText()))
+	default:
+		list.scroll(height)
+		end := min(len(list.songs), list.offset+height)
+		for i := list.offset; i < end; i++ {
+			lines = append(lines, m.songLine(list, i))
+		}
+	}
+
+	for len(lines) < m.listHeight() {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func (m *model) emptyText() string {
+	switch m.view {
+	case viewSearch:
+		if m.searching || m.query == "" {
+			return "Type to search, enter to run it."
+		}
+		return "Nothing found."
+	case viewQueue:
+		return "The queue is empty. Press a on a song to add it."
+	default:
+		if m.loading {
+			return "Loading…"
+		}
+		return "No songs."
+	}
+}
+
+func (m *model) songLine(list *songList, i int) string {
+	song := list.songs[i]
+	playing := m.isCurrent(list, i)
+
+	marker := "  "
+	switch {
+	case playing && m.paused:
+		marker = "‖ "
+	case playing:
+		marker = "▶ "
+	}
+	length := formatDuration(time.Duration(song.Length) * time.Second)
+	text := song.Name
+	if artists := types.CreditsLabel(song.Authors); artists != "" {
+		text += " — " + artists
+	}
+	width := max(1, m.width-runewidth.StringWidth(marker)-len(length)-1)
+	text = runewidth.FillRight(truncate(text, width), width)
+	line := marker + text + " " + length
+
+	switch {
+	case i == list.cursor:
+		return cursorStyle.Render(line)
+	case playing:
+		return playingStyle.Render(line)
+	default:
+		return line
+	}
+}
+
+// isCurrent reports whether row i of list is the song playing.
+func (m *model) isCurrent(list *songList, i int) bool {
+	if m.current < 0 || m.current >= len(m.queue.songs) {
+		return false
+	}
+	if list == &m.queue {
+		return i == m.current
+	}
+	return list.songs[i].Slug == m.queue.songs[m.current].Slug
+}
+
+func (m *model) nowPlaying() string {
+	if m.current < 0 || m.current >= len(m.queue.songs) {
+		status := m.status
+		if status == "" {
+			status = "Nothing playing"
+		}
+		return dimStyle.Render(truncate(status, m.width)) + "\n\n"
+	}
+
+	song := m.queue.songs[m.current]
+	pos, dur := m.player.GetPosition(), m.player.GetDuration()
+	if dur <= 0 {
+		dur = time.Duration(song.Length) * time.Second
+	}
+
+	state := "▶"
+	if m.paused {
+		state = "‖"
+	}
+	info := fmt.Sprintf("%s / %s  vol %d%%", formatDuration(pos), formatDuration(dur), int(m.player.Volume()*100+0.5))
+	title := song.Name
+	if artists := types.CreditsLabel(song.Authors); artists != "" {
+		title += " — " + artists
+	}
+	if m.status != "" {
+		title = m.status
+	}
+	titleWidth := max(1, m.width-len(info)-4)
+	line := state + " " + titleStyle.Render(runewidth.FillRight(truncate(title, titleWidth), titleWidth)) + "  " + info
+
+	return line + "\n" + progressBar(pos, dur, m.width) + "\n"
+}
+
+func progressBar(pos, dur time.Duration, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	filled := 0
+	if dur > 0 {
+		filled = int(float64(width) * float64(min(pos, dur)) / float64(dur))
+	}
+	return playingStyle.Render(strings.Repeat("━", filled)) + dimStyle.Render(strings.Repeat("─", width-filled))
+}
+
+// scroll keeps the cursor within the height rows shown.
+func (l *songList) scroll(height int) {
+	l.move(0)
+	if l.cursor < l.offset {
+		l.offset = l.cursor
+	}
+	if l.cursor >= l.offset+height {
+		l.offset = l.cursor - height + 1
+	}
+	l.offset = max(0, min(l.offset, len(l.songs)-height))
+	l.offset = max(0, l.offset)
+}
+
+func truncate(s string, width int) string {
+	return runewidth.Truncate(s, width, "…")
+}
+
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	seconds := int(d.Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -2,27 +2,39 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/Alexander-D-Karpov/amp/internal/api"
 	"github.com/Alexander-D-Karpov/amp/internal/audio"
+	"github.com/Alexander-D-Karpov/amp/internal/backup"
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/demo"
 	"github.com/Alexander-D-Karpov/amp/internal/download"
+	"github.com/Alexander-D-Karpov/amp/internal/feedback"
 	"github.com/Alexander-D-Karpov/amp/internal/handlers"
 	"github.com/Alexander-D-Karpov/amp/internal/media"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+	"github.com/Alexander-D-Karpov/amp/internal/party"
 	"github.com/Alexander-D-Karpov/amp/internal/search"
 	"github.com/Alexander-D-Karpov/amp/internal/services"
 	"github.com/Alexander-D-Karpov/amp/internal/storage"
 	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
 	"github.com/Alexander-D-Karpov/amp/internal/ui/themes"
 	"github.com/Alexander-D-Karpov/amp/internal/ui/views"
+	"github.com/Alexander-D-Karpov/amp/internal/updater"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
@@ -36,13 +48,32 @@ type App struct {
 	ui       *UIComponents
 	state    *AppState
 	eventBus *handlers.EventBus
+	updater  *updater.Updater
+	media    *mediaControls
+	tray     *trayIcon
+	hotkeys  *globalHotkeys
+	queue    *queueSaver
+	library  *libraryScanner
+	backups  *backup.Scheduler
+	kiosk    *views.KioskView
+
+	// sleepTicking is set while the sleep timer's countdown is updated
+	// every second.
+	sleepTicking bool
+
+	// guestReset is held while a stale anonymous token is replaced.
+	guestReset sync.Mutex
+
+	version string
+	commit  string
 
 	mainContainer *fyne.Container
 	lastSize      fyne.Size
 }
 
 type Core struct {
-	api             *api.Client
+	api             api.MusicBackend
+	offline         *api.Switch
 	storage         *storage.Database
 	player          *audio.Player
 	searchEngine    *search.SearchEngine
@@ -52,14 +83,24 @@ type Core struct {
 	musicService    *services.MusicService
 	imageService    *services.ImageService
 	playSyncService *services.PlaySyncService
+	lyricsService   *services.LyricsService
+	partyServer     *party.Server
+	resourceMonitor *services.ResourceMonitor
+	cacheJanitor    *storage.CacheJanitor
+	smartCache      *services.SmartCache
+	cast            *services.CastService
+	sleepTimer      *audio.SleepTimer
 }
 
 type UIComponents struct {
 	playerBar        *components.PlayerBar
+	queuePanel       *components.QueuePanel
 	sidebar          *components.Sidebar
 	mainView         *views.MainView
 	authDialog       *components.AuthDialog
+	aboutDialog      *components.AboutDialog
 	statusBar        *widget.Label
+	sleepLabel       *widget.Label
 	loadingIndicator *widget.ProgressBarInfinite
 }
 
@@ -68,18 +109,24 @@ type AppState struct {
 	currentQueue    []*types.Song
 	currentIndex    int
 	compactMode     bool
-	syncInProgress  bool
+	kioskMode       bool
 }
 
 func NewApp(ctx context.Context, fyneApp fyne.App, cfg *config.Config) (*App, error) {
-	fyneApp.Settings().SetTheme(themes.NewTheme(cfg.UI.Theme))
+	fyneApp.Settings().SetTheme(newTheme(cfg))
 
 	core, err := initCore(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("initialize core: %w", err)
 	}
 
-	window := fyneApp.NewWindow("AMP - A(dvanced)karpov Music Player")
+	title := "AMP - A(dvanced)karpov Music Player"
+	if cfg.Demo {
+		title += " (Demo)"
+	}
+	window := fyneApp.NewWindow(title)
+	// Closing the main window quits, even with the mini-player still open.
+	window.SetMaster()
 	window.Resize(fyne.NewSize(float32(cfg.UI.WindowWidth), float32(cfg.UI.WindowHeight)))
 	window.CenterOnScreen()
 
@@ -95,6 +142,7 @@ func NewApp(ctx context.Context, fyneApp fyne.App, cfg *config.Config) (*App, er
 		},
 		eventBus: handlers.NewEventBus(),
 		lastSize: window.Canvas().Size(),
+		version:  "dev",
 	}
 
 	if err := app.initUI(); err != nil {
@@ -103,6 +151,7 @@ func NewApp(ctx context.Context, fyneApp fyne.App, cfg *config.Config) (*App, er
 
 	app.setupEventHandlers()
 	app.setupKeyboardShortcuts()
+	app.setupKiosk()
 	app.loadSavedState()
 	app.startBackgroundTasks()
 	app.startResizePolling()
@@ -113,10 +162,40 @@ func NewApp(ctx context.Context, fyneApp fyne.App, cfg *config.Config) (*App, er
 	return app, nil
 }
 
+// newTheme builds the app theme from the appearance settings.
+func newTheme(cfg *config.Config) fyne.Theme {
+	return themes.NewTheme(cfg.UI.Theme, themes.TextOptions{
+		Scale:    cfg.UI.TextScale,
+		Font:     cfg.UI.Font,
+		TimeFont: cfg.UI.TimeFont,
+	}, cfg.UI.HighContrast)
+}
+
 func initCore(cfg *config.Config) (*Core, error) {
-	apiClient := api.NewClient(cfg)
+	if err := netutil.Apply(cfg); err != nil {
+		log.Printf("[APP] Ignoring invalid network settings: %v", err)
+	}
+
+	// Earlier builds saved a new anonymous token as if it were a login, so
+	// it was sent as one and every request was refused.
+	if !cfg.User.IsAnonymous && cfg.API.Token != "" && cfg.API.Token == cfg.User.AnonymousID {
+		log.Printf("[APP] Restoring anonymous token saved as a login")
+		cfg.User.IsAnonymous = true
+		if err := cfg.Save(); err != nil {
+			log.Printf("[APP] Failed to save config: %v", err)
+		}
+	}
+
+	var backend api.MusicBackend
+	if cfg.Demo {
+		backend = demo.NewBackend(cfg)
+	} else {
+		backend = api.NewBackend(cfg)
+	}
+	offline := api.NewSwitch(backend, cfg.API.Offline)
+	apiClient := api.MusicBackend(offline)
 	if cfg.User.IsAnonymous && cfg.API.Token == "" {
-		if _, err := apiClient.EnsureAnonymousToken(context.Background()); err != nil {
+		if _, err := apiClient.EnsureAnonymousToken(context.Background()); err != nil && !errors.Is(err, api.ErrUnsupported) {
 			log.Printf("anon token create failed: %v", err)
 		}
 	}
@@ -135,10 +214,23 @@ func initCore(cfg *config.Config) (*Core, error) {
 	}
 	searchEngine := search.NewSearchEngine(cfg, storageDB)
 	downloadManager := download.NewManager(cfg)
+	downloadManager.EnablePersistence(storageDB)
 	syncManager := storage.NewSyncManager(apiClient, storageDB, cfg)
 	musicService := services.NewMusicService(apiClient, storageDB, searchEngine)
 	imageService := services.NewImageService(imageLoader)
+	if err := imageService.EnableArtworkOverrides(context.Background(), storageDB, filepath.Join(cfg.Storage.CacheDir, "artwork")); err != nil {
+		log.Printf("[APP] Artwork overrides unavailable: %v", err)
+	}
+	if err := imageService.EnablePlaylistCollages(filepath.Join(cfg.Storage.CacheDir, "collages")); err != nil {
+		log.Printf("[APP] Playlist collages unavailable: %v", err)
+	}
 	playSyncService := services.NewPlaySyncService(apiClient, storageDB, cfg, cfg.Debug)
+	lyricsService := services.NewLyricsService(apiClient, storageDB, cfg)
+	partyServer := party.NewServer(cfg, musicService)
+	resourceMonitor := services.NewResourceMonitor(cfg, imageService, player)
+	cacheJanitor := storage.NewCacheJanitor(storageDB, cfg)
+	smartCache := services.NewSmartCache(musicService, downloadManager, cfg)
+	castService := services.NewCastService(cfg)
 
 	if !cfg.Debug {
 		musicService.SetDebug(false)
@@ -147,6 +239,7 @@ func initCore(cfg *config.Config) (*Core, error) {
 
 	return &Core{
 		api:             apiClient,
+		offline:         offline,
 		storage:         storageDB,
 		player:          player,
 		searchEngine:    searchEngine,
@@ -156,6 +249,13 @@ func initCore(cfg *config.Config) (*Core, error) {
 		musicService:    musicService,
 		imageService:    imageService,
 		playSyncService: playSyncService,
+		lyricsService:   lyricsService,
+		partyServer:     partyServer,
+		resourceMonitor: resourceMonitor,
+		cacheJanitor:    cacheJanitor,
+		smartCache:      smartCache,
+		cast:            castService,
+		sleepTimer:      audio.NewSleepTimer(player),
 	}, nil
 }
 
@@ -163,15 +263,20 @@ func (a *App) initUI() error {
 	a.ui = &UIComponents{
 		playerBar:        components.NewPlayerBar(a.core.player, a.core.storage, a.core.imageService, a.cfg.Debug),
 		sidebar:          components.NewSidebar(a.cfg),
-		authDialog:       components.NewAuthDialog(a.core.api),
+		authDialog:       components.NewAuthDialog(a.core.offline.Backend()),
+		aboutDialog:      components.NewAboutDialog("", ""),
 		statusBar:        widget.NewLabel("Ready"),
+		sleepLabel:       widget.NewLabel(""),
 		loadingIndicator: widget.NewProgressBarInfinite(),
 	}
 
 	a.ui.statusBar.Hide()
+	a.ui.sleepLabel.Hide()
 
 	a.ui.playerBar.SetConfig(a.cfg)
 	a.ui.playerBar.SetParentWindow(a.window)
+	a.ui.playerBar.SetSleepTimer(a.core.sleepTimer)
+	a.ui.playerBar.SetCaster(a.core.cast)
 
 	a.ui.playerBar.OnPrefetchNext(func(s *types.Song) {
 		go func() {
@@ -184,9 +289,26 @@ func (a *App) initUI() error {
 		}()
 	})
 
+	a.ui.playerBar.OnRadio(func(seed *types.Song, queued []*types.Song) []*types.Song {
+		exclude := make(map[string]bool, len(queued))
+		for _, song := range queued {
+			exclude[song.Slug] = true
+		}
+		ctx, cancel := context.WithTimeout(a.ctx, 30*time.Second)
+		defer cancel()
+		return a.core.musicService.RadioSongs(ctx, seed, exclude, radioBatchSize)
+	})
+
 	a.ui.loadingIndicator.Hide()
 	a.ui.mainView = views.NewMainView(a.core.musicService, a.core.imageService, a.core.downloadManager, a.core.playSyncService, a.cfg)
 	a.ui.mainView.SetParentWindow(a.window)
+	a.ui.playerBar.SetUndoBar(a.ui.mainView.UndoBar())
+	a.ui.mainView.SongDetailView.SetLyricsService(a.core.lyricsService)
+	a.ui.playerBar.OnPositionChanged(a.ui.mainView.SongDetailView.SetPlaybackPosition)
+
+	a.ui.queuePanel = components.NewQueuePanel(a.ui.playerBar)
+	a.ui.queuePanel.OnClose(a.ui.queuePanel.Toggle)
+	a.ui.playerBar.OnShowQueue(a.ui.queuePanel.Toggle)
 
 	a.createLayout()
 	a.window.SetContent(a.mainContainer)
@@ -199,16 +321,17 @@ func (a *App) initUI() error {
 func (a *App) createLayout() {
 	statusContainer := container.NewBorder(
 		nil, nil,
-		a.ui.statusBar, a.ui.loadingIndicator,
+		a.ui.statusBar, container.NewHBox(a.ui.sleepLabel, a.ui.loadingIndicator),
 		nil,
 	)
 
 	bottomBar := container.NewVBox(
+		a.ui.mainView.UndoBar().Container(),
 		a.ui.playerBar.Container(),
 		statusContainer,
 	)
 
-	a.mainContainer = container.NewBorder(nil, bottomBar, a.ui.sidebar, nil, a.ui.mainView.Container())
+	a.mainContainer = container.NewBorder(nil, bottomBar, a.ui.sidebar, a.ui.queuePanel.Container(), a.ui.mainView.Container())
 }
 
 func (a *App) startResizePolling() {
@@ -245,12 +368,57 @@ func (a *App) handleWindowResize(size fyne.Size) {
 
 func (a *App) setupEventHandlers() {
 	a.ui.mainView.SettingsView.SetParentWindow(a.window)
+	a.ui.mainView.SettingsView.OnImportLibrary(a.showLibraryImport)
+	a.ui.mainView.SettingsView.OnScanLibrary(a.rescanLibrary)
+	a.ui.mainView.SettingsView.OnFullResync(a.fullResync)
+	a.ui.mainView.SettingsView.OnTestConnection(a.testConnection)
+	a.ui.mainView.SettingsView.OnSettingsChanged(func() {
+		a.core.player.ApplyOutputDevice()
+		a.applyCueDevice()
+		a.core.player.ApplyCrossfeed()
+		a.core.player.ApplyEqualizer()
+		a.applyPartyMode()
+		a.applyQueueTransition()
+		a.fyneApp.Settings().SetTheme(newTheme(a.cfg))
+		a.ui.playerBar.RefreshDynamicColors()
+		a.ui.playerBar.RefreshSeekMode()
+		a.applyMediaControls()
+		a.applyGlobalHotkeys()
+		a.applyLibraryScanner()
+		a.applyNetworkSettings()
+		go a.sweepCache()
+	})
+
+	a.setupPartyMode()
+	a.setupMediaControls()
+	a.setupTray()
+	a.ui.playerBar.OnPlaybackChanged(func(song *types.Song, playing bool) {
+		a.media.publish(song, playing)
+		if a.tray != nil {
+			fyne.Do(func() { a.tray.update(song, playing) })
+		}
+	})
+	a.setupGlobalHotkeys()
+	a.setupQueuePersistence()
+	a.setupBackups()
+	a.setupCacheJanitor()
+	a.setupSmartCache()
+	a.setupSleepTimer()
 
 	a.ui.sidebar.OnNavigate(func(view string) {
 		a.ui.mainView.ShowView(view)
 		a.updateStatus("Viewing " + view)
 	})
 
+	a.ui.sidebar.OnOpenRecent(func(shortcut *types.RecentShortcut) {
+		switch shortcut.Kind {
+		case types.RecentAlbum:
+			a.ui.mainView.OpenAlbumBySlug(shortcut.Slug)
+		case types.RecentAuthor:
+			a.ui.mainView.OpenAuthorBySlug(shortcut.Slug)
+		}
+	})
+
 	a.ui.sidebar.OnAuthRequested(func() {
 		if a.state.isAuthenticated {
 			a.logout()
@@ -259,6 +427,18 @@ func (a *App) setupEventHandlers() {
 		}
 	})
 
+	a.ui.sidebar.OnAboutRequested(func() {
+		a.ui.aboutDialog.Show(a.window)
+	})
+
+	a.ui.sidebar.OnOfflineChanged(a.setOfflineMode)
+
+	a.ui.aboutDialog.OnUpdateRequested(func() {
+		a.ui.mainView.ShowView("settings")
+	})
+
+	a.ui.aboutDialog.OnFeedbackRequested(a.showFeedback)
+
 	a.ui.authDialog.OnAuthenticated(func(token string) {
 		a.handleAuthentication(token)
 	})
@@ -267,6 +447,27 @@ func (a *App) setupEventHandlers() {
 		a.playSong(song, playlist)
 	})
 
+	a.ui.mainView.OnPreview(func(song *types.Song) {
+		if a.cfg.UI.HoverPreview {
+			a.core.player.Preview(song)
+		}
+	}, a.core.player.StopPreview)
+	a.applyCueDevice()
+
+	a.ui.mainView.OnQueueSongs(func(songs []*types.Song, next bool) {
+		what := songs[0].Name
+		if len(songs) > 1 {
+			what = fmt.Sprintf("%d songs", len(songs))
+		}
+		if next {
+			a.ui.playerBar.InsertNext(songs...)
+			a.updateStatus(fmt.Sprintf("Playing %s next", what))
+		} else {
+			a.ui.playerBar.AddToQueue(songs...)
+			a.updateStatus(fmt.Sprintf("Added %s to the queue", what))
+		}
+	})
+
 	a.ui.mainView.OnAlbumSelected(func(album *types.Album) {
 		a.ui.mainView.OpenAlbumDetail(album)
 	})
@@ -282,6 +483,31 @@ func (a *App) setupEventHandlers() {
 		}
 	})
 
+	a.ui.playerBar.OnTrackStarted(func(song *types.Song, index, queueLength int) {
+		go func() {
+			if err := a.core.playSyncService.RecordAndSendListen(context.Background(), song, index, queueLength); err != nil && a.cfg.Debug {
+				log.Printf("[APP] Failed to record listen for %s: %v", song.Name, err)
+			}
+		}()
+	})
+
+	a.ui.playerBar.OnTrackEnded(func(song *types.Song, playedSeconds int, finished bool) {
+		go func() {
+			if err := a.core.playSyncService.RecordPlaybackEnd(context.Background(), song, playedSeconds, finished); err != nil && a.cfg.Debug {
+				log.Printf("[APP] Failed to record playback end for %s: %v", song.Name, err)
+			}
+		}()
+	})
+
+	a.ui.playerBar.OnPrivateModeChanged(func(enabled bool) {
+		a.core.playSyncService.SetPrivate(enabled)
+		if enabled {
+			a.updateStatus("Private listening: plays are not recorded")
+		} else {
+			a.updateStatus("Private listening off")
+		}
+	})
+
 	a.ui.playerBar.OnNext(func() {
 		a.updateStatus("Next song")
 	})
@@ -315,20 +541,28 @@ func (a *App) setupEventHandlers() {
 }
 
 func (a *App) setupSyncEventHandlers() {
+	a.ui.sidebar.OnSyncNow(a.syncNow)
+
 	a.core.syncManager.OnProgress(func(status string, current, total int) {
 		if total > 0 && current < total {
 			a.showLoading(true)
+			fyne.Do(func() { a.ui.sidebar.SetSyncing(true) })
 		}
 	})
 
 	a.core.syncManager.OnError(func(err error) {
-		a.showLoading(false)
-		a.state.syncInProgress = false
+		log.Printf("[APP] Library sync failed: %v", err)
+		fyne.Do(func() {
+			a.ui.sidebar.SetSyncing(false)
+			if next := a.core.syncManager.NextSync(); !next.IsZero() {
+				a.updateStatus("Sync failed, retrying at " + next.Format("15:04"))
+			}
+		})
 	})
 
 	a.core.syncManager.OnComplete(func() {
 		a.showLoading(false)
-		a.state.syncInProgress = false
+		fyne.Do(func() { a.ui.sidebar.SetSyncing(false) })
 		go func() {
 			time.Sleep(100 * time.Millisecond)
 			fyne.Do(func() {
@@ -352,18 +586,29 @@ func (a *App) setupKeyboardShortcuts() {
 			a.core.player.Seek(a.core.player.GetPosition() + 10*time.Second)
 		case fyne.KeyLeft:
 			a.core.player.Seek(a.core.player.GetPosition() - 10*time.Second)
-		case fyne.KeyF:
-			a.window.SetFullScreen(!a.window.FullScreen())
+		case fyne.KeyF11:
+			if !a.state.kioskMode {
+				a.window.SetFullScreen(!a.window.FullScreen())
+			}
 		case fyne.KeyEscape:
-			if a.window.FullScreen() {
+			if a.window.FullScreen() && !a.state.kioskMode {
 				a.window.SetFullScreen(false)
 			}
 		}
 	})
 
+	// Letters typed outside a text field jump through the current list, so
+	// search and fullscreen are on keys that don't type letters.
 	a.window.Canvas().SetOnTypedRune(func(r rune) {
-		if r == 's' || r == 'S' {
+		switch {
+		case r == '/':
 			a.focusSearch()
+		case a.state.kioskMode:
+			if r == 's' || r == 'S' {
+				a.focusSearch()
+			}
+		default:
+			a.ui.mainView.TypeToJump(r)
 		}
 	})
 }
@@ -383,6 +628,7 @@ func (a *App) loadSavedState() {
 			a.loadInitialSongs()
 		})
 	}()
+	go a.restoreQueue()
 }
 
 func (a *App) loadInitialSongs() {
@@ -410,6 +656,7 @@ func (a *App) initializeAnonymous() {
 		}
 		a.cfg.User.AnonymousID = anonID
 		a.cfg.Save()
+		a.validateGuestSession(ctx)
 	}()
 }
 
@@ -441,8 +688,30 @@ func (a *App) handleAuthentication(token string) {
 
 func (a *App) startBackgroundTasks() {
 	if a.core.playSyncService != nil {
+		a.core.playSyncService.OnStaleToken(func() { go a.resetGuestSession() })
 		a.core.playSyncService.Start()
 	}
+	a.applyPartyMode()
+	a.applyMediaControls()
+	a.applyGlobalHotkeys()
+	a.applyLibraryScanner()
+
+	go a.probeServer()
+	if !a.cfg.API.Offline {
+		go a.sendOfflineChanges()
+	}
+	go a.purgeTrashPeriodically()
+	go a.saveQueuePositionPeriodically()
+	if a.backups != nil {
+		go a.backups.Run(a.ctx)
+	}
+	go a.core.resourceMonitor.Run(a.ctx)
+	go a.core.cacheJanitor.Run(a.ctx)
+	go a.core.smartCache.Run(a.ctx)
+	go a.core.musicService.RunEnrichment(a.ctx)
+	go a.watchBluetoothOutput()
+	go a.refreshCacheUsage()
+	go a.resumeDownloads()
 
 	go func() {
 		ticker := time.NewTicker(30 * time.Second)
@@ -458,6 +727,77 @@ func (a *App) startBackgroundTasks() {
 	}()
 }
 
+// resumeDownloads restores the downloads left unfinished by the last run.
+func (a *App) resumeDownloads() {
+	restored, err := a.core.downloadManager.ResumeSaved(a.ctx)
+	if err != nil {
+		log.Printf("[APP] Failed to restore downloads: %v", err)
+		return
+	}
+	if restored > 0 {
+		fyne.Do(a.ui.mainView.DownloadsView.Refresh)
+		a.updateStatus(fmt.Sprintf("Restored %d unfinished downloads", restored))
+	}
+}
+
+// applyNetworkSettings moves every HTTP client onto the current proxy and
+// TLS settings.
+func (a *App) applyNetworkSettings() {
+	if err := netutil.Apply(a.cfg); err != nil {
+		log.Printf("[APP] Ignoring invalid network settings: %v", err)
+	}
+}
+
+// probeServer learns the server version so optional features the server
+// lacks are not offered.
+func (a *App) probeServer() {
+	ctx, cancel := context.WithTimeout(a.ctx, 10*time.Second)
+	defer cancel()
+
+	info, err := a.core.api.ProbeServer(ctx)
+	if err != nil {
+		log.Printf("[APP] Failed to probe server: %v", err)
+		return
+	}
+	if a.cfg.Debug {
+		log.Printf("[APP] Server version %q, legacy: %v", info.Version, info.Legacy)
+	}
+}
+
+// trashPurgeInterval is how often expired trash is purged while running.
+const trashPurgeInterval = 6 * time.Hour
+
+// radioBatchSize is how many songs radio mode queues each time the queue
+// runs out.
+const radioBatchSize = 10
+
+// purgeTrashPeriodically permanently removes trashed items once their
+// retention period has passed.
+func (a *App) purgeTrashPeriodically() {
+	purge := func() {
+		count, err := a.core.musicService.PurgeExpiredTrash(a.ctx)
+		if err != nil {
+			log.Printf("[APP] Failed to purge trash: %v", err)
+			return
+		}
+		if count > 0 && a.cfg.Debug {
+			log.Printf("[APP] Purged %d expired items from the trash", count)
+		}
+	}
+
+	purge()
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			purge()
+		}
+	}
+}
+
 func (a *App) playSong(song *types.Song, playlist []*types.Song) {
 	if a.cfg.Debug {
 		log.Printf("[APP] Playing song: %s", song.Name)
@@ -475,12 +815,70 @@ func (a *App) playSong(song *types.Song, playlist []*types.Song) {
 		a.state.currentIndex = 0
 	}
 	a.ui.playerBar.SetQueue(a.state.currentQueue, a.state.currentIndex)
+	a.applyQueueTransition()
 
 	if a.cfg.Download.AutoDownload && !song.Downloaded {
 		go a.core.downloadManager.DownloadSong(context.Background(), song)
 	}
 }
 
+func (a *App) setupPartyMode() {
+	server := a.core.partyServer
+
+	server.SetQueueProvider(func() (*types.Song, []*types.Song) {
+		var current *types.Song
+		var upcoming []*types.Song
+		fyne.DoAndWait(func() {
+			queue := a.ui.playerBar.GetQueue()
+			index := a.ui.playerBar.GetCurrentIndex()
+			if index >= 0 && index < len(queue) {
+				current = queue[index]
+			}
+			if index+1 < len(queue) {
+				upcoming = append(upcoming, queue[index+1:]...)
+			}
+		})
+		return current, upcoming
+	})
+
+	server.OnRequest(func(req *party.Request) {
+		if req.Status != party.StatusPending {
+			return
+		}
+		message := fmt.Sprintf("%s wants to play \"%s\" by %s.", req.Guest, req.Song.Name, getArtistNames(req.Song.Authors))
+		fyne.Do(func() {
+			dialog.ShowConfirm("Party Request", message, func(accept bool) {
+				server.Resolve(req.ID, accept)
+			}, a.window)
+		})
+	})
+
+	server.OnAccepted(func(req *party.Request) {
+		fyne.Do(func() {
+			a.enqueueSong(req.Song)
+			a.updateStatus(fmt.Sprintf("%s added %s to the queue", req.Guest, req.Song.Name))
+		})
+	})
+}
+
+// applyPartyMode starts or stops the party server to match the settings.
+func (a *App) applyPartyMode() {
+	server := a.core.partyServer
+	if !a.cfg.Party.Enabled {
+		server.Stop()
+		a.ui.mainView.SettingsView.SetPartyAddresses(nil)
+		return
+	}
+
+	if err := server.Start(); err != nil {
+		log.Printf("[APP] Failed to start party mode: %v", err)
+		a.updateStatus("Party mode could not start: " + err.Error())
+		a.ui.mainView.SettingsView.SetPartyAddresses(nil)
+		return
+	}
+	a.ui.mainView.SettingsView.SetPartyAddresses(server.Addresses())
+}
+
 func (a *App) playPlaylist(playlist *types.Playlist) {
 	if len(playlist.Songs) == 0 {
 		return
@@ -490,13 +888,106 @@ func (a *App) playPlaylist(playlist *types.Playlist) {
 	a.ui.playerBar.SetQueue(a.state.currentQueue, a.state.currentIndex)
 }
 
+// applyCueDevice opens the pre-listen device set in the settings and offers
+// pre-listening on songs while one is set.
+func (a *App) applyCueDevice() {
+	a.core.player.ApplyCueDevice()
+	if a.cfg.Audio.CueDevice == "" {
+		a.ui.mainView.OnCue(nil)
+		return
+	}
+	a.ui.mainView.OnCue(func(song *types.Song) {
+		// Pre-listening to the same song again stops it.
+		if cued := a.core.player.CueSong(); cued != nil && cued.Slug == song.Slug {
+			a.core.player.StopCue()
+			a.updateStatus(fmt.Sprintf("Stopped pre-listening to %s", song.Name))
+			return
+		}
+		if err := a.core.player.Cue(song); err != nil {
+			a.updateStatus(fmt.Sprintf("Can't pre-listen: %v", err))
+			return
+		}
+		a.updateStatus(fmt.Sprintf("Pre-listening to %s on %s; pre-listen again to stop", song.Name, a.cfg.Audio.CueDevice))
+	})
+}
+
+// applyQueueTransition uses the transition chosen for the playlist the queue
+// was started from. Any other source, and playlists left on the normal
+// transition, follow the crossfade setting.
+func (a *App) applyQueueTransition() {
+	fallback := a.defaultTransition()
+	source := a.core.playSyncService.PlaySource()
+	if source.Type != types.PlaySourcePlaylist || source.ID == "" {
+		a.ui.playerBar.SetTransition(fallback)
+		return
+	}
+
+	go func() {
+		transition, err := a.core.storage.GetPlaylistTransition(context.Background(), source.ID)
+		if err != nil {
+			log.Printf("[APP] Failed to load transition for playlist %s: %v", source.ID, err)
+			return
+		}
+		if transition.Mode == types.TransitionNormal || transition.Mode == "" {
+			transition = &fallback
+		}
+		fyne.Do(func() {
+			a.ui.playerBar.SetTransition(*transition)
+		})
+	}()
+}
+
+// defaultTransition is the transition configured in settings.
+func (a *App) defaultTransition() types.PlaylistTransition {
+	if a.cfg.Audio.Crossfade {
+		return types.PlaylistTransition{Mode: types.TransitionCrossfade, Seconds: a.cfg.Audio.CrossfadeSeconds}
+	}
+	return types.PlaylistTransition{Mode: types.TransitionNormal}
+}
+
 func (a *App) startSync() {
-	if a.state.syncInProgress {
+	a.core.syncManager.Start(a.ctx)
+}
+
+// syncNow syncs the library right away instead of at the next scheduled
+// time.
+func (a *App) syncNow() {
+	if a.core.offline.Offline() {
+		a.updateStatus("Syncing needs the server; turn off offline mode first")
 		return
 	}
-	a.state.syncInProgress = true
-	a.showLoading(true)
-	go a.core.syncManager.Start(a.ctx)
+	if a.core.syncManager.IsSyncing() {
+		a.updateStatus("A sync is already running")
+		return
+	}
+	a.ui.sidebar.SetSyncing(true)
+	a.updateStatus("Syncing library...")
+	a.core.syncManager.SyncNow(a.ctx)
+}
+
+// fullResync refetches the whole library from the server, dropping the
+// delta sync cursors.
+func (a *App) fullResync() {
+	if a.core.offline.Offline() {
+		a.updateStatus("Full resync needs the server; turn off offline mode first")
+		return
+	}
+	if a.core.syncManager.IsSyncing() {
+		a.updateStatus("A sync is already running")
+		return
+	}
+	a.updateStatus("Resyncing the whole library...")
+	go func() {
+		err := a.core.syncManager.FullResync(a.ctx)
+		fyne.Do(func() {
+			if err != nil {
+				log.Printf("[APP] Full resync failed: %v", err)
+				a.updateStatus("Full resync finished with errors")
+				return
+			}
+			a.updateStatus("Library resynced")
+		})
+	}()
 }
 
 func (a *App) logout() {
@@ -544,6 +1035,10 @@ func (a *App) showLoading(show bool) {
 	})
 }
 
+// recentShortcutCount is how many recently played albums and artists the
+// sidebar links to.
+const recentShortcutCount = 3
+
 func (a *App) updateLibraryStats() {
 	go func() {
 		ctx := context.Background()
@@ -558,46 +1053,164 @@ func (a *App) updateLibraryStats() {
 		hours := totalSeconds / 3600
 		minutes := (totalSeconds % 3600) / 60
 		timeListened := fmt.Sprintf("%dh %dm", hours, minutes)
+
+		recent, err := a.core.storage.GetRecentlyPlayed(ctx, recentShortcutCount)
+		if err != nil && a.cfg.Debug {
+			log.Printf("[APP] Failed to load recently played: %v", err)
+		}
+
 		fyne.Do(func() {
 			if a.ui.sidebar != nil {
 				a.ui.sidebar.UpdateStats(len(songs), timeListened)
+				a.ui.sidebar.SetRecentlyPlayed(recent)
 			}
 		})
 	}()
 }
 
 func (a *App) focusSearch() {
+	if a.state.kioskMode {
+		a.kiosk.FocusSearch(a.window.Canvas())
+		return
+	}
 	a.ui.mainView.SearchInCurrentView("")
 }
 
+// SetBuildInfo records the version and commit the binary was built from.
+func (a *App) SetBuildInfo(version, commit string) {
+	a.version = version
+	a.commit = commit
+	a.ui.aboutDialog.SetBuildInfo(version, commit)
+}
+
+func (a *App) showFeedback() {
+	reporter := feedback.NewReporter(a.cfg, a.version, a.commit)
+	reporter.SetResourceReport(a.core.resourceMonitor.Report)
+	components.NewFeedbackDialog(reporter, a.fyneApp).Show(a.window)
+}
+
+// SetUpdater hooks the updater into settings and starts background checks.
+func (a *App) SetUpdater(u *updater.Updater) {
+	a.updater = u
+	a.ui.mainView.SettingsView.SetUpdater(u)
+
+	u.OnUpdateAvailable(func(release *updater.Release) {
+		a.updateStatus(fmt.Sprintf("AMP %s is available - see Settings to update", release.Version))
+		fyne.Do(func() {
+			a.ui.aboutDialog.SetUpdateAvailable(release)
+			a.ui.sidebar.SetUpdateAvailable(true)
+			a.ui.mainView.SettingsView.Refresh()
+		})
+	})
+	u.StartAutoCheck(a.ctx)
+}
+
+// Activate brings the window to the front and opens any file or URL that
+// was passed to a second launch of the application.
+func (a *App) Activate(args []string) {
+	fyne.Do(func() {
+		a.window.Show()
+		a.window.RequestFocus()
+		for _, arg := range args {
+			a.openArgument(arg)
+		}
+	})
+}
+
+func (a *App) openArgument(arg string) {
+	if arg == "" {
+		return
+	}
+
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		path, absErr := filepath.Abs(arg)
+		if absErr != nil {
+			path = arg
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		song := &types.Song{
+			Slug:      types.LocalSlugPrefix + path,
+			Name:      name,
+			File:      path,
+			LocalPath: &path,
+		}
+		a.core.playSyncService.SetPlaySource(types.PlaySource{Type: types.PlaySourceExternal, Name: name})
+		a.playSong(song, []*types.Song{song})
+		a.updateStatus("Playing " + name)
+		return
+	}
+
+	u, err := url.Parse(arg)
+	if err != nil || u.Scheme == "" {
+		log.Printf("[APP] Ignoring unsupported argument: %s", arg)
+		return
+	}
+
+	segments := strings.FieldsFunc(u.Host+"/"+u.Path, func(r rune) bool { return r == '/' })
+	for i := 0; i < len(segments)-1; i++ {
+		slug := segments[i+1]
+		switch segments[i] {
+		case "songs", "song":
+			a.ui.mainView.OpenSongBySlug(slug)
+			return
+		case "albums", "album":
+			a.ui.mainView.OpenAlbumBySlug(slug)
+			return
+		case "authors", "author":
+			a.ui.mainView.OpenAuthorBySlug(slug)
+			return
+		}
+	}
+
+	log.Printf("[APP] No handler for URL argument: %s", arg)
+}
+
 func (a *App) ShowAndRun() {
 	a.window.ShowAndRun()
 }
 
 func (a *App) Close() {
+	if a.ui != nil && a.ui.mainView != nil {
+		a.ui.mainView.UndoBar().Flush()
+	}
+	if a.updater != nil {
+		a.updater.Stop()
+	}
 	if a.core.playSyncService != nil {
 		a.core.playSyncService.Stop()
 	}
+	if a.core.partyServer != nil {
+		a.core.partyServer.Stop()
+	}
+	if a.media != nil {
+		a.media.service.Stop()
+	}
+	if a.hotkeys != nil {
+		a.hotkeys.service.Stop()
+	}
 	if a.core.syncManager != nil {
 		a.core.syncManager.Stop()
 	}
+	if a.core.sleepTimer != nil {
+		a.core.sleepTimer.OnChanged(nil)
+		a.core.sleepTimer.Cancel()
+	}
+	if a.core.cast != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		a.core.cast.Disconnect(ctx)
+		cancel()
+	}
 	if a.core.player != nil {
 		a.core.player.Close()
 	}
+	if a.queue != nil {
+		a.queue.saveNow(a.ui.playerBar.PlaybackQueue())
+	}
 	if a.core.storage != nil {
 		a.core.storage.Close()
 	}
 }
 
 func getArtistNames(authors []*types.Author) string {
-	if len(authors) == 0 {
-		return "Unknown Artist"
-	}
-	names := make([]string, len(authors))
-	for i, author := range authors {
-		if author != nil {
-			names[i] = author.Name
-		}
-	}
-	return strings.Join(names, ", ")
+	return types.CreditsLabel(authors)
 }
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/backup"
+)
+
+// setupBackups creates the backup scheduler; startBackgroundTasks runs it.
+// Demo sessions are never backed up.
+func (a *App) setupBackups() {
+	a.ui.mainView.SettingsView.OnBackupNow(a.backupNow)
+	a.ui.mainView.SettingsView.OnRestoreBackup(a.showRestoreBackup)
+	if a.cfg.Demo {
+		return
+	}
+
+	a.backups = backup.NewScheduler(a.core.storage, a.cfg)
+	a.backups.OnBackup(func(snapshot *backup.Snapshot, err error) {
+		fyne.Do(func() {
+			if err != nil {
+				a.updateStatus("Backup failed: " + err.Error())
+				return
+			}
+			a.updateStatus("Backed up library to " + snapshot.Path)
+		})
+	})
+}
+
+func (a *App) backupNow() {
+	if a.backups == nil {
+		a.updateStatus("Backups are off in demo mode")
+		return
+	}
+
+	a.updateStatus("Backing up library...")
+	go func() {
+		snapshot, err := a.backups.BackupNow(a.ctx)
+		fyne.Do(func() {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("back up: %w", err), a.window)
+				a.updateStatus("Backup failed")
+				return
+			}
+			a.updateStatus("Backed up library to " + snapshot.Path)
+			dialog.ShowInformation("Backup Complete", "Saved "+snapshot.Path, a.window)
+		})
+	}()
+}
+
+// showRestoreBackup offers the snapshots in the backup folder and restores
+// the chosen one, which takes effect after a restart.
+func (a *App) showRestoreBackup() {
+	if a.cfg.Demo {
+		a.updateStatus("Backups are off in demo mode")
+		return
+	}
+
+	snapshots, err := backup.List(a.cfg.Backup.Folder)
+	if err != nil {
+		dialog.ShowError(err, a.window)
+		return
+	}
+	if len(snapshots) == 0 {
+		dialog.ShowInformation("Restore Backup", "There are no backups in "+a.cfg.Backup.Folder+".", a.window)
+		return
+	}
+
+	names := make([]string, len(snapshots))
+	for i, snapshot := range snapshots {
+		names[i] = snapshot.Name()
+	}
+	choice := widget.NewSelect(names, nil)
+	choice.SetSelectedIndex(0)
+
+	warning := widget.NewLabel("Your current library, play history and settings will be replaced by the backup the next time AMP starts.")
+	warning.Wrapping = fyne.TextWrapWord
+
+	confirm := dialog.NewCustomConfirm("Restore Backup", "Restore", "Cancel",
+		widget.NewForm(widget.NewFormItem("Backup", choice), widget.NewFormItem("", warning)),
+		func(ok bool) {
+			index := choice.SelectedIndex()
+			if !ok || index < 0 {
+				return
+			}
+			if err := backup.Restore(snapshots[index], a.cfg); err != nil {
+				dialog.ShowError(err, a.window)
+				return
+			}
+			dialog.ShowInformation("Restore Backup", "Restart AMP to finish restoring the backup from "+
+				snapshots[index].CreatedAt.Format("2006-01-02 15:04")+".", a.window)
+		}, a.window)
+	confirm.Resize(fyne.NewSize(480, 0))
+	confirm.Show()
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/internal/integrations/bluetooth"
+)
+
+// bluetoothCheckInterval is how often the Bluetooth output is looked up. The
+// codec can change at any time, as when a call takes the headset over.
+const bluetoothCheckInterval = 10 * time.Second
+
+// watchBluetoothOutput shows the Bluetooth device audio plays on, its battery
+// and codec in the player bar, until the app closes. It gives up where the
+// system doesn't expose them.
+func (a *App) watchBluetoothOutput() {
+	ticker := time.NewTicker(bluetoothCheckInterval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		device, err := bluetooth.Output()
+		if err != nil {
+			if a.cfg.Debug {
+				log.Printf("[APP] Bluetooth details unavailable: %v", err)
+			}
+			return
+		}
+		label := ""
+		if device != nil {
+			label = device.String()
+		}
+		if label != last {
+			last = label
+			fyne.Do(func() { a.ui.playerBar.SetOutputDevice(label) })
+		}
+
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+)
+
+// setupCacheJanitor wires the cache controls in the settings;
+// startBackgroundTasks runs the janitor.
+func (a *App) setupCacheJanitor() {
+	a.ui.mainView.SettingsView.OnClearCache(a.clearCache)
+	a.core.cacheJanitor.OnSweep(func(freed int64, err error) {
+		if err == nil && freed > 0 {
+			a.updateStatus(fmt.Sprintf("Freed %d MB to keep the cache within its limit", freed/1024/1024))
+		}
+		a.refreshCacheUsage()
+	})
+}
+
+// setupSmartCache reports the nightly smart cache runs;
+// startBackgroundTasks runs the smart cache.
+func (a *App) setupSmartCache() {
+	a.core.smartCache.OnRun(func(result *services.SmartCacheResult, err error) {
+		if err != nil {
+			a.updateStatus("Smart cache failed")
+			return
+		}
+		if result.Queued > 0 || result.Removed > 0 {
+			a.updateStatus(fmt.Sprintf("Smart cache: downloading %d favorites, removed %d stale downloads", result.Queued, result.Removed))
+		}
+		a.refreshCacheUsage()
+		fyne.Do(a.ui.mainView.DownloadsView.Refresh)
+	})
+}
+
+// sweepCache brings the cache back within its limit at once, as after the
+// limit was lowered.
+func (a *App) sweepCache() {
+	if _, err := a.core.cacheJanitor.Sweep(a.ctx); err != nil {
+		log.Printf("[APP] Failed to sweep cache: %v", err)
+	}
+	a.refreshCacheUsage()
+}
+
+// refreshCacheUsage shows the current cache usage in the settings.
+func (a *App) refreshCacheUsage() {
+	usage, err := a.core.cacheJanitor.Usage(a.ctx)
+	if err != nil {
+		log.Printf("[APP] Failed to measure cache: %v", err)
+		return
+	}
+	fyne.Do(func() { a.ui.mainView.SettingsView.SetCacheUsage(usage) })
+}
+
+// clearCache removes the cached images, keeping downloaded songs.
+func (a *App) clearCache() {
+	a.updateStatus("Clearing cache...")
+	go func() {
+		freed, err := a.core.cacheJanitor.Clear(a.ctx)
+		a.refreshCacheUsage()
+		fyne.Do(func() {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("clear cache: %w", err), a.window)
+				a.updateStatus("Failed to clear cache")
+				return
+			}
+			a.updateStatus(fmt.Sprintf("Cleared %d MB from the cache", freed/1024/1024))
+		})
+	}()
+}
@@ -0,0 +1,156 @@
+package components
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/updater"
+)
+
+const unknownValue = "unknown"
+
+// thirdPartyLicenses covers every direct requirement in go.mod.
+// TestThirdPartyLicensesMatchGoMod fails when the two drift apart.
+var thirdPartyLicenses = []struct {
+	name    string
+	license string
+}{
+	{"fyne.io/fyne/v2", "BSD-3-Clause"},
+	{"fyne.io/systray", "Apache-2.0"},
+	{"github.com/charmbracelet/bubbletea", "MIT"},
+	{"github.com/charmbracelet/lipgloss", "MIT"},
+	{"github.com/ebitengine/oto/v3", "Apache-2.0"},
+	{"github.com/fsnotify/fsnotify", "BSD-3-Clause"},
+	{"github.com/godbus/dbus/v5", "BSD-2-Clause"},
+	{"github.com/gopxl/beep", "MIT"},
+	{"github.com/gordonklaus/portaudio", "MIT"},
+	{"github.com/hashicorp/go-retryablehttp", "MPL-2.0"},
+	{"github.com/lithammer/fuzzysearch", "MIT"},
+	{"github.com/mattn/go-runewidth", "MIT"},
+	{"github.com/spf13/viper", "MIT"},
+	{"golang.org/x/image", "BSD-3-Clause"},
+	{"golang.org/x/net", "BSD-3-Clause"},
+	{"golang.org/x/time", "BSD-3-Clause"},
+	{"modernc.org/sqlite", "BSD-3-Clause"},
+}
+
+type AboutDialog struct {
+	version string
+	commit  string
+	release *updater.Release
+
+	onUpdateRequested   func()
+	onFeedbackRequested func()
+}
+
+func NewAboutDialog(version, commit string) *AboutDialog {
+	ad := &AboutDialog{}
+	ad.SetBuildInfo(version, commit)
+	return ad
+}
+
+func (ad *AboutDialog) SetBuildInfo(version, commit string) {
+	if version == "" {
+		version = "dev"
+	}
+	if commit == "" {
+		commit = unknownValue
+	}
+	ad.version = version
+	ad.commit = commit
+}
+
+// SetUpdateAvailable records a pending release so the dialog can announce it.
+func (ad *AboutDialog) SetUpdateAvailable(release *updater.Release) {
+	ad.release = release
+}
+
+func (ad *AboutDialog) OnUpdateRequested(callback func()) {
+	ad.onUpdateRequested = callback
+}
+
+func (ad *AboutDialog) OnFeedbackRequested(callback func()) {
+	ad.onFeedbackRequested = callback
+}
+
+func (ad *AboutDialog) Show(parent fyne.Window) {
+	title := widget.NewLabelWithStyle("AMP - A(dvanced)karpov Music Player", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+
+	info := widget.NewForm(
+		widget.NewFormItem("Version", widget.NewLabel(ad.version)),
+		widget.NewFormItem("Commit", widget.NewLabel(ad.commit)),
+		widget.NewFormItem("Go", widget.NewLabel(runtime.Version())),
+		widget.NewFormItem("Fyne", widget.NewLabel(fyneVersion())),
+		widget.NewFormItem("Platform", widget.NewLabel(runtime.GOOS+"/"+runtime.GOARCH)),
+	)
+
+	content := container.NewVBox(title, widget.NewSeparator(), info)
+
+	var d dialog.Dialog
+
+	if ad.release != nil {
+		notice := widget.NewLabelWithStyle(
+			fmt.Sprintf("Update available: %s", ad.release.Version),
+			fyne.TextAlignLeading, fyne.TextStyle{Bold: true},
+		)
+		row := container.NewHBox(widget.NewIcon(theme.DownloadIcon()), notice)
+		if ad.onUpdateRequested != nil {
+			row.Add(widget.NewButton("Update...", func() {
+				d.Hide()
+				ad.onUpdateRequested()
+			}))
+		}
+		content.Add(widget.NewSeparator())
+		content.Add(row)
+
+		if ad.release.Notes != "" {
+			notes := widget.NewRichTextFromMarkdown(ad.release.Notes)
+			notes.Wrapping = fyne.TextWrapWord
+			scroll := container.NewVScroll(notes)
+			scroll.SetMinSize(fyne.NewSize(0, 120))
+			content.Add(widget.NewAccordion(widget.NewAccordionItem("What's new", scroll)))
+		}
+	}
+
+	licenses := container.NewVBox()
+	for _, dep := range thirdPartyLicenses {
+		licenses.Add(widget.NewLabel(fmt.Sprintf("%s - %s", dep.name, dep.license)))
+	}
+	content.Add(widget.NewSeparator())
+	content.Add(widget.NewAccordion(widget.NewAccordionItem("Open source licenses", licenses)))
+
+	if ad.onFeedbackRequested != nil {
+		content.Add(widget.NewSeparator())
+		content.Add(container.NewHBox(widget.NewButtonWithIcon("Send Feedback", theme.MailComposeIcon(), func() {
+			d.Hide()
+			ad.onFeedbackRequested()
+		})))
+	}
+
+	d = dialog.NewCustom("About AMP", "Close", container.NewVScroll(content), parent)
+	d.Resize(fyne.NewSize(480, 460))
+	d.Show()
+}
+
+func fyneVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return unknownValue
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "fyne.io/fyne/v2" {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return unknownValue
+}
@@ -0,0 +1,66 @@
+package components
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+// directRequires returns the module paths go.mod requires directly.
+func directRequires(t *testing.T, path string) map[string]bool {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open go.mod: %v", err)
+	}
+	defer f.Close()
+
+	modules := make(map[string]bool)
+	inBlock := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "require (":
+			inBlock = true
+			continue
+		case inBlock && line == ")":
+			inBlock = false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimPrefix(line, "require ")
+		case !inBlock:
+			continue
+		}
+		if line == "" || strings.HasSuffix(line, "// indirect") {
+			continue
+		}
+		modules[strings.Fields(line)[0]] = true
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read go.mod: %v", err)
+	}
+	return modules
+}
+
+func TestThirdPartyLicensesMatchGoMod(t *testing.T) {
+	required := directRequires(t, "../../../go.mod")
+
+	listed := make(map[string]bool)
+	for _, dep := range thirdPartyLicenses {
+		if dep.license == "" {
+			t.Errorf("%s has no license", dep.name)
+		}
+		listed[dep.name] = true
+		if !required[dep.name] {
+			t.Errorf("%s is listed but not a direct requirement in go.mod", dep.name)
+		}
+	}
+	for name := range required {
+		if !listed[name] {
+			t.Errorf("%s is required in go.mod but missing from thirdPartyLicenses", name)
+		}
+	}
+}
@@ -15,7 +15,7 @@ import (
 )
 
 type AuthDialog struct {
-	api             *api.Client
+	api             api.MusicBackend
 	onAuthenticated func(string)
 	parentWindow    fyne.Window
 
@@ -28,7 +28,7 @@ type AuthDialog struct {
 	helpLink    *widget.Hyperlink
 }
 
-func NewAuthDialog(api *api.Client) *AuthDialog {
+func NewAuthDialog(api api.MusicBackend) *AuthDialog {
 	return &AuthDialog{
 		api: api,
 	}
@@ -67,6 +67,13 @@ func (ad *AuthDialog) setupWidgets() {
 
 	helpURL, _ := url.Parse("https://new.akarpov.ru/users/tokens/create/?name=MusicToken&permissions=music.listen&permissions=music.upload&permissions=music.playlist")
 	ad.helpLink = widget.NewHyperlink("Get your API token here", helpURL)
+
+	// Subsonic servers log in with the configured user's password.
+	if _, ok := ad.api.(*api.SubsonicClient); ok {
+		ad.tokenEntry.SetPlaceHolder("Enter your password")
+		ad.statusLabel.SetText("Enter your Subsonic password to authenticate")
+		ad.helpLink.Hide()
+	}
 }
 
 func (ad *AuthDialog) createDialog(parent fyne.Window) {
@@ -0,0 +1,115 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ChartBar is one bar of a BarChart. Label is shown under it and may be
+// empty to keep a crowded chart readable.
+type ChartBar struct {
+	Label string
+	Value float64
+}
+
+// BarChart draws bars side by side, scaled so the tallest fills the chart.
+type BarChart struct {
+	widget.BaseWidget
+	bars   []ChartBar
+	height float32
+}
+
+func NewBarChart(height float32) *BarChart {
+	c := &BarChart{height: height}
+	c.ExtendBaseWidget(c)
+	return c
+}
+
+func (c *BarChart) SetBars(bars []ChartBar) {
+	c.bars = bars
+	c.Refresh()
+}
+
+func (c *BarChart) MinSize() fyne.Size {
+	return fyne.NewSize(float32(len(c.bars))*4, c.height)
+}
+
+type barChartRenderer struct {
+	chart   *BarChart
+	bars    []*canvas.Rectangle
+	labels  []*canvas.Text
+	objects []fyne.CanvasObject
+}
+
+func (c *BarChart) CreateRenderer() fyne.WidgetRenderer {
+	r := &barChartRenderer{chart: c}
+	r.Refresh()
+	return r
+}
+
+func (r *barChartRenderer) Layout(size fyne.Size) {
+	n := len(r.bars)
+	if n == 0 {
+		return
+	}
+
+	labelHeight := theme.CaptionTextSize() + theme.Padding()
+	area := size.Height - labelHeight
+	slot := size.Width / float32(n)
+	gap := min(slot*0.2, theme.Padding())
+
+	peak := 0.0
+	for _, bar := range r.chart.bars {
+		peak = max(peak, bar.Value)
+	}
+
+	for i, bar := range r.bars {
+		height := float32(0)
+		if peak > 0 {
+			height = area * float32(r.chart.bars[i].Value/peak)
+		}
+		// Days with a little listening still show.
+		if r.chart.bars[i].Value > 0 {
+			height = max(height, 2)
+		}
+		x := slot * float32(i)
+		bar.Move(fyne.NewPos(x+gap/2, area-height))
+		bar.Resize(fyne.NewSize(slot-gap, height))
+
+		label := r.labels[i]
+		label.Move(fyne.NewPos(x+slot/2-label.MinSize().Width/2, area+theme.Padding()/2))
+		label.Resize(label.MinSize())
+	}
+}
+
+func (r *barChartRenderer) MinSize() fyne.Size { return r.chart.MinSize() }
+
+func (r *barChartRenderer) Refresh() {
+	bars := r.chart.bars
+	if len(r.bars) != len(bars) {
+		r.bars = make([]*canvas.Rectangle, len(bars))
+		r.labels = make([]*canvas.Text, len(bars))
+		r.objects = make([]fyne.CanvasObject, 0, 2*len(bars))
+		for i := range bars {
+			r.bars[i] = canvas.NewRectangle(nil)
+			r.labels[i] = canvas.NewText("", nil)
+			r.labels[i].TextSize = theme.CaptionTextSize()
+			r.objects = append(r.objects, r.bars[i], r.labels[i])
+		}
+	}
+
+	for i, bar := range bars {
+		r.bars[i].FillColor = theme.Color(theme.ColorNamePrimary)
+		r.bars[i].CornerRadius = theme.InputRadiusSize()
+		r.bars[i].Refresh()
+		r.labels[i].Text = bar.Label
+		r.labels[i].Color = theme.Color(theme.ColorNamePlaceHolder)
+		r.labels[i].Refresh()
+	}
+	r.Layout(r.chart.Size())
+}
+
+func (r *barChartRenderer) Objects() []fyne.CanvasObject { return r.objects }
+func (r *barChartRenderer) Destroy()                     {}
@@ -0,0 +1,71 @@
+package components
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2/dialog"
+)
+
+// What happens once playback ran for the set time without a break.
+const (
+	BreakActionRemind = "remind"
+	BreakActionPause  = "pause"
+)
+
+// breakGap is how long playback has to stop for to count as a break, which
+// starts the count of continuous listening over.
+const breakGap = 5 * time.Minute
+
+// checkBreak adds the time since the last position update to the
+// continuous listening, and reminds the listener to take a break, or
+// pauses, once it reaches the set limit. It is called on every position
+// update.
+func (pb *PlayerBar) checkBreak() {
+	now := time.Now()
+	last := pb.lastListenTick
+	pb.lastListenTick = now
+
+	if pb.cfg == nil || !pb.cfg.Wellbeing.BreakReminder || pb.cfg.Wellbeing.BreakMinutes <= 0 || !pb.isPlaying {
+		return
+	}
+	if last.IsZero() || now.Sub(last) >= breakGap {
+		pb.listened = 0
+		return
+	}
+	pb.listened += now.Sub(last)
+
+	limit := time.Duration(pb.cfg.Wellbeing.BreakMinutes) * time.Minute
+	if pb.listened < limit {
+		return
+	}
+	pb.listened = 0
+
+	if pb.debug {
+		log.Printf("[PLAYER_BAR] Listened for %v, suggesting a break (%s)", limit, pb.cfg.Wellbeing.BreakAction)
+	}
+	message := fmt.Sprintf("You have been listening for %s. How about a short break?", formatListened(limit))
+	if pb.cfg.Wellbeing.BreakAction == BreakActionPause {
+		pb.Pause()
+		message = fmt.Sprintf("You have been listening for %s, so playback is paused for a short break.", formatListened(limit))
+	}
+	if pb.parentWindow != nil {
+		dialog.ShowInformation("Time for a Break", message, pb.parentWindow)
+	}
+}
+
+// formatListened renders a listening time as "45 minutes" or "1h 30m".
+func formatListened(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	}
+	hours, minutes := int(d.Hours()), int(d.Minutes())%60
+	if minutes == 0 {
+		if hours == 1 {
+			return "an hour"
+		}
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
@@ -7,6 +7,7 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/Alexander-D-Karpov/amp/internal/ui/themes"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
@@ -16,9 +17,13 @@ type ContextMenu struct {
 	canvas fyne.Canvas
 
 	onPlay        func(*types.Song)
+	onPlayNext    func(*types.Song)
+	onAddToQueue  func(*types.Song)
+	onCue         func(*types.Song)
 	onLike        func(*types.Song)
 	onDownload    func(*types.Song)
 	onAddPlaylist func(*types.Song)
+	onRemove      func(*types.Song)
 	debug         bool
 }
 
@@ -54,6 +59,42 @@ func (cm *ContextMenu) createMenu(canvas fyne.Canvas) {
 	playItem.Icon = theme.MediaPlayIcon()
 	menuItems = append(menuItems, playItem)
 
+	if cm.onPlayNext != nil {
+		playNextItem := fyne.NewMenuItem("Play Next", func() {
+			if cm.debug {
+				log.Printf("[CONTEXT_MENU] Play next requested for: %s", cm.song.Name)
+			}
+			cm.onPlayNext(cm.song)
+			cm.Hide()
+		})
+		playNextItem.Icon = theme.MediaSkipNextIcon()
+		menuItems = append(menuItems, playNextItem)
+	}
+
+	if cm.onAddToQueue != nil {
+		queueItem := fyne.NewMenuItem("Add to Queue", func() {
+			if cm.debug {
+				log.Printf("[CONTEXT_MENU] Add to queue requested for: %s", cm.song.Name)
+			}
+			cm.onAddToQueue(cm.song)
+			cm.Hide()
+		})
+		queueItem.Icon = theme.ListIcon()
+		menuItems = append(menuItems, queueItem)
+	}
+
+	if cm.onCue != nil {
+		cueItem := fyne.NewMenuItem("Pre-listen", func() {
+			if cm.debug {
+				log.Printf("[CONTEXT_MENU] Pre-listen requested for: %s", cm.song.Name)
+			}
+			cm.onCue(cm.song)
+			cm.Hide()
+		})
+		cueItem.Icon = theme.VolumeUpIcon()
+		menuItems = append(menuItems, cueItem)
+	}
+
 	// Separator
 	menuItems = append(menuItems, fyne.NewMenuItemSeparator())
 
@@ -69,7 +110,7 @@ func (cm *ContextMenu) createMenu(canvas fyne.Canvas) {
 			}
 			cm.Hide()
 		})
-		likeItem.Icon = theme.ConfirmIcon()
+		likeItem.Icon = themes.LikeIcon(true)
 	} else {
 		likeItem = fyne.NewMenuItem("Like", func() {
 			if cm.debug {
@@ -80,7 +121,7 @@ func (cm *ContextMenu) createMenu(canvas fyne.Canvas) {
 			}
 			cm.Hide()
 		})
-		likeItem.Icon = theme.VisibilityOffIcon()
+		likeItem.Icon = themes.LikeIcon(false)
 	}
 	menuItems = append(menuItems, likeItem)
 
@@ -117,6 +158,18 @@ func (cm *ContextMenu) createMenu(canvas fyne.Canvas) {
 	playlistItem.Icon = theme.ContentAddIcon()
 	menuItems = append(menuItems, playlistItem)
 
+	if cm.onRemove != nil {
+		removeItem := fyne.NewMenuItem("Remove from Library", func() {
+			if cm.debug {
+				log.Printf("[CONTEXT_MENU] Remove requested for: %s", cm.song.Name)
+			}
+			cm.onRemove(cm.song)
+			cm.Hide()
+		})
+		removeItem.Icon = theme.DeleteIcon()
+		menuItems = append(menuItems, fyne.NewMenuItemSeparator(), removeItem)
+	}
+
 	// Create the menu with proper canvas
 	menu := fyne.NewMenu("", menuItems...)
 	cm.menu = widget.NewPopUpMenu(menu, canvas)
@@ -153,6 +206,22 @@ func (cm *ContextMenu) SetCallbacks(
 	cm.onAddPlaylist = onAddPlaylist
 }
 
+// SetQueueCallbacks enables the "Play Next" and "Add to Queue" entries.
+func (cm *ContextMenu) SetQueueCallbacks(onPlayNext, onAddToQueue func(*types.Song)) {
+	cm.onPlayNext = onPlayNext
+	cm.onAddToQueue = onAddToQueue
+}
+
+// SetOnCue enables the "Pre-listen" entry.
+func (cm *ContextMenu) SetOnCue(onCue func(*types.Song)) {
+	cm.onCue = onCue
+}
+
+// SetOnRemove enables the "Remove from Library" entry.
+func (cm *ContextMenu) SetOnRemove(onRemove func(*types.Song)) {
+	cm.onRemove = onRemove
+}
+
 func (cm *ContextMenu) Update(song *types.Song) {
 	cm.song = song
 	// Don't recreate menu here, let ShowAt handle it with proper canvas
@@ -0,0 +1,66 @@
+package components
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio"
+)
+
+// showEqualizerMenu pops up the presets under the EQ button, along with
+// whether the preset follows the output device or the song's genre, and a
+// way to the band sliders.
+func (pb *PlayerBar) showEqualizerMenu() {
+	if pb.parentWindow == nil {
+		return
+	}
+	state := pb.player.EqualizerState()
+
+	choose := func(preset string) func() {
+		return func() {
+			pb.player.SetEqualizerPreset(preset)
+			pb.updateEqualizerButton()
+		}
+	}
+
+	offItem := fyne.NewMenuItem("Off", choose(""))
+	offItem.Checked = !state.Enabled
+	items := []*fyne.MenuItem{offItem, fyne.NewMenuItemSeparator()}
+	for _, preset := range audio.EQPresetNames() {
+		item := fyne.NewMenuItem(audio.EQPresetLabel(preset), choose(preset))
+		item.Checked = state.Enabled && state.Preset == preset
+		items = append(items, item)
+	}
+
+	device := state.Device
+	if device == "" {
+		device = "this device"
+	}
+	deviceItem := fyne.NewMenuItem(fmt.Sprintf("Use for %s", device), func() {
+		pb.player.RememberEqualizerForDevice(!state.DeviceRemembered)
+	})
+	deviceItem.Checked = state.DeviceRemembered
+	deviceItem.Disabled = !state.Enabled
+	items = append(items, fyne.NewMenuItemSeparator(), deviceItem)
+
+	if state.Genre != "" {
+		genreItem := fyne.NewMenuItem(fmt.Sprintf("Use for %s", state.Genre), func() {
+			pb.player.RememberEqualizerForGenre(!state.GenreRemembered)
+		})
+		genreItem.Checked = state.GenreRemembered
+		genreItem.Disabled = !state.Enabled
+		items = append(items, genreItem)
+	}
+
+	items = append(items, fyne.NewMenuItemSeparator(), fyne.NewMenuItem("Adjust Bands…", pb.showEqualizerPanel))
+
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(pb.eqBtn)
+	pos = pos.AddXY(0, pb.eqBtn.Size().Height)
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), pb.parentWindow.Canvas(), pos)
+}
+
+func (pb *PlayerBar) updateEqualizerButton() {
+	setToggled(pb.eqBtn, pb.player.EqualizerState().Enabled)
+}
@@ -0,0 +1,135 @@
+package components
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio"
+)
+
+// equalizerPanel adjusts the ten bands of the equalizer. Moving a band turns
+// the preset into Custom.
+type equalizerPanel struct {
+	pb      *PlayerBar
+	enabled *widget.Check
+	presets *widget.Select
+	sliders []*widget.Slider
+	gains   []*widget.Label
+
+	// updating is set while the widgets are filled in from the player, so
+	// their callbacks don't write back.
+	updating bool
+}
+
+// showEqualizerPanel opens the band sliders in a dialog.
+func (pb *PlayerBar) showEqualizerPanel() {
+	if pb.parentWindow == nil {
+		return
+	}
+
+	panel := &equalizerPanel{pb: pb}
+	panel.enabled = widget.NewCheck("Enabled", panel.onEnabled)
+
+	labels := make([]string, 0, len(audio.EQPresetNames()))
+	for _, preset := range audio.EQPresetNames() {
+		labels = append(labels, audio.EQPresetLabel(preset))
+	}
+	panel.presets = widget.NewSelect(labels, panel.onPreset)
+
+	bands := make([]fyne.CanvasObject, len(audio.EQBands))
+	for i, freq := range audio.EQBands {
+		slider := widget.NewSlider(-audio.EQMaxGain, audio.EQMaxGain)
+		slider.Orientation = widget.Vertical
+		slider.Step = 1
+		slider.OnChanged = func(value float64) {
+			panel.gains[i].SetText(formatGain(value))
+		}
+		slider.OnChangeEnded = func(float64) { panel.onBandChanged() }
+
+		gain := widget.NewLabel("")
+		gain.Alignment = fyne.TextAlignCenter
+		band := widget.NewLabel(formatBand(freq))
+		band.Alignment = fyne.TextAlignCenter
+
+		panel.sliders = append(panel.sliders, slider)
+		panel.gains = append(panel.gains, gain)
+		bands[i] = container.NewBorder(gain, band, nil, nil, slider)
+	}
+
+	top := container.NewBorder(nil, nil, panel.enabled, nil, panel.presets)
+	content := container.NewBorder(top, nil, nil, nil, container.NewGridWithColumns(len(bands), bands...))
+	panel.load()
+
+	d := dialog.NewCustom("Equalizer", "Close", content, pb.parentWindow)
+	d.Resize(fyne.NewSize(640, 380))
+	d.Show()
+}
+
+// load fills the widgets in from the player.
+func (ep *equalizerPanel) load() {
+	ep.updating = true
+	defer func() { ep.updating = false }()
+
+	state := ep.pb.player.EqualizerState()
+	ep.enabled.SetChecked(state.Enabled)
+	ep.presets.SetSelected(audio.EQPresetLabel(state.Preset))
+	for i, gain := range ep.pb.player.EqualizerGains() {
+		ep.sliders[i].SetValue(gain)
+		ep.gains[i].SetText(formatGain(gain))
+	}
+}
+
+func (ep *equalizerPanel) onEnabled(enabled bool) {
+	if ep.updating {
+		return
+	}
+	preset := ""
+	if enabled {
+		preset = ep.pb.player.EqualizerState().Preset
+	}
+	ep.pb.player.SetEqualizerPreset(preset)
+	ep.pb.updateEqualizerButton()
+}
+
+func (ep *equalizerPanel) onPreset(label string) {
+	if ep.updating {
+		return
+	}
+	for _, preset := range audio.EQPresetNames() {
+		if audio.EQPresetLabel(preset) == label {
+			ep.pb.player.SetEqualizerPreset(preset)
+			break
+		}
+	}
+	ep.pb.updateEqualizerButton()
+	ep.load()
+}
+
+func (ep *equalizerPanel) onBandChanged() {
+	if ep.updating {
+		return
+	}
+	gains := make([]float64, len(ep.sliders))
+	for i, slider := range ep.sliders {
+		gains[i] = slider.Value
+	}
+	ep.pb.player.SetEqualizerGains(gains)
+	ep.pb.updateEqualizerButton()
+	ep.load()
+}
+
+// formatBand renders a band's frequency as "125" or "2k".
+func formatBand(freq float64) string {
+	if freq >= 1000 {
+		return fmt.Sprintf("%gk", freq/1000)
+	}
+	return fmt.Sprintf("%g", freq)
+}
+
+func formatGain(gain float64) string {
+	return fmt.Sprintf("%+.0f", gain)
+}
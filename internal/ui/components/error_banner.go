@@ -0,0 +1,109 @@
+package components
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+)
+
+// ErrorBanner reports a failed load at the top of a view and offers to try
+// it again. Unlike a status label it stays until it is dismissed, retried,
+// or cleared by a load that worked.
+type ErrorBanner struct {
+	container *fyne.Container
+	label     *widget.Label
+	hint      *widget.Label
+	retryBtn  *widget.Button
+
+	retry func()
+}
+
+func NewErrorBanner() *ErrorBanner {
+	b := &ErrorBanner{
+		label: widget.NewLabel(""),
+		hint:  widget.NewLabel(""),
+	}
+	b.label.Importance = widget.DangerImportance
+	b.label.Truncation = fyne.TextTruncateEllipsis
+	b.hint.Importance = widget.LowImportance
+	b.hint.Wrapping = fyne.TextWrapWord
+
+	b.retryBtn = widget.NewButtonWithIcon("Retry", theme.ViewRefreshIcon(), b.runRetry)
+	b.retryBtn.Importance = widget.HighImportance
+	closeBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), b.Hide)
+	closeBtn.Importance = widget.LowImportance
+
+	b.container = container.NewBorder(nil, nil,
+		widget.NewIcon(theme.ErrorIcon()),
+		container.NewHBox(b.retryBtn, closeBtn),
+		container.NewVBox(b.label, b.hint))
+	b.container.Hide()
+	return b
+}
+
+// Show reports err under message. retry is called when Retry is pressed;
+// without one the button is left out. When err says the server can't be
+// reached, a hint explains what still works offline.
+func (b *ErrorBanner) Show(message string, err error, retry func()) {
+	b.retry = retry
+	text := message
+	if err != nil {
+		text += ": " + err.Error()
+	}
+	b.label.SetText(text)
+
+	if hint := offlineHint(err); hint != "" {
+		b.hint.SetText(hint)
+		b.hint.Show()
+	} else {
+		b.hint.Hide()
+	}
+	if retry != nil {
+		b.retryBtn.Show()
+	} else {
+		b.retryBtn.Hide()
+	}
+	b.container.Show()
+}
+
+// Hide dismisses the banner.
+func (b *ErrorBanner) Hide() {
+	b.retry = nil
+	b.container.Hide()
+}
+
+// Container returns the banner, hidden until Show is called.
+func (b *ErrorBanner) Container() fyne.CanvasObject {
+	return b.container
+}
+
+func (b *ErrorBanner) runRetry() {
+	retry := b.retry
+	b.Hide()
+	if retry != nil {
+		retry()
+	}
+}
+
+// offlineHint explains what err means for browsing when it comes from being
+// offline or not reaching the server, and returns "" otherwise.
+func offlineHint(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, api.ErrOffline) {
+		return "Offline mode is on, so only downloaded music is listed. Turn it off in the sidebar to load the rest."
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return "The server can't be reached. Check your connection, or turn on Offline mode in the sidebar to browse your downloads."
+	}
+	return ""
+}
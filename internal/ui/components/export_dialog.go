@@ -0,0 +1,117 @@
+package components
+
+import (
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/exporter"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// ExportDialog previews a list of songs as a Markdown or HTML snippet and
+// copies it to the clipboard or saves it to a file.
+type ExportDialog struct {
+	title string
+	songs []*types.Song
+
+	dialog      dialog.Dialog
+	formatRadio *widget.RadioGroup
+	preview     *widget.Entry
+	statusLabel *widget.Label
+}
+
+func NewExportDialog(title string, songs []*types.Song) *ExportDialog {
+	return &ExportDialog{title: title, songs: songs}
+}
+
+func (ed *ExportDialog) Show(parent fyne.Window) {
+	ed.preview = widget.NewMultiLineEntry()
+	ed.preview.Wrapping = fyne.TextWrapOff
+	ed.preview.SetMinRowsVisible(12)
+
+	ed.statusLabel = widget.NewLabel("")
+	ed.statusLabel.Importance = widget.LowImportance
+
+	ed.formatRadio = widget.NewRadioGroup(exporter.Formats, func(string) { ed.render() })
+	ed.formatRadio.Horizontal = true
+	ed.formatRadio.Required = true
+	ed.formatRadio.SetSelected(exporter.FormatMarkdown)
+
+	copyBtn := widget.NewButtonWithIcon("Copy", theme.ContentCopyIcon(), func() {
+		fyne.CurrentApp().Clipboard().SetContent(ed.preview.Text)
+		ed.statusLabel.SetText("Copied to the clipboard.")
+	})
+	copyBtn.Importance = widget.HighImportance
+	saveBtn := widget.NewButtonWithIcon("Save…", theme.DocumentSaveIcon(), func() {
+		ed.save(parent)
+	})
+	closeBtn := widget.NewButtonWithIcon("Close", theme.CancelIcon(), func() {
+		ed.dialog.Hide()
+	})
+
+	content := container.NewBorder(
+		container.NewHBox(widget.NewLabel("Format:"), ed.formatRadio),
+		container.NewBorder(nil, nil, ed.statusLabel, container.NewHBox(closeBtn, saveBtn, copyBtn)),
+		nil, nil,
+		ed.preview,
+	)
+
+	ed.dialog = dialog.NewCustomWithoutButtons("Export Snippet", content, parent)
+	ed.dialog.Resize(fyne.NewSize(640, 480))
+	ed.dialog.Show()
+}
+
+// render fills the preview with the snippet in the selected format. Edits
+// made in the preview are kept until the format changes.
+func (ed *ExportDialog) render() {
+	text, err := exporter.Render(ed.formatRadio.Selected, ed.title, ed.songs)
+	if err != nil {
+		ed.statusLabel.SetText("Failed to export: " + err.Error())
+		return
+	}
+	ed.preview.SetText(text)
+	ed.statusLabel.SetText("")
+}
+
+func (ed *ExportDialog) save(parent fyne.Window) {
+	text := ed.preview.Text
+	saver := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil || writer == nil {
+			return
+		}
+		defer func() {
+			if closeErr := writer.Close(); closeErr != nil {
+				log.Printf("Failed to close file writer: %v", closeErr)
+			}
+		}()
+
+		if _, err := writer.Write([]byte(text)); err != nil {
+			dialog.ShowError(err, parent)
+			return
+		}
+		ed.statusLabel.SetText("Saved to " + writer.URI().Name() + ".")
+	}, parent)
+	saver.SetFileName(exportFileName(ed.title) + exporter.Extension(ed.formatRadio.Selected))
+	saver.Show()
+}
+
+// exportFileName turns a title into a file name without characters file
+// systems reject.
+func exportFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if name == "" {
+		return "songs"
+	}
+	return name
+}
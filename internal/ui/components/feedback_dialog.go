@@ -0,0 +1,198 @@
+package components
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/feedback"
+	"github.com/Alexander-D-Karpov/amp/internal/platform"
+)
+
+type FeedbackDialog struct {
+	reporter *feedback.Reporter
+	fyneApp  fyne.App
+
+	dialog            dialog.Dialog
+	descriptionEntry  *widget.Entry
+	screenshotCheck   *widget.Check
+	diagnosticsCheck  *widget.Check
+	diagnosticsOutput *widget.Entry
+	statusLabel       *widget.Label
+	sendBtn           *widget.Button
+}
+
+func NewFeedbackDialog(reporter *feedback.Reporter, fyneApp fyne.App) *FeedbackDialog {
+	return &FeedbackDialog{
+		reporter: reporter,
+		fyneApp:  fyneApp,
+	}
+}
+
+func (fd *FeedbackDialog) Show(parent fyne.Window) {
+	// Capture before the dialog covers the window.
+	screenshot := fd.captureScreenshot(parent)
+
+	fd.descriptionEntry = widget.NewMultiLineEntry()
+	fd.descriptionEntry.SetPlaceHolder("What happened? What did you expect to happen?")
+	fd.descriptionEntry.Wrapping = fyne.TextWrapWord
+	fd.descriptionEntry.SetMinRowsVisible(6)
+
+	fd.screenshotCheck = widget.NewCheck("Include screenshot of the window", nil)
+	fd.screenshotCheck.SetChecked(screenshot != nil)
+	if screenshot == nil {
+		fd.screenshotCheck.Disable()
+	}
+
+	diagnostics := fd.reporter.Diagnostics()
+	fd.diagnosticsOutput = widget.NewMultiLineEntry()
+	fd.diagnosticsOutput.SetText(diagnostics)
+	fd.diagnosticsOutput.Disable()
+	fd.diagnosticsOutput.SetMinRowsVisible(6)
+
+	fd.diagnosticsCheck = widget.NewCheck("Include diagnostics (tokens and account details are removed)", nil)
+	fd.diagnosticsCheck.SetChecked(true)
+
+	fd.statusLabel = widget.NewLabel("")
+	fd.statusLabel.Wrapping = fyne.TextWrapWord
+
+	sendText := "Open Issue"
+	if fd.reporter.HasEndpoint() {
+		sendText = "Send"
+	}
+	fd.sendBtn = widget.NewButtonWithIcon(sendText, theme.MailSendIcon(), func() {
+		fd.send(parent, screenshot)
+	})
+	fd.sendBtn.Importance = widget.HighImportance
+
+	cancelBtn := widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), func() {
+		fd.dialog.Hide()
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel("Description:"),
+		fd.descriptionEntry,
+		fd.screenshotCheck,
+		fd.diagnosticsCheck,
+		widget.NewAccordion(widget.NewAccordionItem("Diagnostics preview", fd.diagnosticsOutput)),
+		fd.statusLabel,
+		container.NewHBox(cancelBtn, fd.sendBtn),
+	)
+
+	fd.dialog = dialog.NewCustomWithoutButtons("Send Feedback", container.NewVScroll(content), parent)
+	fd.dialog.Resize(fyne.NewSize(520, 520))
+	fd.dialog.Show()
+}
+
+func (fd *FeedbackDialog) captureScreenshot(parent fyne.Window) []byte {
+	if parent == nil || parent.Canvas() == nil {
+		return nil
+	}
+	img := parent.Canvas().Capture()
+	if img == nil {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		log.Printf("[FEEDBACK] Failed to encode screenshot: %v", err)
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func (fd *FeedbackDialog) send(parent fyne.Window, screenshot []byte) {
+	description := strings.TrimSpace(fd.descriptionEntry.Text)
+	if description == "" {
+		fd.statusLabel.SetText("Please describe the problem or suggestion first.")
+		return
+	}
+
+	report := &feedback.Report{
+		Description: fd.reporter.Redact(description),
+	}
+	if fd.diagnosticsCheck.Checked {
+		report.Diagnostics = fd.diagnosticsOutput.Text
+	}
+	if fd.screenshotCheck.Checked {
+		report.Screenshot = screenshot
+	}
+
+	if !fd.reporter.HasEndpoint() {
+		fd.openIssue(parent, report)
+		return
+	}
+
+	fd.sendBtn.Disable()
+	fd.statusLabel.SetText("Sending feedback...")
+
+	go func() {
+		err := fd.reporter.Submit(context.Background(), report)
+		fyne.Do(func() {
+			fd.sendBtn.Enable()
+			if err != nil {
+				fd.statusLabel.SetText("Failed to send: " + err.Error())
+				return
+			}
+			fd.dialog.Hide()
+			dialog.ShowInformation("Thank You", "Your feedback has been sent.", parent)
+		})
+	}()
+}
+
+func (fd *FeedbackDialog) openIssue(parent fyne.Window, report *feedback.Report) {
+	savedPath := ""
+	if len(report.Screenshot) > 0 {
+		path, err := saveScreenshot(report.Screenshot)
+		if err != nil {
+			log.Printf("[FEEDBACK] Failed to save screenshot: %v", err)
+			report.Screenshot = nil
+		} else {
+			savedPath = path
+		}
+	}
+
+	issueURL, err := fd.reporter.IssueURL(report)
+	if err != nil {
+		fd.statusLabel.SetText("Failed to build issue link: " + err.Error())
+		return
+	}
+
+	if err := fd.fyneApp.OpenURL(issueURL); err != nil {
+		fd.statusLabel.SetText("Failed to open browser: " + err.Error())
+		return
+	}
+
+	fd.dialog.Hide()
+	if savedPath != "" {
+		dialog.ShowInformation("Attach Screenshot",
+			fmt.Sprintf("The screenshot was saved to:\n%s\n\nPlease attach it to the issue.", savedPath), parent)
+	}
+}
+
+func saveScreenshot(data []byte) (string, error) {
+	dataDir, err := platform.GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(dataDir, "feedback")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("screenshot-%s.png", time.Now().Format("20060102-150405")))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,7 @@
+//go:build !windows && !(linux && cgo && !wayland)
+
+package components
+
+// keepAbove does nothing where there is no way to keep a window above the
+// others; the mini-player is then an ordinary window.
+func keepAbove(context any) {}
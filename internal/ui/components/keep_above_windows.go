@@ -0,0 +1,28 @@
+//go:build windows
+
+package components
+
+import (
+	"syscall"
+
+	"fyne.io/fyne/v2/driver"
+)
+
+var setWindowPos = syscall.NewLazyDLL("user32.dll").NewProc("SetWindowPos")
+
+const (
+	hwndTopmost  = ^uintptr(0) // HWND_TOPMOST, (HWND)-1
+	swpNoSize    = 0x0001
+	swpNoMove    = 0x0002
+	swpNoActive  = 0x0010
+	swpKeepAbove = swpNoSize | swpNoMove | swpNoActive
+)
+
+// keepAbove makes the window topmost, so it stays above other apps.
+func keepAbove(context any) {
+	window, ok := context.(driver.WindowsWindowContext)
+	if !ok || window.HWND == 0 {
+		return
+	}
+	setWindowPos.Call(window.HWND, hwndTopmost, 0, 0, 0, 0, swpKeepAbove)
+}
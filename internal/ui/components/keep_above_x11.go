@@ -0,0 +1,44 @@
+//go:build linux && cgo && !wayland
+
+package components
+
+/*
+#cgo LDFLAGS: -lX11
+#include <X11/Xlib.h>
+#include <string.h>
+
+// keep_above asks the window manager to add _NET_WM_STATE_ABOVE to the
+// window, as EWMH has clients do for mapped windows.
+static void keep_above(Window window) {
+	Display *display = XOpenDisplay(NULL);
+	if (display == NULL) {
+		return;
+	}
+	XEvent event;
+	memset(&event, 0, sizeof(event));
+	event.xclient.type = ClientMessage;
+	event.xclient.window = window;
+	event.xclient.message_type = XInternAtom(display, "_NET_WM_STATE", False);
+	event.xclient.format = 32;
+	event.xclient.data.l[0] = 1; // _NET_WM_STATE_ADD
+	event.xclient.data.l[1] = XInternAtom(display, "_NET_WM_STATE_ABOVE", False);
+	event.xclient.data.l[3] = 1; // sent by a normal application
+	XSendEvent(display, DefaultRootWindow(display), False,
+		SubstructureRedirectMask | SubstructureNotifyMask, &event);
+	XFlush(display);
+	XCloseDisplay(display);
+}
+*/
+import "C"
+
+import "fyne.io/fyne/v2/driver"
+
+// keepAbove asks the X11 window manager to keep the window above the
+// others. Wayland has no way for a client to ask this.
+func keepAbove(context any) {
+	window, ok := context.(driver.X11WindowContext)
+	if !ok || window.WindowHandle == 0 {
+		return
+	}
+	C.keep_above(C.Window(window.WindowHandle))
+}
@@ -0,0 +1,22 @@
+package components
+
+// What a headset button or the play/pause media key can be set to do, for
+// each number of quick presses.
+const (
+	ButtonActionNone      = "none"
+	ButtonActionPlayPause = "play_pause"
+	ButtonActionNext      = "next"
+	ButtonActionPrevious  = "previous"
+)
+
+// RunButtonAction does what a media button press was set to do.
+func (pb *PlayerBar) RunButtonAction(action string) {
+	switch action {
+	case ButtonActionPlayPause:
+		pb.TogglePlay()
+	case ButtonActionNext:
+		pb.Next()
+	case ButtonActionPrevious:
+		pb.Previous()
+	}
+}
@@ -1,10 +1,10 @@
 package components
 
 import (
+	"context"
 	"fmt"
 	"image/color"
 	"log"
-	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -21,6 +21,10 @@ import (
 
 const UnknownArtist = "Unknown Artist"
 
+// hoverHoldDelay is how long the pointer rests on a card before it counts
+// as held there.
+const hoverHoldDelay = time.Second
+
 type MediaGrid struct {
 	widget.BaseWidget
 	items              []MediaItem
@@ -28,12 +32,16 @@ type MediaGrid struct {
 	columns            int
 	onItemTap          func(int)
 	onItemSecondaryTap func(int, fyne.Position)
+	onItemHold         func(int)
+	onItemRelease      func(int)
 	imageService       *services.ImageService
 	compactMode        bool
 	virtualScroll      bool
 	debug              bool
 	maxItems           int
 	initialized        bool
+	renderer           *mediaGridRenderer
+	highlighted        int
 }
 
 type MediaItem struct {
@@ -52,6 +60,7 @@ func NewMediaGrid(itemSize fyne.Size, imageService *services.ImageService) *Medi
 		maxItems:     1000,
 		items:        make([]MediaItem, 0),
 		initialized:  false,
+		highlighted:  -1,
 	}
 	grid.ExtendBaseWidget(grid)
 	return grid
@@ -67,6 +76,7 @@ func (mg *MediaGrid) SetItems(items []MediaItem) {
 	}
 
 	mg.items = items
+	mg.highlighted = -1
 
 	if mg.initialized {
 		mg.Refresh()
@@ -80,6 +90,39 @@ func (mg *MediaGrid) GetItems() []MediaItem {
 	return mg.items
 }
 
+// Highlight marks the item at index, or no item when index is -1, until
+// another is marked or the items change.
+func (mg *MediaGrid) Highlight(index int) {
+	if mg == nil || index == mg.highlighted {
+		return
+	}
+	if card := mg.card(mg.highlighted); card != nil {
+		card.setHighlighted(false)
+	}
+	mg.highlighted = index
+	if card := mg.card(index); card != nil {
+		card.setHighlighted(true)
+	}
+}
+
+// ItemPosition returns where the item at index is laid out in the grid,
+// and false when it isn't shown.
+func (mg *MediaGrid) ItemPosition(index int) (fyne.Position, bool) {
+	card := mg.card(index)
+	if card == nil {
+		return fyne.Position{}, false
+	}
+	return mg.renderer.container.Position().Add(card.Position()), true
+}
+
+func (mg *MediaGrid) card(index int) *MediaCard {
+	if mg == nil || mg.renderer == nil || index < 0 || index >= len(mg.renderer.container.Objects) {
+		return nil
+	}
+	card, _ := mg.renderer.container.Objects[index].(*MediaCard)
+	return card
+}
+
 func (mg *MediaGrid) SetItemTapCallback(callback func(int)) {
 	if mg == nil {
 		return
@@ -94,6 +137,16 @@ func (mg *MediaGrid) SetItemSecondaryTapCallback(callback func(int, fyne.Positio
 	mg.onItemSecondaryTap = callback
 }
 
+// SetItemHoverCallbacks sets what happens once the pointer has rested on an
+// item for a second, and when it leaves an item it rested on.
+func (mg *MediaGrid) SetItemHoverCallbacks(onHold, onRelease func(int)) {
+	if mg == nil {
+		return
+	}
+	mg.onItemHold = onHold
+	mg.onItemRelease = onRelease
+}
+
 func (mg *MediaGrid) SetVirtualScroll(enabled bool) {
 	if mg == nil {
 		return
@@ -139,6 +192,7 @@ func (mg *MediaGrid) CreateRenderer() fyne.WidgetRenderer {
 	}
 
 	mg.initialized = true
+	mg.renderer = renderer
 	renderer.Refresh()
 	return renderer
 }
@@ -180,9 +234,21 @@ func (r *mediaGridRenderer) Refresh() {
 		itemsToShow = itemsToShow[:r.grid.maxItems]
 	}
 
+	// The old cards are discarded, so their pending image loads and
+	// hovers are too.
+	for _, obj := range r.container.Objects {
+		if card, ok := obj.(*MediaCard); ok {
+			card.CancelImageLoad()
+			card.MouseOut()
+		}
+	}
+
 	objs := make([]fyne.CanvasObject, 0, len(itemsToShow))
 	for i, item := range itemsToShow {
 		card := NewMediaCardWithContext(item, r.grid.itemSize, r.grid.imageService, r.grid.debug, i)
+		if i == r.grid.highlighted {
+			card.setHighlighted(true)
+		}
 
 		// Set up tap callbacks properly
 		if r.grid.onItemTap != nil {
@@ -213,6 +279,15 @@ func (r *mediaGridRenderer) Refresh() {
 			})
 		}
 
+		if r.grid.onItemHold != nil {
+			idx := i
+			onHold, onRelease := r.grid.onItemHold, r.grid.onItemRelease
+			card.onHold = func() { onHold(idx) }
+			if onRelease != nil {
+				card.onRelease = func() { onRelease(idx) }
+			}
+		}
+
 		objs = append(objs, card)
 	}
 	r.container.Objects = objs
@@ -253,20 +328,27 @@ type MediaCard struct {
 	imageService   *services.ImageService
 	onTap          func()
 	onSecondaryTap func(fyne.Position)
+	onHold         func()
+	onRelease      func()
 	debug          bool
 	index          int
 
-	image     *canvas.Image
-	title     *widget.Label
-	subtitle  *widget.Label
-	overlay   *canvas.Rectangle
-	hovered   bool
-	container *fyne.Container
+	image       *canvas.Image
+	title       *widget.Label
+	subtitle    *widget.Label
+	overlay     *canvas.Rectangle
+	hovered     bool
+	highlighted bool
+	container   *fyne.Container
 
 	lastTapTime    time.Time
 	tapCount       int
 	longPressTimer *time.Timer
 	longPressPos   fyne.Position
+	holdTimer      *time.Timer
+	held           bool
+
+	cancelImage context.CancelFunc
 }
 
 func NewMediaCardWithContext(item MediaItem, size fyne.Size, imageService *services.ImageService, debug bool, index int) *MediaCard {
@@ -305,7 +387,7 @@ func NewMediaCardWithContext(item MediaItem, size fyne.Size, imageService *servi
 	card.subtitle.Wrapping = fyne.TextWrapOff
 
 	card.overlay = canvas.NewRectangle(color.NRGBA{R: 0, G: 0, B: 0, A: 30})
-	card.overlay.Hide()
+	card.updateOverlay()
 
 	card.ExtendBaseWidget(card)
 
@@ -314,8 +396,11 @@ func NewMediaCardWithContext(item MediaItem, size fyne.Size, imageService *servi
 	}
 
 	// Load image if available
-	if item.ImageURL != "" && imageService != nil {
-		imageService.GetImageWithSize(item.ImageURL, fyne.NewSize(size.Width-16, size.Height-60), func(res fyne.Resource, err error) {
+	coverSize := fyne.NewSize(size.Width-16, size.Height-60)
+	if imageURL := mediaItemImageURL(imageService, item, coverSize); imageURL != "" {
+		var ctx context.Context
+		ctx, card.cancelImage = context.WithCancel(context.Background())
+		imageService.GetImageWithSize(ctx, imageURL, coverSize, func(res fyne.Resource, err error) {
 			if err == nil && res != nil {
 				card.image.Resource = res
 				card.image.Refresh()
@@ -398,16 +483,57 @@ func (mc *MediaCard) TouchCancel(event *mobile.TouchEvent) {
 
 func (mc *MediaCard) MouseIn(event *desktop.MouseEvent) {
 	mc.hovered = true
-	mc.overlay.FillColor = color.NRGBA{R: 255, G: 255, B: 255, A: 20}
-	mc.overlay.Show()
-	mc.overlay.Refresh()
+	mc.updateOverlay()
+
+	if mc.onHold != nil {
+		mc.holdTimer = time.AfterFunc(hoverHoldDelay, func() {
+			fyne.Do(func() {
+				if !mc.hovered || mc.held {
+					return
+				}
+				mc.held = true
+				mc.onHold()
+			})
+		})
+	}
 }
 
 func (mc *MediaCard) MouseMoved(event *desktop.MouseEvent) {}
 
 func (mc *MediaCard) MouseOut() {
 	mc.hovered = false
-	mc.overlay.Hide()
+	mc.updateOverlay()
+
+	if mc.holdTimer != nil {
+		mc.holdTimer.Stop()
+		mc.holdTimer = nil
+	}
+	if mc.held {
+		mc.held = false
+		if mc.onRelease != nil {
+			mc.onRelease()
+		}
+	}
+}
+
+func (mc *MediaCard) setHighlighted(on bool) {
+	mc.highlighted = on
+	mc.updateOverlay()
+}
+
+// updateOverlay lightens the card while hovered and tints it in the
+// selection color while highlighted.
+func (mc *MediaCard) updateOverlay() {
+	switch {
+	case mc.hovered:
+		mc.overlay.FillColor = color.NRGBA{R: 255, G: 255, B: 255, A: 20}
+		mc.overlay.Show()
+	case mc.highlighted:
+		mc.overlay.FillColor = theme.Color(theme.ColorNameSelection)
+		mc.overlay.Show()
+	default:
+		mc.overlay.Hide()
+	}
 	mc.overlay.Refresh()
 }
 
@@ -429,35 +555,29 @@ func (r *mediaGridRenderer) Layout(size fyne.Size) {
 
 func MediaItemFromSong(song *types.Song) MediaItem {
 	subtitle := getArtistNamesForSong(song.Authors)
-	imageURL := ""
-	if song.ImageCropped != nil && *song.ImageCropped != "" {
-		imageURL = *song.ImageCropped
-	} else if song.Image != nil && *song.Image != "" {
-		imageURL = *song.Image
-	}
+	imageURL := services.SongImageURL(song, fyne.Size{})
 	return MediaItem{Title: song.Name, Subtitle: subtitle, ImageURL: imageURL, Data: song}
 }
 
 func MediaItemFromAlbum(album *types.Album) MediaItem {
-	subtitle := getArtistNamesForAlbum(album.Artists)
+	artist := album.DisplayArtist()
+	subtitle := artist
+	if subtitle == "" {
+		subtitle = UnknownArtist
+	}
 
 	// Show actual song count if available
 	if len(album.Songs) > 0 {
-		if len(album.Artists) > 0 {
+		if artist != "" {
 			subtitle = fmt.Sprintf("%s • %d songs", subtitle, len(album.Songs))
 		} else {
 			subtitle = fmt.Sprintf("%d songs", len(album.Songs))
 		}
-	} else if len(album.Artists) == 0 {
+	} else if artist == "" {
 		subtitle = "Album"
 	}
 
-	imageURL := ""
-	if album.ImageCropped != nil && *album.ImageCropped != "" {
-		imageURL = *album.ImageCropped
-	} else if album.Image != nil && *album.Image != "" {
-		imageURL = *album.Image
-	}
+	imageURL := services.AlbumImageURL(album, fyne.Size{})
 	return MediaItem{Title: album.Name, Subtitle: subtitle, ImageURL: imageURL, Data: album}
 }
 
@@ -477,59 +597,32 @@ func MediaItemFromAuthor(author *types.Author) MediaItem {
 		subtitle = "Artist"
 	}
 
-	imageURL := ""
-	if author.ImageCropped != nil && *author.ImageCropped != "" {
-		imageURL = *author.ImageCropped
-	} else if author.Image != nil && *author.Image != "" {
-		imageURL = *author.Image
-	}
+	imageURL := services.AuthorImageURL(author, fyne.Size{})
 	return MediaItem{Title: author.Name, Subtitle: subtitle, ImageURL: imageURL, Data: author}
 }
 
-func getArtistNamesForSong(authors []*types.Author) string {
-	if len(authors) == 0 {
-		return UnknownArtist
-	}
-
-	names := make([]string, 0, len(authors))
-	for _, author := range authors {
-		if author != nil && author.Name != "" {
-			names = append(names, author.Name)
-		}
-	}
-
-	if len(names) == 0 {
-		return UnknownArtist
+// mediaItemImageURL returns the image to show for item at size, preferring
+// artwork the user picked for it over the server's.
+func mediaItemImageURL(imageService *services.ImageService, item MediaItem, size fyne.Size) string {
+	if imageService == nil {
+		return ""
 	}
-
-	if len(names) == 1 {
-		return names[0]
+	switch data := item.Data.(type) {
+	case *types.Song:
+		return imageService.SongCoverURL(data, size)
+	case *types.Album:
+		return imageService.AlbumCoverURL(data, size)
+	case *types.Author:
+		return imageService.AuthorCoverURL(data, size)
 	}
-
-	if len(names) == 2 {
-		return names[0] + " & " + names[1]
-	}
-
-	return strings.Join(names[:2], ", ") + fmt.Sprintf(" +%d", len(names)-2)
+	return item.ImageURL
 }
 
-func getArtistNamesForAlbum(artists []*types.Author) string {
-	if len(artists) == 0 {
-		return UnknownArtist
-	}
-
-	names := make([]string, 0, len(artists))
-	for _, artist := range artists {
-		if artist != nil && artist.Name != "" {
-			names = append(names, artist.Name)
-		}
-	}
-
-	if len(names) == 0 {
-		return UnknownArtist
+func getArtistNamesForSong(authors []*types.Author) string {
+	if label := types.CreditsLabel(authors); label != "" {
+		return label
 	}
-
-	return strings.Join(names, ", ")
+	return UnknownArtist
 }
 
 func (r *mediaGridRenderer) setColumns(cols int) {
@@ -575,6 +668,13 @@ func maxInt(a, b int) int {
 	return b
 }
 
+// CancelImageLoad drops the card's cover request if it has not resolved yet.
+func (mc *MediaCard) CancelImageLoad() {
+	if mc.cancelImage != nil {
+		mc.cancelImage()
+	}
+}
+
 func (mc *MediaCard) CreateRenderer() fyne.WidgetRenderer {
 	return &mediaCardRenderer{card: mc}
 }
@@ -617,4 +717,8 @@ func (r *mediaCardRenderer) Objects() []fyne.CanvasObject {
 	return []fyne.CanvasObject{r.card.container}
 }
 
-func (r *mediaCardRenderer) Destroy() {}
+func (r *mediaCardRenderer) Destroy() {
+	if r.card != nil {
+		r.card.CancelImageLoad()
+	}
+}
@@ -0,0 +1,65 @@
+package components
+
+import (
+	"fmt"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/test"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+func benchMediaItems(n int) []MediaItem {
+	items := make([]MediaItem, n)
+	for i := range items {
+		items[i] = MediaItemFromSong(&types.Song{
+			Slug:    fmt.Sprintf("bench-song-%d", i),
+			Name:    fmt.Sprintf("Bench Song %d", i),
+			Authors: []*types.Author{{Name: fmt.Sprintf("Bench Artist %d", i%50)}},
+		})
+	}
+	return items
+}
+
+// BenchmarkMediaGridSetItems measures rebuilding the cards when the songs
+// view loads a full page of results.
+func BenchmarkMediaGridSetItems(b *testing.B) {
+	test.NewApp()
+
+	grid := NewMediaGrid(fyne.NewSize(200, 280), nil)
+	grid.SetVirtualScroll(true)
+
+	c := test.NewCanvas()
+	c.SetContent(container.NewVScroll(grid))
+	c.Resize(fyne.NewSize(1200, 800))
+
+	items := benchMediaItems(1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		grid.SetItems(items)
+	}
+}
+
+// BenchmarkMediaGridLayout measures laying the grid out again at a new
+// width, as happens while the window is resized.
+func BenchmarkMediaGridLayout(b *testing.B) {
+	test.NewApp()
+
+	grid := NewMediaGrid(fyne.NewSize(200, 280), nil)
+	grid.SetVirtualScroll(true)
+
+	c := test.NewCanvas()
+	c.SetContent(container.NewVScroll(grid))
+	c.Resize(fyne.NewSize(1200, 800))
+	grid.SetItems(benchMediaItems(1000))
+
+	widths := []float32{900, 1200, 1600}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		grid.Resize(fyne.NewSize(widths[i%len(widths)], grid.MinSize().Height))
+	}
+}
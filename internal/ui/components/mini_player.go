@@ -0,0 +1,178 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const miniCoverSize = 96
+
+// miniKeepAboveDelay gives the window manager time to map the window before
+// it is asked to keep it above the others.
+const miniKeepAboveDelay = 200 * time.Millisecond
+
+// miniPlayer is a small window with the cover, title, seek bar and transport
+// controls of the player bar, kept above other windows where the platform
+// allows it. It drives the player bar, so both control the same player.
+type miniPlayer struct {
+	pb     *PlayerBar
+	window fyne.Window
+
+	coverImg    *canvas.Image
+	coverCancel context.CancelFunc
+	songLabel   *widget.Label
+	artistLabel *widget.Label
+	timeLabel   *widget.Label
+	seekBar     *widget.Slider
+	playBtn     *widget.Button
+}
+
+// ToggleMiniPlayer opens the mini-player, or closes it when open.
+func (pb *PlayerBar) ToggleMiniPlayer() {
+	if pb.mini != nil {
+		pb.mini.window.Close()
+		return
+	}
+	pb.showMiniPlayer()
+}
+
+// MiniPlayerOpen reports whether the mini-player window is showing.
+func (pb *PlayerBar) MiniPlayerOpen() bool {
+	return pb.mini != nil
+}
+
+func (pb *PlayerBar) showMiniPlayer() {
+	mp := &miniPlayer{pb: pb}
+	mp.window = fyne.CurrentApp().NewWindow("AMP")
+	mp.window.SetFixedSize(true)
+
+	mp.coverImg = canvas.NewImageFromResource(theme.MediaMusicIcon())
+	mp.coverImg.FillMode = canvas.ImageFillContain
+	mp.coverImg.SetMinSize(fyne.NewSize(miniCoverSize, miniCoverSize))
+
+	mp.songLabel = widget.NewLabel("")
+	mp.songLabel.TextStyle = fyne.TextStyle{Bold: true}
+	mp.songLabel.Truncation = fyne.TextTruncateEllipsis
+	mp.artistLabel = widget.NewLabel("")
+	mp.artistLabel.Truncation = fyne.TextTruncateEllipsis
+	mp.timeLabel = widget.NewLabel("0:00 / 0:00")
+	mp.timeLabel.TextStyle = fyne.TextStyle{Monospace: true}
+
+	mp.seekBar = widget.NewSlider(0, 100)
+	mp.seekBar.OnChangeEnded = mp.onSeekEnded
+
+	mp.playBtn = widget.NewButtonWithIcon("", theme.MediaPlayIcon(), pb.togglePlay)
+	prevBtn := widget.NewButtonWithIcon("", theme.MediaSkipPreviousIcon(), pb.previousSong)
+	nextBtn := widget.NewButtonWithIcon("", theme.MediaSkipNextIcon(), pb.nextSong)
+	expandBtn := widget.NewButtonWithIcon("", theme.ViewFullScreenIcon(), mp.expand)
+	expandBtn.Importance = widget.LowImportance
+
+	controls := container.NewBorder(nil, nil, mp.timeLabel, expandBtn,
+		container.NewCenter(container.NewHBox(prevBtn, mp.playBtn, nextBtn)))
+	info := container.NewVBox(mp.songLabel, mp.artistLabel, mp.seekBar, controls)
+	mp.window.SetContent(container.NewBorder(nil, nil, container.NewCenter(mp.coverImg), nil, info))
+	mp.window.Resize(fyne.NewSize(420, miniCoverSize+24))
+
+	mp.window.SetOnClosed(func() {
+		if mp.coverCancel != nil {
+			mp.coverCancel()
+		}
+		if pb.mini == mp {
+			pb.mini = nil
+		}
+	})
+
+	pb.mini = mp
+	mp.setSong(pb.currentSong)
+	mp.setPlaying(pb.isPlaying)
+	if pb.lastDuration > 0 {
+		mp.setProgress(pb.lastPosition, pb.lastDuration)
+	}
+	mp.window.Show()
+
+	time.AfterFunc(miniKeepAboveDelay, func() {
+		fyne.Do(func() {
+			if native, ok := mp.window.(driver.NativeWindow); ok && pb.mini == mp {
+				native.RunNative(keepAbove)
+			}
+		})
+	})
+}
+
+// expand brings the main window back and closes the mini-player.
+func (mp *miniPlayer) expand() {
+	if parent := mp.pb.parentWindow; parent != nil {
+		parent.Show()
+		parent.RequestFocus()
+	}
+	mp.window.Close()
+}
+
+func (mp *miniPlayer) setSong(song *types.Song) {
+	if song == nil {
+		mp.songLabel.SetText("No song playing")
+		mp.artistLabel.SetText("")
+		mp.window.SetTitle("AMP")
+	} else {
+		mp.songLabel.SetText(song.Name)
+		mp.artistLabel.SetText(getArtistNames(song.Authors))
+		mp.window.SetTitle(song.Name + " - AMP")
+	}
+
+	if mp.coverCancel != nil {
+		mp.coverCancel()
+	}
+	size := fyne.NewSize(miniCoverSize, miniCoverSize)
+	url := mp.pb.imageService.SongCoverURL(song, size)
+	if url == "" {
+		mp.coverImg.Resource = theme.MediaMusicIcon()
+		mp.coverImg.Refresh()
+		return
+	}
+	var ctx context.Context
+	ctx, mp.coverCancel = context.WithCancel(context.Background())
+	mp.pb.imageService.GetImageWithSize(ctx, url, size, func(res fyne.Resource, err error) {
+		if err != nil || res == nil {
+			res = theme.MediaMusicIcon()
+		}
+		mp.coverImg.Resource = res
+		mp.coverImg.Refresh()
+	})
+}
+
+func (mp *miniPlayer) setPlaying(playing bool) {
+	if playing {
+		mp.playBtn.SetIcon(theme.MediaPauseIcon())
+	} else {
+		mp.playBtn.SetIcon(theme.MediaPlayIcon())
+	}
+}
+
+func (mp *miniPlayer) setProgress(pos, dur time.Duration) {
+	if dur <= 0 {
+		mp.timeLabel.SetText(fmt.Sprintf("%s / --:--", formatDuration(pos)))
+		return
+	}
+	mp.timeLabel.SetText(fmt.Sprintf("%s / %s", formatDuration(pos), formatDuration(dur)))
+	mp.seekBar.SetValue(max(0, min(float64(pos)/float64(dur)*100, 100)))
+}
+
+func (mp *miniPlayer) onSeekEnded(value float64) {
+	if mp.pb.lastDuration <= 0 {
+		return
+	}
+	pos := time.Duration(float64(mp.pb.lastDuration) * value / 100)
+	if _, err := mp.pb.SeekTo(pos); err != nil {
+		mp.setProgress(mp.pb.lastPosition, mp.pb.lastDuration)
+	}
+}
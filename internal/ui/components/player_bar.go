@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/Alexander-D-Karpov/amp/internal/services"
 	"log"
-	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -17,7 +16,9 @@ import (
 
 	"github.com/Alexander-D-Karpov/amp/internal/audio"
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/media"
 	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/themes"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
@@ -33,24 +34,42 @@ type PlayerBar struct {
 	shuffleBtn     *widget.Button
 	repeatBtn      *widget.Button
 	likeBtn        *widget.Button
+	privateBtn     *widget.Button
+	karaokeBtn     *widget.Button
+	visualizer     *Visualizer
+	castBtn        *widget.Button
+	eqBtn          *widget.Button
 	seekBar        *widget.Slider
 	bufferProgress *bufferBar
 	waveform       *waveformBar
-	volumeBar      *widget.Slider
-	volumeBtn      *widget.Button
+	volumeBar      *volumeSlider
+	volumeBtn      *volumeButton
+	mutedLevel     float64
 	timeLabel      *widget.Label
+	healthLabel    *widget.Label
+	outputLabel    *widget.Label
 	songLabel      *widget.Label
 	artistLabel    *widget.Label
 	imageService   *services.ImageService
 	coverImg       *canvas.Image
+	coverCancel    context.CancelFunc
+	coverColors    *media.CoverColors
+	tintBg         *canvas.Rectangle
+	seekTint       *container.ThemeOverride
 	volumeDialog   dialog.Dialog
 	closeBtn       *widget.Button
+	upNextBtn      *widget.Button
+	upNextSkip     *widget.Button
+	moreBtn        *widget.Button
+	mini           *miniPlayer
 
-	seekStack *fyne.Container
+	seekStack   *fyne.Container
+	seekPreview *seekPreview
 
 	currentSong   *types.Song
 	isPlaying     bool
 	loading       bool
+	buffering     bool
 	loadingStopCh chan struct{}
 	isShuffled    bool
 	repeatMode    RepeatMode
@@ -59,6 +78,25 @@ type PlayerBar struct {
 	compactMode   bool
 	breakpoint    float32
 
+	// unshuffled is the queue's order from before it was shuffled, and
+	// shuffleHistory the songs played since, most recent last.
+	unshuffled     []*types.Song
+	shuffleHistory []*types.Song
+
+	// casting sends playback to a network renderer through caster instead
+	// of the speakers. localOutput is the output shown once it stops.
+	caster      *services.CastService
+	casting     bool
+	castStop    chan struct{}
+	castSent    time.Time
+	localOutput string
+
+	// radioMode queues songs from radioSource when the queue runs out.
+	radioMode    bool
+	radioBtn     *widget.Button
+	radioLoading bool
+	radioSource  RadioSource
+
 	currentHeight float32
 	desiredHeight float32
 	minHeight     float32
@@ -77,6 +115,27 @@ type PlayerBar struct {
 	loadingLabel            *widget.Label
 	onPlayed                func(*types.Song)
 	onPrefetchNext          func(*types.Song)
+	onTrackStarted          func(*types.Song, int, int)
+	onTrackEnded            func(*types.Song, int, bool)
+	onPlaybackChanged       func(*types.Song, bool)
+	onPosition              func(*types.Song, time.Duration)
+	onPrivateMode           func(bool)
+	privateMode             bool
+	onQueueChanged          func()
+	onShowQueue             func()
+	undoBar                 *UndoBar
+	restoredPending         bool
+	resumeSlug              string
+	resumeAt                time.Duration
+	endReported             bool
+	transition              types.PlaylistTransition
+	transitionStarted       bool
+	crossfadeNext           time.Duration
+	gapTimer                *time.Timer
+	sleepTimer              *audio.SleepTimer
+	sleepFade               bool
+	lastListenTick          time.Time
+	listened                time.Duration
 
 	playStartTime   time.Time
 	minPlayDuration time.Duration
@@ -110,6 +169,7 @@ func NewPlayerBar(player *audio.Player, storage *storage.Database, imageService
 		player:          player,
 		storage:         storage,
 		imageService:    imageService,
+		coverColors:     media.NewCoverColors(256),
 		queue:           make([]*types.Song, 0),
 		queueIndex:      -1,
 		breakpoint:      800.0,
@@ -117,23 +177,43 @@ func NewPlayerBar(player *audio.Player, storage *storage.Database, imageService
 		maxHeight:       132.0,
 		screenSize:      fyne.NewSize(800, 54),
 		minPlayDuration: 30 * time.Second, // Minimum 30 seconds to count as played
+		transition:      types.PlaylistTransition{Mode: types.TransitionNormal},
+		sleepFade:       true,
 		debug:           debug,
 	}
 	pb.setupWidgets()
 	pb.setupLayout()
 	pb.setupEventHandlers()
 	pb.calculateDesiredHeight()
+
+	imageService.OnArtworkChanged(func(types.ArtworkKind, string) {
+		if song := pb.currentSong; song != nil {
+			pb.SetCurrentSong(song)
+		}
+	})
+
 	return pb
 }
 
 func (pb *PlayerBar) SetConfig(cfg *config.Config) {
 	pb.cfg = cfg
+	if cfg != nil {
+		pb.radioMode = cfg.Audio.Radio
+		pb.updateRadioButton()
+		pb.visualizer.SetMode(cfg.UI.VisualizerMode)
+		pb.showVisualizer(cfg.UI.Visualizer)
+	}
 }
 
 func (pb *PlayerBar) SetParentWindow(window fyne.Window) {
 	pb.parentWindow = window
 }
 
+// SetUndoBar sets where removals from the queue and unlikes can be undone.
+func (pb *PlayerBar) SetUndoBar(bar *UndoBar) {
+	pb.undoBar = bar
+}
+
 func (pb *PlayerBar) SetScreenSize(size fyne.Size) {
 	pb.screenSize = size
 	pb.compactMode = size.Width < pb.breakpoint
@@ -160,20 +240,47 @@ func (pb *PlayerBar) setupWidgets() {
 	pb.playBtn = widget.NewButtonWithIcon("", theme.MediaPlayIcon(), pb.togglePlay)
 	pb.prevBtn = widget.NewButtonWithIcon("", theme.MediaSkipPreviousIcon(), pb.previousSong)
 	pb.nextBtn = widget.NewButtonWithIcon("", theme.MediaSkipNextIcon(), pb.nextSong)
+	pb.shuffleBtn = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), pb.toggleShuffle)
+	pb.shuffleBtn.Importance = widget.LowImportance
+	pb.repeatBtn = widget.NewButtonWithIcon("", theme.MediaReplayIcon(), pb.toggleRepeat)
+	pb.repeatBtn.Importance = widget.LowImportance
 
 	pb.closeBtn = widget.NewButtonWithIcon("", theme.CancelIcon(), pb.closeAndHide)
 	pb.closeBtn.Importance = widget.LowImportance
 
-	pb.likeBtn = widget.NewButtonWithIcon("", theme.VisibilityOffIcon(), nil)
+	pb.likeBtn = widget.NewButtonWithIcon("", themes.LikeIcon(false), nil)
 	pb.likeBtn.Hide()
 
-	pb.volumeBar = widget.NewSlider(0, 100)
-	pb.volumeBar.SetValue(70)
+	pb.karaokeBtn = widget.NewButton("Karaoke", pb.toggleKaraoke)
+	pb.updateKaraokeButton()
+
+	pb.radioBtn = widget.NewButton("Radio", pb.toggleRadio)
+	pb.updateRadioButton()
+
+	pb.eqBtn = widget.NewButton("EQ", pb.showEqualizerMenu)
+	pb.updateEqualizerButton()
+
+	pb.castBtn = widget.NewButton("Cast", pb.onCastTapped)
+	pb.castBtn.Hide()
+
+	pb.privateBtn = widget.NewButtonWithIcon("", theme.VisibilityIcon(), pb.togglePrivateMode)
+	pb.updatePrivateButton()
+
+	pb.moreBtn = widget.NewButtonWithIcon("", theme.MoreHorizontalIcon(), pb.showMoreMenu)
+	pb.moreBtn.Importance = widget.LowImportance
+
+	pb.volumeBar = newVolumeSlider(pb.scrollVolume)
+	pb.volumeBar.SetValue(pb.player.Volume() * 100)
 	pb.volumeBar.OnChanged = pb.onVolumeChange
-	pb.volumeBtn = widget.NewButtonWithIcon("", volumeIconFor(pb.volumeBar.Value), pb.showVolumeDialog)
+	pb.volumeBtn = newVolumeButton(volumeIconFor(pb.volumeBar.Value), pb.onVolumeButtonTapped, pb.scrollVolume)
 
 	pb.timeLabel = widget.NewLabel("0:00 / 0:00")
 	pb.timeLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	pb.healthLabel = widget.NewLabel("")
+	pb.healthLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	pb.healthLabel.Hide()
+	pb.outputLabel = widget.NewLabel("")
+	pb.outputLabel.Hide()
 	pb.loadingLabel = widget.NewLabel("")
 	pb.loadingLabel.Hide()
 
@@ -192,7 +299,9 @@ func (pb *PlayerBar) setupWidgets() {
 	pb.artistLabel.Truncation = fyne.TextTruncateEllipsis
 
 	pb.setupSeekBar()
+	pb.setupUpNext()
 	pb.setupStatusLabel()
+	pb.setupVisualizer()
 
 	pb.coverImg = canvas.NewImageFromResource(theme.MediaMusicIcon())
 }
@@ -222,23 +331,25 @@ func (pb *PlayerBar) setupDesktopLayout() {
 
 	left := container.NewHBox(pb.coverImg, infoWrap)
 
-	controls := container.NewHBox(pb.prevBtn, pb.playBtn, pb.nextBtn)
+	controls := container.NewHBox(pb.shuffleBtn, pb.prevBtn, pb.playBtn, pb.nextBtn, pb.repeatBtn)
 
 	volWidth := float32(200)
 	volWrap := container.NewGridWrap(fyne.NewSize(volWidth, pb.volumeBar.MinSize().Height), pb.volumeBar)
 	volRow := container.NewBorder(nil, nil, pb.volumeBtn, nil, volWrap)
 
-	right := container.NewHBox(volRow, pb.closeBtn)
+	right := container.NewHBox(pb.privateBtn, pb.karaokeBtn, pb.radioBtn, pb.eqBtn, pb.castBtn, volRow, pb.moreBtn, pb.closeBtn)
 
 	row := container.NewBorder(nil, nil, left, right, container.NewCenter(controls))
 
+	upNext := container.NewHBox(pb.upNextBtn, pb.upNextSkip)
 	content := container.NewVBox(
 		pb.topSeekRow(),
-		container.NewHBox(pb.timeLabel),
+		container.NewBorder(nil, nil, container.NewHBox(pb.timeLabel, pb.healthLabel, pb.outputLabel), upNext),
 		row,
+		pb.visualizer,
 	)
 
-	pb.container.Objects = []fyne.CanvasObject{content}
+	pb.container.Objects = []fyne.CanvasObject{pb.tintBg, content}
 	pb.container.Refresh()
 }
 
@@ -251,19 +362,20 @@ func (pb *PlayerBar) setupCompactLayout() {
 
 	left := container.NewHBox(pb.coverImg, infoWrap)
 
-	controls := container.NewHBox(pb.prevBtn, pb.playBtn, pb.nextBtn)
+	controls := container.NewHBox(pb.shuffleBtn, pb.prevBtn, pb.playBtn, pb.nextBtn, pb.repeatBtn)
 
-	right := container.NewHBox(pb.volumeBtn, pb.closeBtn)
+	right := container.NewHBox(pb.privateBtn, pb.karaokeBtn, pb.radioBtn, pb.eqBtn, pb.castBtn, pb.volumeBtn, pb.moreBtn, pb.closeBtn)
 
 	row := container.NewBorder(nil, nil, left, right, container.NewCenter(controls))
 
 	content := container.NewVBox(
 		pb.topSeekRow(),
-		container.NewHBox(pb.loadingLabel, pb.timeLabel),
+		container.NewHBox(pb.loadingLabel, pb.timeLabel, pb.healthLabel, pb.outputLabel),
 		row,
+		pb.visualizer,
 	)
 
-	pb.container.Objects = []fyne.CanvasObject{content}
+	pb.container.Objects = []fyne.CanvasObject{pb.tintBg, content}
 	pb.container.Refresh()
 }
 
@@ -275,6 +387,9 @@ func (pb *PlayerBar) setupEventHandlers() {
 			}
 
 			pb.lastPosition = pos
+			if pb.resumeAt > 0 {
+				pb.resumeRestoredPosition(pos)
+			}
 			dur := pb.player.GetDuration()
 			pb.lastDuration = dur
 
@@ -287,29 +402,43 @@ func (pb *PlayerBar) setupEventHandlers() {
 					progress = 0
 				}
 
-				pb.seekingProgrammatically = true
-				pb.seekBar.SetValue(progress)
-				pb.seekingProgrammatically = false
+				pb.setSeekValue(progress)
 
 				pb.timeLabel.SetText(fmt.Sprintf("%s / %s", formatDuration(pos), formatDuration(dur)))
-
+				pb.checkTransition(pos, dur)
 			} else {
 				pb.timeLabel.SetText(fmt.Sprintf("%s / --:--", formatDuration(pos)))
 			}
+			if pb.mini != nil {
+				pb.mini.setProgress(pos, dur)
+			}
+			if pb.onPosition != nil {
+				pb.onPosition(pb.currentSong, pos)
+			}
+			pb.checkBreak()
+
+			pb.updateBufferHealth()
 
 			// Update buffer progress
 			dp := pb.player.GetDownloadProgress()
 			if dp < 1.0 && dp > 0 {
-				pb.bufferProgress.SetValue(dp)
-				pb.bufferProgress.Show()
+				pb.showBufferProgress(dp)
 			} else {
 				pb.bufferProgress.Hide()
 			}
 		})
 	})
 
+	pb.player.OnBufferingChanged(func(buffering, stalled bool) {
+		fyne.Do(func() { pb.setBuffering(buffering, stalled) })
+	})
+
+	pb.player.OnVolumeChanged(func(level float64) {
+		fyne.Do(func() { pb.volumeBar.SetValue(level * 100) })
+	})
+
 	pb.player.OnFinished(func() {
-		pb.handleSongFinished()
+		fyne.Do(pb.handleSongFinished)
 	})
 }
 
@@ -328,10 +457,8 @@ func (pb *PlayerBar) onSeekChanged(value float64) {
 		// Check if player supports seeking
 		if !pb.player.CanSeek() {
 			// Reset to current position if seeking not supported
-			pb.seekingProgrammatically = true
 			currentProgress := float64(pb.lastPosition) / float64(pb.lastDuration) * 100
-			pb.seekBar.SetValue(currentProgress)
-			pb.seekingProgrammatically = false
+			pb.setSeekValue(currentProgress)
 			pb.userSeeking = false
 			return
 		}
@@ -354,13 +481,12 @@ func (pb *PlayerBar) onSeekChanged(value float64) {
 
 		// Update seek bar if we had to clamp the value
 		if pb.seekBar.Value != value {
-			pb.seekingProgrammatically = true
-			pb.seekBar.SetValue(value)
-			pb.seekingProgrammatically = false
+			pb.setSeekValue(value)
 		}
 
 		// Update time display
 		pb.timeLabel.SetText(fmt.Sprintf("%s / %s", formatDuration(pos), formatDuration(pb.lastDuration)))
+		pb.showSeekPreview(value / 100)
 
 		// Show buffer progress if streaming
 		if !pb.player.HasSufficientBuffer(pos) {
@@ -374,10 +500,15 @@ func (pb *PlayerBar) onSeekChanged(value float64) {
 
 func (pb *PlayerBar) onSeekEnded(value float64) {
 	pb.userSeeking = false
+	pb.hideSeekPreview()
 
 	if pb.seekingProgrammatically || pb.lastDuration <= 0 {
 		return
 	}
+	if pb.casting {
+		pb.castSeek(time.Duration(float64(pb.lastDuration) * value / 100.0))
+		return
+	}
 
 	// Check if player supports seeking
 	if !pb.player.CanSeek() {
@@ -414,9 +545,7 @@ func (pb *PlayerBar) onSeekEnded(value float64) {
 
 		// Reset to current position
 		currentProgress := float64(pb.lastPosition) / float64(pb.lastDuration) * 100
-		pb.seekingProgrammatically = true
-		pb.seekBar.SetValue(currentProgress)
-		pb.seekingProgrammatically = false
+		pb.setSeekValue(currentProgress)
 		return
 	}
 
@@ -469,11 +598,21 @@ func (pb *PlayerBar) setupSeekBar() {
 	pb.bufferProgress = newBufferBar()
 	pb.bufferProgress.Hide()
 
+	// The waveform becomes the seek control instead of the slider when
+	// waveform seeking is on and the song has volume data
 	pb.waveform = newWaveformBar()
 	pb.waveform.Hide()
 
-	// Order: waveform at bottom, then buffer, then slider on top
-	pb.seekStack = container.NewStack(pb.waveform, pb.bufferProgress, pb.seekBar)
+	pb.seekPreview = newSeekPreview()
+	hoverArea := newSeekHoverArea(pb.showSeekPreview, func() {
+		if !pb.userSeeking {
+			pb.hideSeekPreview()
+		}
+	})
+
+	// Order: waveform at bottom, then buffer, then slider, then the hover
+	// tracker and the preview tooltip layer on top
+	pb.seekStack = container.NewStack(pb.waveform, pb.bufferProgress, pb.seekBar, hoverArea, pb.seekPreview.layer)
 }
 
 func (pb *PlayerBar) topSeekRow() fyne.CanvasObject {
@@ -481,7 +620,10 @@ func (pb *PlayerBar) topSeekRow() fyne.CanvasObject {
 	if pb.seekStack == nil {
 		pb.seekStack = container.NewStack(pb.bufferProgress, pb.seekBar)
 	}
-	return pb.seekStack
+	if pb.seekTint == nil {
+		pb.tintBg, pb.seekTint = newTintLayers(pb.seekStack)
+	}
+	return pb.seekTint
 }
 
 func (pb *PlayerBar) updateBufferProgress() {
@@ -492,8 +634,7 @@ func (pb *PlayerBar) updateBufferProgress() {
 	downloadProgress := pb.player.GetDownloadProgress()
 
 	if downloadProgress < 1.0 && downloadProgress > 0 {
-		pb.bufferProgress.SetValue(downloadProgress)
-		pb.bufferProgress.Show()
+		pb.showBufferProgress(downloadProgress)
 	} else {
 		pb.bufferProgress.Hide()
 	}
@@ -517,8 +658,23 @@ func (pb *PlayerBar) playSong(song *types.Song) {
 		log.Printf("[PLAYER_BAR] Starting playback for: %s", song.Name)
 	}
 
+	pb.reportTrackEnd(false)
+	pb.resetTransition()
+	pb.restoredPending = false
+	if song.Slug != pb.resumeSlug {
+		pb.resumeAt = 0
+	}
+	crossfade := pb.crossfadeNext
+	pb.crossfadeNext = 0
+	casting := pb.casting
+	var castStart time.Duration
+	if casting && song.Slug == pb.resumeSlug {
+		castStart, pb.resumeAt = pb.resumeAt, 0
+	}
+
 	// Reset UI state
 	pb.seekBar.SetValue(0)
+	pb.waveform.SetProgress(0)
 	pb.bufferProgress.SetValue(0)
 	pb.timeLabel.SetText("0:00 / 0:00")
 	pb.lastPosition = 0
@@ -530,7 +686,17 @@ func (pb *PlayerBar) playSong(song *types.Song) {
 		defer pb.setLoading(false)
 
 		ctx := context.Background()
-		if err := pb.player.Play(ctx, song); err != nil {
+		play := pb.player.Play
+		if casting {
+			play = func(ctx context.Context, song *types.Song) error {
+				return pb.castPlay(ctx, song, castStart)
+			}
+		} else if crossfade > 0 {
+			play = func(ctx context.Context, song *types.Song) error {
+				return pb.player.CrossfadeTo(ctx, song, crossfade)
+			}
+		}
+		if err := play(ctx, song); err != nil {
 			log.Printf("[PLAYER_BAR] Failed to play song: %v", err)
 
 			// Try next song if this one fails
@@ -547,8 +713,16 @@ func (pb *PlayerBar) playSong(song *types.Song) {
 			pb.SetCurrentSong(song)
 			pb.isPlaying = true
 			pb.playStartTime = time.Now()
+			pb.endReported = false
 			pb.updatePlayButton()
 
+			if pb.onTrackStarted != nil {
+				pb.onTrackStarted(song, pb.queueIndex, len(pb.queue))
+			}
+			pb.notifyQueue()
+			pb.prefetchUpcoming()
+			pb.maybeExtendRadio()
+
 			if pb.debug {
 				log.Printf("[PLAYER_BAR] Playback started successfully for: %s", song.Name)
 			}
@@ -556,6 +730,75 @@ func (pb *PlayerBar) playSong(song *types.Song) {
 	}()
 }
 
+// updateBufferHealth shows how many seconds of audio are downloaded ahead of
+// the playback position, colored against the configured pre-buffer.
+func (pb *PlayerBar) updateBufferHealth() {
+	if pb.buffering {
+		return
+	}
+
+	ahead, complete := pb.player.BufferHealth()
+	if complete {
+		pb.healthLabel.Hide()
+		return
+	}
+
+	target := 6 * time.Second
+	if pb.cfg != nil && pb.cfg.Audio.PrebufferSeconds > 0 {
+		target = time.Duration(pb.cfg.Audio.PrebufferSeconds) * time.Second
+	}
+
+	// The level is spelled out as well as colored.
+	importance, level := widget.SuccessImportance, ""
+	switch {
+	case ahead < target/2:
+		importance, level = widget.DangerImportance, " (very low)"
+	case ahead < target:
+		importance, level = widget.WarningImportance, " (low)"
+	}
+
+	text := fmt.Sprintf("Buffer %ds%s", int(ahead.Seconds()), level)
+	if pb.healthLabel.Text != text || pb.healthLabel.Importance != importance {
+		pb.healthLabel.Importance = importance
+		pb.healthLabel.SetText(text)
+	}
+	pb.healthLabel.Show()
+}
+
+// SetOutputDevice names the headphones or speaker audio plays on, with
+// details like their battery, next to the buffer health. An empty name
+// hides it.
+func (pb *PlayerBar) SetOutputDevice(name string) {
+	pb.localOutput = name
+	if pb.casting {
+		return
+	}
+	if name == "" {
+		pb.outputLabel.Hide()
+		return
+	}
+	pb.outputLabel.SetText(name)
+	pb.outputLabel.Show()
+}
+
+// setBuffering shows the rebuffering state in place of the buffer health.
+func (pb *PlayerBar) setBuffering(buffering, stalled bool) {
+	pb.buffering = buffering
+	if !buffering {
+		pb.updateBufferHealth()
+		return
+	}
+
+	if stalled {
+		pb.healthLabel.Importance = widget.DangerImportance
+		pb.healthLabel.SetText("Connection stalled, waiting for data...")
+	} else {
+		pb.healthLabel.Importance = widget.WarningImportance
+		pb.healthLabel.SetText("Buffering...")
+	}
+	pb.healthLabel.Show()
+}
+
 // Helper function to ensure proper duration formatting
 func formatDuration(d time.Duration) string {
 	if d <= 0 {
@@ -588,7 +831,7 @@ func volumeIconFor(v float64) fyne.Resource {
 
 func (pb *PlayerBar) togglePlay() {
 	if pb.isPlaying {
-		if err := pb.player.Pause(); err != nil {
+		if err := pb.pausePlayback(); err != nil {
 			log.Printf("[PLAYER_BAR] Pause failed: %v", err)
 			return
 		}
@@ -598,8 +841,10 @@ func (pb *PlayerBar) togglePlay() {
 		if pb.currentSong == nil && len(pb.queue) > 0 {
 			pb.playSong(pb.queue[0])
 			pb.queueIndex = 0
+		} else if pb.restoredPending {
+			pb.playSong(pb.currentSong)
 		} else {
-			if err := pb.player.Resume(); err != nil {
+			if err := pb.resumePlayback(); err != nil {
 				log.Printf("[PLAYER_BAR] Resume failed: %v", err)
 				return
 			}
@@ -610,6 +855,7 @@ func (pb *PlayerBar) togglePlay() {
 }
 
 func (pb *PlayerBar) updatePlayButton() {
+	pb.notifyPlayback()
 	fyne.Do(func() {
 		if pb.isPlaying {
 			pb.playBtn.SetIcon(theme.MediaPauseIcon())
@@ -617,6 +863,9 @@ func (pb *PlayerBar) updatePlayButton() {
 			pb.playBtn.SetIcon(theme.MediaPlayIcon())
 		}
 		pb.playBtn.Refresh()
+		if pb.mini != nil {
+			pb.mini.setPlaying(pb.isPlaying)
+		}
 	})
 }
 
@@ -651,6 +900,7 @@ func (pb *PlayerBar) nextSong() {
 
 	var nextIndex int
 	if pb.isShuffled {
+		pb.pushShuffleHistory()
 		nextIndex = (pb.queueIndex + 1) % len(pb.queue)
 	} else {
 		nextIndex = pb.queueIndex + 1
@@ -666,6 +916,7 @@ func (pb *PlayerBar) nextSong() {
 
 	if nextIndex >= 0 && nextIndex < len(pb.queue) {
 		pb.queueIndex = nextIndex
+		pb.reshuffleCycle()
 		pb.playSong(pb.queue[nextIndex])
 
 		if pb.onNext != nil {
@@ -703,7 +954,11 @@ func (pb *PlayerBar) previousSong() {
 
 	var nextIndex int
 	if pb.isShuffled {
-		nextIndex = (pb.queueIndex - 1 + len(pb.queue)) % len(pb.queue)
+		// Shuffled songs go back the way they came, across reshuffles.
+		nextIndex = pb.popShuffleHistory()
+		if nextIndex < 0 {
+			return
+		}
 	} else {
 		nextIndex = pb.queueIndex - 1
 		if nextIndex < 0 {
@@ -725,7 +980,42 @@ func (pb *PlayerBar) previousSong() {
 	}
 }
 
+// reportTrackEnd notifies listeners that the current track stopped, either
+// because it finished or because the user moved on.
+func (pb *PlayerBar) reportTrackEnd(finished bool) {
+	if pb.currentSong == nil || pb.endReported || pb.playStartTime.IsZero() {
+		return
+	}
+	pb.endReported = true
+
+	played := int(pb.playbackPosition().Seconds())
+	if finished && pb.currentSong.Length > 0 {
+		played = pb.currentSong.Length
+	}
+
+	if pb.onTrackEnded != nil {
+		pb.onTrackEnded(pb.currentSong, played, finished)
+	}
+}
+
 func (pb *PlayerBar) handleSongFinished() {
+	if pb.transitionStarted && pb.transition.Mode != types.TransitionNormal {
+		// Already moved on when the trailing silence or fade started.
+		return
+	}
+	if pb.sleepTimer != nil && pb.sleepTimer.TrackEnded() {
+		pb.sleepAfterTrack()
+		return
+	}
+
+	pb.completeTrack()
+	pb.scheduleNext()
+}
+
+// completeTrack records the current track as finished.
+func (pb *PlayerBar) completeTrack() {
+	pb.reportTrackEnd(true)
+
 	if pb.currentSong != nil {
 		playedDuration := time.Since(pb.playStartTime)
 		if playedDuration >= pb.minPlayDuration {
@@ -733,21 +1023,23 @@ func (pb *PlayerBar) handleSongFinished() {
 		}
 	}
 
-	// Small delay to ensure clean transition
-	time.Sleep(200 * time.Millisecond)
+}
 
-	// Prefetch next song
-	if len(pb.queue) > 0 {
-		next := (pb.queueIndex + 1) % len(pb.queue)
-		if next >= 0 && next < len(pb.queue) && pb.onPrefetchNext != nil {
-			pb.onPrefetchNext(pb.queue[next])
-		}
+// prefetchUpcoming asks for the next tracks of the queue to be downloaded;
+// slower networks prefetch further ahead.
+func (pb *PlayerBar) prefetchUpcoming() {
+	if pb.onPrefetchNext == nil || len(pb.queue) < 2 {
+		return
 	}
 
-	// Move to next song
-	fyne.Do(func() {
-		pb.nextSong()
-	})
+	depth := pb.player.PrefetchDepth()
+	if depth > len(pb.queue)-1 {
+		depth = len(pb.queue) - 1
+	}
+	for i := 1; i <= depth; i++ {
+		next := (pb.queueIndex + i) % len(pb.queue)
+		pb.onPrefetchNext(pb.queue[next])
+	}
 }
 
 // Improved setLoading to prevent UI issues
@@ -773,6 +1065,10 @@ func (pb *PlayerBar) setLoading(loading bool) {
 }
 
 func (pb *PlayerBar) recordPlay(song *types.Song) {
+	if song.IsLocalOnly() || pb.privateMode {
+		return
+	}
+
 	ctx := context.Background()
 	song.Played++
 
@@ -780,10 +1076,6 @@ func (pb *PlayerBar) recordPlay(song *types.Song) {
 		log.Printf("[PLAYER_BAR] Failed to update play count for song %s: %v", song.Name, err)
 	}
 
-	if err := pb.storage.AddPlayHistory(ctx, song.Slug, nil); err != nil {
-		log.Printf("[PLAYER_BAR] Failed to add play history for %s: %v", song.Slug, err)
-	}
-
 	if pb.onPlayed != nil {
 		pb.onPlayed(song)
 	}
@@ -793,11 +1085,20 @@ func (pb *PlayerBar) recordPlay(song *types.Song) {
 
 func (pb *PlayerBar) toggleShuffle() {
 	pb.isShuffled = !pb.isShuffled
+	pb.shuffleHistory = nil
+	if pb.isShuffled {
+		pb.shuffleQueue()
+	} else {
+		pb.unshuffleQueue()
+	}
 	pb.updateShuffleButton()
 
 	if pb.onShuffle != nil {
 		pb.onShuffle(pb.isShuffled)
 	}
+	pb.notifyQueue()
+	pb.prefetchUpcoming()
+	pb.maybeExtendRadio()
 }
 
 func (pb *PlayerBar) toggleRepeat() {
@@ -815,6 +1116,8 @@ func (pb *PlayerBar) toggleRepeat() {
 	if pb.onRepeat != nil {
 		pb.onRepeat(pb.repeatMode)
 	}
+	pb.notifyQueue()
+	pb.maybeExtendRadio()
 }
 
 func (pb *PlayerBar) toggleLike() {
@@ -822,17 +1125,76 @@ func (pb *PlayerBar) toggleLike() {
 		return
 	}
 
-	liked := pb.currentSong.Liked == nil || !*pb.currentSong.Liked
-	pb.currentSong.Liked = &liked
+	song := pb.currentSong
+	liked := song.Liked == nil || !*song.Liked
+	previous := song.Liked
+	song.Liked = &liked
+	pb.updateLikeButton()
 
-	go func() {
-		ctx := context.Background()
-		if err := pb.storage.SaveSong(ctx, pb.currentSong); err != nil {
-			log.Printf("[PLAYER_BAR] Failed to update like status: %v", err)
+	save := func() {
+		go func() {
+			ctx := context.Background()
+			if err := pb.storage.SaveLike(ctx, song); err != nil {
+				log.Printf("[PLAYER_BAR] Failed to update like status: %v", err)
+			}
+		}()
+	}
+	if liked || pb.undoBar == nil {
+		save()
+		return
+	}
+
+	pb.undoBar.Defer("Removed "+song.Name+" from liked songs", save, func() {
+		song.Liked = previous
+		pb.updateLikeButton()
+	})
+}
+
+func (pb *PlayerBar) toggleKaraoke() {
+	enabled := !pb.player.IsKaraokeEnabled()
+	pb.player.SetKaraoke(enabled)
+	pb.updateKaraokeButton()
+
+	if pb.cfg != nil {
+		pb.cfg.Audio.Karaoke = enabled
+		if err := pb.cfg.Save(); err != nil {
+			log.Printf("[PLAYER_BAR] Failed to save karaoke setting: %v", err)
 		}
-	}()
+	}
+}
 
-	pb.updateLikeButton()
+func (pb *PlayerBar) updateKaraokeButton() {
+	setToggled(pb.karaokeBtn, pb.player.IsKaraokeEnabled())
+}
+
+// setToggled shows whether a toggle button is on with a check mark as well
+// as its importance, so the state reads without telling colors apart.
+func setToggled(btn *widget.Button, on bool) {
+	if on {
+		btn.Icon = theme.ConfirmIcon()
+		btn.Importance = widget.HighImportance
+	} else {
+		btn.Icon = nil
+		btn.Importance = widget.LowImportance
+	}
+	btn.Refresh()
+}
+
+// togglePrivateMode switches private listening, in which plays are neither
+// counted nor reported. It lasts until it is switched off or the app quits.
+func (pb *PlayerBar) togglePrivateMode() {
+	pb.SetPrivateMode(!pb.privateMode)
+}
+
+func (pb *PlayerBar) updatePrivateButton() {
+	if pb.privateMode {
+		pb.privateBtn.SetIcon(theme.VisibilityOffIcon())
+		pb.privateBtn.Importance = widget.HighImportance
+	} else {
+		pb.privateBtn.SetIcon(theme.VisibilityIcon())
+		pb.privateBtn.Importance = widget.LowImportance
+	}
+	pb.privateBtn.Refresh()
 }
 
 func (pb *PlayerBar) updateShuffleButton() {
@@ -867,11 +1229,11 @@ func (pb *PlayerBar) updateRepeatButton() {
 
 func (pb *PlayerBar) updateLikeButton() {
 	fyne.Do(func() {
-		if pb.currentSong != nil && pb.currentSong.Liked != nil && *pb.currentSong.Liked {
-			pb.likeBtn.SetIcon(theme.ConfirmIcon())
+		liked := pb.currentSong != nil && pb.currentSong.Liked != nil && *pb.currentSong.Liked
+		pb.likeBtn.SetIcon(themes.LikeIcon(liked))
+		if liked {
 			pb.likeBtn.Importance = widget.MediumImportance
 		} else {
-			pb.likeBtn.SetIcon(theme.VisibilityOffIcon())
 			pb.likeBtn.Importance = widget.LowImportance
 		}
 		pb.likeBtn.Refresh()
@@ -879,6 +1241,9 @@ func (pb *PlayerBar) updateLikeButton() {
 }
 
 func (pb *PlayerBar) onVolumeChange(v float64) {
+	if v > 0 {
+		pb.mutedLevel = 0
+	}
 	if err := pb.player.SetVolume(v / 100); err != nil {
 		log.Printf("[PLAYER_BAR] Failed to set volume: %v", err)
 	}
@@ -895,15 +1260,23 @@ func (pb *PlayerBar) showVolumeDialog() {
 	if !pb.compactMode || pb.parentWindow == nil {
 		return
 	}
-	volumeSlider := widget.NewSlider(0, 100)
+	var volumeSlider *volumeSlider
+	volumeLabel := widget.NewLabel(pb.volumeLabelText())
+	volumeSlider = newVolumeSlider(func(notches float64) {
+		pb.scrollVolume(notches)
+		volumeSlider.SetValue(pb.volumeBar.Value)
+	})
 	volumeSlider.SetValue(pb.volumeBar.Value)
-	volumeLabel := widget.NewLabel(fmt.Sprintf("Volume: %.0f%%", pb.volumeBar.Value))
 	volumeSlider.OnChanged = func(value float64) {
 		pb.volumeBar.SetValue(value)
-		pb.onVolumeChange(value)
-		volumeLabel.SetText(fmt.Sprintf("Volume: %.0f%%", value))
+		volumeLabel.SetText(pb.volumeLabelText())
 	}
-	content := container.NewVBox(volumeLabel, volumeSlider)
+	muteBtn := widget.NewButtonWithIcon("Mute", theme.VolumeMuteIcon(), func() {
+		pb.toggleMute()
+		volumeSlider.SetValue(pb.volumeBar.Value)
+		volumeLabel.SetText(pb.volumeLabelText())
+	})
+	content := container.NewVBox(volumeLabel, volumeSlider, muteBtn)
 	pb.volumeDialog = dialog.NewCustom("Volume", "Close", content, pb.parentWindow)
 	pb.volumeDialog.Resize(fyne.NewSize(300, 150))
 	pb.volumeDialog.Show()
@@ -948,8 +1321,7 @@ func (pb *PlayerBar) watchBufferUntilReady() {
 					pb.loadingLabel.Show()
 				}
 				if pb.bufferProgress != nil {
-					pb.bufferProgress.SetValue(p)
-					pb.bufferProgress.Show()
+					pb.showBufferProgress(p)
 				}
 			})
 		}
@@ -967,21 +1339,28 @@ func (pb *PlayerBar) watchBufferUntilReady() {
 }
 
 func (pb *PlayerBar) stop() {
+	pb.reportTrackEnd(false)
+	if pb.casting {
+		pb.castCommand("stop", pb.caster.Stop)
+	}
 	if err := pb.player.Stop(); err != nil {
 		log.Printf("[PLAYER_BAR] Failed to stop: %v", err)
 	}
 	fyne.Do(func() {
 		pb.playBtn.SetIcon(theme.MediaPlayIcon())
 		pb.seekBar.SetValue(0)
+		pb.waveform.SetProgress(0)
 		pb.bufferProgress.Hide()
 	})
 	pb.isPlaying = false
 	pb.stopLoadingTicker()
 	pb.loading = false
+	pb.notifyPlayback()
 }
 
 func (pb *PlayerBar) SetCurrentSong(song *types.Song) {
 	pb.currentSong = song
+	pb.notifyPlayback()
 	fyne.Do(func() {
 		if song != nil {
 			pb.songLabel.SetText(song.Name)
@@ -991,6 +1370,9 @@ func (pb *PlayerBar) SetCurrentSong(song *types.Song) {
 			pb.songLabel.SetText("No song playing")
 			pb.artistLabel.SetText("")
 		}
+		if pb.mini != nil {
+			pb.mini.setSong(song)
+		}
 
 		var target fyne.Size
 		if pb.compactMode {
@@ -1005,15 +1387,26 @@ func (pb *PlayerBar) SetCurrentSong(song *types.Song) {
 		pb.coverImg.SetMinSize(target)
 		pb.coverImg.Resize(target)
 
-		url := pb.imageService.PreferredCoverURL(song)
+		// A cover still loading for the previous song must not replace this one.
+		if pb.coverCancel != nil {
+			pb.coverCancel()
+		}
+
+		url := pb.imageService.SongCoverURL(song, target)
 		if pb.imageService == nil || url == "" {
 			pb.coverImg.Resource = theme.MediaMusicIcon()
 			pb.coverImg.Refresh()
+			pb.clearTint()
 			return
 		}
-		pb.imageService.GetImageWithSize(url, target, func(res fyne.Resource, err error) {
+		var ctx context.Context
+		ctx, pb.coverCancel = context.WithCancel(context.Background())
+		pb.imageService.GetImageWithSize(ctx, url, target, func(res fyne.Resource, err error) {
 			if err != nil || res == nil {
 				res = theme.MediaMusicIcon()
+				pb.clearTint()
+			} else {
+				pb.applyCoverTint(song, url, res)
 			}
 			pb.coverImg.Resource = res
 			pb.coverImg.Refresh()
@@ -1028,13 +1421,11 @@ func (pb *PlayerBar) setWaveformFromSong(song *types.Song) {
 	if pb.waveform == nil {
 		return
 	}
-	if song == nil || len(song.Volume) == 0 {
-		pb.waveform.Clear()
-		pb.waveform.Hide()
+	if song == nil {
+		pb.SetWaveform(nil)
 		return
 	}
-	pb.waveform.SetDataInt(song.Volume)
-	pb.waveform.Show()
+	pb.SetWaveform(song.Volume)
 }
 
 func (pb *PlayerBar) SetWaveform(vol []int) {
@@ -1044,23 +1435,26 @@ func (pb *PlayerBar) SetWaveform(vol []int) {
 	if len(vol) == 0 {
 		pb.waveform.Clear()
 		pb.waveform.Hide()
-		return
+	} else {
+		pb.waveform.SetDataInt(vol)
+		pb.waveform.Show()
 	}
-	pb.waveform.SetDataInt(vol)
-	pb.waveform.Show()
+	pb.applySeekMode()
 }
 
 func (pb *PlayerBar) SetQueue(songs []*types.Song, startIndex int) {
 	pb.queue = songs
 	pb.queueIndex = startIndex
-
-	if startIndex >= 0 && startIndex < len(songs) {
-		pb.playSong(songs[startIndex])
+	pb.unshuffled = nil
+	pb.shuffleHistory = nil
+	if pb.isShuffled {
+		pb.shuffleQueue()
 	}
-}
+	pb.notifyQueue()
 
-func (pb *PlayerBar) AddToQueue(song *types.Song) {
-	pb.queue = append(pb.queue, song)
+	if pb.queueIndex >= 0 && pb.queueIndex < len(pb.queue) {
+		pb.playSong(pb.queue[pb.queueIndex])
+	}
 }
 
 func (pb *PlayerBar) GetQueue() []*types.Song {
@@ -1103,31 +1497,103 @@ func (pb *PlayerBar) Container() *fyne.Container {
 	return pb.container
 }
 
-func getArtistNames(authors []*types.Author) string {
-	if len(authors) == 0 {
-		return "Unknown Artist"
+// CurrentSong returns the song shown in the bar, or nil.
+func (pb *PlayerBar) CurrentSong() *types.Song {
+	return pb.currentSong
+}
+
+// IsPlaying reports whether the bar is playing rather than paused or stopped.
+func (pb *PlayerBar) IsPlaying() bool {
+	return pb.isPlaying
+}
+
+// TogglePlay pauses or resumes playback, starting the queue if nothing has
+// played yet.
+func (pb *PlayerBar) TogglePlay() {
+	pb.togglePlay()
+}
+
+// Play resumes playback unless it is already playing.
+func (pb *PlayerBar) Play() {
+	if !pb.isPlaying {
+		pb.togglePlay()
 	}
+}
 
-	names := make([]string, 0, len(authors))
-	for _, author := range authors {
-		if author != nil && author.Name != "" {
-			names = append(names, author.Name)
-		}
+// Pause pauses playback if it is playing.
+func (pb *PlayerBar) Pause() {
+	if pb.isPlaying {
+		pb.togglePlay()
 	}
+}
+
+func (pb *PlayerBar) Stop() {
+	pb.stop()
+}
 
-	if len(names) == 0 {
-		return "Unknown Artist"
+func (pb *PlayerBar) Next() {
+	pb.nextSong()
+}
+
+func (pb *PlayerBar) Previous() {
+	pb.previousSong()
+}
+
+// SeekTo seeks the current track, clamped to the part that can be reached,
+// and returns the position it went to.
+func (pb *PlayerBar) SeekTo(pos time.Duration) (time.Duration, error) {
+	if pb.casting {
+		pos = max(0, pos)
+		pb.castSeek(pos)
+		return pos, nil
+	}
+	if !pb.player.CanSeek() {
+		return 0, fmt.Errorf("seeking not available for this track")
 	}
+	minSeek, maxSeek := pb.player.GetSeekableRange()
+	pos = max(minSeek, min(pos, maxSeek))
+	if err := pb.player.Seek(pos); err != nil {
+		return 0, err
+	}
+	return pos, nil
+}
+
+// OnPlaybackChanged is called with the current song and whether it plays
+// whenever either changes.
+func (pb *PlayerBar) OnPlaybackChanged(cb func(*types.Song, bool)) { pb.onPlaybackChanged = cb }
 
-	if len(names) == 1 {
-		return names[0]
+// OnPositionChanged is called on the UI goroutine with the current song and
+// the playback position whenever the position moves.
+func (pb *PlayerBar) OnPositionChanged(cb func(*types.Song, time.Duration)) { pb.onPosition = cb }
+
+func (pb *PlayerBar) OnPrivateModeChanged(cb func(bool)) { pb.onPrivateMode = cb }
+
+// PrivateMode reports whether private listening is on.
+func (pb *PlayerBar) PrivateMode() bool { return pb.privateMode }
+
+// SetPrivateMode turns private listening on or off and tells the listener.
+func (pb *PlayerBar) SetPrivateMode(enabled bool) {
+	if pb.privateMode == enabled {
+		return
+	}
+	pb.privateMode = enabled
+	pb.updatePrivateButton()
+	if pb.onPrivateMode != nil {
+		pb.onPrivateMode(enabled)
 	}
+}
 
-	if len(names) == 2 {
-		return names[0] + " & " + names[1]
+func (pb *PlayerBar) notifyPlayback() {
+	if pb.onPlaybackChanged != nil {
+		pb.onPlaybackChanged(pb.currentSong, pb.isPlaying)
 	}
+}
 
-	return strings.Join(names, ", ")
+func getArtistNames(authors []*types.Author) string {
+	if label := types.CreditsLabel(authors); label != "" {
+		return label
+	}
+	return "Unknown Artist"
 }
 
 func (pb *PlayerBar) startLoadingTicker() {
@@ -1165,3 +1631,10 @@ func (pb *PlayerBar) closeAndHide() {
 
 func (pb *PlayerBar) OnPlayed(cb func(*types.Song))       { pb.onPlayed = cb }
 func (pb *PlayerBar) OnPrefetchNext(cb func(*types.Song)) { pb.onPrefetchNext = cb }
+
+// OnTrackStarted is called with the song, its queue index and the queue length
+// once playback of a track has actually begun.
+func (pb *PlayerBar) OnTrackStarted(cb func(*types.Song, int, int)) { pb.onTrackStarted = cb }
+
+// OnTrackEnded is called with the seconds listened when a track finishes or is skipped.
+func (pb *PlayerBar) OnTrackEnded(cb func(*types.Song, int, bool)) { pb.onTrackEnded = cb }
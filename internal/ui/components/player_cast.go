@@ -0,0 +1,331 @@
+package components
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/integrations/cast"
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	// castPollInterval is how often the renderer is asked where it is.
+	castPollInterval = time.Second
+	// castTimeout limits each command sent to the renderer.
+	castTimeout = 10 * time.Second
+)
+
+// SetCaster sets what plays songs on network renderers. Without one the
+// cast button is hidden.
+func (pb *PlayerBar) SetCaster(caster *services.CastService) {
+	pb.caster = caster
+	if caster == nil {
+		pb.castBtn.Hide()
+	} else {
+		pb.castBtn.Show()
+	}
+}
+
+// Casting reports whether songs play on a network renderer.
+func (pb *PlayerBar) Casting() bool { return pb.casting }
+
+func (pb *PlayerBar) onCastTapped() {
+	if pb.parentWindow == nil || pb.caster == nil {
+		return
+	}
+	if pb.casting {
+		name := "the renderer"
+		if device := pb.caster.Device(); device != nil {
+			name = device.Name
+		}
+		dialog.ShowConfirm("Stop Casting",
+			fmt.Sprintf("Stop playing on %s and go on here?", name),
+			func(ok bool) {
+				if ok {
+					pb.stopCasting()
+				}
+			}, pb.parentWindow)
+		return
+	}
+	pb.showCastDialog()
+}
+
+// showCastDialog searches the network and lists the renderers found.
+func (pb *PlayerBar) showCastDialog() {
+	body := container.NewVBox()
+	d := dialog.NewCustom("Cast To", "Cancel", container.NewVScroll(body), pb.parentWindow)
+	d.Resize(fyne.NewSize(360, 320))
+
+	var search func()
+	search = func() {
+		progress := widget.NewProgressBarInfinite()
+		body.Objects = []fyne.CanvasObject{widget.NewLabel("Looking for Chromecast and DLNA devices…"), progress}
+		body.Refresh()
+
+		go func() {
+			devices, err := pb.caster.Discover(context.Background())
+			fyne.Do(func() {
+				progress.Stop()
+				switch {
+				case err != nil:
+					body.Objects = []fyne.CanvasObject{widget.NewLabel(fmt.Sprintf("Could not search the network: %v", err))}
+				case len(devices) == 0:
+					body.Objects = []fyne.CanvasObject{widget.NewLabel("No devices found on this network.")}
+				default:
+					body.Objects = nil
+					for _, device := range devices {
+						label := fmt.Sprintf("%s (%s)", device.Name, castKindLabel(device.Kind))
+						btn := widget.NewButton(label, func() {
+							d.Hide()
+							pb.startCasting(device)
+						})
+						btn.Alignment = widget.ButtonAlignLeading
+						body.Objects = append(body.Objects, btn)
+					}
+				}
+				body.Objects = append(body.Objects, widget.NewButton("Search Again", search))
+				body.Refresh()
+			})
+		}()
+	}
+
+	search()
+	d.Show()
+}
+
+func castKindLabel(kind cast.Kind) string {
+	if kind == cast.KindChromecast {
+		return "Chromecast"
+	}
+	return "DLNA"
+}
+
+// startCasting connects to device and moves the current song there, from
+// where it is.
+func (pb *PlayerBar) startCasting(device cast.Device) {
+	pb.showTemporaryMessage(fmt.Sprintf("Connecting to %s…", device.Name))
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), castTimeout)
+		defer cancel()
+		if err := pb.caster.Connect(ctx, device); err != nil {
+			log.Printf("[PLAYER_BAR] Failed to cast to %s: %v", device.Name, err)
+			fyne.Do(func() {
+				dialog.ShowError(fmt.Errorf("could not connect to %s: %w", device.Name, err), pb.parentWindow)
+			})
+			return
+		}
+
+		fyne.Do(func() {
+			playing := pb.isPlaying
+			if playing {
+				if err := pb.player.Pause(); err != nil {
+					log.Printf("[PLAYER_BAR] Pause failed: %v", err)
+				}
+			}
+
+			pb.casting = true
+			setToggled(pb.castBtn, true)
+			pb.outputLabel.SetText("Casting to " + device.Name)
+			pb.outputLabel.Show()
+			pb.startCastPoll()
+
+			if song := pb.currentSong; song != nil && playing {
+				pb.resumeSlug, pb.resumeAt = song.Slug, pb.lastPosition
+				pb.playSong(song)
+			} else if song != nil {
+				// Picked up from here once play is pressed.
+				pb.resumeSlug, pb.resumeAt = song.Slug, pb.lastPosition
+				pb.restoredPending = true
+			}
+		})
+	}()
+}
+
+// stopCasting ends the session and goes on playing here from where the
+// renderer was.
+func (pb *PlayerBar) stopCasting() {
+	if !pb.casting {
+		return
+	}
+	pb.casting = false
+	pb.stopCastPoll()
+	setToggled(pb.castBtn, false)
+	pb.SetOutputDevice(pb.localOutput)
+
+	caster := pb.caster
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), castTimeout)
+		defer cancel()
+		caster.Disconnect(ctx)
+	}()
+
+	song := pb.currentSong
+	if song == nil {
+		return
+	}
+	pb.resumeSlug, pb.resumeAt = song.Slug, pb.lastPosition
+	if pb.isPlaying {
+		pb.playSong(song)
+	} else {
+		pb.restoredPending = true
+	}
+}
+
+// castPlay plays song on the renderer, from where playback was restored or
+// casting began when that was this song.
+func (pb *PlayerBar) castPlay(ctx context.Context, song *types.Song, start time.Duration) error {
+	if err := pb.player.Pause(); err != nil && pb.debug {
+		log.Printf("[PLAYER_BAR] Pause before casting failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, castTimeout)
+	defer cancel()
+	return pb.caster.Play(ctx, song, pb.player.SongFile(song), start)
+}
+
+// castCommand sends a command to the renderer without holding up the UI.
+func (pb *PlayerBar) castCommand(name string, command func(context.Context) error) {
+	pb.castSent = time.Now()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), castTimeout)
+		defer cancel()
+		if err := command(ctx); err != nil {
+			log.Printf("[PLAYER_BAR] Cast %s failed: %v", name, err)
+			fyne.Do(func() { pb.showTemporaryMessage(fmt.Sprintf("Cast %s failed", name)) })
+		}
+	}()
+}
+
+// pausePlayback pauses the speakers or the renderer cast to.
+func (pb *PlayerBar) pausePlayback() error {
+	if pb.casting {
+		pb.castCommand("pause", pb.caster.Pause)
+		return nil
+	}
+	return pb.player.Pause()
+}
+
+// resumePlayback resumes the speakers or the renderer cast to.
+func (pb *PlayerBar) resumePlayback() error {
+	if pb.casting {
+		pb.castCommand("resume", pb.caster.Resume)
+		return nil
+	}
+	return pb.player.Resume()
+}
+
+// castSeek moves the renderer to pos.
+func (pb *PlayerBar) castSeek(pos time.Duration) {
+	pb.castCommand("seek", func(ctx context.Context) error {
+		return pb.caster.Seek(ctx, pos)
+	})
+	pb.lastPosition = pos
+	pb.timeLabel.SetText(fmt.Sprintf("%s / %s", formatDuration(pos), formatDuration(pb.lastDuration)))
+}
+
+// playbackPosition is how far into the current song playback is.
+func (pb *PlayerBar) playbackPosition() time.Duration {
+	if pb.casting {
+		return pb.lastPosition
+	}
+	return pb.player.GetPosition()
+}
+
+func (pb *PlayerBar) startCastPoll() {
+	pb.stopCastPoll()
+	stop := make(chan struct{})
+	pb.castStop = stop
+
+	go func() {
+		ticker := time.NewTicker(castPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), castTimeout)
+			status, err := pb.caster.Status(ctx)
+			cancel()
+			fyne.Do(func() {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err != nil {
+					log.Printf("[PLAYER_BAR] Lost the cast session: %v", err)
+					pb.stopCasting()
+					pb.showTemporaryMessage("Casting ended")
+					return
+				}
+				pb.showCastStatus(status)
+			})
+		}
+	}()
+}
+
+func (pb *PlayerBar) stopCastPoll() {
+	if pb.castStop != nil {
+		close(pb.castStop)
+		pb.castStop = nil
+	}
+}
+
+// showCastStatus moves the seek bar along with the renderer and goes on to
+// the next song once it finished one.
+func (pb *PlayerBar) showCastStatus(status cast.Status) {
+	if status.State == cast.StateFinished {
+		pb.handleSongFinished()
+		return
+	}
+	if pb.userSeeking || pb.loading || pb.currentSong == nil {
+		return
+	}
+
+	pos, dur := status.Position, status.Duration
+	if dur <= 0 {
+		dur = time.Duration(pb.currentSong.Length) * time.Second
+	}
+	pb.lastPosition, pb.lastDuration = pos, dur
+
+	if dur > 0 {
+		pb.setSeekValue(max(0, min(100, float64(pos)/float64(dur)*100)))
+		pb.timeLabel.SetText(fmt.Sprintf("%s / %s", formatDuration(pos), formatDuration(dur)))
+	} else {
+		pb.timeLabel.SetText(fmt.Sprintf("%s / --:--", formatDuration(pos)))
+	}
+	if pb.mini != nil {
+		pb.mini.setProgress(pos, dur)
+	}
+	if pb.onPosition != nil {
+		pb.onPosition(pb.currentSong, pos)
+	}
+
+	// The renderer may not have taken the last command yet.
+	if time.Since(pb.castSent) < 2*castPollInterval {
+		return
+	}
+	switch status.State {
+	case cast.StatePlaying:
+		if !pb.isPlaying {
+			pb.isPlaying = true
+			pb.updatePlayButton()
+		}
+	case cast.StatePaused:
+		if pb.isPlaying {
+			pb.isPlaying = false
+			pb.updatePlayButton()
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package components
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio"
+)
+
+// sleepDurations are the sleep timer lengths offered in the menu.
+var sleepDurations = []time.Duration{
+	15 * time.Minute,
+	30 * time.Minute,
+	45 * time.Minute,
+	time.Hour,
+	90 * time.Minute,
+}
+
+// SetSleepTimer sets the timer the overflow menu controls. When it runs out
+// the player bar shows playback as paused.
+func (pb *PlayerBar) SetSleepTimer(timer *audio.SleepTimer) {
+	pb.sleepTimer = timer
+	timer.OnExpired(func() { fyne.Do(pb.Pause) })
+}
+
+// showMoreMenu pops up the less used controls under the overflow button.
+func (pb *PlayerBar) showMoreMenu() {
+	if pb.parentWindow == nil {
+		return
+	}
+
+	mini := fyne.NewMenuItem("Mini Player", pb.ToggleMiniPlayer)
+	mini.Checked = pb.mini != nil
+
+	visualizer := fyne.NewMenuItem("Visualizer", pb.toggleVisualizer)
+	visualizer.Checked = pb.visualizer.Visible()
+
+	items := []*fyne.MenuItem{mini, visualizer}
+	if pb.sleepTimer != nil {
+		sleep := fyne.NewMenuItem("Sleep Timer", nil)
+		sleep.ChildMenu = fyne.NewMenu("", pb.sleepMenuItems()...)
+		items = append(items, sleep)
+	}
+
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(pb.moreBtn)
+	pos = pos.AddXY(0, pb.moreBtn.Size().Height)
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), pb.parentWindow.Canvas(), pos)
+}
+
+func (pb *PlayerBar) sleepMenuItems() []*fyne.MenuItem {
+	state := pb.sleepTimer.State()
+
+	var items []*fyne.MenuItem
+	for _, d := range sleepDurations {
+		items = append(items, fyne.NewMenuItem(fmt.Sprintf("%d Minutes", int(d.Minutes())), func() {
+			pb.sleepTimer.Start(d, pb.sleepFade)
+		}))
+	}
+
+	endOfTrack := fyne.NewMenuItem("End of Current Track", pb.sleepTimer.StopAfterTrack)
+	endOfTrack.Checked = state.EndOfTrack
+	endOfTrack.Disabled = pb.currentSong == nil
+
+	fade := fyne.NewMenuItem("Fade Out", func() { pb.sleepFade = !pb.sleepFade })
+	fade.Checked = pb.sleepFade
+
+	cancel := fyne.NewMenuItem("Cancel Timer", pb.sleepTimer.Cancel)
+	cancel.Disabled = !state.Active
+
+	return append(items, endOfTrack, fyne.NewMenuItemSeparator(), fade, cancel)
+}
+
+// sleepAfterTrack stops at the end of a track for the sleep timer, with the
+// track that would have followed ready to play.
+func (pb *PlayerBar) sleepAfterTrack() {
+	pb.completeTrack()
+	pb.stop()
+	if pb.repeatMode != RepeatOne && pb.hasNextSong() {
+		pb.queueIndex = (pb.queueIndex + 1) % len(pb.queue)
+		pb.SetCurrentSong(pb.queue[pb.queueIndex])
+	}
+	pb.restoredPending = true
+}
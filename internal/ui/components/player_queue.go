@@ -0,0 +1,203 @@
+package components
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// resumeGiveUp is how far into a restored song playback may get while its
+// saved position still can't be reached before it stops trying to seek.
+const resumeGiveUp = 15 * time.Second
+
+// OnQueueChanged is called when the queue, the current entry, or the shuffle
+// or repeat mode change.
+func (pb *PlayerBar) OnQueueChanged(cb func()) { pb.onQueueChanged = cb }
+
+func (pb *PlayerBar) notifyQueue() {
+	pb.updateUpNext()
+	if pb.onQueueChanged != nil {
+		pb.onQueueChanged()
+	}
+}
+
+// PlaybackQueue returns a copy of the queue and where playback is in it.
+func (pb *PlayerBar) PlaybackQueue() *types.PlaybackQueue {
+	songs := make([]*types.Song, len(pb.queue))
+	copy(songs, pb.queue)
+	return &types.PlaybackQueue{
+		Songs:    songs,
+		Index:    pb.queueIndex,
+		Position: pb.lastPosition,
+		Shuffle:  pb.isShuffled,
+		Repeat:   int(pb.repeatMode),
+	}
+}
+
+// PlayQueueEntry plays the queue entry at index.
+func (pb *PlayerBar) PlayQueueEntry(index int) {
+	pb.jumpTo(index)
+}
+
+// InsertNext queues songs to play right after the current song, or plays
+// them when nothing is queued.
+func (pb *PlayerBar) InsertNext(songs ...*types.Song) {
+	if len(songs) == 0 {
+		return
+	}
+	if pb.queueIndex < 0 || pb.queueIndex >= len(pb.queue) {
+		pb.SetQueue(slices.Clone(songs), 0)
+		return
+	}
+
+	// The queue may share its array with the list it was played from.
+	pb.queue = slices.Insert(slices.Clone(pb.queue), pb.queueIndex+1, songs...)
+	if pb.unshuffled != nil {
+		at := slices.Index(pb.unshuffled, pb.currentSong) + 1
+		pb.unshuffled = slices.Insert(slices.Clone(pb.unshuffled), at, songs...)
+	}
+
+	if pb.debug {
+		log.Printf("[PLAYER_BAR] Queued %d songs to play next", len(songs))
+	}
+	pb.notifyQueue()
+	pb.prefetchUpcoming()
+}
+
+// AddToQueue queues songs after the rest of the queue, or plays them when
+// nothing is queued.
+func (pb *PlayerBar) AddToQueue(songs ...*types.Song) {
+	if len(songs) == 0 {
+		return
+	}
+	if pb.queueIndex < 0 || pb.queueIndex >= len(pb.queue) {
+		pb.SetQueue(slices.Clone(songs), 0)
+		return
+	}
+
+	pb.queue = append(pb.queue[:len(pb.queue):len(pb.queue)], songs...)
+	if pb.unshuffled != nil {
+		pb.unshuffled = append(pb.unshuffled[:len(pb.unshuffled):len(pb.unshuffled)], songs...)
+	}
+
+	if pb.debug {
+		log.Printf("[PLAYER_BAR] Added %d songs to the queue", len(songs))
+	}
+	pb.notifyQueue()
+	pb.prefetchUpcoming()
+}
+
+// MoveQueueEntry moves the queue entry at from to index to. The current song
+// stays current wherever it ends up.
+func (pb *PlayerBar) MoveQueueEntry(from, to int) {
+	if from == to || from < 0 || to < 0 || from >= len(pb.queue) || to >= len(pb.queue) {
+		return
+	}
+
+	// The queue may share its array with the list it was played from.
+	queue := slices.Clone(pb.queue)
+	song := queue[from]
+	queue = slices.Insert(slices.Delete(queue, from, from+1), to, song)
+	pb.queue = queue
+
+	switch {
+	case from == pb.queueIndex:
+		pb.queueIndex = to
+	case from < pb.queueIndex && to >= pb.queueIndex:
+		pb.queueIndex--
+	case from > pb.queueIndex && to <= pb.queueIndex:
+		pb.queueIndex++
+	}
+
+	pb.notifyQueue()
+	pb.prefetchUpcoming()
+}
+
+// RemoveQueueEntry drops the queue entry at index, with an undo where the
+// player bar has an undo bar. The current song can't be removed.
+func (pb *PlayerBar) RemoveQueueEntry(index int) {
+	if index < 0 || index >= len(pb.queue) || index == pb.queueIndex {
+		return
+	}
+
+	removed := pb.queue[index]
+	pb.queue = append(pb.queue[:index:index], pb.queue[index+1:]...)
+	if index < pb.queueIndex {
+		pb.queueIndex--
+	}
+
+	if pb.debug {
+		log.Printf("[PLAYER_BAR] Removed %s from the queue", removed.Name)
+	}
+	pb.notifyQueue()
+	pb.prefetchUpcoming()
+
+	if pb.undoBar != nil {
+		pb.undoBar.Defer("Removed "+removed.Name+" from the queue", nil, func() {
+			pb.restoreToQueue(removed, index)
+		})
+	}
+}
+
+// RestoreQueue brings back a queue saved in an earlier run. The current song
+// is shown paused; pressing play starts it where it was left. Nothing is
+// restored once something else has started playing.
+func (pb *PlayerBar) RestoreQueue(queue *types.PlaybackQueue) {
+	if queue == nil || len(queue.Songs) == 0 || pb.currentSong != nil {
+		return
+	}
+
+	index := max(0, min(queue.Index, len(queue.Songs)-1))
+	song := queue.Songs[index]
+
+	pb.queue = queue.Songs
+	pb.queueIndex = index
+	pb.unshuffled, pb.shuffleHistory = nil, nil
+	pb.isShuffled = queue.Shuffle
+	pb.repeatMode = RepeatMode(queue.Repeat)
+	pb.updateShuffleButton()
+	pb.updateRepeatButton()
+	pb.updateUpNext()
+
+	pb.restoredPending = true
+	pb.resumeSlug, pb.resumeAt = song.Slug, queue.Position
+	pb.lastPosition = queue.Position
+	pb.SetCurrentSong(song)
+
+	length := time.Duration(song.Length) * time.Second
+	if length > 0 {
+		pb.seekingProgrammatically = true
+		pb.seekBar.SetValue(min(100, float64(queue.Position)/float64(length)*100))
+		pb.seekingProgrammatically = false
+		pb.timeLabel.SetText(fmt.Sprintf("%s / %s", formatDuration(queue.Position), formatDuration(length)))
+	}
+
+	if pb.debug {
+		log.Printf("[PLAYER_BAR] Restored queue of %d songs at %s (%v)", len(pb.queue), song.Name, queue.Position)
+	}
+}
+
+// resumeRestoredPosition seeks a restored song to where it was left once
+// that part can be reached. Streams that don't get there early on just play
+// from where they are.
+func (pb *PlayerBar) resumeRestoredPosition(pos time.Duration) {
+	if pb.currentSong == nil || pb.currentSong.Slug != pb.resumeSlug || !pb.isPlaying {
+		return
+	}
+	if pb.player.CanSeek() {
+		if _, maxSeek := pb.player.GetSeekableRange(); maxSeek >= pb.resumeAt {
+			target := pb.resumeAt
+			pb.resumeAt = 0
+			if err := pb.player.Seek(target); err != nil {
+				log.Printf("[PLAYER_BAR] Failed to resume at %v: %v", target, err)
+			}
+			return
+		}
+	}
+	if pos > resumeGiveUp {
+		pb.resumeAt = 0
+	}
+}
@@ -0,0 +1,80 @@
+package components
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// RadioSource picks songs to queue after seed, leaving out the queued ones.
+type RadioSource func(seed *types.Song, queued []*types.Song) []*types.Song
+
+// OnRadio sets where radio mode gets the songs it queues.
+func (pb *PlayerBar) OnRadio(source RadioSource) { pb.radioSource = source }
+
+// RadioMode reports whether the queue is kept going with related songs
+// instead of stopping after its last one.
+func (pb *PlayerBar) RadioMode() bool { return pb.radioMode }
+
+// SetRadioMode switches radio mode and remembers it for the next start.
+func (pb *PlayerBar) SetRadioMode(enabled bool) {
+	pb.radioMode = enabled
+	pb.updateRadioButton()
+
+	if pb.cfg != nil && pb.cfg.Audio.Radio != enabled {
+		pb.cfg.Audio.Radio = enabled
+		if err := pb.cfg.Save(); err != nil {
+			log.Printf("[PLAYER_BAR] Failed to save radio setting: %v", err)
+		}
+	}
+	pb.notifyQueue()
+	pb.maybeExtendRadio()
+}
+
+func (pb *PlayerBar) toggleRadio() {
+	pb.SetRadioMode(!pb.radioMode)
+}
+
+func (pb *PlayerBar) updateRadioButton() {
+	setToggled(pb.radioBtn, pb.radioMode)
+}
+
+// maybeExtendRadio queues related songs once the last song of the queue is
+// playing, so playback goes on when it ends. Shuffle and repeat already keep
+// the queue going, so radio waits until both are off.
+func (pb *PlayerBar) maybeExtendRadio() {
+	if !pb.radioMode || pb.radioLoading || pb.radioSource == nil {
+		return
+	}
+	if pb.isShuffled || pb.repeatMode != RepeatOff {
+		return
+	}
+	if pb.currentSong == nil || pb.queueIndex != len(pb.queue)-1 {
+		return
+	}
+
+	seed := pb.currentSong
+	queued := append([]*types.Song(nil), pb.queue...)
+	source := pb.radioSource
+	pb.radioLoading = true
+
+	go func() {
+		songs := source(seed, queued)
+		fyne.Do(func() {
+			pb.radioLoading = false
+			if !pb.radioMode || len(songs) == 0 {
+				if pb.debug && len(songs) == 0 {
+					log.Printf("[PLAYER_BAR] Radio found nothing to play after %s", seed.Name)
+				}
+				return
+			}
+			// The queue moved on or grew while these were picked.
+			if pb.currentSong != seed || pb.queueIndex != len(pb.queue)-1 {
+				return
+			}
+			pb.AddToQueue(songs...)
+		})
+	}()
+}
@@ -0,0 +1,111 @@
+package components
+
+import (
+	"math/rand/v2"
+	"slices"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// shuffleHistoryLimit is how many songs Previous can step back through
+// while shuffling.
+const shuffleHistoryLimit = 200
+
+// shuffleSongs puts songs in a random order in place (Fisher-Yates).
+func shuffleSongs(songs []*types.Song) {
+	for i := len(songs) - 1; i > 0; i-- {
+		j := rand.IntN(i + 1)
+		songs[i], songs[j] = songs[j], songs[i]
+	}
+}
+
+// shuffleQueue puts the queue in a random order that starts with the
+// current song, so every other song plays once before any plays again. The
+// order it replaces is kept for when shuffle is turned off.
+func (pb *PlayerBar) shuffleQueue() {
+	pb.unshuffled = slices.Clone(pb.queue)
+
+	queue := slices.Clone(pb.queue)
+	if pb.queueIndex >= 0 && pb.queueIndex < len(queue) {
+		queue[0], queue[pb.queueIndex] = queue[pb.queueIndex], queue[0]
+		shuffleSongs(queue[1:])
+		pb.queueIndex = 0
+	} else {
+		shuffleSongs(queue)
+	}
+	pb.queue = queue
+}
+
+// unshuffleQueue puts the queue back in the order it had before it was
+// shuffled. Songs removed since stay out, songs queued since follow the
+// rest, and the current song stays current.
+func (pb *PlayerBar) unshuffleQueue() {
+	if pb.unshuffled == nil {
+		return
+	}
+
+	// Songs are counted, as the same one may be queued more than once.
+	left := make(map[*types.Song]int, len(pb.queue))
+	for _, song := range pb.queue {
+		left[song]++
+	}
+	restored := make([]*types.Song, 0, len(pb.queue))
+	for _, order := range [][]*types.Song{pb.unshuffled, pb.queue} {
+		for _, song := range order {
+			if left[song] > 0 {
+				left[song]--
+				restored = append(restored, song)
+			}
+		}
+	}
+
+	pb.unshuffled = nil
+	pb.queue = restored
+	if i := slices.Index(restored, pb.currentSong); i >= 0 {
+		pb.queueIndex = i
+	} else {
+		pb.queueIndex = min(pb.queueIndex, len(restored)-1)
+	}
+}
+
+// reshuffleCycle gives the next shuffled cycle a new order once its last
+// song plays. That song stays last, so the next cycle can't start with it.
+func (pb *PlayerBar) reshuffleCycle() {
+	if !pb.isShuffled || len(pb.queue) < 3 || pb.queueIndex != len(pb.queue)-1 {
+		return
+	}
+	queue := slices.Clone(pb.queue)
+	shuffleSongs(queue[:len(queue)-1])
+	pb.queue = queue
+}
+
+// pushShuffleHistory remembers the current song, so Previous returns to it
+// while shuffling.
+func (pb *PlayerBar) pushShuffleHistory() {
+	if !pb.isShuffled || pb.currentSong == nil {
+		return
+	}
+	pb.shuffleHistory = append(pb.shuffleHistory, pb.currentSong)
+	if over := len(pb.shuffleHistory) - shuffleHistoryLimit; over > 0 {
+		pb.shuffleHistory = slices.Delete(pb.shuffleHistory, 0, over)
+	}
+}
+
+// popShuffleHistory returns the queue index of the song that played before
+// the current one, skipping songs no longer queued, or -1 when there is
+// none.
+func (pb *PlayerBar) popShuffleHistory() int {
+	for len(pb.shuffleHistory) > 0 {
+		last := len(pb.shuffleHistory) - 1
+		song := pb.shuffleHistory[last]
+		pb.shuffleHistory = pb.shuffleHistory[:last]
+
+		if pb.queueIndex > 0 && pb.queueIndex <= len(pb.queue) && pb.queue[pb.queueIndex-1] == song {
+			return pb.queueIndex - 1
+		}
+		if i := slices.Index(pb.queue, song); i >= 0 {
+			return i
+		}
+	}
+	return -1
+}
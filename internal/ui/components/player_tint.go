@@ -0,0 +1,164 @@
+package components
+
+import (
+	"image/color"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// tintBackgroundAlpha keeps the cover color behind the player bar subtle
+// enough for the labels on top to stay readable.
+const tintBackgroundAlpha = 48
+
+// tintTheme is the app theme with the primary color replaced, used to color
+// the seek bar after the current cover. Without a primary color it is the
+// app theme unchanged.
+type tintTheme struct {
+	primary color.Color
+}
+
+var _ fyne.Theme = (*tintTheme)(nil)
+
+func (t *tintTheme) base() fyne.Theme {
+	if app := fyne.CurrentApp(); app != nil {
+		return app.Settings().Theme()
+	}
+	return theme.DefaultTheme()
+}
+
+func (t *tintTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	if name == theme.ColorNamePrimary && t.primary != nil {
+		return t.primary
+	}
+	return t.base().Color(name, variant)
+}
+
+func (t *tintTheme) Font(style fyne.TextStyle) fyne.Resource {
+	return t.base().Font(style)
+}
+
+func (t *tintTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return t.base().Icon(name)
+}
+
+func (t *tintTheme) Size(name fyne.ThemeSizeName) float32 {
+	return t.base().Size(name)
+}
+
+// readableAccent shifts c towards white on dark themes and towards black on
+// light ones until it stands out from the background.
+func readableAccent(c color.NRGBA, background color.Color) color.NRGBA {
+	darkTheme := luminance(toNRGBA(background)) < 0.5
+	for i := 0; i < 8; i++ {
+		l := luminance(c)
+		if darkTheme && l >= 0.45 || !darkTheme && l <= 0.55 {
+			break
+		}
+		target := uint8(0)
+		if darkTheme {
+			target = 255
+		}
+		c = color.NRGBA{R: mix(c.R, target), G: mix(c.G, target), B: mix(c.B, target), A: c.A}
+	}
+	return c
+}
+
+func mix(a, b uint8) uint8 {
+	return uint8((int(a)*3 + int(b)) / 4)
+}
+
+func luminance(c color.NRGBA) float64 {
+	return (0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)) / 255
+}
+
+func toNRGBA(c color.Color) color.NRGBA {
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
+
+// dynamicColorsEnabled reports whether the bar follows the cover's colors.
+func (pb *PlayerBar) dynamicColorsEnabled() bool {
+	return pb.cfg != nil && pb.cfg.UI.DynamicColors
+}
+
+// applyCoverTint tints the bar with the dominant color of the song's cover.
+// The color is extracted off the UI goroutine and cached per song.
+func (pb *PlayerBar) applyCoverTint(song *types.Song, url string, cover fyne.Resource) {
+	if !pb.dynamicColorsEnabled() || song == nil || cover == nil || url == "" {
+		pb.clearTint()
+		return
+	}
+
+	key := song.Slug + "@" + url
+	if c, ok := pb.coverColors.Get(key); ok {
+		pb.setTint(c)
+		return
+	}
+
+	data := cover.Content()
+	go func() {
+		c, err := pb.coverColors.Extract(key, data)
+		fyne.Do(func() {
+			if pb.currentSong != song {
+				return
+			}
+			if err != nil {
+				if pb.debug {
+					log.Printf("[PLAYER_BAR] No cover color for %s: %v", song.Slug, err)
+				}
+				pb.clearTint()
+				return
+			}
+			pb.setTint(c)
+		})
+	}()
+}
+
+func (pb *PlayerBar) setTint(c color.NRGBA) {
+	if !pb.dynamicColorsEnabled() {
+		pb.clearTint()
+		return
+	}
+
+	background := theme.Color(theme.ColorNameBackground)
+	pb.seekTint.Theme = &tintTheme{primary: readableAccent(c, background)}
+	pb.seekTint.Refresh()
+	pb.seekBar.Refresh()
+
+	pb.tintBg.FillColor = color.NRGBA{R: c.R, G: c.G, B: c.B, A: tintBackgroundAlpha}
+	pb.tintBg.Show()
+	pb.tintBg.Refresh()
+}
+
+func (pb *PlayerBar) clearTint() {
+	if pb.seekTint == nil || pb.tintBg == nil {
+		return
+	}
+	pb.seekTint.Theme = &tintTheme{}
+	pb.seekTint.Refresh()
+	pb.seekBar.Refresh()
+	pb.tintBg.Hide()
+}
+
+// RefreshDynamicColors applies or removes the cover tint after the setting
+// changed.
+func (pb *PlayerBar) RefreshDynamicColors() {
+	if song := pb.currentSong; song != nil && pb.dynamicColorsEnabled() {
+		pb.SetCurrentSong(song)
+		return
+	}
+	fyne.Do(pb.clearTint)
+}
+
+// newTintLayers returns the hidden background rectangle and the theme
+// override around the seek row that the cover tint is drawn with.
+func newTintLayers(seek fyne.CanvasObject) (*canvas.Rectangle, *container.ThemeOverride) {
+	bg := canvas.NewRectangle(color.Transparent)
+	bg.Hide()
+	return bg, container.NewThemeOverride(seek, &tintTheme{})
+}
@@ -0,0 +1,101 @@
+package components
+
+import (
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/internal/audio"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// minGaplessTrim ignores tiny tails so gapless mode does not cut off fades
+// that are part of the music.
+const minGaplessTrim = 500 * time.Millisecond
+
+// SetTransition sets how tracks of the current queue follow each other.
+func (pb *PlayerBar) SetTransition(transition types.PlaylistTransition) {
+	if transition.Mode == "" {
+		transition.Mode = types.TransitionNormal
+	}
+	pb.transition = transition
+
+	if pb.debug {
+		log.Printf("[PLAYER_BAR] Transition set to %s (%v)", transition.Mode, transition.Duration())
+	}
+}
+
+// checkTransition starts a transition ahead of the track end when the mode
+// needs one. It runs on every position update.
+func (pb *PlayerBar) checkTransition(pos, dur time.Duration) {
+	if pb.transitionStarted || pb.currentSong == nil || dur <= 0 || pb.repeatMode == RepeatOne {
+		return
+	}
+	if pb.sleepTimer != nil && pb.sleepTimer.AtEndOfTrack() {
+		// The track plays to its end, where the sleep timer stops.
+		return
+	}
+
+	switch pb.transition.Mode {
+	case types.TransitionCrossfade:
+		fade := pb.transition.Duration()
+		if dur <= 2*fade || pos < dur-fade || !pb.hasNextSong() {
+			return
+		}
+		pb.transitionStarted = true
+		pb.completeTrack()
+		pb.crossfadeNext = fade
+		pb.nextSong()
+
+	case types.TransitionGapless:
+		silence := audio.TrailingSilence(pb.currentSong.Volume, dur)
+		if silence < minGaplessTrim || pos < dur-silence {
+			return
+		}
+		pb.transitionStarted = true
+		if pb.debug {
+			log.Printf("[PLAYER_BAR] Skipping %v of trailing silence in '%s'", silence, pb.currentSong.Name)
+		}
+		pb.completeTrack()
+		pb.nextSong()
+	}
+}
+
+// hasNextSong reports whether moving on would start another track rather
+// than stop at the end of the queue.
+func (pb *PlayerBar) hasNextSong() bool {
+	if len(pb.queue) == 0 {
+		return false
+	}
+	return pb.isShuffled || pb.repeatMode == RepeatAll || pb.queueIndex+1 < len(pb.queue)
+}
+
+// scheduleNext moves to the next track once the current one has finished,
+// honoring the gap of the active transition.
+func (pb *PlayerBar) scheduleNext() {
+	switch pb.transition.Mode {
+	case types.TransitionGapless, types.TransitionCrossfade:
+		fyne.Do(pb.nextSong)
+
+	case types.TransitionGap:
+		gap := pb.transition.Duration()
+		pb.gapTimer = time.AfterFunc(gap, func() {
+			fyne.Do(pb.nextSong)
+		})
+
+	default:
+		// Small delay to ensure clean transition
+		time.Sleep(200 * time.Millisecond)
+		fyne.Do(pb.nextSong)
+	}
+}
+
+// resetTransition cancels a pending gap before another track starts.
+func (pb *PlayerBar) resetTransition() {
+	if pb.gapTimer != nil {
+		pb.gapTimer.Stop()
+		pb.gapTimer = nil
+	}
+	pb.transitionStarted = false
+}
@@ -0,0 +1,152 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	// upNextMaxRunes keeps the up next label from crowding the time label.
+	upNextMaxRunes = 60
+	// queueMenuSize is how many upcoming songs the queue menu lists.
+	queueMenuSize = 15
+)
+
+func (pb *PlayerBar) setupUpNext() {
+	pb.upNextBtn = widget.NewButton("", pb.showQueue)
+	pb.upNextBtn.Importance = widget.LowImportance
+	pb.upNextBtn.Alignment = widget.ButtonAlignTrailing
+	pb.upNextBtn.Hide()
+
+	pb.upNextSkip = widget.NewButtonWithIcon("", theme.MediaSkipNextIcon(), pb.skipUpNext)
+	pb.upNextSkip.Importance = widget.LowImportance
+	pb.upNextSkip.Hide()
+}
+
+// OnShowQueue sets what clicking the up next label opens. Without it the
+// upcoming songs are listed in a menu.
+func (pb *PlayerBar) OnShowQueue(cb func()) { pb.onShowQueue = cb }
+
+// upNextIndex returns the queue index that plays after the current song,
+// or -1 when nothing else follows.
+func (pb *PlayerBar) upNextIndex() int {
+	if len(pb.queue) < 2 || pb.repeatMode == RepeatOne {
+		return -1
+	}
+	next := pb.queueIndex + 1
+	if next >= len(pb.queue) {
+		if !pb.isShuffled && pb.repeatMode != RepeatAll {
+			return -1
+		}
+		next = 0
+	}
+	if next == pb.queueIndex {
+		return -1
+	}
+	return next
+}
+
+// updateUpNext shows the song that plays next, or hides the label.
+func (pb *PlayerBar) updateUpNext() {
+	if pb.upNextBtn == nil {
+		return
+	}
+	fyne.Do(func() {
+		next := pb.upNextIndex()
+		if next < 0 {
+			pb.upNextBtn.Hide()
+			pb.upNextSkip.Hide()
+			return
+		}
+
+		pb.upNextBtn.SetText("Up next: " + truncateRunes(songTitle(pb.queue[next]), upNextMaxRunes))
+		pb.upNextBtn.Show()
+		pb.upNextSkip.Show()
+	})
+}
+
+// skipUpNext drops the song that plays next from the queue, so the one
+// after it moves up.
+func (pb *PlayerBar) skipUpNext() {
+	if next := pb.upNextIndex(); next >= 0 {
+		pb.RemoveQueueEntry(next)
+	}
+}
+
+// restoreToQueue puts a song removed from the queue back at index, or at
+// the end if the queue has shrunk since.
+func (pb *PlayerBar) restoreToQueue(song *types.Song, index int) {
+	index = min(index, len(pb.queue))
+	pb.queue = append(pb.queue[:index:index], append([]*types.Song{song}, pb.queue[index:]...)...)
+	if index <= pb.queueIndex && len(pb.queue) > 1 {
+		pb.queueIndex++
+	}
+
+	pb.notifyQueue()
+	pb.prefetchUpcoming()
+}
+
+// showQueue opens the queue, or lists the upcoming songs to jump to.
+func (pb *PlayerBar) showQueue() {
+	if pb.onShowQueue != nil {
+		pb.onShowQueue()
+		return
+	}
+	if pb.parentWindow == nil {
+		return
+	}
+
+	var items []*fyne.MenuItem
+	index := pb.upNextIndex()
+	for len(items) < queueMenuSize && index >= 0 && index != pb.queueIndex {
+		target := index
+		items = append(items, fyne.NewMenuItem(truncateRunes(songTitle(pb.queue[target]), upNextMaxRunes), func() {
+			pb.jumpTo(target)
+		}))
+
+		index++
+		if index >= len(pb.queue) {
+			if !pb.isShuffled && pb.repeatMode != RepeatAll {
+				break
+			}
+			index = 0
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(pb.upNextBtn)
+	pos = pos.AddXY(0, pb.upNextBtn.Size().Height)
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), pb.parentWindow.Canvas(), pos)
+}
+
+// jumpTo plays the queue entry at index.
+func (pb *PlayerBar) jumpTo(index int) {
+	if index < 0 || index >= len(pb.queue) {
+		return
+	}
+	pb.pushShuffleHistory()
+	pb.queueIndex = index
+	pb.playSong(pb.queue[index])
+}
+
+// songTitle renders "Title — Artist", or just the title without artists.
+func songTitle(song *types.Song) string {
+	artist := getArtistNames(song.Authors)
+	if artist == "" {
+		return song.Name
+	}
+	return song.Name + " — " + artist
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
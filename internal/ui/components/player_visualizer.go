@@ -0,0 +1,47 @@
+package components
+
+import "log"
+
+// visualizerHeight is the height of the visualizer panel under the bar.
+const visualizerHeight = 72
+
+func (pb *PlayerBar) setupVisualizer() {
+	pb.visualizer = NewVisualizer(pb.player, visualizerHeight)
+	pb.visualizer.Hide()
+	pb.visualizer.OnModeChanged(func(mode string) {
+		if pb.cfg == nil {
+			return
+		}
+		pb.cfg.UI.VisualizerMode = mode
+		if err := pb.cfg.Save(); err != nil {
+			log.Printf("[PLAYER_BAR] Failed to save visualizer mode: %v", err)
+		}
+	})
+}
+
+// toggleVisualizer shows or hides the visualizer and remembers the choice.
+func (pb *PlayerBar) toggleVisualizer() {
+	shown := !pb.visualizer.Visible()
+	pb.showVisualizer(shown)
+
+	if pb.cfg != nil {
+		pb.cfg.UI.Visualizer = shown
+		if err := pb.cfg.Save(); err != nil {
+			log.Printf("[PLAYER_BAR] Failed to save visualizer setting: %v", err)
+		}
+	}
+}
+
+// showVisualizer opens or closes the visualizer panel. The player only
+// keeps samples for it while it shows.
+func (pb *PlayerBar) showVisualizer(shown bool) {
+	pb.player.SetVisualizer(shown)
+	if shown {
+		pb.visualizer.Show()
+		pb.visualizer.Start()
+	} else {
+		pb.visualizer.Stop()
+		pb.visualizer.Hide()
+	}
+	pb.container.Refresh()
+}
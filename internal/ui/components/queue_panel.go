@@ -0,0 +1,240 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// queuePanelWidth is how wide the queue panel is beside the main view.
+const queuePanelWidth = 320
+
+// queueExportTitle heads the snippet the queue is exported as.
+const queueExportTitle = "What I'm listening to"
+
+// QueuePanel lists the player bar's queue beside the main view. Rows are
+// dragged by their grip to reorder them, tapped to play them, and removed
+// with their button; the player bar keeps track of the current song
+// through all of it.
+type QueuePanel struct {
+	pb        *PlayerBar
+	container *fyne.Container
+	list      *widget.List
+	countLbl  *widget.Label
+	exportBtn *widget.Button
+	closeBtn  *widget.Button
+
+	songs   []*types.Song
+	current int
+
+	// dragFrom is the row a drag started on and dragAt where its song is
+	// now, both -1 while nothing is dragged. dragDY is how far the grip
+	// moved.
+	dragFrom int
+	dragAt   int
+	dragDY   float32
+
+	onClose func()
+}
+
+func NewQueuePanel(pb *PlayerBar) *QueuePanel {
+	qp := &QueuePanel{pb: pb, current: -1, dragFrom: -1, dragAt: -1}
+
+	title := widget.NewLabelWithStyle("Queue", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	qp.countLbl = widget.NewLabel("")
+	qp.countLbl.Importance = widget.LowImportance
+	qp.closeBtn = widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		if qp.onClose != nil {
+			qp.onClose()
+		}
+	})
+	qp.closeBtn.Importance = widget.LowImportance
+	qp.exportBtn = widget.NewButtonWithIcon("", theme.MailForwardIcon(), func() {
+		NewExportDialog(queueExportTitle, qp.songs).Show(qp.pb.parentWindow)
+	})
+	qp.exportBtn.Importance = widget.LowImportance
+
+	qp.list = widget.NewList(
+		func() int { return len(qp.songs) },
+		qp.createRow,
+		qp.updateRow,
+	)
+	qp.list.OnSelected = func(id widget.ListItemID) {
+		qp.list.UnselectAll()
+		qp.pb.PlayQueueEntry(id)
+	}
+
+	header := container.NewBorder(nil, nil, container.NewHBox(title, qp.countLbl), container.NewHBox(qp.exportBtn, qp.closeBtn))
+	width := canvas.NewRectangle(color.Transparent)
+	width.SetMinSize(fyne.NewSize(queuePanelWidth, 0))
+	qp.container = container.NewStack(width, container.NewBorder(
+		container.NewVBox(header, widget.NewSeparator()), nil, nil, nil, qp.list,
+	))
+	qp.container.Hide()
+	qp.refresh()
+	return qp
+}
+
+func (qp *QueuePanel) createRow() fyne.CanvasObject {
+	grip := newQueueGrip(qp)
+	playing := widget.NewIcon(theme.MediaPlayIcon())
+	title := widget.NewLabel("")
+	title.Truncation = fyne.TextTruncateEllipsis
+	artist := widget.NewLabel("")
+	artist.Truncation = fyne.TextTruncateEllipsis
+	artist.Importance = widget.LowImportance
+	remove := widget.NewButtonWithIcon("", theme.ContentRemoveIcon(), nil)
+	remove.Importance = widget.LowImportance
+
+	return container.NewBorder(nil, nil,
+		container.NewHBox(grip, playing),
+		remove,
+		container.NewVBox(title, artist))
+}
+
+func (qp *QueuePanel) updateRow(id widget.ListItemID, obj fyne.CanvasObject) {
+	if id >= len(qp.songs) {
+		return
+	}
+	song := qp.songs[id]
+	row := obj.(*fyne.Container)
+	texts := row.Objects[0].(*fyne.Container)
+	left := row.Objects[1].(*fyne.Container)
+	remove := row.Objects[2].(*widget.Button)
+
+	left.Objects[0].(*queueGrip).id = id
+	if playing := left.Objects[1]; id == qp.current {
+		playing.Show()
+	} else {
+		playing.Hide()
+	}
+
+	title := texts.Objects[0].(*widget.Label)
+	title.Text = song.Name
+	title.TextStyle.Bold = id == qp.current || id == qp.dragAt
+	title.Importance = widget.MediumImportance
+	if id == qp.current {
+		title.Importance = widget.HighImportance
+	}
+	title.Refresh()
+	texts.Objects[1].(*widget.Label).SetText(getArtistNames(song.Authors))
+
+	if id == qp.current {
+		remove.Disable()
+	} else {
+		remove.Enable()
+	}
+	remove.OnTapped = func() { qp.pb.RemoveQueueEntry(id) }
+}
+
+// Refresh shows the player bar's queue as it is now.
+func (qp *QueuePanel) Refresh() {
+	fyne.Do(qp.refresh)
+}
+
+func (qp *QueuePanel) refresh() {
+	queue := qp.pb.PlaybackQueue()
+	qp.songs, qp.current = queue.Songs, queue.Index
+	if len(qp.songs) == 0 {
+		qp.exportBtn.Disable()
+	} else {
+		qp.exportBtn.Enable()
+	}
+	switch len(qp.songs) {
+	case 0:
+		qp.countLbl.SetText("empty")
+	case 1:
+		qp.countLbl.SetText("1 song")
+	default:
+		qp.countLbl.SetText(fmt.Sprintf("%d songs", len(qp.songs)))
+	}
+	qp.list.Refresh()
+}
+
+// Container returns the panel, hidden until Show is called.
+func (qp *QueuePanel) Container() fyne.CanvasObject {
+	return qp.container
+}
+
+// Toggle shows the panel, or hides it when shown.
+func (qp *QueuePanel) Toggle() {
+	if qp.container.Visible() {
+		qp.container.Hide()
+		return
+	}
+	qp.Show()
+}
+
+// Show shows the panel scrolled to the current song.
+func (qp *QueuePanel) Show() {
+	qp.refresh()
+	qp.container.Show()
+	if qp.current >= 0 {
+		qp.list.ScrollTo(qp.current)
+	}
+}
+
+// OnClose is called when the panel's close button is tapped.
+func (qp *QueuePanel) OnClose(cb func()) { qp.onClose = cb }
+
+// dragged moves the song whose grip is dragged by as many rows as the grip
+// moved, following the pointer as it goes.
+func (qp *QueuePanel) dragged(id int, dy float32) {
+	if qp.dragFrom < 0 {
+		qp.dragFrom, qp.dragAt, qp.dragDY = id, id, 0
+	}
+	qp.dragDY += dy
+
+	// Rows are as tall as the template, with padding between them.
+	rowHeight := qp.createRow().MinSize().Height + theme.Padding()
+	target := qp.dragFrom + int(math.Round(float64(qp.dragDY/rowHeight)))
+	target = max(0, min(target, len(qp.songs)-1))
+	if target == qp.dragAt {
+		return
+	}
+	from := qp.dragAt
+	qp.dragAt = target
+	qp.pb.MoveQueueEntry(from, target)
+}
+
+func (qp *QueuePanel) dragEnd() {
+	at := qp.dragAt
+	qp.dragFrom, qp.dragAt, qp.dragDY = -1, -1, 0
+	if at >= 0 {
+		qp.list.RefreshItem(at)
+	}
+}
+
+// queueGrip is the handle of a queue row; dragging it moves the row.
+type queueGrip struct {
+	widget.Icon
+	panel *QueuePanel
+	id    widget.ListItemID
+}
+
+var (
+	_ fyne.Draggable     = (*queueGrip)(nil)
+	_ desktop.Cursorable = (*queueGrip)(nil)
+)
+
+func newQueueGrip(panel *QueuePanel) *queueGrip {
+	g := &queueGrip{panel: panel}
+	g.ExtendBaseWidget(g)
+	g.SetResource(theme.MenuIcon())
+	return g
+}
+
+func (g *queueGrip) Dragged(ev *fyne.DragEvent) { g.panel.dragged(g.id, ev.Dragged.DY) }
+
+func (g *queueGrip) DragEnd() { g.panel.dragEnd() }
+
+func (g *queueGrip) Cursor() desktop.Cursor { return desktop.VResizeCursor }
@@ -0,0 +1,164 @@
+package components
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// seekPreviewSpan is the share of the waveform shown around the cursor.
+	seekPreviewSpan = 0.1
+	seekPreviewSize = float32(160)
+)
+
+// seekHoverArea tracks the pointer over the seek bar without taking taps or
+// drags away from the slider underneath.
+type seekHoverArea struct {
+	widget.BaseWidget
+
+	onMove func(fraction float64)
+	onOut  func()
+}
+
+func newSeekHoverArea(onMove func(float64), onOut func()) *seekHoverArea {
+	h := &seekHoverArea{onMove: onMove, onOut: onOut}
+	h.ExtendBaseWidget(h)
+	return h
+}
+
+func (h *seekHoverArea) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(canvas.NewRectangle(color.Transparent))
+}
+
+func (h *seekHoverArea) MinSize() fyne.Size { return fyne.NewSize(10, 4) }
+
+func (h *seekHoverArea) MouseIn(event *desktop.MouseEvent) { h.MouseMoved(event) }
+
+func (h *seekHoverArea) MouseMoved(event *desktop.MouseEvent) {
+	width := h.Size().Width
+	if width <= 0 || h.onMove == nil {
+		return
+	}
+	fraction := float64(event.Position.X / width)
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	h.onMove(fraction)
+}
+
+func (h *seekHoverArea) MouseOut() {
+	if h.onOut != nil {
+		h.onOut()
+	}
+}
+
+// seekPreview is the tooltip shown above the seek bar with the target time and
+// a magnified slice of the waveform around it. It lives in a layout-free layer
+// of the seek stack and is moved above the bar by hand, so it never takes
+// pointer events away from the slider the way a pop-up overlay would.
+type seekPreview struct {
+	layer     *fyne.Container
+	box       *fyne.Container
+	timeLabel *widget.Label
+	wave      *waveformBar
+	marker    *canvas.Rectangle
+}
+
+func newSeekPreview() *seekPreview {
+	sp := &seekPreview{
+		timeLabel: widget.NewLabel("0:00"),
+		wave:      newWaveformBar(),
+		marker:    canvas.NewRectangle(theme.Color(theme.ColorNamePrimary)),
+	}
+	sp.timeLabel.Alignment = fyne.TextAlignCenter
+	sp.timeLabel.TextStyle = fyne.TextStyle{Monospace: true}
+	sp.marker.SetMinSize(fyne.NewSize(2, 32))
+
+	waveArea := container.NewStack(sp.wave, container.NewCenter(sp.marker))
+	waveWrap := container.NewGridWrap(fyne.NewSize(seekPreviewSize, 32), waveArea)
+
+	background := canvas.NewRectangle(theme.Color(theme.ColorNameOverlayBackground))
+	background.CornerRadius = theme.InputRadiusSize()
+	background.StrokeColor = theme.Color(theme.ColorNameShadow)
+	background.StrokeWidth = 1
+
+	sp.box = container.NewStack(background, container.NewPadded(container.NewVBox(waveWrap, sp.timeLabel)))
+	sp.box.Hide()
+	sp.layer = container.NewWithoutLayout(sp.box)
+	return sp
+}
+
+// update fills the tooltip for the given position; data is the normalized
+// waveform of the whole track and may be empty.
+func (sp *seekPreview) update(pos time.Duration, fraction float64, data []float64) {
+	sp.timeLabel.SetText(formatDuration(pos))
+
+	if len(data) == 0 {
+		sp.wave.Hide()
+		sp.marker.Hide()
+		return
+	}
+
+	half := int(float64(len(data)) * seekPreviewSpan / 2)
+	if half < 4 {
+		half = 4
+	}
+	center := int(fraction * float64(len(data)-1))
+
+	segment := make([]float64, 0, 2*half+1)
+	for i := center - half; i <= center+half; i++ {
+		if i < 0 || i >= len(data) {
+			segment = append(segment, 0)
+			continue
+		}
+		segment = append(segment, data[i])
+	}
+
+	sp.wave.setData(segment)
+	sp.wave.Show()
+	sp.marker.Show()
+}
+
+// showAt places the tooltip centered over x, above a bar of the given width.
+func (sp *seekPreview) showAt(x, barWidth float32) {
+	size := sp.box.MinSize()
+	left := x - size.Width/2
+	if left+size.Width > barWidth {
+		left = barWidth - size.Width
+	}
+	if left < 0 {
+		left = 0
+	}
+
+	sp.box.Resize(size)
+	sp.box.Move(fyne.NewPos(left, -size.Height-theme.Padding()))
+	sp.box.Show()
+}
+
+func (pb *PlayerBar) showSeekPreview(fraction float64) {
+	if pb.seekPreview == nil || pb.lastDuration <= 0 || pb.seekBar.Disabled() {
+		return
+	}
+
+	pos := time.Duration(float64(pb.lastDuration) * fraction)
+	pb.seekPreview.update(pos, fraction, pb.waveform.data)
+
+	width := pb.seekStack.Size().Width
+	pb.seekPreview.showAt(width*float32(fraction), width)
+}
+
+func (pb *PlayerBar) hideSeekPreview() {
+	if pb.seekPreview != nil {
+		pb.seekPreview.box.Hide()
+	}
+}
@@ -9,8 +9,12 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
+// recentNameLength keeps long titles from widening the sidebar.
+const recentNameLength = 22
+
 type Sidebar struct {
 	widget.BaseWidget
 	cfg *config.Config
@@ -20,23 +24,36 @@ type Sidebar struct {
 	artistsBtn  *widget.Button
 	playlistBtn *widget.Button
 	downloadBtn *widget.Button
+	localBtn    *widget.Button
+	historyBtn  *widget.Button
+	reportBtn   *widget.Button
 	statsBtn    *widget.Button
+	trashBtn    *widget.Button
 	settingsBtn *widget.Button
+	aboutBtn    *widget.Button
 
 	userCard         *widget.Card
 	authBtn          *widget.Button
+	offlineCheck     *widget.Check
+	syncBtn          *widget.Button
 	userLabel        *widget.Label
 	statusLabel      *widget.Label
 	statsLabel       *widget.Label
 	timeLabel        *widget.Label
 	offlineIndicator *widget.Icon
+	recentBox        *fyne.Container
 
 	onNavigate      func(string)
 	onAuthRequested func()
+	onAbout         func()
+	onOpenRecent    func(*types.RecentShortcut)
+	onOffline       func(bool)
+	onSyncNow       func()
 
 	isAuthenticated bool
 	currentView     string
 	compactMode     bool
+	updateAvailable bool
 	breakpoint      float32
 }
 
@@ -56,21 +73,50 @@ func (s *Sidebar) CreateRenderer() fyne.WidgetRenderer {
 	s.artistsBtn = widget.NewButtonWithIcon("Artists", theme.AccountIcon(), func() { s.navigate("artists") })
 	s.playlistBtn = widget.NewButtonWithIcon("Playlists", theme.ListIcon(), func() { s.navigate("playlists") })
 	s.downloadBtn = widget.NewButtonWithIcon("Downloads", theme.DownloadIcon(), func() { s.navigate("downloads") })
+	s.localBtn = widget.NewButtonWithIcon("On This Device", theme.StorageIcon(), func() { s.navigate("local_files") })
+	s.historyBtn = widget.NewButtonWithIcon("History", theme.HistoryIcon(), func() { s.navigate("history") })
+	s.reportBtn = widget.NewButtonWithIcon("Library Report", theme.DocumentIcon(), func() { s.navigate("report") })
 	s.statsBtn = widget.NewButtonWithIcon("Statistics", theme.InfoIcon(), func() { s.navigate("stats") })
+	s.trashBtn = widget.NewButtonWithIcon("Trash", theme.DeleteIcon(), func() { s.navigate("trash") })
 	s.settingsBtn = widget.NewButtonWithIcon("Settings", theme.SettingsIcon(), func() { s.navigate("settings") })
 
+	s.aboutBtn = widget.NewButtonWithIcon("About", theme.HelpIcon(), func() {
+		if s.onAbout != nil {
+			s.onAbout()
+		}
+	})
+
 	s.authBtn = widget.NewButtonWithIcon("Login", theme.LoginIcon(), func() {
 		if s.onAuthRequested != nil {
 			s.onAuthRequested()
 		}
 	})
 
+	s.offlineCheck = widget.NewCheck("Offline mode", func(offline bool) {
+		if offline == s.cfg.API.Offline {
+			return
+		}
+		s.cfg.API.Offline = offline
+		s.Refresh()
+		if s.onOffline != nil {
+			s.onOffline(offline)
+		}
+	})
+	s.offlineCheck.SetChecked(s.cfg.API.Offline)
+
+	s.syncBtn = widget.NewButtonWithIcon("Sync Now", theme.ViewRefreshIcon(), func() {
+		if s.onSyncNow != nil {
+			s.onSyncNow()
+		}
+	})
+
 	s.userLabel = widget.NewLabel("Not logged in")
 	s.userLabel.TextStyle = fyne.TextStyle{Bold: true}
 	s.statusLabel = widget.NewLabel("Offline mode")
 	s.offlineIndicator = widget.NewIcon(theme.WarningIcon())
 	s.statsLabel = widget.NewLabel("0 songs")
 	s.timeLabel = widget.NewLabel("0h 0m listened")
+	s.recentBox = container.NewVBox()
 	s.userCard = widget.NewCard("", "", nil)
 
 	r := &sidebarRenderer{
@@ -122,6 +168,47 @@ func (s *Sidebar) UpdateStats(songCount int, timeListened string) {
 	s.Refresh()
 }
 
+// SetRecentlyPlayed shows shortcuts to recently played albums and artists
+// beneath the stats.
+func (s *Sidebar) SetRecentlyPlayed(shortcuts []*types.RecentShortcut) {
+	if s.recentBox == nil {
+		return
+	}
+
+	s.recentBox.Objects = nil
+	if len(shortcuts) > 0 {
+		title := widget.NewLabel("Recently played")
+		title.TextStyle = fyne.TextStyle{Italic: true}
+		s.recentBox.Add(title)
+	}
+	for _, shortcut := range shortcuts {
+		icon := theme.FolderIcon()
+		if shortcut.Kind == types.RecentAuthor {
+			icon = theme.AccountIcon()
+		}
+		name := []rune(shortcut.Name)
+		if len(name) > recentNameLength {
+			name = append(name[:recentNameLength-1], '…')
+		}
+		btn := widget.NewButtonWithIcon(string(name), icon, func(sc *types.RecentShortcut) func() {
+			return func() {
+				if s.onOpenRecent != nil {
+					s.onOpenRecent(sc)
+				}
+			}
+		}(shortcut))
+		btn.Importance = widget.LowImportance
+		btn.Alignment = widget.ButtonAlignLeading
+		s.recentBox.Add(btn)
+	}
+	s.Refresh()
+}
+
+// OnOpenRecent is called when a recently played shortcut is tapped.
+func (s *Sidebar) OnOpenRecent(callback func(*types.RecentShortcut)) {
+	s.onOpenRecent = callback
+}
+
 func (s *Sidebar) SetShowStats(show bool) {
 	if s.cfg.UI.ShowStats == show {
 		return
@@ -138,6 +225,43 @@ func (s *Sidebar) OnAuthRequested(callback func()) {
 	s.onAuthRequested = callback
 }
 
+func (s *Sidebar) OnAboutRequested(callback func()) {
+	s.onAbout = callback
+}
+
+// OnOfflineChanged is called when offline mode is switched from the sidebar.
+func (s *Sidebar) OnOfflineChanged(callback func(bool)) {
+	s.onOffline = callback
+}
+
+// OnSyncNow is called when a library sync is asked for from the sidebar.
+func (s *Sidebar) OnSyncNow(callback func()) {
+	s.onSyncNow = callback
+}
+
+// SetSyncing shows whether a library sync is running.
+func (s *Sidebar) SetSyncing(syncing bool) {
+	if s.syncBtn == nil {
+		return
+	}
+	if syncing {
+		s.syncBtn.SetText("Syncing...")
+		s.syncBtn.Disable()
+	} else {
+		s.syncBtn.SetText("Sync Now")
+		s.syncBtn.Enable()
+	}
+}
+
+// SetUpdateAvailable highlights the About entry when a new release is out.
+func (s *Sidebar) SetUpdateAvailable(available bool) {
+	if s.updateAvailable == available {
+		return
+	}
+	s.updateAvailable = available
+	s.Refresh()
+}
+
 type sidebarRenderer struct {
 	sidebar       *Sidebar
 	mainContainer *fyne.Container
@@ -174,7 +298,7 @@ func (r *sidebarRenderer) createNavContainer() *fyne.Container {
 		navObjects = []fyne.CanvasObject{
 			r.sidebar.songsBtn, r.sidebar.albumsBtn, r.sidebar.artistsBtn, r.sidebar.playlistBtn,
 			widget.NewSeparator(),
-			r.sidebar.downloadBtn, r.sidebar.statsBtn, r.sidebar.settingsBtn,
+			r.sidebar.downloadBtn, r.sidebar.localBtn, r.sidebar.historyBtn, r.sidebar.reportBtn, r.sidebar.statsBtn, r.sidebar.trashBtn, r.sidebar.settingsBtn, r.sidebar.aboutBtn,
 		}
 	} else {
 		headerLabel := widget.NewLabel("AMP")
@@ -185,7 +309,7 @@ func (r *sidebarRenderer) createNavContainer() *fyne.Container {
 			widget.NewLabel("Library"),
 			r.sidebar.songsBtn, r.sidebar.albumsBtn, r.sidebar.artistsBtn, r.sidebar.playlistBtn,
 			widget.NewSeparator(), widget.NewLabel("Tools"),
-			r.sidebar.downloadBtn, r.sidebar.statsBtn, r.sidebar.settingsBtn,
+			r.sidebar.downloadBtn, r.sidebar.localBtn, r.sidebar.historyBtn, r.sidebar.reportBtn, r.sidebar.statsBtn, r.sidebar.trashBtn, r.sidebar.settingsBtn, r.sidebar.aboutBtn,
 		}
 	}
 	return container.NewVBox(navObjects...)
@@ -197,11 +321,18 @@ func (r *sidebarRenderer) updateUserCardContent() {
 		userContent = r.sidebar.authBtn
 	} else {
 		statusContainer := container.NewHBox(r.sidebar.statusLabel, r.sidebar.offlineIndicator)
-		vbox := container.NewVBox(r.sidebar.userLabel, statusContainer, r.sidebar.authBtn)
+		vbox := container.NewVBox(r.sidebar.userLabel, statusContainer, r.sidebar.offlineCheck)
+		if r.sidebar.isAuthenticated && !r.sidebar.cfg.API.Offline {
+			vbox.Add(r.sidebar.syncBtn)
+		}
+		vbox.Add(r.sidebar.authBtn)
 		if r.sidebar.cfg.UI.ShowStats {
 			vbox.Add(widget.NewSeparator())
 			vbox.Add(r.sidebar.statsLabel)
 			vbox.Add(r.sidebar.timeLabel)
+			if len(r.sidebar.recentBox.Objects) > 0 {
+				vbox.Add(r.sidebar.recentBox)
+			}
 		}
 		userContent = vbox
 	}
@@ -211,11 +342,12 @@ func (r *sidebarRenderer) updateUserCardContent() {
 func (r *sidebarRenderer) updateButtonStylesAndText() {
 	buttons := map[string]*widget.Button{
 		"songs": r.sidebar.songsBtn, "albums": r.sidebar.albumsBtn, "artists": r.sidebar.artistsBtn,
-		"playlists": r.sidebar.playlistBtn, "downloads": r.sidebar.downloadBtn, "stats": r.sidebar.statsBtn, "settings": r.sidebar.settingsBtn,
+		"playlists": r.sidebar.playlistBtn, "downloads": r.sidebar.downloadBtn, "local_files": r.sidebar.localBtn, "history": r.sidebar.historyBtn, "report": r.sidebar.reportBtn, "stats": r.sidebar.statsBtn, "trash": r.sidebar.trashBtn,
+		"settings": r.sidebar.settingsBtn,
 	}
 	labels := map[string]string{
 		"songs": "Songs", "albums": "Albums", "artists": "Artists", "playlists": "Playlists",
-		"downloads": "Downloads", "stats": "Statistics", "settings": "Settings",
+		"downloads": "Downloads", "local_files": "On This Device", "history": "History", "report": "Library Report", "stats": "Statistics", "trash": "Trash", "settings": "Settings",
 	}
 
 	for name, btn := range buttons {
@@ -232,6 +364,23 @@ func (r *sidebarRenderer) updateButtonStylesAndText() {
 		}
 	}
 
+	r.sidebar.aboutBtn.Alignment = widget.ButtonAlignLeading
+	switch {
+	case r.sidebar.compactMode:
+		r.sidebar.aboutBtn.SetText("")
+	case r.sidebar.updateAvailable:
+		r.sidebar.aboutBtn.SetText("About (update)")
+	default:
+		r.sidebar.aboutBtn.SetText("About")
+	}
+	if r.sidebar.updateAvailable {
+		r.sidebar.aboutBtn.SetIcon(theme.DownloadIcon())
+		r.sidebar.aboutBtn.Importance = widget.WarningImportance
+	} else {
+		r.sidebar.aboutBtn.SetIcon(theme.HelpIcon())
+		r.sidebar.aboutBtn.Importance = widget.MediumImportance
+	}
+
 	if r.sidebar.isAuthenticated {
 		r.sidebar.authBtn.SetIcon(theme.LogoutIcon())
 		if !r.sidebar.compactMode {
@@ -239,8 +388,6 @@ func (r *sidebarRenderer) updateButtonStylesAndText() {
 		} else {
 			r.sidebar.authBtn.SetText("")
 		}
-		r.sidebar.statusLabel.SetText("Online")
-		r.sidebar.offlineIndicator.SetResource(theme.ConfirmIcon())
 	} else {
 		r.sidebar.authBtn.SetIcon(theme.LoginIcon())
 		if !r.sidebar.compactMode {
@@ -248,8 +395,18 @@ func (r *sidebarRenderer) updateButtonStylesAndText() {
 		} else {
 			r.sidebar.authBtn.SetText("")
 		}
+	}
+
+	switch {
+	case r.sidebar.cfg.API.Offline:
 		r.sidebar.statusLabel.SetText("Offline mode")
 		r.sidebar.offlineIndicator.SetResource(theme.WarningIcon())
+	case r.sidebar.isAuthenticated:
+		r.sidebar.statusLabel.SetText("Online")
+		r.sidebar.offlineIndicator.SetResource(theme.ConfirmIcon())
+	default:
+		r.sidebar.statusLabel.SetText("Guest")
+		r.sidebar.offlineIndicator.SetResource(theme.ConfirmIcon())
 	}
 }
 
@@ -25,11 +25,13 @@ type SongList struct {
 	onOpenAuthor func(slug string)
 	onOpenSong   func(slug string) // optional: open detailed song view
 
-	root *fyne.Container
+	root        *fyne.Container
+	titles      []*widget.Button
+	highlighted int
 }
 
 func NewSongList() *SongList {
-	sl := &SongList{}
+	sl := &SongList{highlighted: -1}
 	sl.ExtendBaseWidget(sl)
 	return sl
 }
@@ -43,9 +45,37 @@ func (sl *SongList) CreateRenderer() fyne.WidgetRenderer {
 
 func (sl *SongList) SetSongs(songs []*types.Song) {
 	sl.songs = songs
+	sl.highlighted = -1
 	sl.Refresh()
 }
 
+// Highlight marks the song at index, or no song when index is -1, until
+// another is marked or the songs change.
+func (sl *SongList) Highlight(index int) {
+	if index == sl.highlighted {
+		return
+	}
+	if index >= 0 && index < len(sl.titles) {
+		sl.titles[index].Importance = widget.HighImportance
+		sl.titles[index].Refresh()
+	}
+	if sl.highlighted >= 0 && sl.highlighted < len(sl.titles) {
+		sl.titles[sl.highlighted].Importance = widget.MediumImportance
+		sl.titles[sl.highlighted].Refresh()
+	}
+	sl.highlighted = index
+}
+
+// RowPosition returns where the row of the song at index is laid out, and
+// false when it isn't shown.
+func (sl *SongList) RowPosition(index int) (fyne.Position, bool) {
+	// The first row is the header.
+	if sl.root == nil || index < 0 || index+1 >= len(sl.root.Objects) || len(sl.titles) == 0 {
+		return fyne.Position{}, false
+	}
+	return sl.root.Objects[index+1].Position(), true
+}
+
 func (sl *SongList) OnPlay(cb func(*types.Song, []*types.Song)) { sl.onPlay = cb }
 func (sl *SongList) OnDownload(cb func(*types.Song))            { sl.onDownload = cb }
 func (sl *SongList) OnOpenAlbum(cb func(slug string))           { sl.onOpenAlbum = cb }
@@ -65,6 +95,7 @@ func (r *songListRenderer) Objects() []fyne.CanvasObject {
 
 func (r *songListRenderer) Refresh() {
 	r.sl.root.Objects = nil
+	r.sl.titles = r.sl.titles[:0]
 
 	if len(r.sl.songs) == 0 {
 		empty := widget.NewLabel("No songs")
@@ -82,15 +113,15 @@ func (r *songListRenderer) Refresh() {
 	)
 	r.sl.root.Add(header)
 
-	for _, s := range r.sl.songs {
-		row := r.makeRow(s)
+	for i, s := range r.sl.songs {
+		row := r.makeRow(s, i == r.sl.highlighted)
 		r.sl.root.Add(row)
 	}
 
 	r.sl.root.Refresh()
 }
 
-func (r *songListRenderer) makeRow(s *types.Song) fyne.CanvasObject {
+func (r *songListRenderer) makeRow(s *types.Song, highlighted bool) fyne.CanvasObject {
 	// play / pause button
 	playBtn := widget.NewButtonWithIcon("", theme.MediaPlayIcon(), func() {
 		if r.sl.onPlay != nil {
@@ -114,35 +145,57 @@ func (r *songListRenderer) makeRow(s *types.Song) fyne.CanvasObject {
 		}
 	})
 	titleBtn.Importance = widget.MediumImportance
+	if highlighted {
+		titleBtn.Importance = widget.HighImportance
+	}
 	titleBtn.Alignment = widget.ButtonAlignLeading
+	r.sl.titles = append(r.sl.titles, titleBtn)
 
-	// authors “chips”
+	// authors “chips”, featured artists after a "feat." separator
 	authorsBox := container.NewHBox()
-	if len(s.Authors) == 0 {
+	var main, featured []*types.Author
+	for _, a := range s.Authors {
+		if a == nil {
+			continue
+		}
+		switch a.CreditRole() {
+		case types.AuthorRoleMain:
+			main = append(main, a)
+		case types.AuthorRoleFeatured:
+			featured = append(featured, a)
+		}
+	}
+	if len(main) == 0 && len(featured) == 0 {
 		authorsBox.Add(widget.NewLabel("Unknown Artist"))
 	} else {
-		for i, a := range s.Authors {
-			if a == nil {
-				continue
-			}
-			txt := a.Name
-			if txt == "" {
-				txt = "Unknown"
-			}
-			btn := widget.NewButtonWithIcon(txt, theme.AccountIcon(), func(slug string) func() {
-				return func() {
-					if r.sl.onOpenAuthor != nil && slug != "" {
-						r.sl.onOpenAuthor(slug)
-					}
+		addChips := func(authors []*types.Author) {
+			for i, a := range authors {
+				txt := a.Name
+				if txt == "" {
+					txt = "Unknown"
 				}
-			}(a.Slug))
-			btn.Importance = widget.LowImportance
-			btn.Alignment = widget.ButtonAlignLeading
-			authorsBox.Add(btn)
+				btn := widget.NewButtonWithIcon(txt, theme.AccountIcon(), func(slug string) func() {
+					return func() {
+						if r.sl.onOpenAuthor != nil && slug != "" {
+							r.sl.onOpenAuthor(slug)
+						}
+					}
+				}(a.Slug))
+				btn.Importance = widget.LowImportance
+				btn.Alignment = widget.ButtonAlignLeading
+				authorsBox.Add(btn)
 
-			if i < len(s.Authors)-1 {
-				authorsBox.Add(widget.NewLabel(", "))
+				if i < len(authors)-1 {
+					authorsBox.Add(widget.NewLabel(", "))
+				}
+			}
+		}
+		addChips(main)
+		if len(featured) > 0 {
+			if len(main) > 0 {
+				authorsBox.Add(widget.NewLabel("feat."))
 			}
+			addChips(featured)
 		}
 	}
 
@@ -0,0 +1,120 @@
+package components
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// undoBarTimeout is how long the undo action stays available.
+	undoBarTimeout = 8 * time.Second
+	// undoWindow is how long a deferred action waits before it is
+	// committed.
+	undoWindow = 5 * time.Second
+)
+
+// UndoBar is a thin strip that reports a destructive action and offers to
+// undo it for a few seconds. Actions offered with Defer are only committed
+// once that time has passed.
+type UndoBar struct {
+	container *fyne.Container
+	label     *widget.Label
+	undoBtn   *widget.Button
+
+	undo   func()
+	commit func()
+	timer  *time.Timer
+}
+
+func NewUndoBar() *UndoBar {
+	b := &UndoBar{
+		label: widget.NewLabel(""),
+	}
+	b.label.Truncation = fyne.TextTruncateEllipsis
+	b.undoBtn = widget.NewButtonWithIcon("Undo", theme.ContentUndoIcon(), b.runUndo)
+	b.undoBtn.Importance = widget.HighImportance
+	closeBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), b.Flush)
+	closeBtn.Importance = widget.LowImportance
+
+	b.container = container.NewBorder(nil, nil, nil, container.NewHBox(b.undoBtn, closeBtn), b.label)
+	b.container.Hide()
+	return b
+}
+
+// Show displays message with an Undo button that calls undo. A previous
+// message that is still showing is replaced and can no longer be undone.
+func (b *UndoBar) Show(message string, undo func()) {
+	b.show(message, nil, undo, undoBarTimeout)
+}
+
+// Defer displays message for an action the caller has only shown so far.
+// commit carries it out once undoWindow has passed, the bar is closed, or
+// another message replaces it; undo is called instead if the user presses
+// Undo and should put back what the caller changed. Both run on the UI
+// goroutine.
+func (b *UndoBar) Defer(message string, commit, undo func()) {
+	b.show(message, commit, undo, undoWindow)
+}
+
+func (b *UndoBar) show(message string, commit, undo func(), timeout time.Duration) {
+	b.Flush()
+
+	b.undo = undo
+	b.commit = commit
+	b.label.SetText(message)
+	if undo != nil {
+		b.undoBtn.Show()
+	} else {
+		b.undoBtn.Hide()
+	}
+	b.container.Show()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(timeout, func() {
+		fyne.Do(func() {
+			// A message shown since has a timer of its own.
+			if b.timer == timer {
+				b.Flush()
+			}
+		})
+	})
+	b.timer = timer
+}
+
+// Flush commits the deferred action that is showing, if any, and hides
+// the bar.
+func (b *UndoBar) Flush() {
+	commit := b.commit
+	b.Hide()
+	if commit != nil {
+		commit()
+	}
+}
+
+// Hide hides the bar. A deferred action that is showing is dropped without
+// being committed or undone.
+func (b *UndoBar) Hide() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.undo = nil
+	b.commit = nil
+	b.container.Hide()
+}
+
+func (b *UndoBar) runUndo() {
+	undo := b.undo
+	b.Hide()
+	if undo != nil {
+		undo()
+	}
+}
+
+func (b *UndoBar) Container() *fyne.Container {
+	return b.container
+}
@@ -0,0 +1,242 @@
+package components
+
+import (
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// visualizerFrame is how often the visualizer redraws, about 30 times
+	// a second.
+	visualizerFrame = 33 * time.Millisecond
+	visualizerBands = 48
+	// visualizerPoints is how many segments the oscilloscope line has.
+	visualizerPoints = 160
+	// visualizerFall is how much of its height a spectrum bar may drop in
+	// one frame, so bars fall smoothly instead of flickering.
+	visualizerFall = 0.08
+
+	VisualizerSpectrum     = "spectrum"
+	VisualizerOscilloscope = "oscilloscope"
+)
+
+// VisualizerSource is where the visualizer gets what plays now. Both return
+// nil while nothing plays.
+type VisualizerSource interface {
+	Spectrum(bands int) []float64
+	Oscilloscope(points int) []float64
+}
+
+// Visualizer draws what plays as spectrum bars or an oscilloscope line,
+// switching between them when tapped. It only samples while started.
+type Visualizer struct {
+	widget.BaseWidget
+
+	source VisualizerSource
+	height float32
+
+	mu     sync.Mutex
+	mode   string
+	levels []float64
+	wave   []float64
+	stop   chan struct{}
+
+	onModeChanged func(mode string)
+}
+
+func NewVisualizer(source VisualizerSource, height float32) *Visualizer {
+	v := &Visualizer{source: source, height: height, mode: VisualizerSpectrum}
+	v.ExtendBaseWidget(v)
+	return v
+}
+
+// SetMode shows the spectrum or the oscilloscope.
+func (v *Visualizer) SetMode(mode string) {
+	if mode != VisualizerOscilloscope {
+		mode = VisualizerSpectrum
+	}
+	v.mu.Lock()
+	v.mode = mode
+	v.mu.Unlock()
+	v.Refresh()
+}
+
+// OnModeChanged sets a callback for when a tap switches the mode.
+func (v *Visualizer) OnModeChanged(callback func(mode string)) {
+	v.onModeChanged = callback
+}
+
+func (v *Visualizer) Tapped(*fyne.PointEvent) {
+	v.mu.Lock()
+	mode := VisualizerOscilloscope
+	if v.mode == VisualizerOscilloscope {
+		mode = VisualizerSpectrum
+	}
+	v.mu.Unlock()
+
+	v.SetMode(mode)
+	if v.onModeChanged != nil {
+		v.onModeChanged(mode)
+	}
+}
+
+// Start redraws the visualizer every frame until Stop.
+func (v *Visualizer) Start() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.stop != nil {
+		return
+	}
+	stop := make(chan struct{})
+	v.stop = stop
+
+	go func() {
+		ticker := time.NewTicker(visualizerFrame)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				v.sample()
+				fyne.Do(v.Refresh)
+			}
+		}
+	}()
+}
+
+// Stop ends the redraws and clears the visualizer.
+func (v *Visualizer) Stop() {
+	v.mu.Lock()
+	if v.stop != nil {
+		close(v.stop)
+		v.stop = nil
+	}
+	v.levels, v.wave = nil, nil
+	v.mu.Unlock()
+	v.Refresh()
+}
+
+// sample reads the source for the next frame. Spectrum bars fall by at most
+// visualizerFall a frame.
+func (v *Visualizer) sample() {
+	v.mu.Lock()
+	mode := v.mode
+	v.mu.Unlock()
+
+	if mode == VisualizerOscilloscope {
+		wave := v.source.Oscilloscope(visualizerPoints)
+		v.mu.Lock()
+		v.wave = wave
+		v.mu.Unlock()
+		return
+	}
+
+	levels := v.source.Spectrum(visualizerBands)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.levels) != visualizerBands {
+		v.levels = make([]float64, visualizerBands)
+	}
+	for i := range v.levels {
+		next := 0.0
+		if i < len(levels) {
+			next = levels[i]
+		}
+		v.levels[i] = max(next, v.levels[i]-visualizerFall)
+	}
+}
+
+func (v *Visualizer) MinSize() fyne.Size {
+	return fyne.NewSize(visualizerBands*3, v.height)
+}
+
+type visualizerRenderer struct {
+	v       *Visualizer
+	bars    []*canvas.Rectangle
+	lines   []*canvas.Line
+	objects []fyne.CanvasObject
+}
+
+func (v *Visualizer) CreateRenderer() fyne.WidgetRenderer {
+	r := &visualizerRenderer{v: v}
+	for range visualizerBands {
+		bar := canvas.NewRectangle(nil)
+		r.bars = append(r.bars, bar)
+		r.objects = append(r.objects, bar)
+	}
+	for range visualizerPoints - 1 {
+		line := canvas.NewLine(nil)
+		line.StrokeWidth = 2
+		r.lines = append(r.lines, line)
+		r.objects = append(r.objects, line)
+	}
+	r.Refresh()
+	return r
+}
+
+func (r *visualizerRenderer) Layout(size fyne.Size) {
+	r.v.mu.Lock()
+	mode, levels, wave := r.v.mode, r.v.levels, r.v.wave
+	r.v.mu.Unlock()
+
+	spectrum := mode != VisualizerOscilloscope
+	slot := size.Width / visualizerBands
+	gap := min(slot*0.25, theme.Padding()/2)
+	for i, bar := range r.bars {
+		level := float32(0)
+		if i < len(levels) {
+			level = float32(levels[i])
+		}
+		height := size.Height * level
+		bar.Move(fyne.NewPos(slot*float32(i)+gap/2, size.Height-height))
+		bar.Resize(fyne.NewSize(slot-gap, height))
+		if spectrum && height > 0 {
+			bar.Show()
+		} else {
+			bar.Hide()
+		}
+	}
+
+	mid := size.Height / 2
+	step := size.Width / float32(visualizerPoints-1)
+	for i, line := range r.lines {
+		y1, y2 := mid, mid
+		if len(wave) == visualizerPoints {
+			y1 = mid - mid*float32(wave[i])
+			y2 = mid - mid*float32(wave[i+1])
+		}
+		line.Position1 = fyne.NewPos(step*float32(i), y1)
+		line.Position2 = fyne.NewPos(step*float32(i+1), y2)
+		if spectrum {
+			line.Hide()
+		} else {
+			line.Show()
+		}
+	}
+}
+
+func (r *visualizerRenderer) MinSize() fyne.Size { return r.v.MinSize() }
+
+func (r *visualizerRenderer) Refresh() {
+	primary := theme.Color(theme.ColorNamePrimary)
+	for _, bar := range r.bars {
+		bar.FillColor = primary
+		bar.CornerRadius = theme.InputRadiusSize() / 2
+	}
+	for _, line := range r.lines {
+		line.StrokeColor = primary
+	}
+	r.Layout(r.v.Size())
+	for _, object := range r.objects {
+		object.Refresh()
+	}
+}
+
+func (r *visualizerRenderer) Objects() []fyne.CanvasObject { return r.objects }
+func (r *visualizerRenderer) Destroy()                     {}
@@ -0,0 +1,124 @@
+package components
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// volumeScrollStep is how much one notch of the scroll wheel changes the volume.
+const volumeScrollStep = 5
+
+// volumeSlider is a slider that also reacts to the scroll wheel.
+type volumeSlider struct {
+	widget.Slider
+
+	onScroll func(delta float64)
+}
+
+func newVolumeSlider(onScroll func(float64)) *volumeSlider {
+	s := &volumeSlider{onScroll: onScroll}
+	s.Min = 0
+	s.Max = 100
+	s.Step = 1
+	s.Orientation = widget.Horizontal
+	s.ExtendBaseWidget(s)
+	return s
+}
+
+func (s *volumeSlider) Scrolled(event *fyne.ScrollEvent) {
+	if s.onScroll != nil {
+		s.onScroll(scrollNotches(event))
+	}
+}
+
+// volumeButton is the speaker icon; it forwards scroll wheel events too.
+type volumeButton struct {
+	widget.Button
+
+	onScroll func(delta float64)
+}
+
+func newVolumeButton(icon fyne.Resource, tapped func(), onScroll func(float64)) *volumeButton {
+	b := &volumeButton{onScroll: onScroll}
+	b.Icon = icon
+	b.OnTapped = tapped
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+func (b *volumeButton) Scrolled(event *fyne.ScrollEvent) {
+	if b.onScroll != nil {
+		b.onScroll(scrollNotches(event))
+	}
+}
+
+// scrollNotches maps a scroll event to +1 (up) or -1 (down).
+func scrollNotches(event *fyne.ScrollEvent) float64 {
+	delta := event.Scrolled.DY
+	if delta == 0 {
+		delta = -event.Scrolled.DX
+	}
+	switch {
+	case delta > 0:
+		return 1
+	case delta < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// scrollVolume moves the volume by one step per scroll notch.
+func (pb *PlayerBar) scrollVolume(notches float64) {
+	if notches == 0 {
+		return
+	}
+	value := pb.volumeBar.Value + notches*volumeScrollStep
+	if value < 0 {
+		value = 0
+	}
+	if value > 100 {
+		value = 100
+	}
+	pb.volumeBar.SetValue(value)
+}
+
+// StepVolume moves the volume up or down by steps, as many scroll notches
+// would.
+func (pb *PlayerBar) StepVolume(steps float64) {
+	pb.scrollVolume(steps)
+}
+
+// toggleMute silences playback, remembering the level so a second click
+// brings it back.
+func (pb *PlayerBar) toggleMute() {
+	if pb.mutedLevel > 0 {
+		level := pb.mutedLevel
+		pb.mutedLevel = 0
+		pb.volumeBar.SetValue(level)
+		return
+	}
+	if pb.volumeBar.Value == 0 {
+		return
+	}
+	level := pb.volumeBar.Value
+	pb.volumeBar.SetValue(0)
+	pb.mutedLevel = level
+}
+
+func (pb *PlayerBar) onVolumeButtonTapped() {
+	if pb.compactMode {
+		pb.showVolumeDialog()
+		return
+	}
+	pb.toggleMute()
+}
+
+func (pb *PlayerBar) volumeLabelText() string {
+	if pb.mutedLevel > 0 {
+		return "Volume: muted"
+	}
+	return fmt.Sprintf("Volume: %.0f%%", pb.volumeBar.Value)
+}
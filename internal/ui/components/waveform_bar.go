@@ -1,16 +1,26 @@
 package components
 
 import (
+	"image/color"
+	"math"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
-	"math"
 )
 
 type waveformBar struct {
 	widget.BaseWidget
 	data []float64 // normalized 0..1
+
+	// progress is the played share of the track, 0..1. Bars before it are
+	// tinted once the bar is seekable.
+	progress float64
+	dragging float64
+
+	onSeek    func(fraction float64)
+	onSeekEnd func(fraction float64)
 }
 
 func newWaveformBar() *waveformBar {
@@ -49,6 +59,80 @@ func (w *waveformBar) SetDataInt(vol []int) {
 	w.Refresh()
 }
 
+// setData takes values that are already normalized to 0..1.
+func (w *waveformBar) setData(data []float64) {
+	w.data = data
+	w.Refresh()
+}
+
+// SetSeekable turns the bar into a seek control: onSeek follows a drag and
+// onSeekEnd gets the position a tap or drag ended on. Nil callbacks make it
+// a plain display again.
+func (w *waveformBar) SetSeekable(onSeek, onSeekEnd func(fraction float64)) {
+	w.onSeek = onSeek
+	w.onSeekEnd = onSeekEnd
+	w.Refresh()
+}
+
+func (w *waveformBar) seekable() bool {
+	return w.onSeekEnd != nil
+}
+
+// SetProgress sets the played share of the track, 0..1.
+func (w *waveformBar) SetProgress(fraction float64) {
+	fraction = clampFraction(fraction)
+	if fraction == w.progress {
+		return
+	}
+	w.progress = fraction
+	if w.seekable() {
+		w.Refresh()
+	}
+}
+
+func (w *waveformBar) Tapped(event *fyne.PointEvent) {
+	if !w.seekable() {
+		return
+	}
+	w.onSeekEnd(w.fractionAt(event.Position.X))
+}
+
+func (w *waveformBar) Dragged(event *fyne.DragEvent) {
+	if !w.seekable() {
+		return
+	}
+	w.dragging = w.fractionAt(event.Position.X)
+	w.SetProgress(w.dragging)
+	if w.onSeek != nil {
+		w.onSeek(w.dragging)
+	}
+}
+
+func (w *waveformBar) DragEnd() {
+	if !w.seekable() {
+		return
+	}
+	w.onSeekEnd(w.dragging)
+}
+
+func (w *waveformBar) fractionAt(x float32) float64 {
+	width := w.Size().Width
+	if width <= 0 {
+		return 0
+	}
+	return clampFraction(float64(x / width))
+}
+
+func clampFraction(fraction float64) float64 {
+	if fraction < 0 {
+		return 0
+	}
+	if fraction > 1 {
+		return 1
+	}
+	return fraction
+}
+
 func (w *waveformBar) MinSize() fyne.Size { return fyne.NewSize(10, 14) }
 
 type waveformRenderer struct {
@@ -124,6 +208,12 @@ func (r *waveformRenderer) Layout(size fyne.Size) {
 		r.bars[i].Hide()
 	}
 
+	// Bars before played are drawn in the primary color when seekable
+	played := -1
+	if r.w.seekable() {
+		played = int(math.Round(r.w.progress * float64(count)))
+	}
+
 	h := size.Height
 	for i := 0; i < count; i++ {
 		start := int(math.Floor(float64(i) * segment))
@@ -148,8 +238,25 @@ func (r *waveformRenderer) Layout(size fyne.Size) {
 		y := h - barHeight
 
 		bar := r.bars[i]
+		fill := theme.DisabledColor()
+		if i < played {
+			fill = theme.Color(theme.ColorNamePrimary)
+		}
+		if !sameColor(bar.FillColor, fill) {
+			bar.FillColor = fill
+			canvas.Refresh(bar)
+		}
 		bar.Show()
 		bar.Resize(fyne.NewSize(bw, barHeight))
 		bar.Move(fyne.NewPos(x, y))
 	}
 }
+
+func sameColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
@@ -0,0 +1,81 @@
+package components
+
+import "fyne.io/fyne/v2"
+
+// waveformSeekEnabled reports whether the waveform replaces the seek slider
+// for songs that have volume data.
+func (pb *PlayerBar) waveformSeekEnabled() bool {
+	return pb.cfg != nil && pb.cfg.UI.WaveformSeek
+}
+
+// waveformSeeking reports whether the waveform is the seek control now.
+func (pb *PlayerBar) waveformSeeking() bool {
+	return pb.waveform != nil && pb.waveform.seekable()
+}
+
+// applySeekMode shows either the slider or the seekable waveform, depending
+// on the setting and on whether the current song has a waveform.
+func (pb *PlayerBar) applySeekMode() {
+	if pb.waveform == nil || pb.seekBar == nil {
+		return
+	}
+
+	if pb.waveformSeekEnabled() && len(pb.waveform.data) > 0 {
+		if pb.lastDuration > 0 {
+			pb.waveform.SetProgress(float64(pb.lastPosition) / float64(pb.lastDuration))
+		}
+		pb.waveform.SetSeekable(pb.onWaveformSeek, pb.onWaveformSeekEnd)
+		pb.seekBar.Hide()
+		pb.bufferProgress.Hide()
+		return
+	}
+
+	pb.waveform.SetSeekable(nil, nil)
+	pb.seekBar.Show()
+}
+
+// RefreshSeekMode applies the waveform seek setting after it changed.
+func (pb *PlayerBar) RefreshSeekMode() {
+	fyne.Do(pb.applySeekMode)
+}
+
+// setSeekValue moves the seek controls to value, in percent, without
+// seeking.
+func (pb *PlayerBar) setSeekValue(value float64) {
+	pb.seekingProgrammatically = true
+	pb.seekBar.SetValue(value)
+	pb.seekingProgrammatically = false
+	pb.waveform.SetProgress(value / 100)
+}
+
+// showBufferProgress shows how much of the song has downloaded, unless the
+// waveform stands in for the slider the bar is drawn behind.
+func (pb *PlayerBar) showBufferProgress(progress float64) {
+	pb.bufferProgress.SetValue(progress)
+	if pb.waveformSeeking() {
+		pb.bufferProgress.Hide()
+		return
+	}
+	pb.bufferProgress.Show()
+}
+
+// onWaveformSeek follows a drag on the waveform like one on the slider.
+func (pb *PlayerBar) onWaveformSeek(fraction float64) {
+	if pb.seekBar.Disabled() {
+		return
+	}
+	pb.onSeekChanged(fraction * 100)
+}
+
+// onWaveformSeekEnd seeks to where a tap or drag on the waveform ended.
+func (pb *PlayerBar) onWaveformSeekEnd(fraction float64) {
+	if pb.seekBar.Disabled() {
+		pb.userSeeking = false
+		if pb.lastDuration > 0 {
+			pb.waveform.SetProgress(float64(pb.lastPosition) / float64(pb.lastDuration))
+		}
+		return
+	}
+	pb.setSeekValue(fraction * 100)
+	pb.onSeekEnded(fraction * 100)
+}
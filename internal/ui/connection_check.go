@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+)
+
+// connectionCheckTimeout bounds the whole check, so a black-holed server
+// does not leave the user waiting on every retry.
+const connectionCheckTimeout = 30 * time.Second
+
+// testConnection checks each step of reaching the server in the settings,
+// DNS, TCP, TLS, then the server itself and the login, and shows how far it
+// got.
+func (a *App) testConnection() {
+	a.updateStatus("Testing connection to " + a.cfg.API.BaseURL + "...")
+	a.applyNetworkSettings()
+	go func() {
+		ctx, cancel := context.WithTimeout(a.ctx, connectionCheckTimeout)
+		defer cancel()
+
+		stages := netutil.CheckConnection(ctx, a.cfg, a.cfg.API.BaseURL)
+		if stagesPassed(stages) {
+			stages = append(stages, a.checkServer(ctx)...)
+		}
+		fyne.Do(func() { a.showConnectionReport(stages) })
+	}()
+}
+
+// checkServer asks a fresh client for the server version and, when logged
+// in, for the account.
+func (a *App) checkServer(ctx context.Context) []netutil.Stage {
+	backend := api.NewBackend(a.cfg)
+
+	start := time.Now()
+	info, err := backend.ProbeServer(ctx)
+	server := netutil.Stage{Name: "Server", Took: time.Since(start), Err: err}
+	switch {
+	case err != nil:
+		return []netutil.Stage{server}
+	case info.Legacy:
+		server.Detail = "older server without version information"
+	default:
+		server.Detail = "version " + info.Version
+	}
+
+	auth := netutil.Stage{Name: "Login"}
+	if backend.IsAnonymous() {
+		auth.Detail = "not logged in, browsing as a guest"
+		return []netutil.Stage{server, auth}
+	}
+	start = time.Now()
+	user, err := backend.GetCurrentUser(ctx)
+	auth.Took, auth.Err = time.Since(start), err
+	if err == nil {
+		auth.Detail = "logged in as " + user.Username
+	}
+	return []netutil.Stage{server, auth}
+}
+
+func stagesPassed(stages []netutil.Stage) bool {
+	for _, stage := range stages {
+		if stage.Err != nil {
+			return false
+		}
+	}
+	return len(stages) > 0
+}
+
+func (a *App) showConnectionReport(stages []netutil.Stage) {
+	rows := widget.NewForm()
+	for _, stage := range stages {
+		icon := widget.NewIcon(theme.ConfirmIcon())
+		text := stage.Detail
+		if stage.Err != nil {
+			icon.SetResource(theme.ErrorIcon())
+			text = stage.Err.Error()
+		}
+		if stage.Took > 0 {
+			text = fmt.Sprintf("%s (%d ms)", text, stage.Took.Milliseconds())
+		}
+		label := widget.NewLabel(text)
+		label.Wrapping = fyne.TextWrapWord
+		rows.Append(stage.Name, container.NewBorder(nil, nil, icon, nil, label))
+	}
+
+	title := "Connection Works"
+	if !stagesPassed(stages) {
+		title = "Connection Failed"
+	}
+	a.updateStatus(title)
+
+	report := dialog.NewCustom(title, "Close", rows, a.window)
+	report.Resize(fyne.NewSize(560, 0))
+	report.Show()
+}
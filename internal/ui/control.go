@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// controlLookupTimeout bounds the library lookup of a song to queue.
+const controlLookupTimeout = 4 * time.Second
+
+// HandleControl carries out a command sent by ampctl through the instance
+// socket and returns what to print. It is called on the socket's goroutine
+// and waits for the UI to apply the command.
+func (a *App) HandleControl(command string, args []string) (string, error) {
+	switch command {
+	case "play":
+		fyne.DoAndWait(a.ui.playerBar.Play)
+	case "pause":
+		fyne.DoAndWait(a.ui.playerBar.Pause)
+	case "toggle":
+		fyne.DoAndWait(a.ui.playerBar.TogglePlay)
+	case "stop":
+		fyne.DoAndWait(a.ui.playerBar.Stop)
+	case "next":
+		fyne.DoAndWait(a.ui.playerBar.Next)
+	case "prev", "previous":
+		fyne.DoAndWait(a.ui.playerBar.Previous)
+	case "now":
+		return a.controlNowPlaying(false), nil
+	case "status":
+		return a.controlNowPlaying(true), nil
+	case "queue":
+		return a.controlQueue(args)
+	case "sync":
+		fyne.DoAndWait(a.syncNow)
+	case "show":
+		a.Activate(nil)
+	default:
+		return "", fmt.Errorf("unknown command %q", command)
+	}
+	return "", nil
+}
+
+// controlNowPlaying describes the current song as "Artist – Title", or as
+// "key: value" lines for scripts when detailed.
+func (a *App) controlNowPlaying(detailed bool) string {
+	var (
+		song    *types.Song
+		playing bool
+	)
+	fyne.DoAndWait(func() {
+		song, playing = a.ui.playerBar.CurrentSong(), a.ui.playerBar.IsPlaying()
+	})
+
+	state := "stopped"
+	switch {
+	case song != nil && playing:
+		state = "playing"
+	case song != nil:
+		state = "paused"
+	}
+
+	if !detailed {
+		if song == nil {
+			return ""
+		}
+		if artists := types.CreditsLabel(song.Authors); artists != "" {
+			return artists + " – " + song.Name
+		}
+		return song.Name
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "state: %s\n", state)
+	if song == nil {
+		return b.String()
+	}
+	fmt.Fprintf(&b, "title: %s\n", song.Name)
+	fmt.Fprintf(&b, "artist: %s\n", types.CreditsLabel(song.Authors))
+	if song.Album != nil {
+		fmt.Fprintf(&b, "album: %s\n", song.Album.Name)
+	}
+	fmt.Fprintf(&b, "position: %d\n", int(a.core.player.GetPosition().Seconds()))
+	fmt.Fprintf(&b, "length: %d\n", song.Length)
+	fmt.Fprintf(&b, "slug: %s\n", song.Slug)
+	return b.String()
+}
+
+// controlQueue adds a song to the end of the queue, looked up by its slug
+// or else by searching the library, and plays it when nothing is queued.
+func (a *App) controlQueue(args []string) (string, error) {
+	query := strings.TrimSpace(strings.Join(args, " "))
+	if query == "" {
+		return "", fmt.Errorf("queue needs a song slug or search query")
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, controlLookupTimeout)
+	defer cancel()
+
+	var song *types.Song
+	if !strings.ContainsAny(query, " \t") {
+		if found, err := a.core.musicService.GetSong(ctx, query); err == nil && found != nil {
+			song = found
+		}
+	}
+	if song == nil {
+		songs, _, err := a.core.musicService.GetSongs(ctx, 1, query)
+		if err != nil {
+			return "", fmt.Errorf("search songs: %w", err)
+		}
+		if len(songs) == 0 {
+			return "", fmt.Errorf("no song matches %q", query)
+		}
+		song = songs[0]
+	}
+
+	fyne.DoAndWait(func() { a.enqueueSong(song) })
+	return "Queued " + song.Name, nil
+}
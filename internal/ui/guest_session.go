@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+)
+
+// guestResetTimeout bounds creating a new anonymous token and moving the
+// history over to it.
+const guestResetTimeout = 15 * time.Second
+
+// validateGuestSession checks with the server that the saved anonymous
+// token still works and starts a new guest session when it does not.
+func (a *App) validateGuestSession(ctx context.Context) {
+	err := a.core.offline.ValidateAnonymousToken(ctx)
+	switch {
+	case err == nil, errors.Is(err, api.ErrUnsupported), errors.Is(err, api.ErrOffline):
+	case errors.Is(err, api.ErrStaleAnonymousToken):
+		a.resetGuestSession()
+	default:
+		log.Printf("[APP] Could not validate anonymous token: %v", err)
+	}
+}
+
+// resetGuestSession replaces an anonymous token the server no longer
+// accepts with a new one, moves the play history recorded under the old
+// one to it so unsynced listens are still reported, and tells the user.
+// A reset already running makes further calls return at once.
+func (a *App) resetGuestSession() {
+	if !a.guestReset.TryLock() {
+		return
+	}
+	defer a.guestReset.Unlock()
+
+	if !a.cfg.User.IsAnonymous {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, guestResetTimeout)
+	defer cancel()
+
+	oldID := a.cfg.User.AnonymousID
+	if oldID == "" {
+		oldID = a.core.api.GetToken()
+	}
+
+	a.core.api.SetToken("")
+	anonID, err := a.core.api.EnsureAnonymousToken(ctx)
+	if err != nil {
+		log.Printf("[APP] Failed to create a new anonymous token: %v", err)
+		return
+	}
+	a.cfg.User.AnonymousID = anonID
+	if err := a.cfg.Save(); err != nil {
+		log.Printf("[APP] Failed to save config: %v", err)
+	}
+
+	if oldID != "" && oldID != anonID {
+		moved, err := a.core.storage.ReassignPlayEvents(ctx, oldID, anonID)
+		if err != nil {
+			log.Printf("[APP] Failed to move play history to the new guest session: %v", err)
+		} else {
+			log.Printf("[APP] Guest session reset, moved %d play events to it", moved)
+		}
+	}
+	a.core.playSyncService.ForceSyncNow()
+
+	fyne.Do(func() {
+		a.ui.mainView.UndoBar().Show("Guest session reset: the server had forgotten it. Your listening history was kept.", nil)
+	})
+}
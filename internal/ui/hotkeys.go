@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"log"
+	"slices"
+	"sync"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/internal/integrations/hotkeys"
+)
+
+// globalHotkeys drives the player bar from system-wide shortcuts, which
+// work while another window has focus. Presses come in on the bus goroutine
+// and are handed to the UI goroutine.
+type globalHotkeys struct {
+	service *hotkeys.Service
+
+	// mu orders the restarts applyGlobalHotkeys makes, since binding can
+	// wait on the desktop.
+	mu    sync.Mutex
+	bound []hotkeys.Binding
+}
+
+// setupGlobalHotkeys creates the shortcut service. applyGlobalHotkeys binds
+// the shortcuts.
+func (a *App) setupGlobalHotkeys() {
+	a.hotkeys = &globalHotkeys{}
+	a.hotkeys.service = hotkeys.NewService(func(action hotkeys.Action) {
+		fyne.Do(func() { a.runHotkey(action) })
+	}, a.cfg.Debug)
+}
+
+func (a *App) runHotkey(action hotkeys.Action) {
+	playerBar := a.ui.playerBar
+	switch action {
+	case hotkeys.PlayPause:
+		playerBar.TogglePlay()
+	case hotkeys.Next:
+		playerBar.Next()
+	case hotkeys.Previous:
+		playerBar.Previous()
+	case hotkeys.VolumeUp:
+		playerBar.StepVolume(1)
+	case hotkeys.VolumeDown:
+		playerBar.StepVolume(-1)
+	}
+}
+
+// hotkeyBindings returns the shortcuts set in the settings, leaving out
+// the ones cleared.
+func (a *App) hotkeyBindings() []hotkeys.Binding {
+	keys := a.cfg.Integrations.GlobalHotkeys
+	all := []hotkeys.Binding{
+		{Action: hotkeys.PlayPause, Trigger: keys.PlayPause},
+		{Action: hotkeys.Next, Trigger: keys.Next},
+		{Action: hotkeys.Previous, Trigger: keys.Previous},
+		{Action: hotkeys.VolumeUp, Trigger: keys.VolumeUp},
+		{Action: hotkeys.VolumeDown, Trigger: keys.VolumeDown},
+	}
+	bindings := make([]hotkeys.Binding, 0, len(all))
+	for _, binding := range all {
+		if binding.Trigger != "" {
+			bindings = append(bindings, binding)
+		}
+	}
+	return bindings
+}
+
+// applyGlobalHotkeys binds, rebinds or releases the shortcuts to match the
+// settings. Binding runs in the background, as the desktop may ask the user
+// to confirm the keys.
+func (a *App) applyGlobalHotkeys() {
+	enabled := a.cfg.Integrations.GlobalHotkeys.Enabled
+	bindings := a.hotkeyBindings()
+	gh := a.hotkeys
+
+	go func() {
+		gh.mu.Lock()
+		defer gh.mu.Unlock()
+
+		if !enabled || len(bindings) == 0 {
+			gh.service.Stop()
+			gh.bound = nil
+			return
+		}
+		if gh.service.Running() && slices.Equal(gh.bound, bindings) {
+			return
+		}
+		gh.service.Stop()
+		gh.bound = nil
+		if err := gh.service.Start(bindings); err != nil {
+			if a.cfg.Debug {
+				log.Printf("[APP] Global hotkeys unavailable: %v", err)
+			}
+			return
+		}
+		gh.bound = bindings
+	}()
+}
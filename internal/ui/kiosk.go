@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+
+	"github.com/Alexander-D-Karpov/amp/internal/ui/views"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// kioskShortcut turns kiosk mode on and off. Guests at a touchscreen have no
+// keyboard, so only whoever runs the machine can leave it.
+var kioskShortcut = &desktop.CustomShortcut{
+	KeyName:  fyne.KeyK,
+	Modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift,
+}
+
+// setupKiosk prepares the kiosk view and starts in it when asked to on the
+// command line.
+func (a *App) setupKiosk() {
+	a.kiosk = views.NewKioskView(a.core.musicService, a.core.imageService)
+	a.kiosk.OnQueue(a.enqueueSong)
+
+	a.window.Canvas().AddShortcut(kioskShortcut, func(fyne.Shortcut) {
+		a.setKioskMode(!a.state.kioskMode)
+	})
+
+	if a.cfg.Kiosk {
+		a.setKioskMode(true)
+	}
+}
+
+// setKioskMode swaps the window between the full player and the kiosk view,
+// which keeps only the player bar and goes fullscreen.
+func (a *App) setKioskMode(enabled bool) {
+	if enabled == a.state.kioskMode {
+		return
+	}
+	a.state.kioskMode = enabled
+
+	if enabled {
+		a.refreshKiosk()
+		a.window.SetContent(container.NewBorder(nil, a.ui.playerBar.Container(), nil, nil, a.kiosk.Container()))
+	} else {
+		a.createLayout()
+		a.window.SetContent(a.mainContainer)
+	}
+	a.window.SetFullScreen(enabled)
+
+	if a.cfg.Debug {
+		log.Printf("[APP] Kiosk mode: %v", enabled)
+	}
+}
+
+// refreshKiosk shows the current queue in the kiosk view.
+func (a *App) refreshKiosk() {
+	if a.kiosk == nil || !a.state.kioskMode {
+		return
+	}
+	var current *types.Song
+	var upcoming []*types.Song
+	queue := a.ui.playerBar.GetQueue()
+	if index := a.ui.playerBar.GetCurrentIndex(); index >= 0 && index < len(queue) {
+		current = queue[index]
+		upcoming = queue[index+1:]
+	}
+	a.kiosk.SetNowPlaying(current, upcoming)
+}
+
+// enqueueSong adds song to the end of the queue, or plays it when nothing
+// is queued.
+func (a *App) enqueueSong(song *types.Song) {
+	queue := a.ui.playerBar.GetQueue()
+	if index := a.ui.playerBar.GetCurrentIndex(); index >= 0 && index < len(queue) {
+		a.ui.playerBar.AddToQueue(song)
+	} else {
+		a.playSong(song, []*types.Song{song})
+	}
+}
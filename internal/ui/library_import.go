@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/Alexander-D-Karpov/amp/internal/importer"
+)
+
+// showLibraryImport asks for a library, playlist or listening log exported
+// by another player and imports it into the library.
+func (a *App) showLibraryImport() {
+	open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		name := reader.URI().Name()
+		data, err := io.ReadAll(reader)
+		if closeErr := reader.Close(); closeErr != nil {
+			log.Printf("[APP] Failed to close %s: %v", name, closeErr)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("read %s: %w", name, err), a.window)
+			return
+		}
+
+		lib, err := importer.Parse(name, data)
+		if err != nil {
+			dialog.ShowError(err, a.window)
+			return
+		}
+
+		a.updateStatus(fmt.Sprintf("Importing %s...", lib.Format))
+		go a.importLibrary(lib)
+	}, a.window)
+	open.SetFilter(storage.NewExtensionFileFilter([]string{".xml", ".m3u", ".m3u8", ".pls", ".log", ".txt"}))
+	open.Show()
+}
+
+func (a *App) importLibrary(lib *importer.Library) {
+	result, err := a.core.musicService.ImportLibrary(a.ctx, lib)
+	fyne.Do(func() {
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("import %s: %w", lib.Format, err), a.window)
+			if result == nil {
+				return
+			}
+		}
+
+		lines := []string{fmt.Sprintf("Matched %d of %d songs in your library.", result.Matched, result.Tracks)}
+		if result.Liked > 0 {
+			lines = append(lines, fmt.Sprintf("Liked %d songs.", result.Liked))
+		}
+		if result.PlayCounts > 0 {
+			lines = append(lines, fmt.Sprintf("Updated %d play counts.", result.PlayCounts))
+		}
+		if result.Playlists > 0 {
+			lines = append(lines, fmt.Sprintf("Imported %d playlists.", result.Playlists))
+		}
+		if result.Listens > 0 {
+			lines = append(lines, fmt.Sprintf("Added %d listens to your history.", result.Listens))
+		}
+		dialog.ShowInformation("Import Complete", strings.Join(lines, "\n"), a.window)
+
+		a.updateStatus(fmt.Sprintf("Imported %s", lib.Format))
+		a.ui.mainView.PlaylistsView.Refresh()
+	})
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/internal/scanner"
+)
+
+// libraryScanner runs the scanner over the configured library folders and
+// restarts it when they change.
+type libraryScanner struct {
+	scanner *scanner.Scanner
+	cancel  context.CancelFunc
+	folders string
+	watch   bool
+}
+
+// applyLibraryScanner starts, restarts or stops scanning to match the
+// library settings. Unchanged settings leave a running scanner alone.
+func (a *App) applyLibraryScanner() {
+	folders := strings.Join(a.cfg.Library.Folders, "\n")
+	if a.library != nil && a.library.folders == folders && a.library.watch == a.cfg.Library.Watch {
+		return
+	}
+	if a.library != nil {
+		a.library.cancel()
+		a.library = nil
+	}
+	if folders == "" {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	s := scanner.New(a.core.storage, a.cfg.Library.Folders)
+	s.SetDebug(a.cfg.Debug)
+	s.OnChange(func(result scanner.Result) {
+		fyne.Do(func() {
+			a.updateStatus(scanSummary(result))
+			a.ui.mainView.SongsView.Refresh()
+		})
+	})
+	a.library = &libraryScanner{scanner: s, cancel: cancel, folders: folders, watch: a.cfg.Library.Watch}
+
+	if !a.cfg.Library.Watch {
+		go a.scanLibrary(ctx, s)
+		return
+	}
+	go func() {
+		if err := s.Watch(ctx); err != nil {
+			log.Printf("[APP] Cannot watch library folders: %v", err)
+			a.scanLibrary(ctx, s)
+		}
+	}()
+}
+
+// rescanLibrary scans the library folders now, as asked from the settings.
+func (a *App) rescanLibrary() {
+	a.applyLibraryScanner()
+	if a.library == nil {
+		a.updateStatus("No library folders to scan")
+		return
+	}
+
+	a.updateStatus("Scanning library folders...")
+	s := a.library.scanner
+	go func() {
+		result, err := s.Scan(a.ctx)
+		if err != nil {
+			log.Printf("[APP] Library scan failed: %v", err)
+			fyne.Do(func() { a.updateStatus("Library scan failed") })
+			return
+		}
+		if !result.Changed() {
+			fyne.Do(func() { a.updateStatus("Library is up to date") })
+		}
+	}()
+}
+
+func (a *App) scanLibrary(ctx context.Context, s *scanner.Scanner) {
+	if _, err := s.Scan(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("[APP] Library scan failed: %v", err)
+	}
+}
+
+func scanSummary(result scanner.Result) string {
+	var parts []string
+	if result.Added > 0 {
+		parts = append(parts, fmt.Sprintf("%d added", result.Added))
+	}
+	if result.Updated > 0 {
+		parts = append(parts, fmt.Sprintf("%d updated", result.Updated))
+	}
+	if result.Removed > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed", result.Removed))
+	}
+	return "Local library: " + strings.Join(parts, ", ")
+}
@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/internal/integrations/mpris"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// mediaControls lets the desktop's media keys and widgets drive the player
+// bar through MPRIS. Calls come in on the bus goroutine and are handed to
+// the UI goroutine.
+type mediaControls struct {
+	a       *App
+	service *mpris.Service
+
+	lastSlug   string
+	lastArtURL string
+
+	// presses counts the play/pause presses of a double or triple press
+	// still being made.
+	pressMu    sync.Mutex
+	presses    int
+	pressTimer *time.Timer
+}
+
+// buttonPressWindow is how soon after a play/pause press the next one has to
+// come to count toward a double or triple press.
+const buttonPressWindow = 400 * time.Millisecond
+
+var _ mpris.Controls = (*mediaControls)(nil)
+
+func (m *mediaControls) Play()      { fyne.Do(m.a.ui.playerBar.Play) }
+func (m *mediaControls) Pause()     { fyne.Do(m.a.ui.playerBar.Pause) }
+func (m *mediaControls) PlayPause() { m.press() }
+func (m *mediaControls) Stop()      { fyne.Do(m.a.ui.playerBar.Stop) }
+func (m *mediaControls) Next()      { fyne.Do(m.a.ui.playerBar.Next) }
+func (m *mediaControls) Previous()  { fyne.Do(m.a.ui.playerBar.Previous) }
+
+// press counts a press of the headset button or play/pause key. The action
+// set for the number of presses runs once no further press comes within
+// buttonPressWindow, or right away when more presses would do nothing, so a
+// single press is not delayed unless double or triple presses are in use.
+func (m *mediaControls) press() {
+	buttons := m.a.cfg.Integrations.MediaButton
+	actions := []string{buttons.SinglePress, buttons.DoublePress, buttons.TriplePress}
+
+	m.pressMu.Lock()
+	defer m.pressMu.Unlock()
+
+	if m.pressTimer != nil {
+		m.pressTimer.Stop()
+	}
+	m.presses++
+	count := m.presses
+
+	waitForMore := false
+	for _, action := range actions[count:] {
+		if action != "" && action != components.ButtonActionNone {
+			waitForMore = true
+		}
+	}
+	if !waitForMore {
+		m.runPress(actions[count-1])
+		return
+	}
+
+	m.pressTimer = time.AfterFunc(buttonPressWindow, func() {
+		m.pressMu.Lock()
+		defer m.pressMu.Unlock()
+		// Another press came in while this one was waiting for the lock.
+		if m.presses != count {
+			return
+		}
+		m.runPress(actions[count-1])
+	})
+}
+
+// runPress ends a press and runs its action. It is called with pressMu held.
+func (m *mediaControls) runPress(action string) {
+	m.presses = 0
+	m.pressTimer = nil
+	if m.a.cfg.Debug {
+		log.Printf("[APP] Media button: %s", action)
+	}
+	fyne.Do(func() { m.a.ui.playerBar.RunButtonAction(action) })
+}
+
+func (m *mediaControls) SeekTo(position time.Duration) (time.Duration, error) {
+	var (
+		reached time.Duration
+		err     error
+	)
+	fyne.DoAndWait(func() {
+		reached, err = m.a.ui.playerBar.SeekTo(position)
+	})
+	return reached, err
+}
+
+func (m *mediaControls) Position() time.Duration {
+	return m.a.core.player.GetPosition()
+}
+
+func (m *mediaControls) Raise() {
+	fyne.Do(func() {
+		m.a.window.Show()
+		m.a.window.RequestFocus()
+	})
+}
+
+// publish announces the song and whether it plays. The track is only sent
+// again when the song or its cover changed.
+func (m *mediaControls) publish(song *types.Song, playing bool) {
+	var slug, artURL string
+	if song != nil {
+		slug, artURL = song.Slug, m.a.core.imageService.ExternalCoverURL(song)
+	}
+	if slug != m.lastSlug || artURL != m.lastArtURL {
+		m.lastSlug, m.lastArtURL = slug, artURL
+		m.service.SetTrack(mpris.Track{Song: song, ArtURL: artURL})
+	}
+
+	switch {
+	case song == nil:
+		m.service.SetStatus(mpris.StatusStopped)
+	case playing:
+		m.service.SetStatus(mpris.StatusPlaying)
+	default:
+		m.service.SetStatus(mpris.StatusPaused)
+	}
+}
+
+// setupMediaControls creates the MPRIS service, which the player bar's
+// playback changes are published to. applyMediaControls publishes it.
+func (a *App) setupMediaControls() {
+	a.media = &mediaControls{a: a}
+	a.media.service = mpris.NewService(a.media, a.cfg.Debug)
+}
+
+// applyMediaControls publishes the player to the desktop or withdraws it to
+// match the settings.
+func (a *App) applyMediaControls() {
+	service := a.media.service
+	if !a.cfg.Integrations.MPRIS {
+		service.Stop()
+		return
+	}
+	if service.Running() {
+		return
+	}
+	if err := service.Start(); err != nil {
+		if a.cfg.Debug {
+			log.Printf("[APP] Media controls unavailable: %v", err)
+		}
+		return
+	}
+
+	// Announce what is already playing.
+	a.media.lastSlug, a.media.lastArtURL = "", ""
+	a.media.publish(a.ui.playerBar.CurrentSong(), a.ui.playerBar.IsPlaying())
+}
@@ -0,0 +1,50 @@
+package ui
+
+import (
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+)
+
+// setOfflineMode takes the app off or back onto the server. Offline, the
+// library shows only what plays without it; back online, the changes made
+// meanwhile are sent and the library is reloaded from the server.
+func (a *App) setOfflineMode(offline bool) {
+	a.cfg.API.Offline = offline
+	a.core.offline.SetOffline(offline)
+	if err := a.cfg.Save(); err != nil {
+		log.Printf("[APP] Failed to save offline mode: %v", err)
+	}
+
+	if offline {
+		a.updateStatus("Offline mode: showing downloaded music only")
+		a.ui.mainView.RefreshData()
+		return
+	}
+
+	a.updateStatus("Back online")
+	a.ui.mainView.RefreshData()
+	go a.probeServer()
+	go a.sendOfflineChanges()
+}
+
+// sendOfflineChanges sends the likes, playlist changes and plays recorded
+// while offline, including those left from an earlier run.
+func (a *App) sendOfflineChanges() {
+	if _, err := a.core.syncManager.PushLikes(a.ctx); err != nil {
+		log.Printf("[APP] Failed to send some offline likes: %v", err)
+	}
+	sent, err := a.core.syncManager.PushChanges(a.ctx)
+	if err != nil {
+		log.Printf("[APP] Failed to send some offline changes: %v", err)
+	}
+	if a.core.playSyncService != nil {
+		a.core.playSyncService.ForceSyncNow()
+	}
+
+	if sent > 0 {
+		a.updateStatus(fmt.Sprintf("Sent %d playlist changes made offline", sent))
+		fyne.Do(func() { a.ui.mainView.RefreshData() })
+	}
+}
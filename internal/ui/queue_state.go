@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// queuePositionInterval is how often the playback position is saved while
+// the queue itself stays the same.
+const queuePositionInterval = 10 * time.Second
+
+// queueSaver writes the player bar's queue to storage off the UI goroutine.
+// Writes happen one at a time and skip straight to the latest queue.
+type queueSaver struct {
+	storage *storage.Database
+	debug   bool
+
+	writeMu sync.Mutex // held while writing
+	mu      sync.Mutex // guards the fields below
+	pending *types.PlaybackQueue
+	saving  bool
+
+	lastIndex    int
+	lastPosition time.Duration
+}
+
+func (q *queueSaver) save(queue *types.PlaybackQueue) {
+	q.mu.Lock()
+	q.pending = queue
+	q.lastIndex, q.lastPosition = queue.Index, queue.Position
+	if q.saving {
+		q.mu.Unlock()
+		return
+	}
+	q.saving = true
+	q.mu.Unlock()
+
+	go q.flush()
+}
+
+func (q *queueSaver) flush() {
+	for {
+		q.writeMu.Lock()
+		q.mu.Lock()
+		queue := q.pending
+		q.pending = nil
+		if queue == nil {
+			q.saving = false
+			q.mu.Unlock()
+			q.writeMu.Unlock()
+			return
+		}
+		q.mu.Unlock()
+
+		if err := q.storage.SavePlaybackQueue(context.Background(), queue); err != nil {
+			log.Printf("[APP] Failed to save queue: %v", err)
+		}
+		q.writeMu.Unlock()
+	}
+}
+
+// savePosition stores where playback is, unless that was already saved.
+func (q *queueSaver) savePosition(index int, position time.Duration) {
+	q.mu.Lock()
+	if index == q.lastIndex && position == q.lastPosition {
+		q.mu.Unlock()
+		return
+	}
+	q.lastIndex, q.lastPosition = index, position
+	q.mu.Unlock()
+
+	q.writeMu.Lock()
+	defer q.writeMu.Unlock()
+	if err := q.storage.SavePlaybackPosition(context.Background(), index, position); err != nil && q.debug {
+		log.Printf("[APP] Failed to save playback position: %v", err)
+	}
+}
+
+// saveNow writes queue right away, after any write in progress, and drops
+// anything still pending. Used on shutdown.
+func (q *queueSaver) saveNow(queue *types.PlaybackQueue) {
+	q.writeMu.Lock()
+	defer q.writeMu.Unlock()
+
+	q.mu.Lock()
+	q.pending = nil
+	q.mu.Unlock()
+
+	if err := q.storage.SavePlaybackQueue(context.Background(), queue); err != nil {
+		log.Printf("[APP] Failed to save queue: %v", err)
+	}
+}
+
+// setupQueuePersistence saves the queue whenever it changes, and keeps the
+// queue panel and kiosk view showing it.
+func (a *App) setupQueuePersistence() {
+	a.queue = &queueSaver{storage: a.core.storage, debug: a.cfg.Debug, lastIndex: -1}
+	a.ui.playerBar.OnQueueChanged(func() {
+		a.queue.save(a.ui.playerBar.PlaybackQueue())
+		a.ui.queuePanel.Refresh()
+		a.refreshKiosk()
+	})
+}
+
+// restoreQueue brings back the queue of the last run.
+func (a *App) restoreQueue() {
+	queue, err := a.core.storage.GetPlaybackQueue(a.ctx)
+	if err != nil {
+		log.Printf("[APP] Failed to load saved queue: %v", err)
+		return
+	}
+	if queue == nil {
+		return
+	}
+
+	a.queue.mu.Lock()
+	a.queue.lastIndex, a.queue.lastPosition = queue.Index, queue.Position
+	a.queue.mu.Unlock()
+
+	fyne.Do(func() {
+		a.ui.playerBar.RestoreQueue(queue)
+	})
+}
+
+// saveQueuePositionPeriodically keeps the saved position close to where
+// playback is, so a crash loses little more than the last few seconds.
+func (a *App) saveQueuePositionPeriodically() {
+	ticker := time.NewTicker(queuePositionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var queue *types.PlaybackQueue
+		fyne.DoAndWait(func() {
+			queue = a.ui.playerBar.PlaybackQueue()
+		})
+		if len(queue.Songs) > 0 {
+			a.queue.savePosition(queue.Index, queue.Position)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// setupSleepTimer counts the sleep timer down in the status bar while it
+// runs. The player bar's overflow menu sets it.
+func (a *App) setupSleepTimer() {
+	a.core.sleepTimer.OnChanged(func() {
+		fyne.Do(a.showSleepCountdown)
+	})
+}
+
+// showSleepCountdown shows what the sleep timer waits for, and keeps the
+// countdown ticking until it is done or cancelled.
+func (a *App) showSleepCountdown() {
+	state := a.core.sleepTimer.State()
+	switch {
+	case !state.Active:
+		a.ui.sleepLabel.Hide()
+		return
+	case state.EndOfTrack:
+		a.ui.sleepLabel.SetText("Sleep after this track")
+	default:
+		a.ui.sleepLabel.SetText("Sleep in " + formatCountdown(state.Remaining))
+	}
+	a.ui.sleepLabel.Show()
+
+	if state.EndOfTrack || a.sleepTicking {
+		return
+	}
+	a.sleepTicking = true
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// Deciding to stop on the UI goroutine keeps a timer set
+			// meanwhile from finding the countdown still running.
+			done := false
+			fyne.DoAndWait(func() {
+				state := a.core.sleepTimer.State()
+				if !state.Active || state.EndOfTrack {
+					a.sleepTicking = false
+					done = true
+					return
+				}
+				a.ui.sleepLabel.SetText("Sleep in " + formatCountdown(state.Remaining))
+			})
+			if done {
+				return
+			}
+		}
+	}()
+}
+
+// formatCountdown renders d as "m:ss", or "h:mm:ss" from an hour up.
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
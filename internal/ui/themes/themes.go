@@ -7,17 +7,58 @@ import (
 	"fyne.io/fyne/v2/theme"
 )
 
+// Font choices for TextOptions.
+const (
+	FontDefault   = "default"
+	FontMonospace = "monospace"
+)
+
+// Text scale limits; sizes outside them are clamped.
+const (
+	MinTextScale = 0.9
+	MaxTextScale = 1.5
+)
+
+// TextOptions adjust how the theme draws text.
+type TextOptions struct {
+	// Scale multiplies every text size; 1 keeps them as designed.
+	Scale float64
+	// Font is used for all regular text.
+	Font string
+	// TimeFont is used for time displays such as the playback position,
+	// which ask for monospaced text so their digits don't shift.
+	TimeFont string
+}
+
 type AMPTheme struct {
-	variant string
+	variant      string
+	text         TextOptions
+	highContrast bool
 }
 
 var _ fyne.Theme = (*AMPTheme)(nil)
 
-func NewTheme(variant string) fyne.Theme {
-	return &AMPTheme{variant: variant}
+// NewTheme returns the "dark" or "light" theme. highContrast draws text,
+// borders and separators at full contrast and gives the status colors
+// different brightness as well as hue, so they read without color
+// perception.
+func NewTheme(variant string, text TextOptions, highContrast bool) fyne.Theme {
+	if text.Scale == 0 {
+		text.Scale = 1
+	}
+	if text.TimeFont == "" {
+		text.TimeFont = FontMonospace
+	}
+	text.Scale = max(MinTextScale, min(text.Scale, MaxTextScale))
+	return &AMPTheme{variant: variant, text: text, highContrast: highContrast}
 }
 
 func (t *AMPTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	if t.highContrast {
+		if color, exists := highContrastColors(t.variant == "dark")[name]; exists {
+			return color
+		}
+	}
 	if t.variant == "dark" {
 		return t.colorDark(name, variant)
 	}
@@ -88,7 +129,56 @@ func (t *AMPTheme) colorLight(name fyne.ThemeColorName, variant fyne.ThemeVarian
 	return theme.DefaultTheme().Color(name, variant)
 }
 
+// highContrastColors override the regular colors in high contrast mode. The
+// status colors follow the Okabe-Ito palette, which stays apart for the
+// common kinds of color blindness.
+func highContrastColors(dark bool) map[fyne.ThemeColorName]color.NRGBA {
+	if dark {
+		return map[fyne.ThemeColorName]color.NRGBA{
+			theme.ColorNameBackground:      {R: 0, G: 0, B: 0, A: 255},
+			theme.ColorNameButton:          {R: 30, G: 30, B: 30, A: 255},
+			theme.ColorNameDisabled:        {R: 160, G: 160, B: 160, A: 255},
+			theme.ColorNameError:           {R: 230, G: 120, B: 60, A: 255},
+			theme.ColorNameFocus:           {R: 255, G: 220, B: 0, A: 255},
+			theme.ColorNameForeground:      {R: 255, G: 255, B: 255, A: 255},
+			theme.ColorNameHover:           {R: 70, G: 70, B: 70, A: 255},
+			theme.ColorNameInputBackground: {R: 0, G: 0, B: 0, A: 255},
+			theme.ColorNameInputBorder:     {R: 255, G: 255, B: 255, A: 255},
+			theme.ColorNamePlaceHolder:     {R: 190, G: 190, B: 190, A: 255},
+			theme.ColorNamePrimary:         {R: 255, G: 220, B: 0, A: 255},
+			theme.ColorNameSelection:       {R: 255, G: 220, B: 0, A: 110},
+			theme.ColorNameSeparator:       {R: 200, G: 200, B: 200, A: 255},
+			theme.ColorNameSuccess:         {R: 86, G: 180, B: 233, A: 255},
+			theme.ColorNameWarning:         {R: 240, G: 228, B: 66, A: 255},
+			theme.ColorNameHyperlink:       {R: 120, G: 200, B: 255, A: 255},
+		}
+	}
+	return map[fyne.ThemeColorName]color.NRGBA{
+		theme.ColorNameBackground:      {R: 255, G: 255, B: 255, A: 255},
+		theme.ColorNameButton:          {R: 235, G: 235, B: 235, A: 255},
+		theme.ColorNameDisabled:        {R: 90, G: 90, B: 90, A: 255},
+		theme.ColorNameError:           {R: 213, G: 94, B: 0, A: 255},
+		theme.ColorNameFocus:           {R: 0, G: 60, B: 170, A: 255},
+		theme.ColorNameForeground:      {R: 0, G: 0, B: 0, A: 255},
+		theme.ColorNameHover:           {R: 210, G: 210, B: 210, A: 255},
+		theme.ColorNameInputBackground: {R: 255, G: 255, B: 255, A: 255},
+		theme.ColorNameInputBorder:     {R: 0, G: 0, B: 0, A: 255},
+		theme.ColorNamePlaceHolder:     {R: 80, G: 80, B: 80, A: 255},
+		theme.ColorNamePrimary:         {R: 0, G: 60, B: 170, A: 255},
+		theme.ColorNameSelection:       {R: 0, G: 60, B: 170, A: 70},
+		theme.ColorNameSeparator:       {R: 60, G: 60, B: 60, A: 255},
+		theme.ColorNameSuccess:         {R: 0, G: 114, B: 178, A: 255},
+		theme.ColorNameWarning:         {R: 150, G: 100, B: 0, A: 255},
+		theme.ColorNameHyperlink:       {R: 0, G: 70, B: 180, A: 255},
+	}
+}
+
 func (t *AMPTheme) Font(style fyne.TextStyle) fyne.Resource {
+	font := t.text.Font
+	if style.Monospace {
+		font = t.text.TimeFont
+	}
+	style.Monospace = font == FontMonospace
 	return theme.DefaultTheme().Font(style)
 }
 
@@ -97,6 +187,7 @@ func (t *AMPTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
 }
 
 func (t *AMPTheme) Size(name fyne.ThemeSizeName) float32 {
+	scale := float32(t.text.Scale)
 	switch name {
 	case theme.SizeNamePadding:
 		return 8
@@ -107,10 +198,15 @@ func (t *AMPTheme) Size(name fyne.ThemeSizeName) float32 {
 	case theme.SizeNameSeparatorThickness:
 		return 1
 	case theme.SizeNameText:
-		return 14
+		return 14 * scale
 	case theme.SizeNameCaptionText:
-		return 12
+		return 12 * scale
+	case theme.SizeNameHeadingText, theme.SizeNameSubHeadingText:
+		return theme.DefaultTheme().Size(name) * scale
 	case theme.SizeNameInputBorder:
+		if t.highContrast {
+			return 2
+		}
 		return 1
 	case theme.SizeNameInnerPadding:
 		return 6
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/systray"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// trayIcon is the system tray entry with the transport controls. Its menu
+// is rebuilt when the song or whether it plays changes.
+type trayIcon struct {
+	a    *App
+	tray desktop.App
+
+	lastSlug    string
+	lastPlaying bool
+	built       bool
+}
+
+// setupTray puts AMP in the system tray where the desktop has one, and lets
+// closing the window hide it there when the settings ask for that.
+func (a *App) setupTray() {
+	tray, ok := a.fyneApp.(desktop.App)
+	if !ok {
+		return
+	}
+	a.tray = &trayIcon{a: a, tray: tray}
+
+	icon := a.fyneApp.Icon()
+	if icon == nil {
+		icon = theme.MediaMusicIcon()
+	}
+	tray.SetSystemTrayIcon(icon)
+	a.tray.update(nil, false)
+
+	a.window.SetCloseIntercept(func() {
+		if a.cfg.UI.MinimizeToTray {
+			a.window.Hide()
+			return
+		}
+		a.window.Close()
+	})
+}
+
+// update shows song and whether it plays in the tray's menu and tooltip.
+func (t *trayIcon) update(song *types.Song, playing bool) {
+	slug := ""
+	if song != nil {
+		slug = song.Slug
+	}
+	if t.built && slug == t.lastSlug && playing == t.lastPlaying {
+		return
+	}
+	t.built, t.lastSlug, t.lastPlaying = true, slug, playing
+
+	nowPlaying := fyne.NewMenuItem("Nothing playing", nil)
+	tooltip := "AMP"
+	if song != nil {
+		label := song.Name
+		if credits := types.CreditsLabel(song.Authors); credits != "" {
+			label += " - " + credits
+		}
+		nowPlaying.Label = label
+		tooltip = label
+	}
+	nowPlaying.Disabled = true
+
+	playerBar := t.a.ui.playerBar
+	playPause := fyne.NewMenuItem("Play", playerBar.TogglePlay)
+	if playing {
+		playPause.Label = "Pause"
+	}
+	menu := fyne.NewMenu("AMP",
+		nowPlaying,
+		fyne.NewMenuItemSeparator(),
+		playPause,
+		fyne.NewMenuItem("Next", playerBar.Next),
+		fyne.NewMenuItem("Previous", playerBar.Previous),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Show AMP", func() {
+			t.a.window.Show()
+			t.a.window.RequestFocus()
+		}),
+	)
+	t.tray.SetSystemTrayMenu(menu)
+	systray.SetTooltip(tooltip)
+}
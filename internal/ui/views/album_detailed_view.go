@@ -1,11 +1,13 @@
 package views
 
 import (
+	"context"
 	"fmt"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
@@ -14,23 +16,32 @@ import (
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
+// albumCoverSize is how large the album's cover is shown.
+var albumCoverSize = fyne.NewSize(280, 280)
+
 type AlbumDetailView struct {
 	imgSvc   *services.ImageService
 	songList *components.SongList
 
-	root     *fyne.Container
-	backBtn  *widget.Button
-	titleLbl *widget.Label
-	cover    *canvas.Image
-	authors  *fyne.Container
-	metaLbl  *widget.Label
+	root         *fyne.Container
+	parentWindow fyne.Window
+	backBtn      *widget.Button
+	refreshBtn   *widget.Button
+	artworkBtn   *widget.Button
+	titleLbl     *widget.Label
+	cover        *canvas.Image
+	authors      *fyne.Container
+	metaLbl      *widget.Label
 
 	album *types.Album
+	// coverCancel drops the pending cover request when another album is shown.
+	coverCancel context.CancelFunc
 
 	onBack       func()
 	onPlaySong   func(*types.Song)
 	onOpenAlbum  func(string)
 	onOpenAuthor func(string)
+	onRefresh    func(string)
 	onOpenSong   func(*types.Song)
 }
 
@@ -46,12 +57,24 @@ func (v *AlbumDetailView) build() {
 			v.onBack()
 		}
 	})
+	v.refreshBtn = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		if v.onRefresh != nil && v.album != nil {
+			v.onRefresh(v.album.Slug)
+		}
+	})
 	v.titleLbl = widget.NewLabel("")
 	v.titleLbl.TextStyle = fyne.TextStyle{Bold: true}
 	v.cover = canvas.NewImageFromResource(theme.FolderIcon())
 	v.cover.FillMode = canvas.ImageFillContain
 	v.metaLbl = widget.NewLabel("")
 	v.authors = container.NewHBox()
+	v.artworkBtn = widget.NewButtonWithIcon("Change Artwork", theme.FileImageIcon(), func() {
+		if v.album == nil {
+			return
+		}
+		showChangeArtwork(v.parentWindow, v.imgSvc, types.ArtworkAlbum, v.album.Slug,
+			services.AlbumImageURL(v.album, albumCoverSize), v.loadCover)
+	})
 
 	v.songList = components.NewSongList()
 	v.songList.OnPlay(func(s *types.Song, _ []*types.Song) {
@@ -70,17 +93,50 @@ func (v *AlbumDetailView) build() {
 		}
 	})
 
-	left := container.NewGridWrap(fyne.NewSize(280, 280), v.cover)
-	head := container.NewVBox(container.NewHBox(v.backBtn), v.titleLbl, v.authors, v.metaLbl)
+	left := container.NewVBox(container.NewGridWrap(albumCoverSize, v.cover), v.artworkBtn)
+	head := container.NewVBox(container.NewHBox(v.backBtn, layout.NewSpacer(), v.refreshBtn), v.titleLbl, v.authors, v.metaLbl)
 
 	// Use container.NewBorder instead of trying to create an HSplit
 	v.root = container.NewBorder(head, nil, left, nil, v.songList)
 }
 
+func (v *AlbumDetailView) SetParentWindow(window fyne.Window) {
+	v.parentWindow = window
+}
+
+// loadCover shows the album's artwork, the user's own when they picked one.
+func (v *AlbumDetailView) loadCover() {
+	if v.imgSvc == nil || v.album == nil {
+		return
+	}
+	if v.coverCancel != nil {
+		v.coverCancel()
+	}
+	url := v.imgSvc.AlbumCoverURL(v.album, albumCoverSize)
+	if url == "" {
+		v.cover.Resource = theme.FolderIcon()
+		v.cover.Refresh()
+		return
+	}
+	var ctx context.Context
+	ctx, v.coverCancel = context.WithCancel(context.Background())
+	v.imgSvc.GetImageWithSize(ctx, url, albumCoverSize, func(res fyne.Resource, err error) {
+		if err == nil && res != nil {
+			v.cover.Resource = res
+			v.cover.Refresh()
+		}
+	})
+}
+
 func (v *AlbumDetailView) SetCallbacks(onBack func(), onPlaySong func(*types.Song), onOpenAlbum func(string), onOpenAuthor func(string), onOpenSong func(*types.Song)) {
 	v.onBack, v.onPlaySong, v.onOpenAlbum, v.onOpenAuthor, v.onOpenSong = onBack, onPlaySong, onOpenAlbum, onOpenAuthor, onOpenSong
 }
 
+// SetOnRefresh sets what the refresh button does with the shown album's slug.
+func (v *AlbumDetailView) SetOnRefresh(callback func(string)) {
+	v.onRefresh = callback
+}
+
 func (v *AlbumDetailView) ShowAlbum(a *types.Album) {
 	v.album = a
 	if a == nil {
@@ -90,8 +146,11 @@ func (v *AlbumDetailView) ShowAlbum(a *types.Album) {
 	v.metaLbl.SetText(fmt.Sprintf("%d tracks", len(a.Songs)))
 
 	v.authors.Objects = nil
+	if a.IsCompilation() {
+		v.authors.Add(widget.NewLabel(types.VariousArtists))
+	}
 	for _, ar := range a.Artists {
-		if ar == nil {
+		if ar == nil || a.IsCompilation() {
 			continue
 		}
 		btn := widget.NewButton(ar.Name, func(slug string) func() {
@@ -106,22 +165,7 @@ func (v *AlbumDetailView) ShowAlbum(a *types.Album) {
 	}
 	v.authors.Refresh()
 
-	if v.imgSvc != nil {
-		url := ""
-		if a.ImageCropped != nil && *a.ImageCropped != "" {
-			url = *a.ImageCropped
-		} else if a.Image != nil {
-			url = *a.Image
-		}
-		if url != "" {
-			v.imgSvc.GetImageWithSize(url, fyne.NewSize(280, 280), func(res fyne.Resource, err error) {
-				if err == nil && res != nil {
-					v.cover.Resource = res
-					v.cover.Refresh()
-				}
-			})
-		}
-	}
+	v.loadCover()
 
 	v.songList.SetSongs(a.Songs)
 	v.root.Refresh()
@@ -129,6 +173,14 @@ func (v *AlbumDetailView) ShowAlbum(a *types.Album) {
 
 func (v *AlbumDetailView) Container() *fyne.Container { return v.root }
 
+// PlaySource describes the album for listening history.
+func (v *AlbumDetailView) PlaySource() types.PlaySource {
+	if v.album == nil {
+		return types.PlaySource{Type: types.PlaySourceAlbum}
+	}
+	return types.PlaySource{Type: types.PlaySourceAlbum, ID: v.album.Slug, Name: v.album.Name}
+}
+
 func (v *AlbumDetailView) SetAlbum(a *types.Album) {
 	v.ShowAlbum(a)
 }
@@ -3,6 +3,7 @@ package views
 import (
 	"context"
 	"fmt"
+	"log"
 	"sort"
 	"strings"
 	"sync"
@@ -26,12 +27,18 @@ type AlbumsView struct {
 	debug        bool
 
 	container   *fyne.Container
+	content     *fyne.Container
+	gridScroll  *container.Scroll
 	mediaGrid   *components.MediaGrid
+	columns     *ColumnBrowser
 	searchEntry *widget.Entry
+	layoutBtn   *widget.Button
 	refreshBtn  *widget.Button
 	sortSelect  *widget.Select
 	loader      *widget.ProgressBarInfinite
 	statusLabel *widget.Label
+	footer      *pageFooter
+	errorBanner *components.ErrorBanner
 
 	contextMenu     *widget.PopUpMenu
 	parentWindow    fyne.Window
@@ -42,14 +49,20 @@ type AlbumsView struct {
 	filteredAlbums []*types.Album
 	searchTimer    *time.Timer
 	compactMode    bool
+	columnsMode    bool
+	typeAhead      typeAhead
 	loading        bool
+	loadingMore    bool
+	loadingAll     bool
 	searchCache    map[string][]*types.Album
+	searchCancel   context.CancelFunc
 	currentPage    int
 	hasMore        bool
 	lastSearch     string
 
-	onDownload    func(*types.Album)
-	onAddPlaylist func(*types.Album)
+	onDownload      func(*types.Album)
+	onAddPlaylist   func(*types.Album)
+	onLayoutChanged func(columns bool)
 }
 
 func NewAlbumsView(musicService *services.MusicService, imageService *services.ImageService, handlers *handlers.UIHandlers, debug bool) *AlbumsView {
@@ -78,6 +91,7 @@ func (av *AlbumsView) setupWidgets() {
 	av.searchEntry.OnChanged = av.onSearchChanged
 
 	av.refreshBtn = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), av.Refresh)
+	av.layoutBtn = widget.NewButtonWithIcon("", theme.ListIcon(), av.toggleLayout)
 
 	av.sortSelect = widget.NewSelect([]string{"Name A-Z", "Name Z-A", "Artist A-Z", "Release Year"}, av.onSortChanged)
 	av.sortSelect.SetSelected("Name A-Z")
@@ -85,20 +99,72 @@ func (av *AlbumsView) setupWidgets() {
 	av.mediaGrid = components.NewMediaGrid(fyne.NewSize(200, 280), av.imageService)
 	av.mediaGrid.SetItemTapCallback(av.onGridItemTapped)
 	av.mediaGrid.SetItemSecondaryTapCallback(av.onGridItemSecondaryTapped)
+	av.columns = NewAlbumColumnBrowser(av.musicService, av.handlers, av.debug)
 
 	av.loader = widget.NewProgressBarInfinite()
 	av.loader.Hide()
 	av.statusLabel = widget.NewLabel("Loading albums…")
+	av.footer = newPageFooter("albums", av.loadAll)
+	av.errorBanner = components.NewErrorBanner()
 }
 
 func (av *AlbumsView) setupLayout() {
-	searchBar := container.NewBorder(nil, nil, nil, av.refreshBtn, av.searchEntry)
+	searchBar := container.NewBorder(nil, nil, nil, container.NewHBox(av.layoutBtn, av.refreshBtn), av.searchEntry)
 	controls := container.NewHBox(widget.NewLabel("Sort:"), av.sortSelect)
-	header := container.NewVBox(searchBar, controls, av.statusLabel)
+	header := container.NewVBox(av.errorBanner.Container(), searchBar, controls, av.statusLabel)
 
-	scroll := container.NewScroll(container.NewStack(av.mediaGrid))
+	av.gridScroll = container.NewScroll(container.NewStack(av.mediaGrid))
+	av.gridScroll.OnScrolled = av.onScrolled
+	av.content = container.NewStack(av.gridScroll)
 
-	av.container = container.NewBorder(header, av.loader, nil, nil, scroll)
+	av.container = container.NewBorder(header, container.NewVBox(av.footer.root, av.loader), nil, nil, av.content)
+}
+
+func (av *AlbumsView) toggleLayout() {
+	av.columnsMode = !av.columnsMode
+	av.applyLayout()
+	if av.onLayoutChanged != nil {
+		av.onLayoutChanged(av.columnsMode)
+	}
+}
+
+// applyLayout shows the grid or the column browser, falling back to the
+// grid on compact windows.
+func (av *AlbumsView) applyLayout() {
+	if av.compactMode {
+		av.layoutBtn.Hide()
+	} else {
+		av.layoutBtn.Show()
+	}
+	if av.columnsMode {
+		av.layoutBtn.SetIcon(theme.GridIcon())
+	} else {
+		av.layoutBtn.SetIcon(theme.ListIcon())
+	}
+	if av.columnsMode && !av.compactMode {
+		av.content.Objects = []fyne.CanvasObject{av.columns.Object()}
+	} else {
+		av.content.Objects = []fyne.CanvasObject{av.gridScroll}
+	}
+	av.content.Refresh()
+}
+
+// SetColumnsMode picks between the grid and the column browser.
+func (av *AlbumsView) SetColumnsMode(columns bool) {
+	av.columnsMode = columns
+	fyne.Do(av.applyLayout)
+}
+
+// OnLayoutChanged is called when the user switches between grid and columns.
+func (av *AlbumsView) OnLayoutChanged(cb func(columns bool)) { av.onLayoutChanged = cb }
+
+// TypeToJump moves to the first loaded album whose name starts with the
+// letters typed so far. It works in the grid, not the column browser.
+func (av *AlbumsView) TypeToJump(r rune) {
+	if av.columnsMode && !av.compactMode {
+		return
+	}
+	av.typeAhead.jumpInGrid(r, av.mediaGrid, av.gridScroll)
 }
 
 func (av *AlbumsView) onGridItemTapped(index int) {
@@ -137,6 +203,12 @@ func (av *AlbumsView) showContextMenu(album *types.Album, pos fyne.Position) {
 	})
 	playItem.Icon = theme.MediaPlayIcon()
 
+	playNextItem := fyne.NewMenuItem("Play Next", func() { av.queueAlbum(album, true) })
+	playNextItem.Icon = theme.MediaSkipNextIcon()
+
+	queueItem := fyne.NewMenuItem("Add to Queue", func() { av.queueAlbum(album, false) })
+	queueItem.Icon = theme.ListIcon()
+
 	downloadItem := fyne.NewMenuItem("Download Album", func() {
 		if av.onDownload != nil {
 			av.onDownload(album)
@@ -151,10 +223,39 @@ func (av *AlbumsView) showContextMenu(album *types.Album, pos fyne.Position) {
 	})
 	playlistItem.Icon = theme.ContentAddIcon()
 
-	av.contextMenu = widget.NewPopUpMenu(fyne.NewMenu("", playItem, fyne.NewMenuItemSeparator(), downloadItem, playlistItem), av.parentWindow.Canvas())
+	av.contextMenu = widget.NewPopUpMenu(fyne.NewMenu("",
+		playItem, playNextItem, queueItem, fyne.NewMenuItemSeparator(), downloadItem, playlistItem,
+	), av.parentWindow.Canvas())
 	av.contextMenu.ShowAtPosition(pos)
 }
 
+// queueAlbum queues the songs of album after the current song when next is
+// set, or at the end of the queue. Albums in the grid come without their
+// songs, so those are fetched first.
+func (av *AlbumsView) queueAlbum(album *types.Album, next bool) {
+	if av.handlers == nil {
+		return
+	}
+	go func() {
+		songs := album.Songs
+		if len(songs) == 0 {
+			full, err := av.musicService.GetAlbum(context.Background(), album.Slug)
+			if err != nil || full == nil {
+				log.Printf("[ALBUMS_VIEW] Failed to load songs of %s to queue: %v", album.Name, err)
+				return
+			}
+			songs = full.Songs
+		}
+		fyne.Do(func() {
+			if next {
+				av.handlers.HandlePlayNext(songs...)
+			} else {
+				av.handlers.HandleAddToQueue(songs...)
+			}
+		})
+	}()
+}
+
 func (av *AlbumsView) onSearchChanged(q string) {
 	if av.searchTimer != nil {
 		av.searchTimer.Stop()
@@ -164,6 +265,11 @@ func (av *AlbumsView) onSearchChanged(q string) {
 
 func (av *AlbumsView) performSearch(q string) {
 	av.mu.Lock()
+	if av.searchCancel != nil {
+		av.searchCancel()
+		av.searchCancel = nil
+		av.loading = false
+	}
 	av.lastSearch = q
 	av.currentPage = 1
 	av.hasMore = true
@@ -184,30 +290,46 @@ func (av *AlbumsView) performSearch(q string) {
 	av.loadAlbumsWithSearch(q)
 }
 
+// loadAlbumsWithSearch shows local matches at once and merges in the server's
+// matches as they arrive. A newer search cancels this one.
 func (av *AlbumsView) loadAlbumsWithSearch(q string) {
 	av.mu.Lock()
-	if av.loading {
-		av.mu.Unlock()
-		return
-	}
+	ctx, cancel := context.WithCancel(context.Background())
+	av.searchCancel = cancel
 	av.loading = true
 	av.mu.Unlock()
 	fyne.Do(func() { av.loader.Show(); av.statusLabel.SetText("Searching albums…") })
 	go func() {
-		defer func() { av.mu.Lock(); av.loading = false; av.mu.Unlock(); fyne.Do(func() { av.loader.Hide() }) }()
-		ctx := context.Background()
-		albums, hasMore, err := av.musicService.GetAlbums(ctx, 1, q)
-		if err != nil {
-			fyne.Do(func() { av.statusLabel.SetText(fmt.Sprintf("Search error: %v", err)) })
-			return
+		defer func() {
+			if ctx.Err() != nil {
+				return
+			}
+			av.mu.Lock()
+			av.loading = false
+			av.mu.Unlock()
+			fyne.Do(func() { av.loader.Hide() })
+		}()
+		err := av.musicService.SearchProgressive(ctx, q, func(results *types.SearchResponse, done bool) {
+			av.mu.Lock()
+			if ctx.Err() != nil || av.lastSearch != q {
+				av.mu.Unlock()
+				return
+			}
+			av.albums = results.Albums
+			av.hasMore = false
+			if done {
+				av.searchCache[q] = results.Albums
+			}
+			av.applySortAndFilter()
+			av.mu.Unlock()
+			fyne.Do(func() { av.updateGridView() })
+		})
+		if err != nil && ctx.Err() == nil {
+			fyne.Do(func() {
+				av.statusLabel.SetText("Search failed")
+				av.errorBanner.Show("Couldn't search albums", err, func() { av.performSearch(q) })
+			})
 		}
-		av.mu.Lock()
-		av.albums = albums
-		av.hasMore = hasMore
-		av.searchCache[q] = albums
-		av.applySortAndFilter()
-		av.mu.Unlock()
-		fyne.Do(func() { av.updateGridView() })
 	}()
 }
 
@@ -231,9 +353,13 @@ func (av *AlbumsView) loadAlbums() {
 		ctx := context.Background()
 		albums, hasMore, err := av.musicService.GetAlbums(ctx, 1, q)
 		if err != nil {
-			fyne.Do(func() { av.statusLabel.SetText(fmt.Sprintf("Error: %v", err)) })
+			fyne.Do(func() {
+				av.statusLabel.SetText("No albums loaded")
+				av.errorBanner.Show("Couldn't load albums", err, av.loadAlbums)
+			})
 			return
 		}
+		fyne.Do(av.errorBanner.Hide)
 		av.mu.Lock()
 		av.albums = albums
 		av.hasMore = hasMore
@@ -243,6 +369,86 @@ func (av *AlbumsView) loadAlbums() {
 	}()
 }
 
+func (av *AlbumsView) onScrolled(pos fyne.Position) {
+	av.mu.RLock()
+	idle := !av.loadingMore && !av.loadingAll && av.hasMore
+	av.mu.RUnlock()
+	if idle && nearBottom(av.gridScroll, pos) {
+		go av.loadNextPage()
+	}
+}
+
+// loadNextPage appends the next page of albums. It reports whether more
+// pages remain, and busy while another load is under way.
+func (av *AlbumsView) loadNextPage() (more, busy bool) {
+	av.mu.Lock()
+	if !av.hasMore {
+		av.mu.Unlock()
+		return false, false
+	}
+	if av.loadingMore || av.loading {
+		av.mu.Unlock()
+		return true, true
+	}
+	av.loadingMore = true
+	page := av.currentPage + 1
+	q := av.lastSearch
+	av.mu.Unlock()
+
+	albums, hasMore, err := av.musicService.GetAlbums(context.Background(), page, q)
+
+	av.mu.Lock()
+	av.loadingMore = false
+	if err != nil {
+		av.mu.Unlock()
+		log.Printf("[ALBUMS_VIEW] Failed to load page %d: %v", page, err)
+		fyne.Do(func() {
+			av.errorBanner.Show("Couldn't load more albums", err, func() { go av.loadNextPage() })
+		})
+		return false, false
+	}
+	// The list was reloaded meanwhile, so this page belongs to another.
+	if av.currentPage != page-1 || av.lastSearch != q {
+		av.mu.Unlock()
+		return false, false
+	}
+	av.currentPage = page
+	av.albums = append(av.albums, albums...)
+	av.hasMore = hasMore
+	av.applySortAndFilter()
+	av.mu.Unlock()
+
+	fyne.Do(func() { av.updateGridView() })
+	return hasMore, false
+}
+
+// loadAll loads every remaining page of albums one after another.
+func (av *AlbumsView) loadAll() {
+	av.mu.Lock()
+	if av.loadingAll {
+		av.mu.Unlock()
+		return
+	}
+	av.loadingAll = true
+	av.mu.Unlock()
+	av.updateFooter()
+
+	go func() {
+		loadRemaining(av.loadNextPage)
+		av.mu.Lock()
+		av.loadingAll = false
+		av.mu.Unlock()
+		fyne.Do(av.updateFooter)
+	}()
+}
+
+func (av *AlbumsView) updateFooter() {
+	av.mu.RLock()
+	shown, hasMore, loadingAll, searching := len(av.filteredAlbums), av.hasMore, av.loadingAll, av.lastSearch != ""
+	av.mu.RUnlock()
+	av.footer.update(shown, hasMore, loadingAll, searching)
+}
+
 func (av *AlbumsView) applySortAndFilter() {
 	av.filteredAlbums = append([]*types.Album(nil), av.albums...)
 	if av.sortSelect == nil {
@@ -265,7 +471,11 @@ func (av *AlbumsView) applySortAndFilter() {
 		case "Name Z-A":
 			return strings.ToLower(a1.Name) > strings.ToLower(a2.Name)
 		case "Artist A-Z":
-			return firstAlbumArtist(a1) < firstAlbumArtist(a2)
+			ar1, ar2 := albumArtistKey(a1), albumArtistKey(a2)
+			if ar1 != ar2 {
+				return ar1 < ar2
+			}
+			return strings.ToLower(a1.Name) < strings.ToLower(a2.Name)
 		case "Release Year":
 			return a1.CreatedAt.After(a2.CreatedAt)
 		}
@@ -273,11 +483,11 @@ func (av *AlbumsView) applySortAndFilter() {
 	})
 }
 
-func firstAlbumArtist(a *types.Album) string {
-	if a == nil || len(a.Artists) == 0 || a.Artists[0] == nil {
+func albumArtistKey(a *types.Album) string {
+	if a == nil {
 		return ""
 	}
-	return strings.ToLower(a.Artists[0].Name)
+	return strings.ToLower(a.DisplayArtist())
 }
 
 func (av *AlbumsView) updateGridView() {
@@ -287,6 +497,8 @@ func (av *AlbumsView) updateGridView() {
 	av.mu.RLock()
 	albums := append([]*types.Album(nil), av.filteredAlbums...)
 	av.mu.RUnlock()
+	av.updateFooter()
+	av.columns.SetAlbums(albums)
 	if len(albums) == 0 {
 		av.statusLabel.SetText("No albums found")
 		av.mediaGrid.SetItems([]components.MediaItem{})
@@ -309,7 +521,7 @@ func (av *AlbumsView) updateGridView() {
 
 func (av *AlbumsView) SetCompactMode(compact bool) {
 	av.compactMode = compact
-	fyne.Do(func() { av.mediaGrid.SetCompactMode(compact); av.updateGridView() })
+	fyne.Do(func() { av.mediaGrid.SetCompactMode(compact); av.applyLayout(); av.updateGridView() })
 }
 
 func (av *AlbumsView) Refresh() {
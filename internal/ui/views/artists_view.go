@@ -3,6 +3,7 @@ package views
 import (
 	"context"
 	"fmt"
+	"log"
 	"sort"
 	"strings"
 	"sync"
@@ -26,12 +27,18 @@ type ArtistsView struct {
 	debug        bool
 
 	container   *fyne.Container
+	content     *fyne.Container
+	gridScroll  *container.Scroll
 	mediaGrid   *components.MediaGrid
+	columns     *ColumnBrowser
 	searchEntry *widget.Entry
+	layoutBtn   *widget.Button
 	refreshBtn  *widget.Button
 	sortSelect  *widget.Select
 	loader      *widget.ProgressBarInfinite
 	statusLabel *widget.Label
+	footer      *pageFooter
+	errorBanner *components.ErrorBanner
 
 	contextMenu      *widget.PopUpMenu
 	parentWindow     fyne.Window
@@ -42,14 +49,20 @@ type ArtistsView struct {
 	filteredArtists []*types.Author
 	searchTimer     *time.Timer
 	compactMode     bool
+	columnsMode     bool
+	typeAhead       typeAhead
 	loading         bool
+	loadingMore     bool
+	loadingAll      bool
 	searchCache     map[string][]*types.Author
+	searchCancel    context.CancelFunc
 	currentPage     int
 	hasMore         bool
 	lastSearch      string
 
-	onDownload    func(*types.Author)
-	onAddPlaylist func(*types.Author)
+	onDownload      func(*types.Author)
+	onAddPlaylist   func(*types.Author)
+	onLayoutChanged func(columns bool)
 }
 
 func NewArtistsView(musicService *services.MusicService, imageService *services.ImageService, handlers *handlers.UIHandlers, debug bool) *ArtistsView {
@@ -77,22 +90,75 @@ func (av *ArtistsView) setupWidgets() {
 	av.searchEntry.SetPlaceHolder("Search artists…")
 	av.searchEntry.OnChanged = av.onSearchChanged
 	av.refreshBtn = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), av.Refresh)
+	av.layoutBtn = widget.NewButtonWithIcon("", theme.ListIcon(), av.toggleLayout)
 	av.sortSelect = widget.NewSelect([]string{"Name A-Z", "Name Z-A"}, av.onSortChanged)
 	av.sortSelect.SetSelected("Name A-Z")
 	av.mediaGrid = components.NewMediaGrid(fyne.NewSize(200, 260), av.imageService)
 	av.mediaGrid.SetItemTapCallback(av.onGridItemTapped)
 	av.mediaGrid.SetItemSecondaryTapCallback(av.onGridItemSecondaryTapped)
+	av.columns = NewArtistColumnBrowser(av.musicService, av.handlers, av.debug)
 	av.loader = widget.NewProgressBarInfinite()
 	av.loader.Hide()
 	av.statusLabel = widget.NewLabel("Loading artists…")
+	av.footer = newPageFooter("artists", av.loadAll)
+	av.errorBanner = components.NewErrorBanner()
 }
 
 func (av *ArtistsView) setupLayout() {
-	searchBar := container.NewBorder(nil, nil, nil, av.refreshBtn, av.searchEntry)
+	searchBar := container.NewBorder(nil, nil, nil, container.NewHBox(av.layoutBtn, av.refreshBtn), av.searchEntry)
 	controls := container.NewHBox(widget.NewLabel("Sort:"), av.sortSelect)
-	header := container.NewVBox(searchBar, controls, av.statusLabel)
-	content := container.NewScroll(container.NewStack(av.mediaGrid))
-	av.container = container.NewBorder(header, av.loader, nil, nil, content)
+	header := container.NewVBox(av.errorBanner.Container(), searchBar, controls, av.statusLabel)
+	av.gridScroll = container.NewScroll(container.NewStack(av.mediaGrid))
+	av.gridScroll.OnScrolled = av.onScrolled
+	av.content = container.NewStack(av.gridScroll)
+	av.container = container.NewBorder(header, container.NewVBox(av.footer.root, av.loader), nil, nil, av.content)
+}
+
+func (av *ArtistsView) toggleLayout() {
+	av.columnsMode = !av.columnsMode
+	av.applyLayout()
+	if av.onLayoutChanged != nil {
+		av.onLayoutChanged(av.columnsMode)
+	}
+}
+
+// applyLayout shows the grid or the column browser. Compact windows are too
+// narrow for columns, so they always get the grid.
+func (av *ArtistsView) applyLayout() {
+	if av.compactMode {
+		av.layoutBtn.Hide()
+	} else {
+		av.layoutBtn.Show()
+	}
+	if av.columnsMode {
+		av.layoutBtn.SetIcon(theme.GridIcon())
+	} else {
+		av.layoutBtn.SetIcon(theme.ListIcon())
+	}
+	if av.columnsMode && !av.compactMode {
+		av.content.Objects = []fyne.CanvasObject{av.columns.Object()}
+	} else {
+		av.content.Objects = []fyne.CanvasObject{av.gridScroll}
+	}
+	av.content.Refresh()
+}
+
+// SetColumnsMode picks between the grid and the column browser.
+func (av *ArtistsView) SetColumnsMode(columns bool) {
+	av.columnsMode = columns
+	fyne.Do(av.applyLayout)
+}
+
+// OnLayoutChanged is called when the user switches between grid and columns.
+func (av *ArtistsView) OnLayoutChanged(cb func(columns bool)) { av.onLayoutChanged = cb }
+
+// TypeToJump moves to the first loaded artist whose name starts with the
+// letters typed so far. It works in the grid, not the column browser.
+func (av *ArtistsView) TypeToJump(r rune) {
+	if av.columnsMode && !av.compactMode {
+		return
+	}
+	av.typeAhead.jumpInGrid(r, av.mediaGrid, av.gridScroll)
 }
 
 func (av *ArtistsView) onGridItemTapped(index int) {
@@ -155,6 +221,11 @@ func (av *ArtistsView) onSearchChanged(q string) {
 
 func (av *ArtistsView) performSearch(q string) {
 	av.mu.Lock()
+	if av.searchCancel != nil {
+		av.searchCancel()
+		av.searchCancel = nil
+		av.loading = false
+	}
 	av.lastSearch = q
 	av.currentPage = 1
 	av.hasMore = true
@@ -175,30 +246,46 @@ func (av *ArtistsView) performSearch(q string) {
 	av.loadArtistsWithSearch(q)
 }
 
+// loadArtistsWithSearch shows local matches at once and merges in the server's
+// matches as they arrive. A newer search cancels this one.
 func (av *ArtistsView) loadArtistsWithSearch(q string) {
 	av.mu.Lock()
-	if av.loading {
-		av.mu.Unlock()
-		return
-	}
+	ctx, cancel := context.WithCancel(context.Background())
+	av.searchCancel = cancel
 	av.loading = true
 	av.mu.Unlock()
 	fyne.Do(func() { av.loader.Show(); av.statusLabel.SetText("Searching artists…") })
 	go func() {
-		defer func() { av.mu.Lock(); av.loading = false; av.mu.Unlock(); fyne.Do(func() { av.loader.Hide() }) }()
-		ctx := context.Background()
-		authors, hasMore, err := av.musicService.GetAuthors(ctx, 1, q)
-		if err != nil {
-			fyne.Do(func() { av.statusLabel.SetText(fmt.Sprintf("Search error: %v", err)) })
-			return
+		defer func() {
+			if ctx.Err() != nil {
+				return
+			}
+			av.mu.Lock()
+			av.loading = false
+			av.mu.Unlock()
+			fyne.Do(func() { av.loader.Hide() })
+		}()
+		err := av.musicService.SearchProgressive(ctx, q, func(results *types.SearchResponse, done bool) {
+			av.mu.Lock()
+			if ctx.Err() != nil || av.lastSearch != q {
+				av.mu.Unlock()
+				return
+			}
+			av.artists = results.Authors
+			av.hasMore = false
+			if done {
+				av.searchCache[q] = results.Authors
+			}
+			av.applySortAndFilter()
+			av.mu.Unlock()
+			fyne.Do(func() { av.updateGridView() })
+		})
+		if err != nil && ctx.Err() == nil {
+			fyne.Do(func() {
+				av.statusLabel.SetText("Search failed")
+				av.errorBanner.Show("Couldn't search artists", err, func() { av.performSearch(q) })
+			})
 		}
-		av.mu.Lock()
-		av.artists = authors
-		av.hasMore = hasMore
-		av.searchCache[q] = authors
-		av.applySortAndFilter()
-		av.mu.Unlock()
-		fyne.Do(func() { av.updateGridView() })
 	}()
 }
 
@@ -222,9 +309,13 @@ func (av *ArtistsView) loadArtists() {
 		ctx := context.Background()
 		artists, hasMore, err := av.musicService.GetAuthors(ctx, 1, q)
 		if err != nil {
-			fyne.Do(func() { av.statusLabel.SetText(fmt.Sprintf("Error: %v", err)) })
+			fyne.Do(func() {
+				av.statusLabel.SetText("No artists loaded")
+				av.errorBanner.Show("Couldn't load artists", err, av.loadArtists)
+			})
 			return
 		}
+		fyne.Do(av.errorBanner.Hide)
 		av.mu.Lock()
 		av.artists = artists
 		av.hasMore = hasMore
@@ -234,6 +325,86 @@ func (av *ArtistsView) loadArtists() {
 	}()
 }
 
+func (av *ArtistsView) onScrolled(pos fyne.Position) {
+	av.mu.RLock()
+	idle := !av.loadingMore && !av.loadingAll && av.hasMore
+	av.mu.RUnlock()
+	if idle && nearBottom(av.gridScroll, pos) {
+		go av.loadNextPage()
+	}
+}
+
+// loadNextPage appends the next page of artists. It reports whether more
+// pages remain, and busy while another load is under way.
+func (av *ArtistsView) loadNextPage() (more, busy bool) {
+	av.mu.Lock()
+	if !av.hasMore {
+		av.mu.Unlock()
+		return false, false
+	}
+	if av.loadingMore || av.loading {
+		av.mu.Unlock()
+		return true, true
+	}
+	av.loadingMore = true
+	page := av.currentPage + 1
+	q := av.lastSearch
+	av.mu.Unlock()
+
+	artists, hasMore, err := av.musicService.GetAuthors(context.Background(), page, q)
+
+	av.mu.Lock()
+	av.loadingMore = false
+	if err != nil {
+		av.mu.Unlock()
+		log.Printf("[ARTISTS_VIEW] Failed to load page %d: %v", page, err)
+		fyne.Do(func() {
+			av.errorBanner.Show("Couldn't load more artists", err, func() { go av.loadNextPage() })
+		})
+		return false, false
+	}
+	// The list was reloaded meanwhile, so this page belongs to another.
+	if av.currentPage != page-1 || av.lastSearch != q {
+		av.mu.Unlock()
+		return false, false
+	}
+	av.currentPage = page
+	av.artists = append(av.artists, artists...)
+	av.hasMore = hasMore
+	av.applySortAndFilter()
+	av.mu.Unlock()
+
+	fyne.Do(func() { av.updateGridView() })
+	return hasMore, false
+}
+
+// loadAll loads every remaining page of artists one after another.
+func (av *ArtistsView) loadAll() {
+	av.mu.Lock()
+	if av.loadingAll {
+		av.mu.Unlock()
+		return
+	}
+	av.loadingAll = true
+	av.mu.Unlock()
+	av.updateFooter()
+
+	go func() {
+		loadRemaining(av.loadNextPage)
+		av.mu.Lock()
+		av.loadingAll = false
+		av.mu.Unlock()
+		fyne.Do(av.updateFooter)
+	}()
+}
+
+func (av *ArtistsView) updateFooter() {
+	av.mu.RLock()
+	shown, hasMore, loadingAll, searching := len(av.filteredArtists), av.hasMore, av.loadingAll, av.lastSearch != ""
+	av.mu.RUnlock()
+	av.footer.update(shown, hasMore, loadingAll, searching)
+}
+
 func (av *ArtistsView) applySortAndFilter() {
 	av.filteredArtists = append([]*types.Author(nil), av.artists...)
 	if av.sortSelect == nil {
@@ -267,6 +438,8 @@ func (av *ArtistsView) updateGridView() {
 	av.mu.RLock()
 	artists := append([]*types.Author(nil), av.filteredArtists...)
 	av.mu.RUnlock()
+	av.updateFooter()
+	av.columns.SetArtists(artists)
 	if len(artists) == 0 {
 		av.statusLabel.SetText("No artists found")
 		av.mediaGrid.SetItems([]components.MediaItem{})
@@ -289,7 +462,7 @@ func (av *ArtistsView) updateGridView() {
 
 func (av *ArtistsView) SetCompactMode(compact bool) {
 	av.compactMode = compact
-	fyne.Do(func() { av.mediaGrid.SetCompactMode(compact); av.updateGridView() })
+	fyne.Do(func() { av.mediaGrid.SetCompactMode(compact); av.applyLayout(); av.updateGridView() })
 }
 
 func (av *ArtistsView) Refresh() {
@@ -0,0 +1,86 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// showChangeArtwork lets the user replace an item's artwork with a local
+// image or drop their image and fetch the server's again. serverURL is the
+// item's artwork on the server. onChanged runs on the UI goroutine once the
+// new artwork is in place.
+func showChangeArtwork(window fyne.Window, imgSvc *services.ImageService, kind types.ArtworkKind, slug, serverURL string, onChanged func()) {
+	if window == nil || imgSvc == nil || slug == "" {
+		return
+	}
+
+	var d dialog.Dialog
+	done := func(err error) {
+		fyne.Do(func() {
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if onChanged != nil {
+				onChanged()
+			}
+		})
+	}
+
+	chooseBtn := widget.NewButtonWithIcon("Choose Image…", theme.FileImageIcon(), func() {
+		d.Hide()
+		open := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil || reader == nil {
+				return
+			}
+			go func() {
+				defer func() {
+					if closeErr := reader.Close(); closeErr != nil {
+						log.Printf("Failed to close file reader: %v", closeErr)
+					}
+				}()
+				err := imgSvc.SetArtwork(context.Background(), kind, slug, reader, reader.URI().Name())
+				if err != nil {
+					err = fmt.Errorf("could not change artwork: %w", err)
+				}
+				done(err)
+			}()
+		}, window)
+		open.SetFilter(storage.NewExtensionFileFilter([]string{".png", ".jpg", ".jpeg"}))
+		open.Show()
+	})
+
+	refetchBtn := widget.NewButtonWithIcon("Re-fetch from Server", theme.ViewRefreshIcon(), func() {
+		d.Hide()
+		go func() {
+			err := imgSvc.RefetchArtwork(context.Background(), kind, slug, serverURL)
+			if err != nil {
+				err = fmt.Errorf("could not restore artwork: %w", err)
+			}
+			done(err)
+		}()
+	})
+
+	message := "Pick an image from this device, or load the server's artwork again."
+	if imgSvc.HasArtworkOverride(kind, slug) {
+		message = "This artwork was picked on this device. Re-fetching drops it and loads the server's artwork again."
+	}
+	label := widget.NewLabel(message)
+	label.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(label, chooseBtn, refetchBtn)
+	d = dialog.NewCustom("Change Artwork", "Cancel", content, window)
+	d.Resize(fyne.NewSize(420, 220))
+	d.Show()
+}
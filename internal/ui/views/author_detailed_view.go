@@ -1,11 +1,14 @@
 package views
 
 import (
+	"context"
 	"fmt"
+	"sort"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
@@ -14,24 +17,36 @@ import (
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
+// authorCoverSize is how large the author's picture is shown.
+var authorCoverSize = fyne.NewSize(200, 200)
+
 type AuthorDetailView struct {
 	imgSvc   *services.ImageService
 	songList *components.SongList
 	albums   *components.MediaGrid
 
 	root           *fyne.Container
+	parentWindow   fyne.Window
 	splitContainer *container.Split
 	backBtn        *widget.Button
+	refreshBtn     *widget.Button
+	artworkBtn     *widget.Button
 	nameLbl        *widget.Label
 	avatar         *canvas.Image
 	metaLbl        *widget.Label
+	roleSelect     *widget.Select
+
+	author      *types.Author
+	shownAlbums []*types.Album
 
-	author *types.Author
+	// coverCancel drops the pending avatar request when another author is shown.
+	coverCancel context.CancelFunc
 
 	onBack       func()
 	onPlaySong   func(*types.Song)
 	onOpenAlbum  func(string)
 	onOpenAuthor func(string)
+	onRefresh    func(string)
 }
 
 func NewAuthorDetailView(img *services.ImageService) *AuthorDetailView {
@@ -46,11 +61,23 @@ func (v *AuthorDetailView) build() {
 			v.onBack()
 		}
 	})
+	v.refreshBtn = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), func() {
+		if v.onRefresh != nil && v.author != nil {
+			v.onRefresh(v.author.Slug)
+		}
+	})
 	v.nameLbl = widget.NewLabel("")
 	v.nameLbl.TextStyle = fyne.TextStyle{Bold: true}
 	v.avatar = canvas.NewImageFromResource(theme.AccountIcon())
 	v.avatar.FillMode = canvas.ImageFillContain
 	v.metaLbl = widget.NewLabel("")
+	v.artworkBtn = widget.NewButtonWithIcon("Change Artwork", theme.FileImageIcon(), func() {
+		if v.author == nil {
+			return
+		}
+		showChangeArtwork(v.parentWindow, v.imgSvc, types.ArtworkAuthor, v.author.Slug,
+			services.AuthorImageURL(v.author, authorCoverSize), v.loadCover)
+	})
 
 	v.songList = components.NewSongList()
 	v.songList.OnPlay(func(s *types.Song, _ []*types.Song) {
@@ -71,16 +98,29 @@ func (v *AuthorDetailView) build() {
 
 	v.albums = components.NewMediaGrid(fyne.NewSize(200, 260), v.imgSvc)
 	v.albums.SetItemTapCallback(func(i int) {
-		if v.author != nil && i >= 0 && i < len(v.author.Albums) {
-			a := v.author.Albums[i]
+		if i >= 0 && i < len(v.shownAlbums) {
+			a := v.shownAlbums[i]
 			if a != nil && v.onOpenAlbum != nil {
 				v.onOpenAlbum(a.Slug)
 			}
 		}
 	})
 
-	left := container.NewGridWrap(fyne.NewSize(200, 200), v.avatar)
-	head := container.NewVBox(container.NewHBox(v.backBtn), v.nameLbl, v.metaLbl, widget.NewSeparator(), widget.NewLabel("Albums"))
+	roleOptions := []string{allRolesOption}
+	for _, role := range types.AuthorRoles {
+		roleOptions = append(roleOptions, roleLabel(role))
+	}
+	v.roleSelect = widget.NewSelect(roleOptions, func(string) { v.showSongs() })
+	v.roleSelect.SetSelected(allRolesOption)
+
+	left := container.NewVBox(container.NewGridWrap(authorCoverSize, v.avatar), v.artworkBtn)
+	head := container.NewVBox(
+		container.NewHBox(v.backBtn, layout.NewSpacer(), v.refreshBtn),
+		v.nameLbl,
+		container.NewHBox(v.metaLbl, layout.NewSpacer(), widget.NewLabel("Credits:"), v.roleSelect),
+		widget.NewSeparator(),
+		widget.NewLabel("Albums"),
+	)
 	albumsScroll := container.NewVScroll(container.NewStack(v.albums))
 
 	// Create the split container and set offset
@@ -91,10 +131,43 @@ func (v *AuthorDetailView) build() {
 	v.root = container.NewBorder(head, nil, left, nil, v.splitContainer)
 }
 
+func (v *AuthorDetailView) SetParentWindow(window fyne.Window) {
+	v.parentWindow = window
+}
+
+// loadCover shows the author's picture, the user's own when they picked one.
+func (v *AuthorDetailView) loadCover() {
+	if v.imgSvc == nil || v.author == nil {
+		return
+	}
+	if v.coverCancel != nil {
+		v.coverCancel()
+	}
+	url := v.imgSvc.AuthorCoverURL(v.author, authorCoverSize)
+	if url == "" {
+		v.avatar.Resource = theme.AccountIcon()
+		v.avatar.Refresh()
+		return
+	}
+	var ctx context.Context
+	ctx, v.coverCancel = context.WithCancel(context.Background())
+	v.imgSvc.GetImageWithSize(ctx, url, authorCoverSize, func(res fyne.Resource, err error) {
+		if err == nil && res != nil {
+			v.avatar.Resource = res
+			v.avatar.Refresh()
+		}
+	})
+}
+
 func (v *AuthorDetailView) SetCallbacks(onBack func(), onPlaySong func(*types.Song), onOpenAlbum func(string), onOpenAuthor func(string)) {
 	v.onBack, v.onPlaySong, v.onOpenAlbum, v.onOpenAuthor = onBack, onPlaySong, onOpenAlbum, onOpenAuthor
 }
 
+// SetOnRefresh sets what the refresh button does with the shown author's slug.
+func (v *AuthorDetailView) SetOnRefresh(callback func(string)) {
+	v.onRefresh = callback
+}
+
 func (v *AuthorDetailView) ShowAuthor(a *types.Author) {
 	v.author = a
 	if a == nil {
@@ -103,30 +176,19 @@ func (v *AuthorDetailView) ShowAuthor(a *types.Author) {
 	v.nameLbl.SetText(a.Name)
 	v.metaLbl.SetText(fmt.Sprintf("%d songs • %d albums", len(a.Songs), len(a.Albums)))
 
-	// avatar
-	if v.imgSvc != nil {
-		url := ""
-		if a.ImageCropped != nil && *a.ImageCropped != "" {
-			url = *a.ImageCropped
-		} else if a.Image != nil {
-			url = *a.Image
-		}
-		if url != "" {
-			v.imgSvc.GetImageWithSize(url, fyne.NewSize(200, 200), func(res fyne.Resource, err error) {
-				if err == nil && res != nil {
-					v.avatar.Resource = res
-					v.avatar.Refresh()
-				}
-			})
-		}
-	}
+	v.loadCover()
 
-	// songs
-	v.songList.SetSongs(a.Songs)
+	v.roleSelect.SetSelected(allRolesOption)
+	v.showSongs()
 
-	// albums grid items
-	items := make([]components.MediaItem, 0, len(a.Albums))
-	for _, al := range a.Albums {
+	// albums grid items, the artist's own albums before compilations they appear on
+	albums := append([]*types.Album(nil), a.Albums...)
+	sort.SliceStable(albums, func(i, j int) bool {
+		return albums[i] != nil && albums[j] != nil && !albums[i].IsCompilation() && albums[j].IsCompilation()
+	})
+	v.shownAlbums = albums
+	items := make([]components.MediaItem, 0, len(albums))
+	for _, al := range albums {
 		if al != nil {
 			items = append(items, components.MediaItemFromAlbum(al))
 		}
@@ -136,8 +198,60 @@ func (v *AuthorDetailView) ShowAuthor(a *types.Author) {
 	v.root.Refresh()
 }
 
+const allRolesOption = "All roles"
+
+func roleLabel(role types.AuthorRole) string {
+	switch role {
+	case types.AuthorRoleMain:
+		return "Main"
+	case types.AuthorRoleFeatured:
+		return "Featured"
+	case types.AuthorRoleComposer:
+		return "Composer"
+	case types.AuthorRoleRemixer:
+		return "Remixer"
+	}
+	return string(role)
+}
+
+// showSongs lists the artist's songs credited with the selected role.
+func (v *AuthorDetailView) showSongs() {
+	if v.author == nil || v.songList == nil {
+		return
+	}
+
+	selected := v.roleSelect.Selected
+	if selected == "" || selected == allRolesOption {
+		v.songList.SetSongs(v.author.Songs)
+		return
+	}
+
+	songs := make([]*types.Song, 0, len(v.author.Songs))
+	for _, s := range v.author.Songs {
+		if s == nil {
+			continue
+		}
+		role, ok := s.RoleOf(v.author.Slug)
+		if !ok {
+			role = types.AuthorRoleMain
+		}
+		if roleLabel(role) == selected {
+			songs = append(songs, s)
+		}
+	}
+	v.songList.SetSongs(songs)
+}
+
 func (v *AuthorDetailView) Container() *fyne.Container { return v.root }
 
 func (v *AuthorDetailView) SetAuthor(a *types.Author) {
 	v.ShowAuthor(a)
 }
+
+// PlaySource describes the artist for listening history.
+func (v *AuthorDetailView) PlaySource() types.PlaySource {
+	if v.author == nil {
+		return types.PlaySource{Type: types.PlaySourceAuthor}
+	}
+	return types.PlaySource{Type: types.PlaySourceAuthor, ID: v.author.Slug, Name: v.author.Name}
+}
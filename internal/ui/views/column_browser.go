@@ -0,0 +1,312 @@
+package views
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/handlers"
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// Browse layouts a view can be shown in, as stored in the config.
+const (
+	browseLayoutGrid    = "grid"
+	browseLayoutColumns = "columns"
+)
+
+// ColumnBrowser shows artists, the albums of the selected artist and the
+// songs of the selected album side by side. Selecting an artist lists all of
+// their songs until an album narrows them down; tapping a song plays the
+// column. Built without the artist column it browses albums only.
+type ColumnBrowser struct {
+	musicService *services.MusicService
+	handlers     *handlers.UIHandlers
+	debug        bool
+
+	root       fyne.CanvasObject
+	artistList *widget.List
+	albumList  *widget.List
+	songList   *widget.List
+
+	mu      sync.RWMutex
+	artists []*types.Author
+	albums  []*types.Album
+	songs   []*types.Song
+	// artist and album are what the loaded columns belong to; results of
+	// a load for an earlier selection are dropped.
+	artist *types.Author
+	album  *types.Album
+}
+
+// NewArtistColumnBrowser returns a browser of artists, their albums and songs.
+func NewArtistColumnBrowser(musicService *services.MusicService, handlers *handlers.UIHandlers, debug bool) *ColumnBrowser {
+	cb := &ColumnBrowser{musicService: musicService, handlers: handlers, debug: debug}
+	cb.setupLists()
+	songs := container.NewHSplit(cb.column("Albums", cb.albumList), cb.column("Songs", cb.songList))
+	songs.Offset = 0.4
+	split := container.NewHSplit(cb.column("Artists", cb.artistList), songs)
+	split.Offset = 0.25
+	cb.root = split
+	return cb
+}
+
+// NewAlbumColumnBrowser returns a browser of albums and their songs.
+func NewAlbumColumnBrowser(musicService *services.MusicService, handlers *handlers.UIHandlers, debug bool) *ColumnBrowser {
+	cb := &ColumnBrowser{musicService: musicService, handlers: handlers, debug: debug}
+	cb.setupLists()
+	split := container.NewHSplit(cb.column("Albums", cb.albumList), cb.column("Songs", cb.songList))
+	split.Offset = 0.35
+	cb.root = split
+	return cb
+}
+
+func (cb *ColumnBrowser) column(title string, list *widget.List) fyne.CanvasObject {
+	header := widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	return container.NewBorder(header, nil, nil, nil, list)
+}
+
+func (cb *ColumnBrowser) setupLists() {
+	cb.artistList = widget.NewList(
+		func() int {
+			cb.mu.RLock()
+			defer cb.mu.RUnlock()
+			return len(cb.artists)
+		},
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			cb.mu.RLock()
+			defer cb.mu.RUnlock()
+			if id < len(cb.artists) && cb.artists[id] != nil {
+				obj.(*widget.Label).SetText(cb.artists[id].Name)
+			}
+		},
+	)
+	cb.artistList.OnSelected = cb.onArtistSelected
+
+	cb.albumList = widget.NewList(
+		func() int {
+			cb.mu.RLock()
+			defer cb.mu.RUnlock()
+			return len(cb.albums)
+		},
+		func() fyne.CanvasObject {
+			name := widget.NewLabel("")
+			name.Truncation = fyne.TextTruncateEllipsis
+			artist := widget.NewLabel("")
+			artist.Truncation = fyne.TextTruncateEllipsis
+			artist.Importance = widget.LowImportance
+			return container.NewVBox(name, artist)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			cb.mu.RLock()
+			defer cb.mu.RUnlock()
+			if id >= len(cb.albums) || cb.albums[id] == nil {
+				return
+			}
+			rows := obj.(*fyne.Container).Objects
+			rows[0].(*widget.Label).SetText(cb.albums[id].Name)
+			rows[1].(*widget.Label).SetText(cb.albums[id].DisplayArtist())
+		},
+	)
+	cb.albumList.OnSelected = cb.onAlbumSelected
+
+	cb.songList = widget.NewList(
+		func() int {
+			cb.mu.RLock()
+			defer cb.mu.RUnlock()
+			return len(cb.songs)
+		},
+		func() fyne.CanvasObject {
+			name := widget.NewLabel("")
+			name.Truncation = fyne.TextTruncateEllipsis
+			return container.NewBorder(nil, nil, nil, widget.NewLabel("0:00"), name)
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			cb.mu.RLock()
+			defer cb.mu.RUnlock()
+			if id >= len(cb.songs) || cb.songs[id] == nil {
+				return
+			}
+			row := obj.(*fyne.Container).Objects
+			row[0].(*widget.Label).SetText(cb.songs[id].Name)
+			row[1].(*widget.Label).SetText(formatDuration(cb.songs[id].Length))
+		},
+	)
+	cb.songList.OnSelected = cb.onSongSelected
+}
+
+// SetArtists replaces the artist column. The selected artist stays selected
+// while they are still listed.
+func (cb *ColumnBrowser) SetArtists(artists []*types.Author) {
+	cb.mu.Lock()
+	cb.artists = append([]*types.Author(nil), artists...)
+	selected := -1
+	if cb.artist != nil {
+		for i, a := range cb.artists {
+			if a != nil && a.Slug == cb.artist.Slug {
+				selected = i
+				break
+			}
+		}
+		if selected < 0 {
+			cb.artist, cb.album = nil, nil
+			cb.albums, cb.songs = nil, nil
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.artistList.UnselectAll()
+	if selected >= 0 {
+		cb.artistList.Select(selected)
+	}
+	cb.artistList.Refresh()
+	cb.albumList.Refresh()
+	cb.songList.Refresh()
+}
+
+// SetAlbums replaces the album column of a browser built without artists.
+// The selected album stays selected while it is still listed.
+func (cb *ColumnBrowser) SetAlbums(albums []*types.Album) {
+	cb.mu.Lock()
+	cb.albums = append([]*types.Album(nil), albums...)
+	selected := -1
+	if cb.album != nil {
+		for i, a := range cb.albums {
+			if a != nil && a.Slug == cb.album.Slug {
+				selected = i
+				break
+			}
+		}
+		if selected < 0 {
+			cb.album, cb.songs = nil, nil
+		}
+	}
+	cb.mu.Unlock()
+
+	cb.albumList.UnselectAll()
+	if selected >= 0 {
+		cb.albumList.Select(selected)
+	}
+	cb.albumList.Refresh()
+	cb.songList.Refresh()
+}
+
+func (cb *ColumnBrowser) onArtistSelected(id widget.ListItemID) {
+	cb.mu.Lock()
+	if id >= len(cb.artists) || cb.artists[id] == nil {
+		cb.mu.Unlock()
+		return
+	}
+	artist := cb.artists[id]
+	if cb.artist != nil && cb.artist.Slug == artist.Slug {
+		cb.mu.Unlock()
+		return
+	}
+	cb.artist, cb.album = artist, nil
+	cb.albums, cb.songs = nil, nil
+	cb.mu.Unlock()
+
+	cb.albumList.UnselectAll()
+	cb.albumList.Refresh()
+	cb.songList.Refresh()
+
+	go func() {
+		detailed, err := cb.musicService.GetAuthor(context.Background(), artist.Slug)
+		if err != nil || detailed == nil {
+			if cb.debug {
+				log.Printf("[COLUMN_BROWSER] Failed to load artist %s: %v", artist.Slug, err)
+			}
+			return
+		}
+		cb.mu.Lock()
+		if cb.artist != artist {
+			cb.mu.Unlock()
+			return
+		}
+		cb.albums = detailed.Albums
+		if cb.album == nil {
+			cb.songs = detailed.Songs
+		}
+		cb.mu.Unlock()
+		fyne.Do(func() {
+			cb.albumList.Refresh()
+			cb.songList.Refresh()
+		})
+	}()
+}
+
+func (cb *ColumnBrowser) onAlbumSelected(id widget.ListItemID) {
+	cb.mu.Lock()
+	if id >= len(cb.albums) || cb.albums[id] == nil {
+		cb.mu.Unlock()
+		return
+	}
+	album := cb.albums[id]
+	if cb.album != nil && cb.album.Slug == album.Slug {
+		cb.mu.Unlock()
+		return
+	}
+	cb.album, cb.songs = album, nil
+	cb.mu.Unlock()
+
+	cb.songList.UnselectAll()
+	cb.songList.Refresh()
+
+	go func() {
+		detailed, err := cb.musicService.GetAlbum(context.Background(), album.Slug)
+		if err != nil || detailed == nil {
+			if cb.debug {
+				log.Printf("[COLUMN_BROWSER] Failed to load album %s: %v", album.Slug, err)
+			}
+			return
+		}
+		cb.mu.Lock()
+		if cb.album != album {
+			cb.mu.Unlock()
+			return
+		}
+		cb.songs = detailed.Songs
+		cb.mu.Unlock()
+		fyne.Do(func() { cb.songList.Refresh() })
+	}()
+}
+
+func (cb *ColumnBrowser) onSongSelected(id widget.ListItemID) {
+	cb.mu.RLock()
+	if id >= len(cb.songs) || cb.songs[id] == nil {
+		cb.mu.RUnlock()
+		return
+	}
+	song := cb.songs[id]
+	queue := append([]*types.Song(nil), cb.songs...)
+	source := cb.playSource()
+	cb.mu.RUnlock()
+
+	// Unselect so tapping the same song again plays it again.
+	cb.songList.UnselectAll()
+	if cb.handlers != nil {
+		cb.handlers.HandleSongSelectionFrom(song, queue, source)
+	}
+}
+
+func (cb *ColumnBrowser) playSource() types.PlaySource {
+	switch {
+	case cb.album != nil:
+		return types.PlaySource{Type: types.PlaySourceAlbum, ID: cb.album.Slug, Name: cb.album.Name}
+	case cb.artist != nil:
+		return types.PlaySource{Type: types.PlaySourceAuthor, ID: cb.artist.Slug, Name: cb.artist.Name}
+	}
+	return types.PlaySource{Type: types.PlaySourceLibrary}
+}
+
+func (cb *ColumnBrowser) Object() fyne.CanvasObject { return cb.root }
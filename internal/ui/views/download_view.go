@@ -5,9 +5,11 @@ import (
 	"log"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
@@ -23,6 +25,7 @@ type DownloadsView struct {
 	clearBtn        *widget.Button
 	pauseAllBtn     *widget.Button
 	resumeAllBtn    *widget.Button
+	parentWindow    fyne.Window
 	downloads       []*types.DownloadProgress
 	debug           bool
 }
@@ -145,6 +148,9 @@ func (dv *DownloadsView) updateDownloadItem(id widget.ListItemID, obj fyne.Canva
 		} else {
 			speedText = fmt.Sprintf("%.0f B/s", progress.Speed)
 		}
+		if progress.ETA > 0 {
+			speedText += fmt.Sprintf(" · %s left", formatDuration(int(progress.ETA.Round(time.Second).Seconds())))
+		}
 	}
 	components.speedLabel.SetText(speedText)
 
@@ -152,21 +158,31 @@ func (dv *DownloadsView) updateDownloadItem(id widget.ListItemID, obj fyne.Canva
 	switch progress.Status {
 	case types.DownloadStatusFailed:
 		components.retryBtn.Show()
+		components.retryBtn.SetIcon(theme.ViewRefreshIcon())
 		components.cancelBtn.SetIcon(theme.DeleteIcon())
 		components.retryBtn.OnTapped = func() {
-			if dv.debug {
-				log.Printf("[DOWNLOADS_VIEW] Retry requested for: %s", progress.Filename)
-			}
+			dv.retryDownload(progress.URL)
 		}
 		components.cancelBtn.OnTapped = func() {
 			dv.removeDownload(progress.URL)
 		}
 
+	case types.DownloadStatusPaused:
+		components.retryBtn.Show()
+		components.retryBtn.SetIcon(theme.MediaPlayIcon())
+		components.cancelBtn.SetIcon(theme.CancelIcon())
+		components.retryBtn.OnTapped = func() {
+			dv.resumeDownload(progress.URL)
+		}
+		components.cancelBtn.OnTapped = func() {
+			dv.cancelDownload(progress.URL)
+		}
+
 	case types.DownloadStatusDownloading:
 		components.retryBtn.Hide()
 		components.cancelBtn.SetIcon(theme.CancelIcon())
 		components.cancelBtn.OnTapped = func() {
-			dv.downloadManager.Cancel(progress.URL)
+			dv.cancelDownload(progress.URL)
 		}
 
 	case types.DownloadStatusCompleted:
@@ -180,7 +196,7 @@ func (dv *DownloadsView) updateDownloadItem(id widget.ListItemID, obj fyne.Canva
 		components.retryBtn.Hide()
 		components.cancelBtn.SetIcon(theme.CancelIcon())
 		components.cancelBtn.OnTapped = func() {
-			dv.downloadManager.Cancel(progress.URL)
+			dv.cancelDownload(progress.URL)
 		}
 	}
 
@@ -321,6 +337,7 @@ func (dv *DownloadsView) updateStatus() {
 	active := 0
 	completed := 0
 	failed := 0
+	paused := 0
 
 	for _, download := range dv.downloads {
 		switch download.Status {
@@ -330,6 +347,8 @@ func (dv *DownloadsView) updateStatus() {
 			completed++
 		case types.DownloadStatusFailed:
 			failed++
+		case types.DownloadStatusPaused:
+			paused++
 		}
 	}
 
@@ -341,8 +360,21 @@ func (dv *DownloadsView) updateStatus() {
 	} else {
 		statusText = fmt.Sprintf("%d completed, %d failed", completed, failed)
 	}
+	if paused > 0 {
+		statusText += fmt.Sprintf(", %d paused", paused)
+	}
 
 	dv.statusLabel.SetText(statusText)
+	if active > 0 {
+		dv.pauseAllBtn.Enable()
+	} else {
+		dv.pauseAllBtn.Disable()
+	}
+	if paused > 0 {
+		dv.resumeAllBtn.Enable()
+	} else {
+		dv.resumeAllBtn.Disable()
+	}
 }
 
 func (dv *DownloadsView) clearCompleted() {
@@ -352,22 +384,50 @@ func (dv *DownloadsView) clearCompleted() {
 }
 
 func (dv *DownloadsView) pauseAll() {
+	paused := dv.downloadManager.PauseAll()
 	if dv.debug {
-		log.Printf("[DOWNLOADS_VIEW] Pause all requested")
+		log.Printf("[DOWNLOADS_VIEW] Paused %d downloads", paused)
 	}
+	dv.Refresh()
 }
 
 func (dv *DownloadsView) resumeAll() {
+	resumed := dv.downloadManager.ResumeAll()
 	if dv.debug {
-		log.Printf("[DOWNLOADS_VIEW] Resume all requested")
+		log.Printf("[DOWNLOADS_VIEW] Resumed %d downloads", resumed)
 	}
+	dv.Refresh()
+}
+
+func (dv *DownloadsView) retryDownload(url string) {
+	dv.apply(url, dv.downloadManager.Retry)
+}
+
+func (dv *DownloadsView) resumeDownload(url string) {
+	dv.apply(url, dv.downloadManager.Resume)
+}
+
+func (dv *DownloadsView) cancelDownload(url string) {
+	dv.apply(url, dv.downloadManager.Cancel)
 }
 
 func (dv *DownloadsView) removeDownload(url string) {
-	if dv.debug {
-		log.Printf("[DOWNLOADS_VIEW] Remove download requested for: %s", url)
+	dv.apply(url, dv.downloadManager.Remove)
+}
+
+// apply runs a download action on url and shows its error, if any.
+func (dv *DownloadsView) apply(url string, action func(string) error) {
+	if err := action(url); err != nil {
+		log.Printf("[DOWNLOADS_VIEW] Download action failed for %s: %v", url, err)
+		if dv.parentWindow != nil {
+			dialog.ShowError(err, dv.parentWindow)
+		}
 	}
-	dv.refreshDownloads()
+	dv.Refresh()
+}
+
+func (dv *DownloadsView) SetParentWindow(window fyne.Window) {
+	dv.parentWindow = window
 }
 
 func (dv *DownloadsView) Refresh() {
@@ -0,0 +1,220 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/handlers"
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// historyPageSize is how many plays the history shows at first and adds
+// with each "Show More".
+const historyPageSize = 100
+
+// HistoryView lists the songs played, newest first and by day, with
+// actions to play a song again or a whole day as a queue.
+type HistoryView struct {
+	musicService *services.MusicService
+	handlers     *handlers.UIHandlers
+	container    *fyne.Container
+	parentWindow fyne.Window
+
+	entriesBox   *fyne.Container
+	refreshBtn   *widget.Button
+	yesterdayBtn *widget.Button
+	moreBtn      *widget.Button
+
+	entries []*types.HistoryEntry
+	limit   int
+}
+
+func NewHistoryView(musicService *services.MusicService, handlers *handlers.UIHandlers) *HistoryView {
+	hv := &HistoryView{
+		musicService: musicService,
+		handlers:     handlers,
+		limit:        historyPageSize,
+	}
+
+	hv.setupWidgets()
+	hv.setupLayout()
+	return hv
+}
+
+func (hv *HistoryView) setupWidgets() {
+	hv.refreshBtn = widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), hv.Refresh)
+	hv.yesterdayBtn = widget.NewButtonWithIcon("Play Yesterday Again", theme.MediaReplayIcon(), nil)
+	hv.yesterdayBtn.OnTapped = func() {
+		hv.playDay(startOfDay(time.Now()).AddDate(0, 0, -1), hv.yesterdayBtn)
+	}
+	hv.moreBtn = widget.NewButton("Show More", func() {
+		hv.limit += historyPageSize
+		hv.Refresh()
+	})
+	hv.moreBtn.Hide()
+	hv.entriesBox = container.NewVBox(widget.NewLabel("Loading..."))
+}
+
+func (hv *HistoryView) setupLayout() {
+	header := container.NewBorder(
+		nil, nil,
+		widget.NewLabel("History"),
+		container.NewHBox(hv.yesterdayBtn, hv.refreshBtn),
+		nil,
+	)
+
+	content := container.NewVBox(header, widget.NewSeparator(), hv.entriesBox, hv.moreBtn)
+	hv.container = container.NewBorder(nil, nil, nil, nil, container.NewScroll(content))
+}
+
+// Refresh reloads the history from storage.
+func (hv *HistoryView) Refresh() {
+	limit := hv.limit
+	go func() {
+		entries, err := hv.musicService.GetPlayHistory(context.Background(), limit, 0)
+		if err != nil {
+			log.Printf("[HISTORY_VIEW] Failed to load history: %v", err)
+			return
+		}
+		fyne.Do(func() {
+			hv.entries = entries
+			hv.updateEntries(len(entries) == limit)
+		})
+	}()
+}
+
+func (hv *HistoryView) updateEntries(more bool) {
+	hv.entriesBox.RemoveAll()
+	if more {
+		hv.moreBtn.Show()
+	} else {
+		hv.moreBtn.Hide()
+	}
+
+	if len(hv.entries) == 0 {
+		hv.entriesBox.Add(widget.NewLabel("Nothing played yet"))
+		return
+	}
+
+	now := time.Now()
+	var day time.Time
+	for _, entry := range hv.entries {
+		if played := startOfDay(entry.PlayedAt.Local()); !played.Equal(day) {
+			day = played
+			hv.entriesBox.Add(hv.createDayHeader(day, now))
+		}
+		hv.entriesBox.Add(hv.createEntryRow(entry))
+	}
+}
+
+func (hv *HistoryView) createDayHeader(day, now time.Time) fyne.CanvasObject {
+	title := widget.NewLabelWithStyle(describeDay(day, now), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	playBtn := widget.NewButtonWithIcon("Play This Day", theme.MediaPlayIcon(), nil)
+	playBtn.Importance = widget.LowImportance
+	playBtn.OnTapped = func() {
+		hv.playDay(day, playBtn)
+	}
+	return container.NewVBox(widget.NewSeparator(), container.NewBorder(nil, nil, nil, playBtn, title))
+}
+
+func (hv *HistoryView) createEntryRow(entry *types.HistoryEntry) fyne.CanvasObject {
+	when := widget.NewLabel(entry.PlayedAt.Local().Format("15:04"))
+	when.Importance = widget.LowImportance
+
+	playBtn := widget.NewButtonWithIcon("", theme.MediaPlayIcon(), func() {
+		hv.playEntry(entry)
+	})
+	playBtn.Importance = widget.LowImportance
+
+	name, details := entry.SongSlug, "No longer in your library"
+	if entry.Song != nil {
+		name, details = entry.Song.Name, getArtistNames(entry.Song.Authors)
+	} else {
+		playBtn.Disable()
+	}
+	title := widget.NewLabelWithStyle(name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	title.Truncation = fyne.TextTruncateEllipsis
+	subtitle := widget.NewLabel(details)
+	subtitle.Truncation = fyne.TextTruncateEllipsis
+
+	return container.NewBorder(nil, nil, when, playBtn, container.NewVBox(title, subtitle))
+}
+
+// describeDay renders day as "Today", "Yesterday" or "Monday, Jan 2".
+func describeDay(day, now time.Time) string {
+	today := startOfDay(now)
+	switch {
+	case day.Equal(today):
+		return "Today"
+	case day.Equal(today.AddDate(0, 0, -1)):
+		return "Yesterday"
+	case day.Year() != today.Year():
+		return day.Format("Monday, Jan 2, 2006")
+	default:
+		return day.Format("Monday, Jan 2")
+	}
+}
+
+func (hv *HistoryView) playEntry(entry *types.HistoryEntry) {
+	if hv.handlers == nil || entry.Song == nil {
+		return
+	}
+	source := types.PlaySource{
+		Type: types.PlaySourceHistory,
+		Name: entry.PlayedAt.Local().Format("Jan 2, 15:04"),
+	}
+	hv.handlers.HandleSongSelectionFrom(entry.Song, []*types.Song{entry.Song}, source)
+}
+
+// playDay rebuilds what was listened to on day and plays it as a queue.
+func (hv *HistoryView) playDay(day time.Time, btn *widget.Button) {
+	if hv.handlers == nil {
+		return
+	}
+	btn.Disable()
+
+	go func() {
+		songs, err := hv.musicService.GetDayQueue(context.Background(), day)
+		fyne.Do(func() {
+			btn.Enable()
+			if err != nil {
+				log.Printf("[HISTORY_VIEW] Failed to load the plays of %s: %v", day.Format(time.DateOnly), err)
+				if hv.parentWindow != nil {
+					dialog.ShowError(err, hv.parentWindow)
+				}
+				return
+			}
+			if len(songs) == 0 {
+				if hv.parentWindow != nil {
+					dialog.ShowInformation("Play Again",
+						fmt.Sprintf("No songs played on %s are in your library.", day.Format("Jan 2")), hv.parentWindow)
+				}
+				return
+			}
+
+			source := types.PlaySource{
+				Type: types.PlaySourceHistory,
+				ID:   day.Format(time.DateOnly),
+				Name: day.Format("Jan 2"),
+			}
+			hv.handlers.HandleSongSelectionFrom(songs[0], songs, source)
+		})
+	}()
+}
+
+func (hv *HistoryView) SetParentWindow(window fyne.Window) {
+	hv.parentWindow = window
+}
+
+func (hv *HistoryView) Container() *fyne.Container {
+	return hv.container
+}
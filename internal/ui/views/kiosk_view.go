@@ -0,0 +1,267 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// kioskCoverSize is how large the playing song's cover is shown.
+var kioskCoverSize = fyne.NewSize(320, 320)
+
+// KioskView fills the window in kiosk mode: the song playing, what comes
+// next, and a search whose results are queued with a tap. Nothing else of
+// the library, and no settings, can be reached from it.
+type KioskView struct {
+	musicService *services.MusicService
+	imageService *services.ImageService
+
+	container   *fyne.Container
+	cover       *canvas.Image
+	titleLabel  *widget.Label
+	artistLabel *widget.Label
+	upNextList  *widget.List
+	searchEntry *widget.Entry
+	resultList  *widget.List
+	statusLabel *widget.Label
+
+	mu           sync.RWMutex
+	current      *types.Song
+	upcoming     []*types.Song
+	results      []*types.Song
+	searchTimer  *time.Timer
+	searchCancel context.CancelFunc
+	coverCancel  context.CancelFunc
+
+	onQueue func(*types.Song)
+}
+
+func NewKioskView(musicService *services.MusicService, imageService *services.ImageService) *KioskView {
+	kv := &KioskView{musicService: musicService, imageService: imageService}
+	kv.setupWidgets()
+	kv.setupLayout()
+	return kv
+}
+
+func (kv *KioskView) setupWidgets() {
+	kv.cover = canvas.NewImageFromResource(theme.MediaMusicIcon())
+	kv.cover.FillMode = canvas.ImageFillContain
+	kv.cover.SetMinSize(kioskCoverSize)
+
+	kv.titleLabel = widget.NewLabelWithStyle("Nothing playing", fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	kv.titleLabel.SizeName = theme.SizeNameHeadingText
+	kv.titleLabel.Truncation = fyne.TextTruncateEllipsis
+	kv.artistLabel = widget.NewLabelWithStyle("Search for a song to start the music", fyne.TextAlignCenter, fyne.TextStyle{})
+	kv.artistLabel.Truncation = fyne.TextTruncateEllipsis
+
+	kv.upNextList = widget.NewList(
+		func() int {
+			kv.mu.RLock()
+			defer kv.mu.RUnlock()
+			return len(kv.upcoming)
+		},
+		kv.newSongRow,
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			kv.mu.RLock()
+			defer kv.mu.RUnlock()
+			if id < len(kv.upcoming) {
+				kv.updateSongRow(kv.upcoming[id], obj)
+			}
+		},
+	)
+
+	kv.searchEntry = widget.NewEntry()
+	kv.searchEntry.SetPlaceHolder("Search songs to add to the queue…")
+	kv.searchEntry.OnChanged = kv.onSearchChanged
+
+	kv.resultList = widget.NewList(
+		func() int {
+			kv.mu.RLock()
+			defer kv.mu.RUnlock()
+			return len(kv.results)
+		},
+		kv.newSongRow,
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			kv.mu.RLock()
+			defer kv.mu.RUnlock()
+			if id < len(kv.results) {
+				kv.updateSongRow(kv.results[id], obj)
+			}
+		},
+	)
+	kv.resultList.OnSelected = kv.onResultSelected
+
+	kv.statusLabel = widget.NewLabel("")
+}
+
+func (kv *KioskView) setupLayout() {
+	nowPlaying := container.NewVBox(
+		container.NewCenter(kv.cover),
+		kv.titleLabel,
+		kv.artistLabel,
+	)
+	upNextHeader := widget.NewLabelWithStyle("Up Next", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	left := container.NewBorder(nowPlaying, nil, nil, nil,
+		container.NewBorder(upNextHeader, nil, nil, nil, kv.upNextList))
+
+	searchHeader := container.NewVBox(kv.searchEntry, kv.statusLabel)
+	right := container.NewBorder(searchHeader, nil, nil, nil, kv.resultList)
+
+	split := container.NewHSplit(left, right)
+	split.Offset = 0.45
+	kv.container = container.NewStack(split)
+}
+
+func (kv *KioskView) newSongRow() fyne.CanvasObject {
+	name := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	name.Truncation = fyne.TextTruncateEllipsis
+	artist := widget.NewLabel("")
+	artist.Truncation = fyne.TextTruncateEllipsis
+	artist.Importance = widget.LowImportance
+	return container.NewBorder(nil, nil, nil, widget.NewLabel("0:00"), container.NewVBox(name, artist))
+}
+
+func (kv *KioskView) updateSongRow(song *types.Song, obj fyne.CanvasObject) {
+	if song == nil {
+		return
+	}
+	row := obj.(*fyne.Container).Objects
+	text := row[0].(*fyne.Container).Objects
+	text[0].(*widget.Label).SetText(song.Name)
+	text[1].(*widget.Label).SetText(getArtistNames(song.Authors))
+	row[1].(*widget.Label).SetText(formatDuration(song.Length))
+}
+
+// SetNowPlaying shows the current song and the ones queued after it.
+func (kv *KioskView) SetNowPlaying(current *types.Song, upcoming []*types.Song) {
+	kv.mu.Lock()
+	changed := current != kv.current
+	kv.current = current
+	kv.upcoming = append([]*types.Song(nil), upcoming...)
+	kv.mu.Unlock()
+
+	kv.upNextList.Refresh()
+	if !changed {
+		return
+	}
+	if current == nil {
+		kv.titleLabel.SetText("Nothing playing")
+		kv.artistLabel.SetText("Search for a song to start the music")
+	} else {
+		kv.titleLabel.SetText(current.Name)
+		kv.artistLabel.SetText(getArtistNames(current.Authors))
+	}
+	kv.loadCover(current)
+}
+
+func (kv *KioskView) loadCover(song *types.Song) {
+	if kv.coverCancel != nil {
+		kv.coverCancel()
+	}
+	url := ""
+	if kv.imageService != nil {
+		url = kv.imageService.SongCoverURL(song, kioskCoverSize)
+	}
+	if url == "" {
+		kv.cover.Resource = theme.MediaMusicIcon()
+		kv.cover.Refresh()
+		return
+	}
+	var ctx context.Context
+	ctx, kv.coverCancel = context.WithCancel(context.Background())
+	kv.imageService.GetImageWithSize(ctx, url, kioskCoverSize, func(res fyne.Resource, err error) {
+		if err != nil || res == nil {
+			res = theme.MediaMusicIcon()
+		}
+		kv.cover.Resource = res
+		kv.cover.Refresh()
+	})
+}
+
+func (kv *KioskView) onSearchChanged(q string) {
+	if kv.searchTimer != nil {
+		kv.searchTimer.Stop()
+	}
+	kv.searchTimer = time.AfterFunc(300*time.Millisecond, func() { kv.performSearch(q) })
+}
+
+func (kv *KioskView) performSearch(q string) {
+	kv.mu.Lock()
+	if kv.searchCancel != nil {
+		kv.searchCancel()
+		kv.searchCancel = nil
+	}
+	if q == "" {
+		kv.results = nil
+		kv.mu.Unlock()
+		fyne.Do(func() {
+			kv.statusLabel.SetText("")
+			kv.resultList.Refresh()
+		})
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	kv.searchCancel = cancel
+	kv.mu.Unlock()
+
+	fyne.Do(func() { kv.statusLabel.SetText("Searching…") })
+	go func() {
+		err := kv.musicService.SearchProgressive(ctx, q, func(results *types.SearchResponse, done bool) {
+			if ctx.Err() != nil {
+				return
+			}
+			kv.mu.Lock()
+			kv.results = results.Songs
+			kv.mu.Unlock()
+			fyne.Do(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				if len(results.Songs) == 0 && done {
+					kv.statusLabel.SetText("No songs found")
+				} else {
+					kv.statusLabel.SetText("Tap a song to add it to the queue")
+				}
+				kv.resultList.UnselectAll()
+				kv.resultList.Refresh()
+			})
+		})
+		if err != nil && ctx.Err() == nil {
+			fyne.Do(func() { kv.statusLabel.SetText(fmt.Sprintf("Search error: %v", err)) })
+		}
+	}()
+}
+
+func (kv *KioskView) onResultSelected(id widget.ListItemID) {
+	kv.mu.RLock()
+	if id >= len(kv.results) || kv.results[id] == nil {
+		kv.mu.RUnlock()
+		return
+	}
+	song := kv.results[id]
+	kv.mu.RUnlock()
+
+	kv.resultList.UnselectAll()
+	if kv.onQueue != nil {
+		kv.onQueue(song)
+	}
+	kv.statusLabel.SetText(fmt.Sprintf("Added %s to the queue", song.Name))
+}
+
+// OnQueue is called with a song picked from the search results.
+func (kv *KioskView) OnQueue(cb func(*types.Song)) { kv.onQueue = cb }
+
+// FocusSearch puts the cursor in the search field of canvas.
+func (kv *KioskView) FocusSearch(c fyne.Canvas) { c.Focus(kv.searchEntry) }
+
+func (kv *KioskView) Container() *fyne.Container { return kv.container }
@@ -0,0 +1,195 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/handlers"
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// LibraryReportView lists the albums only some of whose tracks are on this
+// device, measured against the track lists of the server, with an action
+// to download the rest of each.
+type LibraryReportView struct {
+	musicService *services.MusicService
+	handlers     *handlers.UIHandlers
+	container    *fyne.Container
+	parentWindow fyne.Window
+
+	summaryLabel *widget.Label
+	gapsBox      *fyne.Container
+	refreshBtn   *widget.Button
+	checkBtn     *widget.Button
+}
+
+func NewLibraryReportView(musicService *services.MusicService, handlers *handlers.UIHandlers) *LibraryReportView {
+	rv := &LibraryReportView{
+		musicService: musicService,
+		handlers:     handlers,
+	}
+
+	rv.setupWidgets()
+	rv.setupLayout()
+	return rv
+}
+
+func (rv *LibraryReportView) setupWidgets() {
+	rv.refreshBtn = widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), rv.Refresh)
+	rv.checkBtn = widget.NewButtonWithIcon("Check Track Counts", theme.SearchIcon(), rv.checkTrackCounts)
+	rv.summaryLabel = widget.NewLabel("")
+	rv.summaryLabel.Wrapping = fyne.TextWrapWord
+	rv.gapsBox = container.NewVBox(widget.NewLabel("Loading..."))
+}
+
+func (rv *LibraryReportView) setupLayout() {
+	header := container.NewBorder(
+		nil, nil,
+		widget.NewLabel("Library Report"),
+		container.NewHBox(rv.checkBtn, rv.refreshBtn),
+		nil,
+	)
+
+	content := container.NewVBox(header, rv.summaryLabel, widget.NewSeparator(), rv.gapsBox)
+	rv.container = container.NewBorder(nil, nil, nil, nil, container.NewScroll(content))
+}
+
+// Refresh reloads the incomplete albums from storage.
+func (rv *LibraryReportView) Refresh() {
+	go func() {
+		gaps, err := rv.musicService.GetAlbumGaps(context.Background())
+		if err != nil {
+			log.Printf("[LIBRARY_REPORT] Failed to load album gaps: %v", err)
+			return
+		}
+		fyne.Do(func() {
+			rv.updateGaps(gaps)
+		})
+	}()
+}
+
+func (rv *LibraryReportView) updateGaps(gaps []*types.AlbumGap) {
+	rv.gapsBox.RemoveAll()
+	if len(gaps) == 0 {
+		rv.summaryLabel.SetText("")
+		rv.gapsBox.Add(widget.NewLabel("Every album on this device is complete"))
+		return
+	}
+
+	missing, unchecked := 0, 0
+	for _, gap := range gaps {
+		if gap.Tracks == 0 {
+			unchecked++
+		} else {
+			missing += gap.Tracks - gap.Owned
+		}
+	}
+	summary := fmt.Sprintf("%d incomplete albums, %d tracks missing.", len(gaps)-unchecked, missing)
+	if unchecked > 0 {
+		summary += fmt.Sprintf(" The track counts of %d more albums are not known yet; check them with the server to see if they are complete.", unchecked)
+	}
+	rv.summaryLabel.SetText(summary)
+
+	for _, gap := range gaps {
+		rv.gapsBox.Add(rv.createGapRow(gap))
+	}
+}
+
+func (rv *LibraryReportView) createGapRow(gap *types.AlbumGap) fyne.CanvasObject {
+	album := gap.Album
+	title := widget.NewLabelWithStyle(album.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	title.Truncation = fyne.TextTruncateEllipsis
+
+	details := fmt.Sprintf("%d of %d tracks", gap.Owned, gap.Tracks)
+	if gap.Tracks == 0 {
+		details = fmt.Sprintf("%d tracks, total not checked", gap.Owned)
+	}
+	if artist := album.DisplayArtist(); artist != "" {
+		details = artist + " · " + details
+	}
+	subtitle := widget.NewLabel(details)
+	subtitle.Truncation = fyne.TextTruncateEllipsis
+
+	openBtn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() {
+		if rv.handlers != nil {
+			rv.handlers.HandleAlbumSelection(album)
+		}
+	})
+	openBtn.Importance = widget.LowImportance
+
+	completeBtn := widget.NewButtonWithIcon("Complete Album", theme.DownloadIcon(), nil)
+	completeBtn.OnTapped = func() {
+		rv.completeAlbum(album, completeBtn)
+	}
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(openBtn, completeBtn), container.NewVBox(title, subtitle))
+}
+
+// completeAlbum fetches the album's track list and downloads the tracks
+// not on this device yet.
+func (rv *LibraryReportView) completeAlbum(album *types.Album, btn *widget.Button) {
+	if rv.handlers == nil {
+		return
+	}
+	btn.Disable()
+
+	go func() {
+		songs, err := rv.musicService.GetMissingAlbumSongs(context.Background(), album.Slug)
+		fyne.Do(func() {
+			if err != nil {
+				btn.Enable()
+				log.Printf("[LIBRARY_REPORT] Failed to load the tracks of %s: %v", album.Name, err)
+				if rv.parentWindow != nil {
+					dialog.ShowError(fmt.Errorf("couldn't load the tracks of %s: %w", album.Name, err), rv.parentWindow)
+				}
+				return
+			}
+
+			for _, song := range songs {
+				if err := rv.handlers.HandleDownloadSong(song); err != nil {
+					log.Printf("[LIBRARY_REPORT] Failed to queue %s: %v", song.Name, err)
+				}
+			}
+			if len(songs) == 0 {
+				btn.SetText("Complete")
+			} else {
+				btn.SetText(fmt.Sprintf("Downloading %d", len(songs)))
+			}
+		})
+	}()
+}
+
+// checkTrackCounts fetches the track lists of the albums whose track count
+// is not known yet, then reloads the report.
+func (rv *LibraryReportView) checkTrackCounts() {
+	rv.checkBtn.Disable()
+
+	go func() {
+		checked, err := rv.musicService.CheckAlbumGaps(context.Background())
+		if err != nil {
+			log.Printf("[LIBRARY_REPORT] Failed to check track counts: %v", err)
+		} else {
+			log.Printf("[LIBRARY_REPORT] Checked the track counts of %d albums", checked)
+		}
+		fyne.Do(func() {
+			rv.checkBtn.Enable()
+			rv.Refresh()
+		})
+	}()
+}
+
+func (rv *LibraryReportView) SetParentWindow(window fyne.Window) {
+	rv.parentWindow = window
+}
+
+func (rv *LibraryReportView) Container() *fyne.Container {
+	return rv.container
+}
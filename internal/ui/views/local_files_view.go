@@ -0,0 +1,407 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/handlers"
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
+)
+
+// LocalFilesView lists the songs with a file on this machine and manages
+// the downloaded ones in bulk.
+type LocalFilesView struct {
+	handlers     *handlers.UIHandlers
+	container    *fyne.Container
+	parentWindow fyne.Window
+	undoBar      *components.UndoBar
+
+	list         *widget.List
+	summaryLabel *widget.Label
+	selectAll    *widget.Check
+	deleteBtn    *widget.Button
+	redownload   *widget.Button
+	verifyBtn    *widget.Button
+	refreshBtn   *widget.Button
+	removedBtn   *widget.Button
+
+	files    []*storage.LocalFile
+	selected map[string]bool
+	corrupt  map[string]bool
+	// deleting holds the songs whose files are deleted once the undo
+	// window passes; they are left out of the list until then.
+	deleting map[string]bool
+	busy     bool
+}
+
+func NewLocalFilesView(h *handlers.UIHandlers) *LocalFilesView {
+	lv := &LocalFilesView{
+		handlers: h,
+		selected: make(map[string]bool),
+		corrupt:  make(map[string]bool),
+		deleting: make(map[string]bool),
+	}
+
+	lv.setupWidgets()
+	lv.setupLayout()
+	return lv
+}
+
+func (lv *LocalFilesView) setupWidgets() {
+	lv.summaryLabel = widget.NewLabel("Loading...")
+	lv.selectAll = widget.NewCheck("Select all", func(checked bool) {
+		lv.selected = make(map[string]bool)
+		if checked {
+			for _, file := range lv.files {
+				lv.selected[file.Song.Slug] = true
+			}
+		}
+		lv.list.Refresh()
+		lv.updateActions()
+	})
+
+	lv.deleteBtn = widget.NewButtonWithIcon("Delete Local Copy", theme.DeleteIcon(), lv.deleteSelected)
+	lv.deleteBtn.Importance = widget.DangerImportance
+	lv.redownload = widget.NewButtonWithIcon("Re-download", theme.DownloadIcon(), lv.redownloadSelected)
+	lv.verifyBtn = widget.NewButtonWithIcon("Verify", theme.ConfirmIcon(), lv.verifySelected)
+	lv.refreshBtn = widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), lv.Refresh)
+	lv.removedBtn = widget.NewButtonWithIcon("Recently Removed", theme.HistoryIcon(), lv.showRemovedDownloads)
+
+	lv.list = widget.NewList(
+		func() int { return len(lv.files) },
+		lv.createRow,
+		lv.updateRow,
+	)
+	lv.updateActions()
+}
+
+func (lv *LocalFilesView) setupLayout() {
+	header := container.NewBorder(
+		nil, nil,
+		widget.NewLabel("On This Device"),
+		container.NewHBox(lv.removedBtn, lv.refreshBtn),
+		nil,
+	)
+
+	hint := widget.NewLabel("Songs with a file on this computer. Files from your library folders are listed but never deleted.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	actions := container.NewHBox(lv.selectAll, widget.NewSeparator(), lv.verifyBtn, lv.redownload, lv.deleteBtn)
+	top := container.NewVBox(header, hint, lv.summaryLabel, actions, widget.NewSeparator())
+	lv.container = container.NewBorder(top, nil, nil, nil, lv.list)
+}
+
+func (lv *LocalFilesView) createRow() fyne.CanvasObject {
+	check := widget.NewCheck("", nil)
+	title := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	title.Truncation = fyne.TextTruncateEllipsis
+	details := widget.NewLabel("")
+	details.Truncation = fyne.TextTruncateEllipsis
+	size := widget.NewLabel("")
+
+	return container.NewBorder(nil, nil, check, size, container.NewVBox(title, details))
+}
+
+func (lv *LocalFilesView) updateRow(id widget.ListItemID, obj fyne.CanvasObject) {
+	if id >= len(lv.files) {
+		return
+	}
+	file := lv.files[id]
+	row := obj.(*fyne.Container)
+	texts := row.Objects[0].(*fyne.Container)
+	check := row.Objects[1].(*widget.Check)
+	size := row.Objects[2].(*widget.Label)
+
+	slug := file.Song.Slug
+	check.OnChanged = nil
+	check.SetChecked(lv.selected[slug])
+	check.OnChanged = func(checked bool) {
+		if checked {
+			lv.selected[slug] = true
+		} else {
+			delete(lv.selected, slug)
+		}
+		lv.updateActions()
+	}
+
+	texts.Objects[0].(*widget.Label).SetText(file.Song.Name)
+	texts.Objects[1].(*widget.Label).SetText(lv.describe(file))
+	if file.Missing() {
+		size.SetText("—")
+	} else {
+		size.SetText(formatBytes(file.Size))
+	}
+}
+
+// describe renders "Artist · Downloaded · /path/to/file".
+func (lv *LocalFilesView) describe(file *storage.LocalFile) string {
+	status := "Downloaded"
+	switch {
+	case file.Missing():
+		status = "File missing"
+	case lv.corrupt[file.Song.Slug]:
+		status = "Damaged, re-download it"
+	case file.Song.IsLocalOnly():
+		status = "Library file"
+	}
+
+	artist := getArtistNames(file.Song.Authors)
+	if artist == "" {
+		return status + " · " + file.Path
+	}
+	return artist + " · " + status + " · " + file.Path
+}
+
+// Refresh reloads the local files from storage.
+func (lv *LocalFilesView) Refresh() {
+	go func() {
+		files, err := lv.handlers.Music().GetLocalFiles(context.Background())
+		if err != nil {
+			log.Printf("[LOCAL_FILES_VIEW] Failed to load local files: %v", err)
+			return
+		}
+		fyne.Do(func() {
+			lv.files = nil
+			for _, file := range files {
+				if !lv.deleting[file.Song.Slug] {
+					lv.files = append(lv.files, file)
+				}
+			}
+			present := make(map[string]bool, len(files))
+			for _, file := range lv.files {
+				present[file.Song.Slug] = true
+			}
+			for slug := range lv.selected {
+				if !present[slug] {
+					delete(lv.selected, slug)
+				}
+			}
+			lv.updateSummary()
+			lv.updateActions()
+			lv.list.Refresh()
+		})
+	}()
+}
+
+func (lv *LocalFilesView) updateSummary() {
+	var total int64
+	downloads, library, missing := 0, 0, 0
+	for _, file := range lv.files {
+		switch {
+		case file.Missing():
+			missing++
+			continue
+		case file.Song.IsLocalOnly():
+			library++
+		default:
+			downloads++
+		}
+		total += file.Size
+	}
+
+	if len(lv.files) == 0 {
+		lv.summaryLabel.SetText("No songs are stored on this computer")
+		return
+	}
+	text := fmt.Sprintf("%d downloaded, %d from library folders, %s in total", downloads, library, formatBytes(total))
+	if missing > 0 {
+		text += fmt.Sprintf(", %d missing", missing)
+	}
+	lv.summaryLabel.SetText(text)
+}
+
+func (lv *LocalFilesView) updateActions() {
+	downloads := len(lv.selectedDownloads())
+	if lv.busy || len(lv.selected) == 0 {
+		lv.verifyBtn.Disable()
+	} else {
+		lv.verifyBtn.Enable()
+	}
+	if lv.busy || downloads == 0 {
+		lv.deleteBtn.Disable()
+		lv.redownload.Disable()
+	} else {
+		lv.deleteBtn.Enable()
+		lv.redownload.Enable()
+	}
+}
+
+// selectedFiles returns the selected entries in list order.
+func (lv *LocalFilesView) selectedFiles() []*storage.LocalFile {
+	var files []*storage.LocalFile
+	for _, file := range lv.files {
+		if lv.selected[file.Song.Slug] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// selectedDownloads returns the selected entries that were downloaded from
+// the server, leaving out library files.
+func (lv *LocalFilesView) selectedDownloads() []*storage.LocalFile {
+	var files []*storage.LocalFile
+	for _, file := range lv.selectedFiles() {
+		if !file.Song.IsLocalOnly() {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// deleteSelected deletes the files of the selected downloads. With an undo
+// bar they disappear from the list at once and are deleted when the undo
+// window passes; without one the user confirms first.
+func (lv *LocalFilesView) deleteSelected() {
+	files := lv.selectedDownloads()
+	if len(files) == 0 {
+		return
+	}
+	deleteFiles := func() {
+		lv.run(func(ctx context.Context) error {
+			_, err := lv.handlers.Music().RemoveDownloads(ctx, files)
+			fyne.Do(func() {
+				for _, file := range files {
+					delete(lv.deleting, file.Song.Slug)
+				}
+			})
+			return err
+		})
+	}
+
+	if lv.undoBar == nil {
+		if lv.parentWindow == nil {
+			return
+		}
+		message := fmt.Sprintf("Delete the downloaded files of %d songs? They stay in the library and can be streamed or downloaded again.", len(files))
+		dialog.ShowConfirm("Delete Local Copies", message, func(confirmed bool) {
+			if confirmed {
+				deleteFiles()
+			}
+		}, lv.parentWindow)
+		return
+	}
+
+	for _, file := range files {
+		lv.deleting[file.Song.Slug] = true
+		delete(lv.selected, file.Song.Slug)
+	}
+	lv.Refresh()
+
+	message := fmt.Sprintf("Deleted the downloaded files of %d songs", len(files))
+	if len(files) == 1 {
+		message = fmt.Sprintf("Deleted the downloaded file of %s", files[0].Song.Name)
+	}
+	lv.undoBar.Defer(message, deleteFiles, func() {
+		for _, file := range files {
+			delete(lv.deleting, file.Song.Slug)
+		}
+		lv.Refresh()
+	})
+}
+
+// redownloadSelected replaces the files of the selected downloads with fresh
+// copies from the server.
+func (lv *LocalFilesView) redownloadSelected() {
+	files := lv.selectedDownloads()
+	for _, file := range files {
+		delete(lv.corrupt, file.Song.Slug)
+	}
+	lv.run(func(ctx context.Context) error {
+		if _, err := lv.handlers.Music().DeleteLocalCopies(ctx, files); err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := lv.handlers.DownloadManager.DownloadSong(context.Background(), file.Song); err != nil {
+				log.Printf("[LOCAL_FILES_VIEW] Failed to start download of %s: %v", file.Song.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (lv *LocalFilesView) verifySelected() {
+	files := lv.selectedFiles()
+	lv.run(func(ctx context.Context) error {
+		result, err := lv.handlers.Music().VerifyLocalCopies(ctx, files)
+		if err != nil {
+			return err
+		}
+		fyne.Do(func() {
+			lv.showVerifyResult(result)
+		})
+		return nil
+	})
+}
+
+func (lv *LocalFilesView) showVerifyResult(result *services.VerifyResult) {
+	for _, song := range result.Corrupt {
+		lv.corrupt[song.Slug] = true
+	}
+	if lv.parentWindow == nil {
+		return
+	}
+
+	message := fmt.Sprintf("%d files are intact.", result.OK)
+	if result.Recorded > 0 {
+		message += fmt.Sprintf("\n%d files had no checksum yet; it was recorded now.", result.Recorded)
+	}
+	if result.Missing > 0 {
+		message += fmt.Sprintf("\n%d files are missing.", result.Missing)
+	}
+	if len(result.Corrupt) > 0 {
+		message += fmt.Sprintf("\n%d files changed since they were downloaded and are selected for re-download.", len(result.Corrupt))
+		lv.selected = make(map[string]bool)
+		for _, song := range result.Corrupt {
+			lv.selected[song.Slug] = true
+		}
+		lv.updateActions()
+	}
+	dialog.ShowInformation("Verify Files", message, lv.parentWindow)
+}
+
+// run does a bulk action in the background with the actions disabled, then
+// reloads the list.
+func (lv *LocalFilesView) run(action func(ctx context.Context) error) {
+	lv.busy = true
+	lv.updateActions()
+	go func() {
+		err := action(context.Background())
+		fyne.Do(func() {
+			lv.busy = false
+			if err != nil {
+				log.Printf("[LOCAL_FILES_VIEW] Bulk action failed: %v", err)
+				lv.showError(err)
+			}
+			lv.Refresh()
+		})
+	}()
+}
+
+func (lv *LocalFilesView) showError(err error) {
+	if lv.parentWindow != nil {
+		dialog.ShowError(err, lv.parentWindow)
+	}
+}
+
+// SetUndoBar sets where deleting local copies offers an undo.
+func (lv *LocalFilesView) SetUndoBar(bar *components.UndoBar) {
+	lv.undoBar = bar
+}
+
+func (lv *LocalFilesView) SetParentWindow(window fyne.Window) {
+	lv.parentWindow = window
+}
+
+func (lv *LocalFilesView) Container() *fyne.Container {
+	return lv.container
+}
@@ -0,0 +1,199 @@
+package views
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// lyricsPanel shows the lyrics of the song on the detail page. Synced
+// lyrics follow playback while the song plays, marking the line being sung
+// and keeping it in the middle.
+type lyricsPanel struct {
+	svc *services.LyricsService
+
+	root        *fyne.Container
+	sourceLbl   *widget.Label
+	statusLbl   *widget.Label
+	list        *widget.List
+	plainLbl    *widget.Label
+	plainScroll *container.Scroll
+
+	slug    string
+	lines   []services.LyricLine
+	current int
+	cancel  context.CancelFunc
+}
+
+func newLyricsPanel() *lyricsPanel {
+	p := &lyricsPanel{current: -1}
+
+	title := widget.NewLabel("Lyrics")
+	title.TextStyle = fyne.TextStyle{Bold: true}
+	p.sourceLbl = widget.NewLabel("")
+	p.sourceLbl.Importance = widget.LowImportance
+	p.statusLbl = widget.NewLabel("")
+	p.statusLbl.Alignment = fyne.TextAlignCenter
+	p.statusLbl.Importance = widget.LowImportance
+
+	p.list = widget.NewList(
+		func() int { return len(p.lines) },
+		func() fyne.CanvasObject {
+			label := widget.NewLabel("")
+			label.Alignment = fyne.TextAlignCenter
+			label.Truncation = fyne.TextTruncateEllipsis
+			return label
+		},
+		p.updateLine,
+	)
+	p.list.OnSelected = func(widget.ListItemID) { p.list.UnselectAll() }
+
+	p.plainLbl = widget.NewLabel("")
+	p.plainLbl.Alignment = fyne.TextAlignCenter
+	p.plainLbl.Wrapping = fyne.TextWrapWord
+	p.plainScroll = container.NewVScroll(p.plainLbl)
+
+	header := container.NewHBox(title, layout.NewSpacer(), p.sourceLbl)
+	p.root = container.NewBorder(header, nil, nil, nil,
+		container.NewStack(p.list, p.plainScroll, container.NewCenter(p.statusLbl)))
+	p.setStatus("")
+	return p
+}
+
+func (p *lyricsPanel) updateLine(id widget.ListItemID, obj fyne.CanvasObject) {
+	label := obj.(*widget.Label)
+	text := p.lines[id].Text
+	if text == "" {
+		text = "♪"
+	}
+	label.Text = text
+	label.TextStyle.Bold = id == p.current
+	label.Importance = widget.MediumImportance
+	if id == p.current {
+		label.Importance = widget.HighImportance
+	}
+	label.Refresh()
+}
+
+// load shows the lyrics of song once the service has them, dropping the
+// lookup for the song shown before.
+func (p *lyricsPanel) load(song *types.Song) {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+	p.slug = song.Slug
+	if p.svc == nil {
+		p.setStatus("No lyrics")
+		return
+	}
+	p.setStatus("Loading lyrics…")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	go func() {
+		lyrics, err := p.svc.Lyrics(ctx, song)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("[SONG_DETAIL] Lyrics of %s unavailable: %v", song.Slug, err)
+		}
+		fyne.Do(func() {
+			if p.slug == song.Slug {
+				p.show(lyrics, err)
+			}
+		})
+	}()
+}
+
+func (p *lyricsPanel) show(lyrics *types.Lyrics, err error) {
+	switch {
+	case err != nil:
+		p.setStatus("Lyrics unavailable")
+		return
+	case !lyrics.Found():
+		p.setStatus("No lyrics found")
+		return
+	}
+
+	switch lyrics.Source {
+	case types.LyricsSourceLRCLIB:
+		p.sourceLbl.SetText("from LRCLIB")
+	default:
+		p.sourceLbl.SetText("")
+	}
+
+	if lines := services.ParseLRC(lyrics.Synced); len(lines) > 0 {
+		p.lines = lines
+		p.current = -1
+		p.statusLbl.Hide()
+		p.plainScroll.Hide()
+		p.list.Show()
+		p.list.Refresh()
+		p.list.ScrollToTop()
+		return
+	}
+
+	p.lines = nil
+	p.plainLbl.SetText(lyrics.Plain)
+	p.statusLbl.Hide()
+	p.list.Hide()
+	p.plainScroll.Show()
+	p.plainScroll.ScrollToTop()
+}
+
+// setStatus shows text in place of lyrics.
+func (p *lyricsPanel) setStatus(text string) {
+	p.lines = nil
+	p.current = -1
+	p.sourceLbl.SetText("")
+	p.statusLbl.SetText(text)
+	p.statusLbl.Show()
+	p.list.Hide()
+	p.list.Refresh()
+	p.plainScroll.Hide()
+}
+
+// follow marks the line sung at pos and scrolls it to the middle when the
+// mark moves, leaving the list alone in between so it can be scrolled.
+func (p *lyricsPanel) follow(pos time.Duration) {
+	if len(p.lines) == 0 {
+		return
+	}
+	id := services.CurrentLyricLine(p.lines, pos)
+	if id == p.current {
+		return
+	}
+	p.mark(id)
+	if id < 0 {
+		return
+	}
+	rowHeight := widget.NewLabel("").MinSize().Height + theme.Padding()
+	offset := float32(id)*rowHeight - (p.list.Size().Height-rowHeight)/2
+	p.list.ScrollToOffset(max(0, offset))
+}
+
+// mark moves the mark to line id; -1 clears it.
+func (p *lyricsPanel) mark(id int) {
+	if id == p.current {
+		return
+	}
+	previous := p.current
+	p.current = id
+	if previous >= 0 {
+		p.list.RefreshItem(previous)
+	}
+	if id >= 0 {
+		p.list.RefreshItem(id)
+	}
+}
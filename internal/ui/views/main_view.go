@@ -2,7 +2,9 @@ package views
 
 import (
 	"context"
+	"errors"
 	"log"
+	"unicode"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -11,14 +13,16 @@ import (
 	"github.com/Alexander-D-Karpov/amp/internal/download"
 	"github.com/Alexander-D-Karpov/amp/internal/handlers"
 	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
 type MainView struct {
 	handlers *handlers.UIHandlers
 
-	container *fyne.Container
-	views     map[string]fyne.CanvasObject
+	cfg        *config.Config
+	transition *viewTransition
+	views      map[string]fyne.CanvasObject
 
 	SongsView     *SongsView
 	AlbumsView    *AlbumsView
@@ -26,13 +30,20 @@ type MainView struct {
 	PlaylistsView *PlaylistsView
 	DownloadsView *DownloadsView
 	StatsView     *StatsView
+	HistoryView   *HistoryView
+	ReportView    *LibraryReportView
 	SettingsView  *SettingsView
+	TrashView     *TrashView
+	LocalFiles    *LocalFilesView
 
 	SongDetailView   *SongDetailView
 	AlbumDetailView  *AlbumDetailView
 	AuthorDetailView *AuthorDetailView
 
 	parentWindow fyne.Window
+	undoBar      *components.UndoBar
+	errorBanner  *components.ErrorBanner
+	root         *fyne.Container
 
 	current string
 	history []string
@@ -48,7 +59,11 @@ const (
 	viewPlaylists    = "playlists"
 	viewDownloads    = "downloads"
 	viewStats        = "stats"
+	viewHistory      = "history"
+	viewReport       = "report"
 	viewSettings     = "settings"
+	viewTrash        = "trash"
+	viewLocalFiles   = "local_files"
 	viewSongDetail   = "song_detail"
 	viewAlbumDetail  = "album_detail"
 	viewAuthorDetail = "author_detail"
@@ -59,6 +74,7 @@ func NewMainView(musicService *services.MusicService, imageService *services.Ima
 
 	mv := &MainView{
 		handlers:     handlers,
+		cfg:          cfg,
 		views:        make(map[string]fyne.CanvasObject),
 		musicService: musicService,
 		imageService: imageService,
@@ -68,8 +84,10 @@ func NewMainView(musicService *services.MusicService, imageService *services.Ima
 
 	mv.setupViews(musicService, imageService, downloadManager, cfg)
 
-	mv.container = container.NewBorder(nil, nil, nil, nil, mv.SongsView.Container())
+	mv.transition = newViewTransition(mv.SongsView.Container())
 	mv.current = viewSongs
+	mv.errorBanner = components.NewErrorBanner()
+	mv.root = container.NewBorder(mv.errorBanner.Container(), nil, nil, nil, mv.transition.container)
 
 	mv.SongsView.SetOpenAlbumBySlug(mv.OpenAlbumBySlug)
 	mv.SongsView.SetOpenAuthorBySlug(mv.OpenAuthorBySlug)
@@ -78,6 +96,20 @@ func NewMainView(musicService *services.MusicService, imageService *services.Ima
 	return mv
 }
 
+// saveBrowseLayout remembers the layout the user picked for view.
+func saveBrowseLayout(cfg *config.Config, view string, columns bool) {
+	if cfg.UI.BrowseLayouts == nil {
+		cfg.UI.BrowseLayouts = make(map[string]string)
+	}
+	cfg.UI.BrowseLayouts[view] = browseLayoutGrid
+	if columns {
+		cfg.UI.BrowseLayouts[view] = browseLayoutColumns
+	}
+	if err := cfg.Save(); err != nil {
+		log.Printf("[MAIN_VIEW] Failed to save %s layout: %v", view, err)
+	}
+}
+
 func (mv *MainView) SetParentWindow(window fyne.Window) {
 	mv.parentWindow = window
 	if mv.SongsView != nil {
@@ -89,19 +121,53 @@ func (mv *MainView) SetParentWindow(window fyne.Window) {
 	if mv.ArtistsView != nil {
 		mv.ArtistsView.SetParentWindow(window)
 	}
+	if mv.PlaylistsView != nil {
+		mv.PlaylistsView.SetParentWindow(window)
+	}
+	if mv.SongDetailView != nil {
+		mv.SongDetailView.SetParentWindow(window)
+	}
+	if mv.AlbumDetailView != nil {
+		mv.AlbumDetailView.SetParentWindow(window)
+	}
+	if mv.AuthorDetailView != nil {
+		mv.AuthorDetailView.SetParentWindow(window)
+	}
+	if mv.StatsView != nil {
+		mv.StatsView.SetParentWindow(window)
+	}
+	if mv.HistoryView != nil {
+		mv.HistoryView.SetParentWindow(window)
+	}
+	if mv.ReportView != nil {
+		mv.ReportView.SetParentWindow(window)
+	}
 	if mv.SettingsView != nil {
 		mv.SettingsView.SetParentWindow(window)
 	}
+	if mv.TrashView != nil {
+		mv.TrashView.SetParentWindow(window)
+	}
+	if mv.LocalFiles != nil {
+		mv.LocalFiles.SetParentWindow(window)
+	}
+	if mv.DownloadsView != nil {
+		mv.DownloadsView.SetParentWindow(window)
+	}
 }
 
 func (mv *MainView) setupViews(musicService *services.MusicService, imageService *services.ImageService, downloadManager *download.Manager, cfg *config.Config) {
 	mv.SongsView = NewSongsView(musicService, imageService, mv.handlers)
 	mv.AlbumsView = NewAlbumsView(musicService, imageService, mv.handlers, cfg.Debug)
 	mv.ArtistsView = NewArtistsView(musicService, imageService, mv.handlers, cfg.Debug)
-	mv.PlaylistsView = NewPlaylistsView(musicService, cfg.Debug)
+	mv.PlaylistsView = NewPlaylistsView(musicService, imageService, cfg.Debug)
 	mv.DownloadsView = NewDownloadsView(downloadManager)
-	mv.StatsView = NewStatsView(musicService)
+	mv.StatsView = NewStatsView(musicService, mv.handlers)
+	mv.HistoryView = NewHistoryView(musicService, mv.handlers)
+	mv.ReportView = NewLibraryReportView(musicService, mv.handlers)
 	mv.SettingsView = NewSettingsView(cfg)
+	mv.TrashView = NewTrashView(musicService)
+	mv.LocalFiles = NewLocalFilesView(mv.handlers)
 
 	mv.views[viewSongs] = mv.SongsView.Container()
 	mv.views[viewAlbums] = mv.AlbumsView.Container()
@@ -109,12 +175,50 @@ func (mv *MainView) setupViews(musicService *services.MusicService, imageService
 	mv.views[viewPlaylists] = mv.PlaylistsView.Container()
 	mv.views[viewDownloads] = mv.DownloadsView.Container()
 	mv.views[viewStats] = mv.StatsView.Container()
+	mv.views[viewHistory] = mv.HistoryView.Container()
+	mv.views[viewReport] = mv.ReportView.Container()
 	mv.views[viewSettings] = mv.SettingsView.Container()
+	mv.views[viewTrash] = mv.TrashView.Container()
+	mv.views[viewLocalFiles] = mv.LocalFiles.Container()
+
+	mv.AlbumsView.SetColumnsMode(cfg.UI.BrowseLayouts[viewAlbums] == browseLayoutColumns)
+	mv.AlbumsView.OnLayoutChanged(func(columns bool) { saveBrowseLayout(cfg, viewAlbums, columns) })
+	mv.ArtistsView.SetColumnsMode(cfg.UI.BrowseLayouts[viewArtists] == browseLayoutColumns)
+	mv.ArtistsView.OnLayoutChanged(func(columns bool) { saveBrowseLayout(cfg, viewArtists, columns) })
+
+	mv.undoBar = components.NewUndoBar()
+	mv.SongsView.SetUndoBar(mv.undoBar)
+	mv.PlaylistsView.SetUndoBar(mv.undoBar)
+	mv.LocalFiles.SetUndoBar(mv.undoBar)
+	mv.handlers.SetOnDownloadRecorded(func(*types.Song) {
+		fyne.Do(mv.LocalFiles.Refresh)
+	})
+	mv.TrashView.OnRestored(func(kind types.TrashKind) {
+		switch kind {
+		case types.TrashSong:
+			mv.SongsView.Refresh()
+		case types.TrashPlaylist:
+			mv.PlaylistsView.Refresh()
+		}
+	})
 
 	mv.SongDetailView = NewSongDetailView(imageService)
 	mv.AlbumDetailView = NewAlbumDetailView(imageService)
 	mv.AuthorDetailView = NewAuthorDetailView(imageService)
 
+	imageService.OnArtworkChanged(func(kind types.ArtworkKind, _ string) {
+		fyne.Do(func() {
+			switch kind {
+			case types.ArtworkSong:
+				mv.SongsView.updateGridView()
+			case types.ArtworkAlbum:
+				mv.AlbumsView.updateGridView()
+			case types.ArtworkAuthor:
+				mv.ArtistsView.updateGridView()
+			}
+		})
+	})
+
 	mv.SongDetailView.SetOnBack(func() {
 		mv.ShowView("songs")
 	})
@@ -134,7 +238,7 @@ func (mv *MainView) setupViews(musicService *services.MusicService, imageService
 		func() { mv.ShowView("albums") },
 		func(s *types.Song) {
 			if mv.handlers != nil {
-				mv.handlers.HandleSongSelection(s, []*types.Song{s})
+				mv.handlers.HandleSongSelectionFrom(s, []*types.Song{s}, mv.AlbumDetailView.PlaySource())
 			}
 		},
 		func(slug string) { mv.OpenAlbumBySlug(slug) },
@@ -150,13 +254,16 @@ func (mv *MainView) setupViews(musicService *services.MusicService, imageService
 		func() { mv.ShowView("artists") },
 		func(s *types.Song) {
 			if mv.handlers != nil {
-				mv.handlers.HandleSongSelection(s, []*types.Song{s})
+				mv.handlers.HandleSongSelectionFrom(s, []*types.Song{s}, mv.AuthorDetailView.PlaySource())
 			}
 		},
 		func(slug string) { mv.OpenAlbumBySlug(slug) },
 		func(slug string) { mv.OpenAuthorBySlug(slug) },
 	)
 
+	mv.AlbumDetailView.SetOnRefresh(mv.refreshAlbum)
+	mv.AuthorDetailView.SetOnRefresh(mv.refreshAuthor)
+
 	mv.setupContextMenuCallbacks(downloadManager)
 
 	mv.PlaylistsView.OnPlaylistSelected(func(playlist *types.Playlist) {
@@ -256,11 +363,42 @@ func (mv *MainView) ShowView(name string) {
 	if mv.current != "" && mv.current != name {
 		mv.history = append(mv.history, mv.current)
 	}
+	mv.errorBanner.Hide()
+
+	switch name {
+	case viewHistory:
+		mv.HistoryView.Refresh()
+	case viewReport:
+		mv.ReportView.Refresh()
+	case viewTrash:
+		mv.TrashView.Refresh()
+	case viewLocalFiles:
+		mv.LocalFiles.Refresh()
+	}
 
-	mv.container.RemoveAll()
-	mv.container.Add(targetView)
+	kind := transitionFade
+	if isDetailView(name) {
+		kind = transitionForward
+	}
+	mv.switchTo(name, targetView, kind)
+}
+
+// switchTo shows view, animated unless the user asked for reduced motion.
+func (mv *MainView) switchTo(name string, view fyne.CanvasObject, kind transitionKind) {
+	if mv.cfg.UI.ReduceMotion {
+		kind = transitionNone
+	}
 	mv.current = name
-	mv.container.Refresh()
+	mv.transition.Show(view, kind)
+}
+
+func isDetailView(name string) bool {
+	return name == viewSongDetail || name == viewAlbumDetail || name == viewAuthorDetail
+}
+
+// UndoBar returns the strip views use to offer undoing a deletion.
+func (mv *MainView) UndoBar() *components.UndoBar {
+	return mv.undoBar
 }
 
 func (mv *MainView) GoBack() {
@@ -278,10 +416,11 @@ func (mv *MainView) GoBack() {
 		return
 	}
 
-	mv.container.RemoveAll()
-	mv.container.Add(targetView)
-	mv.current = last
-	mv.container.Refresh()
+	kind := transitionFade
+	if isDetailView(mv.current) {
+		kind = transitionBack
+	}
+	mv.switchTo(last, targetView, kind)
 }
 
 func (mv *MainView) OpenSongDetail(song *types.Song) {
@@ -313,6 +452,7 @@ func (mv *MainView) OpenSongBySlug(slug string) {
 		ctx := context.Background()
 		song, err := mv.handlers.Music().GetSong(ctx, slug)
 		if err != nil || song == nil {
+			mv.showLoadError("Couldn't open song", err, func() { mv.OpenSongBySlug(slug) })
 			return
 		}
 		fyne.Do(func() {
@@ -327,6 +467,7 @@ func (mv *MainView) OpenAlbumBySlug(slug string) {
 		ctx := context.Background()
 		album, err := mv.handlers.Music().GetAlbum(ctx, slug)
 		if err != nil || album == nil {
+			mv.showLoadError("Couldn't open album", err, func() { mv.OpenAlbumBySlug(slug) })
 			return
 		}
 		fyne.Do(func() {
@@ -341,6 +482,7 @@ func (mv *MainView) OpenAuthorBySlug(slug string) {
 		ctx := context.Background()
 		author, err := mv.handlers.Music().GetAuthor(ctx, slug)
 		if err != nil || author == nil {
+			mv.showLoadError("Couldn't open artist", err, func() { mv.OpenAuthorBySlug(slug) })
 			return
 		}
 		fyne.Do(func() {
@@ -350,6 +492,45 @@ func (mv *MainView) OpenAuthorBySlug(slug string) {
 	}()
 }
 
+// refreshAlbum fetches the shown album from the server again, skipping the
+// stored copy.
+func (mv *MainView) refreshAlbum(slug string) {
+	go func() {
+		ctx := services.ForceRefresh(context.Background())
+		album, err := mv.handlers.Music().GetAlbum(ctx, slug)
+		if err != nil || album == nil {
+			mv.showLoadError("Couldn't refresh album", err, func() { mv.refreshAlbum(slug) })
+			return
+		}
+		fyne.Do(func() { mv.AlbumDetailView.ShowAlbum(album) })
+	}()
+}
+
+// refreshAuthor fetches the shown author from the server again, skipping the
+// stored copy.
+func (mv *MainView) refreshAuthor(slug string) {
+	go func() {
+		ctx := services.ForceRefresh(context.Background())
+		author, err := mv.handlers.Music().GetAuthor(ctx, slug)
+		if err != nil || author == nil {
+			mv.showLoadError("Couldn't refresh artist", err, func() { mv.refreshAuthor(slug) })
+			return
+		}
+		fyne.Do(func() { mv.AuthorDetailView.ShowAuthor(author) })
+	}()
+}
+
+// showLoadError reports a detail page that failed to load above the
+// current view, with retry to load it again. A nil err means nothing was
+// found.
+func (mv *MainView) showLoadError(message string, err error, retry func()) {
+	if err == nil {
+		err = errors.New("not found")
+	}
+	log.Printf("[MAIN_VIEW] %s: %v", message, err)
+	fyne.Do(func() { mv.errorBanner.Show(message, err, retry) })
+}
+
 func (mv *MainView) OnSongSelected(callback func(*types.Song, []*types.Song)) {
 	mv.handlers.SetOnSongSelected(callback)
 }
@@ -362,10 +543,26 @@ func (mv *MainView) OnArtistSelected(callback func(*types.Author)) {
 	mv.handlers.SetOnArtistSelected(callback)
 }
 
+func (mv *MainView) OnQueueSongs(callback func(songs []*types.Song, next bool)) {
+	mv.handlers.SetOnQueueSongs(callback)
+}
+
 func (mv *MainView) OnPlaylistSelected(callback func(*types.Playlist)) {
 	mv.handlers.SetOnPlaylistSelected(callback)
 }
 
+// OnPreview sets how songs are previewed while the pointer rests on their
+// cards, and how a preview is stopped.
+func (mv *MainView) OnPreview(preview func(*types.Song), stop func()) {
+	mv.SongsView.SetPreviewHandlers(preview, stop)
+}
+
+// OnCue sets how songs are pre-listened to on a second device, or hides
+// pre-listening for nil.
+func (mv *MainView) OnCue(cue func(*types.Song)) {
+	mv.SongsView.SetCueHandler(cue)
+}
+
 func (mv *MainView) RefreshData() {
 	mv.SongsView.Refresh()
 	mv.AlbumsView.Refresh()
@@ -393,6 +590,24 @@ func (mv *MainView) SearchInCurrentView(query string) {
 	}
 }
 
+// TypeToJump passes a letter typed outside any text field to the current
+// view, which jumps to the first item starting with the letters typed.
+func (mv *MainView) TypeToJump(r rune) {
+	if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+		return
+	}
+	switch mv.current {
+	case viewSongs:
+		mv.SongsView.TypeToJump(r)
+	case viewAlbums:
+		mv.AlbumsView.TypeToJump(r)
+	case viewArtists:
+		mv.ArtistsView.TypeToJump(r)
+	case viewPlaylists:
+		mv.PlaylistsView.TypeToJump(r)
+	}
+}
+
 func (mv *MainView) Container() *fyne.Container {
-	return mv.container
+	return mv.root
 }
@@ -0,0 +1,91 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	// loadMoreDistance is how close to the bottom a list is scrolled before
+	// its next page loads.
+	loadMoreDistance = 100
+	// loadAllRetry is how long loading every page waits for a page load
+	// that was already under way.
+	loadAllRetry = 100 * time.Millisecond
+)
+
+// nearBottom reports whether scroll, scrolled to pos, is close enough to
+// the end of its content to load the next page.
+func nearBottom(scroll *container.Scroll, pos fyne.Position) bool {
+	if scroll == nil || scroll.Content == nil {
+		return false
+	}
+	return pos.Y >= scroll.Content.MinSize().Height-scroll.Size().Height-loadMoreDistance
+}
+
+// loadRemaining calls next until no pages remain. next reports busy while
+// another page is loading, which is waited out.
+func loadRemaining(next func() (more, busy bool)) {
+	for {
+		more, busy := next()
+		if busy {
+			time.Sleep(loadAllRetry)
+			continue
+		}
+		if !more {
+			return
+		}
+	}
+}
+
+// pageFooter sits below a list that loads page by page. It says when the
+// whole library is shown, and until then offers to load the rest at once.
+type pageFooter struct {
+	root       *fyne.Container
+	label      *widget.Label
+	loadAllBtn *widget.Button
+	noun       string
+}
+
+func newPageFooter(noun string, onLoadAll func()) *pageFooter {
+	f := &pageFooter{noun: noun}
+	f.label = widget.NewLabel("")
+	f.label.Importance = widget.LowImportance
+	f.loadAllBtn = widget.NewButtonWithIcon("Load All", theme.MoveDownIcon(), onLoadAll)
+	f.loadAllBtn.Importance = widget.LowImportance
+	f.root = container.NewHBox(layout.NewSpacer(), f.label, f.loadAllBtn, layout.NewSpacer())
+	f.root.Hide()
+	return f
+}
+
+// update shows how much of the list is loaded: shown items, whether more
+// pages remain, and whether they are all being loaded. Search results come
+// whole, so they end with the results rather than the library.
+func (f *pageFooter) update(shown int, hasMore, loadingAll, searching bool) {
+	switch {
+	case shown == 0:
+		f.root.Hide()
+		return
+	case loadingAll:
+		f.label.SetText(fmt.Sprintf("Loading all %s…", f.noun))
+		f.loadAllBtn.Disable()
+		f.loadAllBtn.Show()
+	case hasMore:
+		f.label.SetText(fmt.Sprintf("More %s load as you scroll", f.noun))
+		f.loadAllBtn.Enable()
+		f.loadAllBtn.Show()
+	case searching:
+		f.label.SetText("End of results")
+		f.loadAllBtn.Hide()
+	default:
+		f.label.SetText("End of library")
+		f.loadAllBtn.Hide()
+	}
+	f.root.Show()
+}
@@ -0,0 +1,225 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// playlistSongEditor is the song list of the Edit Songs dialog. Songs are
+// moved by dragging their handle or with the arrow buttons and removed with
+// the delete button; nothing is saved until the dialog is confirmed.
+type playlistSongEditor struct {
+	songs     []*types.Song
+	list      *widget.List
+	rowHeight float32
+
+	dragFrom   int
+	dragOffset float32
+}
+
+func newPlaylistSongEditor(songs []*types.Song) *playlistSongEditor {
+	e := &playlistSongEditor{dragFrom: -1}
+	for _, song := range songs {
+		if song != nil {
+			e.songs = append(e.songs, song)
+		}
+	}
+
+	e.list = widget.NewList(
+		func() int { return len(e.songs) },
+		e.newRow,
+		e.updateRow,
+	)
+	return e
+}
+
+func (e *playlistSongEditor) newRow() fyne.CanvasObject {
+	name := widget.NewLabel("")
+	name.Truncation = fyne.TextTruncateEllipsis
+	up := widget.NewButtonWithIcon("", theme.MoveUpIcon(), nil)
+	up.Importance = widget.LowImportance
+	down := widget.NewButtonWithIcon("", theme.MoveDownIcon(), nil)
+	down.Importance = widget.LowImportance
+	remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), nil)
+	remove.Importance = widget.LowImportance
+
+	row := container.NewBorder(nil, nil, newDragHandle(), container.NewHBox(up, down, remove), name)
+	if e.rowHeight == 0 {
+		e.rowHeight = row.MinSize().Height + theme.Padding()
+	}
+	return row
+}
+
+func (e *playlistSongEditor) updateRow(id widget.ListItemID, obj fyne.CanvasObject) {
+	if id >= len(e.songs) {
+		return
+	}
+	song := e.songs[id]
+	row := obj.(*fyne.Container).Objects
+	name := row[0].(*widget.Label)
+	handle := row[1].(*dragHandle)
+	buttons := row[2].(*fyne.Container).Objects
+
+	name.SetText(fmt.Sprintf("%d. %s — %s", id+1, song.Name, getArtistNames(song.Authors)))
+
+	handle.onDragged = func(dy float32) {
+		if e.dragFrom != id {
+			e.dragFrom, e.dragOffset = id, 0
+		}
+		e.dragOffset += dy
+	}
+	handle.onDragEnd = func() {
+		if e.dragFrom != id {
+			return
+		}
+		steps := int(math.Round(float64(e.dragOffset / e.rowHeight)))
+		e.dragFrom, e.dragOffset = -1, 0
+		e.move(id, id+steps)
+	}
+
+	up, down, remove := buttons[0].(*widget.Button), buttons[1].(*widget.Button), buttons[2].(*widget.Button)
+	up.OnTapped = func() { e.move(id, id-1) }
+	down.OnTapped = func() { e.move(id, id+1) }
+	remove.OnTapped = func() { e.remove(id) }
+	if id == 0 {
+		up.Disable()
+	} else {
+		up.Enable()
+	}
+	if id == len(e.songs)-1 {
+		down.Disable()
+	} else {
+		down.Enable()
+	}
+}
+
+// move puts the song at from at position to, shifting the ones between.
+func (e *playlistSongEditor) move(from, to int) {
+	to = max(0, min(to, len(e.songs)-1))
+	if from == to || from < 0 || from >= len(e.songs) {
+		return
+	}
+	song := e.songs[from]
+	if from < to {
+		copy(e.songs[from:to], e.songs[from+1:to+1])
+	} else {
+		copy(e.songs[to+1:from+1], e.songs[to:from])
+	}
+	e.songs[to] = song
+	e.list.Refresh()
+}
+
+func (e *playlistSongEditor) remove(id int) {
+	if id < 0 || id >= len(e.songs) {
+		return
+	}
+	e.songs = append(e.songs[:id], e.songs[id+1:]...)
+	e.list.Refresh()
+}
+
+func (e *playlistSongEditor) slugs() []string {
+	slugs := make([]string, len(e.songs))
+	for i, song := range e.songs {
+		slugs[i] = song.Slug
+	}
+	return slugs
+}
+
+// dragHandle is the grip a song row is dragged by.
+type dragHandle struct {
+	widget.Icon
+	onDragged func(dy float32)
+	onDragEnd func()
+}
+
+func newDragHandle() *dragHandle {
+	h := &dragHandle{}
+	h.Resource = theme.MenuIcon()
+	h.ExtendBaseWidget(h)
+	return h
+}
+
+func (h *dragHandle) Dragged(event *fyne.DragEvent) {
+	if h.onDragged != nil {
+		h.onDragged(event.Dragged.DY)
+	}
+}
+
+func (h *dragHandle) DragEnd() {
+	if h.onDragEnd != nil {
+		h.onDragEnd()
+	}
+}
+
+// showSongEditor lets the user reorder and remove the songs of a playlist.
+func (pv *PlaylistsView) showSongEditor(playlist *types.Playlist) {
+	if pv.parentWindow == nil {
+		return
+	}
+
+	go func() {
+		detailed, err := pv.musicService.GetPlaylist(context.Background(), playlist.Slug)
+		if err == nil && detailed == nil {
+			err = fmt.Errorf("playlist %s not found", playlist.Slug)
+		}
+		if err != nil {
+			log.Printf("[PLAYLISTS_VIEW] Failed to load songs of %s: %v", playlist.Slug, err)
+			fyne.Do(func() {
+				dialog.ShowError(fmt.Errorf("could not load playlist: %w", err), pv.parentWindow)
+			})
+			return
+		}
+
+		fyne.Do(func() {
+			editor := newPlaylistSongEditor(detailed.Songs)
+			original := editor.slugs()
+			hint := widget.NewLabel("Drag songs by their handle to reorder them.")
+			hint.Importance = widget.LowImportance
+			content := container.NewBorder(hint, nil, nil, nil, editor.list)
+
+			d := dialog.NewCustomConfirm(playlist.Name, "Save", "Cancel", content, func(save bool) {
+				edited := editor.slugs()
+				if !save || slices.Equal(original, edited) {
+					return
+				}
+				commit := func() {
+					pv.runCleanup(playlist, func(ctx context.Context) (string, error) {
+						err := pv.musicService.EditPlaylistSongs(ctx, playlist.Slug, edited)
+						return fmt.Sprintf("Saved \"%s\"", playlist.Name), err
+					})
+				}
+				if pv.undoBar == nil {
+					commit()
+					return
+				}
+				pv.undoBar.Defer(editSummary(playlist.Name, len(original), len(edited)), commit, func() {})
+			}, pv.parentWindow)
+			d.Resize(fyne.NewSize(520, 560))
+			d.Show()
+		})
+	}()
+}
+
+// editSummary renders what saving the song editor is about to do, such as
+// "Removing 2 songs from "Road Trip"".
+func editSummary(name string, before, after int) string {
+	switch removed := before - after; {
+	case removed == 1:
+		return fmt.Sprintf("Removing 1 song from \"%s\"", name)
+	case removed > 1:
+		return fmt.Sprintf("Removing %d songs from \"%s\"", removed, name)
+	default:
+		return fmt.Sprintf("Reordering \"%s\"", name)
+	}
+}
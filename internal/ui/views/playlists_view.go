@@ -3,42 +3,57 @@ package views
 import (
 	"context"
 	"fmt"
+	"log"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/Alexander-D-Karpov/amp/internal/api"
 	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
 type PlaylistsView struct {
 	musicService *services.MusicService
+	imageService *services.ImageService
 	debug        bool
 
 	container     *fyne.Container
+	parentWindow  fyne.Window
+	undoBar       *components.UndoBar
 	playlistsGrid *fyne.Container
+	scroll        *container.Scroll
 	searchEntry   *widget.Entry
 	refreshBtn    *widget.Button
 	sortSelect    *widget.Select
+	errorBanner   *components.ErrorBanner
 
 	mu                sync.RWMutex
 	playlists         []*types.Playlist
 	filteredPlaylists []*types.Playlist
 	searchTimer       *time.Timer
 	loading           bool
+	editingSlug       string
+	editEntry         *widget.Entry
+	typeAhead         typeAhead
 
 	onPlaylistSelected func(*types.Playlist)
 }
 
-func NewPlaylistsView(musicService *services.MusicService, debug bool) *PlaylistsView {
+func NewPlaylistsView(musicService *services.MusicService, imageService *services.ImageService, debug bool) *PlaylistsView {
 	pv := &PlaylistsView{
 		musicService:      musicService,
+		imageService:      imageService,
 		debug:             debug,
 		playlists:         make([]*types.Playlist, 0),
 		filteredPlaylists: make([]*types.Playlist, 0),
@@ -62,14 +77,15 @@ func (pv *PlaylistsView) setupWidgets() {
 	}, pv.onSortChanged)
 
 	pv.playlistsGrid = container.NewGridWithColumns(3)
+	pv.errorBanner = components.NewErrorBanner()
 }
 
 func (pv *PlaylistsView) setupLayout() {
 	searchBar := container.NewBorder(nil, nil, nil, pv.refreshBtn, pv.searchEntry)
 	controls := container.NewHBox(widget.NewLabel("Sort:"), pv.sortSelect)
-	header := container.NewVBox(searchBar, controls)
-	content := container.NewScroll(pv.playlistsGrid)
-	pv.container = container.NewBorder(header, nil, nil, nil, content)
+	header := container.NewVBox(pv.errorBanner.Container(), searchBar, controls)
+	pv.scroll = container.NewScroll(pv.playlistsGrid)
+	pv.container = container.NewBorder(header, nil, nil, nil, pv.scroll)
 
 	pv.sortSelect.SetSelected("Name A-Z")
 }
@@ -109,8 +125,11 @@ func (pv *PlaylistsView) loadPlaylists() {
 		ctx := context.Background()
 		playlists, err := pv.musicService.GetPlaylists(ctx)
 		if err != nil {
+			log.Printf("[PLAYLISTS_VIEW] Failed to load playlists: %v", err)
+			fyne.Do(func() { pv.errorBanner.Show("Couldn't load playlists", err, pv.loadPlaylists) })
 			return
 		}
+		fyne.Do(pv.errorBanner.Hide)
 
 		pv.mu.Lock()
 		pv.playlists = playlists
@@ -190,28 +209,449 @@ func (pv *PlaylistsView) refreshView() {
 	pv.playlistsGrid.Refresh()
 }
 
+// TypeToJump scrolls to the first playlist whose name starts with the
+// letters typed so far.
+func (pv *PlaylistsView) TypeToJump(r rune) {
+	pv.mu.RLock()
+	names := make([]string, len(pv.filteredPlaylists))
+	for i, playlist := range pv.filteredPlaylists {
+		names[i] = playlist.Name
+	}
+	pv.mu.RUnlock()
+
+	index := pv.typeAhead.jump(r, names)
+	if index < 0 || index >= len(pv.playlistsGrid.Objects) {
+		return
+	}
+	scrollToItem(pv.scroll, pv.playlistsGrid.Objects[index].Position())
+}
+
 func (pv *PlaylistsView) createPlaylistCard(playlist *types.Playlist) fyne.CanvasObject {
-	cover := widget.NewIcon(theme.ListIcon())
-	cover.Resize(fyne.NewSize(120, 120))
+	cover := canvas.NewImageFromResource(theme.ListIcon())
+	cover.FillMode = canvas.ImageFillContain
+	cover.SetMinSize(fyne.NewSize(120, 120))
+	pv.loadCover(playlist, cover)
+
+	songsCount := len(playlist.Songs)
+	statsText := fmt.Sprintf("%d songs", songsCount)
+	if playlist.Private {
+		statsText += " • Private"
+	}
+	stats := widget.NewLabel(statsText)
+	stats.Alignment = fyne.TextAlignCenter
+
+	if pv.editingSlug == playlist.Slug {
+		return container.NewVBox(cover, pv.createPlaylistEditor(playlist), stats)
+	}
 
 	name := widget.NewLabel(playlist.Name)
 	name.Alignment = fyne.TextAlignCenter
 	name.TextStyle = fyne.TextStyle{Bold: true}
 	name.Wrapping = fyne.TextWrapWord
 
-	songsCount := len(playlist.Songs)
-	stats := widget.NewLabel(fmt.Sprintf("%d songs", songsCount))
-	stats.Alignment = fyne.TextAlignCenter
-
 	content := container.NewVBox(cover, name, stats)
 
-	btn := widget.NewButton("", func() {
+	btn := newPlaylistCardTap(func() {
 		if pv.onPlaylistSelected != nil {
 			pv.onPlaylistSelected(playlist)
 		}
+	}, func(pos fyne.Position) {
+		pv.showPlaylistMenu(playlist, pos)
+	})
+
+	transitionBtn := widget.NewButtonWithIcon("", theme.SettingsIcon(), func() {
+		pv.showTransitionDialog(playlist)
+	})
+	transitionBtn.Importance = widget.LowImportance
+
+	return container.NewStack(content, btn, container.NewBorder(container.NewBorder(nil, nil, nil, transitionBtn), nil, nil, nil))
+}
+
+// loadCover shows the playlist's cover, a collage of its first song covers,
+// once it is ready.
+func (pv *PlaylistsView) loadCover(playlist *types.Playlist, cover *canvas.Image) {
+	if pv.imageService == nil {
+		return
+	}
+	go func() {
+		url := pv.imageService.PlaylistCoverURL(playlist)
+		if url == "" {
+			return
+		}
+		pv.imageService.GetImageWithSize(context.Background(), url, fyne.NewSize(120, 120), func(res fyne.Resource, err error) {
+			if err == nil && res != nil {
+				cover.Resource = res
+				cover.Refresh()
+			}
+		})
+	}()
+}
+
+// playlistCardTap opens the playlist on tap and its menu on secondary tap.
+type playlistCardTap struct {
+	widget.Button
+	onSecondary func(fyne.Position)
+}
+
+func newPlaylistCardTap(onTap func(), onSecondary func(fyne.Position)) *playlistCardTap {
+	b := &playlistCardTap{onSecondary: onSecondary}
+	b.OnTapped = onTap
+	b.ExtendBaseWidget(b)
+	return b
+}
+
+func (b *playlistCardTap) TappedSecondary(event *fyne.PointEvent) {
+	if b.onSecondary != nil {
+		b.onSecondary(event.AbsolutePosition)
+	}
+}
+
+func (pv *PlaylistsView) showPlaylistMenu(playlist *types.Playlist, pos fyne.Position) {
+	if pv.parentWindow == nil {
+		return
+	}
+
+	renameItem := fyne.NewMenuItem("Rename", func() {
+		pv.editingSlug = playlist.Slug
+		pv.refreshView()
+		if pv.editEntry != nil {
+			pv.parentWindow.Canvas().Focus(pv.editEntry)
+		}
+	})
+	renameItem.Icon = theme.DocumentCreateIcon()
+
+	privacyLabel := "Make Private"
+	if playlist.Private {
+		privacyLabel = "Make Public"
+	}
+	privacyItem := fyne.NewMenuItem(privacyLabel, func() {
+		pv.updatePlaylist(playlist, playlist.Name, !playlist.Private)
+	})
+	privacyItem.Icon = theme.VisibilityOffIcon()
+
+	editSongsItem := fyne.NewMenuItem("Edit Songs…", func() {
+		pv.showSongEditor(playlist)
+	})
+	editSongsItem.Icon = theme.ListIcon()
+
+	transitionItem := fyne.NewMenuItem("Transitions…", func() {
+		pv.showTransitionDialog(playlist)
+	})
+	transitionItem.Icon = theme.SettingsIcon()
+
+	exportItem := fyne.NewMenuItem("Export…", func() {
+		pv.showExportDialog(playlist)
+	})
+	exportItem.Icon = theme.MailForwardIcon()
+
+	dedupeItem := fyne.NewMenuItem("Remove Duplicates", func() {
+		pv.runCleanup(playlist, func(ctx context.Context) (string, error) {
+			removed, err := pv.musicService.RemoveDuplicateSongs(ctx, playlist.Slug)
+			return cleanupMessage(removed, "duplicate"), err
+		})
+	})
+	unavailableItem := fyne.NewMenuItem("Remove Unavailable Songs", func() {
+		pv.runCleanup(playlist, func(ctx context.Context) (string, error) {
+			removed, err := pv.musicService.RemoveUnavailableSongs(ctx, playlist.Slug)
+			return cleanupMessage(removed, "unavailable song"), err
+		})
+	})
+	sortItem := fyne.NewMenuItem("Sort Songs", nil)
+	sortItem.Icon = theme.MenuDropDownIcon()
+	sortItem.ChildMenu = fyne.NewMenu("",
+		pv.sortMenuItem(playlist, "By Artist", types.PlaylistSortArtist),
+		pv.sortMenuItem(playlist, "By Title", types.PlaylistSortTitle),
+		pv.sortMenuItem(playlist, "By Date Added", types.PlaylistSortDateAdded),
+	)
+
+	deleteItem := fyne.NewMenuItem("Delete", func() {
+		pv.deletePlaylist(playlist)
+	})
+	deleteItem.Icon = theme.DeleteIcon()
+
+	// Server playlists can only be changed when the server allows it.
+	if !playlist.LocalOnly && !pv.musicService.ServerSupports(api.FeaturePlaylistEditing) {
+		for _, item := range []*fyne.MenuItem{renameItem, privacyItem, editSongsItem, dedupeItem, unavailableItem, sortItem} {
+			item.Disabled = true
+		}
+	}
+
+	menu := fyne.NewMenu("", renameItem, privacyItem, editSongsItem, fyne.NewMenuItemSeparator(), transitionItem, exportItem,
+		fyne.NewMenuItemSeparator(), dedupeItem, unavailableItem, sortItem,
+		fyne.NewMenuItemSeparator(), deleteItem)
+	widget.ShowPopUpMenuAtPosition(menu, pv.parentWindow.Canvas(), pos)
+}
+
+func (pv *PlaylistsView) sortMenuItem(playlist *types.Playlist, label string, key types.PlaylistSortKey) *fyne.MenuItem {
+	return fyne.NewMenuItem(label, func() {
+		pv.runCleanup(playlist, func(ctx context.Context) (string, error) {
+			err := pv.musicService.SortPlaylist(ctx, playlist.Slug, key)
+			return fmt.Sprintf("Sorted \"%s\" %s", playlist.Name, strings.ToLower(label)), err
+		})
+	})
+}
+
+// showExportDialog loads the playlist's songs and offers them as a snippet
+// to share.
+func (pv *PlaylistsView) showExportDialog(playlist *types.Playlist) {
+	go func() {
+		detailed, err := pv.musicService.GetPlaylist(context.Background(), playlist.Slug)
+		if err == nil && detailed == nil {
+			err = fmt.Errorf("playlist %s not found", playlist.Slug)
+		}
+		if err != nil {
+			log.Printf("[PLAYLISTS_VIEW] Failed to load songs of %s: %v", playlist.Slug, err)
+			fyne.Do(func() {
+				dialog.ShowError(fmt.Errorf("could not load playlist: %w", err), pv.parentWindow)
+			})
+			return
+		}
+
+		fyne.Do(func() {
+			components.NewExportDialog(detailed.Name, detailed.Songs).Show(pv.parentWindow)
+		})
+	}()
+}
+
+// runCleanup applies a maintenance action to a playlist, reports the outcome
+// and reloads the grid so song counts stay accurate.
+func (pv *PlaylistsView) runCleanup(playlist *types.Playlist, action func(context.Context) (string, error)) {
+	go func() {
+		message, err := action(context.Background())
+		if err != nil {
+			log.Printf("[PLAYLISTS_VIEW] Cleanup of %s failed: %v", playlist.Slug, err)
+			fyne.Do(func() {
+				if pv.parentWindow != nil {
+					dialog.ShowError(fmt.Errorf("could not update playlist: %w", err), pv.parentWindow)
+				}
+			})
+			return
+		}
+
+		pv.loadPlaylists()
+		fyne.Do(func() {
+			if pv.undoBar != nil {
+				pv.undoBar.Show(message, nil)
+			}
+		})
+	}()
+}
+
+// cleanupMessage renders "Removed 2 duplicates" or "No duplicates found".
+func cleanupMessage(count int, noun string) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("No %ss found", noun)
+	case 1:
+		return fmt.Sprintf("Removed 1 %s", noun)
+	default:
+		return fmt.Sprintf("Removed %d %ss", count, noun)
+	}
+}
+
+// deletePlaylist moves the playlist to the trash and offers to undo it.
+func (pv *PlaylistsView) deletePlaylist(playlist *types.Playlist) {
+	go func() {
+		err := pv.musicService.DeletePlaylist(context.Background(), playlist)
+		if err != nil {
+			log.Printf("[PLAYLISTS_VIEW] Failed to delete %s: %v", playlist.Slug, err)
+			fyne.Do(func() {
+				if pv.parentWindow != nil {
+					dialog.ShowError(fmt.Errorf("could not delete playlist: %w", err), pv.parentWindow)
+				}
+			})
+			return
+		}
+
+		pv.mu.Lock()
+		kept := make([]*types.Playlist, 0, len(pv.playlists))
+		for _, p := range pv.playlists {
+			if p.Slug != playlist.Slug {
+				kept = append(kept, p)
+			}
+		}
+		pv.playlists = kept
+		pv.mu.Unlock()
+		pv.applySortAndFilter()
+
+		fyne.Do(func() {
+			pv.applyFilter(pv.searchEntry.Text)
+			if pv.undoBar != nil {
+				pv.undoBar.Show(fmt.Sprintf("Moved \"%s\" to the trash", playlist.Name), func() {
+					pv.restorePlaylist(playlist)
+				})
+			}
+		})
+	}()
+}
+
+func (pv *PlaylistsView) restorePlaylist(playlist *types.Playlist) {
+	go func() {
+		if err := pv.musicService.RestoreFromTrash(context.Background(), types.TrashPlaylist, playlist.Slug); err != nil {
+			log.Printf("[PLAYLISTS_VIEW] Failed to restore %s: %v", playlist.Slug, err)
+			return
+		}
+		pv.loadPlaylists()
+	}()
+}
+
+// createPlaylistEditor replaces the card title with a name entry and privacy
+// toggle while the playlist is being edited.
+func (pv *PlaylistsView) createPlaylistEditor(playlist *types.Playlist) fyne.CanvasObject {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetText(playlist.Name)
+	privateCheck := widget.NewCheck("Private", nil)
+	privateCheck.SetChecked(playlist.Private)
+
+	save := func() {
+		name := strings.TrimSpace(nameEntry.Text)
+		if name == "" {
+			return
+		}
+		pv.editingSlug = ""
+		pv.updatePlaylist(playlist, name, privateCheck.Checked)
+	}
+	nameEntry.OnSubmitted = func(string) { save() }
+
+	saveBtn := widget.NewButtonWithIcon("", theme.ConfirmIcon(), save)
+	saveBtn.Importance = widget.HighImportance
+	cancelBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+		pv.editingSlug = ""
+		pv.refreshView()
 	})
 
-	return container.NewStack(content, btn)
+	pv.editEntry = nameEntry
+
+	return container.NewVBox(nameEntry, container.NewHBox(privateCheck, layout.NewSpacer(), cancelBtn, saveBtn))
+}
+
+// updatePlaylist saves a new name or privacy setting and updates the card.
+func (pv *PlaylistsView) updatePlaylist(playlist *types.Playlist, name string, private bool) {
+	if name == playlist.Name && private == playlist.Private {
+		pv.refreshView()
+		return
+	}
+
+	go func() {
+		err := pv.musicService.UpdatePlaylistDetails(context.Background(), playlist, name, private)
+		fyne.Do(func() {
+			if err != nil {
+				log.Printf("[PLAYLISTS_VIEW] Failed to update playlist %s: %v", playlist.Slug, err)
+				if pv.parentWindow != nil {
+					dialog.ShowError(fmt.Errorf("could not update playlist: %w", err), pv.parentWindow)
+				}
+				pv.refreshView()
+				return
+			}
+
+			pv.mu.Lock()
+			playlist.Name = name
+			playlist.Private = private
+			pv.mu.Unlock()
+
+			pv.applySortAndFilter()
+			pv.applyFilter(pv.searchEntry.Text)
+		})
+	}()
+}
+
+var transitionLabels = []struct {
+	mode  types.TransitionMode
+	label string
+}{
+	{types.TransitionNormal, "Normal"},
+	{types.TransitionGapless, "Gapless"},
+	{types.TransitionCrossfade, "Crossfade"},
+	{types.TransitionGap, "Pause between tracks"},
+}
+
+// showTransitionDialog lets the user choose how tracks of a playlist follow
+// each other when it is the queue source.
+func (pv *PlaylistsView) showTransitionDialog(playlist *types.Playlist) {
+	if pv.parentWindow == nil {
+		return
+	}
+
+	go func() {
+		storage := pv.musicService.GetStorage()
+		current, err := storage.GetPlaylistTransition(context.Background(), playlist.Slug)
+		if err != nil {
+			log.Printf("[PLAYLISTS_VIEW] Failed to load transition for %s: %v", playlist.Slug, err)
+			current = &types.PlaylistTransition{PlaylistSlug: playlist.Slug, Mode: types.TransitionNormal}
+		}
+
+		fyne.Do(func() {
+			options := make([]string, len(transitionLabels))
+			selected := transitionLabels[0].label
+			for i, t := range transitionLabels {
+				options[i] = t.label
+				if t.mode == current.Mode {
+					selected = t.label
+				}
+			}
+
+			secondsLabel := widget.NewLabel("")
+			secondsSlider := widget.NewSlider(1, 12)
+			secondsSlider.Step = 1
+			secondsSlider.OnChanged = func(v float64) {
+				secondsLabel.SetText(fmt.Sprintf("%.0f s", v))
+			}
+			secondsRow := container.NewBorder(nil, nil, widget.NewLabel("Length:"), secondsLabel, secondsSlider)
+
+			modeSelect := widget.NewSelect(options, func(label string) {
+				mode := transitionModeFor(label)
+				if mode == types.TransitionCrossfade || mode == types.TransitionGap {
+					secondsSlider.SetValue(types.PlaylistTransition{Mode: mode, Seconds: current.Seconds}.Duration().Seconds())
+					secondsRow.Show()
+				} else {
+					secondsRow.Hide()
+				}
+			})
+			modeSelect.SetSelected(selected)
+
+			content := container.NewVBox(
+				widget.NewLabel("Track transitions when playing this playlist:"),
+				modeSelect,
+				secondsRow,
+			)
+
+			dialog.ShowCustomConfirm(playlist.Name, "Save", "Cancel", content, func(save bool) {
+				if !save {
+					return
+				}
+				transition := &types.PlaylistTransition{
+					PlaylistSlug: playlist.Slug,
+					Mode:         transitionModeFor(modeSelect.Selected),
+				}
+				if secondsRow.Visible() {
+					transition.Seconds = int(secondsSlider.Value)
+				}
+				go func() {
+					if err := storage.SavePlaylistTransition(context.Background(), transition); err != nil {
+						log.Printf("[PLAYLISTS_VIEW] Failed to save transition for %s: %v", playlist.Slug, err)
+					}
+				}()
+			}, pv.parentWindow)
+		})
+	}()
+}
+
+func transitionModeFor(label string) types.TransitionMode {
+	for _, t := range transitionLabels {
+		if t.label == label {
+			return t.mode
+		}
+	}
+	return types.TransitionNormal
+}
+
+func (pv *PlaylistsView) SetParentWindow(window fyne.Window) {
+	pv.parentWindow = window
+}
+
+// SetUndoBar sets where deletions offer an undo.
+func (pv *PlaylistsView) SetUndoBar(bar *components.UndoBar) {
+	pv.undoBar = bar
 }
 
 func (pv *PlaylistsView) OnPlaylistSelected(callback func(*types.Playlist)) {
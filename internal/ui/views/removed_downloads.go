@@ -0,0 +1,123 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+)
+
+// showRemovedDownloads lists the downloads removed lately, so one deleted
+// by mistake can be downloaded again.
+func (lv *LocalFilesView) showRemovedDownloads() {
+	if lv.parentWindow == nil {
+		return
+	}
+	go func() {
+		removed, err := lv.handlers.Music().GetRemovedDownloads(context.Background())
+		fyne.Do(func() {
+			if err != nil {
+				log.Printf("[LOCAL_FILES_VIEW] Failed to load removed downloads: %v", err)
+				lv.showError(err)
+				return
+			}
+			lv.openRemovedDownloads(removed)
+		})
+	}()
+}
+
+func (lv *LocalFilesView) openRemovedDownloads(removed []*storage.RemovedDownload) {
+	rows := container.NewVBox()
+	empty := widget.NewLabel("No downloads were removed lately")
+
+	clearBtn := widget.NewButtonWithIcon("Clear List", theme.ContentClearIcon(), nil)
+	clearBtn.Importance = widget.LowImportance
+
+	update := func() {
+		if len(rows.Objects) == 0 {
+			rows.Add(empty)
+			clearBtn.Disable()
+		}
+	}
+
+	for _, entry := range removed {
+		var row fyne.CanvasObject
+		row = lv.removedDownloadRow(entry, func() {
+			rows.Remove(row)
+			update()
+		})
+		rows.Add(row)
+	}
+	update()
+
+	clearBtn.OnTapped = func() {
+		go func() {
+			err := lv.handlers.Music().ClearRemovedDownloads(context.Background())
+			fyne.Do(func() {
+				if err != nil {
+					lv.showError(err)
+					return
+				}
+				rows.RemoveAll()
+				update()
+			})
+		}()
+	}
+
+	hint := widget.NewLabel("Songs whose downloaded files were deleted lately. Download one again to get its file back.")
+	hint.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(480, 320))
+	content := container.NewBorder(
+		container.NewBorder(nil, nil, nil, clearBtn, hint),
+		nil, nil, nil, scroll,
+	)
+	dialog.ShowCustom("Recently Removed", "Close", content, lv.parentWindow)
+}
+
+// removedDownloadRow renders a removed download with a button to download
+// it again, after which done is called.
+func (lv *LocalFilesView) removedDownloadRow(entry *storage.RemovedDownload, done func()) fyne.CanvasObject {
+	song := entry.Song
+
+	title := widget.NewLabelWithStyle(song.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	title.Truncation = fyne.TextTruncateEllipsis
+	details := fmt.Sprintf("removed %s", entry.RemovedAt.Local().Format("Jan 2, 15:04"))
+	if artist := getArtistNames(song.Authors); artist != "" {
+		details = artist + " · " + details
+	}
+	detailsLbl := widget.NewLabel(details)
+	detailsLbl.Truncation = fyne.TextTruncateEllipsis
+
+	var downloadBtn *widget.Button
+	downloadBtn = widget.NewButtonWithIcon("Download Again", theme.DownloadIcon(), func() {
+		downloadBtn.Disable()
+		go func() {
+			ctx := context.Background()
+			err := lv.handlers.DownloadManager.DownloadSong(ctx, song)
+			if err == nil {
+				if forgetErr := lv.handlers.Music().ForgetRemovedDownload(ctx, song.Slug); forgetErr != nil {
+					log.Printf("[LOCAL_FILES_VIEW] Failed to forget removed download %s: %v", song.Slug, forgetErr)
+				}
+			}
+			fyne.Do(func() {
+				if err != nil {
+					downloadBtn.Enable()
+					lv.showError(fmt.Errorf("could not download %s: %w", song.Name, err))
+					return
+				}
+				done()
+			})
+		}()
+	})
+
+	return container.NewBorder(nil, nil, nil, downloadBtn, container.NewVBox(title, detailsLbl))
+}
@@ -1,11 +1,15 @@
 package views
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"slices"
 	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -13,7 +17,13 @@ import (
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/Alexander-D-Karpov/amp/internal/audio"
 	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/themes"
+	"github.com/Alexander-D-Karpov/amp/internal/updater"
 )
 
 type SettingsView struct {
@@ -26,21 +36,73 @@ type SettingsView struct {
 	tokenEntry    *widget.Entry
 	timeoutSlider *widget.Slider
 	retriesSlider *widget.Slider
+	testConnBtn   *widget.Button
+
+	proxyModeSelect    *widget.Select
+	proxyURLEntry      *widget.Entry
+	proxyUserEntry     *widget.Entry
+	proxyPasswordEntry *widget.Entry
+	caFileEntry        *widget.Entry
+	caFileBrowseBtn    *widget.Button
+	insecureTLSCheck   *widget.Check
 
 	cachePathEntry    *widget.Entry
 	cacheSizeSlider   *widget.Slider
 	autoDownloadCheck *widget.Check
 	walModeCheck      *widget.Check
+	cacheUsageLabel   *widget.Label
+	clearCacheBtn     *widget.Button
+
+	libraryFoldersEntry *widget.Entry
+	libraryWatchCheck   *widget.Check
+	scanLibraryBtn      *widget.Button
+	fullResyncBtn       *widget.Button
+
+	backupCheck          *widget.Check
+	backupFolderEntry    *widget.Entry
+	backupBrowseBtn      *widget.Button
+	backupIntervalSlider *widget.Slider
+	backupKeepSlider     *widget.Slider
+	backupNowBtn         *widget.Button
+	restoreBackupBtn     *widget.Button
 
 	sampleRateSelect *widget.Select
+	outputDevice     *widget.Select
+	cueDevice        *widget.Select
 	bufferSizeSlider *widget.Slider
 	volumeSlider     *widget.Slider
+	volumePolicy     *widget.Select
+	maxStartupSlider *widget.Slider
 	crossfadeCheck   *widget.Check
+	crossfadeSlider  *widget.Slider
+	crossfeedCheck   *widget.Check
+	crossfeedSlider  *widget.Slider
+	prebufferSlider  *widget.Slider
 
 	themeSelect       *widget.Select
 	languageSelect    *widget.Select
 	gridColumnsSlider *widget.Slider
 	windowSizeEntry   *widget.Entry
+	dynamicColorCheck *widget.Check
+	mprisCheck        *widget.Check
+	lrclibCheck       *widget.Check
+	singlePress       *widget.Select
+	doublePress       *widget.Select
+	triplePress       *widget.Select
+	hotkeysCheck      *widget.Check
+	hotkeyPlayPause   *widget.Entry
+	hotkeyNext        *widget.Entry
+	hotkeyPrevious    *widget.Entry
+	hotkeyVolumeUp    *widget.Entry
+	hotkeyVolumeDown  *widget.Entry
+	textScaleSlider   *widget.Slider
+	fontSelect        *widget.Select
+	timeFontSelect    *widget.Select
+	reduceMotionCheck *widget.Check
+	waveformSeekCheck *widget.Check
+	highContrastCheck *widget.Check
+	trayCheck         *widget.Check
+	hoverPreviewCheck *widget.Check
 
 	maxResultsSlider     *widget.Slider
 	fuzzyThresholdSlider *widget.Slider
@@ -51,13 +113,44 @@ type SettingsView struct {
 	chunkSizeSlider     *widget.Slider
 	tempDirEntry        *widget.Entry
 
+	updateChannelSelect *widget.Select
+	autoUpdateCheck     *widget.Check
+	updateStatusLabel   *widget.Label
+	checkUpdateBtn      *widget.Button
+	updater             *updater.Updater
+
+	partyCheck         *widget.Check
+	partyApprovalCheck *widget.Check
+	partyQuotaSlider   *widget.Slider
+	partyCodeEntry     *widget.Entry
+	partyAddressLabel  *widget.Label
+
+	breakCheck   *widget.Check
+	breakSlider  *widget.Slider
+	breakActions *widget.Select
+
+	smartCacheCheck   *widget.Check
+	smartUnknownCheck *widget.Check
+	smartTopSlider    *widget.Slider
+	smartLikedSlider  *widget.Slider
+	smartEvictSlider  *widget.Slider
+
 	saveBtn   *widget.Button
 	resetBtn  *widget.Button
 	exportBtn *widget.Button
 	importBtn *widget.Button
 	applyBtn  *widget.Button
 
+	importLibraryBtn *widget.Button
+
 	onSettingsChanged func()
+	onImportLibrary   func()
+	onScanLibrary     func()
+	onFullResync      func()
+	onClearCache      func()
+	onTestConnection  func()
+	onBackupNow       func()
+	onRestoreBackup   func()
 	originalConfig    *config.Config
 }
 
@@ -81,6 +174,16 @@ func (sv *SettingsView) setupLayout() {
 		sv.createFormRow("API Token:", sv.tokenEntry),
 		sv.createSliderRow("Timeout (seconds):", sv.timeoutSlider),
 		sv.createSliderRow("Retry Attempts:", sv.retriesSlider),
+		container.NewHBox(sv.testConnBtn),
+	))
+
+	networkCard := widget.NewCard("Network", "Proxy and certificates for every connection, used after a restart", container.NewVBox(
+		sv.createFormRow("Proxy:", sv.proxyModeSelect),
+		sv.createFormRow("Proxy URL:", sv.proxyURLEntry),
+		sv.createFormRow("Proxy User:", sv.proxyUserEntry),
+		sv.createFormRow("Proxy Password:", sv.proxyPasswordEntry),
+		sv.createFormRow("Extra CA File:", container.NewBorder(nil, nil, nil, sv.caFileBrowseBtn, sv.caFileEntry)),
+		sv.insecureTLSCheck,
 	))
 
 	storageCard := widget.NewCard("Storage Settings", "Configure local storage and caching", container.NewVBox(
@@ -88,13 +191,45 @@ func (sv *SettingsView) setupLayout() {
 		sv.createSliderRow("Max Cache Size (MB):", sv.cacheSizeSlider),
 		sv.autoDownloadCheck,
 		sv.walModeCheck,
+		sv.cacheUsageLabel,
+		container.NewHBox(sv.clearCacheBtn, sv.fullResyncBtn),
+	))
+
+	smartCacheCard := widget.NewCard("Smart Cache", "Download favorites and remove stale downloads at night, on mains power and an unmetered network", container.NewVBox(
+		sv.smartCacheCheck,
+		sv.smartUnknownCheck,
+		sv.createSliderRow("Most Played Songs Kept:", sv.smartTopSlider),
+		sv.createSliderRow("Keep Songs Liked Within (days):", sv.smartLikedSlider),
+		sv.createSliderRow("Remove Unplayed After (days):", sv.smartEvictSlider),
+	))
+
+	libraryCard := widget.NewCard("Local Library", "Add the music files in these folders to the library, one folder per line", container.NewVBox(
+		sv.libraryFoldersEntry,
+		sv.libraryWatchCheck,
+		container.NewHBox(sv.scanLibraryBtn),
+	))
+
+	backupCard := widget.NewCard("Backups", "Keep copies of your library database and settings in another folder", container.NewVBox(
+		sv.backupCheck,
+		sv.createFormRow("Backup Folder:", container.NewBorder(nil, nil, nil, sv.backupBrowseBtn, sv.backupFolderEntry)),
+		sv.createSliderRow("Every (hours):", sv.backupIntervalSlider),
+		sv.createSliderRow("Backups Kept:", sv.backupKeepSlider),
+		container.NewHBox(sv.backupNowBtn, sv.restoreBackupBtn),
 	))
 
 	audioCard := widget.NewCard("Audio Settings", "Configure audio playback options", container.NewVBox(
+		sv.createFormRow("Output Device:", sv.outputDevice),
+		sv.createFormRow("Pre-listen Device:", sv.cueDevice),
 		sv.createFormRow("Sample Rate:", sv.sampleRateSelect),
 		sv.createSliderRow("Buffer Size:", sv.bufferSizeSlider),
 		sv.createSliderRow("Default Volume (%):", sv.volumeSlider),
+		sv.createFormRow("Startup Volume:", sv.volumePolicy),
+		sv.createSliderRow("Max Startup Volume (%):", sv.maxStartupSlider),
+		sv.createSliderRow("Pre-buffer (seconds):", sv.prebufferSlider),
 		sv.crossfadeCheck,
+		sv.createSliderRow("Crossfade (seconds):", sv.crossfadeSlider),
+		sv.crossfeedCheck,
+		sv.createSliderRow("Crossfeed Intensity (%):", sv.crossfeedSlider),
 	))
 
 	uiCard := widget.NewCard("User Interface", "Customize the application appearance", container.NewVBox(
@@ -102,6 +237,26 @@ func (sv *SettingsView) setupLayout() {
 		sv.createFormRow("Language:", sv.languageSelect),
 		sv.createSliderRow("Grid Columns:", sv.gridColumnsSlider),
 		sv.createFormRow("Window Size:", sv.windowSizeEntry),
+		sv.createSliderRow("Text Size (%):", sv.textScaleSlider),
+		sv.createFormRow("Font:", sv.fontSelect),
+		sv.createFormRow("Time Display Font:", sv.timeFontSelect),
+		sv.reduceMotionCheck,
+		sv.waveformSeekCheck,
+		sv.highContrastCheck,
+		sv.trayCheck,
+		sv.hoverPreviewCheck,
+		sv.dynamicColorCheck,
+		sv.mprisCheck,
+		sv.lrclibCheck,
+		sv.createFormRow("Button Single Press:", sv.singlePress),
+		sv.createFormRow("Button Double Press:", sv.doublePress),
+		sv.createFormRow("Button Triple Press:", sv.triplePress),
+		sv.hotkeysCheck,
+		sv.createFormRow("Play/Pause Hotkey:", sv.hotkeyPlayPause),
+		sv.createFormRow("Next Hotkey:", sv.hotkeyNext),
+		sv.createFormRow("Previous Hotkey:", sv.hotkeyPrevious),
+		sv.createFormRow("Volume Up Hotkey:", sv.hotkeyVolumeUp),
+		sv.createFormRow("Volume Down Hotkey:", sv.hotkeyVolumeDown),
 	))
 
 	searchCard := widget.NewCard("Search Settings", "Configure search behavior", container.NewVBox(
@@ -117,22 +272,52 @@ func (sv *SettingsView) setupLayout() {
 		sv.createFormRow("Temporary Directory:", sv.tempDirEntry),
 	))
 
-	actionsCard := widget.NewCard("Actions", "Save, reset, or manage configuration", container.NewVBox(
+	updateCard := widget.NewCard("Updates", "Choose a release channel and check for new versions", container.NewVBox(
+		sv.createFormRow("Channel:", sv.updateChannelSelect),
+		sv.autoUpdateCheck,
+		sv.updateStatusLabel,
+		container.NewHBox(sv.checkUpdateBtn),
+	))
+
+	partyCard := widget.NewCard("Party Mode", "Let guests on your network request songs from a web page", container.NewVBox(
+		sv.partyCheck,
+		sv.partyApprovalCheck,
+		sv.createSliderRow("Songs per Guest per Hour (0 = no limit):", sv.partyQuotaSlider),
+		sv.createFormRow("Join Code:", sv.partyCodeEntry),
+		sv.partyAddressLabel,
+	))
+
+	wellbeingCard := widget.NewCard("Listening Breaks", "Suggest a break after listening for a long time without one", container.NewVBox(
+		sv.breakCheck,
+		sv.createSliderRow("After (minutes):", sv.breakSlider),
+		sv.createFormRow("When It Is Time:", sv.breakActions),
+	))
+
+	actionsCard := widget.NewCard("Actions", "Save, reset, or manage configuration and library data", container.NewVBox(
 		container.NewHBox(sv.saveBtn, sv.applyBtn),
 		container.NewHBox(sv.resetBtn),
 		widget.NewSeparator(),
 		container.NewHBox(sv.exportBtn, sv.importBtn),
+		widget.NewSeparator(),
+		container.NewHBox(sv.importLibraryBtn),
 	))
 
 	content := container.NewVBox(
 		widget.NewLabel("AMP Settings"),
 		widget.NewSeparator(),
 		apiCard,
+		networkCard,
 		storageCard,
+		smartCacheCard,
+		libraryCard,
+		backupCard,
 		audioCard,
 		uiCard,
 		searchCard,
 		downloadCard,
+		updateCard,
+		partyCard,
+		wellbeingCard,
 		actionsCard,
 	)
 
@@ -154,6 +339,37 @@ func (sv *SettingsView) setupWidgets() {
 	sv.retriesSlider = widget.NewSlider(1, 10)
 	sv.retriesSlider.Step = 1
 
+	sv.testConnBtn = widget.NewButtonWithIcon("Test Connection", theme.SearchIcon(), func() {
+		sv.updateConfigFromUI()
+		if err := netutil.Check(sv.cfg); err != nil {
+			sv.showError("Invalid Network Settings", err)
+			return
+		}
+		if sv.onTestConnection != nil {
+			sv.onTestConnection()
+		}
+	})
+
+	sv.proxyURLEntry = widget.NewEntry()
+	sv.proxyURLEntry.SetPlaceHolder("http://proxy:3128 or socks5://host:1080")
+	sv.proxyUserEntry = widget.NewEntry()
+	sv.proxyPasswordEntry = widget.NewPasswordEntry()
+	sv.proxyModeSelect = widget.NewSelect(proxyModeOptions, func(label string) {
+		if proxyModeValue(label) == netutil.ProxyManual {
+			sv.proxyURLEntry.Enable()
+			sv.proxyUserEntry.Enable()
+			sv.proxyPasswordEntry.Enable()
+		} else {
+			sv.proxyURLEntry.Disable()
+			sv.proxyUserEntry.Disable()
+			sv.proxyPasswordEntry.Disable()
+		}
+	})
+	sv.caFileEntry = widget.NewEntry()
+	sv.caFileEntry.SetPlaceHolder("/path/to/ca.pem")
+	sv.caFileBrowseBtn = widget.NewButtonWithIcon("", theme.FolderOpenIcon(), sv.chooseCAFile)
+	sv.insecureTLSCheck = widget.NewCheck("Accept any server certificate (unsafe, for testing only)", nil)
+
 	sv.cachePathEntry = widget.NewEntry()
 	sv.cachePathEntry.SetPlaceHolder("/path/to/cache")
 
@@ -163,15 +379,75 @@ func (sv *SettingsView) setupWidgets() {
 	sv.autoDownloadCheck = widget.NewCheck("Auto-download played songs", nil)
 	sv.walModeCheck = widget.NewCheck("Enable WAL mode (recommended)", nil)
 
+	sv.cacheUsageLabel = widget.NewLabel("")
+	sv.cacheUsageLabel.Wrapping = fyne.TextWrapWord
+	sv.clearCacheBtn = widget.NewButtonWithIcon("Clear Cache", theme.DeleteIcon(), func() {
+		if sv.onClearCache != nil {
+			sv.onClearCache()
+		}
+	})
+
+	sv.fullResyncBtn = widget.NewButtonWithIcon("Full Resync", theme.ViewRefreshIcon(), func() {
+		if sv.onFullResync != nil {
+			sv.onFullResync()
+		}
+	})
+
+	sv.libraryFoldersEntry = widget.NewMultiLineEntry()
+	sv.libraryFoldersEntry.SetPlaceHolder("~/Music")
+	sv.libraryFoldersEntry.SetMinRowsVisible(3)
+	sv.libraryWatchCheck = widget.NewCheck("Rescan when files change", nil)
+	sv.scanLibraryBtn = widget.NewButtonWithIcon("Scan Now", theme.SearchReplaceIcon(), func() {
+		sv.updateConfigFromUI()
+		if sv.onScanLibrary != nil {
+			sv.onScanLibrary()
+		}
+	})
+
+	sv.backupCheck = widget.NewCheck("Back up automatically", nil)
+	sv.backupFolderEntry = widget.NewEntry()
+	sv.backupFolderEntry.SetPlaceHolder("/path/to/backups")
+	sv.backupBrowseBtn = widget.NewButtonWithIcon("", theme.FolderOpenIcon(), sv.chooseBackupFolder)
+	sv.backupIntervalSlider = widget.NewSlider(1, 168)
+	sv.backupIntervalSlider.Step = 1
+	sv.backupKeepSlider = widget.NewSlider(1, 30)
+	sv.backupKeepSlider.Step = 1
+	sv.backupNowBtn = widget.NewButtonWithIcon("Back Up Now", theme.DocumentSaveIcon(), func() {
+		sv.updateConfigFromUI()
+		if sv.onBackupNow != nil {
+			sv.onBackupNow()
+		}
+	})
+	sv.restoreBackupBtn = widget.NewButtonWithIcon("Restore...", theme.HistoryIcon(), func() {
+		sv.updateConfigFromUI()
+		if sv.onRestoreBackup != nil {
+			sv.onRestoreBackup()
+		}
+	})
+
 	sv.sampleRateSelect = widget.NewSelect([]string{
 		"22050", "44100", "48000", "96000",
 	}, nil)
 
+	sv.outputDevice = widget.NewSelect([]string{defaultDeviceLabel}, nil)
+	sv.cueDevice = widget.NewSelect([]string{noCueDeviceLabel}, nil)
+
 	sv.bufferSizeSlider = widget.NewSlider(1024, 16384)
 	sv.bufferSizeSlider.Step = 1024
 
 	sv.volumeSlider = widget.NewSlider(0, 100)
+	sv.volumePolicy = widget.NewSelect(volumePolicyOptions, nil)
+	sv.maxStartupSlider = widget.NewSlider(0, 100)
+	sv.maxStartupSlider.Step = 5
+
+	sv.prebufferSlider = widget.NewSlider(1, 30)
+	sv.prebufferSlider.Step = 1
 	sv.crossfadeCheck = widget.NewCheck("Enable crossfade", nil)
+	sv.crossfadeSlider = widget.NewSlider(1, 12)
+	sv.crossfadeSlider.Step = 1
+	sv.crossfeedCheck = widget.NewCheck("Headphone crossfeed", nil)
+	sv.crossfeedSlider = widget.NewSlider(0, 100)
+	sv.crossfeedSlider.Step = 5
 
 	sv.themeSelect = widget.NewSelect([]string{"light", "dark"}, nil)
 	sv.languageSelect = widget.NewSelect([]string{
@@ -183,6 +459,32 @@ func (sv *SettingsView) setupWidgets() {
 
 	sv.windowSizeEntry = widget.NewEntry()
 	sv.windowSizeEntry.SetPlaceHolder("1200x800")
+	sv.dynamicColorCheck = widget.NewCheck("Tint player bar with cover colors", nil)
+	sv.mprisCheck = widget.NewCheck("Show in system media controls (MPRIS)", nil)
+	sv.lrclibCheck = widget.NewCheck("Look up lyrics on LRCLIB when the server has none", nil)
+	sv.singlePress = widget.NewSelect(buttonActionOptions, nil)
+	sv.doublePress = widget.NewSelect(buttonActionOptions, nil)
+	sv.triplePress = widget.NewSelect(buttonActionOptions, nil)
+	sv.hotkeysCheck = widget.NewCheck("Global hotkeys (work while other windows have focus)", nil)
+	sv.hotkeyPlayPause = widget.NewEntry()
+	sv.hotkeyPlayPause.SetPlaceHolder("CTRL+ALT+space")
+	sv.hotkeyNext = widget.NewEntry()
+	sv.hotkeyNext.SetPlaceHolder("CTRL+ALT+Right")
+	sv.hotkeyPrevious = widget.NewEntry()
+	sv.hotkeyPrevious.SetPlaceHolder("CTRL+ALT+Left")
+	sv.hotkeyVolumeUp = widget.NewEntry()
+	sv.hotkeyVolumeUp.SetPlaceHolder("CTRL+ALT+Up")
+	sv.hotkeyVolumeDown = widget.NewEntry()
+	sv.hotkeyVolumeDown.SetPlaceHolder("CTRL+ALT+Down")
+	sv.textScaleSlider = widget.NewSlider(themes.MinTextScale*100, themes.MaxTextScale*100)
+	sv.textScaleSlider.Step = 5
+	sv.fontSelect = widget.NewSelect(fontOptions, nil)
+	sv.timeFontSelect = widget.NewSelect(fontOptions, nil)
+	sv.reduceMotionCheck = widget.NewCheck("Reduce motion (no animated view changes)", nil)
+	sv.waveformSeekCheck = widget.NewCheck("Seek on the waveform instead of a slider", nil)
+	sv.highContrastCheck = widget.NewCheck("High contrast", nil)
+	sv.trayCheck = widget.NewCheck("Minimize to tray when the window is closed", nil)
+	sv.hoverPreviewCheck = widget.NewCheck("Preview songs when the pointer rests on them", nil)
 
 	sv.maxResultsSlider = widget.NewSlider(10, 500)
 	sv.maxResultsSlider.Step = 10
@@ -204,6 +506,36 @@ func (sv *SettingsView) setupWidgets() {
 	sv.tempDirEntry = widget.NewEntry()
 	sv.tempDirEntry.SetPlaceHolder("/path/to/temp")
 
+	sv.updateChannelSelect = widget.NewSelect([]string{updater.ChannelStable, updater.ChannelBeta}, nil)
+	sv.autoUpdateCheck = widget.NewCheck("Check for updates automatically", nil)
+	sv.updateStatusLabel = widget.NewLabel("")
+	sv.updateStatusLabel.Wrapping = fyne.TextWrapWord
+	sv.checkUpdateBtn = widget.NewButtonWithIcon("Check for Updates", theme.DownloadIcon(), sv.checkForUpdates)
+	sv.checkUpdateBtn.Disable()
+
+	sv.partyCheck = widget.NewCheck("Enable party mode", nil)
+	sv.partyApprovalCheck = widget.NewCheck("Approve requests before they are queued", nil)
+	sv.partyQuotaSlider = widget.NewSlider(0, 20)
+	sv.partyQuotaSlider.Step = 1
+	sv.partyCodeEntry = widget.NewEntry()
+	sv.partyCodeEntry.SetPlaceHolder("Leave empty to let anyone on the network in")
+	sv.partyAddressLabel = widget.NewLabel("")
+	sv.partyAddressLabel.Wrapping = fyne.TextWrapWord
+
+	sv.breakCheck = widget.NewCheck("Remind me to take breaks", nil)
+	sv.breakSlider = widget.NewSlider(15, 240)
+	sv.breakSlider.Step = 15
+	sv.breakActions = widget.NewSelect(breakActionOptions, nil)
+
+	sv.smartCacheCheck = widget.NewCheck("Manage downloads overnight", nil)
+	sv.smartUnknownCheck = widget.NewCheck("Run when power and network state are unknown", nil)
+	sv.smartTopSlider = widget.NewSlider(0, 500)
+	sv.smartTopSlider.Step = 10
+	sv.smartLikedSlider = widget.NewSlider(0, 365)
+	sv.smartLikedSlider.Step = 1
+	sv.smartEvictSlider = widget.NewSlider(0, 365)
+	sv.smartEvictSlider.Step = 1
+
 	sv.saveBtn = widget.NewButtonWithIcon("Save Settings", theme.DocumentSaveIcon(), sv.saveSettings)
 	sv.saveBtn.Importance = widget.HighImportance
 
@@ -211,6 +543,11 @@ func (sv *SettingsView) setupWidgets() {
 	sv.applyBtn = widget.NewButtonWithIcon("Apply Changes", theme.ConfirmIcon(), sv.applySettings)
 	sv.exportBtn = widget.NewButtonWithIcon("Export Config", theme.FolderOpenIcon(), sv.exportSettings)
 	sv.importBtn = widget.NewButtonWithIcon("Import Config", theme.FolderIcon(), sv.importSettings)
+	sv.importLibraryBtn = widget.NewButtonWithIcon("Import from Another Player", theme.DownloadIcon(), func() {
+		if sv.onImportLibrary != nil {
+			sv.onImportLibrary()
+		}
+	})
 }
 
 func (sv *SettingsView) createFormRow(label string, comp fyne.CanvasObject) *fyne.Container {
@@ -244,26 +581,263 @@ func (sv *SettingsView) createSliderRow(label string, slider *widget.Slider) *fy
 	)
 }
 
+var volumePolicyOptions = []string{
+	"Restore last volume",
+	"Always use default volume",
+	"Restore, capped at max",
+}
+
+var volumePolicyValues = []string{audio.VolumeRestore, audio.VolumeFixed, audio.VolumeCapped}
+
+func volumePolicyLabel(policy string) string {
+	for i, value := range volumePolicyValues {
+		if value == policy {
+			return volumePolicyOptions[i]
+		}
+	}
+	return volumePolicyOptions[0]
+}
+
+func volumePolicyValue(label string) string {
+	for i, option := range volumePolicyOptions {
+		if option == label {
+			return volumePolicyValues[i]
+		}
+	}
+	return audio.VolumeRestore
+}
+
+var buttonActionOptions = []string{"Nothing", "Play/Pause", "Next track", "Previous track"}
+
+var buttonActionValues = []string{
+	components.ButtonActionNone,
+	components.ButtonActionPlayPause,
+	components.ButtonActionNext,
+	components.ButtonActionPrevious,
+}
+
+func buttonActionLabel(action string) string {
+	for i, value := range buttonActionValues {
+		if value == action {
+			return buttonActionOptions[i]
+		}
+	}
+	return buttonActionOptions[0]
+}
+
+func buttonActionValue(label string) string {
+	for i, option := range buttonActionOptions {
+		if option == label {
+			return buttonActionValues[i]
+		}
+	}
+	return components.ButtonActionNone
+}
+
+// defaultDeviceLabel stands for the system default output device, and
+// noCueDeviceLabel for pre-listening turned off.
+const (
+	defaultDeviceLabel = "System default"
+	noCueDeviceLabel   = "Off"
+)
+
+func outputDeviceLabel(device, empty string) string {
+	if device == "" {
+		return empty
+	}
+	return device
+}
+
+func outputDeviceValue(label, empty string) string {
+	if label == empty {
+		return ""
+	}
+	return label
+}
+
+// loadOutputDevices lists the output and pre-listen devices to choose from.
+// The configured devices stay listed while they are unplugged.
+func (sv *SettingsView) loadOutputDevices() {
+	selects := []struct {
+		sel        *widget.Select
+		configured string
+		empty      string
+	}{
+		{sv.outputDevice, sv.cfg.Audio.OutputDevice, defaultDeviceLabel},
+		{sv.cueDevice, sv.cfg.Audio.CueDevice, noCueDeviceLabel},
+	}
+	for _, s := range selects {
+		options := []string{s.empty}
+		if s.configured != "" {
+			options = append(options, s.configured)
+		}
+		s.sel.SetOptions(options)
+		s.sel.SetSelected(outputDeviceLabel(s.configured, s.empty))
+	}
+
+	go func() {
+		devices, err := audio.OutputDevices()
+		if errors.Is(err, audio.ErrDeviceSelection) {
+			// This build always plays on the system default device.
+			fyne.Do(func() {
+				for _, s := range selects {
+					s.sel.Disable()
+				}
+			})
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to list output devices: %v", err)
+			return
+		}
+		fyne.Do(func() {
+			for _, s := range selects {
+				options := append([]string{s.empty}, devices...)
+				if s.configured != "" && !slices.Contains(devices, s.configured) {
+					options = append(options, s.configured)
+				}
+				selected := s.sel.Selected
+				s.sel.SetOptions(options)
+				s.sel.SetSelected(selected)
+			}
+		})
+	}()
+}
+
+var breakActionOptions = []string{"Show a reminder", "Pause playback"}
+
+var breakActionValues = []string{components.BreakActionRemind, components.BreakActionPause}
+
+func breakActionLabel(action string) string {
+	for i, value := range breakActionValues {
+		if value == action {
+			return breakActionOptions[i]
+		}
+	}
+	return breakActionOptions[0]
+}
+
+func breakActionValue(label string) string {
+	for i, option := range breakActionOptions {
+		if option == label {
+			return breakActionValues[i]
+		}
+	}
+	return components.BreakActionRemind
+}
+
+var proxyModeOptions = []string{"System settings", "No proxy", "Manual"}
+
+var proxyModeValues = []string{netutil.ProxySystem, netutil.ProxyNone, netutil.ProxyManual}
+
+func proxyModeLabel(mode string) string {
+	for i, value := range proxyModeValues {
+		if value == mode {
+			return proxyModeOptions[i]
+		}
+	}
+	return proxyModeOptions[0]
+}
+
+func proxyModeValue(label string) string {
+	for i, option := range proxyModeOptions {
+		if option == label {
+			return proxyModeValues[i]
+		}
+	}
+	return netutil.ProxySystem
+}
+
+var fontOptions = []string{"Standard", "Monospace"}
+
+var fontValues = []string{themes.FontDefault, themes.FontMonospace}
+
+// fontLabel returns the option shown for a font setting, or for fallback
+// when the setting is empty or unknown.
+func fontLabel(font, fallback string) string {
+	for i, value := range fontValues {
+		if value == font {
+			return fontOptions[i]
+		}
+	}
+	return fontLabel(fallback, themes.FontDefault)
+}
+
+func fontValue(label string) string {
+	for i, option := range fontOptions {
+		if option == label {
+			return fontValues[i]
+		}
+	}
+	return themes.FontDefault
+}
+
 func (sv *SettingsView) loadSettings() {
 	sv.apiURLEntry.SetText(sv.cfg.API.BaseURL)
 	sv.tokenEntry.SetText(sv.cfg.API.Token)
 	sv.timeoutSlider.SetValue(float64(sv.cfg.API.Timeout))
 	sv.retriesSlider.SetValue(float64(sv.cfg.API.Retries))
 
+	sv.proxyURLEntry.SetText(sv.cfg.Network.Proxy.URL)
+	sv.proxyUserEntry.SetText(sv.cfg.Network.Proxy.Username)
+	sv.proxyPasswordEntry.SetText(sv.cfg.Network.Proxy.Password)
+	sv.proxyModeSelect.SetSelected(proxyModeLabel(sv.cfg.Network.Proxy.Mode))
+	sv.caFileEntry.SetText(sv.cfg.Network.CAFile)
+	sv.insecureTLSCheck.SetChecked(sv.cfg.Network.InsecureSkipVerify)
+
 	sv.cachePathEntry.SetText(sv.cfg.Storage.CacheDir)
 	sv.cacheSizeSlider.SetValue(float64(sv.cfg.Storage.MaxCacheSize / 1024 / 1024))
 	sv.autoDownloadCheck.SetChecked(sv.cfg.Download.AutoDownload)
 	sv.walModeCheck.SetChecked(sv.cfg.Storage.EnableWAL)
 
+	sv.libraryFoldersEntry.SetText(strings.Join(sv.cfg.Library.Folders, "\n"))
+	sv.libraryWatchCheck.SetChecked(sv.cfg.Library.Watch)
+
+	sv.backupCheck.SetChecked(sv.cfg.Backup.Enabled)
+	sv.backupFolderEntry.SetText(sv.cfg.Backup.Folder)
+	sv.backupIntervalSlider.SetValue(float64(sv.cfg.Backup.IntervalHours))
+	sv.backupKeepSlider.SetValue(float64(sv.cfg.Backup.Keep))
+
+	sv.loadOutputDevices()
 	sv.sampleRateSelect.SetSelected(fmt.Sprintf("%d", sv.cfg.Audio.SampleRate))
 	sv.bufferSizeSlider.SetValue(float64(sv.cfg.Audio.BufferSize))
 	sv.volumeSlider.SetValue(sv.cfg.Audio.DefaultVolume * 100)
+	sv.volumePolicy.SetSelected(volumePolicyLabel(sv.cfg.Audio.VolumePolicy))
+	sv.maxStartupSlider.SetValue(sv.cfg.Audio.MaxStartupVolume * 100)
+	sv.prebufferSlider.SetValue(float64(sv.cfg.Audio.PrebufferSeconds))
 	sv.crossfadeCheck.SetChecked(sv.cfg.Audio.Crossfade)
+	sv.crossfadeSlider.SetValue(float64(sv.cfg.Audio.CrossfadeSeconds))
+	sv.crossfeedCheck.SetChecked(sv.cfg.Audio.Crossfeed)
+	sv.crossfeedSlider.SetValue(sv.cfg.Audio.CrossfeedLevel * 100)
 
 	sv.themeSelect.SetSelected(sv.cfg.UI.Theme)
 	sv.languageSelect.SetSelected(sv.cfg.UI.Language)
 	sv.gridColumnsSlider.SetValue(float64(sv.cfg.UI.GridColumns))
 	sv.windowSizeEntry.SetText(fmt.Sprintf("%dx%d", sv.cfg.UI.WindowWidth, sv.cfg.UI.WindowHeight))
+	sv.dynamicColorCheck.SetChecked(sv.cfg.UI.DynamicColors)
+	sv.mprisCheck.SetChecked(sv.cfg.Integrations.MPRIS)
+	sv.lrclibCheck.SetChecked(sv.cfg.Integrations.LRCLIB)
+	sv.singlePress.SetSelected(buttonActionLabel(sv.cfg.Integrations.MediaButton.SinglePress))
+	sv.doublePress.SetSelected(buttonActionLabel(sv.cfg.Integrations.MediaButton.DoublePress))
+	sv.triplePress.SetSelected(buttonActionLabel(sv.cfg.Integrations.MediaButton.TriplePress))
+	sv.hotkeysCheck.SetChecked(sv.cfg.Integrations.GlobalHotkeys.Enabled)
+	sv.hotkeyPlayPause.SetText(sv.cfg.Integrations.GlobalHotkeys.PlayPause)
+	sv.hotkeyNext.SetText(sv.cfg.Integrations.GlobalHotkeys.Next)
+	sv.hotkeyPrevious.SetText(sv.cfg.Integrations.GlobalHotkeys.Previous)
+	sv.hotkeyVolumeUp.SetText(sv.cfg.Integrations.GlobalHotkeys.VolumeUp)
+	sv.hotkeyVolumeDown.SetText(sv.cfg.Integrations.GlobalHotkeys.VolumeDown)
+	textScale := sv.cfg.UI.TextScale
+	if textScale == 0 {
+		textScale = 1
+	}
+	sv.textScaleSlider.SetValue(textScale * 100)
+	sv.fontSelect.SetSelected(fontLabel(sv.cfg.UI.Font, themes.FontDefault))
+	sv.timeFontSelect.SetSelected(fontLabel(sv.cfg.UI.TimeFont, themes.FontMonospace))
+	sv.reduceMotionCheck.SetChecked(sv.cfg.UI.ReduceMotion)
+	sv.waveformSeekCheck.SetChecked(sv.cfg.UI.WaveformSeek)
+	sv.highContrastCheck.SetChecked(sv.cfg.UI.HighContrast)
+	sv.trayCheck.SetChecked(sv.cfg.UI.MinimizeToTray)
+	sv.hoverPreviewCheck.SetChecked(sv.cfg.UI.HoverPreview)
 
 	sv.maxResultsSlider.SetValue(float64(sv.cfg.Search.MaxResults))
 	sv.fuzzyThresholdSlider.SetValue(sv.cfg.Search.FuzzyThreshold)
@@ -273,6 +847,24 @@ func (sv *SettingsView) loadSettings() {
 	sv.maxConcurrentSlider.SetValue(float64(sv.cfg.Download.MaxConcurrent))
 	sv.chunkSizeSlider.SetValue(float64(sv.cfg.Download.ChunkSize / 1024))
 	sv.tempDirEntry.SetText(sv.cfg.Download.TempDir)
+
+	sv.updateChannelSelect.SetSelected(sv.cfg.Update.Channel)
+	sv.autoUpdateCheck.SetChecked(sv.cfg.Update.AutoCheck)
+	sv.refreshUpdateStatus()
+
+	sv.partyCheck.SetChecked(sv.cfg.Party.Enabled)
+	sv.partyApprovalCheck.SetChecked(sv.cfg.Party.RequireApproval)
+	sv.partyQuotaSlider.SetValue(float64(sv.cfg.Party.RequestsPerHour))
+	sv.partyCodeEntry.SetText(sv.cfg.Party.JoinCode)
+
+	sv.breakCheck.SetChecked(sv.cfg.Wellbeing.BreakReminder)
+	sv.smartCacheCheck.SetChecked(sv.cfg.SmartCache.Enabled)
+	sv.smartUnknownCheck.SetChecked(sv.cfg.SmartCache.RunWhenUnknown)
+	sv.smartTopSlider.SetValue(float64(sv.cfg.SmartCache.TopSongs))
+	sv.smartLikedSlider.SetValue(float64(sv.cfg.SmartCache.LikedDays))
+	sv.smartEvictSlider.SetValue(float64(sv.cfg.SmartCache.EvictDays))
+	sv.breakSlider.SetValue(float64(sv.cfg.Wellbeing.BreakMinutes))
+	sv.breakActions.SetSelected(breakActionLabel(sv.cfg.Wellbeing.BreakAction))
 }
 
 func (sv *SettingsView) applySettings() {
@@ -288,6 +880,11 @@ func (sv *SettingsView) applySettings() {
 func (sv *SettingsView) saveSettings() {
 	sv.updateConfigFromUI()
 
+	if err := netutil.Check(sv.cfg); err != nil {
+		sv.showError("Invalid Network Settings", err)
+		return
+	}
+
 	if err := sv.saveConfigToFile(); err != nil {
 		sv.showError("Save Failed", err)
 		return
@@ -306,21 +903,70 @@ func (sv *SettingsView) updateConfigFromUI() {
 	sv.cfg.API.Timeout = int(sv.timeoutSlider.Value)
 	sv.cfg.API.Retries = int(sv.retriesSlider.Value)
 
+	sv.cfg.Network.Proxy.Mode = proxyModeValue(sv.proxyModeSelect.Selected)
+	sv.cfg.Network.Proxy.URL = strings.TrimSpace(sv.proxyURLEntry.Text)
+	sv.cfg.Network.Proxy.Username = sv.proxyUserEntry.Text
+	sv.cfg.Network.Proxy.Password = sv.proxyPasswordEntry.Text
+	sv.cfg.Network.CAFile = strings.TrimSpace(sv.caFileEntry.Text)
+	sv.cfg.Network.InsecureSkipVerify = sv.insecureTLSCheck.Checked
+
 	sv.cfg.Storage.CacheDir = sv.cachePathEntry.Text
 	sv.cfg.Storage.MaxCacheSize = int64(sv.cacheSizeSlider.Value * 1024 * 1024)
 	sv.cfg.Download.AutoDownload = sv.autoDownloadCheck.Checked
 	sv.cfg.Storage.EnableWAL = sv.walModeCheck.Checked
 
+	var folders []string
+	for _, folder := range strings.Split(sv.libraryFoldersEntry.Text, "\n") {
+		if folder = strings.TrimSpace(folder); folder != "" {
+			folders = append(folders, folder)
+		}
+	}
+	sv.cfg.Library.Folders = folders
+	sv.cfg.Library.Watch = sv.libraryWatchCheck.Checked
+
+	sv.cfg.Backup.Enabled = sv.backupCheck.Checked
+	sv.cfg.Backup.Folder = strings.TrimSpace(sv.backupFolderEntry.Text)
+	sv.cfg.Backup.IntervalHours = int(sv.backupIntervalSlider.Value)
+	sv.cfg.Backup.Keep = int(sv.backupKeepSlider.Value)
+
+	sv.cfg.Audio.OutputDevice = outputDeviceValue(sv.outputDevice.Selected, defaultDeviceLabel)
+	sv.cfg.Audio.CueDevice = outputDeviceValue(sv.cueDevice.Selected, noCueDeviceLabel)
 	if rate, err := strconv.Atoi(sv.sampleRateSelect.Selected); err == nil {
 		sv.cfg.Audio.SampleRate = rate
 	}
 	sv.cfg.Audio.BufferSize = int(sv.bufferSizeSlider.Value)
 	sv.cfg.Audio.DefaultVolume = sv.volumeSlider.Value / 100.0
+	sv.cfg.Audio.VolumePolicy = volumePolicyValue(sv.volumePolicy.Selected)
+	sv.cfg.Audio.MaxStartupVolume = sv.maxStartupSlider.Value / 100.0
+	sv.cfg.Audio.PrebufferSeconds = int(sv.prebufferSlider.Value)
 	sv.cfg.Audio.Crossfade = sv.crossfadeCheck.Checked
+	sv.cfg.Audio.CrossfadeSeconds = int(sv.crossfadeSlider.Value)
+	sv.cfg.Audio.Crossfeed = sv.crossfeedCheck.Checked
+	sv.cfg.Audio.CrossfeedLevel = sv.crossfeedSlider.Value / 100.0
 
 	sv.cfg.UI.Theme = sv.themeSelect.Selected
 	sv.cfg.UI.Language = sv.languageSelect.Selected
 	sv.cfg.UI.GridColumns = int(sv.gridColumnsSlider.Value)
+	sv.cfg.UI.DynamicColors = sv.dynamicColorCheck.Checked
+	sv.cfg.Integrations.MPRIS = sv.mprisCheck.Checked
+	sv.cfg.Integrations.LRCLIB = sv.lrclibCheck.Checked
+	sv.cfg.Integrations.MediaButton.SinglePress = buttonActionValue(sv.singlePress.Selected)
+	sv.cfg.Integrations.MediaButton.DoublePress = buttonActionValue(sv.doublePress.Selected)
+	sv.cfg.Integrations.MediaButton.TriplePress = buttonActionValue(sv.triplePress.Selected)
+	sv.cfg.Integrations.GlobalHotkeys.Enabled = sv.hotkeysCheck.Checked
+	sv.cfg.Integrations.GlobalHotkeys.PlayPause = strings.TrimSpace(sv.hotkeyPlayPause.Text)
+	sv.cfg.Integrations.GlobalHotkeys.Next = strings.TrimSpace(sv.hotkeyNext.Text)
+	sv.cfg.Integrations.GlobalHotkeys.Previous = strings.TrimSpace(sv.hotkeyPrevious.Text)
+	sv.cfg.Integrations.GlobalHotkeys.VolumeUp = strings.TrimSpace(sv.hotkeyVolumeUp.Text)
+	sv.cfg.Integrations.GlobalHotkeys.VolumeDown = strings.TrimSpace(sv.hotkeyVolumeDown.Text)
+	sv.cfg.UI.TextScale = sv.textScaleSlider.Value / 100.0
+	sv.cfg.UI.Font = fontValue(sv.fontSelect.Selected)
+	sv.cfg.UI.TimeFont = fontValue(sv.timeFontSelect.Selected)
+	sv.cfg.UI.ReduceMotion = sv.reduceMotionCheck.Checked
+	sv.cfg.UI.WaveformSeek = sv.waveformSeekCheck.Checked
+	sv.cfg.UI.HighContrast = sv.highContrastCheck.Checked
+	sv.cfg.UI.MinimizeToTray = sv.trayCheck.Checked
+	sv.cfg.UI.HoverPreview = sv.hoverPreviewCheck.Checked
 
 	if windowSize := sv.windowSizeEntry.Text; windowSize != "" {
 		var width, height int
@@ -338,6 +984,142 @@ func (sv *SettingsView) updateConfigFromUI() {
 	sv.cfg.Download.MaxConcurrent = int(sv.maxConcurrentSlider.Value)
 	sv.cfg.Download.ChunkSize = int(sv.chunkSizeSlider.Value * 1024)
 	sv.cfg.Download.TempDir = sv.tempDirEntry.Text
+
+	if sv.updateChannelSelect.Selected != "" {
+		sv.cfg.Update.Channel = sv.updateChannelSelect.Selected
+	}
+	sv.cfg.Update.AutoCheck = sv.autoUpdateCheck.Checked
+
+	sv.cfg.Party.Enabled = sv.partyCheck.Checked
+	sv.cfg.Party.RequireApproval = sv.partyApprovalCheck.Checked
+	sv.cfg.Party.RequestsPerHour = int(sv.partyQuotaSlider.Value)
+	sv.cfg.Party.JoinCode = strings.TrimSpace(sv.partyCodeEntry.Text)
+
+	sv.cfg.Wellbeing.BreakReminder = sv.breakCheck.Checked
+	sv.cfg.SmartCache.Enabled = sv.smartCacheCheck.Checked
+	sv.cfg.SmartCache.RunWhenUnknown = sv.smartUnknownCheck.Checked
+	sv.cfg.SmartCache.TopSongs = int(sv.smartTopSlider.Value)
+	sv.cfg.SmartCache.LikedDays = int(sv.smartLikedSlider.Value)
+	sv.cfg.SmartCache.EvictDays = int(sv.smartEvictSlider.Value)
+	sv.cfg.Wellbeing.BreakMinutes = int(sv.breakSlider.Value)
+	sv.cfg.Wellbeing.BreakAction = breakActionValue(sv.breakActions.Selected)
+}
+
+// SetPartyAddresses shows where guests can reach the party page. An empty
+// list means party mode is not running.
+func (sv *SettingsView) SetPartyAddresses(urls []string) {
+	if len(urls) == 0 {
+		sv.partyAddressLabel.SetText("")
+		return
+	}
+	sv.partyAddressLabel.SetText("Guests can open: " + strings.Join(urls, ", "))
+}
+
+// SetCacheUsage shows how much of the cache budget is taken.
+func (sv *SettingsView) SetCacheUsage(usage storage.CacheUsage) {
+	text := fmt.Sprintf("Using %s of %s: %s downloaded songs, %s images",
+		formatBytes(usage.Total()), formatBytes(usage.Limit),
+		formatBytes(usage.Downloads), formatBytes(usage.Images))
+	if usage.Other > 0 {
+		text += ", " + formatBytes(usage.Other) + " other"
+	}
+	sv.cacheUsageLabel.SetText(text)
+}
+
+// formatBytes renders a size in the largest unit that keeps it above one.
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size) / unit
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= unit
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
+
+// SetUpdater enables the update controls once the updater is available.
+func (sv *SettingsView) SetUpdater(u *updater.Updater) {
+	sv.updater = u
+	if u != nil {
+		sv.checkUpdateBtn.Enable()
+	}
+	sv.refreshUpdateStatus()
+}
+
+func (sv *SettingsView) refreshUpdateStatus() {
+	if sv.updater == nil {
+		sv.updateStatusLabel.SetText("Updates are not available in this build")
+		return
+	}
+
+	status := fmt.Sprintf("Current version: %s", sv.updater.CurrentVersion())
+	if staged := sv.updater.StagedVersion(); staged != "" {
+		status += fmt.Sprintf("\nVersion %s will be installed on restart", staged)
+	} else if latest := sv.updater.LatestRelease(); latest != nil {
+		status += fmt.Sprintf("\nVersion %s is available", latest.Version)
+	}
+	sv.updateStatusLabel.SetText(status)
+}
+
+func (sv *SettingsView) checkForUpdates() {
+	if sv.updater == nil {
+		return
+	}
+
+	if sv.updateChannelSelect.Selected != "" {
+		sv.cfg.Update.Channel = sv.updateChannelSelect.Selected
+	}
+
+	sv.checkUpdateBtn.Disable()
+	sv.updateStatusLabel.SetText(fmt.Sprintf("Checking the %s channel...", sv.updater.Channel()))
+
+	go func() {
+		release, err := sv.updater.Check(context.Background())
+		fyne.Do(func() {
+			sv.checkUpdateBtn.Enable()
+			sv.refreshUpdateStatus()
+
+			if err != nil {
+				sv.showError("Update Check Failed", err)
+				return
+			}
+			if release == nil {
+				sv.showInfo("No Updates", "You are running the latest version.")
+				return
+			}
+
+			message := fmt.Sprintf("AMP %s is available. Download it now? It will be installed when you restart.", release.Version)
+			dialog.ShowConfirm("Update Available", message, func(confirmed bool) {
+				if confirmed {
+					sv.downloadUpdate(release)
+				}
+			}, sv.parentWindow)
+		})
+	}()
+}
+
+func (sv *SettingsView) downloadUpdate(release *updater.Release) {
+	sv.checkUpdateBtn.Disable()
+	sv.updateStatusLabel.SetText(fmt.Sprintf("Downloading %s...", release.Version))
+
+	go func() {
+		err := sv.updater.Download(context.Background(), release)
+		fyne.Do(func() {
+			sv.checkUpdateBtn.Enable()
+			sv.refreshUpdateStatus()
+
+			if err != nil {
+				sv.showError("Update Failed", err)
+				return
+			}
+			sv.showInfo("Update Ready", fmt.Sprintf("AMP %s has been verified and will be installed on restart.", release.Version))
+		})
+	}()
 }
 
 func (sv *SettingsView) resetSettings() {
@@ -443,6 +1225,80 @@ func (sv *SettingsView) OnSettingsChanged(callback func()) {
 	sv.onSettingsChanged = callback
 }
 
+// OnImportLibrary is called when the user asks to import ratings, playlists
+// and history from another player.
+func (sv *SettingsView) OnImportLibrary(callback func()) {
+	sv.onImportLibrary = callback
+}
+
+// OnScanLibrary is called when the user asks to scan the library folders
+// now, after the folders shown were applied.
+func (sv *SettingsView) OnScanLibrary(callback func()) {
+	sv.onScanLibrary = callback
+}
+
+// OnTestConnection is called when the user asks to check the connection to
+// the server, after the settings shown were applied.
+func (sv *SettingsView) OnTestConnection(callback func()) {
+	sv.onTestConnection = callback
+}
+
+// OnFullResync is called when the user asks to fetch the whole library from
+// the server again instead of only what changed.
+func (sv *SettingsView) OnFullResync(callback func()) {
+	sv.onFullResync = callback
+}
+
+// OnClearCache is called when the user asks to remove the cached images.
+// Downloaded songs are kept.
+func (sv *SettingsView) OnClearCache(callback func()) {
+	sv.onClearCache = callback
+}
+
+// OnBackupNow is called when the user asks for a backup right away.
+func (sv *SettingsView) OnBackupNow(callback func()) {
+	sv.onBackupNow = callback
+}
+
+// OnRestoreBackup is called when the user asks to restore a backup.
+func (sv *SettingsView) OnRestoreBackup(callback func()) {
+	sv.onRestoreBackup = callback
+}
+
+func (sv *SettingsView) chooseBackupFolder() {
+	if sv.parentWindow == nil {
+		return
+	}
+	dialog.ShowFolderOpen(func(folder fyne.ListableURI, err error) {
+		if err != nil {
+			sv.showError("Choose Folder", err)
+			return
+		}
+		if folder != nil {
+			sv.backupFolderEntry.SetText(folder.Path())
+		}
+	}, sv.parentWindow)
+}
+
+func (sv *SettingsView) chooseCAFile() {
+	if sv.parentWindow == nil {
+		return
+	}
+	dialog.ShowFileOpen(func(file fyne.URIReadCloser, err error) {
+		if err != nil {
+			sv.showError("Choose CA File", err)
+			return
+		}
+		if file == nil {
+			return
+		}
+		sv.caFileEntry.SetText(file.URI().Path())
+		if err := file.Close(); err != nil {
+			log.Printf("Failed to close CA file reader: %v", err)
+		}
+	}, sv.parentWindow)
+}
+
 func (sv *SettingsView) Container() *fyne.Container {
 	return container.NewStack(sv.container)
 }
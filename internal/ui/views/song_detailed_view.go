@@ -1,6 +1,7 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -12,26 +13,35 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/themes"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
+// songCoverSize is how large the song's cover is shown.
+var songCoverSize = fyne.NewSize(300, 300)
+
 type SongDetailView struct {
 	imgSvc *services.ImageService
 
 	root           *fyne.Container
+	parentWindow   fyne.Window
 	splitContainer *container.Split
 	backBtn        *widget.Button
 	playBtn        *widget.Button
 	likeBtn        *widget.Button
 	downloadBtn    *widget.Button
+	artworkBtn     *widget.Button
 	titleLbl       *widget.Label
 	artistsBox     *fyne.Container
 	cover          *canvas.Image
 	metaLbl        *widget.Label
 	albumBtn       *widget.Button
 	fileInfoLbl    *widget.Label
+	lyrics         *lyricsPanel
 
 	song *types.Song
+	// coverCancel drops the pending cover request when another song is shown.
+	coverCancel context.CancelFunc
 
 	onBack       func()
 	onOpenAlbum  func(string)
@@ -61,7 +71,7 @@ func (v *SongDetailView) build() {
 	})
 	v.playBtn.Importance = widget.HighImportance
 
-	v.likeBtn = widget.NewButtonWithIcon("Like", theme.VisibilityOffIcon(), func() {
+	v.likeBtn = widget.NewButtonWithIcon("Like", themes.LikeIcon(false), func() {
 		if v.onLike != nil && v.song != nil {
 			v.onLike(v.song)
 		}
@@ -73,6 +83,14 @@ func (v *SongDetailView) build() {
 		}
 	})
 
+	v.artworkBtn = widget.NewButtonWithIcon("Change Artwork", theme.FileImageIcon(), func() {
+		if v.song == nil {
+			return
+		}
+		showChangeArtwork(v.parentWindow, v.imgSvc, types.ArtworkSong, v.song.Slug,
+			services.SongImageURL(v.song, songCoverSize), v.loadCover)
+	})
+
 	v.titleLbl = widget.NewLabel("")
 	v.titleLbl.TextStyle = fyne.TextStyle{Bold: true}
 	v.titleLbl.Wrapping = fyne.TextWrapWord
@@ -84,11 +102,12 @@ func (v *SongDetailView) build() {
 	v.albumBtn = widget.NewButton("", nil)
 	v.albumBtn.Hide()
 	v.fileInfoLbl = widget.NewLabel("")
+	v.lyrics = newLyricsPanel()
 
 	// Layout
-	actionBtns := container.NewHBox(v.playBtn, v.likeBtn, v.downloadBtn)
+	actionBtns := container.NewHBox(v.playBtn, v.likeBtn, v.downloadBtn, v.artworkBtn)
 
-	coverContainer := container.NewGridWrap(fyne.NewSize(300, 300), v.cover)
+	coverContainer := container.NewGridWrap(songCoverSize, v.cover)
 
 	infoContainer := container.NewVBox(
 		container.NewHBox(v.backBtn),
@@ -104,7 +123,8 @@ func (v *SongDetailView) build() {
 	)
 
 	// Create the split container and set offset
-	v.splitContainer = container.NewHSplit(coverContainer, infoContainer)
+	v.splitContainer = container.NewHSplit(coverContainer,
+		container.NewBorder(infoContainer, nil, nil, nil, v.lyrics.root))
 	v.splitContainer.Offset = 0.4 // 40% for cover, 60% for info
 
 	// Wrap in a regular container
@@ -133,7 +153,11 @@ func (v *SongDetailView) ShowSong(s *types.Song) {
 			if author == nil {
 				continue
 			}
-			btn := widget.NewButton(author.Name, func(slug string) func() {
+			label := author.Name
+			if role := author.CreditRole(); role != types.AuthorRoleMain {
+				label = fmt.Sprintf("%s (%s)", author.Name, role)
+			}
+			btn := widget.NewButton(label, func(slug string) func() {
 				return func() {
 					if v.onOpenAuthor != nil {
 						v.onOpenAuthor(slug)
@@ -194,26 +218,52 @@ func (v *SongDetailView) ShowSong(s *types.Song) {
 	// Like button
 	v.updateLikeButton()
 
-	// Cover image
-	if v.imgSvc != nil {
-		url := ""
-		if s.ImageCropped != nil && *s.ImageCropped != "" {
-			url = *s.ImageCropped
-		} else if s.Image != nil && *s.Image != "" {
-			url = *s.Image
-		}
+	v.loadCover()
+	v.lyrics.load(s)
 
-		if url != "" {
-			v.imgSvc.GetImageWithSize(url, fyne.NewSize(300, 300), func(res fyne.Resource, err error) {
-				if err == nil && res != nil {
-					v.cover.Resource = res
-					v.cover.Refresh()
-				}
-			})
-		}
+	v.root.Refresh()
+}
+
+// SetLyricsService sets where the lyrics of shown songs come from.
+func (v *SongDetailView) SetLyricsService(svc *services.LyricsService) {
+	v.lyrics.svc = svc
+}
+
+// SetPlaybackPosition moves the lyrics along while the shown song plays.
+func (v *SongDetailView) SetPlaybackPosition(song *types.Song, pos time.Duration) {
+	if v.song == nil || song == nil || song.Slug != v.song.Slug {
+		v.lyrics.mark(-1)
+		return
 	}
+	v.lyrics.follow(pos)
+}
 
-	v.root.Refresh()
+func (v *SongDetailView) SetParentWindow(window fyne.Window) {
+	v.parentWindow = window
+}
+
+// loadCover shows the song's cover, the user's own when they picked one.
+func (v *SongDetailView) loadCover() {
+	if v.imgSvc == nil || v.song == nil {
+		return
+	}
+	if v.coverCancel != nil {
+		v.coverCancel()
+	}
+	url := v.imgSvc.SongCoverURL(v.song, songCoverSize)
+	if url == "" {
+		v.cover.Resource = theme.MediaMusicIcon()
+		v.cover.Refresh()
+		return
+	}
+	var ctx context.Context
+	ctx, v.coverCancel = context.WithCancel(context.Background())
+	v.imgSvc.GetImageWithSize(ctx, url, songCoverSize, func(res fyne.Resource, err error) {
+		if err == nil && res != nil {
+			v.cover.Resource = res
+			v.cover.Refresh()
+		}
+	})
 }
 
 func (v *SongDetailView) updateLikeButton() {
@@ -221,13 +271,13 @@ func (v *SongDetailView) updateLikeButton() {
 		return
 	}
 
-	if v.song.Liked != nil && *v.song.Liked {
+	liked := v.song.Liked != nil && *v.song.Liked
+	v.likeBtn.SetIcon(themes.LikeIcon(liked))
+	if liked {
 		v.likeBtn.SetText("Unlike")
-		v.likeBtn.SetIcon(theme.ConfirmIcon())
 		v.likeBtn.Importance = widget.MediumImportance
 	} else {
 		v.likeBtn.SetText("Like")
-		v.likeBtn.SetIcon(theme.VisibilityOffIcon())
 		v.likeBtn.Importance = widget.LowImportance
 	}
 	v.likeBtn.Refresh()
@@ -12,16 +12,40 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/Alexander-D-Karpov/amp/internal/api"
 	"github.com/Alexander-D-Karpov/amp/internal/handlers"
 	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
 	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
+// songSort is a sort the songs view offers. The API sorts by api when it
+// can; otherwise order is set and the stored library is sorted instead, so
+// the sort covers every song rather than the pages loaded so far.
+type songSort struct {
+	label string
+	api   api.SortOption
+	order storage.SongOrder
+}
+
+var songSorts = []songSort{
+	{label: "Date Added", api: api.SortDefault},
+	{label: "Name A-Z", order: storage.SongOrderName},
+	{label: "Name Z-A", order: storage.SongOrderNameReverse},
+	{label: "Artist A-Z", order: storage.SongOrderArtist},
+	{label: "Shortest", order: storage.SongOrderShortest},
+	{label: "Most Played", api: api.SortPlayed},
+	{label: "Most Liked", api: api.SortLikes},
+	{label: "Least Liked", api: api.SortLikesReversed},
+	{label: "Longest", api: api.SortLength},
+	{label: "Newest", api: api.SortUploaded},
+}
+
 type SongsView struct {
 	musicService    *services.MusicService
 	imageService    *services.ImageService
@@ -43,10 +67,13 @@ type SongsView struct {
 	filterSelect  *widget.Select
 	loader        *widget.ProgressBarInfinite
 	statusLabel   *widget.Label
+	footer        *pageFooter
+	errorBanner   *components.ErrorBanner
 
 	contextMenu    *components.ContextMenu
 	lastTappedSong *types.Song
 	parentWindow   fyne.Window
+	undoBar        *components.UndoBar
 
 	mu            sync.RWMutex
 	songs         []*types.Song
@@ -59,16 +86,22 @@ type SongsView struct {
 	hasMore       bool
 	loading       bool
 	loadingMore   bool
+	loadingAll    bool
 	lastSearch    string
 	debug         bool
 	searchCache   map[string][]*types.Song
-	currentSort   api.SortOption
+	searchCancel  context.CancelFunc
+	currentSort   songSort
+	typeAhead     typeAhead
 
 	onDownload       func(*types.Song)
 	onAddPlaylist    func(*types.Song)
 	openAlbumBySlug  func(string)
 	openAuthorBySlug func(string)
 	openSongBySlug   func(string)
+	onPreview        func(*types.Song)
+	onStopPreview    func()
+	onCue            func(*types.Song)
 }
 
 func NewSongsView(musicService *services.MusicService, imageService *services.ImageService, handlers *handlers.UIHandlers) *SongsView {
@@ -84,7 +117,7 @@ func NewSongsView(musicService *services.MusicService, imageService *services.Im
 		allSongs:      make([]*types.Song, 0),
 		debug:         true,
 		searchCache:   make(map[string][]*types.Song),
-		currentSort:   api.SortDefault,
+		currentSort:   songSorts[0],
 	}
 
 	sv.setupWidgets()
@@ -105,10 +138,12 @@ func (sv *SongsView) setupWidgets() {
 	sv.refreshBtn = widget.NewButtonWithIcon("", theme.ViewRefreshIcon(), sv.Refresh)
 	sv.viewToggleBtn = widget.NewButtonWithIcon("", theme.GridIcon(), sv.toggleView)
 
-	sv.sortSelect = widget.NewSelect([]string{
-		"Date Added", "Name A-Z", "Name Z-A", "Artist A-Z", "Duration", "Most Played", "Most Liked", "Least Liked", "Longest", "Newest",
-	}, sv.onSortChanged)
-	sv.sortSelect.SetSelected("Date Added")
+	labels := make([]string, len(songSorts))
+	for i, option := range songSorts {
+		labels[i] = option.label
+	}
+	sv.sortSelect = widget.NewSelect(labels, sv.onSortChanged)
+	sv.sortSelect.SetSelected(songSorts[0].label)
 
 	sv.filterSelect = widget.NewSelect([]string{
 		"All Songs", "Downloaded", "Liked",
@@ -118,6 +153,7 @@ func (sv *SongsView) setupWidgets() {
 	sv.mediaGrid = components.NewMediaGrid(fyne.NewSize(200, 260), sv.imageService)
 	sv.mediaGrid.SetItemTapCallback(sv.onGridItemTapped)
 	sv.mediaGrid.SetItemSecondaryTapCallback(sv.onGridItemSecondaryTapped)
+	sv.mediaGrid.SetItemHoverCallbacks(sv.onGridItemHeld, sv.onGridItemReleased)
 
 	sv.songList = components.NewSongList()
 	sv.songList.OnPlay(func(s *types.Song, queue []*types.Song) {
@@ -150,6 +186,8 @@ func (sv *SongsView) setupWidgets() {
 	sv.loader = widget.NewProgressBarInfinite()
 	sv.loader.Hide()
 	sv.statusLabel = widget.NewLabel("Loading songs...")
+	sv.footer = newPageFooter("songs", sv.loadAllSongs)
+	sv.errorBanner = components.NewErrorBanner()
 }
 
 func (sv *SongsView) setupLayout() {
@@ -162,7 +200,7 @@ func (sv *SongsView) setupLayout() {
 		widget.NewLabel("Sort:"), sv.sortSelect,
 		widget.NewLabel("Filter:"), sv.filterSelect,
 	)
-	header := container.NewVBox(searchBar, controls, sv.statusLabel)
+	header := container.NewVBox(sv.errorBanner.Container(), searchBar, controls, sv.statusLabel)
 
 	sv.gridScroll = container.NewScroll(sv.mediaGrid)
 	sv.gridScroll.OnScrolled = sv.onScrolled
@@ -173,26 +211,50 @@ func (sv *SongsView) setupLayout() {
 	sv.centerStack = container.NewStack(sv.gridScroll, sv.listScroll)
 	sv.listScroll.Hide()
 
-	sv.container = container.NewBorder(header, sv.loader, nil, nil, sv.centerStack)
+	sv.container = container.NewBorder(header, container.NewVBox(sv.footer.root, sv.loader), nil, nil, sv.centerStack)
 }
 
 func (sv *SongsView) onScrolled(pos fyne.Position) {
-	if sv.loadingMore || !sv.hasMore {
+	sv.mu.RLock()
+	idle := !sv.loadingMore && !sv.loadingAll && sv.hasMore
+	sv.mu.RUnlock()
+	if !idle {
 		return
 	}
-	var scrollSize fyne.Size
-	if sv.isGridView {
-		scrollSize = sv.gridScroll.Size()
-	} else {
-		scrollSize = sv.listScroll.Size()
+	scroll := sv.gridScroll
+	if !sv.isGridView {
+		scroll = sv.listScroll
 	}
-	contentSize := sv.mediaGrid.MinSize()
-
-	if pos.Y >= contentSize.Height-scrollSize.Height-100 {
+	if nearBottom(scroll, pos) {
 		if sv.debug {
 			log.Printf("[SONGS_VIEW] Near bottom, loading more songs")
 		}
-		go sv.loadMoreSongs()
+		go sv.loadNextPage()
+	}
+}
+
+// TypeToJump moves to the first loaded song whose name starts with the
+// letters typed so far.
+func (sv *SongsView) TypeToJump(r rune) {
+	if sv.isGridView {
+		sv.typeAhead.jumpInGrid(r, sv.mediaGrid, sv.gridScroll)
+		return
+	}
+
+	sv.mu.RLock()
+	names := make([]string, len(sv.filteredSongs))
+	for i, song := range sv.filteredSongs {
+		names[i] = song.Name
+	}
+	sv.mu.RUnlock()
+
+	index := sv.typeAhead.jump(r, names)
+	if index < 0 {
+		return
+	}
+	sv.songList.Highlight(index)
+	if pos, ok := sv.songList.RowPosition(index); ok {
+		scrollToItem(sv.listScroll, pos)
 	}
 }
 
@@ -217,10 +279,34 @@ func (sv *SongsView) onGridItemTapped(index int) {
 	}
 }
 
+// onGridItemHeld previews the song the pointer rests on.
+func (sv *SongsView) onGridItemHeld(index int) {
+	sv.mu.RLock()
+	if index >= len(sv.filteredSongs) {
+		sv.mu.RUnlock()
+		return
+	}
+	song := sv.filteredSongs[index]
+	sv.mu.RUnlock()
+
+	if sv.onPreview != nil {
+		sv.onPreview(song)
+	}
+}
+
+func (sv *SongsView) onGridItemReleased(int) {
+	if sv.onStopPreview != nil {
+		sv.onStopPreview()
+	}
+}
+
 func (sv *SongsView) recordSongPlay(song *types.Song) {
 	if song == nil {
 		return
 	}
+	if sv.handlers != nil && sv.handlers.PlaySync() != nil && sv.handlers.PlaySync().Private() {
+		return
+	}
 
 	go func() {
 		ctx := context.Background()
@@ -230,10 +316,6 @@ func (sv *SongsView) recordSongPlay(song *types.Song) {
 			log.Printf("[SONGS_VIEW] Failed to update play count for song %s: %v", song.Name, err)
 		}
 
-		if err := sv.musicService.GetStorage().AddPlayHistory(ctx, song.Slug, nil); err != nil {
-			log.Printf("[SONGS_VIEW] Failed to add play history for %s: %v", song.Slug, err)
-		}
-
 		if sv.debug {
 			log.Printf("[SONGS_VIEW] Recorded play for song: %s (total plays: %d)", song.Name, song.Played)
 		}
@@ -247,28 +329,53 @@ func (sv *SongsView) handlePlaySong(song *types.Song) {
 	}
 }
 
+func (sv *SongsView) handlePlayNext(song *types.Song) {
+	if sv.handlers != nil {
+		sv.handlers.HandlePlayNext(song)
+	}
+}
+
+func (sv *SongsView) handleAddToQueue(song *types.Song) {
+	if sv.handlers != nil {
+		sv.handlers.HandleAddToQueue(song)
+	}
+}
+
 func (sv *SongsView) handleLikeSong(song *types.Song) {
 	if song == nil {
 		return
 	}
 
 	liked := song.Liked == nil || !*song.Liked
+	previous := song.Liked
 	song.Liked = &liked
 
 	if sv.debug {
 		log.Printf("[SONGS_VIEW] Toggled like for song: %s (liked: %v)", song.Name, liked)
 	}
 
-	go func() {
-		ctx := context.Background()
-		if err := sv.musicService.GetStorage().SaveSong(ctx, song); err != nil {
-			log.Printf("[SONGS_VIEW] Failed to save like status: %v", err)
-		}
+	save := func() {
+		go func() {
+			ctx := context.Background()
+			if err := sv.musicService.GetStorage().SaveLike(ctx, song); err != nil {
+				log.Printf("[SONGS_VIEW] Failed to save like status: %v", err)
+			}
 
-		fyne.Do(func() {
-			sv.updateGridView()
-		})
-	}()
+			fyne.Do(func() {
+				sv.updateGridView()
+			})
+		}()
+	}
+	if liked || sv.undoBar == nil {
+		save()
+		return
+	}
+
+	sv.updateGridView()
+	sv.undoBar.Defer("Removed "+song.Name+" from liked songs", save, func() {
+		song.Liked = previous
+		sv.updateGridView()
+	})
 }
 
 func (sv *SongsView) handleDownloadSong(song *types.Song) {
@@ -297,6 +404,65 @@ func (sv *SongsView) handleDownloadSong(song *types.Song) {
 	}
 }
 
+// handleRemoveSong moves a song to the trash and offers to undo it.
+func (sv *SongsView) handleRemoveSong(song *types.Song) {
+	if song == nil {
+		return
+	}
+
+	go func() {
+		err := sv.musicService.DeleteSong(context.Background(), song)
+		fyne.Do(func() {
+			if err != nil {
+				log.Printf("[SONGS_VIEW] Failed to remove %s: %v", song.Name, err)
+				if sv.parentWindow != nil {
+					dialog.ShowError(fmt.Errorf("could not remove song: %w", err), sv.parentWindow)
+				}
+				return
+			}
+
+			sv.mu.Lock()
+			sv.songs = withoutSong(sv.songs, song.Slug)
+			sv.allSongs = withoutSong(sv.allSongs, song.Slug)
+			sv.searchCache = make(map[string][]*types.Song)
+			sv.applySortAndFilter()
+			sv.mu.Unlock()
+			sv.updateGridView()
+
+			if sv.undoBar != nil {
+				sv.undoBar.Show(fmt.Sprintf("Moved \"%s\" to the trash", song.Name), func() {
+					sv.restoreSong(song)
+				})
+			}
+		})
+	}()
+}
+
+func (sv *SongsView) restoreSong(song *types.Song) {
+	go func() {
+		if err := sv.musicService.RestoreFromTrash(context.Background(), types.TrashSong, song.Slug); err != nil {
+			log.Printf("[SONGS_VIEW] Failed to restore %s: %v", song.Name, err)
+			return
+		}
+		fyne.Do(sv.Refresh)
+	}()
+}
+
+func withoutSong(songs []*types.Song, slug string) []*types.Song {
+	kept := make([]*types.Song, 0, len(songs))
+	for _, song := range songs {
+		if song != nil && song.Slug != slug {
+			kept = append(kept, song)
+		}
+	}
+	return kept
+}
+
+// SetUndoBar sets where removals offer an undo.
+func (sv *SongsView) SetUndoBar(bar *components.UndoBar) {
+	sv.undoBar = bar
+}
+
 func (sv *SongsView) handleAddToPlaylist(song *types.Song) {
 	if song == nil {
 		return
@@ -349,6 +515,7 @@ func (sv *SongsView) updateGridView() {
 				sv.statusLabel.SetText(fmt.Sprintf("Showing %d songs", len(songs)))
 			}
 		}
+		sv.updateFooter()
 	})
 
 	if sv.isGridView {
@@ -378,6 +545,11 @@ func (sv *SongsView) onSearchChanged(query string) {
 
 func (sv *SongsView) performSearch(query string) {
 	sv.mu.Lock()
+	if sv.searchCancel != nil {
+		sv.searchCancel()
+		sv.searchCancel = nil
+		sv.loading = false
+	}
 	sv.lastSearch = query
 	sv.currentPage = 1
 	sv.hasMore = true
@@ -394,7 +566,7 @@ func (sv *SongsView) performSearch(query string) {
 		return
 	}
 
-	cacheKey := fmt.Sprintf("%s_%s", query, sv.currentSort)
+	cacheKey := fmt.Sprintf("%s_%s", query, sv.currentSort.label)
 	if cached, exists := sv.searchCache[cacheKey]; exists {
 		sv.mu.Lock()
 		sv.songs = cached
@@ -410,13 +582,15 @@ func (sv *SongsView) performSearch(query string) {
 	sv.loadSongsWithSearch(query)
 }
 
+// loadSongsWithSearch shows matching songs from the local library at once
+// and merges in the server's matches as they arrive. A newer search cancels
+// this one.
 func (sv *SongsView) loadSongsWithSearch(query string) {
 	sv.mu.Lock()
-	if sv.loading {
-		sv.mu.Unlock()
-		return
-	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sv.searchCancel = cancel
 	sv.loading = true
+	sortOption := sv.currentSort
 	sv.mu.Unlock()
 
 	fyne.Do(func() {
@@ -430,6 +604,9 @@ func (sv *SongsView) loadSongsWithSearch(query string) {
 
 	go func() {
 		defer func() {
+			if ctx.Err() != nil {
+				return
+			}
 			sv.mu.Lock()
 			sv.loading = false
 			sv.mu.Unlock()
@@ -440,40 +617,42 @@ func (sv *SongsView) loadSongsWithSearch(query string) {
 			})
 		}()
 
-		ctx := context.Background()
-
 		if sv.debug {
-			log.Printf("[SONGS_VIEW] Loading songs with search - query: '%s', sort: '%s'", query, sv.currentSort)
+			log.Printf("[SONGS_VIEW] Loading songs with search - query: '%s', sort: '%s'", query, sortOption.label)
 		}
 
-		songs, hasMore, err := sv.musicService.GetSongsWithSort(ctx, 1, query, sv.currentSort)
-		if err != nil {
+		err := sv.musicService.SearchProgressive(ctx, query, func(results *types.SearchResponse, done bool) {
+			sv.mu.Lock()
+			if ctx.Err() != nil || sv.lastSearch != query {
+				sv.mu.Unlock()
+				return
+			}
+			sv.songs = results.Songs
+			sv.allSongs = append([]*types.Song(nil), results.Songs...)
+			sv.filteredSongs = append([]*types.Song(nil), results.Songs...)
+			sv.hasMore = false
+			if done {
+				sv.searchCache[fmt.Sprintf("%s_%s", query, sortOption.label)] = results.Songs
+			}
+			sv.applySortAndFilter()
+			sv.mu.Unlock()
+
+			if sv.debug {
+				log.Printf("[SONGS_VIEW] Search has %d songs (done: %v)", len(results.Songs), done)
+			}
+			fyne.Do(func() { sv.updateGridView() })
+		})
+		if err != nil && ctx.Err() == nil {
 			if sv.debug {
 				log.Printf("[SONGS_VIEW] Error searching songs: %v", err)
 			}
 			fyne.Do(func() {
 				if sv.statusLabel != nil {
-					sv.statusLabel.SetText(fmt.Sprintf("Search error: %v", err))
+					sv.statusLabel.SetText("Search failed")
 				}
+				sv.errorBanner.Show("Couldn't search songs", err, func() { sv.performSearch(query) })
 			})
-			return
 		}
-
-		if sv.debug {
-			log.Printf("[SONGS_VIEW] Search returned %d songs", len(songs))
-		}
-
-		sv.mu.Lock()
-		sv.songs = songs
-		sv.allSongs = append([]*types.Song(nil), songs...)
-		sv.filteredSongs = append([]*types.Song(nil), songs...)
-		sv.hasMore = hasMore
-		cacheKey := fmt.Sprintf("%s_%s", query, sv.currentSort)
-		sv.searchCache[cacheKey] = songs
-		sv.applySortAndFilter()
-		sv.mu.Unlock()
-
-		fyne.Do(func() { sv.updateGridView() })
 	}()
 }
 
@@ -500,21 +679,13 @@ func (sv *SongsView) onSortChanged(option string) {
 	}
 }
 
-func (sv *SongsView) mapSortOption(option string) api.SortOption {
-	switch option {
-	case "Most Played":
-		return api.SortPlayed
-	case "Most Liked":
-		return api.SortLikes
-	case "Least Liked":
-		return api.SortLikesReversed
-	case "Longest":
-		return api.SortLength
-	case "Newest":
-		return api.SortUploaded
-	default:
-		return api.SortDefault
+func (sv *SongsView) mapSortOption(option string) songSort {
+	for _, candidate := range songSorts {
+		if candidate.label == option {
+			return candidate
+		}
 	}
+	return songSorts[0]
 }
 
 func (sv *SongsView) onFilterChanged(filter string) {
@@ -558,18 +729,19 @@ func (sv *SongsView) loadSongs() {
 		ctx := context.Background()
 
 		if sv.debug {
-			log.Printf("[SONGS_VIEW] Loading songs - page: %d, query: '%s', sort: '%s'", page, query, sortOption)
+			log.Printf("[SONGS_VIEW] Loading songs - page: %d, query: '%s', sort: '%s'", page, query, sortOption.label)
 		}
 
-		songs, hasMore, err := sv.musicService.GetSongsWithSort(ctx, page, query, sortOption)
+		songs, hasMore, err := sv.fetchSongs(ctx, page, query, sortOption)
 		if err != nil {
 			if sv.debug {
 				log.Printf("[SONGS_VIEW] Error loading songs: %v", err)
 			}
 			fyne.Do(func() {
 				if sv.statusLabel != nil {
-					sv.statusLabel.SetText(fmt.Sprintf("Error: %v", err))
+					sv.statusLabel.SetText("No songs loaded")
 				}
+				sv.errorBanner.Show("Couldn't load songs", err, sv.loadSongs)
 			})
 			return
 		}
@@ -577,6 +749,7 @@ func (sv *SongsView) loadSongs() {
 		if sv.debug {
 			log.Printf("[SONGS_VIEW] Loaded %d songs from service", len(songs))
 		}
+		fyne.Do(sv.errorBanner.Hide)
 
 		sv.mu.Lock()
 		if page == 1 {
@@ -594,15 +767,20 @@ func (sv *SongsView) loadSongs() {
 	}()
 }
 
-func (sv *SongsView) loadMoreSongs() {
+// loadNextPage appends the next page of songs. It reports whether more
+// pages remain, and busy while another load is under way.
+func (sv *SongsView) loadNextPage() (more, busy bool) {
 	sv.mu.Lock()
-	if sv.loadingMore || !sv.hasMore || sv.loading {
+	if !sv.hasMore {
 		sv.mu.Unlock()
-		return
+		return false, false
+	}
+	if sv.loadingMore || sv.loading {
+		sv.mu.Unlock()
+		return true, true
 	}
 	sv.loadingMore = true
-	sv.currentPage++
-	page := sv.currentPage
+	page := sv.currentPage + 1
 	query := sv.lastSearch
 	sortOption := sv.currentSort
 	sv.mu.Unlock()
@@ -611,37 +789,77 @@ func (sv *SongsView) loadMoreSongs() {
 		log.Printf("[SONGS_VIEW] Loading more songs - page: %d", page)
 	}
 
-	go func() {
-		defer func() {
-			sv.mu.Lock()
-			sv.loadingMore = false
-			sv.mu.Unlock()
-		}()
-
-		ctx := context.Background()
-		songs, hasMore, err := sv.musicService.GetSongsWithSort(ctx, page, query, sortOption)
-		if err != nil {
-			if sv.debug {
-				log.Printf("[SONGS_VIEW] Error loading more songs: %v", err)
-			}
-			return
-		}
+	songs, hasMore, err := sv.fetchSongs(context.Background(), page, query, sortOption)
 
+	sv.mu.Lock()
+	sv.loadingMore = false
+	if err != nil {
+		sv.mu.Unlock()
 		if sv.debug {
-			log.Printf("[SONGS_VIEW] Loaded %d more songs", len(songs))
+			log.Printf("[SONGS_VIEW] Error loading more songs: %v", err)
 		}
+		fyne.Do(func() {
+			sv.errorBanner.Show("Couldn't load more songs", err, func() { go sv.loadNextPage() })
+		})
+		return false, false
+	}
+	// The list was reloaded meanwhile, so this page belongs to another.
+	if sv.currentPage != page-1 || sv.lastSearch != query || sv.currentSort != sortOption {
+		sv.mu.Unlock()
+		return false, false
+	}
 
-		sv.mu.Lock()
-		sv.songs = append(sv.songs, songs...)
-		sv.allSongs = append(sv.allSongs, songs...)
-		sv.hasMore = hasMore
-		sv.applySortAndFilter()
+	if sv.debug {
+		log.Printf("[SONGS_VIEW] Loaded %d more songs", len(songs))
+	}
+	sv.currentPage = page
+	sv.songs = append(sv.songs, songs...)
+	sv.allSongs = append(sv.allSongs, songs...)
+	sv.hasMore = hasMore
+	sv.applySortAndFilter()
+	sv.mu.Unlock()
+
+	fyne.Do(func() { sv.updateGridView() })
+	return hasMore, false
+}
+
+// loadAllSongs loads every remaining page, for those who would rather have
+// the whole library at hand than scroll for it.
+func (sv *SongsView) loadAllSongs() {
+	sv.mu.Lock()
+	if sv.loadingAll {
 		sv.mu.Unlock()
+		return
+	}
+	sv.loadingAll = true
+	sv.mu.Unlock()
+	sv.updateFooter()
 
-		fyne.Do(func() { sv.updateGridView() })
+	go func() {
+		loadRemaining(sv.loadNextPage)
+		sv.mu.Lock()
+		sv.loadingAll = false
+		sv.mu.Unlock()
+		fyne.Do(sv.updateFooter)
 	}()
 }
 
+func (sv *SongsView) updateFooter() {
+	sv.mu.RLock()
+	shown, hasMore, loadingAll, searching := len(sv.filteredSongs), sv.hasMore, sv.loadingAll, sv.lastSearch != ""
+	sv.mu.RUnlock()
+	sv.footer.update(shown, hasMore, loadingAll, searching)
+}
+
+// fetchSongs loads a page of songs sorted by option: by the API when it has
+// the sort, and from the stored library otherwise.
+func (sv *SongsView) fetchSongs(ctx context.Context, page int, query string, option songSort) ([]*types.Song, bool, error) {
+	if option.order != "" && query == "" {
+		return sv.musicService.GetSongsOrdered(ctx, page, option.order)
+	}
+	return sv.musicService.GetSongsWithSort(ctx, page, query, option.api)
+}
+
 func (sv *SongsView) applySortAndFilter() {
 	filtered := make([]*types.Song, 0, len(sv.songs))
 	var filter string
@@ -669,30 +887,25 @@ func (sv *SongsView) applySortAndFilter() {
 		}
 	}
 
-	var sortOpt string
-	if sv.sortSelect != nil {
-		sortOpt = sv.sortSelect.Selected
-	}
-
-	if sv.currentSort == api.SortDefault {
-		sort.Slice(filtered, func(i, j int) bool {
+	// Pages come sorted; search results come all at once, so they are
+	// sorted here the way the storage would sort them.
+	if sv.lastSearch != "" && sv.currentSort.order != "" {
+		order := sv.currentSort.order
+		sort.SliceStable(filtered, func(i, j int) bool {
 			s1, s2 := filtered[i], filtered[j]
-			if s1 == nil || s2 == nil {
-				return false
-			}
-			switch sortOpt {
-			case "Name A-Z":
+			switch order {
+			case storage.SongOrderName:
 				return strings.ToLower(s1.Name) < strings.ToLower(s2.Name)
-			case "Name Z-A":
+			case storage.SongOrderNameReverse:
 				return strings.ToLower(s1.Name) > strings.ToLower(s2.Name)
-			case "Artist A-Z":
-				return getFirstAuthor(s1) < getFirstAuthor(s2)
-			case "Duration":
-				return s1.Length > s2.Length
-			case "Play Count":
-				return s1.Played > s2.Played
-			case "Date Added", "":
-				return s1.CreatedAt.After(s2.CreatedAt)
+			case storage.SongOrderArtist:
+				a1, a2 := getFirstAuthor(s1), getFirstAuthor(s2)
+				if (a1 == "") != (a2 == "") {
+					return a2 == ""
+				}
+				return a1 < a2
+			case storage.SongOrderShortest:
+				return s1.Length < s2.Length
 			}
 			return false
 		})
@@ -757,6 +970,19 @@ func (sv *SongsView) SetOpenSongBySlug(cb func(string)) {
 	}
 }
 
+// SetPreviewHandlers sets how a song card the pointer rests on is
+// previewed, and how the preview stops when the pointer leaves.
+func (sv *SongsView) SetPreviewHandlers(onPreview func(*types.Song), onStop func()) {
+	sv.onPreview = onPreview
+	sv.onStopPreview = onStop
+}
+
+// SetCueHandler sets how a song is pre-listened to, or hides pre-listening
+// for nil.
+func (sv *SongsView) SetCueHandler(onCue func(*types.Song)) {
+	sv.onCue = onCue
+}
+
 func (sv *SongsView) Container() *fyne.Container {
 	return sv.container
 }
@@ -766,19 +992,10 @@ func (sv *SongsView) SetDownloadHandler(handler func(*types.Song)) {
 }
 
 func getArtistNames(authors []*types.Author) string {
-	if len(authors) == 0 {
-		return "Unknown Artist"
-	}
-	names := make([]string, 0, len(authors))
-	for _, author := range authors {
-		if author != nil && author.Name != "" {
-			names = append(names, author.Name)
-		}
-	}
-	if len(names) == 0 {
-		return "Unknown Artist"
+	if label := types.CreditsLabel(authors); label != "" {
+		return label
 	}
-	return strings.Join(names, ", ")
+	return "Unknown Artist"
 }
 
 func getFirstAuthor(s *types.Song) string {
@@ -825,6 +1042,9 @@ func (sv *SongsView) showContextMenu(song *types.Song, pos fyne.Position) {
 		sv.handleDownloadSong,
 		sv.handleAddToPlaylist,
 	)
+	sv.contextMenu.SetQueueCallbacks(sv.handlePlayNext, sv.handleAddToQueue)
+	sv.contextMenu.SetOnRemove(sv.handleRemoveSong)
+	sv.contextMenu.SetOnCue(sv.onCue)
 
 	windowSize := sv.parentWindow.Canvas().Size()
 	if pos.X > windowSize.Width-200 {
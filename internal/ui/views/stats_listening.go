@@ -0,0 +1,286 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+const (
+	// topListSize is how many songs, albums and artists each top list shows.
+	topListSize = 10
+	// chartDays and chartWeeks are how far back the listening charts go.
+	chartDays  = 30
+	chartWeeks = 12
+
+	rankByPlaysLabel = "By Plays"
+	rankByTimeLabel  = "By Time"
+)
+
+// statsPeriod is a stretch of time the top lists cover, ending today. All
+// of the history is covered when days is 0.
+type statsPeriod struct {
+	label string
+	days  int
+}
+
+var statsPeriods = []statsPeriod{
+	{label: "Last 7 Days", days: 7},
+	{label: "Last 30 Days", days: 30},
+	{label: "Last Year", days: 365},
+	{label: "All Time"},
+}
+
+// since returns the start of the first day of the period.
+func (p statsPeriod) since(now time.Time) time.Time {
+	if p.days == 0 {
+		return time.Time{}
+	}
+	return startOfDay(now).AddDate(0, 0, 1-p.days)
+}
+
+func (p statsPeriod) describe() string {
+	if p.days == 0 {
+		return "in total"
+	}
+	return fmt.Sprintf("in the last %d days", p.days)
+}
+
+func (sv *StatsView) onPeriodChanged(label string) {
+	for _, period := range statsPeriods {
+		if period.label == label && period != sv.period {
+			sv.period = period
+			go sv.loadTopLists(sv.period, sv.rank)
+		}
+	}
+}
+
+func (sv *StatsView) onRankChanged(label string) {
+	rank := storage.RankByPlays
+	if label == rankByTimeLabel {
+		rank = storage.RankByTime
+	}
+	if rank != sv.rank {
+		sv.rank = rank
+		go sv.loadTopLists(sv.period, sv.rank)
+	}
+}
+
+// loadListening fills the streaks and charts from the whole play history.
+func (sv *StatsView) loadListening() {
+	days, err := sv.musicService.GetStorage().GetListeningDays(context.Background(), time.Time{})
+	if err != nil {
+		log.Printf("[STATS_VIEW] Failed to load listening days: %v", err)
+		return
+	}
+	fyne.Do(func() {
+		sv.updateListening(days, time.Now())
+	})
+}
+
+func (sv *StatsView) updateListening(days []*types.ListeningDay, now time.Time) {
+	totalSeconds := 0
+	for _, day := range days {
+		totalSeconds += day.Seconds
+	}
+	sv.timeListenedCard.SetContent(statContent(formatListened(totalSeconds), "total listening time"))
+
+	current, longest := listeningStreaks(days, now)
+	sv.currentStreakCard.SetContent(statContent(formatDays(current), "in a row up to today"))
+	sv.longestStreakCard.SetContent(statContent(formatDays(longest), "in a row at most"))
+	sv.daysListenedCard.SetContent(statContent(fmt.Sprintf("%d", len(days)), "days with music"))
+
+	bars, seconds := dailyBars(days, now)
+	sv.dailyChart.SetBars(bars)
+	sv.dailyLabel.SetText(fmt.Sprintf("%s in the last %d days", formatListened(seconds), chartDays))
+
+	bars, seconds = weeklyBars(days, now)
+	sv.weeklyChart.SetBars(bars)
+	sv.weeklyLabel.SetText(fmt.Sprintf("%s a week on average over %d weeks", formatListened(seconds/chartWeeks), chartWeeks))
+}
+
+// loadTopLists fills the top songs, albums and artists of the chosen period
+// and ranking.
+func (sv *StatsView) loadTopLists(period statsPeriod, rank storage.ListeningRank) {
+	ctx := context.Background()
+	db := sv.musicService.GetStorage()
+	since := period.since(time.Now())
+
+	songs, err := db.GetTopSongs(ctx, since, rank, topListSize)
+	if err != nil {
+		log.Printf("[STATS_VIEW] Failed to load top songs: %v", err)
+		return
+	}
+	albums, err := db.GetTopAlbums(ctx, since, rank, topListSize)
+	if err != nil {
+		log.Printf("[STATS_VIEW] Failed to load top albums: %v", err)
+		return
+	}
+	artists, err := db.GetTopAuthors(ctx, since, rank, topListSize)
+	if err != nil {
+		log.Printf("[STATS_VIEW] Failed to load top artists: %v", err)
+		return
+	}
+	days, err := db.GetListeningDays(ctx, since)
+	if err != nil {
+		log.Printf("[STATS_VIEW] Failed to load listening days: %v", err)
+		return
+	}
+
+	fyne.Do(func() {
+		// A newer choice loads its own lists.
+		if period != sv.period || rank != sv.rank {
+			return
+		}
+		plays, seconds := 0, 0
+		for _, day := range days {
+			plays += day.Plays
+			seconds += day.Seconds
+		}
+		sv.periodLabel.SetText(fmt.Sprintf("%s, %s %s", formatPlays(plays), formatListened(seconds), period.describe()))
+		updateTopList(sv.topSongsBox, songs)
+		updateTopList(sv.topAlbumsBox, albums)
+		updateTopList(sv.topArtistsBox, artists)
+	})
+}
+
+func updateTopList(box *fyne.Container, totals []*types.ListeningTotal) {
+	box.RemoveAll()
+	if len(totals) == 0 {
+		box.Add(widget.NewLabel("Nothing played in this period"))
+		return
+	}
+	for i, total := range totals {
+		name := widget.NewLabel(total.Name)
+		name.Truncation = fyne.TextTruncateEllipsis
+		rank := widget.NewLabelWithStyle(fmt.Sprintf("%d.", i+1), fyne.TextAlignTrailing, fyne.TextStyle{Bold: true})
+		detail := widget.NewLabel(fmt.Sprintf("%s · %s", formatPlays(total.Plays), formatListened(total.Seconds)))
+		detail.Importance = widget.LowImportance
+		box.Add(container.NewBorder(nil, nil, rank, detail, name))
+	}
+}
+
+// statContent is the body of an overview card: a value over what it counts.
+func statContent(value, caption string) fyne.CanvasObject {
+	return container.NewVBox(
+		widget.NewLabelWithStyle(value, fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
+		widget.NewLabelWithStyle(caption, fyne.TextAlignCenter, fyne.TextStyle{}),
+	)
+}
+
+// listeningStreaks returns how many days in a row had listening up to
+// today, or up to yesterday while nothing has played today yet, and the
+// most days in a row that ever did. days must be oldest first.
+func listeningStreaks(days []*types.ListeningDay, now time.Time) (current, longest int) {
+	run := 0
+	var last time.Time
+	for _, day := range days {
+		if !last.IsZero() && day.Day.Equal(last.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		last = day.Day
+		longest = max(longest, run)
+	}
+
+	today := startOfDay(now)
+	if !last.IsZero() && (last.Equal(today) || last.Equal(today.AddDate(0, 0, -1))) {
+		current = run
+	}
+	return current, longest
+}
+
+// dailyBars charts the minutes listened on each of the last chartDays days
+// and returns the seconds listened over them.
+func dailyBars(days []*types.ListeningDay, now time.Time) ([]components.ChartBar, int) {
+	first := startOfDay(now).AddDate(0, 0, 1-chartDays)
+	seconds := make(map[string]int, len(days))
+	for _, day := range days {
+		seconds[day.Day.Format(time.DateOnly)] = day.Seconds
+	}
+
+	bars := make([]components.ChartBar, chartDays)
+	total := 0
+	for i := range bars {
+		day := first.AddDate(0, 0, i)
+		listened := seconds[day.Format(time.DateOnly)]
+		bars[i].Value = float64(listened) / 60
+		total += listened
+		// Label every week back from today.
+		if (chartDays-1-i)%7 == 0 {
+			bars[i].Label = day.Format("Jan 2")
+		}
+	}
+	return bars, total
+}
+
+// weeklyBars charts the minutes listened in each of the last chartWeeks
+// weeks, which start on Monday, and returns the seconds listened over them.
+func weeklyBars(days []*types.ListeningDay, now time.Time) ([]components.ChartBar, int) {
+	first := startOfWeek(now).AddDate(0, 0, -7*(chartWeeks-1))
+
+	bars := make([]components.ChartBar, chartWeeks)
+	for i := range bars {
+		if (chartWeeks-1-i)%4 == 0 {
+			bars[i].Label = first.AddDate(0, 0, 7*i).Format("Jan 2")
+		}
+	}
+
+	total := 0
+	for _, day := range days {
+		if day.Day.Before(first) {
+			continue
+		}
+		// Days are rounded, as a week with a clock change is an hour off.
+		week := int(math.Round(day.Day.Sub(first).Hours()/24)) / 7
+		if week < chartWeeks {
+			bars[week].Value += float64(day.Seconds) / 60
+			total += day.Seconds
+		}
+	}
+	return bars, total
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+func startOfWeek(t time.Time) time.Time {
+	sinceMonday := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -sinceMonday)
+}
+
+// formatListened renders seconds of listening as "3h 12m" or "12m".
+func formatListened(seconds int) string {
+	hours, minutes := seconds/3600, (seconds%3600)/60
+	if hours == 0 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+func formatPlays(plays int) string {
+	if plays == 1 {
+		return "1 play"
+	}
+	return fmt.Sprintf("%d plays", plays)
+}
+
+func formatDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
@@ -3,32 +3,60 @@ package views
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/Alexander-D-Karpov/amp/internal/handlers"
 	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/internal/storage"
+	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
 	"github.com/Alexander-D-Karpov/amp/pkg/types"
 )
 
 type StatsView struct {
 	musicService *services.MusicService
+	handlers     *handlers.UIHandlers
 	container    *fyne.Container
+	parentWindow fyne.Window
 
 	totalSongsCard   *widget.Card
 	totalAlbumsCard  *widget.Card
 	totalArtistsCard *widget.Card
 	timeListenedCard *widget.Card
+	sessionsBox      *fyne.Container
+
+	currentStreakCard *widget.Card
+	longestStreakCard *widget.Card
+	daysListenedCard  *widget.Card
+	dailyChart        *components.BarChart
+	dailyLabel        *widget.Label
+	weeklyChart       *components.BarChart
+	weeklyLabel       *widget.Label
+
+	periodSelect  *widget.Select
+	rankSelect    *widget.Select
+	periodLabel   *widget.Label
+	topSongsBox   *fyne.Container
+	topAlbumsBox  *fyne.Container
+	topArtistsBox *fyne.Container
+	period        statsPeriod
+	rank          storage.ListeningRank
 
 	refreshBtn  *widget.Button
 	compactMode bool
 }
 
-func NewStatsView(musicService *services.MusicService) *StatsView {
+func NewStatsView(musicService *services.MusicService, h *handlers.UIHandlers) *StatsView {
 	sv := &StatsView{
 		musicService: musicService,
+		handlers:     h,
+		period:       statsPeriods[1],
+		rank:         storage.RankByPlays,
 	}
 
 	sv.setupWidgets()
@@ -44,6 +72,28 @@ func (sv *StatsView) setupWidgets() {
 	sv.totalAlbumsCard = widget.NewCard("Total Albums", "", widget.NewLabel("Loading..."))
 	sv.totalArtistsCard = widget.NewCard("Total Artists", "", widget.NewLabel("Loading..."))
 	sv.timeListenedCard = widget.NewCard("Time Listened", "", widget.NewLabel("Loading..."))
+	sv.sessionsBox = container.NewVBox(widget.NewLabel("Loading..."))
+
+	sv.currentStreakCard = widget.NewCard("Current Streak", "", widget.NewLabel("Loading..."))
+	sv.longestStreakCard = widget.NewCard("Longest Streak", "", widget.NewLabel("Loading..."))
+	sv.daysListenedCard = widget.NewCard("Days Listened", "", widget.NewLabel("Loading..."))
+	sv.dailyChart = components.NewBarChart(140)
+	sv.dailyLabel = widget.NewLabel("")
+	sv.weeklyChart = components.NewBarChart(140)
+	sv.weeklyLabel = widget.NewLabel("")
+
+	periods := make([]string, len(statsPeriods))
+	for i, period := range statsPeriods {
+		periods[i] = period.label
+	}
+	sv.periodSelect = widget.NewSelect(periods, sv.onPeriodChanged)
+	sv.periodSelect.SetSelected(sv.period.label)
+	sv.rankSelect = widget.NewSelect([]string{rankByPlaysLabel, rankByTimeLabel}, sv.onRankChanged)
+	sv.rankSelect.SetSelected(rankByPlaysLabel)
+	sv.periodLabel = widget.NewLabel("")
+	sv.topSongsBox = container.NewVBox(widget.NewLabel("Loading..."))
+	sv.topAlbumsBox = container.NewVBox(widget.NewLabel("Loading..."))
+	sv.topArtistsBox = container.NewVBox(widget.NewLabel("Loading..."))
 }
 
 func (sv *StatsView) setupLayout() {
@@ -54,22 +104,60 @@ func (sv *StatsView) setupLayout() {
 		nil,
 	)
 
-	overviewGrid := container.NewGridWithColumns(4,
+	columns := 4
+	topColumns := 3
+	if sv.compactMode {
+		columns = 2
+		topColumns = 1
+	}
+
+	overviewGrid := container.NewGridWithColumns(columns,
 		sv.totalSongsCard,
 		sv.totalAlbumsCard,
 		sv.totalArtistsCard,
 		sv.timeListenedCard,
 	)
+	streakGrid := container.NewGridWithColumns(columns-1,
+		sv.currentStreakCard,
+		sv.longestStreakCard,
+		sv.daysListenedCard,
+	)
+	charts := container.NewGridWithColumns(topColumns,
+		widget.NewCard("Daily Listening", "", container.NewVBox(sv.dailyChart, sv.dailyLabel)),
+		widget.NewCard("Weekly Listening", "", container.NewVBox(sv.weeklyChart, sv.weeklyLabel)),
+	)
+
+	topControls := container.NewHBox(widget.NewLabel("Top Listening"), sv.periodSelect, sv.rankSelect)
+	topGrid := container.NewGridWithColumns(topColumns,
+		widget.NewCard("Top Songs", "", sv.topSongsBox),
+		widget.NewCard("Top Albums", "", sv.topAlbumsBox),
+		widget.NewCard("Top Artists", "", sv.topArtistsBox),
+	)
 
 	content := container.NewVBox(
 		header,
 		widget.NewSeparator(),
 		widget.NewLabel("Overview"),
 		overviewGrid,
+		streakGrid,
+		charts,
+		widget.NewSeparator(),
+		topControls,
+		sv.periodLabel,
+		topGrid,
+		widget.NewSeparator(),
+		widget.NewLabel("Recent Listening Sessions"),
+		sv.sessionsBox,
 	)
 
+	// The view is registered by its container, so it is kept when the
+	// layout changes.
 	scroll := container.NewScroll(content)
-	sv.container = container.NewVBox(scroll)
+	if sv.container == nil {
+		sv.container = container.NewStack()
+	}
+	sv.container.Objects = []fyne.CanvasObject{scroll}
+	sv.container.Refresh()
 }
 
 func (sv *StatsView) loadStats() {
@@ -92,12 +180,123 @@ func (sv *StatsView) loadStats() {
 		}
 
 		fyne.Do(func() {
-			sv.updateStats(len(songs), len(albums), len(artists), songs)
+			sv.updateStats(len(songs), len(albums), len(artists))
+		})
+	}()
+
+	go sv.loadListening()
+	go sv.loadTopLists(sv.period, sv.rank)
+
+	go func() {
+		sessions, err := sv.musicService.GetStorage().GetListeningSessions(context.Background(), 10, 0)
+		if err != nil {
+			log.Printf("[STATS_VIEW] Failed to load listening sessions: %v", err)
+			return
+		}
+		fyne.Do(func() {
+			sv.updateSessions(sessions)
+		})
+	}()
+}
+
+func (sv *StatsView) updateSessions(sessions []*types.ListeningSession) {
+	sv.sessionsBox.RemoveAll()
+
+	if len(sessions) == 0 {
+		sv.sessionsBox.Add(widget.NewLabel("No listening sessions yet"))
+		return
+	}
+
+	for _, session := range sessions {
+		title := widget.NewLabelWithStyle(describeSession(session), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		when := widget.NewLabel(session.StartedAt.Local().Format("Jan 2, 15:04"))
+		replayBtn := widget.NewButtonWithIcon("Play Again", theme.MediaPlayIcon(), nil)
+		replayBtn.Importance = widget.LowImportance
+		replayBtn.OnTapped = func() {
+			sv.replaySession(session, replayBtn)
+		}
+		if session.TrackCount == 0 {
+			replayBtn.Disable()
+		}
+		right := container.NewHBox(when, replayBtn)
+		sv.sessionsBox.Add(container.NewBorder(nil, nil, widget.NewIcon(theme.MediaMusicIcon()), right, title))
+	}
+}
+
+// replaySession loads the songs of a past session and plays them as a queue.
+func (sv *StatsView) replaySession(session *types.ListeningSession, btn *widget.Button) {
+	if sv.handlers == nil {
+		return
+	}
+	btn.Disable()
+
+	go func() {
+		songs, err := sv.musicService.GetSessionQueue(context.Background(), session.ID)
+		fyne.Do(func() {
+			btn.Enable()
+			if err != nil {
+				log.Printf("[STATS_VIEW] Failed to load session %s: %v", session.ID, err)
+				if sv.parentWindow != nil {
+					dialog.ShowError(err, sv.parentWindow)
+				}
+				return
+			}
+			if len(songs) == 0 {
+				if sv.parentWindow != nil {
+					dialog.ShowInformation("Play Again", "None of the songs from this session are in your library anymore.", sv.parentWindow)
+				}
+				return
+			}
+
+			source := types.PlaySource{
+				Type: types.PlaySourceHistory,
+				ID:   session.ID,
+				Name: session.StartedAt.Local().Format("Jan 2, 15:04"),
+			}
+			sv.handlers.HandleSongSelectionFrom(songs[0], songs, source)
 		})
 	}()
 }
 
-func (sv *StatsView) updateStats(songCount, albumCount, artistCount int, songs []*types.Song) {
+func (sv *StatsView) SetParentWindow(window fyne.Window) {
+	sv.parentWindow = window
+}
+
+// describeSession renders a session as "Listening session: 14 tracks, 52 min, started from playlist X".
+func describeSession(session *types.ListeningSession) string {
+	tracks := "tracks"
+	if session.TrackCount == 1 {
+		tracks = "track"
+	}
+
+	minutes := session.ListenedSeconds / 60
+	if minutes == 0 && session.ListenedSeconds == 0 {
+		minutes = int(session.EndedAt.Sub(session.StartedAt).Minutes())
+	}
+
+	text := fmt.Sprintf("Listening session: %d %s, %d min", session.TrackCount, tracks, minutes)
+
+	switch session.Source.Type {
+	case types.PlaySourcePlaylist:
+		text += fmt.Sprintf(", started from playlist %s", session.Source.Name)
+	case types.PlaySourceAlbum:
+		text += fmt.Sprintf(", started from album %s", session.Source.Name)
+	case types.PlaySourceAuthor:
+		text += fmt.Sprintf(", started from artist %s", session.Source.Name)
+	case types.PlaySourceExternal:
+		text += fmt.Sprintf(", started from file %s", session.Source.Name)
+	case types.PlaySourceHistory:
+		text += fmt.Sprintf(", replaying the session of %s", session.Source.Name)
+	case types.PlaySourceImport:
+		text += fmt.Sprintf(", imported from %s", session.Source.Name)
+	default:
+		text += ", started from library"
+	}
+
+	return text
+}
+
+func (sv *StatsView) updateStats(songCount, albumCount, artistCount int) {
 	sv.totalSongsCard.SetContent(container.NewVBox(
 		widget.NewLabelWithStyle(fmt.Sprintf("%d", songCount), fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
 		widget.NewLabelWithStyle("songs in library", fyne.TextAlignCenter, fyne.TextStyle{}),
@@ -112,21 +311,6 @@ func (sv *StatsView) updateStats(songCount, albumCount, artistCount int, songs [
 		widget.NewLabelWithStyle(fmt.Sprintf("%d", artistCount), fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
 		widget.NewLabelWithStyle("artists discovered", fyne.TextAlignCenter, fyne.TextStyle{}),
 	))
-
-	totalSeconds := 0
-	totalPlays := 0
-	for _, song := range songs {
-		totalSeconds += song.Played * song.Length
-		totalPlays += song.Played
-	}
-
-	hours := totalSeconds / 3600
-	minutes := (totalSeconds % 3600) / 60
-
-	sv.timeListenedCard.SetContent(container.NewVBox(
-		widget.NewLabelWithStyle(fmt.Sprintf("%dh %dm", hours, minutes), fyne.TextAlignCenter, fyne.TextStyle{Bold: true}),
-		widget.NewLabelWithStyle("total listening time", fyne.TextAlignCenter, fyne.TextStyle{}),
-	))
 }
 
 func (sv *StatsView) SetCompactMode(compact bool) {
@@ -0,0 +1,163 @@
+package views
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+)
+
+type transitionKind int
+
+const (
+	// transitionNone swaps views at once.
+	transitionNone transitionKind = iota
+	// transitionFade fades the new view in over the old one, for switching
+	// between top-level views.
+	transitionFade
+	// transitionForward slides the new view in from the right, for opening
+	// a detail page.
+	transitionForward
+	// transitionBack slides the previous view back in from the left.
+	transitionBack
+)
+
+const (
+	fadeDuration  = 160 * time.Millisecond
+	slideDuration = 220 * time.Millisecond
+)
+
+// viewTransition shows one view at a time and animates switching between
+// them. Starting a switch while one is running finishes the running one at
+// once, so quick navigation never waits for an animation.
+type viewTransition struct {
+	container *fyne.Container
+	stack     *fyne.Container
+	layout    *transitionLayout
+	shade     *canvas.Rectangle
+
+	current fyne.CanvasObject
+	anim    *fyne.Animation
+}
+
+func newViewTransition(initial fyne.CanvasObject) *viewTransition {
+	t := &viewTransition{
+		layout:  &transitionLayout{offsets: make(map[fyne.CanvasObject]float32)},
+		shade:   canvas.NewRectangle(color.Transparent),
+		current: initial,
+	}
+	t.stack = container.New(t.layout, initial)
+
+	// The scroll clips views sliding past the edges; it never scrolls.
+	clip := container.NewScroll(t.stack)
+	clip.Direction = container.ScrollNone
+	t.container = container.NewStack(clip)
+	return t
+}
+
+// Show replaces the current view with view, animated as kind.
+func (t *viewTransition) Show(view fyne.CanvasObject, kind transitionKind) {
+	t.stop()
+	if view == t.current {
+		return
+	}
+	from := t.current
+	t.current = view
+
+	if kind == transitionNone || from == nil {
+		t.settle()
+		return
+	}
+
+	duration := slideDuration
+	var tick func(float32)
+	switch kind {
+	case transitionFade:
+		duration = fadeDuration
+		t.stack.Objects = []fyne.CanvasObject{from, view, t.shade}
+		tick = func(p float32) {
+			t.shade.FillColor = fadedBackground(1 - p)
+			t.shade.Refresh()
+		}
+		t.shade.FillColor = fadedBackground(1)
+	case transitionForward, transitionBack:
+		dir := float32(1)
+		if kind == transitionBack {
+			dir = -1
+		}
+		t.stack.Objects = []fyne.CanvasObject{from, view}
+		// Moving the views is enough to redraw them; refreshing would
+		// rebuild their content on every frame.
+		tick = func(p float32) {
+			t.layout.offsets[from] = -dir * p
+			t.layout.offsets[view] = dir * (1 - p)
+			t.layout.Layout(t.stack.Objects, t.stack.Size())
+		}
+		t.layout.offsets[view] = dir
+	}
+	t.stack.Refresh()
+
+	var anim *fyne.Animation
+	anim = fyne.NewAnimation(duration, func(p float32) {
+		if t.anim != anim {
+			return
+		}
+		if p >= 1 {
+			t.anim = nil
+			t.settle()
+			return
+		}
+		tick(p)
+	})
+	t.anim = anim
+	t.anim.Curve = fyne.AnimationEaseOut
+	t.anim.Start()
+}
+
+// stop ends a running switch, leaving its new view in place.
+func (t *viewTransition) stop() {
+	if t.anim == nil {
+		return
+	}
+	t.anim.Stop()
+	t.anim = nil
+	t.settle()
+}
+
+// settle shows only the current view, in place.
+func (t *viewTransition) settle() {
+	clear(t.layout.offsets)
+	t.shade.FillColor = color.Transparent
+	t.stack.Objects = []fyne.CanvasObject{t.current}
+	t.stack.Refresh()
+}
+
+// fadedBackground is the theme background at the given opacity.
+func fadedBackground(alpha float32) color.Color {
+	r, g, b, _ := theme.Color(theme.ColorNameBackground).RGBA()
+	return color.NRGBA{R: uint8(r >> 8), G: uint8(g >> 8), B: uint8(b >> 8), A: uint8(alpha * 255)}
+}
+
+// transitionLayout gives every object the full size, shifted sideways by
+// its offset as a fraction of the width.
+type transitionLayout struct {
+	offsets map[fyne.CanvasObject]float32
+}
+
+func (l *transitionLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	for _, obj := range objects {
+		obj.Resize(size)
+		obj.Move(fyne.NewPos(l.offsets[obj]*size.Width, 0))
+	}
+}
+
+func (l *transitionLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	var min fyne.Size
+	for _, obj := range objects {
+		min = min.Max(obj.MinSize())
+	}
+	return min
+}
@@ -0,0 +1,212 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/Alexander-D-Karpov/amp/internal/services"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+// TrashView lists deleted songs and playlists until they are purged.
+type TrashView struct {
+	musicService *services.MusicService
+	container    *fyne.Container
+	parentWindow fyne.Window
+
+	itemsBox   *fyne.Container
+	refreshBtn *widget.Button
+	emptyBtn   *widget.Button
+
+	items      []*types.TrashItem
+	onRestored func(types.TrashKind)
+}
+
+func NewTrashView(musicService *services.MusicService) *TrashView {
+	tv := &TrashView{
+		musicService: musicService,
+	}
+
+	tv.setupWidgets()
+	tv.setupLayout()
+	return tv
+}
+
+func (tv *TrashView) setupWidgets() {
+	tv.refreshBtn = widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), tv.Refresh)
+	tv.emptyBtn = widget.NewButtonWithIcon("Empty Trash", theme.DeleteIcon(), tv.confirmEmpty)
+	tv.emptyBtn.Importance = widget.DangerImportance
+	tv.emptyBtn.Disable()
+	tv.itemsBox = container.NewVBox(widget.NewLabel("Loading..."))
+}
+
+func (tv *TrashView) setupLayout() {
+	header := container.NewBorder(
+		nil, nil,
+		widget.NewLabel("Trash"),
+		container.NewHBox(tv.emptyBtn, tv.refreshBtn),
+		nil,
+	)
+
+	hint := widget.NewLabel(fmt.Sprintf("Deleted songs and playlists can be restored for %d days.", int(types.TrashRetention.Hours()/24)))
+	hint.Wrapping = fyne.TextWrapWord
+
+	content := container.NewVBox(header, hint, widget.NewSeparator(), tv.itemsBox)
+	tv.container = container.NewBorder(nil, nil, nil, nil, container.NewScroll(content))
+}
+
+// Refresh reloads the trash from storage.
+func (tv *TrashView) Refresh() {
+	go func() {
+		items, err := tv.musicService.GetTrash(context.Background())
+		if err != nil {
+			log.Printf("[TRASH_VIEW] Failed to load trash: %v", err)
+			return
+		}
+		fyne.Do(func() {
+			tv.items = items
+			tv.updateItems()
+		})
+	}()
+}
+
+func (tv *TrashView) updateItems() {
+	tv.itemsBox.RemoveAll()
+
+	if len(tv.items) == 0 {
+		tv.emptyBtn.Disable()
+		tv.itemsBox.Add(widget.NewLabel("Trash is empty"))
+		return
+	}
+	tv.emptyBtn.Enable()
+
+	for _, item := range tv.items {
+		tv.itemsBox.Add(tv.createItemRow(item))
+	}
+}
+
+func (tv *TrashView) createItemRow(item *types.TrashItem) fyne.CanvasObject {
+	icon := widget.NewIcon(theme.MediaMusicIcon())
+	if item.Kind == types.TrashPlaylist {
+		icon = widget.NewIcon(theme.ListIcon())
+	}
+
+	title := widget.NewLabelWithStyle(item.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	title.Truncation = fyne.TextTruncateEllipsis
+	details := widget.NewLabel(describeTrashItem(item))
+
+	restoreBtn := widget.NewButtonWithIcon("Restore", theme.ContentUndoIcon(), func() {
+		tv.restore(item)
+	})
+	deleteBtn := widget.NewButtonWithIcon("Delete Forever", theme.DeleteIcon(), func() {
+		tv.confirmPurge(item)
+	})
+	deleteBtn.Importance = widget.LowImportance
+
+	return container.NewBorder(nil, nil, icon, container.NewHBox(restoreBtn, deleteBtn),
+		container.NewVBox(title, details))
+}
+
+// describeTrashItem renders "Playlist · deleted Jan 2 · 28 days left".
+func describeTrashItem(item *types.TrashItem) string {
+	kind := "Song"
+	if item.Kind == types.TrashPlaylist {
+		kind = "Playlist"
+	}
+
+	days := int(time.Until(item.ExpiresAt()).Hours() / 24)
+	left := fmt.Sprintf("%d days left", days)
+	if days < 1 {
+		left = "purged soon"
+	} else if days == 1 {
+		left = "1 day left"
+	}
+
+	return fmt.Sprintf("%s · deleted %s · %s", kind, item.DeletedAt.Local().Format("Jan 2"), left)
+}
+
+func (tv *TrashView) restore(item *types.TrashItem) {
+	go func() {
+		err := tv.musicService.RestoreFromTrash(context.Background(), item.Kind, item.Slug)
+		fyne.Do(func() {
+			if err != nil {
+				tv.showError(fmt.Errorf("could not restore %s: %w", item.Name, err))
+				return
+			}
+			if tv.onRestored != nil {
+				tv.onRestored(item.Kind)
+			}
+			tv.Refresh()
+		})
+	}()
+}
+
+func (tv *TrashView) confirmPurge(item *types.TrashItem) {
+	if tv.parentWindow == nil {
+		return
+	}
+	message := fmt.Sprintf("Delete \"%s\" permanently? This cannot be undone.", item.Name)
+	dialog.ShowConfirm("Delete Forever", message, func(confirmed bool) {
+		if confirmed {
+			tv.purge([]*types.TrashItem{item})
+		}
+	}, tv.parentWindow)
+}
+
+func (tv *TrashView) confirmEmpty() {
+	if tv.parentWindow == nil || len(tv.items) == 0 {
+		return
+	}
+	items := tv.items
+	message := fmt.Sprintf("Permanently delete %d items? This cannot be undone.", len(items))
+	dialog.ShowConfirm("Empty Trash", message, func(confirmed bool) {
+		if confirmed {
+			tv.purge(items)
+		}
+	}, tv.parentWindow)
+}
+
+func (tv *TrashView) purge(items []*types.TrashItem) {
+	go func() {
+		var failed error
+		for _, item := range items {
+			if err := tv.musicService.PurgeFromTrash(context.Background(), item); err != nil {
+				log.Printf("[TRASH_VIEW] Failed to purge %s: %v", item.Slug, err)
+				failed = fmt.Errorf("could not delete %s: %w", item.Name, err)
+			}
+		}
+		fyne.Do(func() {
+			if failed != nil {
+				tv.showError(failed)
+			}
+			tv.Refresh()
+		})
+	}()
+}
+
+func (tv *TrashView) showError(err error) {
+	if tv.parentWindow != nil {
+		dialog.ShowError(err, tv.parentWindow)
+	}
+}
+
+// OnRestored is called after an item is restored so the owning view can reload.
+func (tv *TrashView) OnRestored(callback func(types.TrashKind)) {
+	tv.onRestored = callback
+}
+
+func (tv *TrashView) SetParentWindow(window fyne.Window) {
+	tv.parentWindow = window
+}
+
+func (tv *TrashView) Container() *fyne.Container {
+	return tv.container
+}
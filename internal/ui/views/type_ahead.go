@@ -0,0 +1,92 @@
+package views
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+
+	"github.com/Alexander-D-Karpov/amp/internal/ui/components"
+)
+
+// typeAheadTimeout is how long typing may pause before the next letter
+// starts a new prefix instead of extending the last one.
+const typeAheadTimeout = time.Second
+
+// typeAhead jumps to the first item of a list whose name starts with the
+// letters typed into it, as file managers do. Typing the same letter again
+// steps on to the next item starting with it. Only loaded items are
+// searched, and it works apart from the search box.
+type typeAhead struct {
+	prefix string
+	last   time.Time
+	index  int
+}
+
+// jump adds r to what was typed and returns the index in names it lands on,
+// or -1 when no name starts with it.
+func (t *typeAhead) jump(r rune, names []string) int {
+	now := time.Now()
+	if now.Sub(t.last) > typeAheadTimeout {
+		t.prefix = ""
+		t.index = -1
+	}
+	t.last = now
+
+	typed := string(unicode.ToLower(r))
+	from := max(t.index, 0)
+	if t.prefix == typed {
+		from = t.index + 1
+	} else {
+		t.prefix += typed
+	}
+
+	index := matchPrefix(names, t.prefix, from)
+	if index >= 0 {
+		t.index = index
+	}
+	return index
+}
+
+// jumpInGrid jumps to the item of grid that typing r lands on, marking it
+// and scrolling it to the top of scroll.
+func (t *typeAhead) jumpInGrid(r rune, grid *components.MediaGrid, scroll *container.Scroll) {
+	items := grid.GetItems()
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.Title
+	}
+
+	index := t.jump(r, names)
+	if index < 0 {
+		return
+	}
+	grid.Highlight(index)
+	if pos, ok := grid.ItemPosition(index); ok {
+		scrollToItem(scroll, pos)
+	}
+}
+
+// matchPrefix returns the index of the first name starting with prefix,
+// ignoring case, searching from index from and wrapping around, or -1.
+func matchPrefix(names []string, prefix string, from int) int {
+	for i := range names {
+		j := (from + i) % len(names)
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(names[j])), prefix) {
+			return j
+		}
+	}
+	return -1
+}
+
+// scrollToItem scrolls so the item laid out at pos in scroll's content is
+// at the top, or as near as the content allows.
+func scrollToItem(scroll *container.Scroll, pos fyne.Position) {
+	if scroll == nil || scroll.Content == nil {
+		return
+	}
+	bottom := max(scroll.Content.MinSize().Height-scroll.Size().Height, 0)
+	scroll.ScrollToOffset(fyne.NewPos(scroll.Offset.X, min(pos.Y, bottom)))
+}
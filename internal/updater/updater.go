@@ -0,0 +1,609 @@
+package updater
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+	"github.com/Alexander-D-Karpov/amp/internal/netutil"
+	"github.com/Alexander-D-Karpov/amp/internal/platform"
+)
+
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+
+	pendingFile   = "pending.json"
+	checkInterval = 24 * time.Hour
+)
+
+// ReleaseKey is the base64 ed25519 public key releases are signed with. It
+// is set at build time with -ldflags "-X
+// github.com/Alexander-D-Karpov/amp/internal/updater.ReleaseKey=...", and
+// update.public_key in the config overrides it.
+var ReleaseKey string
+
+// ErrNoPublicKey is returned when an update is downloaded by a build that
+// has no key to check its signature with. Such builds only check for
+// updates and never install them.
+var ErrNoPublicKey = errors.New("no public key to verify updates with")
+
+// Feed is the release feed document published for every channel.
+type Feed struct {
+	Channels map[string]*Release `json:"channels"`
+}
+
+type Release struct {
+	Version     string           `json:"version"`
+	Notes       string           `json:"notes"`
+	PublishedAt string           `json:"published_at"`
+	Assets      map[string]Asset `json:"assets"`
+}
+
+// Asset is a downloadable binary for a single GOOS-GOARCH pair. Signature
+// is the base64 ed25519 signature of the asset's Manifest.
+type Asset struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+type pendingUpdate struct {
+	Version string `json:"version"`
+	Path    string `json:"path"`
+}
+
+type Updater struct {
+	cfg            *config.Config
+	httpClient     *http.Client
+	currentVersion string
+	debug          bool
+
+	mu          sync.Mutex
+	latest      *Release
+	staged      *pendingUpdate
+	onAvailable func(*Release)
+	stop        chan struct{}
+}
+
+func New(cfg *config.Config, currentVersion string) *Updater {
+	u := &Updater{
+		cfg:            cfg,
+		httpClient:     netutil.NewClient(cfg, netutil.ProfileUpdate),
+		currentVersion: currentVersion,
+		debug:          cfg.Debug,
+	}
+
+	if pending, err := u.readPending(); err == nil {
+		u.staged = pending
+	}
+
+	return u
+}
+
+func (u *Updater) CurrentVersion() string {
+	return u.currentVersion
+}
+
+func (u *Updater) Channel() string {
+	if u.cfg.Update.Channel == ChannelBeta {
+		return ChannelBeta
+	}
+	return ChannelStable
+}
+
+// LatestRelease returns the newest release found by the last check, if any.
+func (u *Updater) LatestRelease() *Release {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.latest
+}
+
+// StagedVersion returns the version waiting to be applied on restart.
+func (u *Updater) StagedVersion() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.staged == nil {
+		return ""
+	}
+	return u.staged.Version
+}
+
+func (u *Updater) OnUpdateAvailable(callback func(*Release)) {
+	u.mu.Lock()
+	u.onAvailable = callback
+	u.mu.Unlock()
+}
+
+// Check fetches the release feed and returns the channel's release if it is
+// newer than the running build, or nil when already up to date.
+func (u *Updater) Check(ctx context.Context) (*Release, error) {
+	if u.cfg.Update.FeedURL == "" {
+		return nil, fmt.Errorf("no update feed configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.cfg.Update.FeedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create feed request: %w", err)
+	}
+	req.Header.Set("User-Agent", u.cfg.API.UserAgent)
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch release feed: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Printf("[UPDATER] Failed to close feed body: %v", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("release feed returned status %d", resp.StatusCode)
+	}
+
+	var feed Feed
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("decode release feed: %w", err)
+	}
+
+	release := feed.Channels[u.Channel()]
+	if release == nil {
+		return nil, fmt.Errorf("channel %q not found in feed", u.Channel())
+	}
+
+	newer, err := isNewer(release.Version, u.currentVersion)
+	if err != nil {
+		if u.debug {
+			log.Printf("[UPDATER] Skipping update check for build %q: %v", u.currentVersion, err)
+		}
+		return nil, nil
+	}
+	if !newer {
+		if u.debug {
+			log.Printf("[UPDATER] Up to date on %s channel (%s)", u.Channel(), u.currentVersion)
+		}
+		return nil, nil
+	}
+
+	u.mu.Lock()
+	u.latest = release
+	cb := u.onAvailable
+	u.mu.Unlock()
+
+	if u.debug {
+		log.Printf("[UPDATER] Update available: %s -> %s", u.currentVersion, release.Version)
+	}
+	if cb != nil {
+		cb(release)
+	}
+
+	return release, nil
+}
+
+// Download fetches the release binary for this platform, verifies its hash
+// and signature and stages it to be applied on the next restart.
+func (u *Updater) Download(ctx context.Context, release *Release) error {
+	asset, ok := release.Assets[platformKey()]
+	if !ok {
+		return fmt.Errorf("no build for %s in release %s", platformKey(), release.Version)
+	}
+	if newer, err := isNewer(release.Version, u.currentVersion); err != nil || !newer {
+		return fmt.Errorf("release %s is not newer than %s", release.Version, u.currentVersion)
+	}
+
+	dir, err := updatesDir()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return fmt.Errorf("create download request: %w", err)
+	}
+	req.Header.Set("User-Agent", u.cfg.API.UserAgent)
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("download update: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Printf("[UPDATER] Failed to close download body: %v", closeErr)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("update download returned status %d", resp.StatusCode)
+	}
+
+	target := filepath.Join(dir, "amp-"+sanitizeVersion(release.Version)+filepath.Ext(os.Args[0]))
+	tmp := target + ".part"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return fmt.Errorf("create update file: %w", err)
+	}
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hasher), resp.Body); err != nil {
+		_ = file.Close()
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write update file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("close update file: %w", err)
+	}
+
+	digest := hasher.Sum(nil)
+	if err := u.verify(release.Version, asset, digest); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		return fmt.Errorf("stage update: %w", err)
+	}
+
+	pending := &pendingUpdate{Version: release.Version, Path: target}
+	if err := writePending(pending); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	u.staged = pending
+	u.mu.Unlock()
+
+	if u.debug {
+		log.Printf("[UPDATER] Staged %s at %s", release.Version, target)
+	}
+	return nil
+}
+
+// Manifest returns what a release asset's signature covers: the version,
+// channel and platform it is published for and the sha256 digest of the
+// binary. Signing them together keeps a tampered or replayed feed from
+// passing an older signed build off as a newer version.
+func Manifest(version, channel, platform string, digest []byte) []byte {
+	return []byte(fmt.Sprintf("amp-update\nversion=%s\nchannel=%s\nplatform=%s\nsha256=%x\n",
+		version, channel, platform, digest))
+}
+
+// verify checks the downloaded binary against the asset's checksum, and the
+// asset's signature against the manifest of version on this channel and
+// platform.
+func (u *Updater) verify(version string, asset Asset, digest []byte) error {
+	expected, err := hex.DecodeString(strings.TrimSpace(asset.SHA256))
+	if err != nil || len(expected) != sha256.Size {
+		return fmt.Errorf("release has invalid sha256 checksum")
+	}
+	if !bytes.Equal(expected, digest) {
+		return fmt.Errorf("checksum mismatch for downloaded update")
+	}
+
+	publicKey := u.cfg.Update.PublicKey
+	if publicKey == "" {
+		publicKey = ReleaseKey
+	}
+	if publicKey == "" {
+		return ErrNoPublicKey
+	}
+
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid update public key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(asset.Signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return fmt.Errorf("release is missing a valid signature")
+	}
+	manifest := Manifest(version, u.Channel(), platformKey(), digest)
+	if !ed25519.Verify(ed25519.PublicKey(key), manifest, sig) {
+		return fmt.Errorf("signature verification failed for downloaded update")
+	}
+	return nil
+}
+
+// ApplyPending swaps the running executable for a staged update. It is meant
+// to be called once the UI has shut down so the new build starts next launch.
+func (u *Updater) ApplyPending() error {
+	u.mu.Lock()
+	pending := u.staged
+	u.mu.Unlock()
+
+	if pending == nil {
+		return nil
+	}
+
+	if newer, err := isNewer(pending.Version, u.currentVersion); err != nil || !newer {
+		u.discardPending(pending)
+		return fmt.Errorf("staged update %s is not newer than %s", pending.Version, u.currentVersion)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	if _, err := os.Stat(pending.Path); err != nil {
+		_ = removePending()
+		return fmt.Errorf("staged update missing: %w", err)
+	}
+
+	backup := exe + ".old"
+	_ = os.Remove(backup)
+	if err := os.Rename(exe, backup); err != nil {
+		return fmt.Errorf("move current executable aside: %w", err)
+	}
+	if err := moveFile(pending.Path, exe); err != nil {
+		if restoreErr := os.Rename(backup, exe); restoreErr != nil {
+			log.Printf("[UPDATER] Failed to restore previous executable: %v", restoreErr)
+		}
+		return fmt.Errorf("install update: %w", err)
+	}
+
+	if err := removePending(); err != nil {
+		log.Printf("[UPDATER] Failed to clear pending update: %v", err)
+	}
+
+	u.mu.Lock()
+	u.staged = nil
+	u.mu.Unlock()
+
+	log.Printf("[UPDATER] Installed AMP %s, it will be used on next launch", pending.Version)
+	return nil
+}
+
+// discardPending forgets a staged update and deletes its file.
+func (u *Updater) discardPending(pending *pendingUpdate) {
+	if err := os.Remove(pending.Path); err != nil && !os.IsNotExist(err) {
+		log.Printf("[UPDATER] Failed to remove staged update: %v", err)
+	}
+	if err := removePending(); err != nil {
+		log.Printf("[UPDATER] Failed to clear pending update: %v", err)
+	}
+
+	u.mu.Lock()
+	u.staged = nil
+	u.mu.Unlock()
+}
+
+// CleanupPrevious removes the executable left behind by the last update.
+func (u *Updater) CleanupPrevious() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if err := os.Remove(exe + ".old"); err != nil && !os.IsNotExist(err) && u.debug {
+		log.Printf("[UPDATER] Failed to remove previous executable: %v", err)
+	}
+}
+
+// StartAutoCheck checks for updates shortly after startup and then daily.
+func (u *Updater) StartAutoCheck(ctx context.Context) {
+	if !u.cfg.Update.AutoCheck {
+		return
+	}
+
+	u.mu.Lock()
+	if u.stop != nil {
+		u.mu.Unlock()
+		return
+	}
+	u.stop = make(chan struct{})
+	stop := u.stop
+	u.mu.Unlock()
+
+	go func() {
+		timer := time.NewTimer(time.Minute)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-timer.C:
+				if _, err := u.Check(ctx); err != nil && u.debug {
+					log.Printf("[UPDATER] Background check failed: %v", err)
+				}
+				timer.Reset(checkInterval)
+			}
+		}
+	}()
+}
+
+func (u *Updater) Stop() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.stop != nil {
+		close(u.stop)
+		u.stop = nil
+	}
+}
+
+func (u *Updater) readPending() (*pendingUpdate, error) {
+	dir, err := updatesDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, pendingFile))
+	if err != nil {
+		return nil, err
+	}
+	var pending pendingUpdate
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("decode pending update: %w", err)
+	}
+	return &pending, nil
+}
+
+func writePending(pending *pendingUpdate) error {
+	dir, err := updatesDir()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("encode pending update: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, pendingFile), data, 0644); err != nil {
+		return fmt.Errorf("write pending update: %w", err)
+	}
+	return nil
+}
+
+func removePending() error {
+	dir, err := updatesDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, pendingFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func updatesDir() (string, error) {
+	dataDir, err := platform.GetDataDir()
+	if err != nil {
+		return "", fmt.Errorf("get data dir: %w", err)
+	}
+	dir := filepath.Join(dataDir, "updates")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("create updates dir: %w", err)
+	}
+	return dir, nil
+}
+
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	// Rename fails across filesystems, fall back to a copy.
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := in.Close(); closeErr != nil {
+			log.Printf("[UPDATER] Failed to close staged file: %v", closeErr)
+		}
+	}()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+func platformKey() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+func sanitizeVersion(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, v)
+}
+
+type version struct {
+	parts      [3]int
+	prerelease string
+}
+
+func parseVersion(v string) (version, error) {
+	var out version
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if v == "" {
+		return out, fmt.Errorf("empty version")
+	}
+
+	core := v
+	if idx := strings.IndexAny(v, "-+"); idx >= 0 {
+		core = v[:idx]
+		if v[idx] == '-' {
+			out.prerelease = strings.SplitN(v[idx+1:], "+", 2)[0]
+		}
+	}
+
+	fields := strings.Split(core, ".")
+	if len(fields) > 3 {
+		return out, fmt.Errorf("invalid version %q", v)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return out, fmt.Errorf("invalid version %q", v)
+		}
+		out.parts[i] = n
+	}
+	return out, nil
+}
+
+// isNewer reports whether candidate is a later version than current.
+func isNewer(candidate, current string) (bool, error) {
+	c, err := parseVersion(candidate)
+	if err != nil {
+		return false, err
+	}
+	cur, err := parseVersion(current)
+	if err != nil {
+		return false, err
+	}
+
+	for i := range c.parts {
+		if c.parts[i] != cur.parts[i] {
+			return c.parts[i] > cur.parts[i], nil
+		}
+	}
+
+	switch {
+	case c.prerelease == cur.prerelease:
+		return false, nil
+	case c.prerelease == "":
+		return true, nil
+	case cur.prerelease == "":
+		return false, nil
+	default:
+		return c.prerelease > cur.prerelease, nil
+	}
+}
@@ -0,0 +1,60 @@
+package updater
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+func TestVerifyChecksSignedManifest(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Update.Channel = ChannelStable
+	cfg.Update.PublicKey = base64.StdEncoding.EncodeToString(public)
+	u := &Updater{cfg: cfg, currentVersion: "1.0.0"}
+
+	digest := sha256.Sum256([]byte("amp 1.1.0"))
+	asset := Asset{
+		SHA256:    hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(private, Manifest("1.1.0", ChannelStable, platformKey(), digest[:]))),
+	}
+
+	if err := u.verify("1.1.0", asset, digest[:]); err != nil {
+		t.Fatalf("verify signed release: %v", err)
+	}
+
+	// A feed relabeling the same signed build as another version must
+	// not pass.
+	if err := u.verify("9.0.0", asset, digest[:]); err == nil {
+		t.Fatal("relabeled version passed verification")
+	}
+
+	cfg.Update.Channel = ChannelBeta
+	if err := u.verify("1.1.0", asset, digest[:]); err == nil {
+		t.Fatal("build signed for stable passed verification on beta")
+	}
+}
+
+func TestVerifyNeedsKey(t *testing.T) {
+	cfg := &config.Config{}
+	u := &Updater{cfg: cfg, currentVersion: "1.0.0"}
+
+	saved := ReleaseKey
+	ReleaseKey = ""
+	defer func() { ReleaseKey = saved }()
+
+	digest := sha256.Sum256([]byte("amp"))
+	err := u.verify("1.1.0", Asset{SHA256: hex.EncodeToString(digest[:])}, digest[:])
+	if !errors.Is(err, ErrNoPublicKey) {
+		t.Fatalf("verify without key: %v, want ErrNoPublicKey", err)
+	}
+}
@@ -0,0 +1,235 @@
+package ampclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/internal/config"
+)
+
+const (
+	// DefaultBaseURL is the public AMP server.
+	DefaultBaseURL   = "https://new.akarpov.ru/api/v1"
+	defaultUserAgent = "AMP/1.0.0"
+	defaultTimeout   = 30 * time.Second
+	defaultRetries   = 3
+)
+
+// Options set up a Client. Only what differs from the defaults needs to be
+// set.
+type Options struct {
+	// Backend is BackendAMP, the default, or BackendSubsonic.
+	Backend string
+	// BaseURL is the server API, DefaultBaseURL when empty.
+	BaseURL string
+	// Token signs in to an AMP server. Without one the client browses
+	// anonymously once Anonymous or Login was called.
+	Token string
+	// Username and Password sign in to a Subsonic server.
+	Username string
+	Password string
+
+	UserAgent string
+	// Timeout limits each request, 30 seconds when zero.
+	Timeout time.Duration
+	// Retries is how often a failed request is tried again, 3 when zero.
+	Retries int
+	// RequestsPerSecond limits the request rate, 100 when zero.
+	RequestsPerSecond int
+	// Debug logs every request.
+	Debug bool
+}
+
+// Client talks to one music server. It is safe to use from several
+// goroutines.
+type Client struct {
+	backend api.MusicBackend
+	name    string
+}
+
+// New returns a client for the server in opts.
+func New(opts Options) (*Client, error) {
+	cfg := &config.Config{Embedded: true, Debug: opts.Debug}
+
+	cfg.API.Backend = opts.Backend
+	if cfg.API.Backend == "" {
+		cfg.API.Backend = BackendAMP
+	}
+	if cfg.API.Backend != BackendAMP && cfg.API.Backend != BackendSubsonic {
+		return nil, fmt.Errorf("unknown backend %q", opts.Backend)
+	}
+	cfg.API.BaseURL = opts.BaseURL
+	if cfg.API.BaseURL == "" {
+		cfg.API.BaseURL = DefaultBaseURL
+	}
+	cfg.API.Token = opts.Token
+	cfg.API.Subsonic.Username = opts.Username
+	cfg.API.Subsonic.Password = opts.Password
+
+	cfg.API.UserAgent = opts.UserAgent
+	if cfg.API.UserAgent == "" {
+		cfg.API.UserAgent = defaultUserAgent
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	cfg.API.Timeout = max(1, int(timeout/time.Second))
+	cfg.API.Retries = opts.Retries
+	if cfg.API.Retries == 0 {
+		cfg.API.Retries = defaultRetries
+	}
+	cfg.API.RateLimit.RequestsPerSecond = opts.RequestsPerSecond
+	if cfg.API.RateLimit.RequestsPerSecond <= 0 {
+		cfg.API.RateLimit.RequestsPerSecond = 100
+	}
+	cfg.API.RateLimit.BurstSize = 10
+
+	return &Client{backend: api.NewBackend(cfg), name: cfg.API.Backend}, nil
+}
+
+// Backend returns the protocol the client speaks.
+func (c *Client) Backend() string {
+	return c.name
+}
+
+// Login signs in to an AMP server with a username and password. Subsonic
+// servers take theirs in Options instead.
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	client, ok := c.backend.(*api.Client)
+	if !ok {
+		return ErrUnsupported
+	}
+	_, err := client.Login(ctx, username, password)
+	return err
+}
+
+// Authenticate signs in with token, keeping the previous one if the server
+// turns it down.
+func (c *Client) Authenticate(ctx context.Context, token string) error {
+	return c.backend.Authenticate(ctx, token)
+}
+
+// Anonymous makes sure the client has a token, asking the server for an
+// anonymous one when it has none, and returns it.
+func (c *Client) Anonymous(ctx context.Context) (string, error) {
+	return c.backend.EnsureAnonymousToken(ctx)
+}
+
+// Token returns the token the client signs its requests with, to keep for
+// the next run.
+func (c *Client) Token() string {
+	return c.backend.GetToken()
+}
+
+func (c *Client) IsAnonymous() bool {
+	return c.backend.IsAnonymous()
+}
+
+func (c *Client) Logout(ctx context.Context) error {
+	return c.backend.Logout(ctx)
+}
+
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	return c.backend.GetCurrentUser(ctx)
+}
+
+// Probe asks the server for its version and features, which Supports then
+// goes by.
+func (c *Client) Probe(ctx context.Context) (*ServerInfo, error) {
+	return c.backend.ProbeServer(ctx)
+}
+
+// Supports reports whether the server offers an optional feature. Until
+// Probe ran every feature is assumed.
+func (c *Client) Supports(feature string) bool {
+	return c.backend.Supports(feature)
+}
+
+// Songs returns a page of songs, starting from 1, matching search when it
+// is not empty.
+func (c *Client) Songs(ctx context.Context, page int, search string, sort SortOption) (*SongListResponse, error) {
+	return c.backend.GetSongsWithSort(ctx, page, search, sort)
+}
+
+func (c *Client) Song(ctx context.Context, slug string) (*Song, error) {
+	return c.backend.GetSong(ctx, slug)
+}
+
+// Albums returns a page of albums, starting from 1, matching search when it
+// is not empty.
+func (c *Client) Albums(ctx context.Context, page int, search string) (*AlbumListResponse, error) {
+	return c.backend.GetAlbums(ctx, page, search)
+}
+
+// Album returns an album with its songs.
+func (c *Client) Album(ctx context.Context, slug string) (*Album, error) {
+	return c.backend.GetAlbum(ctx, slug)
+}
+
+// Authors returns a page of artists, starting from 1, matching search when
+// it is not empty.
+func (c *Client) Authors(ctx context.Context, page int, search string) (*AuthorListResponse, error) {
+	return c.backend.GetAuthors(ctx, page, search)
+}
+
+// Author returns an artist with their songs and albums.
+func (c *Client) Author(ctx context.Context, slug string) (*Author, error) {
+	return c.backend.GetAuthor(ctx, slug)
+}
+
+func (c *Client) Playlists(ctx context.Context) ([]*Playlist, error) {
+	return c.backend.GetPlaylists(ctx)
+}
+
+// Playlist returns a playlist with its songs.
+func (c *Client) Playlist(ctx context.Context, slug string) (*Playlist, error) {
+	return c.backend.GetPlaylist(ctx, slug)
+}
+
+func (c *Client) UpdatePlaylist(ctx context.Context, playlist *Playlist) error {
+	return c.backend.UpdatePlaylist(ctx, playlist)
+}
+
+func (c *Client) DeletePlaylist(ctx context.Context, slug string) error {
+	return c.backend.DeletePlaylist(ctx, slug)
+}
+
+// Search looks for songs, albums and artists at once.
+func (c *Client) Search(ctx context.Context, query string) (*SearchResponse, error) {
+	return c.backend.SearchAll(ctx, query)
+}
+
+// Listen reports a play of the song.
+func (c *Client) Listen(ctx context.Context, slug string) error {
+	return c.backend.ListenSong(ctx, slug, "")
+}
+
+// Like likes a song on an AMP server.
+func (c *Client) Like(ctx context.Context, slug string) error {
+	client, ok := c.backend.(*api.Client)
+	if !ok {
+		return ErrUnsupported
+	}
+	return client.LikeSong(ctx, slug)
+}
+
+// Dislike takes back a like on an AMP server.
+func (c *Client) Dislike(ctx context.Context, slug string) error {
+	client, ok := c.backend.(*api.Client)
+	if !ok {
+		return ErrUnsupported
+	}
+	return client.DislikeSong(ctx, slug)
+}
+
+// LikedSongs returns the songs liked on an AMP server.
+func (c *Client) LikedSongs(ctx context.Context) ([]*Song, error) {
+	client, ok := c.backend.(*api.Client)
+	if !ok || !client.Supports(FeatureLikedSongs) {
+		return nil, ErrUnsupported
+	}
+	return client.GetLikedSongs(ctx)
+}
@@ -0,0 +1,22 @@
+// Package ampclient lets other programs talk to the music servers AMP
+// plays from, without AMP's player, database or user interface.
+//
+// A Client covers the server API of either backend AMP supports, the
+// akarpov music API and Subsonic-compatible servers. A Library walks the
+// whole catalog a page at a time, for bots, importers and terminal
+// front ends that want every song rather than one page of them:
+//
+//	client, err := ampclient.New(ampclient.Options{Token: token})
+//	if err != nil {
+//		return err
+//	}
+//	library := ampclient.NewLibrary(client)
+//	err = library.EachSong(ctx, "", func(song *ampclient.Song) error {
+//		fmt.Println(song.Name)
+//		return nil
+//	})
+//
+// The types are the ones AMP itself uses, so songs fetched here can be
+// handed to anything built on pkg/types. Unlike AMP, a Client keeps its
+// token to itself: logging in never touches AMP's config file.
+package ampclient
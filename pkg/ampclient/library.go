@@ -0,0 +1,93 @@
+package ampclient
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxPages stops a walk over a server that never reports the last page.
+const maxPages = 10000
+
+// Library walks the catalog of a server a page at a time. It plays
+// nothing and keeps nothing; each walk asks the server afresh.
+type Library struct {
+	client *Client
+}
+
+func NewLibrary(client *Client) *Library {
+	return &Library{client: client}
+}
+
+// Client returns the client the library asks.
+func (l *Library) Client() *Client {
+	return l.client
+}
+
+// EachSong calls fn for every song matching search, or every song when
+// search is empty. The walk stops at the first error fn returns, which
+// EachSong returns.
+func (l *Library) EachSong(ctx context.Context, search string, fn func(*Song) error) error {
+	return eachPage(ctx, "songs", func(page int) ([]*Song, bool, error) {
+		resp, err := l.client.Songs(ctx, page, search, SortDefault)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Results, resp.Next != nil, nil
+	}, fn)
+}
+
+// EachAlbum calls fn for every album matching search, like EachSong.
+// Albums come without their songs; Client.Album fetches those.
+func (l *Library) EachAlbum(ctx context.Context, search string, fn func(*Album) error) error {
+	return eachPage(ctx, "albums", func(page int) ([]*Album, bool, error) {
+		resp, err := l.client.Albums(ctx, page, search)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Results, resp.Next != nil, nil
+	}, fn)
+}
+
+// EachAuthor calls fn for every artist matching search, like EachSong.
+func (l *Library) EachAuthor(ctx context.Context, search string, fn func(*Author) error) error {
+	return eachPage(ctx, "authors", func(page int) ([]*Author, bool, error) {
+		resp, err := l.client.Authors(ctx, page, search)
+		if err != nil {
+			return nil, false, err
+		}
+		return resp.Results, resp.Next != nil, nil
+	}, fn)
+}
+
+// Songs returns every song matching search. For large catalogs EachSong
+// keeps less in memory.
+func (l *Library) Songs(ctx context.Context, search string) ([]*Song, error) {
+	var songs []*Song
+	err := l.EachSong(ctx, search, func(song *Song) error {
+		songs = append(songs, song)
+		return nil
+	})
+	return songs, err
+}
+
+func eachPage[T any](ctx context.Context, entity string, fetch func(page int) ([]*T, bool, error), fn func(*T) error) error {
+	for page := 1; page <= maxPages; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, more, err := fetch(page)
+		if err != nil {
+			return fmt.Errorf("get %s page %d: %w", entity, page, err)
+		}
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if !more || len(items) == 0 {
+			return nil
+		}
+	}
+	return nil
+}
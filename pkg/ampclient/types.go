@@ -0,0 +1,50 @@
+package ampclient
+
+import (
+	"github.com/Alexander-D-Karpov/amp/internal/api"
+	"github.com/Alexander-D-Karpov/amp/pkg/types"
+)
+
+type (
+	Song               = types.Song
+	Album              = types.Album
+	Author             = types.Author
+	Playlist           = types.Playlist
+	User               = types.User
+	SongListResponse   = types.SongListResponse
+	AlbumListResponse  = types.AlbumListResponse
+	AuthorListResponse = types.AuthorListResponse
+	SearchResponse     = types.SearchResponse
+
+	// ServerInfo is what a server reports about its version and features.
+	ServerInfo = api.ServerInfo
+	// SortOption orders the songs Client.Songs returns.
+	SortOption = api.SortOption
+)
+
+// Backends, the protocols a Client can speak.
+const (
+	BackendAMP      = api.BackendAMP
+	BackendSubsonic = api.BackendSubsonic
+)
+
+const (
+	SortDefault       = api.SortDefault
+	SortPlayed        = api.SortPlayed
+	SortLikes         = api.SortLikes
+	SortLikesReversed = api.SortLikesReversed
+	SortLength        = api.SortLength
+	SortUploaded      = api.SortUploaded
+)
+
+// Optional server features, for Client.Supports.
+const (
+	FeatureSongSort        = api.FeatureSongSort
+	FeaturePlaylistEditing = api.FeaturePlaylistEditing
+	FeatureLikedSongs      = api.FeatureLikedSongs
+	FeatureListenHistory   = api.FeatureListenHistory
+	FeatureUpdatedAfter    = api.FeatureUpdatedAfter
+)
+
+// ErrUnsupported is returned for what the server or backend cannot do.
+var ErrUnsupported = api.ErrUnsupported
@@ -48,7 +48,9 @@ type PlayerControl interface {
 }
 
 type DownloadProgress struct {
-	URL        string         `json:"url"`
+	URL string `json:"url"`
+	// SongSlug is the song being downloaded, empty for other files.
+	SongSlug   string         `json:"song_slug,omitempty"`
 	Filename   string         `json:"filename"`
 	Total      int64          `json:"total"`
 	Downloaded int64          `json:"downloaded"`
@@ -70,6 +72,7 @@ const (
 	DownloadStatusCompleted
 	DownloadStatusFailed
 	DownloadStatusCancelled
+	DownloadStatusPaused
 )
 
 func (s DownloadStatus) String() string {
@@ -84,6 +87,8 @@ func (s DownloadStatus) String() string {
 		return "Failed"
 	case DownloadStatusCancelled:
 		return "Cancelled"
+	case DownloadStatusPaused:
+		return "Paused"
 	default:
 		return "Unknown"
 	}
@@ -2,9 +2,19 @@ package types
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
+// LocalSlugPrefix marks songs opened from local files that the API does not know about
+const LocalSlugPrefix = "local:"
+
+// VariousArtists is the album artist of compilations
+const VariousArtists = "Various Artists"
+
+// compilationLeadArtists is how many different lead artists make an album a compilation
+const compilationLeadArtists = 3
+
 // Song represents a music track with metadata and playback information
 type Song struct {
 	Slug         string    `json:"slug" db:"slug"`
@@ -29,6 +39,31 @@ type Song struct {
 	UpdatedAt  time.Time `json:"-" db:"updated_at"`
 }
 
+// IsLocalOnly reports whether the song only exists on this machine
+func (s *Song) IsLocalOnly() bool {
+	return strings.HasPrefix(s.Slug, LocalSlugPrefix)
+}
+
+// IsAvailableOffline reports whether the song can be played without the
+// server: it was downloaded or scanned from a library folder.
+func (s *Song) IsAvailableOffline() bool {
+	return s.Downloaded || (s.LocalPath != nil && *s.LocalPath != "")
+}
+
+// AlbumArtist returns the artist the song is filed under, which differs from
+// its own authors on compilations.
+func (s *Song) AlbumArtist() string {
+	if s.Album != nil {
+		if name := s.Album.DisplayArtist(); name != "" {
+			return name
+		}
+	}
+	if len(s.Authors) > 0 && s.Authors[0] != nil {
+		return s.Authors[0].Name
+	}
+	return ""
+}
+
 // Album represents a music album containing multiple songs
 type Album struct {
 	Slug         string    `json:"slug" db:"slug"`
@@ -38,6 +73,7 @@ type Album struct {
 	Link         string    `json:"link" db:"link"`
 	Songs        []*Song   `json:"songs" db:"-"`
 	Artists      []*Author `json:"artists" db:"-"`
+	AlbumArtist  string    `json:"album_artist" db:"album_artist"`
 	Meta         *Meta     `json:"meta" db:"-"`
 
 	LastSync  time.Time `json:"-" db:"last_sync"`
@@ -45,22 +81,129 @@ type Album struct {
 	UpdatedAt time.Time `json:"-" db:"updated_at"`
 }
 
+// IsCompilation reports whether the album collects tracks by many artists
+func (a *Album) IsCompilation() bool {
+	if strings.EqualFold(a.AlbumArtist, VariousArtists) {
+		return true
+	}
+
+	leads := make(map[string]struct{})
+	for _, song := range a.Songs {
+		if song != nil && len(song.Authors) > 0 && song.Authors[0] != nil {
+			leads[song.Authors[0].Slug] = struct{}{}
+		}
+	}
+	return len(leads) >= compilationLeadArtists
+}
+
+// DisplayArtist returns the album artist: the one set explicitly, Various
+// Artists for compilations, otherwise the credited artists. It is empty when
+// nothing is known.
+func (a *Album) DisplayArtist() string {
+	if a.AlbumArtist != "" {
+		return a.AlbumArtist
+	}
+	if a.IsCompilation() {
+		return VariousArtists
+	}
+
+	names := make([]string, 0, len(a.Artists))
+	for _, artist := range a.Artists {
+		if artist != nil && artist.Name != "" {
+			names = append(names, artist.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// AuthorRole is how an author is credited on a song
+type AuthorRole string
+
+const (
+	AuthorRoleMain     AuthorRole = "main"
+	AuthorRoleFeatured AuthorRole = "featured"
+	AuthorRoleComposer AuthorRole = "composer"
+	AuthorRoleRemixer  AuthorRole = "remixer"
+)
+
+// AuthorRoles lists the roles in display order
+var AuthorRoles = []AuthorRole{AuthorRoleMain, AuthorRoleFeatured, AuthorRoleComposer, AuthorRoleRemixer}
+
 // Author represents a music artist or author
 type Author struct {
-	Slug         string   `json:"slug" db:"slug"`
-	Name         string   `json:"name" db:"name"`
-	Image        *string  `json:"image" db:"image"`
-	ImageCropped *string  `json:"image_cropped" db:"image_cropped"`
-	Link         string   `json:"link" db:"link"`
-	Songs        []*Song  `json:"songs" db:"-"`
-	Albums       []*Album `json:"albums" db:"-"`
-	Meta         *Meta    `json:"meta" db:"-"`
+	Slug         string     `json:"slug" db:"slug"`
+	Name         string     `json:"name" db:"name"`
+	Image        *string    `json:"image" db:"image"`
+	ImageCropped *string    `json:"image_cropped" db:"image_cropped"`
+	Link         string     `json:"link" db:"link"`
+	Songs        []*Song    `json:"songs" db:"-"`
+	Albums       []*Album   `json:"albums" db:"-"`
+	Meta         *Meta      `json:"meta" db:"-"`
+	Role         AuthorRole `json:"role,omitempty" db:"-"`
 
 	LastSync  time.Time `json:"-" db:"last_sync"`
 	CreatedAt time.Time `json:"-" db:"created_at"`
 	UpdatedAt time.Time `json:"-" db:"updated_at"`
 }
 
+// CreditRole returns the author's role, treating unknown credits as main
+func (a *Author) CreditRole() AuthorRole {
+	if a.Role == "" {
+		return AuthorRoleMain
+	}
+	return a.Role
+}
+
+// RoleOf returns the role the author with the given slug has on the song
+func (s *Song) RoleOf(authorSlug string) (AuthorRole, bool) {
+	for _, author := range s.Authors {
+		if author != nil && author.Slug == authorSlug {
+			return author.CreditRole(), true
+		}
+	}
+	return "", false
+}
+
+// CreditsLabel formats performing credits as "A & B feat. C". Composers and
+// remixers are left out unless nobody else is credited.
+func CreditsLabel(authors []*Author) string {
+	var main, featured, other []string
+	for _, author := range authors {
+		if author == nil || author.Name == "" {
+			continue
+		}
+		switch author.CreditRole() {
+		case AuthorRoleMain:
+			main = append(main, author.Name)
+		case AuthorRoleFeatured:
+			featured = append(featured, author.Name)
+		default:
+			other = append(other, author.Name)
+		}
+	}
+
+	if len(main) == 0 {
+		main, featured = append(featured, other...), nil
+	}
+
+	label := joinNames(main)
+	if len(featured) > 0 {
+		label += " feat. " + joinNames(featured)
+	}
+	return label
+}
+
+func joinNames(names []string) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	default:
+		return strings.Join(names[:len(names)-1], ", ") + " & " + names[len(names)-1]
+	}
+}
+
 // Playlist represents a collection of songs organized by a user
 type Playlist struct {
 	Slug    string   `json:"slug" db:"slug"`
@@ -139,14 +282,227 @@ type AuthResponse struct {
 	Token string `json:"token"`
 }
 
-// PlayHistory represents a record of song playback
-type PlayHistory struct {
-	ID        int64     `db:"id"`
+// PlaySource describes the view or collection playback was started from
+type PlaySource struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+const (
+	PlaySourceLibrary  = "library"
+	PlaySourceAlbum    = "album"
+	PlaySourceAuthor   = "author"
+	PlaySourcePlaylist = "playlist"
+	PlaySourceExternal = "external"
+	PlaySourceHistory  = "history"
+	PlaySourceImport   = "import"
+)
+
+// PlayEventType identifies what happened to a track during a session
+type PlayEventType string
+
+const (
+	PlayEventStart  PlayEventType = "start"
+	PlayEventFinish PlayEventType = "finish"
+	PlayEventSkip   PlayEventType = "skip"
+)
+
+// PlayEvent is a single entry in the listening history event log
+type PlayEvent struct {
+	ID            int64         `db:"id"`
+	SessionID     string        `db:"session_id"`
+	SongSlug      string        `db:"song_slug"`
+	UserID        *string       `db:"user_id"`
+	Type          PlayEventType `db:"event_type"`
+	Source        PlaySource    `db:"-"`
+	QueueIndex    int           `db:"queue_index"`
+	QueueLength   int           `db:"queue_length"`
+	PlayedSeconds int           `db:"played_seconds"`
+	OccurredAt    time.Time     `db:"occurred_at"`
+	Synced        bool          `db:"synced"`
+}
+
+// ListeningSession groups play events that happened without a long pause
+type ListeningSession struct {
+	ID              string     `db:"id"`
+	StartedAt       time.Time  `db:"started_at"`
+	EndedAt         time.Time  `db:"ended_at"`
+	Source          PlaySource `db:"-"`
+	TrackCount      int        `db:"track_count"`
+	ListenedSeconds int        `db:"listened_seconds"`
+}
+
+// HistoryEntry is a song started in the listening history, with the song as
+// stored, or nil when it is no longer in the library
+type HistoryEntry struct {
+	SongSlug string
+	Song     *Song
+	PlayedAt time.Time
+	Source   PlaySource
+}
+
+// AlbumGap is an album only some of whose tracks play without the server.
+// Tracks is how many the server lists, 0 while not known
+type AlbumGap struct {
+	Album  *Album
+	Tracks int
+	Owned  int
+}
+
+// ListeningTotal is how often and how long a song, album or artist was
+// listened to
+type ListeningTotal struct {
+	Slug    string
+	Name    string
+	Plays   int
+	Seconds int
+}
+
+// ListeningDay is the listening of one day, dated as it was where it happened
+type ListeningDay struct {
+	Day     time.Time
+	Plays   int
+	Seconds int
+}
+
+// RecentKind tells what a recently played shortcut points at
+type RecentKind string
+
+const (
+	RecentAlbum  RecentKind = "album"
+	RecentAuthor RecentKind = "author"
+)
+
+// RecentShortcut is an album or artist the user listened to lately
+type RecentShortcut struct {
+	Kind RecentKind
+	Slug string
+	Name string
+}
+
+// TrashKind tells what a trashed item is
+type TrashKind string
+
+const (
+	TrashSong     TrashKind = "song"
+	TrashPlaylist TrashKind = "playlist"
+)
+
+// TrashRetention is how long deleted items stay restorable before they are purged
+const TrashRetention = 30 * 24 * time.Hour
+
+// TrashItem is a soft-deleted song or playlist
+type TrashItem struct {
+	Kind      TrashKind
+	Slug      string
+	Name      string
+	LocalOnly bool
+	DeletedAt time.Time
+}
+
+// ExpiresAt returns when the item will be purged for good
+func (t *TrashItem) ExpiresAt() time.Time {
+	return t.DeletedAt.Add(TrashRetention)
+}
+
+// PlaybackQueue is the player's queue and where it was in it, kept across
+// restarts
+type PlaybackQueue struct {
+	Songs    []*Song
+	Index    int
+	Position time.Duration
+	Shuffle  bool
+	// Repeat is the player bar's repeat mode: off, one or all
+	Repeat int
+}
+
+// LibraryFile is an audio file found in a library folder, remembered so
+// rescans only read files that changed
+type LibraryFile struct {
+	Path     string
+	Size     int64
+	ModTime  time.Time
+	SongSlug string
+}
+
+// TransitionMode controls what happens between two tracks of a queue
+type TransitionMode string
+
+const (
+	TransitionNormal    TransitionMode = "normal"
+	TransitionGapless   TransitionMode = "gapless"
+	TransitionCrossfade TransitionMode = "crossfade"
+	TransitionGap       TransitionMode = "gap"
+)
+
+// DefaultCrossfadeSeconds and DefaultGapSeconds are used when a playlist picks
+// a mode without specifying a length
+const (
+	DefaultCrossfadeSeconds = 5
+	DefaultGapSeconds       = 2
+)
+
+// PlaylistTransition is the per-playlist track transition preference
+type PlaylistTransition struct {
+	PlaylistSlug string         `db:"playlist_slug"`
+	Mode         TransitionMode `db:"mode"`
+	Seconds      int            `db:"seconds"`
+}
+
+// Duration returns the crossfade or gap length for the transition
+func (t PlaylistTransition) Duration() time.Duration {
+	seconds := t.Seconds
+	if seconds <= 0 {
+		switch t.Mode {
+		case TransitionCrossfade:
+			seconds = DefaultCrossfadeSeconds
+		case TransitionGap:
+			seconds = DefaultGapSeconds
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// ArtworkKind tells which kind of item an artwork override belongs to
+type ArtworkKind string
+
+const (
+	ArtworkSong   ArtworkKind = "song"
+	ArtworkAlbum  ArtworkKind = "album"
+	ArtworkAuthor ArtworkKind = "author"
+)
+
+// ArtworkOverride is a local image the user picked in place of the server's
+// artwork. It is kept apart from synced rows so a sync never replaces it.
+type ArtworkOverride struct {
+	Kind      ArtworkKind `db:"kind"`
+	Slug      string      `db:"slug"`
+	Path      string      `db:"path"`
+	UpdatedAt time.Time   `db:"updated_at"`
+}
+
+// Lyrics are the words of a song as cached locally. Synced is LRC text with
+// a timestamp on each line, Plain the words without timing; either may be
+// empty. Both are empty when no lyrics were found, which is cached too so
+// the lookup is not repeated on every view.
+type Lyrics struct {
 	SongSlug  string    `db:"song_slug"`
-	UserID    *string   `db:"user_id"`
-	PlayedAt  time.Time `db:"played_at"`
-	Synced    bool      `db:"synced"`
-	CreatedAt time.Time `db:"created_at"`
+	Synced    string    `db:"synced"`
+	Plain     string    `db:"plain"`
+	Source    string    `db:"source"`
+	FetchedAt time.Time `db:"fetched_at"`
+}
+
+// Where lyrics came from
+const (
+	LyricsSourceServer = "server"
+	LyricsSourceLRCLIB = "lrclib"
+)
+
+// Found reports whether any lyrics are known
+func (l *Lyrics) Found() bool {
+	return l != nil && (l.Synced != "" || l.Plain != "")
 }
 
 // DownloadItem represents a download task
@@ -173,6 +529,8 @@ type CacheEntry struct {
 type Storage interface {
 	GetSong(ctx context.Context, slug string) (*Song, error)
 	SearchSongs(ctx context.Context, query string, limit int) ([]*Song, error)
+	SearchAlbums(ctx context.Context, query string, limit int) ([]*Album, error)
+	SearchAuthors(ctx context.Context, query string, limit int) ([]*Author, error)
 	GetAlbum(ctx context.Context, slug string) (*Album, error)
 	GetAuthor(ctx context.Context, slug string) (*Author, error)
 	GetPlaylist(ctx context.Context, slug string) (*Playlist, error)
@@ -180,3 +538,12 @@ type Storage interface {
 	GetAlbums(ctx context.Context, limit, offset int) ([]*Album, error)
 	GetAuthors(ctx context.Context, limit, offset int) ([]*Author, error)
 }
+
+// PlaylistSortKey is the order a playlist's songs can be rearranged into
+type PlaylistSortKey string
+
+const (
+	PlaylistSortArtist    PlaylistSortKey = "artist"
+	PlaylistSortTitle     PlaylistSortKey = "title"
+	PlaylistSortDateAdded PlaylistSortKey = "date_added"
+)